@@ -0,0 +1,264 @@
+// -*- coding: utf-8 -*-
+// cli.go - subcommands and the MCP client they share
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets the binary act as a small MCP client for smoke-testing a
+// running server. When the first argument names a subcommand, that command
+// runs instead of the server:
+//
+//   fast-time-server call get_system_time --timezone=Asia/Tokyo --transport=sse
+//
+// Subcommands accept the client options below in either -name or --name form.
+// With the stdio transport the client starts a server subprocess (this binary
+// unless --command is given) and talks to it over its stdin and stdout; the
+// inprocess transport runs this server inside the client instead.
+
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/client"
+    "github.com/mark3labs/mcp-go/client/transport"
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+
+    "fast-time-server/fasttime"
+)
+
+// subcommand runs with the arguments after its name and returns the exit code
+type subcommand func(args []string, stdout, stderr io.Writer) int
+
+// subcommands maps the first command-line argument to the command it runs
+var subcommands = map[string]subcommand{
+    "call":   runCall,
+    "repl":   runRepl,
+    "bridge": runBridge,
+}
+
+// flagSubcommand is a subcommand that needs the server's flag definitions
+type flagSubcommand func(server *flag.FlagSet, args []string, stdout, stderr io.Writer) int
+
+// flagSubcommands run once main has defined the server flags
+var flagSubcommands = map[string]flagSubcommand{
+    "validate-config": runValidateConfig,
+    "completion":      runCompletion,
+    "service":         runService,
+}
+
+// appName and appVersion identify the client and the help output
+const appName = fasttime.Name
+
+var appVersion = fasttime.Version()
+
+// defaultPort is the server's port unless -port says otherwise
+var defaultPort = fasttime.DefaultConfig().Port
+
+// envAuthToken names the variable holding the server's Bearer token
+const envAuthToken = "AUTH_TOKEN"
+
+// Exit codes of the subcommands
+const (
+    exitOK    = 0
+    exitFail  = 1 // the server could not be reached or the request failed
+    exitUsage = 2
+)
+
+// defaultClientTimeout bounds a subcommand's whole conversation
+const defaultClientTimeout = 30 * time.Second
+
+// clientOptions says how a subcommand reaches the server
+type clientOptions struct {
+    transport string
+    url       string
+    authToken string
+    command   string
+    timeout   time.Duration
+    tracer    *trafficTracer // shows the JSON-RPC traffic when set
+}
+
+// addClientFlags registers the client options on fs
+func addClientFlags(fs *flag.FlagSet, o *clientOptions) {
+    fs.StringVar(&o.transport, "transport", "stdio", "Transport: stdio | sse | http | inprocess")
+    fs.StringVar(&o.url, "url", "", "Server URL (default http://localhost:8080/sse or /http)")
+    fs.StringVar(&o.authToken, "auth-token", "", "Bearer token (default $AUTH_TOKEN)")
+    fs.StringVar(&o.command, "command", "", "Server command for the stdio transport (default this binary)")
+    fs.DurationVar(&o.timeout, "timeout", defaultClientTimeout, "Give up after this long")
+}
+
+// serverURL returns the URL to connect to for the sse and http transports
+func (o clientOptions) serverURL() string {
+    if o.url != "" {
+        return o.url
+    }
+    return fmt.Sprintf("http://localhost:%d/%s", defaultPort, o.transport)
+}
+
+// target describes the server o connects to, for error messages
+func (o clientOptions) target() string {
+    switch strings.ToLower(o.transport) {
+    case "stdio":
+        if o.command != "" {
+            return o.command
+        }
+        return "the stdio server"
+    case "inprocess":
+        return "the in-process server"
+    }
+    return o.serverURL()
+}
+
+// clientLogger sends the client transports' own logging to the debug log;
+// errors that matter are returned to the subcommand anyway
+type clientLogger struct{}
+
+func (clientLogger) Infof(format string, v ...any)  { fasttime.Logf("debug", "client: "+format, v...) }
+func (clientLogger) Errorf(format string, v ...any) { fasttime.Logf("debug", "client: "+format, v...) }
+
+// newClientTransport creates the transport for o without starting it
+func newClientTransport(o clientOptions, headers map[string]string) (transport.Interface, error) {
+    switch strings.ToLower(o.transport) {
+    case "stdio":
+        cmd := strings.Fields(o.command)
+        if len(cmd) == 0 {
+            self, err := os.Executable()
+            if err != nil {
+                return nil, err
+            }
+            cmd = []string{self, "-transport=stdio", "-log-level=none"}
+        }
+        return transport.NewStdioWithOptions(cmd[0], nil, cmd[1:], transport.WithCommandLogger(clientLogger{})), nil
+    case "sse":
+        return transport.NewSSE(o.serverURL(), transport.WithHeaders(headers), transport.WithSSELogger(clientLogger{}))
+    case "http":
+        return transport.NewStreamableHTTP(o.serverURL(), transport.WithHTTPHeaders(headers), transport.WithHTTPLogger(clientLogger{}))
+    case "inprocess":
+        s, err := newQuietServer()
+        if err != nil {
+            return nil, err
+        }
+        return transport.NewInProcessTransport(s), nil
+    }
+    return nil, fmt.Errorf("unknown transport %q (use stdio, sse, http or inprocess)", o.transport)
+}
+
+// newQuietServer builds the server for the inprocess transport and for
+// completion; its info logging would interleave with their output
+func newQuietServer() (*server.MCPServer, error) {
+    cfg := fasttime.DefaultConfig()
+    cfg.LogLevel = "warn"
+    srv, err := fasttime.New(cfg)
+    if err != nil {
+        return nil, err
+    }
+    return srv.MCPServer(), nil
+}
+
+// connectClient starts a client on the chosen transport and initializes the
+// MCP session
+func connectClient(ctx context.Context, o clientOptions) (*client.Client, error) {
+    token := o.authToken
+    if token == "" {
+        token = os.Getenv(envAuthToken)
+    }
+    headers := map[string]string{}
+    if token != "" {
+        headers["Authorization"] = "Bearer " + token
+    }
+
+    t, err := newClientTransport(o, headers)
+    if err != nil {
+        return nil, err
+    }
+    if o.tracer != nil {
+        t = o.tracer.wrap(t)
+    }
+    // Client.Start leaves stdio transports to whoever created them
+    if st, ok := t.(*transport.Stdio); ok {
+        if err := st.Start(ctx); err != nil {
+            return nil, fmt.Errorf("starting %s: %w", o.target(), err)
+        }
+    }
+    c := client.NewClient(t)
+    if err := c.Start(ctx); err != nil {
+        c.Close()
+        return nil, fmt.Errorf("connecting to %s: %w", o.target(), err)
+    }
+
+    initReq := mcp.InitializeRequest{}
+    initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+    initReq.Params.ClientInfo = mcp.Implementation{Name: appName + "-cli", Version: appVersion}
+    if _, err := c.Initialize(ctx, initReq); err != nil {
+        c.Close()
+        return nil, fmt.Errorf("initialize: %w", err)
+    }
+    return c, nil
+}
+
+// splitOption splits "--name=value" or "-name" into name and value; ok is
+// false for arguments that are not options
+func splitOption(arg string) (name, value string, hasValue, ok bool) {
+    if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+        return "", "", false, false
+    }
+    name = strings.TrimLeft(arg, "-")
+    name, value, hasValue = strings.Cut(name, "=")
+    return name, value, hasValue, name != ""
+}
+
+// isBoolFlag reports whether f takes no value
+func isBoolFlag(f *flag.Flag) bool {
+    b, ok := f.Value.(interface{ IsBoolFlag() bool })
+    return ok && b.IsBoolFlag()
+}
+
+// parseMixedArgs sets the options defined on fs and returns the positional
+// arguments and the remaining --name=value pairs in order. An option without
+// "=" takes the next argument as its value unless that is another option or
+// the option is a boolean flag of fs.
+func parseMixedArgs(fs *flag.FlagSet, args []string) (positional []string, extra [][2]string, err error) {
+    for i := 0; i < len(args); i++ {
+        name, value, hasValue, ok := splitOption(args[i])
+        if !ok {
+            if args[i] == "--" {
+                return append(positional, args[i+1:]...), extra, nil
+            }
+            positional = append(positional, args[i])
+            continue
+        }
+        if f := fs.Lookup(name); f != nil {
+            if !hasValue && isBoolFlag(f) {
+                value, hasValue = "true", true
+            }
+            if !hasValue {
+                if i+1 >= len(args) {
+                    return nil, nil, fmt.Errorf("option --%s needs a value", name)
+                }
+                i++
+                value = args[i]
+            }
+            if err := fs.Set(name, value); err != nil {
+                return nil, nil, fmt.Errorf("invalid --%s: %v", name, err)
+            }
+            continue
+        }
+        if !hasValue {
+            if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+                i++
+                value = args[i]
+            } else {
+                value = "true"
+            }
+        }
+        extra = append(extra, [2]string{name, value})
+    }
+    return positional, extra, nil
+}
@@ -0,0 +1,280 @@
+// -*- coding: utf-8 -*-
+// cli_bridge.go - the bridge subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements `fast-time-server bridge`, which lets a desktop
+// client that only starts stdio servers use a centrally hosted one:
+//
+//   {"mcpServers": {"fast-time": {
+//     "command": "/path/to/fast-time-server",
+//     "args": ["bridge", "--to=sse://time.example.com:8080", "--auth-token=..."]
+//   }}}
+//
+// The bridge speaks stdio to the client and relays each JSON-RPC message to
+// the remote server unchanged: requests and their responses, notifications
+// in both directions and, over streamable HTTP, the server's own requests
+// such as sampling. --to takes sse://HOST[:PORT][/PATH] (SSE, path /sse by
+// default; sses:// for TLS) or an http:// or https:// URL, which is SSE when
+// its path ends in /sse and streamable HTTP otherwise. Errors and the
+// connection state go to stderr, which desktop clients keep in their logs.
+
+package main
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "strings"
+    "sync"
+
+    "github.com/mark3labs/mcp-go/client/transport"
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxBridgeMessage bounds one JSON-RPC message read from the client
+const maxBridgeMessage = 16 << 20
+
+// runBridge implements the bridge subcommand
+func runBridge(args []string, stdout, stderr io.Writer) int {
+    fs := flag.NewFlagSet("bridge", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    var to, token string
+    fs.StringVar(&to, "to", "", "Remote server: sse://host:port, sses://host, or an http(s):// URL")
+    fs.StringVar(&token, "auth-token", "", "Bearer token (default $AUTH_TOKEN)")
+    fs.Usage = func() {
+        fmt.Fprintf(stderr, "Usage: %s bridge --to=URL [--auth-token=TOKEN]\n\n", appName)
+        fmt.Fprintf(stderr, "Relays MCP between stdio and a remote SSE or streamable HTTP server.\n\nOptions:\n")
+        fs.PrintDefaults()
+    }
+    if _, extra, err := parseMixedArgs(fs, args); err != nil || len(extra) > 0 {
+        if len(extra) > 0 && (extra[0][0] == "h" || extra[0][0] == "help") {
+            fs.Usage()
+            return exitOK
+        }
+        if err == nil {
+            err = fmt.Errorf("unknown option --%s", extra[0][0])
+        }
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitUsage
+    }
+    kind, target, err := parseBridgeTarget(to)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitUsage
+    }
+    if token == "" {
+        token = os.Getenv(envAuthToken)
+    }
+    headers := map[string]string{}
+    if token != "" {
+        headers["Authorization"] = "Bearer " + token
+    }
+
+    t, err := newBridgeTransport(kind, target, headers)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitUsage
+    }
+    if err := t.Start(context.Background()); err != nil {
+        fmt.Fprintf(stderr, "Error: connecting to %s: %v\n", target, err)
+        return exitFail
+    }
+    defer t.Close()
+    fmt.Fprintf(stderr, "%s bridge: relaying stdio to %s (%s)\n", appName, target, kind)
+    if err := bridge(context.Background(), t, os.Stdin, stdout, stderr); err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitFail
+    }
+    return exitOK
+}
+
+// parseBridgeTarget returns the transport and URL named by --to
+func parseBridgeTarget(to string) (kind, target string, err error) {
+    if to == "" {
+        return "", "", errors.New("--to is required, e.g. --to=sse://time.example.com:8080")
+    }
+    u, err := url.Parse(to)
+    if err != nil || u.Host == "" {
+        return "", "", fmt.Errorf("invalid --to %q: use sse://host:port or an http(s):// URL", to)
+    }
+    switch strings.ToLower(u.Scheme) {
+    case "sse", "sses":
+        u.Scheme = map[string]string{"sse": "http", "sses": "https"}[strings.ToLower(u.Scheme)]
+        if u.Path == "" || u.Path == "/" {
+            u.Path = "/sse"
+        }
+        return "sse", u.String(), nil
+    case "http", "https":
+        if strings.HasSuffix(u.Path, "/sse") {
+            return "sse", u.String(), nil
+        }
+        return "http", u.String(), nil
+    }
+    return "", "", fmt.Errorf("invalid --to %q: unknown scheme %q (use sse, sses, http or https)", to, u.Scheme)
+}
+
+// newBridgeTransport creates the client transport for a parsed --to
+func newBridgeTransport(kind, target string, headers map[string]string) (transport.Interface, error) {
+    if kind == "sse" {
+        return transport.NewSSE(target, transport.WithHeaders(headers), transport.WithSSELogger(clientLogger{}))
+    }
+    return transport.NewStreamableHTTP(target, transport.WithHTTPHeaders(headers), transport.WithHTTPLogger(clientLogger{}))
+}
+
+// bridgeMessage is any JSON-RPC message read from the client
+type bridgeMessage struct {
+    ID     *mcp.RequestId  `json:"id,omitempty"`
+    Method string          `json:"method"`
+    Params json.RawMessage `json:"params,omitempty"`
+}
+
+// bridgeWriter writes whole messages to the client, one per line
+type bridgeWriter struct {
+    mu  sync.Mutex
+    out io.Writer
+}
+
+func (w *bridgeWriter) write(v any) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.out.Write(append(data, '\n'))
+}
+
+// bridge relays messages between the client on in/out and t until in ends;
+// problems sending notifications are reported on errOut
+func bridge(ctx context.Context, t transport.Interface, in io.Reader, out, errOut io.Writer) error {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    w := &bridgeWriter{out: out}
+
+    t.SetNotificationHandler(func(n mcp.JSONRPCNotification) { w.write(n) })
+
+    // The server's requests wait for the client's answer, matched by id
+    var mu sync.Mutex
+    pending := map[string]chan *transport.JSONRPCResponse{}
+    if bt, ok := t.(transport.BidirectionalInterface); ok {
+        bt.SetRequestHandler(func(ctx context.Context, req transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+            ch := make(chan *transport.JSONRPCResponse, 1)
+            mu.Lock()
+            pending[req.ID.String()] = ch
+            mu.Unlock()
+            defer func() {
+                mu.Lock()
+                delete(pending, req.ID.String())
+                mu.Unlock()
+            }()
+            w.write(req)
+            select {
+            case resp := <-ch:
+                return resp, nil
+            case <-ctx.Done():
+                return nil, ctx.Err()
+            }
+        })
+    }
+
+    var wg sync.WaitGroup
+    defer wg.Wait()
+    r := bufio.NewReaderSize(in, 64<<10)
+    for {
+        line, err := readBridgeLine(r)
+        if len(line) > 0 {
+            relayBridgeMessage(ctx, t, w, errOut, line, &wg, func(id string, resp *transport.JSONRPCResponse) {
+                mu.Lock()
+                ch := pending[id]
+                mu.Unlock()
+                if ch != nil {
+                    ch <- resp
+                }
+            })
+        }
+        if errors.Is(err, io.EOF) {
+            return nil
+        }
+        if err != nil {
+            return err
+        }
+    }
+}
+
+// readBridgeLine reads one line of at most maxBridgeMessage bytes
+func readBridgeLine(r *bufio.Reader) ([]byte, error) {
+    var line []byte
+    for {
+        chunk, err := r.ReadSlice('\n')
+        line = append(line, chunk...)
+        if len(line) > maxBridgeMessage {
+            return nil, fmt.Errorf("message longer than %d bytes", maxBridgeMessage)
+        }
+        if !errors.Is(err, bufio.ErrBufferFull) {
+            return []byte(strings.TrimSpace(string(line))), err
+        }
+    }
+}
+
+// relayBridgeMessage sends one client message to t: requests are answered
+// asynchronously, notifications sent as they come and responses handed to
+// answer for the server request they belong to
+func relayBridgeMessage(ctx context.Context, t transport.Interface, w *bridgeWriter, errOut io.Writer, line []byte, wg *sync.WaitGroup, answer func(string, *transport.JSONRPCResponse)) {
+    var msg bridgeMessage
+    if err := json.Unmarshal(line, &msg); err != nil {
+        w.write(mcp.NewJSONRPCError(mcp.NewRequestId(nil), mcp.PARSE_ERROR, "parse error: "+err.Error(), nil))
+        return
+    }
+    switch {
+    case msg.ID != nil && msg.Method != "":
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            req := transport.JSONRPCRequest{JSONRPC: mcp.JSONRPC_VERSION, ID: *msg.ID, Method: msg.Method, Params: msg.Params}
+            resp, err := t.SendRequest(ctx, req)
+            if err != nil {
+                w.write(mcp.NewJSONRPCError(*msg.ID, mcp.INTERNAL_ERROR, "bridge: "+err.Error(), nil))
+                return
+            }
+            if msg.Method == string(mcp.MethodInitialize) {
+                setBridgeProtocol(t, resp.Result)
+            }
+            w.write(resp)
+        }()
+
+    case msg.Method != "":
+        var n mcp.JSONRPCNotification
+        if err := json.Unmarshal(line, &n); err == nil {
+            if err := t.SendNotification(ctx, n); err != nil {
+                fmt.Fprintf(errOut, "bridge: %s: %v\n", msg.Method, err)
+            }
+        }
+
+    case msg.ID != nil:
+        var resp transport.JSONRPCResponse
+        if err := json.Unmarshal(line, &resp); err == nil {
+            answer(msg.ID.String(), &resp)
+        }
+    }
+}
+
+// setBridgeProtocol tells an HTTP transport the negotiated protocol version,
+// which it sends on later requests
+func setBridgeProtocol(t transport.Interface, result json.RawMessage) {
+    hc, ok := t.(transport.HTTPConnection)
+    if !ok {
+        return
+    }
+    var init mcp.InitializeResult
+    if json.Unmarshal(result, &init) == nil && init.ProtocolVersion != "" {
+        hc.SetProtocolVersion(init.ProtocolVersion)
+    }
+}
@@ -0,0 +1,140 @@
+// -*- coding: utf-8 -*-
+// cli_bridge_test.go - Tests for the bridge subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "context"
+    "encoding/json"
+    "io"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestParseBridgeTarget(t *testing.T) {
+    for _, tc := range []struct{ to, kind, target string }{
+        {"sse://time.example.com:8080", "sse", "http://time.example.com:8080/sse"},
+        {"sses://time.example.com/", "sse", "https://time.example.com/sse"},
+        {"sse://host:8080/mcp/sse", "sse", "http://host:8080/mcp/sse"},
+        {"http://host:8080/sse", "sse", "http://host:8080/sse"},
+        {"https://host/http", "http", "https://host/http"},
+    } {
+        kind, target, err := parseBridgeTarget(tc.to)
+        if err != nil || kind != tc.kind || target != tc.target {
+            t.Errorf("%s = %s %s %v, want %s %s", tc.to, kind, target, err, tc.kind, tc.target)
+        }
+    }
+    for _, to := range []string{"", "host:8080", "ws://host:8080", "sse://"} {
+        if _, _, err := parseBridgeTarget(to); err == nil {
+            t.Errorf("%q should be rejected", to)
+        }
+    }
+}
+
+func TestBridge(t *testing.T) {
+    sse := server.NewTestServer(callTestServer(t))
+    defer sse.Close()
+    streamable := server.NewTestStreamableHTTPServer(callTestServer(t))
+    defer streamable.Close()
+
+    for name, to := range map[string]string{
+        "sse":  "sse://" + strings.TrimPrefix(sse.URL, "http://"),
+        "http": streamable.URL,
+    } {
+        t.Run(name, func(t *testing.T) {
+            kind, target, err := parseBridgeTarget(to)
+            if err != nil || kind != name {
+                t.Fatalf("%s: %s %v", to, kind, err)
+            }
+            tr, err := newBridgeTransport(kind, target, nil)
+            if err != nil {
+                t.Fatal(err)
+            }
+            ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+            defer cancel()
+            if err := tr.Start(ctx); err != nil {
+                t.Fatal(err)
+            }
+            defer tr.Close()
+
+            inR, inW := io.Pipe()
+            outR, outW := io.Pipe()
+            var errOut bytes.Buffer
+            done := make(chan error, 1)
+            go func() {
+                done <- bridge(ctx, tr, inR, outW, &errOut)
+                outW.Close()
+            }()
+            r := bufio.NewReader(outR)
+            send := func(msg string) {
+                if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+                    t.Fatal(err)
+                }
+            }
+            receive := func() map[string]any {
+                line, err := r.ReadString('\n')
+                if err != nil {
+                    t.Fatalf("reading from bridge: %v", err)
+                }
+                var msg map[string]any
+                if err := json.Unmarshal([]byte(line), &msg); err != nil {
+                    t.Fatalf("%v: %s", err, line)
+                }
+                return msg
+            }
+
+            send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"desktop","version":"1"}}}`)
+            init := receive()
+            data, _ := json.Marshal(init["result"])
+            if init["id"] != 1.0 || !strings.Contains(string(data), appName) {
+                t.Fatalf("initialize = %v", init)
+            }
+            send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+            send(`{"jsonrpc":"2.0","id":"two","method":"tools/call","params":{"name":"get_system_time","arguments":{"timezone":"UTC"}}}`)
+            call := receive()
+            data, _ = json.Marshal(call["result"])
+            if call["id"] != "two" || !strings.Contains(string(data), `Z"`) {
+                t.Errorf("tools/call = %v", call)
+            }
+
+            send(`not json`)
+            if bad := receive(); bad["error"] == nil {
+                t.Errorf("parse error = %v", bad)
+            }
+
+            inW.Close()
+            if err := <-done; err != nil {
+                t.Errorf("bridge: %v", err)
+            }
+            if errOut.Len() > 0 {
+                t.Errorf("stderr: %s", errOut.String())
+            }
+        })
+    }
+}
+
+func TestRunBridgeUsage(t *testing.T) {
+    var out, errOut bytes.Buffer
+    if code := runBridge(nil, &out, &errOut); code != exitUsage || !strings.Contains(errOut.String(), "--to is required") {
+        t.Errorf("no --to = %d %q", code, errOut.String())
+    }
+    errOut.Reset()
+    if code := runBridge([]string{"--to=sse://h:1", "--colour=red"}, &out, &errOut); code != exitUsage {
+        t.Errorf("unknown option = %d %q", code, errOut.String())
+    }
+    if code := runBridge([]string{"-h"}, &out, &errOut); code != exitOK {
+        t.Errorf("-h = %d", code)
+    }
+    if out.Len() > 0 {
+        t.Errorf("stdout must stay clean for the client: %q", out.String())
+    }
+}
@@ -0,0 +1,246 @@
+// -*- coding: utf-8 -*-
+// cli_call.go - the call subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements `fast-time-server call TOOL [--arg=value ...]`, which
+// connects to a server, calls one tool and prints the result:
+//
+//   fast-time-server call get_system_time --timezone=Asia/Tokyo
+//   fast-time-server call convert_time --transport=http --url=http://host:8080/http \
+//       --time=2025-06-01T09:00:00 --source_timezone=UTC --target_timezone=Europe/Paris
+//   fast-time-server call --transport=sse          # list the tools
+//
+// Options other than the client options become tool arguments. Their values
+// are converted to the types in the tool's input schema, so --samples=3 is
+// sent as a number and --working_hours='{"start":"09:00"}' as an object;
+// --args='{...}' passes a complete JSON object instead. Text results are
+// printed as they are and --json prints the whole result. A tool error is
+// printed to stderr and exits with status 1.
+
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/mark3labs/mcp-go/client"
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// runCall implements the call subcommand
+func runCall(args []string, stdout, stderr io.Writer) int {
+    fs := flag.NewFlagSet("call", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    var (
+        opts    clientOptions
+        rawArgs string
+        asJSON  bool
+    )
+    addClientFlags(fs, &opts)
+    fs.StringVar(&rawArgs, "args", "", "Tool arguments as one JSON object")
+    fs.BoolVar(&asJSON, "json", false, "Print the whole result as JSON")
+    fs.Usage = func() {
+        fmt.Fprintf(stderr, "Usage: %s call [TOOL] [--arg=value ...] [options]\n\n", appName)
+        fmt.Fprintf(stderr, "Calls TOOL on a running server, or lists the tools when TOOL is omitted.\n\nOptions:\n")
+        fs.PrintDefaults()
+    }
+
+    positional, extra, err := parseMixedArgs(fs, args)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitUsage
+    }
+    for _, kv := range extra {
+        if kv[0] == "h" || kv[0] == "help" {
+            fs.Usage()
+            return exitOK
+        }
+    }
+    if len(positional) > 1 {
+        fmt.Fprintf(stderr, "Error: unexpected arguments %q\n", positional[1:])
+        return exitUsage
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+    defer cancel()
+    c, err := connectClient(ctx, opts)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitFail
+    }
+    defer c.Close()
+
+    tools, err := listAllTools(ctx, c)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error: tools/list:", err)
+        return exitFail
+    }
+    if len(positional) == 0 {
+        for _, t := range tools {
+            fmt.Fprintf(stdout, "%-32s %s\n", t.Name, t.Description)
+        }
+        return exitOK
+    }
+
+    name := positional[0]
+    var tool *mcp.Tool
+    for i := range tools {
+        if tools[i].Name == name {
+            tool = &tools[i]
+        }
+    }
+    if tool == nil {
+        fmt.Fprintf(stderr, "Error: the server has no tool %q (run `%s call` to list them)\n", name, appName)
+        return exitUsage
+    }
+    arguments, err := buildToolArguments(tool.InputSchema, rawArgs, extra)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitUsage
+    }
+
+    req := mcp.CallToolRequest{}
+    req.Params.Name = name
+    req.Params.Arguments = arguments
+    res, err := c.CallTool(ctx, req)
+    if err != nil {
+        fmt.Fprintf(stderr, "Error: %s: %v\n", name, err)
+        return exitFail
+    }
+    return printToolResult(res, asJSON, stdout, stderr)
+}
+
+// listAllTools returns every tool, following pagination
+func listAllTools(ctx context.Context, c *client.Client) ([]mcp.Tool, error) {
+    var tools []mcp.Tool
+    req := mcp.ListToolsRequest{}
+    for {
+        res, err := c.ListTools(ctx, req)
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, res.Tools...)
+        if res.NextCursor == "" {
+            return tools, nil
+        }
+        req.Params.Cursor = res.NextCursor
+    }
+}
+
+// buildToolArguments merges --args and the --name=value options, converting
+// each value to the type its schema property declares
+func buildToolArguments(schema mcp.ToolInputSchema, rawArgs string, extra [][2]string) (map[string]any, error) {
+    arguments := map[string]any{}
+    if rawArgs != "" {
+        if err := json.Unmarshal([]byte(rawArgs), &arguments); err != nil {
+            return nil, fmt.Errorf("--args is not a JSON object: %v", err)
+        }
+    }
+    for _, kv := range extra {
+        name, value := kv[0], kv[1]
+        prop, ok := schema.Properties[name].(map[string]any)
+        if !ok {
+            return nil, fmt.Errorf("unknown argument --%s (the tool takes %s)", name, describeProperties(schema))
+        }
+        v, err := convertArgument(value, prop)
+        if err != nil {
+            return nil, fmt.Errorf("--%s: %v", name, err)
+        }
+        arguments[name] = v
+    }
+    var missing []string
+    for _, r := range schema.Required {
+        if _, ok := arguments[r]; !ok {
+            missing = append(missing, "--"+r)
+        }
+    }
+    if len(missing) > 0 {
+        return nil, fmt.Errorf("missing required %s", strings.Join(missing, ", "))
+    }
+    return arguments, nil
+}
+
+// convertArgument converts a command-line value to the JSON type of prop
+func convertArgument(value string, prop map[string]any) (any, error) {
+    switch prop["type"] {
+    case "number", "integer":
+        n, err := strconv.ParseFloat(value, 64)
+        if err != nil {
+            return nil, fmt.Errorf("%q is not a number", value)
+        }
+        return n, nil
+    case "boolean":
+        b, err := strconv.ParseBool(value)
+        if err != nil {
+            return nil, fmt.Errorf("%q is not true or false", value)
+        }
+        return b, nil
+    case "array", "object":
+        var v any
+        if err := json.Unmarshal([]byte(value), &v); err != nil {
+            if prop["type"] == "array" && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+                return splitArrayArgument(value), nil
+            }
+            return nil, fmt.Errorf("invalid JSON: %v", err)
+        }
+        return v, nil
+    }
+    return value, nil
+}
+
+// splitArrayArgument turns "a,b,c" into a JSON array of strings
+func splitArrayArgument(value string) []any {
+    var out []any
+    for _, s := range strings.Split(value, ",") {
+        out = append(out, strings.TrimSpace(s))
+    }
+    return out
+}
+
+// describeProperties lists the argument names of a schema
+func describeProperties(schema mcp.ToolInputSchema) string {
+    if len(schema.Properties) == 0 {
+        return "no arguments"
+    }
+    names := make([]string, 0, len(schema.Properties))
+    for name := range schema.Properties {
+        names = append(names, "--"+name)
+    }
+    sort.Strings(names)
+    return strings.Join(names, ", ")
+}
+
+// printToolResult writes a tool result and returns the exit code
+func printToolResult(res *mcp.CallToolResult, asJSON bool, stdout, stderr io.Writer) int {
+    out := stdout
+    code := exitOK
+    if res.IsError {
+        out, code = stderr, exitFail
+    }
+    if asJSON {
+        data, err := json.MarshalIndent(res, "", "  ")
+        if err != nil {
+            fmt.Fprintln(stderr, "Error:", err)
+            return exitFail
+        }
+        fmt.Fprintln(out, string(data))
+        return code
+    }
+    for _, content := range res.Content {
+        if text, ok := content.(mcp.TextContent); ok {
+            fmt.Fprintln(out, text.Text)
+            continue
+        }
+        data, _ := json.Marshal(content)
+        fmt.Fprintln(out, string(data))
+    }
+    return code
+}
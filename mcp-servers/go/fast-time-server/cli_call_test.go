@@ -0,0 +1,141 @@
+// -*- coding: utf-8 -*-
+// cli_call_test.go - Tests for the call subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bytes"
+    "context"
+    "flag"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// callTestServer returns this server with an extra tool echoing the type of
+// its "n" argument
+func callTestServer(t *testing.T) *server.MCPServer {
+    srv, err := newQuietServer()
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv.AddTool(mcp.NewTool("kind",
+        mcp.WithNumber("n", mcp.Required()),
+        mcp.WithBoolean("flag"),
+    ), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        args := req.GetArguments()
+        if _, ok := args["n"].(float64); !ok {
+            return mcp.NewToolResultError("n is not a number"), nil
+        }
+        return mcp.NewToolResultText("number " + req.GetString("label", "")), nil
+    })
+    return srv
+}
+
+func TestParseMixedArgs(t *testing.T) {
+    fs := flag.NewFlagSet("t", flag.ContinueOnError)
+    var o clientOptions
+    var asJSON bool
+    addClientFlags(fs, &o)
+    fs.BoolVar(&asJSON, "json", false, "")
+
+    pos, extra, err := parseMixedArgs(fs, []string{"tool", "--timezone=UTC", "-transport", "http", "--json", "--label", "x y", "--on", "--url=http://h/"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(pos, []string{"tool"}) || o.transport != "http" || o.url != "http://h/" || !asJSON {
+        t.Errorf("pos=%v opts=%+v json=%v", pos, o, asJSON)
+    }
+    want := [][2]string{{"timezone", "UTC"}, {"label", "x y"}, {"on", "true"}}
+    if !reflect.DeepEqual(extra, want) {
+        t.Errorf("extra = %v", extra)
+    }
+    if _, _, err := parseMixedArgs(fs, []string{"--timeout=soon"}); err == nil {
+        t.Error("bad duration should fail")
+    }
+}
+
+func TestBuildToolArguments(t *testing.T) {
+    tool := mcp.NewTool("t",
+        mcp.WithString("name", mcp.Required()),
+        mcp.WithNumber("count"),
+        mcp.WithBoolean("dry_run"),
+        mcp.WithArray("zones"),
+    )
+    got, err := buildToolArguments(tool.InputSchema, `{"name":"x"}`, [][2]string{{"count", "3"}, {"dry_run", "true"}, {"zones", "UTC, Asia/Tokyo"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := map[string]any{"name": "x", "count": 3.0, "dry_run": true, "zones": []any{"UTC", "Asia/Tokyo"}}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("arguments = %#v", got)
+    }
+    if _, err := buildToolArguments(tool.InputSchema, "", [][2]string{{"name", "x"}, {"colour", "red"}}); err == nil || !strings.Contains(err.Error(), "--count") {
+        t.Errorf("unknown argument: %v", err)
+    }
+    if _, err := buildToolArguments(tool.InputSchema, "", nil); err == nil || !strings.Contains(err.Error(), "--name") {
+        t.Errorf("missing required: %v", err)
+    }
+    if _, err := buildToolArguments(tool.InputSchema, "", [][2]string{{"name", "x"}, {"count", "many"}}); err == nil {
+        t.Error("non-numeric count should fail")
+    }
+}
+
+func TestRunCallOverHTTP(t *testing.T) {
+    ts := server.NewTestStreamableHTTPServer(callTestServer(t))
+    defer ts.Close()
+
+    var out, errOut bytes.Buffer
+    code := runCall([]string{"get_system_time", "--timezone=UTC", "--transport=http", "--url=" + ts.URL}, &out, &errOut)
+    if code != exitOK || !strings.HasSuffix(strings.TrimSpace(out.String()), "Z") {
+        t.Errorf("call = %d %q %q", code, out.String(), errOut.String())
+    }
+
+    out.Reset()
+    if code := runCall([]string{"--transport=http", "--url", ts.URL}, &out, &errOut); code != exitOK || !strings.Contains(out.String(), "get_system_time") {
+        t.Errorf("list = %d %q", code, out.String())
+    }
+
+    out.Reset()
+    errOut.Reset()
+    if code := runCall([]string{"kind", "--n=2", "--transport=http", "--url=" + ts.URL, "--json"}, &out, &errOut); code != exitOK || !strings.Contains(out.String(), `"content"`) {
+        t.Errorf("json = %d %q %q", code, out.String(), errOut.String())
+    }
+
+    errOut.Reset()
+    if code := runCall([]string{"get_system_time", "--timezone=Nowhere/X", "--transport=http", "--url=" + ts.URL}, &out, &errOut); code != exitFail || errOut.Len() == 0 {
+        t.Errorf("tool error = %d %q", code, errOut.String())
+    }
+    if code := runCall([]string{"missing", "--transport=http", "--url=" + ts.URL}, &out, &errOut); code != exitUsage {
+        t.Errorf("unknown tool = %d", code)
+    }
+}
+
+func TestRunCallOverSSE(t *testing.T) {
+    ts := server.NewTestServer(callTestServer(t))
+    defer ts.Close()
+
+    var out, errOut bytes.Buffer
+    if code := runCall([]string{"kind", "--n", "7", "--transport=sse", "--url=" + ts.URL + "/sse"}, &out, &errOut); code != exitOK || strings.TrimSpace(out.String()) != "number" {
+        t.Errorf("call = %d %q %q", code, out.String(), errOut.String())
+    }
+}
+
+func TestRunCallUnreachable(t *testing.T) {
+    ts := httptest.NewServer(nil)
+    ts.Close()
+    var out, errOut bytes.Buffer
+    if code := runCall([]string{"get_system_time", "--transport=http", "--url=" + ts.URL, "--timeout=2s"}, &out, &errOut); code != exitFail {
+        t.Errorf("unreachable server = %d %q", code, errOut.String())
+    }
+    if code := runCall([]string{"--transport=carrier-pigeon"}, &out, &errOut); code != exitFail {
+        t.Errorf("unknown transport = %d", code)
+    }
+}
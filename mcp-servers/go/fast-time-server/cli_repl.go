@@ -0,0 +1,453 @@
+// -*- coding: utf-8 -*-
+// cli_repl.go - the repl subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements `fast-time-server repl`, an interactive MCP client for
+// exploring a server by hand. It connects like `call` (or runs the server in
+// the same process with --transport=inprocess) and reads commands:
+//
+//   fast-time> tools
+//   fast-time> call convert_time time=2025-06-01T09:00:00 source_timezone=UTC target_timezone="America/New_York"
+//   fast-time> read time://current/world
+//   fast-time> trace on
+//
+// "trace on" prints every JSON-RPC message sent and received, with the time
+// each request took.
+
+package main
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/client"
+    "github.com/mark3labs/mcp-go/client/transport"
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+/* ------------------------------------------------------------------ */
+/*                            traffic trace                           */
+/* ------------------------------------------------------------------ */
+
+// trafficTracer prints JSON-RPC messages while enabled
+type trafficTracer struct {
+    mu  sync.Mutex
+    out io.Writer // nil while tracing is off
+}
+
+// setOutput turns tracing on (w != nil) or off
+func (tr *trafficTracer) setOutput(w io.Writer) {
+    tr.mu.Lock()
+    tr.out = w
+    tr.mu.Unlock()
+}
+
+// printf writes one trace line when tracing is on
+func (tr *trafficTracer) printf(format string, v ...any) {
+    tr.mu.Lock()
+    defer tr.mu.Unlock()
+    if tr.out != nil {
+        fmt.Fprintf(tr.out, format+"\n", v...)
+    }
+}
+
+// message renders a JSON-RPC message for the trace
+func (tr *trafficTracer) message(v any) string {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return fmt.Sprintf("<%v>", err)
+    }
+    return string(data)
+}
+
+// wrap returns t with its traffic traced
+func (tr *trafficTracer) wrap(t transport.Interface) transport.Interface {
+    return &tracedTransport{Interface: t, tr: tr}
+}
+
+// tracedTransport passes everything to the wrapped transport and traces it
+type tracedTransport struct {
+    transport.Interface
+    tr *trafficTracer
+}
+
+// SendRequest traces a request, its response and how long it took
+func (t *tracedTransport) SendRequest(ctx context.Context, req transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+    t.tr.printf("--> %s", t.tr.message(req))
+    start := time.Now()
+    resp, err := t.Interface.SendRequest(ctx, req)
+    elapsed := time.Since(start).Round(time.Microsecond)
+    if err != nil {
+        t.tr.printf("<-- error after %s: %v", elapsed, err)
+        return resp, err
+    }
+    t.tr.printf("<-- %s (%s)", t.tr.message(resp), elapsed)
+    return resp, nil
+}
+
+// SendNotification traces an outgoing notification
+func (t *tracedTransport) SendNotification(ctx context.Context, n mcp.JSONRPCNotification) error {
+    t.tr.printf("--> %s", t.tr.message(n))
+    return t.Interface.SendNotification(ctx, n)
+}
+
+// SetNotificationHandler traces incoming notifications
+func (t *tracedTransport) SetNotificationHandler(handler func(mcp.JSONRPCNotification)) {
+    t.Interface.SetNotificationHandler(func(n mcp.JSONRPCNotification) {
+        t.tr.printf("<-- %s", t.tr.message(n))
+        handler(n)
+    })
+}
+
+// SetRequestHandler traces requests from the server, such as sampling
+func (t *tracedTransport) SetRequestHandler(handler transport.RequestHandler) {
+    bidi, ok := t.Interface.(transport.BidirectionalInterface)
+    if !ok {
+        return
+    }
+    bidi.SetRequestHandler(func(ctx context.Context, req transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+        t.tr.printf("<-- %s", t.tr.message(req))
+        resp, err := handler(ctx, req)
+        if err == nil {
+            t.tr.printf("--> %s", t.tr.message(resp))
+        }
+        return resp, err
+    })
+}
+
+// SetProtocolVersion passes the negotiated version to HTTP transports
+func (t *tracedTransport) SetProtocolVersion(version string) {
+    if hc, ok := t.Interface.(transport.HTTPConnection); ok {
+        hc.SetProtocolVersion(version)
+    }
+}
+
+/* ------------------------------------------------------------------ */
+/*                                 repl                               */
+/* ------------------------------------------------------------------ */
+
+// replPrompt is printed before each command
+const replPrompt = "fast-time> "
+
+// replHelp lists the commands
+const replHelp = `Commands:
+  tools                          list tools
+  call TOOL [name=value ...]     call a tool; values are converted using its schema
+  resources                      list resources and resource templates
+  read URI                       read a resource
+  prompts                        list prompts
+  prompt NAME [name=value ...]   get a prompt
+  ping                           ping the server
+  trace on|off                   show the JSON-RPC traffic with timings
+  help                           show this help
+  quit                           leave (also Ctrl-D)`
+
+// errQuit ends the loop
+var errQuit = errors.New("quit")
+
+// replSession is an interactive session with one server
+type replSession struct {
+    c       *client.Client
+    tracer  *trafficTracer
+    timeout time.Duration
+    out     io.Writer
+}
+
+// runRepl implements the repl subcommand
+func runRepl(args []string, stdout, stderr io.Writer) int {
+    fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    var (
+        opts  clientOptions
+        trace bool
+    )
+    addClientFlags(fs, &opts)
+    fs.BoolVar(&trace, "trace", false, "Start with JSON-RPC tracing on")
+    fs.Usage = func() {
+        fmt.Fprintf(stderr, "Usage: %s repl [options]\n\nInteractive MCP client; type help at the prompt.\n\nOptions:\n", appName)
+        fs.PrintDefaults()
+    }
+    if err := fs.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return exitOK
+        }
+        return exitUsage
+    }
+    if fs.NArg() > 0 {
+        fmt.Fprintf(stderr, "Error: unexpected arguments %q\n", fs.Args())
+        return exitUsage
+    }
+
+    opts.tracer = &trafficTracer{}
+    if trace {
+        opts.tracer.setOutput(stdout)
+    }
+    c, err := connectClient(context.Background(), opts)
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitFail
+    }
+    defer c.Close()
+
+    fmt.Fprintf(stdout, "Connected to %s. Type help for commands.\n", opts.target())
+    rs := &replSession{c: c, tracer: opts.tracer, timeout: opts.timeout, out: stdout}
+    rs.loop(os.Stdin, true)
+    return exitOK
+}
+
+// loop reads and runs commands until EOF or quit
+func (rs *replSession) loop(in io.Reader, prompt bool) {
+    sc := bufio.NewScanner(in)
+    sc.Buffer(make([]byte, 64*1024), 1<<20)
+    for {
+        if prompt {
+            fmt.Fprint(rs.out, replPrompt)
+        }
+        if !sc.Scan() {
+            if prompt {
+                fmt.Fprintln(rs.out)
+            }
+            return
+        }
+        if err := rs.run(sc.Text()); err != nil {
+            if errors.Is(err, errQuit) {
+                return
+            }
+            fmt.Fprintln(rs.out, "Error:", err)
+        }
+    }
+}
+
+// run executes one command line
+func (rs *replSession) run(line string) error {
+    words, err := splitCommandLine(line)
+    if err != nil {
+        return err
+    }
+    if len(words) == 0 {
+        return nil
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+    defer cancel()
+
+    cmd, rest := strings.ToLower(words[0]), words[1:]
+    switch cmd {
+    case "help", "?":
+        fmt.Fprintln(rs.out, replHelp)
+    case "quit", "exit":
+        return errQuit
+    case "trace":
+        switch {
+        case len(rest) == 1 && rest[0] == "on":
+            rs.tracer.setOutput(rs.out)
+        case len(rest) == 1 && rest[0] == "off":
+            rs.tracer.setOutput(nil)
+        default:
+            return errors.New("usage: trace on|off")
+        }
+    case "ping":
+        start := time.Now()
+        if err := rs.c.Ping(ctx); err != nil {
+            return err
+        }
+        fmt.Fprintf(rs.out, "pong (%s)\n", time.Since(start).Round(time.Microsecond))
+    case "tools":
+        tools, err := listAllTools(ctx, rs.c)
+        if err != nil {
+            return err
+        }
+        for _, t := range tools {
+            fmt.Fprintf(rs.out, "%-32s %s\n", t.Name, t.Description)
+        }
+    case "call":
+        return rs.call(ctx, rest)
+    case "resources":
+        return rs.listResources(ctx)
+    case "read":
+        if len(rest) != 1 {
+            return errors.New("usage: read URI")
+        }
+        return rs.read(ctx, rest[0])
+    case "prompts":
+        res, err := rs.c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+        if err != nil {
+            return err
+        }
+        for _, p := range res.Prompts {
+            fmt.Fprintf(rs.out, "%-32s %s\n", p.Name, p.Description)
+        }
+    case "prompt":
+        return rs.prompt(ctx, rest)
+    default:
+        return fmt.Errorf("unknown command %q (type help)", words[0])
+    }
+    return nil
+}
+
+// splitAssignments turns name=value words into pairs
+func splitAssignments(words []string) ([][2]string, error) {
+    pairs := make([][2]string, 0, len(words))
+    for _, w := range words {
+        name, value, ok := strings.Cut(strings.TrimLeft(w, "-"), "=")
+        if !ok || name == "" {
+            return nil, fmt.Errorf("expected name=value, got %q", w)
+        }
+        pairs = append(pairs, [2]string{name, value})
+    }
+    return pairs, nil
+}
+
+// call runs "call TOOL name=value ..."
+func (rs *replSession) call(ctx context.Context, words []string) error {
+    if len(words) == 0 {
+        return errors.New("usage: call TOOL [name=value ...]")
+    }
+    pairs, err := splitAssignments(words[1:])
+    if err != nil {
+        return err
+    }
+    tools, err := listAllTools(ctx, rs.c)
+    if err != nil {
+        return err
+    }
+    for _, t := range tools {
+        if t.Name != words[0] {
+            continue
+        }
+        arguments, err := buildToolArguments(t.InputSchema, "", pairs)
+        if err != nil {
+            return err
+        }
+        req := mcp.CallToolRequest{}
+        req.Params.Name = t.Name
+        req.Params.Arguments = arguments
+        res, err := rs.c.CallTool(ctx, req)
+        if err != nil {
+            return err
+        }
+        printToolResult(res, false, rs.out, rs.out)
+        return nil
+    }
+    return fmt.Errorf("the server has no tool %q", words[0])
+}
+
+// listResources runs "resources"
+func (rs *replSession) listResources(ctx context.Context) error {
+    res, err := rs.c.ListResources(ctx, mcp.ListResourcesRequest{})
+    if err != nil {
+        return err
+    }
+    for _, r := range res.Resources {
+        fmt.Fprintf(rs.out, "%-32s %s\n", r.URI, r.Name)
+    }
+    templates, err := rs.c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+    if err != nil {
+        return err
+    }
+    for _, t := range templates.ResourceTemplates {
+        fmt.Fprintf(rs.out, "%-32s %s\n", t.URITemplate.Raw(), t.Name)
+    }
+    return nil
+}
+
+// read runs "read URI"
+func (rs *replSession) read(ctx context.Context, uri string) error {
+    req := mcp.ReadResourceRequest{}
+    req.Params.URI = uri
+    res, err := rs.c.ReadResource(ctx, req)
+    if err != nil {
+        return err
+    }
+    for _, content := range res.Contents {
+        switch c := content.(type) {
+        case mcp.TextResourceContents:
+            fmt.Fprintln(rs.out, c.Text)
+        case mcp.BlobResourceContents:
+            fmt.Fprintf(rs.out, "[%s, %d bytes base64]\n", c.MIMEType, len(c.Blob))
+        }
+    }
+    return nil
+}
+
+// prompt runs "prompt NAME name=value ..."
+func (rs *replSession) prompt(ctx context.Context, words []string) error {
+    if len(words) == 0 {
+        return errors.New("usage: prompt NAME [name=value ...]")
+    }
+    pairs, err := splitAssignments(words[1:])
+    if err != nil {
+        return err
+    }
+    req := mcp.GetPromptRequest{}
+    req.Params.Name = words[0]
+    req.Params.Arguments = make(map[string]string, len(pairs))
+    for _, kv := range pairs {
+        req.Params.Arguments[kv[0]] = kv[1]
+    }
+    res, err := rs.c.GetPrompt(ctx, req)
+    if err != nil {
+        return err
+    }
+    for _, m := range res.Messages {
+        if text, ok := m.Content.(mcp.TextContent); ok {
+            fmt.Fprintf(rs.out, "[%s] %s\n", m.Role, text.Text)
+        }
+    }
+    return nil
+}
+
+// splitCommandLine splits a line into words, honouring single and double
+// quotes and backslash escapes outside single quotes
+func splitCommandLine(line string) ([]string, error) {
+    var (
+        words   []string
+        cur     strings.Builder
+        inWord  bool
+        quote   rune
+        escaped bool
+    )
+    for _, r := range line {
+        switch {
+        case escaped:
+            cur.WriteRune(r)
+            escaped = false
+        case r == '\\' && quote != '\'':
+            escaped, inWord = true, true
+        case quote != 0:
+            if r == quote {
+                quote = 0
+            } else {
+                cur.WriteRune(r)
+            }
+        case r == '\'' || r == '"':
+            quote, inWord = r, true
+        case r == ' ' || r == '\t':
+            if inWord {
+                words = append(words, cur.String())
+                cur.Reset()
+                inWord = false
+            }
+        default:
+            cur.WriteRune(r)
+            inWord = true
+        }
+    }
+    if quote != 0 {
+        return nil, errors.New("unterminated quote")
+    }
+    if inWord {
+        words = append(words, cur.String())
+    }
+    return words, nil
+}
@@ -0,0 +1,75 @@
+// -*- coding: utf-8 -*-
+// cli_repl_test.go - Tests for the repl subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bytes"
+    "context"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+    got, err := splitCommandLine(`call convert_time time="2025-06-01 09:00" zone='Asia/Tokyo' a\ b ""`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []string{"call", "convert_time", "time=2025-06-01 09:00", "zone=Asia/Tokyo", "a b", ""}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("words = %q", got)
+    }
+    if _, err := splitCommandLine(`read "time://`); err == nil {
+        t.Error("unterminated quote should fail")
+    }
+}
+
+func TestReplSession(t *testing.T) {
+    tracer := &trafficTracer{}
+    c, err := connectClient(context.Background(), clientOptions{transport: "inprocess", tracer: tracer})
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer c.Close()
+
+    var out bytes.Buffer
+    rs := &replSession{c: c, tracer: tracer, timeout: 5 * time.Second, out: &out}
+    rs.loop(strings.NewReader(strings.Join([]string{
+        "tools",
+        "call convert_time time=2025-06-01T09:00:00Z source_timezone=UTC target_timezone=Asia/Tokyo",
+        "trace on",
+        "read time://formats",
+        "trace off",
+        "prompt compare_timezones timezones=UTC reference_time=2025-01-01T00:00:00Z",
+        "call convert_time oops",
+        "frobnicate",
+        "quit",
+        "tools",
+    }, "\n")), false)
+
+    got := out.String()
+    for _, want := range []string{
+        "get_system_time",
+        "2025-06-01T18:00:00+09:00",
+        `--> {"jsonrpc":"2.0","id":`,
+        `"method":"resources/read"`,
+        "Reference time: 2025-01-01T00:00:00Z",
+        `Error: expected name=value, got "oops"`,
+        `Error: unknown command "frobnicate"`,
+    } {
+        if !strings.Contains(got, want) {
+            t.Errorf("output lacks %q:\n%s", want, got)
+        }
+    }
+    if strings.Count(got, "--> ") != 1 {
+        t.Errorf("trace should cover only the read:\n%s", got)
+    }
+    if strings.Count(got, "get_system_time") != 1 {
+        t.Error("commands after quit should not run")
+    }
+}
@@ -0,0 +1,347 @@
+// -*- coding: utf-8 -*-
+// completion.go - the completion subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements `fast-time-server completion bash|zsh|fish`, which
+// prints a shell completion script:
+//
+//   source <(fast-time-server completion bash)      # bash, also in ~/.bashrc
+//   source <(fast-time-server completion zsh)       # zsh, after compinit
+//   fast-time-server completion fish | source       # fish
+//
+// The scripts are generated from this binary, so they cover its server
+// flags, the subcommands and their options, the tool names and arguments
+// accepted by `call`, and the values of transports, log levels and timezone
+// arguments. The zsh script reuses the bash one through bashcompinit.
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+
+    "github.com/mark3labs/mcp-go/mcp"
+
+    "fast-time-server/fasttime"
+)
+
+// cliSubcommands lists the subcommands in the order completion offers them
+var cliSubcommands = [][2]string{
+    {"call", "Call a tool on a running server"},
+    {"repl", "Interactive client for a running server"},
+    {"bridge", "Relay stdio to a remote server"},
+    {"doctor", "Check the deployment described by the server flags"},
+    {"validate-config", "Check -config files"},
+    {"completion", "Print a shell completion script"},
+    {"service", "Manage the Windows service"},
+}
+
+// completionShells are the shells completion writes scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// clientTransports are the transports accepted by call and repl
+var clientTransports = []string{"stdio", "sse", "http", "inprocess"}
+
+// completionSpec is what the scripts complete
+type completionSpec struct {
+    server []*flag.Flag // server flags, also taken by doctor and service install
+    client []*flag.Flag // options shared by call and repl
+    tools  []mcp.Tool   // sorted by name
+}
+
+// newCompletionSpec collects the flags of server, the client options and the
+// tools this binary serves
+func newCompletionSpec(fs *flag.FlagSet) completionSpec {
+    var spec completionSpec
+    fs.VisitAll(func(f *flag.Flag) { spec.server = append(spec.server, f) })
+
+    client := flag.NewFlagSet("client", flag.ContinueOnError)
+    addClientFlags(client, &clientOptions{})
+    client.VisitAll(func(f *flag.Flag) { spec.client = append(spec.client, f) })
+
+    if s, err := newQuietServer(); err == nil {
+        for _, t := range s.ListTools() {
+            spec.tools = append(spec.tools, t.Tool)
+        }
+    }
+    sort.Slice(spec.tools, func(i, j int) bool { return spec.tools[i].Name < spec.tools[j].Name })
+    return spec
+}
+
+// optionValues returns the values offered for an option, or nil when any
+// value goes; client selects the transports of call and repl
+func optionValues(name string, client bool) []string {
+    switch {
+    case name == "transport" && client:
+        return clientTransports
+    case name == "transport", name == "log-level":
+        return configEnums[name]
+    case name == "log-output":
+        return configEnums[name][1:] // without the empty default
+    case strings.Contains(name, "timezone"):
+        return fasttime.Timezones()
+    }
+    return nil
+}
+
+// toolArgumentNames returns the sorted argument names of a tool
+func toolArgumentNames(t mcp.Tool) []string {
+    names := make([]string, 0, len(t.InputSchema.Properties))
+    for name := range t.InputSchema.Properties {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// shortDescription returns the first sentence of a description
+func shortDescription(s string) string {
+    s, _, _ = strings.Cut(s, "\n")
+    if i := strings.Index(s, ". "); i > 0 {
+        s = s[:i]
+    }
+    return strings.TrimSuffix(strings.TrimSpace(s), ".")
+}
+
+// runCompletion implements the completion subcommand
+func runCompletion(server *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+    fs := flag.NewFlagSet("completion", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    fs.Usage = func() {
+        fmt.Fprintf(stderr, "Usage: %s completion bash|zsh|fish\n\n", appName)
+        fmt.Fprintf(stderr, "Prints a shell completion script. Load it with\n\n")
+        fmt.Fprintf(stderr, "  source <(%s completion bash)\n  source <(%s completion zsh)\n  %s completion fish | source\n", appName, appName, appName)
+    }
+    if err := fs.Parse(args); err != nil {
+        if err == flag.ErrHelp {
+            return exitOK
+        }
+        return exitUsage
+    }
+    if fs.NArg() != 1 {
+        fs.Usage()
+        return exitUsage
+    }
+
+    spec := newCompletionSpec(server)
+    switch fs.Arg(0) {
+    case "bash":
+        fmt.Fprintf(stdout, "# bash completion for %s\n# Load it with: source <(%s completion bash)\n\n", appName, appName)
+        writeBashCompletion(stdout, spec)
+    case "zsh":
+        fmt.Fprintf(stdout, "# zsh completion for %s\n# Load it with: source <(%s completion zsh)\n\n", appName, appName)
+        fmt.Fprintf(stdout, "autoload -U +X bashcompinit && bashcompinit\n\n")
+        writeBashCompletion(stdout, spec)
+    case "fish":
+        writeFishCompletion(stdout, spec)
+    default:
+        fmt.Fprintf(stderr, "Error: unsupported shell %q (use %s)\n", fs.Arg(0), strings.Join(completionShells, ", "))
+        return exitUsage
+    }
+    return exitOK
+}
+
+// flagNames returns the flags with the given dash prefix
+func flagNames(flags []*flag.Flag, dash string) string {
+    names := make([]string, len(flags))
+    for i, f := range flags {
+        names[i] = dash + f.Name
+    }
+    return strings.Join(names, " ")
+}
+
+// bashCompletionScript is the bash (and zsh) script. Bash splits
+// --name=value at "=", so values are completed both after "=" and after an
+// option on its own.
+const bashCompletionScript = `%[1]s_values() {
+    local opt="${1#-}"
+    opt="${opt#-}"
+    case "$opt" in
+        transport)
+            if [[ "$2" == call || "$2" == repl ]]; then echo "%[9]s"; else echo "%[8]s"; fi ;;
+        log-level) echo "%[10]s" ;;
+        log-output) echo "%[11]s" ;;
+        *timezone*) echo "%[7]s" ;;
+    esac
+}
+
+%[1]s_tool_args() {
+    case "$1" in
+%[12]s    esac
+}
+
+%[1]s() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local sub="" tool="" opt=""
+    if [[ $COMP_CWORD -gt 1 && " %[3]s " == *" ${COMP_WORDS[1]} "* ]]; then
+        sub="${COMP_WORDS[1]}"
+    fi
+    if [[ "$sub" == call && $COMP_CWORD -gt 2 && "${COMP_WORDS[2]}" != -* ]]; then
+        tool="${COMP_WORDS[2]}"
+    fi
+
+    if [[ "$cur" == "=" ]]; then
+        opt="$prev"
+        cur=""
+    elif [[ "$prev" == "=" ]]; then
+        opt="${COMP_WORDS[COMP_CWORD-2]}"
+    elif [[ "$prev" == -* ]]; then
+        opt="$prev"
+    fi
+    if [[ -n "$opt" ]]; then
+        local values
+        values="$(%[1]s_values "$opt" "$sub")"
+        if [[ -n "$values" ]]; then
+            COMPREPLY=($(compgen -W "$values" -- "$cur"))
+            return
+        fi
+    fi
+
+    if [[ "$cur" == -* ]]; then
+        case "$sub" in
+            call) COMPREPLY=($(compgen -W "%[5]s --args --json $(%[1]s_tool_args "$tool")" -- "$cur")) ;;
+            repl) COMPREPLY=($(compgen -W "%[5]s --trace" -- "$cur")) ;;
+            bridge) COMPREPLY=($(compgen -W "--to --auth-token" -- "$cur")) ;;
+            validate-config) COMPREPLY=($(compgen -W "-schema" -- "$cur")) ;;
+            completion) ;;
+            *) COMPREPLY=($(compgen -W "%[4]s" -- "$cur")) ;;
+        esac
+        return
+    fi
+
+    case "$sub" in
+        "")
+            if [[ $COMP_CWORD -eq 1 ]]; then
+                COMPREPLY=($(compgen -W "%[3]s" -- "$cur"))
+            elif [[ -n "$opt" ]]; then
+                COMPREPLY=($(compgen -f -- "$cur"))
+            fi ;;
+        call)
+            if [[ $COMP_CWORD -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "%[6]s" -- "$cur"))
+            fi ;;
+        doctor|validate-config)
+            COMPREPLY=($(compgen -f -- "$cur")) ;;
+        service)
+            if [[ $COMP_CWORD -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "%[14]s" -- "$cur"))
+            fi ;;
+        completion)
+            COMPREPLY=($(compgen -W "%[13]s" -- "$cur")) ;;
+    esac
+}
+
+complete -F %[1]s %[2]s
+`
+
+// writeBashCompletion writes the bash completion function for spec
+func writeBashCompletion(w io.Writer, spec completionSpec) {
+    subs := make([]string, len(cliSubcommands))
+    for i, s := range cliSubcommands {
+        subs[i] = s[0]
+    }
+    var tools []string
+    var toolArgs strings.Builder
+    for _, t := range spec.tools {
+        tools = append(tools, t.Name)
+        if args := toolArgumentNames(t); len(args) > 0 {
+            fmt.Fprintf(&toolArgs, "        %s) echo \"--%s\" ;;\n", t.Name, strings.Join(args, " --"))
+        }
+    }
+    fmt.Fprintf(w, bashCompletionScript,
+        "_"+strings.ReplaceAll(appName, "-", "_"),
+        appName,
+        strings.Join(subs, " "),
+        flagNames(spec.server, "-"),
+        flagNames(spec.client, "--"),
+        strings.Join(tools, " "),
+        strings.Join(fasttime.Timezones(), " "),
+        strings.Join(optionValues("transport", false), " "),
+        strings.Join(optionValues("transport", true), " "),
+        strings.Join(optionValues("log-level", false), " "),
+        strings.Join(optionValues("log-output", false), " "),
+        toolArgs.String(),
+        strings.Join(completionShells, " "),
+        strings.Join(serviceActions, " "),
+    )
+}
+
+// fishQuote quotes s for fish
+func fishQuote(s string) string {
+    return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// fishOption returns a fish complete line for option f of the form flag
+// (-o for single-dash options, -l for double-dash ones) when cond holds
+func fishOption(cond, form string, f *flag.Flag, client bool) string {
+    line := fmt.Sprintf("complete -c %s -n %s %s %s", appName, fishQuote(cond), form, f.Name)
+    switch {
+    case isBoolFlag(f):
+    case optionValues(f.Name, client) != nil:
+        line += " -xa " + fishQuote(strings.Join(optionValues(f.Name, client), " "))
+    case configType(f) == "string":
+        line += " -rF" // often a file
+    default:
+        line += " -x"
+    }
+    return line + " -d " + fishQuote(f.Usage)
+}
+
+// writeFishCompletion writes fish complete commands for spec
+func writeFishCompletion(w io.Writer, spec completionSpec) {
+    fmt.Fprintf(w, "# fish completion for %s\n# Load it with: %s completion fish | source\n\n", appName, appName)
+    fmt.Fprintf(w, "complete -c %s -f\n", appName)
+    for _, s := range cliSubcommands {
+        fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", appName, s[0], fishQuote(s[1]))
+    }
+
+    fmt.Fprintln(w, "\n# Server flags")
+    for _, f := range spec.server {
+        fmt.Fprintln(w, fishOption("__fish_use_subcommand; or __fish_seen_subcommand_from doctor service", "-o", f, false))
+    }
+
+    fmt.Fprintln(w, "\n# call and repl")
+    for _, f := range spec.client {
+        fmt.Fprintln(w, fishOption("__fish_seen_subcommand_from call repl", "-l", f, true))
+    }
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from call' -l args -x -d 'Tool arguments as one JSON object'\n", appName)
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from call' -l json -d 'Print the whole result as JSON'\n", appName)
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from repl' -l trace -d 'Print the JSON-RPC traffic'\n", appName)
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from bridge' -l to -x -d 'Remote server URL'\n", appName)
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from bridge' -l auth-token -x -d 'Bearer token'\n", appName)
+
+    names := make([]string, len(spec.tools))
+    for i, t := range spec.tools {
+        names[i] = t.Name
+    }
+    noTool := "__fish_seen_subcommand_from call; and not __fish_seen_subcommand_from " + strings.Join(names, " ")
+    for _, t := range spec.tools {
+        fmt.Fprintf(w, "complete -c %s -n %s -a %s -d %s\n", appName, fishQuote(noTool), t.Name, fishQuote(shortDescription(t.Description)))
+    }
+    for _, t := range spec.tools {
+        for _, arg := range toolArgumentNames(t) {
+            line := fmt.Sprintf("complete -c %s -n '__fish_seen_subcommand_from %s' -l %s -x", appName, t.Name, arg)
+            if values := optionValues(arg, true); values != nil {
+                line += " -a " + fishQuote(strings.Join(values, " "))
+            }
+            if prop, ok := t.InputSchema.Properties[arg].(map[string]any); ok {
+                if desc, ok := prop["description"].(string); ok {
+                    line += " -d " + fishQuote(shortDescription(desc))
+                }
+            }
+            fmt.Fprintln(w, line)
+        }
+    }
+
+    fmt.Fprintln(w, "\n# validate-config, completion and service")
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from validate-config' -F\n", appName)
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from validate-config' -o schema -d 'Print the configuration JSON Schema'\n", appName)
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from completion' -xa %s\n", appName, fishQuote(strings.Join(completionShells, " ")))
+    fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from service; and not __fish_seen_subcommand_from %s' -xa %s\n",
+        appName, strings.Join(serviceActions, " "), fishQuote(strings.Join(serviceActions, " ")))
+}
@@ -0,0 +1,108 @@
+// -*- coding: utf-8 -*-
+// completion_test.go - Tests for the completion subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bytes"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "fast-time-server/fasttime"
+)
+
+func TestOptionValues(t *testing.T) {
+    if got := optionValues("transport", true); strings.Join(got, " ") != "stdio sse http inprocess" {
+        t.Errorf("client transports = %v", got)
+    }
+    if got := optionValues("transport", false); len(got) != 6 || got[5] != "pipe" {
+        t.Errorf("server transports = %v", got)
+    }
+    if got := optionValues("log-output", false); got[0] != "stderr" {
+        t.Errorf("log-output = %v", got)
+    }
+    if got := optionValues("source_timezone", true); len(got) != len(fasttime.Timezones()) {
+        t.Errorf("source_timezone = %d values", len(got))
+    }
+    if optionValues("port", false) != nil {
+        t.Error("port takes any value")
+    }
+    if got := shortDescription("Convert time. Accepts ISO 8601.\nMore"); got != "Convert time" {
+        t.Errorf("shortDescription = %q", got)
+    }
+}
+
+func TestRunCompletionBash(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    if code := runCompletion(testServerFlags(), []string{"bash"}, &stdout, &stderr); code != exitOK {
+        t.Fatalf("exit %d: %s", code, stderr.String())
+    }
+    script := stdout.String()
+    for _, want := range []string{
+        "complete -F _fast_time_server fast-time-server",
+        "-compress -config -max-body-size -port -sse-keepalive -transport",
+        "get_system_time) echo \"--timezone\" ;;",
+        "Asia/Tokyo",
+        "stdio sse http inprocess",
+    } {
+        if !strings.Contains(script, want) {
+            t.Errorf("script lacks %q", want)
+        }
+    }
+
+    bash, err := exec.LookPath("bash")
+    if err != nil {
+        t.Skip("bash not installed")
+    }
+    path := filepath.Join(t.TempDir(), "completion.bash")
+    os.WriteFile(path, stdout.Bytes(), 0o600)
+    test := `source "$1"
+COMP_WORDS=(fast-time-server call convert_time --target_timezone = Europe/Be)
+COMP_CWORD=5
+_fast_time_server
+echo "${COMPREPLY[*]}"`
+    out, err := exec.Command(bash, "-c", test, "bash", path).CombinedOutput()
+    if err != nil || strings.TrimSpace(string(out)) != "Europe/Berlin" {
+        t.Errorf("completion = %q, %v", out, err)
+    }
+}
+
+func TestRunCompletionFishAndZsh(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    if code := runCompletion(testServerFlags(), []string{"fish"}, &stdout, &stderr); code != exitOK {
+        t.Fatalf("exit %d: %s", code, stderr.String())
+    }
+    fish := stdout.String()
+    for _, want := range []string{
+        "complete -c fast-time-server -n __fish_use_subcommand -a call -d 'Call a tool on a running server'",
+        "-o transport -xa 'stdio sse http dual rest pipe'",
+        "-o compress -d 'Compress responses'",
+        "-l transport -xa 'stdio sse http inprocess'",
+        "-n '__fish_seen_subcommand_from convert_time' -l source_timezone -x -a 'UTC America/New_York",
+    } {
+        if !strings.Contains(fish, want) {
+            t.Errorf("fish script lacks %q", want)
+        }
+    }
+
+    stdout.Reset()
+    if code := runCompletion(testServerFlags(), []string{"zsh"}, &stdout, &stderr); code != exitOK || !strings.Contains(stdout.String(), "bashcompinit") {
+        t.Errorf("zsh: exit %d\n%s", code, stdout.String())
+    }
+}
+
+func TestRunCompletionUsage(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    if code := runCompletion(testServerFlags(), nil, &stdout, &stderr); code != exitUsage {
+        t.Errorf("no shell: exit %d", code)
+    }
+    if code := runCompletion(testServerFlags(), []string{"tcsh"}, &stdout, &stderr); code != exitUsage || !strings.Contains(stderr.String(), "unsupported shell") {
+        t.Errorf("tcsh: exit %d, %q", code, stderr.String())
+    }
+}
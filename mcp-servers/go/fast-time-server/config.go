@@ -0,0 +1,295 @@
+// -*- coding: utf-8 -*-
+// config.go - configuration files and the validate-config subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets the server read its flags from a YAML (or JSON) file given
+// with -config. Keys are flag names without the dash and values have the
+// flag's type; flags given on the command line win over the file:
+//
+//   transport: http
+//   port: 9090
+//   sse-keepalive: 30s
+//   compress: true
+//
+// `fast-time-server validate-config FILE...` checks files against the same
+// rules without starting anything and reports each problem with its line and
+// column, so a bad file is caught before a rollout. `validate-config -schema`
+// prints the rules as a JSON Schema for editors and CI linters.
+
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "gopkg.in/yaml.v3"
+)
+
+// configSkip lists flags that cannot be set from a file
+var configSkip = map[string]bool{
+    "config": true,
+    "help":   true,
+}
+
+// configEnums lists the accepted values of flags with a fixed set
+var configEnums = map[string][]string{
+    "transport":    {"stdio", "sse", "http", "dual", "rest", "pipe"},
+    "log-level":    {"debug", "info", "warn", "warning", "error", "none", "off", "silent"},
+    "log-output":   {"", "stderr", "file", "syslog", "journald", "eventlog"},
+    "error-format": {"", "classic", "structured"},
+}
+
+// durationPattern matches the durations time.ParseDuration accepts
+const durationPattern = `^[-+]?([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`
+
+// configError is a problem at a position in a configuration file
+type configError struct {
+    line, column int // 0 when the position is unknown
+    msg          string
+}
+
+func (e configError) Error() string {
+    if e.line == 0 {
+        return e.msg
+    }
+    return fmt.Sprintf("%d:%d: %s", e.line, e.column, e.msg)
+}
+
+// in prefixes the error with the file it was found in
+func (e configError) in(path string) string {
+    if e.line == 0 {
+        return path + ": " + e.msg
+    }
+    return path + ":" + e.Error()
+}
+
+// configType returns the schema type of a flag: boolean, integer, duration
+// or string
+func configType(f *flag.Flag) string {
+    g, ok := f.Value.(flag.Getter)
+    if !ok {
+        return "string"
+    }
+    switch g.Get().(type) {
+    case bool:
+        return "boolean"
+    case int, int64, uint, uint64:
+        return "integer"
+    case time.Duration:
+        return "duration"
+    }
+    return "string"
+}
+
+// parseConfig checks a configuration document against the flags of fs and
+// returns its settings in file order as flag name and value pairs
+func parseConfig(fs *flag.FlagSet, data []byte) ([][2]string, []configError) {
+    var doc yaml.Node
+    if err := yaml.Unmarshal(data, &doc); err != nil {
+        return nil, []configError{{msg: err.Error()}}
+    }
+    if len(doc.Content) == 0 {
+        return nil, nil // empty file
+    }
+    root := doc.Content[0]
+    if root.Kind != yaml.MappingNode {
+        return nil, []configError{{root.Line, root.Column, "the configuration must be a mapping of flag names to values"}}
+    }
+
+    var (
+        settings [][2]string
+        errs     []configError
+        seen     = map[string]int{}
+    )
+    for i := 0; i+1 < len(root.Content); i += 2 {
+        key, val := root.Content[i], root.Content[i+1]
+        name := strings.TrimLeft(key.Value, "-")
+        f := fs.Lookup(name)
+        switch {
+        case f == nil || configSkip[name]:
+            errs = append(errs, configError{key.Line, key.Column, fmt.Sprintf("unknown option %q (see %s -help)", key.Value, appName)})
+            continue
+        case seen[name] != 0:
+            errs = append(errs, configError{key.Line, key.Column, fmt.Sprintf("%q is already set on line %d", name, seen[name])})
+            continue
+        }
+        seen[name] = key.Line
+        if err := checkConfigValue(f, val); err != "" {
+            errs = append(errs, configError{val.Line, val.Column, name + ": " + err})
+            continue
+        }
+        settings = append(settings, [2]string{name, val.Value})
+    }
+    return settings, errs
+}
+
+// checkConfigValue returns what is wrong with v as a value of f, or ""
+func checkConfigValue(f *flag.Flag, v *yaml.Node) string {
+    if v.Kind != yaml.ScalarNode {
+        return "expected a single value, not a list or mapping"
+    }
+    if v.Tag == "!!null" {
+        return "missing value"
+    }
+    switch configType(f) {
+    case "boolean":
+        if v.Tag != "!!bool" {
+            return fmt.Sprintf("expected true or false, got %q", v.Value)
+        }
+    case "integer":
+        if v.Tag != "!!int" {
+            return fmt.Sprintf("expected a whole number, got %q", v.Value)
+        }
+        if _, err := strconv.ParseInt(v.Value, 0, 64); err != nil {
+            return fmt.Sprintf("%q is out of range", v.Value)
+        }
+    case "duration":
+        if v.Tag == "!!int" && v.Value == "0" {
+            return ""
+        }
+        if _, err := time.ParseDuration(v.Value); err != nil {
+            return fmt.Sprintf("expected a duration such as 30s or 5m, got %q", v.Value)
+        }
+    default:
+        if choices, ok := configEnums[f.Name]; ok && !containsString(choices, v.Value) {
+            return fmt.Sprintf("%q is not one of %q", v.Value, choices)
+        }
+    }
+    return ""
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+    for _, v := range list {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
+
+// applyConfigFile sets the flags of fs from the file at path, leaving those
+// given on the command line alone
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    settings, errs := parseConfig(fs, data)
+    if len(errs) > 0 {
+        all := make([]error, len(errs))
+        for i, e := range errs {
+            all[i] = errors.New(e.in(path))
+        }
+        return errors.Join(all...)
+    }
+    explicit := map[string]bool{}
+    fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+    for _, kv := range settings {
+        if explicit[kv[0]] {
+            continue
+        }
+        if err := fs.Set(kv[0], kv[1]); err != nil {
+            return fmt.Errorf("%s: %s: %v", path, kv[0], err)
+        }
+    }
+    return nil
+}
+
+// configSchema describes the configuration file of fs as a JSON Schema
+func configSchema(fs *flag.FlagSet) map[string]interface{} {
+    props := map[string]interface{}{}
+    fs.VisitAll(func(f *flag.Flag) {
+        if configSkip[f.Name] {
+            return
+        }
+        p := map[string]interface{}{"description": f.Usage}
+        switch configType(f) {
+        case "boolean":
+            p["type"] = "boolean"
+            p["default"] = f.DefValue == "true"
+        case "integer":
+            p["type"] = "integer"
+            p["default"], _ = strconv.ParseInt(f.DefValue, 0, 64)
+        case "duration":
+            p["oneOf"] = []interface{}{
+                map[string]interface{}{"type": "string", "pattern": durationPattern},
+                map[string]interface{}{"const": 0},
+            }
+            p["default"] = f.DefValue
+        default:
+            p["type"] = "string"
+            p["default"] = f.DefValue
+            if choices, ok := configEnums[f.Name]; ok {
+                p["enum"] = choices
+            }
+        }
+        props[f.Name] = p
+    })
+    return map[string]interface{}{
+        "$schema":              "https://json-schema.org/draft/2020-12/schema",
+        "title":                appName + " configuration",
+        "description":          "Flag values for " + appName + " -config; keys are flag names without the dash",
+        "type":                 "object",
+        "properties":           props,
+        "additionalProperties": false,
+    }
+}
+
+// runValidateConfig implements the validate-config subcommand against the
+// server flags in server
+func runValidateConfig(server *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+    fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    printSchema := fs.Bool("schema", false, "Print the configuration JSON Schema and exit")
+    fs.Usage = func() {
+        fmt.Fprintf(stderr, "Usage: %s validate-config FILE...\n       %s validate-config -schema\n\n", appName, appName)
+        fmt.Fprintf(stderr, "Checks configuration files for -config and reports errors by line.\n\nOptions:\n")
+        fs.PrintDefaults()
+    }
+    if err := fs.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return exitOK
+        }
+        return exitUsage
+    }
+
+    if *printSchema {
+        data, _ := json.MarshalIndent(configSchema(server), "", "  ")
+        fmt.Fprintln(stdout, string(data))
+        return exitOK
+    }
+    if fs.NArg() == 0 {
+        fs.Usage()
+        return exitUsage
+    }
+
+    code := exitOK
+    for _, path := range fs.Args() {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            fmt.Fprintln(stderr, "Error:", err)
+            code = exitFail
+            continue
+        }
+        settings, errs := parseConfig(server, data)
+        for _, e := range errs {
+            fmt.Fprintln(stderr, e.in(path))
+        }
+        if len(errs) > 0 {
+            code = exitFail
+            continue
+        }
+        fmt.Fprintf(stdout, "%s: ok (%d settings)\n", path, len(settings))
+    }
+    return code
+}
@@ -0,0 +1,165 @@
+// -*- coding: utf-8 -*-
+// config_test.go - Tests for -config files and validate-config
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "flag"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "fast-time-server/fasttime"
+)
+
+// testServerFlags returns a small flag set shaped like the server's
+func testServerFlags() *flag.FlagSet {
+    fs := flag.NewFlagSet("server", flag.ContinueOnError)
+    fs.String("transport", "stdio", "Transport")
+    fs.Int("port", defaultPort, "TCP port")
+    fs.Int64("max-body-size", fasttime.DefaultConfig().MaxBodySize, "Largest body")
+    fs.Duration("sse-keepalive", 0, "Keep-alive interval")
+    fs.Bool("compress", false, "Compress responses")
+    fs.String("config", "", "Config file")
+    return fs
+}
+
+// writeConfig writes a configuration file and returns its path
+func writeConfig(t *testing.T, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestParseConfig(t *testing.T) {
+    settings, errs := parseConfig(testServerFlags(), []byte("transport: http\nport: 9090\nsse-keepalive: 30s\ncompress: true\nmax-body-size: 0x100000\n"))
+    if len(errs) != 0 || len(settings) != 5 || settings[1] != [2]string{"port", "9090"} {
+        t.Errorf("settings = %v, errors = %v", settings, errs)
+    }
+    if settings, errs := parseConfig(testServerFlags(), nil); len(settings) != 0 || len(errs) != 0 {
+        t.Errorf("empty file: %v, %v", settings, errs)
+    }
+
+    cases := []struct {
+        doc  string
+        want string
+    }{
+        {"port: \"9090\"", "1:7: port: expected a whole number"},
+        {"compress: yes", "1:11: compress: expected true or false"},
+        {"sse-keepalive: 30", "1:16: sse-keepalive: expected a duration"},
+        {"transport: carrier-pigeon", `"carrier-pigeon" is not one of`},
+        {"transport: http\nbogus: 1", `2:1: unknown option "bogus"`},
+        {"config: other.yaml", `unknown option "config"`},
+        {"port: 1\nport: 2", `2:1: "port" is already set on line 1`},
+        {"port: [1, 2]", "expected a single value"},
+        {"port:", "missing value"},
+        {"- port", "must be a mapping"},
+        {"port: 1\n  bad: indent", "yaml:"},
+    }
+    for _, c := range cases {
+        _, errs := parseConfig(testServerFlags(), []byte(c.doc))
+        if len(errs) != 1 || !strings.Contains(errs[0].Error(), c.want) {
+            t.Errorf("%q: errors = %v, want %q", c.doc, errs, c.want)
+        }
+    }
+
+    if _, errs := parseConfig(testServerFlags(), []byte("sse-keepalive: 0")); len(errs) != 0 {
+        t.Errorf("a bare 0 duration should be accepted: %v", errs)
+    }
+}
+
+func TestApplyConfigFile(t *testing.T) {
+    fs := testServerFlags()
+    if err := fs.Parse([]string{"-port=7000"}); err != nil {
+        t.Fatal(err)
+    }
+    path := writeConfig(t, "transport: sse\nport: 9090\nsse-keepalive: 15s\n")
+    if err := applyConfigFile(fs, path); err != nil {
+        t.Fatal(err)
+    }
+    get := func(name string) interface{} { return fs.Lookup(name).Value.(flag.Getter).Get() }
+    if get("transport") != "sse" || get("sse-keepalive") != 15*time.Second {
+        t.Errorf("transport = %v, sse-keepalive = %v", get("transport"), get("sse-keepalive"))
+    }
+    if get("port") != 7000 {
+        t.Errorf("the command line should win: port = %v", get("port"))
+    }
+
+    err := applyConfigFile(testServerFlags(), writeConfig(t, "port: high\ncompress: 1\n"))
+    if err == nil || !strings.Contains(err.Error(), "config.yaml:1:7:") || !strings.Contains(err.Error(), "config.yaml:2:11:") {
+        t.Errorf("err = %v", err)
+    }
+    if err := applyConfigFile(testServerFlags(), filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+        t.Error("a missing file should be an error")
+    }
+}
+
+func TestConfigSchema(t *testing.T) {
+    schema := configSchema(testServerFlags())
+    data, err := json.Marshal(schema)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var out struct {
+        AdditionalProperties bool                              `json:"additionalProperties"`
+        Properties           map[string]map[string]interface{} `json:"properties"`
+    }
+    if err := json.Unmarshal(data, &out); err != nil {
+        t.Fatal(err)
+    }
+    if out.AdditionalProperties || out.Properties["config"] != nil {
+        t.Errorf("schema = %s", data)
+    }
+    if p := out.Properties["port"]; p["type"] != "integer" || p["default"] != float64(defaultPort) {
+        t.Errorf("port = %v", p)
+    }
+    if p := out.Properties["compress"]; p["type"] != "boolean" || p["default"] != false {
+        t.Errorf("compress = %v", p)
+    }
+    if p := out.Properties["transport"]; len(p["enum"].([]interface{})) != 6 {
+        t.Errorf("transport = %v", p)
+    }
+    if p := out.Properties["sse-keepalive"]; p["oneOf"] == nil {
+        t.Errorf("sse-keepalive = %v", p)
+    }
+}
+
+func TestRunValidateConfig(t *testing.T) {
+    good := writeConfig(t, "transport: http\nport: 9090\n")
+    bad := writeConfig(t, "transport: http\nprot: 9090\n")
+
+    var stdout, stderr bytes.Buffer
+    if code := runValidateConfig(testServerFlags(), []string{good}, &stdout, &stderr); code != exitOK {
+        t.Errorf("good file: exit %d, %s", code, stderr.String())
+    }
+    if !strings.Contains(stdout.String(), "ok (2 settings)") {
+        t.Errorf("stdout = %q", stdout.String())
+    }
+
+    stdout.Reset()
+    stderr.Reset()
+    if code := runValidateConfig(testServerFlags(), []string{good, bad}, &stdout, &stderr); code != exitFail {
+        t.Errorf("bad file: exit %d", code)
+    }
+    if !strings.Contains(stderr.String(), bad+`:2:1: unknown option "prot"`) {
+        t.Errorf("stderr = %q", stderr.String())
+    }
+
+    stdout.Reset()
+    if code := runValidateConfig(testServerFlags(), []string{"-schema"}, &stdout, &stderr); code != exitOK || !json.Valid(stdout.Bytes()) {
+        t.Errorf("-schema: exit %d, %q", code, stdout.String())
+    }
+    if code := runValidateConfig(testServerFlags(), nil, &stdout, &stderr); code != exitUsage {
+        t.Errorf("no files: exit %d", code)
+    }
+}
@@ -0,0 +1,101 @@
+// -*- coding: utf-8 -*-
+// daemon.go - background mode
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file supports classic init scripts. The server runs in the
+// foreground by default, which is what systemd, Docker and other
+// supervisors expect. With -daemon it starts a copy of itself in a new
+// session with its standard streams on the null device, waits until that
+// copy is serving, prints its PID and exits: 0 once the server is ready,
+// 1 if it exited during startup (see its log). The log must go to -log-file,
+// syslog or journald. -pid-file is written by the server itself (see
+// fasttime/pidfile.go).
+
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "fast-time-server/fasttime"
+)
+
+// envDaemonFD names the descriptor a -daemon child reports readiness on
+const envDaemonFD = "FAST_TIME_DAEMON_FD"
+
+// daemonStartTimeout bounds how long -daemon waits for the server
+const daemonStartTimeout = time.Minute
+
+// checkDaemonFlags fails when a -daemon server could not work: stdio needs
+// the terminal, and a log on stderr would be lost
+func checkDaemonFlags(transport, logOutput, logFile string) error {
+    if t := strings.ToLower(transport); t == "" || t == "stdio" {
+        return errors.New("-daemon needs a network or pipe transport, not stdio")
+    }
+    if o := strings.ToLower(logOutput); o == "stderr" || (o == "" && logFile == "") {
+        return errors.New("-daemon would lose the log; add -log-file, or -log-output=syslog or journald")
+    }
+    return nil
+}
+
+// daemonArgs returns args without -daemon
+func daemonArgs(args []string) []string {
+    var out []string
+    for _, a := range args {
+        name, _, _, ok := splitOption(a)
+        if ok && name == "daemon" {
+            continue
+        }
+        out = append(out, a)
+    }
+    return out
+}
+
+// waitDaemon waits for the child's readiness report on ready and returns
+// the exit code of the -daemon command; exited reports the child's exit
+func waitDaemon(ready io.Reader, pid int, exited func() error, timeout time.Duration, stdout, stderr io.Writer) int {
+    got := make(chan bool, 1)
+    go func() {
+        buf := make([]byte, 16)
+        n, _ := ready.Read(buf)
+        got <- strings.TrimSpace(string(buf[:n])) == "ready"
+    }()
+    select {
+    case ok := <-got:
+        if ok {
+            fmt.Fprintf(stdout, "%s started in the background (pid %d)\n", appName, pid)
+            return exitOK
+        }
+        fmt.Fprintf(stderr, "Error: %s exited during startup (%v); see its log\n", appName, exited())
+    case <-time.After(timeout):
+        fmt.Fprintf(stderr, "Error: %s (pid %d) is not ready after %v; see its log\n", appName, pid, timeout)
+    }
+    return exitFail
+}
+
+// notifyDaemonParent tells a waiting -daemon parent that the server is ready
+// (set as Config.Ready)
+func notifyDaemonParent() {
+    v := os.Getenv(envDaemonFD)
+    if v == "" {
+        return
+    }
+    os.Unsetenv(envDaemonFD)
+    fd, err := strconv.Atoi(v)
+    if err != nil || fd < 3 {
+        fasttime.Logf("warn", "invalid %s=%q", envDaemonFD, v)
+        return
+    }
+    f := os.NewFile(uintptr(fd), "daemon-ready")
+    defer f.Close()
+    if _, err := f.WriteString("ready\n"); err != nil {
+        fasttime.Logf("warn", "cannot report readiness to the -daemon parent: %v", err)
+    }
+}
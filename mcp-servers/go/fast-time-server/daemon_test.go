@@ -0,0 +1,94 @@
+// -*- coding: utf-8 -*-
+// daemon_test.go - Tests for background mode
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bytes"
+    "errors"
+    "io"
+    "os"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestCheckDaemonFlags(t *testing.T) {
+    cases := []struct {
+        transport, logOutput, logFile string
+        ok                            bool
+    }{
+        {"stdio", "", "/var/log/fts.log", false},
+        {"http", "", "", false},
+        {"http", "stderr", "/var/log/fts.log", false},
+        {"http", "", "/var/log/fts.log", true},
+        {"sse", "syslog", "", true},
+        {"pipe", "journald", "", true},
+    }
+    for _, c := range cases {
+        err := checkDaemonFlags(c.transport, c.logOutput, c.logFile)
+        if (err == nil) != c.ok {
+            t.Errorf("checkDaemonFlags(%q, %q, %q) = %v", c.transport, c.logOutput, c.logFile, err)
+        }
+    }
+}
+
+func TestDaemonArgs(t *testing.T) {
+    got := daemonArgs([]string{"-transport=http", "-daemon", "--daemon=true", "-pid-file", "/run/fts.pid"})
+    want := []string{"-transport=http", "-pid-file", "/run/fts.pid"}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("daemonArgs = %q, want %q", got, want)
+    }
+}
+
+func TestWaitDaemon(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    exited := func() error { return errors.New("exit status 1") }
+
+    if code := waitDaemon(strings.NewReader("ready\n"), 42, exited, time.Second, &stdout, &stderr); code != exitOK {
+        t.Errorf("ready: exit %d", code)
+    }
+    if !strings.Contains(stdout.String(), "(pid 42)") {
+        t.Errorf("stdout = %q", stdout.String())
+    }
+
+    if code := waitDaemon(strings.NewReader(""), 42, exited, time.Second, &stdout, &stderr); code != exitFail {
+        t.Errorf("early exit: exit %d", code)
+    }
+    if !strings.Contains(stderr.String(), "exit status 1") {
+        t.Errorf("stderr = %q", stderr.String())
+    }
+
+    r, w := io.Pipe()
+    defer w.Close()
+    stderr.Reset()
+    if code := waitDaemon(r, 42, exited, 10*time.Millisecond, &stdout, &stderr); code != exitFail {
+        t.Errorf("timeout: exit %d", code)
+    }
+    if !strings.Contains(stderr.String(), "not ready") {
+        t.Errorf("stderr = %q", stderr.String())
+    }
+}
+
+func TestNotifyDaemonParent(t *testing.T) {
+    r, w, err := os.Pipe()
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer r.Close()
+    t.Setenv(envDaemonFD, strconv.Itoa(int(w.Fd())))
+    notifyDaemonParent()
+    buf := make([]byte, 16)
+    n, _ := r.Read(buf)
+    if string(buf[:n]) != "ready\n" {
+        t.Errorf("read %q", buf[:n])
+    }
+    if os.Getenv(envDaemonFD) != "" {
+        t.Error("notifyDaemonParent should report only once")
+    }
+}
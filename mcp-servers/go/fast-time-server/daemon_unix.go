@@ -0,0 +1,56 @@
+// -*- coding: utf-8 -*-
+// daemon_unix.go - background mode and process checks
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file starts the -daemon child in its own session, detached from the
+// terminal.
+
+//go:build !windows
+
+package main
+
+import (
+    "io"
+    "os"
+    "os/exec"
+    "syscall"
+)
+
+// startDaemon starts this server in the background and returns the exit
+// code of the -daemon command
+func startDaemon(stdout, stderr io.Writer) int {
+    exe, err := os.Executable()
+    if err != nil {
+        return daemonError(stderr, err)
+    }
+    null, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+    if err != nil {
+        return daemonError(stderr, err)
+    }
+    defer null.Close()
+    r, w, err := os.Pipe()
+    if err != nil {
+        return daemonError(stderr, err)
+    }
+    defer r.Close()
+
+    cmd := exec.Command(exe, daemonArgs(os.Args[1:])...)
+    cmd.Stdin, cmd.Stdout, cmd.Stderr = null, null, null
+    cmd.ExtraFiles = []*os.File{w} // becomes fd 3
+    cmd.Env = append(os.Environ(), envDaemonFD+"=3")
+    cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+    err = cmd.Start()
+    w.Close()
+    if err != nil {
+        return daemonError(stderr, err)
+    }
+    return waitDaemon(r, cmd.Process.Pid, cmd.Wait, daemonStartTimeout, stdout, stderr)
+}
+
+// daemonError reports a failure to start the background server
+func daemonError(stderr io.Writer, err error) int {
+    io.WriteString(stderr, "Error: -daemon: "+err.Error()+"\n")
+    return exitFail
+}
@@ -0,0 +1,23 @@
+// -*- coding: utf-8 -*-
+// daemon_windows.go - the -daemon stub for Windows
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Windows has no sessions to detach into; a background server there is a
+// service (`fast-time-server service install`).
+
+//go:build windows
+
+package main
+
+import (
+    "fmt"
+    "io"
+)
+
+// startDaemon points to the service subcommand
+func startDaemon(_, stderr io.Writer) int {
+    fmt.Fprintf(stderr, "Error: -daemon is not available on Windows; use `%s service install`\n", appName)
+    return exitUsage
+}
@@ -0,0 +1,97 @@
+// -*- coding: utf-8 -*-
+// acl.go - IP allowlist/denylist for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file restricts the HTTP transports to known networks. Networks come
+// from -allow-ips / -deny-ips and from an optional JSON file (-ip-acl-file):
+//
+//   {"allow": ["10.0.0.0/8", "192.168.1.20"], "deny": ["10.66.0.0/16"]}
+//
+// A denied address is always rejected; when an allowlist is configured, only
+// addresses on it get through. The check runs before authentication, against
+// the client address resolved by realIPMiddleware when -trusted-proxies is set.
+
+package fasttime
+
+import (
+    "encoding/json"
+    "fmt"
+    "net"
+    "net/http"
+    "os"
+    "strings"
+)
+
+// ipACL is a pair of allow and deny lists
+type ipACL struct {
+    allow ipNets
+    deny  ipNets
+}
+
+// loadIPACL builds an ipACL from the flag values and an optional JSON file
+func loadIPACL(allowSpec, denySpec, path string) (*ipACL, error) {
+    allow, err := parseIPNets(allowSpec)
+    if err != nil {
+        return nil, fmt.Errorf("allow list: %w", err)
+    }
+    deny, err := parseIPNets(denySpec)
+    if err != nil {
+        return nil, fmt.Errorf("deny list: %w", err)
+    }
+    acl := &ipACL{allow: allow, deny: deny}
+
+    if path != "" {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return nil, err
+        }
+        var file struct {
+            Allow []string `json:"allow"`
+            Deny  []string `json:"deny"`
+        }
+        if err := json.Unmarshal(data, &file); err != nil {
+            return nil, fmt.Errorf("parse %s: %w", path, err)
+        }
+        more, err := parseIPNets(strings.Join(file.Allow, ","))
+        if err != nil {
+            return nil, fmt.Errorf("%s allow list: %w", path, err)
+        }
+        acl.allow = append(acl.allow, more...)
+        if more, err = parseIPNets(strings.Join(file.Deny, ",")); err != nil {
+            return nil, fmt.Errorf("%s deny list: %w", path, err)
+        }
+        acl.deny = append(acl.deny, more...)
+    }
+    return acl, nil
+}
+
+// enabled reports whether any rule is configured
+func (a *ipACL) enabled() bool {
+    return len(a.allow) > 0 || len(a.deny) > 0
+}
+
+// permits reports whether the address may use the server
+func (a *ipACL) permits(addr string) bool {
+    ip := net.ParseIP(remoteIP(addr))
+    if ip == nil {
+        return false
+    }
+    if a.deny.contains(ip) {
+        return false
+    }
+    return len(a.allow) == 0 || a.allow.contains(ip)
+}
+
+// aclMiddleware rejects requests from addresses the ACL does not permit
+func aclMiddleware(acl *ipACL, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !acl.permits(r.RemoteAddr) {
+            logAt(logWarn, "rejected request from %s to %s by IP access list", r.RemoteAddr, r.URL.Path)
+            writeJSONError(w, http.StatusForbidden, "Forbidden")
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
@@ -0,0 +1,99 @@
+// -*- coding: utf-8 -*-
+// acl_test.go - Tests for the IP allowlist/denylist
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestIPACLPermits(t *testing.T) {
+    acl, err := loadIPACL("10.0.0.0/8,::1", "10.66.0.0/16", "")
+    if err != nil {
+        t.Fatalf("load error: %v", err)
+    }
+    tests := map[string]bool{
+        "10.1.2.3:5000":    true,
+        "10.66.1.1:5000":   false, // deny wins over allow
+        "203.0.113.7:5000": false, // not on the allowlist
+        "[::1]:5000":       true,
+        "198.51.100.1":     false,
+        "not-an-address":   false,
+    }
+    for addr, want := range tests {
+        if got := acl.permits(addr); got != want {
+            t.Errorf("permits(%q) = %v, want %v", addr, got, want)
+        }
+    }
+
+    denyOnly, _ := loadIPACL("", "203.0.113.0/24", "")
+    if !denyOnly.permits("198.51.100.1:1") || denyOnly.permits("203.0.113.9:1") {
+        t.Error("deny-only list should permit everything except denied networks")
+    }
+    if empty, _ := loadIPACL("", "", ""); empty.enabled() {
+        t.Error("empty ACL should be disabled")
+    }
+}
+
+func TestLoadIPACLFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "acl.json")
+    if err := os.WriteFile(path, []byte(`{"allow": ["192.168.0.0/16"], "deny": ["192.168.9.9"]}`), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    acl, err := loadIPACL("10.0.0.0/8", "", path)
+    if err != nil {
+        t.Fatalf("load error: %v", err)
+    }
+    if !acl.permits("10.0.0.1:1") || !acl.permits("192.168.1.1:1") || acl.permits("192.168.9.9:1") {
+        t.Errorf("flag and file networks should be merged: %+v", acl)
+    }
+
+    if err := os.WriteFile(path, []byte(`{"allow": ["not-a-network"]}`), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := loadIPACL("", "", path); err == nil {
+        t.Error("expected error for invalid network in file")
+    }
+    if _, err := loadIPACL("", "", filepath.Join(t.TempDir(), "missing.json")); err == nil {
+        t.Error("expected error for missing file")
+    }
+}
+
+func TestACLMiddleware(t *testing.T) {
+    acl, _ := loadIPACL("10.0.0.0/8", "", "")
+    h := aclMiddleware(acl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+    }))
+
+    for addr, want := range map[string]int{
+        "10.0.0.1:4000":    http.StatusOK,
+        "203.0.113.7:4000": http.StatusForbidden,
+    } {
+        req := httptest.NewRequest(http.MethodGet, "/health", nil)
+        req.RemoteAddr = addr
+        rec := httptest.NewRecorder()
+        h.ServeHTTP(rec, req)
+        if rec.Code != want {
+            t.Errorf("%s: got %d, want %d", addr, rec.Code, want)
+        }
+    }
+
+    // Behind a trusted proxy the forwarded client address is checked
+    proxies, _ := parseIPNets("10.0.0.0/8")
+    h = realIPMiddleware(proxies, h)
+    req := httptest.NewRequest(http.MethodGet, "/health", nil)
+    req.RemoteAddr = "10.0.0.1:4000"
+    req.Header.Set("X-Forwarded-For", "203.0.113.7")
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    if rec.Code != http.StatusForbidden {
+        t.Errorf("forwarded client outside the allowlist: got %d", rec.Code)
+    }
+}
@@ -0,0 +1,467 @@
+// -*- coding: utf-8 -*-
+// admin.go - admin API for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file serves /admin/* for operators. Every route requires the admin
+// token (-admin-token or ADMIN_TOKEN) and the API is only mounted when one is
+// configured. It reports runtime state, adjusts it, and manages timezone
+// aliases and, through holidays.go, custom holiday calendars:
+//
+//   GET    /admin/config           effective configuration (tokens redacted)
+//   GET    /admin/status           health of the store, NTP and calendar backends
+//                                  (backends.go)
+//   GET    /admin/sessions         connected MCP sessions
+//   GET    /admin/stats/tools      per-tool call counts and latency
+//   DELETE /admin/stats/tools      reset the tool counters
+//   GET    /admin/usage            requests, tool mix and errors per client
+//   DELETE /admin/usage            reset the usage counters
+//   GET    /admin/calendars        loaded market and custom holiday calendars
+//   GET    /admin/clock            clock mode and current time
+//   PUT    /admin/clock            freeze the clock {"time": "2025-01-01T00:00:00Z"}
+//                                  or shift it {"offset": "+3h"}
+//   DELETE /admin/clock            return to the wall clock
+//   POST   /admin/clock/advance    move a frozen clock {"by": "1h30m"}
+//   GET    /admin/log-level        current log level
+//   PUT    /admin/log-level        change the log level {"level": "debug"}
+//   POST   /admin/tokens/reload    re-read -auth-token-file / -admin-token-file
+//   GET    /admin/dashboard/data   live data for the /dashboard page
+//   GET    /admin/aliases          list aliases
+//   GET    /admin/aliases/{name}   show one alias
+//   PUT    /admin/aliases/{name}   create or replace an alias {"timezone": "..."}
+//   DELETE /admin/aliases/{name}   delete an alias
+//   GET    /admin/tzdata           timezone database in use (tzdata.go)
+//   POST   /admin/tzdata/reload    load a new release from -tzdata-dir
+//   GET    /admin/tzcache          timezone cache size and hit rate (tzcache.go)
+//   DELETE /admin/tzcache          empty the timezone cache
+
+package fasttime
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "time"
+)
+
+// adminPathPrefix is the URL prefix served by the admin handler
+const adminPathPrefix = "/admin/"
+
+// secretFlags are never echoed by GET /admin/config; -outbound-proxy may
+// carry proxy credentials
+var secretFlags = map[string]bool{"auth-token": true, "admin-token": true, "webhook-secret": true, "outbound-proxy": true}
+
+// adminMiddleware serves /admin/* with admin auth, the dashboard page, and
+// passes everything else to next, counting every request for the dashboard.
+// Like debugMiddleware it sits in front of the regular auth chain.
+func adminMiddleware(adminToken *bearerToken, next http.Handler) http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/admin/config", handleAdminConfig)
+    mux.HandleFunc("/admin/status", handleAdminStatus)
+    mux.HandleFunc("/admin/sessions", handleAdminSessions)
+    mux.HandleFunc("/admin/stats/tools", handleAdminToolStats)
+    mux.HandleFunc("/admin/usage", handleAdminUsage)
+    mux.HandleFunc("/admin/calendars", handleAdminCalendars)
+    mux.HandleFunc("/admin/clock", handleAdminClock)
+    mux.HandleFunc("/admin/clock/advance", handleAdminClockAdvance)
+    mux.HandleFunc("/admin/log-level", handleAdminLogLevel)
+    mux.HandleFunc("/admin/tokens/reload", handleAdminReloadTokens)
+    mux.HandleFunc("/admin/dashboard/data", handleDashboardData)
+    mux.HandleFunc("/admin/aliases", handleAdminAliases)
+    mux.HandleFunc("/admin/aliases/", handleAdminAlias)
+    mux.HandleFunc("/admin/holidays", handleAdminHolidays)
+    mux.HandleFunc("/admin/holidays/", handleAdminHolidayCalendar)
+    mux.HandleFunc("/admin/tzdata", handleAdminTzdata)
+    mux.HandleFunc("/admin/tzdata/reload", handleAdminTzdataReload)
+    mux.HandleFunc("/admin/tzcache", handleAdminTzCache)
+    mux.HandleFunc("/admin/workers", handleAdminWorkers)
+    mux.HandleFunc("/admin/slow", handleAdminSlow)
+
+    adminHandler := loggingHTTPMiddleware(adminAuthMiddleware(adminToken, mux))
+
+    dashboard := loggingHTTPMiddleware(http.HandlerFunc(handleDashboard))
+
+    return requests.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch {
+        case strings.HasPrefix(r.URL.Path, adminPathPrefix):
+            adminHandler.ServeHTTP(w, r)
+        case r.URL.Path == dashboardPath:
+            // The page is a static shell; its data comes from /admin/dashboard/data
+            dashboard.ServeHTTP(w, r)
+        default:
+            next.ServeHTTP(w, r)
+        }
+    }))
+}
+
+// flagValues returns the settings of cfg by flag name with secrets redacted
+func flagValues(cfg Config) map[string]string {
+    out := configValues(cfg)
+    for name := range secretFlags {
+        if out[name] != "" {
+            out[name] = "[redacted]"
+        }
+    }
+    return out
+}
+
+// handleAdminConfig handles GET /admin/config
+func handleAdminConfig(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    tokens := make(map[string]interface{}, len(liveTokens))
+    for role, t := range liveTokens {
+        tokens[role] = map[string]interface{}{
+            "enabled":    t.enabled(),
+            "reloadable": t.reloadable(),
+        }
+    }
+    if callers.enabled() {
+        tokens["scoped"] = map[string]interface{}{
+            "enabled":    true,
+            "reloadable": true,
+            "count":      callers.count(),
+        }
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "name":       appName,
+        "version":    appVersion,
+        "started_at": startTime.UTC().Format(time.RFC3339),
+        "log_level":  logLevel().String(),
+        "flags":      flagValues(runningConfig),
+        "tokens":     tokens,
+        "persistent": store.Persistent(),
+    })
+}
+
+// handleAdminSessions handles GET /admin/sessions
+func handleAdminSessions(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    now := time.Now()
+    list := sessions.list()
+    out := make([]map[string]interface{}, 0, len(list))
+    for _, info := range list {
+        entry := map[string]interface{}{
+            "id":               info.ID,
+            "connected_at":     info.ConnectedAt.Format(time.RFC3339),
+            "client_name":      info.ClientName,
+            "client_version":   info.ClientVersion,
+            "protocol_version": info.ProtocolVersion,
+            "inflight":         inflight.countFor(info.ID),
+            "timers":           timers.countFor(info.ID),
+            "subscriptions":    resourceSubs.countFor(info.ID),
+        }
+        if idle, ok := sseConns.sessionIdle(info.ID, now); ok {
+            entry["sse_idle_seconds"] = int(idle.Seconds())
+        }
+        out = append(out, entry)
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "sessions":    out,
+        "count":       len(out),
+        "sse_streams": sseConns.active(),
+    })
+}
+
+// handleAdminToolStats handles GET and DELETE /admin/stats/tools
+func handleAdminToolStats(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodGet:
+        stats := toolStats.snapshot()
+        writeJSON(w, http.StatusOK, map[string]interface{}{
+            "tools": stats,
+            "count": len(stats),
+        })
+    case http.MethodDelete:
+        toolStats.reset()
+        logAt(logInfo, "admin: tool statistics reset")
+        w.WriteHeader(http.StatusNoContent)
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
+
+// handleAdminUsage handles GET and DELETE /admin/usage
+func handleAdminUsage(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodGet:
+        clients := usage.snapshot("")
+        writeJSON(w, http.StatusOK, map[string]interface{}{
+            "clients": clients,
+            "count":   len(clients),
+        })
+    case http.MethodDelete:
+        usage.reset()
+        logAt(logInfo, "admin: usage statistics reset")
+        w.WriteHeader(http.StatusNoContent)
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
+
+// handleAdminCalendars handles GET /admin/calendars
+func handleAdminCalendars(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    markets := make([]map[string]interface{}, 0, len(exchanges))
+    for _, code := range exchangeCodes() {
+        ex := exchanges[code]
+        markets = append(markets, map[string]interface{}{
+            "code":           ex.code,
+            "name":           ex.name,
+            "timezone":       ex.tz,
+            "holidays":       len(ex.holidays),
+            "early_closes":   len(ex.halfDays),
+            "calendar_years": marketCalendarYears,
+        })
+    }
+
+    calendars, err := store.ListCalendars()
+    if err != nil {
+        writeJSONError(w, http.StatusInternalServerError, err.Error())
+        return
+    }
+    custom := make([]map[string]interface{}, 0, len(calendars))
+    for _, c := range calendars {
+        custom = append(custom, map[string]interface{}{
+            "name":       c.Name,
+            "holidays":   len(c.Holidays),
+            "updated_at": c.UpdatedAt,
+        })
+    }
+
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "markets": markets,
+        "custom":  custom,
+    })
+}
+
+// handleAdminClock handles GET, PUT and DELETE /admin/clock
+func handleAdminClock(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodGet:
+        writeJSON(w, http.StatusOK, clock.status())
+
+    case http.MethodPut:
+        var body struct {
+            Time   string `json:"time"`
+            Offset string `json:"offset"`
+        }
+        if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+            return
+        }
+        if body.Offset != "" {
+            if body.Time != "" {
+                writeJSONError(w, http.StatusBadRequest, "Set either time or offset, not both")
+                return
+            }
+            d, err := parseClockOffset(body.Offset)
+            if err != nil {
+                writeJSONError(w, http.StatusBadRequest, err.Error())
+                return
+            }
+            clock.shift(d)
+            logAt(logInfo, "admin: clock shifted by %s", formatClockOffset(d))
+            writeJSON(w, http.StatusOK, clock.status())
+            return
+        }
+        t, err := time.Parse(time.RFC3339, body.Time)
+        if err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Invalid time: use RFC3339, e.g. 2025-01-01T00:00:00Z")
+            return
+        }
+        clock.set(t)
+        logAt(logInfo, "admin: clock frozen at %s", t.Format(time.RFC3339))
+        writeJSON(w, http.StatusOK, clock.status())
+
+    case http.MethodDelete:
+        clock.reset()
+        logAt(logInfo, "admin: clock returned to real time")
+        writeJSON(w, http.StatusOK, clock.status())
+
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
+
+// handleAdminClockAdvance handles POST /admin/clock/advance
+func handleAdminClockAdvance(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    var body struct {
+        By string `json:"by"`
+    }
+    if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+        writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+    d, err := time.ParseDuration(body.By)
+    if err != nil {
+        writeJSONError(w, http.StatusBadRequest, "Invalid duration: use Go syntax, e.g. 90m or 24h")
+        return
+    }
+    if !clock.advance(d) {
+        writeJSONError(w, http.StatusConflict, "Clock is not frozen: set it with PUT /admin/clock or -mock-time")
+        return
+    }
+    logAt(logInfo, "admin: clock advanced by %s", d)
+    writeJSON(w, http.StatusOK, clock.status())
+}
+
+// handleAdminLogLevel handles GET and PUT /admin/log-level
+func handleAdminLogLevel(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodGet:
+        writeJSON(w, http.StatusOK, map[string]string{"level": logLevel().String()})
+
+    case http.MethodPut:
+        var body struct {
+            Level string `json:"level"`
+        }
+        if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+            return
+        }
+        l, ok := lookupLvl(body.Level)
+        if !ok {
+            writeJSONError(w, http.StatusBadRequest, "Invalid log level: use debug, info, warn, error or none")
+            return
+        }
+        prev := logLevel()
+        setLogLevel(l)
+        logAt(logInfo, "admin: log level %s -> %s", prev, l)
+        writeJSON(w, http.StatusOK, map[string]string{"level": l.String(), "previous": prev.String()})
+
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
+
+// handleAdminReloadTokens handles POST /admin/tokens/reload
+func handleAdminReloadTokens(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    roles := make([]string, 0, len(liveTokens))
+    for role := range liveTokens {
+        roles = append(roles, role)
+    }
+    sort.Strings(roles)
+
+    code := http.StatusOK
+    results := make(map[string]string, len(roles))
+    for _, role := range roles {
+        t := liveTokens[role]
+        if !t.reloadable() {
+            results[role] = "not reloadable"
+            continue
+        }
+        changed, err := t.reload()
+        switch {
+        case err != nil:
+            logAt(logError, "admin: reloading %s token: %v", role, err)
+            results[role] = "error: " + err.Error()
+            code = http.StatusInternalServerError
+        case changed:
+            logAt(logInfo, "admin: %s token reloaded", role)
+            results[role] = "reloaded"
+        default:
+            results[role] = "unchanged"
+        }
+    }
+    if callers.enabled() {
+        if n, err := callers.reload(); err != nil {
+            logAt(logError, "admin: reloading scoped tokens: %v", err)
+            results["scoped"] = "error: " + err.Error()
+            code = http.StatusInternalServerError
+        } else {
+            logAt(logInfo, "admin: %d scoped token(s) reloaded", n)
+            results["scoped"] = fmt.Sprintf("reloaded (%d tokens)", n)
+        }
+    }
+    writeJSON(w, code, map[string]interface{}{"tokens": results})
+}
+
+// handleAdminAliases handles GET /admin/aliases
+func handleAdminAliases(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    aliases := tzAliases.list()
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "aliases":    aliases,
+        "count":      len(aliases),
+        "persistent": tzAliases.persistent(),
+    })
+}
+
+// handleAdminAlias handles GET, PUT and DELETE /admin/aliases/{name}
+func handleAdminAlias(w http.ResponseWriter, r *http.Request) {
+    name := strings.TrimPrefix(r.URL.Path, "/admin/aliases/")
+    if name == "" || strings.Contains(name, "/") {
+        writeJSONError(w, http.StatusNotFound, "Alias not specified")
+        return
+    }
+
+    switch r.Method {
+    case http.MethodGet:
+        a, ok := tzAliases.get(name)
+        if !ok {
+            writeJSONError(w, http.StatusNotFound, "Unknown alias: "+name)
+            return
+        }
+        writeJSON(w, http.StatusOK, a)
+
+    case http.MethodPut:
+        var body struct {
+            Timezone string `json:"timezone"`
+        }
+        if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+            return
+        }
+        a, err := tzAliases.set(name, body.Timezone)
+        switch {
+        case errors.Is(err, errAliasReadOnly):
+            writeJSONError(w, http.StatusConflict, err.Error())
+        case err != nil:
+            writeJSONError(w, http.StatusBadRequest, err.Error())
+        default:
+            logAt(logInfo, "admin: alias %s -> %s", a.Name, a.Timezone)
+            writeJSON(w, http.StatusOK, a)
+        }
+
+    case http.MethodDelete:
+        found, err := tzAliases.remove(name)
+        switch {
+        case errors.Is(err, errAliasReadOnly):
+            writeJSONError(w, http.StatusConflict, err.Error())
+        case err != nil:
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+        case !found:
+            writeJSONError(w, http.StatusNotFound, "Unknown alias: "+name)
+        default:
+            logAt(logInfo, "admin: alias %s deleted", name)
+            w.WriteHeader(http.StatusNoContent)
+        }
+
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
@@ -0,0 +1,229 @@
+// -*- coding: utf-8 -*-
+// admin_test.go - Tests for the admin API
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestAdminMiddlewareAuth(t *testing.T) {
+    next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusTeapot)
+    })
+    h := adminMiddleware(newBearerToken("admin-secret"), next)
+
+    tests := []struct {
+        path, auth string
+        code       int
+    }{
+        {"/admin/aliases", "", http.StatusUnauthorized},
+        {"/admin/aliases", "Bearer wrong", http.StatusUnauthorized},
+        {"/admin/aliases", "Bearer admin-secret", http.StatusOK},
+        {"/api/v1/time", "", http.StatusTeapot},
+    }
+    for _, tt := range tests {
+        req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+        if tt.auth != "" {
+            req.Header.Set("Authorization", tt.auth)
+        }
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        if w.Code != tt.code {
+            t.Errorf("%s (%q): status = %d, want %d", tt.path, tt.auth, w.Code, tt.code)
+        }
+    }
+}
+
+func TestAdminAliasCRUD(t *testing.T) {
+    h := adminMiddleware(newBearerToken("admin-secret"), http.NotFoundHandler())
+    do := func(method, path, body string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(method, path, strings.NewReader(body))
+        req.Header.Set("Authorization", "Bearer admin-secret")
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        return w
+    }
+    defer func() { _, _ = tzAliases.remove("Team-West") }()
+
+    if w := do(http.MethodPut, "/admin/aliases/Team-West", `{"timezone":"America/Los_Angeles"}`); w.Code != http.StatusOK {
+        t.Fatalf("PUT: %d %s", w.Code, w.Body)
+    }
+    if w := do(http.MethodPut, "/admin/aliases/Team-West", `{"timezone":"Nowhere/Else"}`); w.Code != http.StatusBadRequest {
+        t.Errorf("PUT invalid zone: %d", w.Code)
+    }
+    if w := do(http.MethodGet, "/admin/aliases/team-west", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "America/Los_Angeles") {
+        t.Errorf("GET: %d %s", w.Code, w.Body)
+    }
+    if w := do(http.MethodGet, "/admin/aliases", ""); !strings.Contains(w.Body.String(), "Team-West") {
+        t.Errorf("list missing alias: %s", w.Body)
+    }
+    if w := do(http.MethodDelete, "/admin/aliases/Team-West", ""); w.Code != http.StatusNoContent {
+        t.Errorf("DELETE: %d", w.Code)
+    }
+    if w := do(http.MethodDelete, "/admin/aliases/Team-West", ""); w.Code != http.StatusNotFound {
+        t.Errorf("second DELETE: %d, want 404", w.Code)
+    }
+}
+
+// adminDo sends an authorized request through a fresh admin handler
+func adminDo(method, path, body string) *httptest.ResponseRecorder {
+    h := adminMiddleware(newBearerToken("admin-secret"), http.NotFoundHandler())
+    req := httptest.NewRequest(method, path, strings.NewReader(body))
+    req.Header.Set("Authorization", "Bearer admin-secret")
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, req)
+    return w
+}
+
+func TestAdminConfigRedactsTokens(t *testing.T) {
+    w := adminDo(http.MethodGet, "/admin/config", "")
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d", w.Code)
+    }
+    var body struct {
+        Version  string `json:"version"`
+        LogLevel string `json:"log_level"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatal(err)
+    }
+    if body.Version != appVersion || body.LogLevel == "" {
+        t.Errorf("unexpected config: %+v", body)
+    }
+
+    cfg := DefaultConfig()
+    cfg.AuthToken = "secret"
+    cfg.Port = 9000
+    got := flagValues(cfg)
+    if got["auth-token"] != "[redacted]" || got["port"] != "9000" {
+        t.Errorf("flagValues = %v", got)
+    }
+}
+
+func TestAdminLogLevel(t *testing.T) {
+    defer setLogLevel(logLevel())
+
+    if w := adminDo(http.MethodPut, "/admin/log-level", `{"level":"debug"}`); w.Code != http.StatusOK {
+        t.Fatalf("PUT: %d %s", w.Code, w.Body)
+    }
+    if logLevel() != logDebug {
+        t.Errorf("level = %s, want debug", logLevel())
+    }
+    if w := adminDo(http.MethodGet, "/admin/log-level", ""); !strings.Contains(w.Body.String(), `"debug"`) {
+        t.Errorf("GET: %s", w.Body)
+    }
+    if w := adminDo(http.MethodPut, "/admin/log-level", `{"level":"loud"}`); w.Code != http.StatusBadRequest {
+        t.Errorf("invalid level: %d", w.Code)
+    }
+}
+
+func TestAdminSessionsAndStats(t *testing.T) {
+    sessions.add("admin-test-session", time.Now())
+    defer sessions.remove("admin-test-session")
+    sessions.setClient("admin-test-session", mcp.Implementation{Name: "inspector", Version: "1.0"}, "2025-03-26")
+
+    w := adminDo(http.MethodGet, "/admin/sessions", "")
+    if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"client_name":"inspector"`) {
+        t.Errorf("sessions: %d %s", w.Code, w.Body)
+    }
+
+    toolStats.reset()
+    handler := toolStatsMiddleware(func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        if req.GetString("fail", "") != "" {
+            return mcp.NewToolResultError("boom"), nil
+        }
+        return mcp.NewToolResultText("ok"), nil
+    })
+    _, _ = handler(context.Background(), testRequest("demo", nil))
+    _, _ = handler(context.Background(), testRequest("demo", map[string]any{"fail": "yes"}))
+
+    w = adminDo(http.MethodGet, "/admin/stats/tools", "")
+    var body struct {
+        Tools []struct {
+            Tool   string `json:"tool"`
+            Calls  int    `json:"calls"`
+            Errors int    `json:"errors"`
+        } `json:"tools"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatal(err)
+    }
+    if len(body.Tools) != 1 || body.Tools[0].Tool != "demo" || body.Tools[0].Calls != 2 || body.Tools[0].Errors != 1 {
+        t.Errorf("stats = %+v", body.Tools)
+    }
+    if w := adminDo(http.MethodDelete, "/admin/stats/tools", ""); w.Code != http.StatusNoContent {
+        t.Errorf("reset: %d", w.Code)
+    }
+    if got := toolStats.snapshot(); len(got) != 0 {
+        t.Errorf("after reset: %v", got)
+    }
+}
+
+func TestAdminCalendars(t *testing.T) {
+    w := adminDo(http.MethodGet, "/admin/calendars", "")
+    if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"NYSE"`) {
+        t.Errorf("calendars: %d %s", w.Code, w.Body)
+    }
+}
+
+func TestAdminReloadTokens(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "token")
+    if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    tok, err := newBearerTokenFile(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer delete(liveTokens, "auth")
+    liveTokens["auth"] = tok
+
+    client := authMiddleware(tok, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    call := func(token string) int {
+        req := httptest.NewRequest(http.MethodGet, "/api/v1/time", nil)
+        req.Header.Set("Authorization", "Bearer "+token)
+        w := httptest.NewRecorder()
+        client.ServeHTTP(w, req)
+        return w.Code
+    }
+    if code := call("first"); code != http.StatusNoContent {
+        t.Fatalf("first token rejected: %d", code)
+    }
+
+    if err := os.WriteFile(path, []byte("second"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    w := adminDo(http.MethodPost, "/admin/tokens/reload", "")
+    if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"reloaded"`) {
+        t.Fatalf("reload: %d %s", w.Code, w.Body)
+    }
+    if call("first") != http.StatusUnauthorized || call("second") != http.StatusNoContent {
+        t.Error("reloaded token not in effect")
+    }
+
+    if err := os.WriteFile(path, nil, 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if w := adminDo(http.MethodPost, "/admin/tokens/reload", ""); w.Code != http.StatusInternalServerError {
+        t.Errorf("empty token file: %d", w.Code)
+    }
+    if call("second") != http.StatusNoContent {
+        t.Error("failed reload should keep the previous token")
+    }
+}
@@ -0,0 +1,201 @@
+// -*- coding: utf-8 -*-
+// aliases.go - named timezone aliases for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets operators give zones short names such as "HQ" for
+// Europe/Berlin. Aliases come from a JSON file (-aliases) and from the admin
+// API; the latter are saved in the Store (storage.go), which persists them in
+// SQLite when -db is set and keeps them in memory otherwise. Alias names are matched case-insensitively and resolved inside
+// loadLocation, so every tool, resource, prompt and REST endpoint that takes a
+// timezone accepts them. Aliases from the file are read-only at runtime.
+
+package fasttime
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// aliasNamePattern restricts alias names so they can never look like IANA zones
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Sources an alias can come from
+const (
+    aliasSourceFile = "file"
+    aliasSourceAPI  = "api"
+)
+
+// tzAlias maps a short name to an IANA zone
+type tzAlias struct {
+    Name      string `json:"name"`
+    Timezone  string `json:"timezone"`
+    Source    string `json:"source"`
+    UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// aliasRegistry holds the aliases in effect
+type aliasRegistry struct {
+    mu    sync.RWMutex
+    byKey map[string]tzAlias // lower-case name -> alias
+    store Store              // where API-managed aliases are saved (nil: map only)
+}
+
+// tzAliases is the process-wide alias registry used by loadLocation
+var tzAliases = newAliasRegistry()
+
+// newAliasRegistry creates an empty in-memory registry
+func newAliasRegistry() *aliasRegistry {
+    return &aliasRegistry{byKey: make(map[string]tzAlias)}
+}
+
+// validateAlias checks an alias name and its target zone
+func validateAlias(name, zone string) error {
+    if !aliasNamePattern.MatchString(name) {
+        return fmt.Errorf("invalid alias name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+    }
+    if _, err := time.LoadLocation(name); err == nil {
+        return fmt.Errorf("alias %q would shadow a timezone of the same name", name)
+    }
+    if _, err := time.LoadLocation(zone); err != nil || zone == "" || zone == "Local" {
+        return fmt.Errorf("invalid timezone %q for alias %q", zone, name)
+    }
+    return nil
+}
+
+// resolve returns the zone an alias points to, or name unchanged
+func (ar *aliasRegistry) resolve(name string) string {
+    ar.mu.RLock()
+    defer ar.mu.RUnlock()
+    if a, ok := ar.byKey[strings.ToLower(name)]; ok {
+        return a.Timezone
+    }
+    return name
+}
+
+// list returns all aliases sorted by name
+func (ar *aliasRegistry) list() []tzAlias {
+    ar.mu.RLock()
+    defer ar.mu.RUnlock()
+    out := make([]tzAlias, 0, len(ar.byKey))
+    for _, a := range ar.byKey {
+        out = append(out, a)
+    }
+    sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name) })
+    return out
+}
+
+// get looks up one alias
+func (ar *aliasRegistry) get(name string) (tzAlias, bool) {
+    ar.mu.RLock()
+    defer ar.mu.RUnlock()
+    a, ok := ar.byKey[strings.ToLower(name)]
+    return a, ok
+}
+
+// loadFile adds the aliases in a JSON object file ({"HQ": "Europe/Berlin"})
+func (ar *aliasRegistry) loadFile(path string) (int, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return 0, err
+    }
+    var raw map[string]string
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return 0, fmt.Errorf("parse %s: %w", path, err)
+    }
+
+    ar.mu.Lock()
+    defer ar.mu.Unlock()
+    for name, zone := range raw {
+        if err := validateAlias(name, zone); err != nil {
+            return 0, fmt.Errorf("%s: %w", path, err)
+        }
+        ar.byKey[strings.ToLower(name)] = tzAlias{Name: name, Timezone: zone, Source: aliasSourceFile}
+    }
+    return len(raw), nil
+}
+
+// useStore saves API-managed aliases in st and loads the ones it holds
+func (ar *aliasRegistry) useStore(st Store) (int, error) {
+    stored, err := st.ListAliases()
+    if err != nil {
+        return 0, err
+    }
+
+    ar.mu.Lock()
+    defer ar.mu.Unlock()
+    n := 0
+    for _, a := range stored {
+        a.Source = aliasSourceAPI
+        if existing, ok := ar.byKey[strings.ToLower(a.Name)]; ok && existing.Source == aliasSourceFile {
+            logAt(logWarn, "alias %q from the database is shadowed by the aliases file", a.Name)
+            continue
+        }
+        ar.byKey[strings.ToLower(a.Name)] = a
+        n++
+    }
+    ar.store = st
+    return n, nil
+}
+
+// errAliasReadOnly is returned when the API tries to change a file alias
+var errAliasReadOnly = fmt.Errorf("alias is defined in the aliases file and cannot be changed at runtime")
+
+// set creates or replaces an API-managed alias
+func (ar *aliasRegistry) set(name, zone string) (tzAlias, error) {
+    if err := validateAlias(name, zone); err != nil {
+        return tzAlias{}, err
+    }
+
+    ar.mu.Lock()
+    defer ar.mu.Unlock()
+    key := strings.ToLower(name)
+    if existing, ok := ar.byKey[key]; ok && existing.Source == aliasSourceFile {
+        return tzAlias{}, errAliasReadOnly
+    }
+
+    a := tzAlias{Name: name, Timezone: zone, Source: aliasSourceAPI, UpdatedAt: time.Now().UTC().Format(time.RFC3339)}
+    if ar.store != nil {
+        if err := ar.store.PutAlias(a); err != nil {
+            return tzAlias{}, fmt.Errorf("store alias: %w", err)
+        }
+    }
+    ar.byKey[key] = a
+    return a, nil
+}
+
+// remove deletes an API-managed alias and reports whether it existed
+func (ar *aliasRegistry) remove(name string) (bool, error) {
+    ar.mu.Lock()
+    defer ar.mu.Unlock()
+    key := strings.ToLower(name)
+    existing, ok := ar.byKey[key]
+    if !ok {
+        return false, nil
+    }
+    if existing.Source == aliasSourceFile {
+        return false, errAliasReadOnly
+    }
+    if ar.store != nil {
+        if err := ar.store.DeleteAlias(name); err != nil && !errors.Is(err, errNotFound) {
+            return false, fmt.Errorf("delete alias: %w", err)
+        }
+    }
+    delete(ar.byKey, key)
+    return true, nil
+}
+
+// persistent reports whether API changes survive a restart
+func (ar *aliasRegistry) persistent() bool {
+    ar.mu.RLock()
+    defer ar.mu.RUnlock()
+    return ar.store != nil && ar.store.Persistent()
+}
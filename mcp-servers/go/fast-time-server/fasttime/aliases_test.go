@@ -0,0 +1,104 @@
+// -*- coding: utf-8 -*-
+// aliases_test.go - tests for timezone aliases
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+func TestValidateAlias(t *testing.T) {
+    tests := []struct {
+        name, zone string
+        ok         bool
+    }{
+        {"HQ", "Europe/Berlin", true},
+        {"Team-East", "America/New_York", true},
+        {"UTC", "Europe/Berlin", false},       // shadows a real zone
+        {"Europe/HQ", "Europe/Berlin", false}, // slash not allowed
+        {"HQ", "Mars/Olympus", false},
+        {"HQ", "", false},
+    }
+    for _, tt := range tests {
+        if err := validateAlias(tt.name, tt.zone); (err == nil) != tt.ok {
+            t.Errorf("validateAlias(%q, %q) = %v, want ok=%v", tt.name, tt.zone, err, tt.ok)
+        }
+    }
+}
+
+func TestAliasRegistryFileAndStore(t *testing.T) {
+    dir := t.TempDir()
+    file := filepath.Join(dir, "aliases.json")
+    if err := os.WriteFile(file, []byte(`{"HQ": "Europe/Berlin"}`), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    dbFile := filepath.Join(dir, "fast-time.sqlite")
+
+    ar := newAliasRegistry()
+    if n, err := ar.loadFile(file); err != nil || n != 1 {
+        t.Fatalf("loadFile = %d, %v", n, err)
+    }
+    st, err := openSQLiteStore(dbFile)
+    if err != nil {
+        t.Fatalf("openSQLiteStore: %v", err)
+    }
+    defer st.Close()
+    if _, err := ar.useStore(st); err != nil {
+        t.Fatalf("useStore: %v", err)
+    }
+
+    if got := ar.resolve("hq"); got != "Europe/Berlin" {
+        t.Errorf("resolve(hq) = %s, want Europe/Berlin", got)
+    }
+    if _, err := ar.set("HQ", "Asia/Tokyo"); !errors.Is(err, errAliasReadOnly) {
+        t.Errorf("changing a file alias: err = %v, want errAliasReadOnly", err)
+    }
+    if _, err := ar.set("Team-East", "America/New_York"); err != nil {
+        t.Fatalf("set: %v", err)
+    }
+
+    // A fresh registry on the same store sees the stored alias
+    again := newAliasRegistry()
+    if n, err := again.useStore(st); err != nil || n != 1 {
+        t.Fatalf("reopen = %d, %v", n, err)
+    }
+    if got := again.resolve("TEAM-EAST"); got != "America/New_York" {
+        t.Errorf("persisted alias resolved to %s", got)
+    }
+
+    if found, err := again.remove("team-east"); err != nil || !found {
+        t.Fatalf("remove = %v, %v", found, err)
+    }
+    if stored, _ := st.ListAliases(); len(stored) != 0 {
+        t.Errorf("deleted alias still stored: %+v", stored)
+    }
+}
+
+func TestLoadLocationResolvesAliases(t *testing.T) {
+    if _, err := tzAliases.set("TestOffice", "Asia/Kolkata"); err != nil {
+        t.Fatal(err)
+    }
+    defer func() { _, _ = tzAliases.remove("TestOffice") }()
+
+    loc, err := loadLocation("testoffice")
+    if err != nil || loc.String() != "Asia/Kolkata" {
+        t.Fatalf("loadLocation(testoffice) = %v, %v", loc, err)
+    }
+
+    // Aliases work for tool arguments too
+    res, err := handleGetSystemTime(context.Background(), testRequest("get_system_time", map[string]any{"timezone": "TestOffice"}))
+    if err != nil || res.IsError {
+        t.Fatalf("get_system_time with alias failed: %v", err)
+    }
+    if !strings.Contains(extractText(t, res), "+05:30") {
+        t.Errorf("expected Kolkata offset, got %s", extractText(t, res))
+    }
+}
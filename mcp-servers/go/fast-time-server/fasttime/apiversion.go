@@ -0,0 +1,171 @@
+// -*- coding: utf-8 -*-
+// apiversion.go - REST API versions for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// The REST API is served under /api/v1 and /api/v2. Breaking changes ship
+// under /api/v2 only, so /api/v1 clients keep the responses they were
+// written against. Both versions share one set of handlers: a /api/v2
+// request is routed to the /api/v1 handler of the same path with its
+// version in the request context, and the handlers and response helpers
+// ask apiVersion where the versions differ. Version 2 currently differs in:
+//
+//   - errors always use the structured body ({"error": {...}, "status": n}),
+//     whatever -error-format says
+//   - /api/v2/openapi.json describes the /api/v2 paths
+//
+// Every response names its version in the API-Version header. When a
+// version is deprecated (-api-v1-sunset for /api/v1), its responses carry
+// Deprecation, Sunset (RFC 8594) and a Link to the successor-version path.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// REST API versions
+const (
+    apiV1 = 1
+    apiV2 = 2
+)
+
+// Path prefixes of the REST API versions
+const (
+    apiV1Prefix = "/api/v1"
+    apiV2Prefix = "/api/v2"
+)
+
+// apiDeprecation is the retirement schedule of an API version
+type apiDeprecation struct {
+    sunset    time.Time
+    successor string // path prefix that replaces the version
+}
+
+// apiDeprecations holds the deprecated versions, set from -api-v1-sunset
+var apiDeprecations = map[int]apiDeprecation{}
+
+// parseSunset reads a -api-v1-sunset value: a date or an RFC3339 time
+func parseSunset(s string) (time.Time, error) {
+    if s == "" {
+        return time.Time{}, nil
+    }
+    if t, err := time.Parse("2006-01-02", s); err == nil {
+        return t, nil
+    }
+    t, err := time.Parse(time.RFC3339, s)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("%q is not a date (2006-01-02) or RFC3339 time", s)
+    }
+    return t.UTC(), nil
+}
+
+// apiRequest is the version of a REST request and the path it was made to
+type apiRequest struct {
+    version int
+    path    string
+}
+
+// apiRequestKey is the context key of the apiRequest
+type apiRequestKey struct{}
+
+// apiRequestOf returns the version and original path of r; requests that
+// did not pass through versionMiddleware are version 1
+func apiRequestOf(r *http.Request) apiRequest {
+    if ar, ok := r.Context().Value(apiRequestKey{}).(apiRequest); ok {
+        return ar
+    }
+    return apiRequest{version: apiV1, path: r.URL.Path}
+}
+
+// apiVersion returns the REST API version of r
+func apiVersion(r *http.Request) int {
+    return apiRequestOf(r).version
+}
+
+// requestPath returns the path r was made to, before version routing
+func requestPath(r *http.Request) string {
+    return apiRequestOf(r).path
+}
+
+// setDeprecationHeaders announces the retirement of version to the client
+func setDeprecationHeaders(w http.ResponseWriter, version int, path string) {
+    dep, ok := apiDeprecations[version]
+    if !ok {
+        return
+    }
+    h := w.Header()
+    h.Set("Deprecation", "true")
+    if !dep.sunset.IsZero() {
+        h.Set("Sunset", dep.sunset.UTC().Format(http.TimeFormat))
+    }
+    if dep.successor != "" {
+        rest := strings.TrimPrefix(path, fmt.Sprintf("/api/v%d", version))
+        h.Add("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", dep.successor, rest))
+    }
+}
+
+// versionMiddleware serves a REST API version with the /api/v1 handlers in
+// next, recording the version and original path in the request context
+func versionMiddleware(version int, next http.Handler) http.Handler {
+    prefix := fmt.Sprintf("/api/v%d", version)
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("API-Version", strconv.Itoa(version))
+        setDeprecationHeaders(w, version, r.URL.Path)
+
+        ctx := context.WithValue(r.Context(), apiRequestKey{}, apiRequest{version: version, path: r.URL.Path})
+        r = r.WithContext(ctx)
+        if version != apiV1 {
+            r.URL.Path = apiV1Prefix + strings.TrimPrefix(r.URL.Path, prefix)
+            if r.URL.RawPath != "" {
+                r.URL.RawPath = apiV1Prefix + strings.TrimPrefix(r.URL.RawPath, prefix)
+            }
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// openAPISpecFor returns the OpenAPI specification of version
+func openAPISpecFor(version int) map[string]interface{} {
+    spec := getOpenAPISpec()
+    if version == apiV1 {
+        return spec
+    }
+
+    info := spec["info"].(map[string]interface{})
+    info["version"] = fmt.Sprintf("%d.0.0", version)
+    paths := make(map[string]interface{})
+    for p, item := range spec["paths"].(map[string]interface{}) {
+        paths[apiV2Prefix+strings.TrimPrefix(p, apiV1Prefix)] = item
+    }
+    spec["paths"] = paths
+
+    // Errors are always structured from version 2 on
+    schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+    schemas["ErrorResponse"] = map[string]interface{}{
+        "type": "object",
+        "properties": map[string]interface{}{
+            "error": map[string]interface{}{
+                "type": "object",
+                "properties": map[string]interface{}{
+                    "code":        map[string]interface{}{"type": "string", "example": "INVALID_TIMEZONE"},
+                    "message":     map[string]interface{}{"type": "string"},
+                    "field":       map[string]interface{}{"type": "string"},
+                    "hint":        map[string]interface{}{"type": "string"},
+                    "suggestions": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+                },
+            },
+            "status": map[string]interface{}{
+                "type":        "integer",
+                "description": "HTTP status code",
+            },
+        },
+    }
+    return spec
+}
@@ -0,0 +1,94 @@
+// -*- coding: utf-8 -*-
+// apiversion_test.go - Tests for REST API versions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "strings"
+    "testing"
+)
+
+func TestAPIV2Routing(t *testing.T) {
+    w := restGet(t, "/api/v2/time/Asia/Tokyo", "")
+    if w.Code != 200 || w.Header().Get("API-Version") != "2" {
+        t.Fatalf("v2 time: %d %v", w.Code, w.Header())
+    }
+    var tr TimeResponse
+    if err := json.NewDecoder(w.Body).Decode(&tr); err != nil || tr.Timezone != "Asia/Tokyo" {
+        t.Errorf("v2 time body: %+v %v", tr, err)
+    }
+    if w := restGet(t, "/api/v1/time/Asia/Tokyo", ""); w.Header().Get("API-Version") != "1" {
+        t.Errorf("v1 API-Version = %q", w.Header().Get("API-Version"))
+    }
+
+    // Paging links keep the version the client used
+    w = restGet(t, "/api/v2/timezones?page=1&per_page=5", "")
+    if link := w.Header().Get("Link"); !strings.Contains(link, "</api/v2/timezones?page=2&per_page=5>") {
+        t.Errorf("v2 Link = %q", link)
+    }
+}
+
+func TestAPIV2StructuredErrors(t *testing.T) {
+    // Classic on v1, structured on v2
+    w := restGet(t, "/api/v1/time/Mars/Base", "")
+    var classic ErrorResponse
+    if err := json.NewDecoder(w.Body).Decode(&classic); err != nil || classic.ErrorCode != codeInvalidTimezone {
+        t.Errorf("v1 error: %+v %v", classic, err)
+    }
+
+    w = restGet(t, "/api/v2/time/Mars/Base", "")
+    var structured struct {
+        Error  apiError `json:"error"`
+        Status int      `json:"status"`
+    }
+    if err := json.NewDecoder(w.Body).Decode(&structured); err != nil {
+        t.Fatal(err)
+    }
+    if structured.Status != 400 || structured.Error.Code != codeInvalidTimezone {
+        t.Errorf("v2 error: %+v", structured)
+    }
+}
+
+func TestAPIV2OpenAPISpec(t *testing.T) {
+    spec := openAPISpecFor(apiV2)
+    paths := spec["paths"].(map[string]interface{})
+    if _, ok := paths["/api/v2/timezones"]; !ok {
+        t.Error("v2 spec lacks /api/v2/timezones")
+    }
+    for p := range paths {
+        if strings.HasPrefix(p, "/api/v1") {
+            t.Errorf("v2 spec lists %s", p)
+        }
+    }
+    if _, ok := openAPISpecFor(apiV1)["paths"].(map[string]interface{})["/api/v1/time"]; !ok {
+        t.Error("v1 spec changed")
+    }
+}
+
+func TestAPIV1Sunset(t *testing.T) {
+    sunset, err := parseSunset("2026-12-31")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := parseSunset("next year"); err == nil {
+        t.Error("expected error for a bad sunset")
+    }
+    apiDeprecations[apiV1] = apiDeprecation{sunset: sunset, successor: apiV2Prefix}
+    defer delete(apiDeprecations, apiV1)
+
+    w := restGet(t, "/api/v1/timezones/Europe/London", "")
+    h := w.Header()
+    if h.Get("Deprecation") != "true" || h.Get("Sunset") != "Thu, 31 Dec 2026 00:00:00 GMT" {
+        t.Errorf("deprecation headers: %v", h)
+    }
+    if h.Get("Link") != `</api/v2/timezones/Europe/London>; rel="successor-version"` {
+        t.Errorf("successor Link = %q", h.Get("Link"))
+    }
+    if w := restGet(t, "/api/v2/timezones/Europe/London", ""); w.Header().Get("Deprecation") != "" {
+        t.Error("v2 is not deprecated")
+    }
+}
@@ -0,0 +1,247 @@
+// -*- coding: utf-8 -*-
+// audit.go - append-only audit log of tool invocations
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file records every MCP tool call for deployments that must keep an
+// audit trail. With -audit-log=path each call is appended as one JSON line
+// holding the time, the caller (scoped token name, session and client), the
+// tool, a SHA-256 of its arguments, the outcome and the latency. A path ending
+// in .db, .sqlite or .sqlite3 writes to an audit_log table in that SQLite
+// database instead.
+//
+// Arguments are hashed rather than stored so the log can prove what was
+// asked without keeping the data itself. JSON files are rotated to
+// path.<timestamp> once they reach -audit-max-size, and -audit-retention
+// removes rotated files (or SQLite rows) older than the given age.
+
+package fasttime
+
+import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Audit log defaults
+const (
+    defaultAuditMaxSize = 100 << 20 // rotate JSON files at 100 MiB
+    auditPruneInterval  = time.Hour
+    auditTimeLayout     = "2006-01-02T15:04:05.000000Z" // fixed width, so rows sort by time
+)
+
+// auditRecord is one logged tool call
+type auditRecord struct {
+    Time          time.Time `json:"time"`
+    Session       string    `json:"session,omitempty"`
+    Token         string    `json:"token,omitempty"` // scoped token name
+    ClientName    string    `json:"client_name,omitempty"`
+    ClientVersion string    `json:"client_version,omitempty"`
+    Tool          string    `json:"tool"`
+    ArgsSHA256    string    `json:"args_sha256"`
+    Status        string    `json:"status"` // ok, error or cancelled
+    Error         string    `json:"error,omitempty"`
+    DurationMS    float64   `json:"duration_ms"`
+}
+
+// auditSink stores audit records; records are only ever appended, and prune
+// drops those older than a retention cutoff
+type auditSink interface {
+    append(rec auditRecord) error
+    prune(before time.Time) (int, error)
+    Close() error
+}
+
+// auditLog is the process-wide sink (nil when auditing is off)
+var auditLog auditSink
+
+// openAuditSink opens the sink selected by path's extension
+func openAuditSink(path string, maxSize int64) (auditSink, error) {
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".db", ".sqlite", ".sqlite3":
+        return openSQLiteAuditSink(path)
+    }
+    return openFileAuditSink(path, maxSize)
+}
+
+// hashArguments returns the hex SHA-256 of the call's arguments encoded as
+// JSON; map keys are sorted, so equal arguments hash equally
+func hashArguments(args any) string {
+    data, err := json.Marshal(args)
+    if err != nil || string(data) == "null" {
+        data = []byte("{}")
+    }
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// newAuditRecord describes a finished tool call
+func newAuditRecord(ctx context.Context, req mcp.CallToolRequest, start time.Time, res *mcp.CallToolResult, err error) auditRecord {
+    rec := auditRecord{
+        Time:       start.UTC(),
+        Session:    sessionIDFrom(ctx),
+        Tool:       req.Params.Name,
+        ArgsSHA256: hashArguments(req.Params.Arguments),
+        Status:     "ok",
+        DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+    }
+    if c := callerFrom(ctx); c != nil {
+        rec.Token = c.Name
+    }
+    if info, ok := sessions.get(rec.Session); ok {
+        rec.ClientName = info.ClientName
+        rec.ClientVersion = info.ClientVersion
+    }
+    switch {
+    case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+        rec.Status = "cancelled"
+    case err != nil || (res != nil && res.IsError):
+        rec.Status = "error"
+        rec.Error = toolErrorText(res, err)
+    }
+    return rec
+}
+
+// auditMiddleware appends a record for every tool call when auditing is on.
+// A failed write is logged but does not fail the call.
+func auditMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        sink := auditLog
+        if sink == nil {
+            return next(ctx, req)
+        }
+        start := time.Now()
+        res, err := next(ctx, req)
+        if werr := sink.append(newAuditRecord(ctx, req, start, res, err)); werr != nil {
+            logAt(logError, "audit: failed to record %s call: %v", req.Params.Name, werr)
+        }
+        return res, err
+    }
+}
+
+// runAuditPruner drops records older than retention now and then every
+// auditPruneInterval until ctx is done
+func runAuditPruner(ctx context.Context, sink auditSink, retention time.Duration) {
+    ticker := time.NewTicker(auditPruneInterval)
+    defer ticker.Stop()
+    for now := time.Now(); ; {
+        if n, err := sink.prune(now.Add(-retention)); err != nil {
+            logAt(logError, "audit: pruning: %v", err)
+        } else if n > 0 {
+            logAt(logInfo, "audit: removed %d record(s) older than %v", n, retention)
+        }
+        select {
+        case <-ctx.Done():
+            return
+        case now = <-ticker.C:
+        }
+    }
+}
+
+/* ------------------------------------------------------------------ */
+/*                            JSON lines file                         */
+/* ------------------------------------------------------------------ */
+
+// fileAuditSink appends JSON lines to a size-rotated file
+type fileAuditSink struct {
+    w *rotatingFile
+}
+
+// openFileAuditSink opens path for appending, creating it if needed
+func openFileAuditSink(path string, maxSize int64) (*fileAuditSink, error) {
+    w, err := openRotatingFile(path, maxSize, 0, 0)
+    if err != nil {
+        return nil, err
+    }
+    return &fileAuditSink{w: w}, nil
+}
+
+// append implements auditSink; each record is a single write, so lines are
+// never interleaved or split by rotation
+func (s *fileAuditSink) append(rec auditRecord) error {
+    line, err := json.Marshal(rec)
+    if err != nil {
+        return err
+    }
+    _, err = s.w.Write(append(line, '\n'))
+    return err
+}
+
+// prune implements auditSink by removing rotated files last written
+// before the cutoff; the current file is kept
+func (s *fileAuditSink) prune(before time.Time) (int, error) {
+    return s.w.removeBefore(before)
+}
+
+// Close implements auditSink
+func (s *fileAuditSink) Close() error { return s.w.Close() }
+
+/* ------------------------------------------------------------------ */
+/*                                SQLite                              */
+/* ------------------------------------------------------------------ */
+
+// sqliteAuditSink inserts records into the audit_log table
+type sqliteAuditSink struct {
+    db *sql.DB
+}
+
+// openSQLiteAuditSink opens (creating if needed) the audit database
+func openSQLiteAuditSink(path string) (*sqliteAuditSink, error) {
+    db, err := sql.Open("sqlite", path)
+    if err != nil {
+        return nil, err
+    }
+    db.SetMaxOpenConns(1)
+    if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS audit_log (
+        id             INTEGER PRIMARY KEY AUTOINCREMENT,
+        time           TEXT NOT NULL,
+        session        TEXT NOT NULL DEFAULT '',
+        token          TEXT NOT NULL DEFAULT '',
+        client_name    TEXT NOT NULL DEFAULT '',
+        client_version TEXT NOT NULL DEFAULT '',
+        tool           TEXT NOT NULL,
+        args_sha256    TEXT NOT NULL,
+        status         TEXT NOT NULL,
+        error          TEXT NOT NULL DEFAULT '',
+        duration_ms    REAL NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS audit_log_time ON audit_log (time)`); err != nil {
+        _ = db.Close()
+        return nil, fmt.Errorf("audit database %s: %w", path, err)
+    }
+    return &sqliteAuditSink{db: db}, nil
+}
+
+// append implements auditSink
+func (s *sqliteAuditSink) append(rec auditRecord) error {
+    _, err := s.db.Exec(`INSERT INTO audit_log
+        (time, session, token, client_name, client_version, tool, args_sha256, status, error, duration_ms)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+        rec.Time.UTC().Format(auditTimeLayout), rec.Session, rec.Token, rec.ClientName, rec.ClientVersion,
+        rec.Tool, rec.ArgsSHA256, rec.Status, rec.Error, rec.DurationMS)
+    return err
+}
+
+// prune implements auditSink
+func (s *sqliteAuditSink) prune(before time.Time) (int, error) {
+    res, err := s.db.Exec(`DELETE FROM audit_log WHERE time < ?`, before.UTC().Format(auditTimeLayout))
+    if err != nil {
+        return 0, err
+    }
+    n, err := res.RowsAffected()
+    return int(n), err
+}
+
+// Close implements auditSink
+func (s *sqliteAuditSink) Close() error { return s.db.Close() }
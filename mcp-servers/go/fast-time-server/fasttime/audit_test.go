@@ -0,0 +1,159 @@
+// -*- coding: utf-8 -*-
+// audit_test.go - Tests for the tool call audit log
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// memoryAuditSink collects records for tests
+type memoryAuditSink struct {
+    records []auditRecord
+}
+
+func (m *memoryAuditSink) append(rec auditRecord) error {
+    m.records = append(m.records, rec)
+    return nil
+}
+
+func (m *memoryAuditSink) prune(time.Time) (int, error) { return 0, nil }
+
+func (m *memoryAuditSink) Close() error { return nil }
+
+func TestHashArgumentsStable(t *testing.T) {
+    a := hashArguments(map[string]any{"timezone": "UTC", "format": "x"})
+    b := hashArguments(map[string]any{"format": "x", "timezone": "UTC"})
+    if a != b || len(a) != 64 {
+        t.Errorf("hashes differ or wrong length: %s %s", a, b)
+    }
+    if hashArguments(nil) != hashArguments(map[string]any{}) {
+        t.Error("nil and empty arguments should hash equally")
+    }
+}
+
+func TestAuditMiddleware(t *testing.T) {
+    sink := &memoryAuditSink{}
+    defer func(prev auditSink) { auditLog = prev }(auditLog)
+    auditLog = sink
+
+    ctx := withCaller(context.Background(), &callerToken{Name: "ci"})
+    ok := auditMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return mcp.NewToolResultText("fine"), nil
+    })
+    failing := auditMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return mcp.NewToolResultError("bad timezone"), nil
+    })
+    cancelled := auditMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return nil, context.Canceled
+    })
+
+    _, _ = ok(ctx, testRequest("get_system_time", map[string]interface{}{"timezone": "UTC"}))
+    _, _ = failing(ctx, testRequest("convert_time", nil))
+    _, err := cancelled(ctx, testRequest("sleep", nil))
+    if !errors.Is(err, context.Canceled) {
+        t.Errorf("error not passed through: %v", err)
+    }
+
+    if len(sink.records) != 3 {
+        t.Fatalf("got %d records", len(sink.records))
+    }
+    want := []struct{ tool, status string }{
+        {"get_system_time", "ok"},
+        {"convert_time", "error"},
+        {"sleep", "cancelled"},
+    }
+    for i, w := range want {
+        rec := sink.records[i]
+        if rec.Tool != w.tool || rec.Status != w.status || rec.Token != "ci" {
+            t.Errorf("record %d = %+v, want %s/%s", i, rec, w.tool, w.status)
+        }
+    }
+    if sink.records[1].Error != "bad timezone" {
+        t.Errorf("error text = %q", sink.records[1].Error)
+    }
+}
+
+func TestFileAuditSinkRotatesAndPrunes(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "audit.jsonl")
+    sink, err := openFileAuditSink(path, 400)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer sink.Close()
+
+    for i := 0; i < 6; i++ {
+        if err := sink.append(auditRecord{Time: time.Now(), Tool: "get_system_time", ArgsSHA256: hashArguments(nil), Status: "ok"}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    rotated, _ := filepath.Glob(path + ".*")
+    if len(rotated) == 0 {
+        t.Fatal("expected the file to be rotated")
+    }
+
+    // Every line in the current file is a complete record
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer f.Close()
+    sc := bufio.NewScanner(f)
+    for sc.Scan() {
+        var rec auditRecord
+        if err := json.Unmarshal(sc.Bytes(), &rec); err != nil || rec.Tool != "get_system_time" {
+            t.Errorf("bad line %q: %v", sc.Text(), err)
+        }
+    }
+
+    old := time.Now().Add(-48 * time.Hour)
+    for _, r := range rotated {
+        _ = os.Chtimes(r, old, old)
+    }
+    n, err := sink.prune(time.Now().Add(-24 * time.Hour))
+    if err != nil || n != len(rotated) {
+        t.Errorf("prune = %d, %v; want %d", n, err, len(rotated))
+    }
+    if _, err := os.Stat(path); err != nil {
+        t.Errorf("current file should be kept: %v", err)
+    }
+}
+
+func TestSQLiteAuditSink(t *testing.T) {
+    sink, err := openAuditSink(filepath.Join(t.TempDir(), "audit.db"), 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer sink.Close()
+    if _, ok := sink.(*sqliteAuditSink); !ok {
+        t.Fatalf("openAuditSink(.db) = %T", sink)
+    }
+
+    now := time.Now()
+    for _, at := range []time.Time{now.Add(-72 * time.Hour), now.Add(-time.Minute), now} {
+        if err := sink.append(auditRecord{Time: at, Tool: "convert_time", ArgsSHA256: "x", Status: "ok"}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    n, err := sink.prune(now.Add(-24 * time.Hour))
+    if err != nil || n != 1 {
+        t.Errorf("prune = %d, %v; want 1", n, err)
+    }
+    var count int
+    _ = sink.(*sqliteAuditSink).db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE tool = 'convert_time'`).Scan(&count)
+    if count != 2 {
+        t.Errorf("rows left = %d", count)
+    }
+}
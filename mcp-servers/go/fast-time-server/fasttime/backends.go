@@ -0,0 +1,375 @@
+// -*- coding: utf-8 -*-
+// backends.go - health of the services the server depends on
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Some features rest on a service outside the process: the -db store, the
+// NTP servers of check_clock_accuracy and the -free-busy-calendars
+// calendars. Each is registered here as a backend, and every use reports
+// its outcome. After backendBreakerFailures failures in a row a backend is
+// "down": its circuit breaker opens and callers fail at once for
+// backendBreakerCooldown, after which one call (or the periodic probe) is let
+// through to find out whether it is back.
+//
+// Only the backends named by -required-backends (default "store") fail
+// /readyz. The others are optional: when they are down the features that
+// use them answer with an error, but the server stays in rotation. /readyz
+// and GET /admin/status report the state of every backend.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Backend breaker and probe settings
+const (
+    backendBreakerFailures = 3
+    backendBreakerCooldown = 30 * time.Second
+    backendProbeInterval   = 30 * time.Second
+    backendProbeTimeout    = 5 * time.Second
+    defaultRequiredBackend = "store"
+)
+
+// errBackendDown is wrapped by the error of a call refused by a breaker
+var errBackendDown = errors.New("backend unavailable")
+
+// backend is a service some features depend on
+type backend struct {
+    name     string
+    kind     string // sqlite, memory, ntp, caldav or google
+    required bool   // a failure takes the server out of rotation
+    probe    func(context.Context) error
+
+    failures  int // consecutive failures
+    openUntil time.Time
+    trial     bool
+    lastError string
+    lastOK    time.Time
+    lastFail  time.Time
+}
+
+// backendRegistry tracks the backends in registration order
+type backendRegistry struct {
+    mu   sync.Mutex
+    list []*backend
+}
+
+// backends is the process-wide registry; New registers the backends of
+// its configuration
+var backends = newBackendRegistry()
+
+// newBackendRegistry returns a registry holding the in-memory store
+func newBackendRegistry() *backendRegistry {
+    r := &backendRegistry{}
+    r.register("store", "memory", storeProbe)
+    _ = r.require(defaultRequiredBackend)
+    return r
+}
+
+// storeProbe pings the current store
+func storeProbe(context.Context) error { return store.Ping() }
+
+// register adds a backend; probe may be nil for backends only known
+// from their use
+func (r *backendRegistry) register(name, kind string, probe func(context.Context) error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.list = append(r.list, &backend{name: name, kind: kind, probe: probe})
+}
+
+// require marks the comma-separated backends as required and all others
+// as optional
+func (r *backendRegistry) require(list string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    want := make(map[string]bool)
+    for _, name := range strings.Split(list, ",") {
+        if name = strings.TrimSpace(name); name != "" {
+            want[name] = true
+        }
+    }
+    for _, b := range r.list {
+        b.required = want[b.name]
+        delete(want, b.name)
+    }
+    for name := range want {
+        return fmt.Errorf("-required-backends: unknown backend %q (have %s)", name, strings.Join(r.names(), ", "))
+    }
+    return nil
+}
+
+// names lists the registered backends; r.mu must be held
+func (r *backendRegistry) names() []string {
+    out := make([]string, len(r.list))
+    for i, b := range r.list {
+        out[i] = b.name
+    }
+    return out
+}
+
+// lookup returns the backend called name, or nil; r.mu must be held
+func (r *backendRegistry) lookup(name string) *backend {
+    for _, b := range r.list {
+        if b.name == name {
+            return b
+        }
+    }
+    return nil
+}
+
+// allow returns an error wrapping errBackendDown while name's breaker is
+// open; after the cooldown a single trial call is let through. Unknown
+// backends are always allowed.
+func (r *backendRegistry) allow(name string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    b := r.lookup(name)
+    switch {
+    case b == nil || b.openUntil.IsZero():
+        return nil
+    case time.Now().Before(b.openUntil) || b.trial:
+        return fmt.Errorf("%s: %w after %d failures (last: %s); retrying after %s",
+            name, errBackendDown, b.failures, b.lastError, b.openUntil.UTC().Format(time.RFC3339))
+    }
+    b.trial = true
+    return nil
+}
+
+// report records the outcome of a use of name
+func (r *backendRegistry) report(name string, err error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    b := r.lookup(name)
+    if b == nil {
+        return
+    }
+    trial := b.trial
+    b.trial = false
+    if err == nil {
+        if !b.openUntil.IsZero() {
+            logAt(logInfo, "backends: %s is back", name)
+        }
+        b.failures, b.openUntil, b.lastOK = 0, time.Time{}, time.Now()
+        return
+    }
+    b.failures++
+    b.lastError, b.lastFail = err.Error(), time.Now()
+    if trial || (b.openUntil.IsZero() && b.failures >= backendBreakerFailures) {
+        if b.openUntil.IsZero() {
+            logAt(logWarn, "backends: %s is down after %d failures: %v", name, b.failures, err)
+        }
+        b.openUntil = time.Now().Add(backendBreakerCooldown)
+    }
+}
+
+// probeAll runs the probes of all backends that have one
+func (r *backendRegistry) probeAll(ctx context.Context) {
+    r.mu.Lock()
+    list := append([]*backend(nil), r.list...)
+    r.mu.Unlock()
+    for _, b := range list {
+        if b.probe != nil {
+            r.runProbe(ctx, b)
+        }
+    }
+}
+
+// runProbe runs b's probe and records its outcome
+func (r *backendRegistry) runProbe(ctx context.Context, b *backend) error {
+    ctx, cancel := context.WithTimeout(ctx, backendProbeTimeout)
+    defer cancel()
+    err := b.probe(ctx)
+    r.report(b.name, err)
+    return err
+}
+
+// run probes every backendProbeInterval until ctx is done, so that a backend
+// nobody is using still recovers
+func (r *backendRegistry) run(ctx context.Context) {
+    ticker := time.NewTicker(backendProbeInterval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            r.probeAll(ctx)
+        }
+    }
+}
+
+// readinessChecks returns a /readyz check per required backend: a probe
+// run on the spot when it has one, otherwise its breaker state
+func (r *backendRegistry) readinessChecks() []readinessCheck {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    var out []readinessCheck
+    for _, b := range r.list {
+        if !b.required {
+            continue
+        }
+        out = append(out, readinessCheck{b.name, func() error {
+            if b.probe != nil {
+                return r.runProbe(context.Background(), b)
+            }
+            r.mu.Lock()
+            defer r.mu.Unlock()
+            if !b.openUntil.IsZero() {
+                return fmt.Errorf("%s is down: %s", b.name, b.lastError)
+            }
+            return nil
+        }})
+    }
+    return out
+}
+
+// status reports every backend and the overall state: "ok", "degraded" when
+// an optional backend is failing, or "down" when a required one is
+func (r *backendRegistry) status() (string, []map[string]interface{}) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    overall := "ok"
+    out := make([]map[string]interface{}, 0, len(r.list))
+    for _, b := range r.list {
+        state := "ok"
+        switch {
+        case !b.openUntil.IsZero():
+            state = "down"
+        case b.failures > 0:
+            state = "degraded"
+        case b.lastOK.IsZero():
+            state = "unknown"
+        }
+        entry := map[string]interface{}{
+            "name":     b.name,
+            "kind":     b.kind,
+            "required": b.required,
+            "status":   state,
+            "failures": b.failures,
+        }
+        if !b.lastOK.IsZero() {
+            entry["last_ok"] = b.lastOK.UTC().Format(time.RFC3339)
+        }
+        if b.lastError != "" {
+            entry["last_error"] = b.lastError
+            entry["last_failure"] = b.lastFail.UTC().Format(time.RFC3339)
+        }
+        if state == "down" {
+            entry["retry_after"] = b.openUntil.UTC().Format(time.RFC3339)
+        }
+        switch {
+        case state == "down" && b.required:
+            overall = "down"
+        case (state == "down" || state == "degraded") && overall == "ok":
+            overall = "degraded"
+        }
+        out = append(out, entry)
+    }
+    return overall, out
+}
+
+// handleAdminStatus handles GET /admin/status
+func handleAdminStatus(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    overall, list := backends.status()
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "status":   overall,
+        "backends": list,
+    })
+}
+
+/* ------------------------------------------------------------------ */
+/*                             guarded store                          */
+/* ------------------------------------------------------------------ */
+
+// guardedStore reports the outcome of every Store call to the "store"
+// backend and refuses calls while its breaker is open
+type guardedStore struct {
+    Store
+}
+
+// guard runs fn unless the store is down; errNotFound and errLimitReached
+// are answers, not failures
+func guard[T any](fn func() (T, error)) (T, error) {
+    var zero T
+    if err := backends.allow("store"); err != nil {
+        return zero, err
+    }
+    v, err := fn()
+    if errors.Is(err, errNotFound) || errors.Is(err, errLimitReached) {
+        backends.report("store", nil)
+    } else {
+        backends.report("store", err)
+    }
+    return v, err
+}
+
+// guardErr is guard for calls that only return an error
+func guardErr(fn func() error) error {
+    _, err := guard(func() (struct{}, error) { return struct{}{}, fn() })
+    return err
+}
+
+func (g guardedStore) ListAliases() ([]tzAlias, error) { return guard(g.Store.ListAliases) }
+func (g guardedStore) PutAlias(a tzAlias) error {
+    return guardErr(func() error { return g.Store.PutAlias(a) })
+}
+func (g guardedStore) DeleteAlias(name string) error {
+    return guardErr(func() error { return g.Store.DeleteAlias(name) })
+}
+
+func (g guardedStore) ListGroups() ([]participantGroup, error) { return guard(g.Store.ListGroups) }
+func (g guardedStore) GetGroup(name string) (participantGroup, error) {
+    return guard(func() (participantGroup, error) { return g.Store.GetGroup(name) })
+}
+func (g guardedStore) PutGroup(p participantGroup) error {
+    return guardErr(func() error { return g.Store.PutGroup(p) })
+}
+func (g guardedStore) DeleteGroup(name string) error {
+    return guardErr(func() error { return g.Store.DeleteGroup(name) })
+}
+
+func (g guardedStore) ListCalendars() ([]holidayCalendar, error) { return guard(g.Store.ListCalendars) }
+func (g guardedStore) GetCalendar(name string) (holidayCalendar, error) {
+    return guard(func() (holidayCalendar, error) { return g.Store.GetCalendar(name) })
+}
+func (g guardedStore) PutCalendar(c holidayCalendar) error {
+    return guardErr(func() error { return g.Store.PutCalendar(c) })
+}
+func (g guardedStore) DeleteCalendar(name string) error {
+    return guardErr(func() error { return g.Store.DeleteCalendar(name) })
+}
+
+func (g guardedStore) ListSchedules() ([]webhookSchedule, error) { return guard(g.Store.ListSchedules) }
+func (g guardedStore) GetSchedule(id string) (webhookSchedule, error) {
+    return guard(func() (webhookSchedule, error) { return g.Store.GetSchedule(id) })
+}
+func (g guardedStore) PutSchedule(s webhookSchedule) error {
+    return guardErr(func() error { return g.Store.PutSchedule(s) })
+}
+func (g guardedStore) UpdateSchedule(id string, fn func(*webhookSchedule) error) error {
+    return guardErr(func() error { return g.Store.UpdateSchedule(id, fn) })
+}
+func (g guardedStore) DeleteSchedule(id string) error {
+    return guardErr(func() error { return g.Store.DeleteSchedule(id) })
+}
+func (g guardedStore) CreateSchedule(s webhookSchedule, maxActive int) error {
+    return guardErr(func() error { return g.Store.CreateSchedule(s, maxActive) })
+}
+func (g guardedStore) ListDueSchedules(now time.Time) ([]webhookSchedule, error) {
+    return guard(func() ([]webhookSchedule, error) { return g.Store.ListDueSchedules(now) })
+}
+func (g guardedStore) PruneSchedules(before time.Time) (int, error) {
+    return guard(func() (int, error) { return g.Store.PruneSchedules(before) })
+}
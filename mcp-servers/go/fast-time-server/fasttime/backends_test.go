@@ -0,0 +1,226 @@
+// -*- coding: utf-8 -*-
+// backends_test.go - tests for backend health and circuit breakers
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+// useTestBackends installs an empty registry for one test
+func useTestBackends(t *testing.T) *backendRegistry {
+    t.Helper()
+    prev := backends
+    t.Cleanup(func() { backends = prev })
+    backends = &backendRegistry{}
+    return backends
+}
+
+// brokenStore is a memory store whose calls fail while broken is set
+type brokenStore struct {
+    *memoryStore
+    broken bool
+}
+
+func (b *brokenStore) ListGroups() ([]participantGroup, error) {
+    if b.broken {
+        return nil, errors.New("disk I/O error")
+    }
+    return b.memoryStore.ListGroups()
+}
+
+func (b *brokenStore) Ping() error {
+    if b.broken {
+        return errors.New("disk I/O error")
+    }
+    return nil
+}
+
+// backendStatus returns the status entry of name
+func backendStatus(r *backendRegistry, name string) map[string]interface{} {
+    _, list := r.status()
+    for _, b := range list {
+        if b["name"] == name {
+            return b
+        }
+    }
+    return nil
+}
+
+func TestBackendBreaker(t *testing.T) {
+    r := useTestBackends(t)
+    r.register("ntp", "ntp", nil)
+    if got := backendStatus(r, "ntp")["status"]; got != "unknown" {
+        t.Errorf("unused backend = %v", got)
+    }
+
+    fail := errors.New("timeout")
+    for i := 0; i < backendBreakerFailures-1; i++ {
+        r.report("ntp", fail)
+    }
+    if err := r.allow("ntp"); err != nil || backendStatus(r, "ntp")["status"] != "degraded" {
+        t.Fatalf("before the limit: %v, %v", err, backendStatus(r, "ntp"))
+    }
+    r.report("ntp", fail)
+    if err := r.allow("ntp"); !errors.Is(err, errBackendDown) || !strings.Contains(err.Error(), "timeout") {
+        t.Fatalf("open breaker: %v", err)
+    }
+    if overall, _ := r.status(); overall != "degraded" {
+        t.Errorf("an optional backend down: overall %s", overall)
+    }
+
+    // After the cooldown one trial call decides
+    r.mu.Lock()
+    r.lookup("ntp").openUntil = time.Now().Add(-time.Second)
+    r.mu.Unlock()
+    if err := r.allow("ntp"); err != nil {
+        t.Fatalf("trial refused: %v", err)
+    }
+    if err := r.allow("ntp"); err == nil {
+        t.Error("a second call during the trial should be refused")
+    }
+    r.report("ntp", nil)
+    if err := r.allow("ntp"); err != nil || backendStatus(r, "ntp")["status"] != "ok" {
+        t.Errorf("after a good trial: %v, %v", err, backendStatus(r, "ntp"))
+    }
+
+    if err := r.allow("redis"); err != nil {
+        t.Errorf("unknown backends are allowed: %v", err)
+    }
+    if err := r.require("store"); err == nil {
+        t.Error("requiring an unknown backend should fail")
+    }
+}
+
+func TestGuardedStore(t *testing.T) {
+    r := useTestBackends(t)
+    raw := &brokenStore{memoryStore: newMemoryStore()}
+    useTestStore(t, guardedStore{raw})
+    r.register("store", "memory", storeProbe)
+    if err := r.require("store"); err != nil {
+        t.Fatal(err)
+    }
+
+    if _, err := store.GetGroup("nobody"); !errors.Is(err, errNotFound) || backendStatus(r, "store")["status"] != "ok" {
+        t.Errorf("not found counted as a failure: %v", backendStatus(r, "store"))
+    }
+    raw.broken = true
+    for i := 0; i < backendBreakerFailures; i++ {
+        if _, err := store.ListGroups(); err == nil {
+            t.Fatal("broken store answered")
+        }
+    }
+    if _, err := store.ListGroups(); !errors.Is(err, errBackendDown) {
+        t.Errorf("open breaker: %v", err)
+    }
+    if overall, _ := r.status(); overall != "down" {
+        t.Errorf("a required backend down: overall %s", overall)
+    }
+
+    // The probe lets the store recover without a caller
+    raw.broken = false
+    r.mu.Lock()
+    r.lookup("store").openUntil = time.Now().Add(-time.Second)
+    r.mu.Unlock()
+    r.probeAll(context.Background())
+    if _, err := store.ListGroups(); err != nil {
+        t.Errorf("after recovery: %v", err)
+    }
+}
+
+func TestReadyzBackends(t *testing.T) {
+    defer listenerState.accepting.Store(false)
+    listenerState.accepting.Store(true)
+    r := useTestBackends(t)
+    raw := &brokenStore{memoryStore: newMemoryStore()}
+    useTestStore(t, raw)
+    r.register("store", "memory", storeProbe)
+    r.register("calendar:ada", "caldav", nil)
+    _ = r.require("store")
+
+    get := func() (int, map[string]interface{}) {
+        rec := httptest.NewRecorder()
+        handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+        var body map[string]interface{}
+        if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+            t.Fatal(err)
+        }
+        return rec.Code, body
+    }
+
+    // An optional backend that is down only shows in the list
+    for i := 0; i < backendBreakerFailures; i++ {
+        r.report("calendar:ada", errors.New("HTTP 503"))
+    }
+    code, body := get()
+    if code != http.StatusOK || len(body["backends"].([]interface{})) != 2 {
+        t.Errorf("optional backend down: %d %v", code, body)
+    }
+
+    // A required one fails the probe
+    raw.broken = true
+    if code, body := get(); code != http.StatusServiceUnavailable || body["checks"].(map[string]interface{})["store"] == nil {
+        t.Errorf("required backend down: %d %v", code, body)
+    }
+
+    // and so does an optional one once made required
+    raw.broken = false
+    _ = r.require("store,calendar:ada")
+    if code, body := get(); code != http.StatusServiceUnavailable {
+        t.Errorf("required calendar down: %d %v", code, body)
+    }
+}
+
+func TestAdminStatus(t *testing.T) {
+    r := useTestBackends(t)
+    r.register("store", "memory", storeProbe)
+    r.report("store", nil)
+
+    rec := httptest.NewRecorder()
+    handleAdminStatus(rec, httptest.NewRequest(http.MethodGet, "/admin/status", nil))
+    var body struct {
+        Status   string                   `json:"status"`
+        Backends []map[string]interface{} `json:"backends"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || rec.Code != http.StatusOK {
+        t.Fatalf("got %d %s", rec.Code, rec.Body)
+    }
+    if body.Status != "ok" || len(body.Backends) != 1 || body.Backends[0]["kind"] != "memory" || body.Backends[0]["last_ok"] == nil {
+        t.Errorf("status = %+v", body)
+    }
+
+    rec = httptest.NewRecorder()
+    handleAdminStatus(rec, httptest.NewRequest(http.MethodPost, "/admin/status", nil))
+    if rec.Code != http.StatusMethodNotAllowed {
+        t.Errorf("POST: %d", rec.Code)
+    }
+}
+
+func TestFetchBusySkipsDownCalendar(t *testing.T) {
+    useTestCalendars(t, map[string]string{"ada": "FREEBUSY:20250310T140000Z/20250310T150000Z\r\n", "down": ""})
+    r := useTestBackends(t)
+    for _, c := range freeBusyCalendars {
+        r.register(calendarBackend(c), c.Type, nil)
+    }
+    from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+    for i := 0; i < backendBreakerFailures; i++ {
+        fetchBusy(context.Background(), freeBusyCalendars, from, from.Add(24*time.Hour))
+    }
+    results := fetchBusy(context.Background(), freeBusyCalendars, from, from.Add(24*time.Hour))
+    if results[0].err != nil || !errors.Is(results[1].err, errBackendDown) {
+        t.Errorf("results = %+v", results)
+    }
+    if s := backendStatus(r, "calendar:down"); s["status"] != "down" || s["kind"] != "caldav" {
+        t.Errorf("calendar:down = %v", s)
+    }
+}
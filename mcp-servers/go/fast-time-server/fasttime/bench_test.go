@@ -0,0 +1,234 @@
+// -*- coding: utf-8 -*-
+// bench_test.go - benchmarks and performance budgets
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// perfOp is one operation timed by the benchmarks and TestPerfBudgets
+type perfOp struct {
+    name string
+    run  func() error
+}
+
+// toolOp calls a tool handler directly
+func toolOp(tool string, handler server.ToolHandlerFunc, args map[string]any) perfOp {
+    req := testRequest(tool, args)
+    return perfOp{"tool/" + tool, func() error {
+        res, err := handler(context.Background(), req)
+        if err == nil && res.IsError {
+            err = fmt.Errorf("%s", res.Content[0].(mcp.TextContent).Text)
+        }
+        return err
+    }}
+}
+
+// mcpOp sends a tools/call through the full MCP server and middleware stack
+func mcpOp(s *server.MCPServer, tool string, args map[string]any) perfOp {
+    params, _ := json.Marshal(map[string]any{"name": tool, "arguments": args})
+    msg := json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":%s}`, params))
+    return perfOp{"mcp/" + tool, func() error {
+        resp, ok := s.HandleMessage(context.Background(), msg).(mcp.JSONRPCResponse)
+        if !ok {
+            return fmt.Errorf("tools/call %s failed", tool)
+        }
+        if res, ok := resp.Result.(mcp.CallToolResult); ok && res.IsError {
+            return fmt.Errorf("tools/call %s returned an error", tool)
+        }
+        return nil
+    }}
+}
+
+// restOp serves a REST request through the API middleware
+func restOp(name string, h http.Handler, method, target, body string) perfOp {
+    return perfOp{"rest/" + name, func() error {
+        rec := httptest.NewRecorder()
+        h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+        if rec.Code != http.StatusOK {
+            return fmt.Errorf("%s %s: %d %s", method, target, rec.Code, rec.Body)
+        }
+        return nil
+    }}
+}
+
+// encodeOp renders a response in one format the way writeJSON does
+func encodeOp(format string, f respFormat, data interface{}) perfOp {
+    return perfOp{"encode/" + format, func() error {
+        if f == formatJSON {
+            return json.NewEncoder(io.Discard).Encode(data)
+        }
+        return encodeAs(io.Discard, f, data)
+    }}
+}
+
+// perfOps returns every timed operation
+func perfOps() []perfOp {
+    mcpServer := newMCPServer(&server.Hooks{}, false)
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+
+    convertArgs := map[string]any{"time": "2025-03-08 09:00:00", "source_timezone": "America/New_York", "target_timezone": "Asia/Tokyo"}
+    batch := `{"conversions":[` + strings.TrimSuffix(strings.Repeat(`{"time":"2025-01-10T10:00:00Z","from_timezone":"UTC","to_timezone":"Asia/Tokyo"},`, 50), ",") + `]}`
+    world := map[string]interface{}{"reference": "UTC", "times": []TimeResponse{
+        {Time: "2025-03-08T12:00:00Z", Timezone: "UTC", Unix: 1741435200},
+        {Time: "2025-03-08T07:00:00-05:00", Timezone: "America/New_York", Unix: 1741435200},
+        {Time: "2025-03-08T21:00:00+09:00", Timezone: "Asia/Tokyo", Unix: 1741435200},
+    }}
+
+    return []perfOp{
+        toolOp("get_system_time", handleGetSystemTime, map[string]any{"timezone": "America/New_York"}),
+        toolOp("convert_time", handleConvertTime, convertArgs),
+        toolOp("is_dst", handleIsDST, map[string]any{"timezone": "Europe/Berlin", "time": "2025-07-01T12:00:00Z"}),
+        toolOp("parse_duration", handleParseDuration, map[string]any{"duration": "P1DT2H30M"}),
+        toolOp("get_world_times", handleGetWorldTimes, map[string]any{"locations": "Sydney, Dubai, London, New York, Tokyo"}),
+        toolOp("meeting_overlap_windows", handleMeetingOverlapWindows, map[string]any{
+            "timezones": "America/New_York, Europe/London, Asia/Kolkata", "start_date": "2025-03-03", "days": float64(5),
+        }),
+        toolOp("parse_ical", handleParseICal, map[string]any{
+            "ics": outlookICS, "timezone": "UTC", "from": "2025-01-01T00:00:00Z", "to": "2025-12-31T00:00:00Z",
+        }),
+
+        mcpOp(mcpServer, "get_system_time", map[string]any{"timezone": "Europe/London"}),
+        mcpOp(mcpServer, "convert_time", convertArgs),
+
+        restOp("time", mux, http.MethodGet, "/api/v1/time/Europe/Berlin", ""),
+        restOp("convert", mux, http.MethodPost, "/api/v1/convert",
+            `{"time":"2025-01-10T10:00:00Z","from_timezone":"UTC","to_timezone":"Asia/Tokyo"}`),
+        restOp("convert_batch", mux, http.MethodPost, "/api/v1/convert/batch", batch),
+
+        encodeOp("json", formatJSON, world),
+        encodeOp("yaml", formatYAML, world),
+        encodeOp("xml", formatXML, world),
+        encodeOp("msgpack", formatMsgPack, world),
+        encodeOp("cbor", formatCBOR, world),
+    }
+}
+
+// perfSetup freezes the clock and keeps the per-call info logs out of the
+// timings, which would otherwise measure the log sink rather than the server
+func perfSetup(tb testing.TB) {
+    level := logLevel()
+    setLogLevel(logWarn)
+    clock.set(time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC))
+    tb.Cleanup(func() {
+        clock.reset()
+        setLogLevel(level)
+    })
+}
+
+// benchOps runs the operations whose names start with prefix as sub-benchmarks
+func benchOps(b *testing.B, prefix string) {
+    perfSetup(b)
+    for _, op := range perfOps() {
+        if !strings.HasPrefix(op.name, prefix) {
+            continue
+        }
+        b.Run(strings.TrimPrefix(op.name, prefix), func(b *testing.B) {
+            b.ReportAllocs()
+            for i := 0; i < b.N; i++ {
+                if err := op.run(); err != nil {
+                    b.Fatal(err)
+                }
+            }
+        })
+    }
+}
+
+func BenchmarkToolHandlers(b *testing.B)  { benchOps(b, "tool/") }
+func BenchmarkMCPStack(b *testing.B)      { benchOps(b, "mcp/") }
+func BenchmarkREST(b *testing.B)          { benchOps(b, "rest/") }
+func BenchmarkSerialization(b *testing.B) { benchOps(b, "encode/") }
+
+/* ------------------------------------------------------------------ */
+/*                        performance budgets                         */
+/* ------------------------------------------------------------------ */
+
+// perfBudgetsFile holds the p99 latency and allocation limits of each
+// operation; make perf-check fails when one is exceeded
+const perfBudgetsFile = "testdata/perf_budgets.json"
+
+// perfBudget is the limit of one operation
+type perfBudget struct {
+    P99Micros float64 `json:"p99_us"`
+    Allocs    float64 `json:"allocs"`
+}
+
+// perfSamples is the number of timed calls per operation
+const perfSamples = 2000
+
+// measure returns the p99 latency and allocations per call of op
+func measure(t *testing.T, op perfOp) (p99 time.Duration, allocs float64) {
+    for i := 0; i < perfSamples/10; i++ { // warm caches
+        if err := op.run(); err != nil {
+            t.Fatalf("%s: %v", op.name, err)
+        }
+    }
+    times := make([]time.Duration, perfSamples)
+    for i := range times {
+        start := time.Now()
+        _ = op.run()
+        times[i] = time.Since(start)
+    }
+    sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+    allocs = testing.AllocsPerRun(200, func() { _ = op.run() })
+    return times[perfSamples*99/100], allocs
+}
+
+// TestPerfBudgets checks every operation against perfBudgetsFile. Timings
+// depend on the machine and suffer under -race, so it only runs when
+// PERF_BUDGETS is set (make perf-check); a number above 1 scales the
+// latency budgets for slow runners.
+func TestPerfBudgets(t *testing.T) {
+    env := os.Getenv("PERF_BUDGETS")
+    if env == "" {
+        t.Skip("set PERF_BUDGETS=1 (make perf-check) to check performance budgets")
+    }
+    scale, err := strconv.ParseFloat(env, 64)
+    if err != nil || scale < 1 {
+        scale = 1
+    }
+    data, err := os.ReadFile(perfBudgetsFile)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var budgets map[string]perfBudget
+    if err := json.Unmarshal(data, &budgets); err != nil {
+        t.Fatalf("%s: %v", perfBudgetsFile, err)
+    }
+
+    perfSetup(t)
+    for _, op := range perfOps() {
+        p99, allocs := measure(t, op)
+        budget, ok := budgets[op.name]
+        budget.P99Micros *= scale
+        t.Logf("%-32s p99 %9.1fµs (budget %7.0f)  allocs %6.0f (budget %5.0f)",
+            op.name, float64(p99.Nanoseconds())/1000, budget.P99Micros, allocs, budget.Allocs)
+        switch {
+        case !ok:
+            t.Errorf("%s has no budget in %s", op.name, perfBudgetsFile)
+        case float64(p99.Nanoseconds())/1000 > budget.P99Micros:
+            t.Errorf("%s: p99 %v exceeds its budget of %.0fµs", op.name, p99, budget.P99Micros)
+        case allocs > budget.Allocs:
+            t.Errorf("%s: %.0f allocations per call exceed its budget of %.0f", op.name, allocs, budget.Allocs)
+        }
+    }
+}
@@ -0,0 +1,237 @@
+// -*- coding: utf-8 -*-
+// binformats.go - MessagePack and CBOR encoding of REST responses
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file renders the value tree built by encodeAs (negotiate.go) as
+// MessagePack or CBOR (RFC 8949) for clients that send
+// Accept: application/msgpack or application/cbor. Only the types that
+// tree can hold are needed: maps in JSON field order, arrays, strings,
+// booleans, null and numbers. Integers use the smallest encoding that holds
+// them and other numbers become 64-bit floats, so a decoder sees the same
+// values a JSON client would.
+
+package fasttime
+
+import (
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "io"
+    "math"
+    "strconv"
+)
+
+// binNumber classifies a JSON number as a signed integer, an unsigned one
+// too large for int64, or a float
+func binNumber(n json.Number) (i int64, u uint64, f float64, kind byte) {
+    if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+        return i, 0, 0, 'i'
+    }
+    if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+        return 0, u, 0, 'u'
+    }
+    f, _ = strconv.ParseFloat(n.String(), 64)
+    return 0, 0, f, 'f'
+}
+
+/* ------------------------------------------------------------------ */
+/*                             MessagePack                            */
+/* ------------------------------------------------------------------ */
+
+// msgpackWriter appends MessagePack to a buffer
+type msgpackWriter struct {
+    buf []byte
+}
+
+// writeMsgPack encodes the decoded tree v to out
+func writeMsgPack(out io.Writer, v interface{}) error {
+    w := &msgpackWriter{}
+    if err := w.value(v); err != nil {
+        return err
+    }
+    _, err := out.Write(w.buf)
+    return err
+}
+
+// header appends a type byte followed by n in size bytes, big-endian
+func (w *msgpackWriter) header(typ byte, n uint64, size int) {
+    w.buf = append(w.buf, typ)
+    for i := size - 1; i >= 0; i-- {
+        w.buf = append(w.buf, byte(n>>(8*i)))
+    }
+}
+
+// length appends a string, array or map header: the fix form when n fits
+// below fixMax, else the 8 (strings only), 16 or 32-bit form
+func (w *msgpackWriter) length(n int, fix byte, fixMax int, t8, t16, t32 byte) {
+    switch {
+    case n < fixMax:
+        w.buf = append(w.buf, fix|byte(n))
+    case t8 != 0 && n <= math.MaxUint8:
+        w.header(t8, uint64(n), 1)
+    case n <= math.MaxUint16:
+        w.header(t16, uint64(n), 2)
+    default:
+        w.header(t32, uint64(n), 4)
+    }
+}
+
+func (w *msgpackWriter) value(v interface{}) error {
+    switch val := v.(type) {
+    case nil:
+        w.buf = append(w.buf, 0xc0)
+    case bool:
+        if val {
+            w.buf = append(w.buf, 0xc3)
+        } else {
+            w.buf = append(w.buf, 0xc2)
+        }
+    case string:
+        w.length(len(val), 0xa0, 32, 0xd9, 0xda, 0xdb)
+        w.buf = append(w.buf, val...)
+    case json.Number:
+        i, u, f, kind := binNumber(val)
+        switch {
+        case kind == 'u':
+            w.header(0xcf, u, 8)
+        case kind == 'f':
+            w.header(0xcb, math.Float64bits(f), 8)
+        case i >= 0 && i <= 0x7f:
+            w.buf = append(w.buf, byte(i))
+        case i >= -32 && i < 0:
+            w.buf = append(w.buf, byte(i))
+        case i >= 0 && i <= math.MaxUint8:
+            w.header(0xcc, uint64(i), 1)
+        case i >= 0 && i <= math.MaxUint16:
+            w.header(0xcd, uint64(i), 2)
+        case i >= 0 && i <= math.MaxUint32:
+            w.header(0xce, uint64(i), 4)
+        case i >= 0:
+            w.header(0xcf, uint64(i), 8)
+        case i >= math.MinInt8:
+            w.header(0xd0, uint64(uint8(i)), 1)
+        case i >= math.MinInt16:
+            w.header(0xd1, uint64(uint16(i)), 2)
+        case i >= math.MinInt32:
+            w.header(0xd2, uint64(uint32(i)), 4)
+        default:
+            w.header(0xd3, uint64(i), 8)
+        }
+    case []interface{}:
+        w.length(len(val), 0x90, 16, 0, 0xdc, 0xdd)
+        for _, item := range val {
+            if err := w.value(item); err != nil {
+                return err
+            }
+        }
+    case orderedObject:
+        w.length(len(val), 0x80, 16, 0, 0xde, 0xdf)
+        for _, f := range val {
+            if err := w.value(f.key); err != nil {
+                return err
+            }
+            if err := w.value(f.value); err != nil {
+                return err
+            }
+        }
+    default:
+        return fmt.Errorf("msgpack: unsupported value %T", v)
+    }
+    return nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                                 CBOR                               */
+/* ------------------------------------------------------------------ */
+
+// CBOR major types
+const (
+    cborUnsigned byte = 0
+    cborNegative byte = 1
+    cborText     byte = 3
+    cborArray    byte = 4
+    cborMap      byte = 5
+)
+
+// cborWriter appends CBOR to a buffer
+type cborWriter struct {
+    buf []byte
+}
+
+// writeCBOR encodes the decoded tree v to out
+func writeCBOR(out io.Writer, v interface{}) error {
+    w := &cborWriter{}
+    if err := w.value(v); err != nil {
+        return err
+    }
+    _, err := out.Write(w.buf)
+    return err
+}
+
+// head appends the initial byte of major type major with argument n,
+// in the shortest form
+func (w *cborWriter) head(major byte, n uint64) {
+    m := major << 5
+    switch {
+    case n < 24:
+        w.buf = append(w.buf, m|byte(n))
+    case n <= math.MaxUint8:
+        w.buf = append(w.buf, m|24, byte(n))
+    case n <= math.MaxUint16:
+        w.buf = binary.BigEndian.AppendUint16(append(w.buf, m|25), uint16(n))
+    case n <= math.MaxUint32:
+        w.buf = binary.BigEndian.AppendUint32(append(w.buf, m|26), uint32(n))
+    default:
+        w.buf = binary.BigEndian.AppendUint64(append(w.buf, m|27), n)
+    }
+}
+
+func (w *cborWriter) value(v interface{}) error {
+    switch val := v.(type) {
+    case nil:
+        w.buf = append(w.buf, 0xf6)
+    case bool:
+        if val {
+            w.buf = append(w.buf, 0xf5)
+        } else {
+            w.buf = append(w.buf, 0xf4)
+        }
+    case string:
+        w.head(cborText, uint64(len(val)))
+        w.buf = append(w.buf, val...)
+    case json.Number:
+        i, u, f, kind := binNumber(val)
+        switch {
+        case kind == 'u':
+            w.head(cborUnsigned, u)
+        case kind == 'f':
+            w.buf = binary.BigEndian.AppendUint64(append(w.buf, 0xfb), math.Float64bits(f))
+        case i >= 0:
+            w.head(cborUnsigned, uint64(i))
+        default:
+            w.head(cborNegative, uint64(-1-i))
+        }
+    case []interface{}:
+        w.head(cborArray, uint64(len(val)))
+        for _, item := range val {
+            if err := w.value(item); err != nil {
+                return err
+            }
+        }
+    case orderedObject:
+        w.head(cborMap, uint64(len(val)))
+        for _, f := range val {
+            if err := w.value(f.key); err != nil {
+                return err
+            }
+            if err := w.value(f.value); err != nil {
+                return err
+            }
+        }
+    default:
+        return fmt.Errorf("cbor: unsupported value %T", v)
+    }
+    return nil
+}
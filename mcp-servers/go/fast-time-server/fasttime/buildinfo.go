@@ -0,0 +1,151 @@
+// -*- coding: utf-8 -*-
+// buildinfo.go - build metadata reported by /version
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file gathers what is needed to audit a deployed binary: version, git
+// commit, build time, Go runtime, the transports being served and the tzdata
+// release in use. Release builds inject the values with ldflags (see the
+// Makefile and Dockerfile):
+//
+//   go build -ldflags "-X fast-time-server/fasttime.appVersion=1.6.0 \
+//     -X fast-time-server/fasttime.gitCommit=$(git rev-parse HEAD) \
+//     -X fast-time-server/fasttime.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Plain `go build` from a git checkout still reports the commit and time
+// recorded by the Go toolchain (vcs.revision / vcs.time).
+
+package fasttime
+
+import (
+    "bufio"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "runtime"
+    "runtime/debug"
+    "strings"
+    "time"
+)
+
+// Set with -ldflags "-X main.name=value"
+var (
+    appVersion    = "1.5.0"
+    gitCommit     = ""
+    buildDate     = ""
+    tzdataRelease = "" // overrides the detected tzdata version
+)
+
+// activeTransports lists the transports being served (set in main)
+var activeTransports = []string{"stdio"}
+
+// transportsFor returns the protocols served by a -transport value
+func transportsFor(transport string) []string {
+    if transport == "dual" {
+        return []string{"sse", "http", "rest"}
+    }
+    return []string{transport}
+}
+
+// versionInfo is the body of /version
+type versionInfo struct {
+    Name       string   `json:"name"`
+    Version    string   `json:"version"`
+    MCPVersion string   `json:"mcp_version"`
+    Commit     string   `json:"commit,omitempty"`
+    Modified   bool     `json:"modified,omitempty"` // built from a dirty tree
+    BuildDate  string   `json:"build_date,omitempty"`
+    GoVersion  string   `json:"go_version"`
+    Platform   string   `json:"platform"`
+    Transports []string `json:"transports"`
+    Tzdata     string   `json:"tzdata"`
+    TzdataDir  string   `json:"tzdata_dir,omitempty"`  // the -tzdata-dir bundle in use
+    Clock      string   `json:"clock"`                 // "real", "mock" (-mock-time) or "offset" (-time-offset)
+    MockTime   string   `json:"mock_time,omitempty"`   // the frozen time in mock mode
+    TimeOffset string   `json:"time_offset,omitempty"` // the shift from the host clock in offset mode
+}
+
+// currentVersionInfo collects the build metadata
+func currentVersionInfo() versionInfo {
+    v := versionInfo{
+        Name:       appName,
+        Version:    appVersion,
+        MCPVersion: "1.0",
+        Commit:     gitCommit,
+        BuildDate:  buildDate,
+        GoVersion:  runtime.Version(),
+        Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+        Transports: activeTransports,
+        Tzdata:     tzdataVersion(),
+        Clock:      clock.mode(),
+    }
+    if b := tzdata.active(); b != nil {
+        v.TzdataDir = b.Dir
+    }
+    switch v.Clock {
+    case clockMock:
+        v.MockTime = clock.now().UTC().Format(time.RFC3339Nano)
+    case clockOffset:
+        v.TimeOffset = formatClockOffset(clock.currentOffset())
+    }
+    if bi, ok := debug.ReadBuildInfo(); ok {
+        for _, s := range bi.Settings {
+            switch s.Key {
+            case "vcs.revision":
+                if v.Commit == "" {
+                    v.Commit = s.Value
+                }
+            case "vcs.time":
+                if v.BuildDate == "" {
+                    v.BuildDate = s.Value
+                }
+            case "vcs.modified":
+                v.Modified = s.Value == "true"
+            }
+        }
+    }
+    return v
+}
+
+// versionJSON returns server version information as JSON
+func versionJSON() string {
+    data, _ := json.Marshal(currentVersionInfo())
+    return string(data)
+}
+
+// tzdataVersion returns the release of the timezone database in use, such as
+// "2025b", or "unknown" when it cannot be determined
+func tzdataVersion() string {
+    if b := tzdata.active(); b != nil {
+        return b.Version
+    }
+    if tzdataRelease != "" {
+        return tzdataRelease
+    }
+    for _, dir := range zoneinfoDirs() {
+        if v := readTzdataVersion(dir); v != "" {
+            return v
+        }
+    }
+    return "unknown"
+}
+
+// readTzdataVersion reads the release from +VERSION or the tzdata.zi header
+func readTzdataVersion(dir string) string {
+    if data, err := os.ReadFile(filepath.Join(dir, "+VERSION")); err == nil {
+        if v := strings.TrimSpace(string(data)); v != "" {
+            return v
+        }
+    }
+    f, err := os.Open(filepath.Join(dir, "tzdata.zi"))
+    if err != nil {
+        return ""
+    }
+    defer f.Close()
+    line, _ := bufio.NewReader(f).ReadString('\n')
+    if v, ok := strings.CutPrefix(strings.TrimSpace(line), "# version "); ok {
+        return v
+    }
+    return ""
+}
@@ -0,0 +1,68 @@
+// -*- coding: utf-8 -*-
+// buildinfo_test.go - Tests for build metadata
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "reflect"
+    "runtime"
+    "testing"
+)
+
+func TestVersionInfo(t *testing.T) {
+    oldCommit, oldDate, oldTransports := gitCommit, buildDate, activeTransports
+    defer func() { gitCommit, buildDate, activeTransports = oldCommit, oldDate, oldTransports }()
+
+    gitCommit, buildDate = "abc123", "2025-06-01T12:00:00Z"
+    activeTransports = transportsFor("dual")
+
+    var v versionInfo
+    if err := json.Unmarshal([]byte(versionJSON()), &v); err != nil {
+        t.Fatalf("version JSON malformed: %v", err)
+    }
+    if v.Commit != "abc123" || v.BuildDate != "2025-06-01T12:00:00Z" {
+        t.Errorf("ldflags values should win: %+v", v)
+    }
+    if v.GoVersion != runtime.Version() || v.Platform != runtime.GOOS+"/"+runtime.GOARCH || v.Tzdata == "" {
+        t.Errorf("runtime fields missing: %+v", v)
+    }
+    if !reflect.DeepEqual(v.Transports, []string{"sse", "http", "rest"}) {
+        t.Errorf("dual transports = %v", v.Transports)
+    }
+}
+
+func TestTzdataVersion(t *testing.T) {
+    dir := t.TempDir()
+    if v := readTzdataVersion(dir); v != "" {
+        t.Errorf("empty dir: got %q", v)
+    }
+    if err := os.WriteFile(filepath.Join(dir, "tzdata.zi"), []byte("# version 2025b\n# ddeps\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if v := readTzdataVersion(dir); v != "2025b" {
+        t.Errorf("tzdata.zi: got %q", v)
+    }
+    if err := os.WriteFile(filepath.Join(dir, "+VERSION"), []byte("2025c\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if v := readTzdataVersion(dir); v != "2025c" {
+        t.Errorf("+VERSION: got %q", v)
+    }
+
+    t.Setenv("ZONEINFO", dir)
+    if v := tzdataVersion(); v != "2025c" {
+        t.Errorf("ZONEINFO should be checked first, got %q", v)
+    }
+    old := tzdataRelease
+    defer func() { tzdataRelease = old }()
+    tzdataRelease = "2024a"
+    if v := tzdataVersion(); v != "2024a" {
+        t.Errorf("ldflags override: got %q", v)
+    }
+}
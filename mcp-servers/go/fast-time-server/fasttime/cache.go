@@ -0,0 +1,118 @@
+// -*- coding: utf-8 -*-
+// cache.go - ETag and Cache-Control support for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets clients skip re-downloading payloads that rarely change,
+// such as the timezone list, the static resources and the OpenAPI spec.
+//
+// REST responses written with writeCacheable carry an ETag computed from the
+// encoded body and a Cache-Control header; a request whose If-None-Match
+// matches gets 304 Not Modified without a body.
+//
+// MCP resources/read results for the same static resources carry
+// "_meta": {"etag": ..., "maxAge": ...}. A client that sends the etag back
+// as the "ifNoneMatch" argument receives an empty contents list with
+// "_meta.notModified": true instead of the payload.
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// staticMaxAge is how long clients may reuse static payloads without revalidating
+const staticMaxAge = time.Hour
+
+// cacheableResources maps MCP resource URIs to their max age
+var cacheableResources = map[string]time.Duration{
+    "timezone://info":       staticMaxAge,
+    "time://formats":        staticMaxAge,
+    "time://business-hours": staticMaxAge,
+}
+
+// computeETag returns a strong entity tag for body
+func computeETag(body []byte) string {
+    sum := sha256.Sum256(body)
+    return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison required for If-None-Match
+func etagMatches(header, etag string) bool {
+    etag = strings.TrimPrefix(etag, "W/")
+    for _, candidate := range strings.Split(header, ",") {
+        candidate = strings.TrimSpace(candidate)
+        if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+            return true
+        }
+    }
+    return false
+}
+
+// writeCacheable writes data like writeJSON, adding ETag and Cache-Control
+// headers and answering a matching If-None-Match with 304 Not Modified
+func writeCacheable(w http.ResponseWriter, r *http.Request, data interface{}, maxAge time.Duration) {
+    f := responseFormat(w)
+    var buf bytes.Buffer
+    if err := encodeAs(&buf, f, data); err != nil {
+        logAt(logError, "Failed to encode response: %v", err)
+        writeJSONError(w, http.StatusInternalServerError, "Failed to encode response")
+        return
+    }
+
+    etag := computeETag(buf.Bytes())
+    h := w.Header()
+    h.Set("ETag", etag)
+    h.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+
+    if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+        w.WriteHeader(http.StatusNotModified)
+        return
+    }
+
+    h.Set("Content-Type", f.contentType())
+    w.WriteHeader(http.StatusOK)
+    _, _ = w.Write(buf.Bytes())
+}
+
+// resourceETagHook adds cache metadata to reads of static resources and
+// empties the result when the client already holds the current version
+func resourceETagHook(_ context.Context, _ any, req *mcp.ReadResourceRequest, result *mcp.ReadResourceResult) {
+    maxAge, ok := cacheableResources[req.Params.URI]
+    if !ok || result == nil {
+        return
+    }
+
+    h := sha256.New()
+    for _, c := range result.Contents {
+        if tc, ok := c.(mcp.TextResourceContents); ok {
+            h.Write([]byte(tc.Text))
+        }
+    }
+    etag := `"` + hex.EncodeToString(h.Sum(nil)[:8]) + `"`
+
+    if result.Meta == nil {
+        result.Meta = &mcp.Meta{}
+    }
+    if result.Meta.AdditionalFields == nil {
+        result.Meta.AdditionalFields = make(map[string]any)
+    }
+    result.Meta.AdditionalFields["etag"] = etag
+    result.Meta.AdditionalFields["maxAge"] = int(maxAge.Seconds())
+
+    if inm, _ := req.Params.Arguments["ifNoneMatch"].(string); inm != "" && etagMatches(inm, etag) {
+        result.Contents = []mcp.ResourceContents{}
+        result.Meta.AdditionalFields["notModified"] = true
+    }
+}
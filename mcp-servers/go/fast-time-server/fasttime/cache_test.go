@@ -0,0 +1,131 @@
+// -*- coding: utf-8 -*-
+// cache_test.go - Tests for ETag and Cache-Control support
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestETagMatches(t *testing.T) {
+    tests := []struct {
+        header string
+        etag   string
+        want   bool
+    }{
+        {`"abc"`, `"abc"`, true},
+        {`"xyz", "abc"`, `"abc"`, true},
+        {`W/"abc"`, `"abc"`, true},
+        {`"abc"`, `W/"abc"`, true},
+        {`*`, `"abc"`, true},
+        {`"xyz"`, `"abc"`, false},
+        {`abc`, `"abc"`, false},
+    }
+    for _, tt := range tests {
+        if got := etagMatches(tt.header, tt.etag); got != tt.want {
+            t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, tt.etag, got, tt.want)
+        }
+    }
+}
+
+func TestRESTConditionalGet(t *testing.T) {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+
+    get := func(path, inm string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, path, nil)
+        if inm != "" {
+            req.Header.Set("If-None-Match", inm)
+        }
+        rec := httptest.NewRecorder()
+        mux.ServeHTTP(rec, req)
+        return rec
+    }
+
+    for _, path := range []string{"/api/v1/timezones", "/api/v1/openapi.json", "/api/v1/resources/time-formats"} {
+        rec := get(path, "")
+        etag := rec.Header().Get("ETag")
+        if rec.Code != http.StatusOK || etag == "" {
+            t.Fatalf("%s: want 200 with ETag, got %d %q", path, rec.Code, etag)
+        }
+        if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "max-age=3600") {
+            t.Errorf("%s: unexpected Cache-Control %q", path, cc)
+        }
+
+        rec = get(path, etag)
+        if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+            t.Errorf("%s: want empty 304, got %d with %d bytes", path, rec.Code, rec.Body.Len())
+        }
+        if rec = get(path, `"stale"`); rec.Code != http.StatusOK {
+            t.Errorf("%s: stale etag should get 200, got %d", path, rec.Code)
+        }
+    }
+
+    // Each representation has its own tag
+    jsonTag := get("/api/v1/timezones", "").Header().Get("ETag")
+    yamlTag := get("/api/v1/timezones?format=yaml", "").Header().Get("ETag")
+    if jsonTag == yamlTag {
+        t.Error("JSON and YAML responses should have different ETags")
+    }
+
+    // Dynamic payloads are not cached
+    if rec := get("/api/v1/resources/current-world", ""); rec.Header().Get("ETag") != "" {
+        t.Error("current-world should not carry an ETag")
+    }
+}
+
+func TestCompressedETagIsWeak(t *testing.T) {
+    h := compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        writeCacheable(w, r, map[string]string{"data": strings.Repeat("x", 2*compressMinSize)}, staticMaxAge)
+    }))
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+    req.Header.Set("Accept-Encoding", "gzip")
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    if etag := rec.Header().Get("ETag"); !strings.HasPrefix(etag, `W/"`) {
+        t.Errorf("compressed response should have a weak ETag, got %q", etag)
+    }
+}
+
+func TestResourceETagHook(t *testing.T) {
+    req := &mcp.ReadResourceRequest{}
+    req.Params.URI = "time://formats"
+    read := func() *mcp.ReadResourceResult {
+        contents, err := handleTimeFormats(context.Background(), *req)
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        result := &mcp.ReadResourceResult{Contents: contents}
+        resourceETagHook(context.Background(), 1, req, result)
+        return result
+    }
+
+    first := read()
+    etag, _ := first.Meta.AdditionalFields["etag"].(string)
+    if etag == "" || first.Meta.AdditionalFields["maxAge"] != 3600 {
+        t.Fatalf("missing cache metadata: %v", first.Meta)
+    }
+
+    req.Params.Arguments = map[string]any{"ifNoneMatch": etag}
+    second := read()
+    if len(second.Contents) != 0 || second.Meta.AdditionalFields["notModified"] != true {
+        t.Errorf("want not-modified result, got %d contents and %v", len(second.Contents), second.Meta)
+    }
+
+    // Dynamic resources are left alone
+    req.Params.URI = "time://current/world"
+    result := &mcp.ReadResourceResult{}
+    resourceETagHook(context.Background(), 1, req, result)
+    if result.Meta != nil {
+        t.Errorf("unexpected meta on dynamic resource: %v", result.Meta)
+    }
+}
@@ -0,0 +1,245 @@
+// -*- coding: utf-8 -*-
+// cancellation.go - request cancellation for MCP handlers
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets in-flight tool, resource and prompt calls stop early. The
+// SSE transport runs each message on a context that is detached from the HTTP
+// request, so cancellation is tracked here instead: every call gets its own
+// cancellable context registered under (session, request id), which is
+// cancelled when the client sends notifications/cancelled for that id or when
+// the session goes away (e.g. the SSE stream disconnects). Handlers with
+// loops check ctx.Err() and return early once cancelled.
+//
+// mcp-go does not expose the JSON-RPC id to handlers, so a mutable slot is
+// attached to each transport request context; the BeforeAny hook fills it in
+// and the handler wrappers read it back. The stdio and pipe transports build
+// one context for the whole session and run tool calls concurrently, so their
+// slot keeps each id under the per-message context mcp-go hands to both the
+// hook and the handler.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "sync"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// methodNotificationCancelled is sent by clients to abandon a request
+const methodNotificationCancelled = "notifications/cancelled"
+
+// requestSlotKey is the context key of the per-request id slot
+type requestSlotKey struct{}
+
+// requestSlot carries the JSON-RPC id of the request being handled
+type requestSlot struct {
+    mu  sync.Mutex
+    id  any
+    ids map[context.Context]any // per message on a shared (stdio) slot
+}
+
+// withRequestSlot attaches an empty id slot to a transport request context;
+// it is used as the SSE and streamable HTTP context function
+func withRequestSlot(ctx context.Context, _ *http.Request) context.Context {
+    return context.WithValue(ctx, requestSlotKey{}, &requestSlot{})
+}
+
+// withSessionRequestSlot attaches a slot shared by every message of a
+// session; it is the stdio context function
+func withSessionRequestSlot(ctx context.Context) context.Context {
+    return context.WithValue(ctx, requestSlotKey{}, &requestSlot{ids: make(map[context.Context]any)})
+}
+
+// newStdioServer returns a stdio server for s whose calls can be cancelled
+func newStdioServer(s *server.MCPServer) *server.StdioServer {
+    stdio := server.NewStdioServer(s)
+    stdio.SetContextFunc(withSessionRequestSlot)
+    return stdio
+}
+
+// cancellableMethods are the requests whose handlers claim their id
+var cancellableMethods = map[mcp.MCPMethod]bool{
+    mcp.MethodToolsCall:     true,
+    mcp.MethodResourcesRead: true,
+    mcp.MethodPromptsGet:    true,
+}
+
+// recordRequestID is a BeforeAny hook that stores the id in the context slot
+func recordRequestID(ctx context.Context, id any, method mcp.MCPMethod, _ any) {
+    slot, ok := ctx.Value(requestSlotKey{}).(*requestSlot)
+    if !ok || id == nil {
+        return
+    }
+    slot.mu.Lock()
+    defer slot.mu.Unlock()
+    if slot.ids == nil {
+        slot.id = id
+    } else if cancellableMethods[method] {
+        slot.ids[ctx] = id
+    }
+}
+
+// forgetRequestID drops the id of a shared-slot request whose handler never
+// claimed it, e.g. because the tool does not exist or -replay answered
+func forgetRequestID(ctx context.Context) {
+    if slot, ok := ctx.Value(requestSlotKey{}).(*requestSlot); ok && slot.ids != nil {
+        slot.mu.Lock()
+        delete(slot.ids, ctx)
+        slot.mu.Unlock()
+    }
+}
+
+// installRequestIDs records request ids on hooks for cancellation
+func installRequestIDs(hooks *server.Hooks) {
+    hooks.AddBeforeAny(recordRequestID)
+    hooks.AddOnSuccess(func(ctx context.Context, _ any, _ mcp.MCPMethod, _ any, _ any) { forgetRequestID(ctx) })
+    hooks.AddOnError(func(ctx context.Context, _ any, _ mcp.MCPMethod, _ any, _ error) { forgetRequestID(ctx) })
+}
+
+// requestKey returns the session and request id for ctx, if both are known.
+// On a shared slot ctx must be the context the hook saw, and the id is
+// claimed so that it is returned once.
+func requestKey(ctx context.Context) (string, string, bool) {
+    slot, ok := ctx.Value(requestSlotKey{}).(*requestSlot)
+    session := server.ClientSessionFromContext(ctx)
+    if !ok || session == nil {
+        return "", "", false
+    }
+    slot.mu.Lock()
+    defer slot.mu.Unlock()
+    id := slot.id
+    if slot.ids != nil {
+        id = slot.ids[ctx]
+        delete(slot.ids, ctx)
+    }
+    if id == nil {
+        return "", "", false
+    }
+    return session.SessionID(), fmt.Sprint(id), true
+}
+
+// inflightRequests holds the cancel functions of running calls per session
+type inflightRequests struct {
+    mu        sync.Mutex
+    bySession map[string]map[string]context.CancelFunc
+}
+
+// inflight is the process-wide registry of running calls
+var inflight = newInflightRequests()
+
+// newInflightRequests creates an empty registry
+func newInflightRequests() *inflightRequests {
+    return &inflightRequests{bySession: make(map[string]map[string]context.CancelFunc)}
+}
+
+// track derives a cancellable context for the call in ctx and registers it;
+// the returned function must be called when the call finishes
+func (ir *inflightRequests) track(ctx context.Context) (context.Context, func()) {
+    sessionID, reqID, ok := requestKey(ctx)
+    ctx, cancel := context.WithCancel(ctx)
+    if !ok {
+        return ctx, cancel
+    }
+
+    ir.mu.Lock()
+    if ir.bySession[sessionID] == nil {
+        ir.bySession[sessionID] = make(map[string]context.CancelFunc)
+    }
+    ir.bySession[sessionID][reqID] = cancel
+    ir.mu.Unlock()
+
+    return ctx, func() {
+        ir.mu.Lock()
+        delete(ir.bySession[sessionID], reqID)
+        if len(ir.bySession[sessionID]) == 0 {
+            delete(ir.bySession, sessionID)
+        }
+        ir.mu.Unlock()
+        cancel()
+    }
+}
+
+// cancel stops one running call and reports whether it was found
+func (ir *inflightRequests) cancel(sessionID, reqID string) bool {
+    ir.mu.Lock()
+    cancel, ok := ir.bySession[sessionID][reqID]
+    ir.mu.Unlock()
+    if ok {
+        cancel()
+    }
+    return ok
+}
+
+// cancelSession stops every running call of a session
+func (ir *inflightRequests) cancelSession(sessionID string) int {
+    ir.mu.Lock()
+    calls := ir.bySession[sessionID]
+    delete(ir.bySession, sessionID)
+    ir.mu.Unlock()
+    for _, cancel := range calls {
+        cancel()
+    }
+    return len(calls)
+}
+
+// countFor returns the number of running calls of a session
+func (ir *inflightRequests) countFor(sessionID string) int {
+    ir.mu.Lock()
+    defer ir.mu.Unlock()
+    return len(ir.bySession[sessionID])
+}
+
+// cancellableToolMiddleware runs each tool call on a tracked context
+func cancellableToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        ctx, done := inflight.track(ctx)
+        defer done()
+        return next(ctx, req)
+    }
+}
+
+// cancellableResource runs a resource handler on a tracked context
+func cancellableResource(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+    return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+        ctx, done := inflight.track(ctx)
+        defer done()
+        return next(ctx, req)
+    }
+}
+
+// cancellableResourceTemplate runs a resource template handler on a tracked context
+func cancellableResourceTemplate(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+    return func(ctx context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+        ctx, done := inflight.track(ctx)
+        defer done()
+        return next(ctx, req)
+    }
+}
+
+// cancellablePrompt runs a prompt handler on a tracked context
+func cancellablePrompt(next server.PromptHandlerFunc) server.PromptHandlerFunc {
+    return func(ctx context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+        ctx, done := inflight.track(ctx)
+        defer done()
+        return next(ctx, req)
+    }
+}
+
+// handleCancelledNotification cancels the request named by notifications/cancelled
+func handleCancelledNotification(ctx context.Context, n mcp.JSONRPCNotification) {
+    session := server.ClientSessionFromContext(ctx)
+    reqID, ok := n.Params.AdditionalFields["requestId"]
+    if session == nil || !ok {
+        return
+    }
+    reason, _ := n.Params.AdditionalFields["reason"].(string)
+    if inflight.cancel(session.SessionID(), fmt.Sprint(reqID)) {
+        logAt(logInfo, "session %s cancelled request %v: %s", session.SessionID(), reqID, reason)
+    }
+}
@@ -0,0 +1,177 @@
+// -*- coding: utf-8 -*-
+// cancellation_test.go - tests for request cancellation
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "io"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// fakeSession is a minimal ClientSession for tests
+type fakeSession struct {
+    id string
+    ch chan mcp.JSONRPCNotification
+}
+
+func (f fakeSession) Initialize()                                         {}
+func (f fakeSession) Initialized() bool                                   { return true }
+func (f fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return f.ch }
+func (f fakeSession) SessionID() string                                   { return f.id }
+
+// sessionRequestContext returns a context for request id in session sid
+func sessionRequestContext(sid string, id any) context.Context {
+    s := server.NewMCPServer("test", "1.0")
+    ctx := s.WithContext(withRequestSlot(context.Background(), nil), fakeSession{id: sid})
+    recordRequestID(ctx, id, mcp.MethodToolsCall, nil)
+    return ctx
+}
+
+func TestInflightCancel(t *testing.T) {
+    ir := newInflightRequests()
+    ctx, done := ir.track(sessionRequestContext("s1", float64(7)))
+    defer done()
+
+    if ir.cancel("s1", "8") {
+        t.Fatal("unknown request id should not be cancelled")
+    }
+    if !ir.cancel("s1", "7") {
+        t.Fatal("request 7 should be tracked")
+    }
+    if ctx.Err() == nil {
+        t.Fatal("context should be cancelled")
+    }
+}
+
+func TestInflightCancelSession(t *testing.T) {
+    ir := newInflightRequests()
+    a, doneA := ir.track(sessionRequestContext("s1", "a"))
+    b, doneB := ir.track(sessionRequestContext("s1", "b"))
+    other, doneOther := ir.track(sessionRequestContext("s2", "a"))
+    defer doneA()
+    defer doneB()
+    defer doneOther()
+
+    if n := ir.cancelSession("s1"); n != 2 {
+        t.Fatalf("cancelled %d requests, want 2", n)
+    }
+    if a.Err() == nil || b.Err() == nil {
+        t.Fatal("session s1 requests should be cancelled")
+    }
+    if other.Err() != nil {
+        t.Fatal("session s2 request should keep running")
+    }
+}
+
+func TestInflightDoneUnregisters(t *testing.T) {
+    ir := newInflightRequests()
+    _, done := ir.track(sessionRequestContext("s1", float64(1)))
+    done()
+    if ir.cancel("s1", "1") {
+        t.Fatal("finished request should no longer be tracked")
+    }
+
+    // Without a session or id the context is still cancellable locally
+    ctx, done := ir.track(context.Background())
+    done()
+    if ctx.Err() == nil {
+        t.Fatal("done should cancel an untracked context")
+    }
+}
+
+func TestHandleCancelledNotification(t *testing.T) {
+    ctx := sessionRequestContext("s-cancel", float64(3))
+    callCtx, done := inflight.track(ctx)
+    defer done()
+
+    n := mcp.JSONRPCNotification{}
+    n.Method = methodNotificationCancelled
+    n.Params.AdditionalFields = map[string]any{"requestId": float64(3), "reason": "user abort"}
+    handleCancelledNotification(ctx, n)
+
+    if callCtx.Err() == nil {
+        t.Fatal("notifications/cancelled should cancel the request")
+    }
+}
+
+func TestCancelledToolCallStops(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    _, err := handleMarketHours(ctx, testRequest("market_hours", nil))
+    if err != context.Canceled {
+        t.Fatalf("err = %v, want context.Canceled", err)
+    }
+}
+
+func TestStdioCancellation(t *testing.T) {
+    hooks := &server.Hooks{}
+    installRequestIDs(hooks)
+    s := newMCPServer(hooks, false)
+
+    inR, inW := io.Pipe()
+    outR, outW := io.Pipe()
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    go newStdioServer(s).Listen(ctx, inR, outW)
+
+    responses := make(chan map[string]any, 8)
+    go func() {
+        scanner := bufio.NewScanner(outR)
+        for scanner.Scan() {
+            var msg map[string]any
+            if json.Unmarshal(scanner.Bytes(), &msg) == nil && msg["id"] != nil {
+                responses <- msg
+            }
+        }
+    }()
+    send := func(msg string) {
+        if _, err := io.WriteString(inW, msg+"\n"); err != nil {
+            t.Fatal(err)
+        }
+    }
+    next := func() map[string]any {
+        select {
+        case msg := <-responses:
+            return msg
+        case <-time.After(5 * time.Second):
+            t.Fatal("no response")
+            return nil
+        }
+    }
+
+    send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+    next()
+    send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+    // Two concurrent sleeps: cancelling one leaves the other running
+    send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"sleep","arguments":{"duration":"1m"}}}`)
+    send(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"sleep","arguments":{"duration":"300ms"}}}`)
+    deadline := time.Now().Add(5 * time.Second)
+    for inflight.countFor("stdio") < 2 && time.Now().Before(deadline) {
+        time.Sleep(5 * time.Millisecond)
+    }
+    if n := inflight.countFor("stdio"); n != 2 {
+        t.Fatalf("%d stdio calls tracked, want 2", n)
+    }
+    send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"test"}}`)
+
+    first := next()
+    if first["id"] != float64(2) || first["error"] == nil {
+        t.Fatalf("first response = %v, want an error for the cancelled request 2", first)
+    }
+    second := next()
+    if second["id"] != float64(3) || second["error"] != nil {
+        t.Errorf("second response = %v, want request 3 to finish", second)
+    }
+}
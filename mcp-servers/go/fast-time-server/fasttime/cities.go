@@ -0,0 +1,154 @@
+// -*- coding: utf-8 -*-
+// cities.go - operator-supplied city database
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// The cities of time://current/world, the dashboard world clock and the
+// city lookups of get_world_times default to ten major cities. -cities
+// replaces them with a YAML or JSON file, either a plain map
+//
+//   New York: America/New_York
+//   Berlin: Europe/Berlin
+//
+// or a list whose entries may add other names for the city:
+//
+//   cities:
+//     - name: New York
+//       timezone: America/New_York
+//       aliases: [NYC, Manhattan]
+//
+// The file is checked at startup: every city needs a name and a zone that
+// loads, and no name or alias may be used twice (ignoring case, spaces, '_'
+// and '-'). Zones are stored under their canonical name.
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "os"
+
+    "gopkg.in/yaml.v3"
+)
+
+// maxCities bounds the size of a -cities file
+const maxCities = 1000
+
+// cityEntry is one city of a -cities file
+type cityEntry struct {
+    Name     string   `yaml:"name"`
+    Timezone string   `yaml:"timezone"`
+    Aliases  []string `yaml:"aliases"`
+}
+
+// cityNames maps folded city aliases to their name in worldCities
+var cityNames = map[string]string{}
+
+// loadCityFile reads and validates a -cities file
+func loadCityFile(path string) ([]cityEntry, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    entries, err := parseCities(data)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    if err := validateCities(entries); err != nil {
+        return nil, fmt.Errorf("%s: %w", path, err)
+    }
+    return entries, nil
+}
+
+// parseCities decodes either file layout
+func parseCities(data []byte) ([]cityEntry, error) {
+    var doc yaml.Node
+    if err := yaml.Unmarshal(data, &doc); err != nil {
+        return nil, err
+    }
+    if len(doc.Content) == 0 {
+        return nil, errors.New("no cities defined")
+    }
+    root := doc.Content[0]
+    var entries []cityEntry
+    switch {
+    case root.Kind == yaml.SequenceNode:
+        if err := root.Decode(&entries); err != nil {
+            return nil, err
+        }
+    case root.Kind == yaml.MappingNode && len(root.Content) == 2 && root.Content[0].Value == "cities":
+        if err := root.Content[1].Decode(&entries); err != nil {
+            return nil, err
+        }
+    case root.Kind == yaml.MappingNode:
+        // Keep the file order; a decoded map would lose it
+        for i := 0; i+1 < len(root.Content); i += 2 {
+            k, v := root.Content[i], root.Content[i+1]
+            if v.Kind != yaml.ScalarNode {
+                return nil, fmt.Errorf("line %d: the zone of %q must be a string", v.Line, k.Value)
+            }
+            entries = append(entries, cityEntry{Name: k.Value, Timezone: v.Value})
+        }
+    default:
+        return nil, errors.New("want a map of city to zone or a list of cities")
+    }
+    return entries, nil
+}
+
+// validateCities checks names and zones and canonicalizes the zones
+func validateCities(entries []cityEntry) error {
+    if len(entries) == 0 {
+        return errors.New("no cities defined")
+    }
+    if len(entries) > maxCities {
+        return fmt.Errorf("at most %d cities can be defined", maxCities)
+    }
+    seen := make(map[string]string)
+    for i := range entries {
+        c := &entries[i]
+        if tzKey(c.Name) == "" {
+            return fmt.Errorf("city %d has no name", i+1)
+        }
+        loc, err := loadLocation(c.Timezone)
+        if err != nil || c.Timezone == "" {
+            return fmt.Errorf("city %q: invalid timezone %q", c.Name, c.Timezone)
+        }
+        c.Timezone = loc.String()
+        for _, name := range append([]string{c.Name}, c.Aliases...) {
+            key := tzKey(name)
+            if other, ok := seen[key]; ok {
+                return fmt.Errorf("city %q: name %q is already used by %q", c.Name, name, other)
+            }
+            seen[key] = c.Name
+        }
+    }
+    return nil
+}
+
+// useCities makes entries the world-clock cities and city lookups
+func useCities(entries []cityEntry) {
+    cities := make(map[string]string, len(entries))
+    names := make(map[string]string)
+    for _, c := range entries {
+        cities[c.Name] = c.Timezone
+        for _, alias := range c.Aliases {
+            names[tzKey(alias)] = c.Name
+        }
+    }
+    worldCities, cityNames = cities, names
+}
+
+// lookupCity finds a world-clock city by name or alias
+func lookupCity(name string) (city, zone string, ok bool) {
+    key := tzKey(name)
+    if c, ok := cityNames[key]; ok {
+        return c, worldCities[c], true
+    }
+    for c, tz := range worldCities {
+        if tzKey(c) == key {
+            return c, tz, true
+        }
+    }
+    return "", "", false
+}
@@ -0,0 +1,79 @@
+// -*- coding: utf-8 -*-
+// cities_test.go - tests for the -cities database
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestLoadCityFile(t *testing.T) {
+    dir := t.TempDir()
+    write := func(name, body string) string {
+        path := filepath.Join(dir, name)
+        if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+            t.Fatal(err)
+        }
+        return path
+    }
+
+    entries, err := loadCityFile(write("map.json", `{"Berlin": "Europe/Berlin", "Kolkata": "Asia/Calcutta"}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(entries) != 2 || entries[0].Name != "Berlin" || entries[1].Timezone != "Asia/Kolkata" {
+        t.Errorf("map = %+v", entries)
+    }
+
+    entries, err = loadCityFile(write("list.yaml", "cities:\n  - name: New York\n    timezone: America/New_York\n    aliases: [NYC]\n"))
+    if err != nil || len(entries) != 1 || entries[0].Aliases[0] != "NYC" {
+        t.Fatalf("list = %+v, %v", entries, err)
+    }
+
+    for body, want := range map[string]string{
+        "{}":                        "no cities",
+        `{"Atlantis": "Mars/Base"}`: `invalid timezone "Mars/Base"`,
+        `{"Berlin": "Europe/Berlin", "berlin": "UTC"}`: "already used",
+        "- name: ''\n  timezone: UTC\n":                "has no name",
+        "[1, 2":                                        "yaml:",
+    } {
+        if _, err := loadCityFile(write("bad.yaml", body)); err == nil || !strings.Contains(err.Error(), want) {
+            t.Errorf("%q: err = %v, want %q", body, err, want)
+        }
+    }
+}
+
+func TestUseCities(t *testing.T) {
+    oldCities, oldNames := worldCities, cityNames
+    defer func() { worldCities, cityNames = oldCities, oldNames }()
+
+    useCities([]cityEntry{{Name: "New York", Timezone: "America/New_York", Aliases: []string{"NYC"}}})
+    city, loc, err := resolvePlace("nyc")
+    if err != nil || city != "New York" || loc.String() != "America/New_York" {
+        t.Errorf("resolvePlace(nyc) = %q %v %v", city, loc, err)
+    }
+
+    contents, err := handleCurrentWorldTimes(context.Background(), mcp.ReadResourceRequest{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    var body struct {
+        Times map[string]string `json:"times"`
+    }
+    if err := json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &body); err != nil {
+        t.Fatal(err)
+    }
+    if _, ok := body.Times["New York"]; !ok || len(body.Times) != 1 {
+        t.Errorf("world times = %v", body.Times)
+    }
+}
@@ -0,0 +1,155 @@
+// -*- coding: utf-8 -*-
+// clock.go - the server's notion of "now"
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets CI and demo environments pin the current time. With
+// -mock-time=2025-01-01T00:00:00Z every answer that depends on "now"
+// (get_system_time, the world-time resources, the REST time endpoints...)
+// is computed from that instant instead of the wall clock. The frozen time
+// only moves when an operator sets or advances it through /admin/clock.
+//
+// -time-offset=+3h (or +30d, -1 week...) keeps the clock running but shifts
+// it, so staging can rehearse a DST transition or year-end without touching
+// the host clock.
+//
+// Timers, latencies, logs and audit records always use the real clock. The
+// clock mode is reported in /version so a mocked or shifted server is never
+// mistaken for a real one.
+
+package fasttime
+
+import (
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Clock modes reported in /version and /admin/clock
+const (
+    clockReal   = "real"
+    clockMock   = "mock"
+    clockOffset = "offset"
+)
+
+// serverClock returns the time answers are computed from
+type serverClock struct {
+    mu     sync.RWMutex
+    frozen bool
+    at     time.Time
+    offset time.Duration // added to the wall clock when not frozen
+}
+
+// clock is the process-wide clock
+var clock = &serverClock{}
+
+// currentTime returns the time answers are computed from: the frozen instant
+// in mock mode, else the wall clock plus any offset
+func currentTime() time.Time {
+    return clock.now()
+}
+
+// now returns the clock's current time
+func (c *serverClock) now() time.Time {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    if c.frozen {
+        return c.at
+    }
+    return time.Now().Add(c.offset)
+}
+
+// set freezes the clock at t
+func (c *serverClock) set(t time.Time) {
+    c.mu.Lock()
+    c.frozen, c.at, c.offset = true, t, 0
+    c.mu.Unlock()
+}
+
+// shift runs the clock d ahead of the wall clock (behind when d < 0)
+func (c *serverClock) shift(d time.Duration) {
+    c.mu.Lock()
+    c.frozen, c.at, c.offset = false, time.Time{}, d
+    c.mu.Unlock()
+}
+
+// advance moves a frozen clock forward by d (backward when d < 0); it
+// reports false when the clock is not frozen
+func (c *serverClock) advance(d time.Duration) bool {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if !c.frozen {
+        return false
+    }
+    c.at = c.at.Add(d)
+    return true
+}
+
+// reset returns to the wall clock
+func (c *serverClock) reset() {
+    c.mu.Lock()
+    c.frozen, c.at, c.offset = false, time.Time{}, 0
+    c.mu.Unlock()
+}
+
+// mode returns clockReal, clockMock or clockOffset
+func (c *serverClock) mode() string {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.modeLocked()
+}
+
+// modeLocked is mode for callers holding mu
+func (c *serverClock) modeLocked() string {
+    switch {
+    case c.frozen:
+        return clockMock
+    case c.offset != 0:
+        return clockOffset
+    }
+    return clockReal
+}
+
+// currentOffset returns the offset from the wall clock (0 when frozen)
+func (c *serverClock) currentOffset() time.Duration {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.offset
+}
+
+// status describes the clock for /admin/clock
+func (c *serverClock) status() map[string]interface{} {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    out := map[string]interface{}{"mode": c.modeLocked()}
+    if c.frozen {
+        out["time"] = c.at.UTC().Format(time.RFC3339Nano)
+    } else {
+        out["time"] = time.Now().Add(c.offset).UTC().Format(time.RFC3339Nano)
+    }
+    if c.offset != 0 {
+        out["offset"] = formatClockOffset(c.offset)
+    }
+    return out
+}
+
+// parseClockOffset parses a -time-offset value such as "+3h", "-90m",
+// "+30d" or "P1Y"; a leading "+" is optional
+func parseClockOffset(s string) (time.Duration, error) {
+    raw := strings.TrimSpace(s)
+    pd, err := parseDurationFlexible(strings.TrimPrefix(raw, "+"))
+    if err != nil {
+        return 0, fmt.Errorf("invalid time offset %q: %v", raw, err)
+    }
+    return pd.d, nil
+}
+
+// formatClockOffset renders an offset with an explicit sign, e.g. "+3h0m0s"
+func formatClockOffset(d time.Duration) string {
+    if d < 0 {
+        return d.String()
+    }
+    return "+" + d.String()
+}
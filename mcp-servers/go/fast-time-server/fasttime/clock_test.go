@@ -0,0 +1,143 @@
+// -*- coding: utf-8 -*-
+// clock_test.go - Tests for the mock clock
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestServerClock(t *testing.T) {
+    c := &serverClock{}
+    if c.mode() != clockReal || time.Since(c.now()) > time.Second {
+        t.Fatalf("new clock should be real: %s %v", c.mode(), c.now())
+    }
+    if c.advance(time.Hour) {
+        t.Error("advancing the real clock should fail")
+    }
+
+    at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    c.set(at)
+    if c.mode() != clockMock || !c.now().Equal(at) {
+        t.Errorf("frozen clock = %s %v", c.mode(), c.now())
+    }
+    if !c.advance(90*time.Minute) || !c.now().Equal(at.Add(90*time.Minute)) {
+        t.Errorf("advanced clock = %v", c.now())
+    }
+    c.reset()
+    if c.mode() != clockReal {
+        t.Error("reset should return to the real clock")
+    }
+}
+
+func TestClockOffset(t *testing.T) {
+    for in, want := range map[string]time.Duration{
+        "+3h":     3 * time.Hour,
+        "-90m":    -90 * time.Minute,
+        "+30d":    30 * nominalDay,
+        "2 weeks": 2 * nominalWeek,
+    } {
+        if got, err := parseClockOffset(in); err != nil || got != want {
+            t.Errorf("parseClockOffset(%q) = %v, %v; want %v", in, got, err, want)
+        }
+    }
+    if _, err := parseClockOffset("soon"); err == nil {
+        t.Error("parseClockOffset(soon) should fail")
+    }
+
+    c := &serverClock{}
+    c.shift(365 * nominalDay)
+    if ahead := time.Until(c.now()); ahead < 364*nominalDay {
+        t.Errorf("shifted clock is only %v ahead", ahead)
+    }
+    if c.mode() != clockOffset || c.status()["offset"] != "+8760h0m0s" {
+        t.Errorf("status = %v", c.status())
+    }
+    if c.advance(time.Hour) {
+        t.Error("advancing a running clock should fail")
+    }
+    c.set(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+    if c.currentOffset() != 0 || c.mode() != clockMock {
+        t.Error("freezing the clock should clear the offset")
+    }
+
+    defer clock.reset()
+    clock.shift(-2 * time.Hour)
+    var v versionInfo
+    if err := json.Unmarshal([]byte(versionJSON()), &v); err != nil {
+        t.Fatal(err)
+    }
+    if v.Clock != clockOffset || v.TimeOffset != "-2h0m0s" || v.MockTime != "" {
+        t.Errorf("version clock = %q offset=%q mock=%q", v.Clock, v.TimeOffset, v.MockTime)
+    }
+}
+
+func TestMockTimeAnswers(t *testing.T) {
+    defer clock.reset()
+    clock.set(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+    res, err := handleGetSystemTime(context.Background(), testRequest("get_system_time", map[string]interface{}{"timezone": "Asia/Tokyo"}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := extractText(t, res); got != "2025-01-01T09:00:00+09:00" {
+        t.Errorf("get_system_time = %q", got)
+    }
+
+    var v versionInfo
+    if err := json.Unmarshal([]byte(versionJSON()), &v); err != nil {
+        t.Fatal(err)
+    }
+    if v.Clock != clockMock || v.MockTime != "2025-01-01T00:00:00Z" {
+        t.Errorf("version clock = %q %q", v.Clock, v.MockTime)
+    }
+}
+
+func TestAdminClock(t *testing.T) {
+    defer clock.reset()
+
+    do := func(method, path, body string) *httptest.ResponseRecorder {
+        rec := httptest.NewRecorder()
+        req := httptest.NewRequest(method, path, strings.NewReader(body))
+        if strings.HasSuffix(path, "/advance") {
+            handleAdminClockAdvance(rec, req)
+        } else {
+            handleAdminClock(rec, req)
+        }
+        return rec
+    }
+
+    if rec := do(http.MethodPost, "/admin/clock/advance", `{"by":"1h"}`); rec.Code != http.StatusConflict {
+        t.Errorf("advance real clock: %d", rec.Code)
+    }
+    if rec := do(http.MethodPut, "/admin/clock", `{"time":"yesterday"}`); rec.Code != http.StatusBadRequest {
+        t.Errorf("bad time: %d", rec.Code)
+    }
+    if rec := do(http.MethodPut, "/admin/clock", `{"time":"2025-03-30T00:30:00Z"}`); rec.Code != http.StatusOK {
+        t.Fatalf("PUT: %d %s", rec.Code, rec.Body)
+    }
+    rec := do(http.MethodPost, "/admin/clock/advance", `{"by":"1h"}`)
+    var st map[string]string
+    _ = json.Unmarshal(rec.Body.Bytes(), &st)
+    if rec.Code != http.StatusOK || st["mode"] != clockMock || st["time"] != "2025-03-30T01:30:00Z" {
+        t.Errorf("advance: %d %v", rec.Code, st)
+    }
+    if rec := do(http.MethodPut, "/admin/clock", `{"offset":"+1h","time":"2025-03-30T00:30:00Z"}`); rec.Code != http.StatusBadRequest {
+        t.Errorf("time and offset: %d", rec.Code)
+    }
+    if rec := do(http.MethodPut, "/admin/clock", `{"offset":"+1h"}`); rec.Code != http.StatusOK || clock.mode() != clockOffset {
+        t.Errorf("PUT offset: %d mode=%s", rec.Code, clock.mode())
+    }
+    if rec := do(http.MethodDelete, "/admin/clock", ""); rec.Code != http.StatusOK || clock.mode() != clockReal {
+        t.Errorf("DELETE: %d mode=%s", rec.Code, clock.mode())
+    }
+}
@@ -0,0 +1,215 @@
+// -*- coding: utf-8 -*-
+// compress.go - gzip/brotli response compression for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements the optional (-compress) response compression used by
+// the HTTP transports. The encoding is negotiated from Accept-Encoding, with
+// brotli preferred over gzip at equal weight. Small responses are sent as-is:
+// output is buffered until compressMinSize bytes or the end of the response
+// decides it. Server-Sent Event streams are never compressed, since
+// compression buffering would hold back events and keep-alive pings.
+
+package fasttime
+
+import (
+    "compress/gzip"
+    "io"
+    "mime"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/andybalholm/brotli"
+)
+
+// compressMinSize is the smallest response body worth compressing
+const compressMinSize = 1024
+
+// compressor is the common interface of the gzip and brotli writers
+type compressor interface {
+    io.WriteCloser
+    Flush() error
+}
+
+// pickEncoding chooses "br", "gzip" or "" from an Accept-Encoding header
+func pickEncoding(header string) string {
+    weights := map[string]float64{}
+    for _, part := range strings.Split(header, ",") {
+        name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+        name = strings.ToLower(strings.TrimSpace(name))
+        q := 1.0
+        if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+            if f, err := strconv.ParseFloat(v, 64); err == nil {
+                q = f
+            }
+        }
+        weights[name] = q
+    }
+    if w, ok := weights["*"]; ok {
+        for _, enc := range []string{"br", "gzip"} {
+            if _, set := weights[enc]; !set {
+                weights[enc] = w
+            }
+        }
+    }
+
+    best, bestQ := "", 0.0
+    for _, enc := range []string{"br", "gzip"} {
+        if q := weights[enc]; q > bestQ {
+            best, bestQ = enc, q
+        }
+    }
+    return best
+}
+
+// compressMiddleware compresses responses for clients that accept it
+func compressMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Add("Vary", "Accept-Encoding")
+        enc := pickEncoding(r.Header.Get("Accept-Encoding"))
+        if enc == "" || r.Method == http.MethodHead || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        cw := &compressWriter{ResponseWriter: w, encoding: enc, status: http.StatusOK}
+        defer cw.finish()
+        next.ServeHTTP(cw, r)
+    })
+}
+
+// compressWriter buffers the start of a response and then either compresses
+// it or passes it through unchanged
+type compressWriter struct {
+    http.ResponseWriter
+    encoding    string
+    status      int
+    wroteHeader bool       // handler called WriteHeader
+    decided     bool       // compression decision made and header sent
+    buf         []byte     // body held back until the decision
+    enc         compressor // nil when passing through
+}
+
+// WriteHeader records the status; it is sent once the decision is made
+func (cw *compressWriter) WriteHeader(code int) {
+    if cw.wroteHeader {
+        return
+    }
+    cw.wroteHeader = true
+    cw.status = code
+    // Informational and bodiless responses never need compression
+    if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+        cw.decide(false)
+    }
+}
+
+// Write buffers until compressMinSize bytes, then streams
+func (cw *compressWriter) Write(b []byte) (int, error) {
+    if !cw.wroteHeader {
+        cw.WriteHeader(http.StatusOK)
+    }
+    if !cw.decided {
+        cw.buf = append(cw.buf, b...)
+        if len(cw.buf) >= compressMinSize || !cw.compressible() {
+            if err := cw.decide(cw.compressible()); err != nil {
+                return 0, err
+            }
+        }
+        return len(b), nil
+    }
+    if cw.enc != nil {
+        return cw.enc.Write(b)
+    }
+    return cw.ResponseWriter.Write(b)
+}
+
+// compressible reports whether the response headers allow compression
+func (cw *compressWriter) compressible() bool {
+    h := cw.Header()
+    if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+        return false
+    }
+    mt, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+    switch {
+    case mt == "text/event-stream":
+        return false
+    case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "video/"), strings.HasPrefix(mt, "audio/"):
+        return false // already compressed formats
+    case mt == "application/zip", mt == "application/gzip":
+        return false
+    }
+    return true
+}
+
+// decide sends the header and flushes the buffer, compressed or not
+func (cw *compressWriter) decide(compress bool) error {
+    if cw.decided {
+        return nil
+    }
+    cw.decided = true
+    if compress {
+        h := cw.Header()
+        h.Set("Content-Encoding", cw.encoding)
+        h.Del("Content-Length")
+        // The encoded body differs byte-wise, so a strong ETag becomes weak
+        if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+            h.Set("ETag", "W/"+etag)
+        }
+        if cw.encoding == "br" {
+            cw.enc = brotli.NewWriterLevel(cw.ResponseWriter, brotli.DefaultCompression)
+        } else {
+            cw.enc, _ = gzip.NewWriterLevel(cw.ResponseWriter, gzip.DefaultCompression)
+        }
+    }
+    cw.ResponseWriter.WriteHeader(cw.status)
+
+    buf := cw.buf
+    cw.buf = nil
+    if len(buf) == 0 {
+        return nil
+    }
+    var err error
+    if cw.enc != nil {
+        _, err = cw.enc.Write(buf)
+    } else {
+        _, err = cw.ResponseWriter.Write(buf)
+    }
+    return err
+}
+
+// Flush ends buffering: a handler that flushes is streaming, so a short
+// buffered body is sent as-is rather than held back
+func (cw *compressWriter) Flush() {
+    if !cw.decided {
+        if !cw.wroteHeader {
+            cw.WriteHeader(http.StatusOK)
+        }
+        _ = cw.decide(len(cw.buf) >= compressMinSize && cw.compressible())
+    }
+    if cw.enc != nil {
+        _ = cw.enc.Flush()
+    }
+    if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+    return cw.ResponseWriter
+}
+
+// finish completes the response once the handler returns
+func (cw *compressWriter) finish() {
+    if !cw.decided {
+        if !cw.wroteHeader && len(cw.buf) == 0 {
+            return // nothing written; let net/http send its default response
+        }
+        _ = cw.decide(len(cw.buf) >= compressMinSize && cw.compressible())
+    }
+    if cw.enc != nil {
+        _ = cw.enc.Close()
+    }
+}
@@ -0,0 +1,100 @@
+// -*- coding: utf-8 -*-
+// compress_test.go - Tests for response compression
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "compress/gzip"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/andybalholm/brotli"
+)
+
+func TestPickEncoding(t *testing.T) {
+    tests := map[string]string{
+        "":                    "",
+        "identity":            "",
+        "gzip":                "gzip",
+        "gzip, deflate, br":   "br",
+        "br;q=0.5, gzip":      "gzip",
+        "*":                   "br",
+        "*;q=0.5, gzip":       "gzip",
+        "gzip;q=0, br;q=0":    "",
+        "GZIP;q=0.8, deflate": "gzip",
+    }
+    for header, want := range tests {
+        if got := pickEncoding(header); got != want {
+            t.Errorf("pickEncoding(%q) = %q, want %q", header, got, want)
+        }
+    }
+}
+
+func TestCompressMiddleware(t *testing.T) {
+    large := strings.Repeat(`{"timezone":"America/New_York"},`, 100)
+    h := compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/small":
+            w.Header().Set("Content-Type", "application/json")
+            _, _ = io.WriteString(w, `{"ok":true}`)
+        case "/sse":
+            w.Header().Set("Content-Type", "text/event-stream")
+            _, _ = io.WriteString(w, "event: endpoint\ndata: "+large+"\n\n")
+            w.(http.Flusher).Flush()
+        default:
+            w.Header().Set("Content-Type", "application/json")
+            w.WriteHeader(http.StatusCreated)
+            // Written in pieces to exercise buffering
+            _, _ = io.WriteString(w, large[:100])
+            _, _ = io.WriteString(w, large[100:])
+        }
+    }))
+
+    get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, path, nil)
+        req.Header.Set("Accept-Encoding", acceptEncoding)
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        return w
+    }
+
+    for _, enc := range []string{"gzip", "br"} {
+        w := get("/large", enc)
+        if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != enc {
+            t.Fatalf("%s: status %d, Content-Encoding %q", enc, w.Code, w.Header().Get("Content-Encoding"))
+        }
+        var r io.Reader
+        if enc == "gzip" {
+            zr, err := gzip.NewReader(w.Body)
+            if err != nil {
+                t.Fatal(err)
+            }
+            r = zr
+        } else {
+            r = brotli.NewReader(w.Body)
+        }
+        body, err := io.ReadAll(r)
+        if err != nil || string(body) != large {
+            t.Errorf("%s: decoded body mismatch (err %v, %d bytes)", enc, err, len(body))
+        }
+        if !strings.Contains(w.Header().Get("Vary"), "Accept-Encoding") {
+            t.Errorf("%s: missing Vary", enc)
+        }
+    }
+
+    if w := get("/small", "gzip"); w.Header().Get("Content-Encoding") != "" || w.Body.String() != `{"ok":true}` {
+        t.Errorf("small responses should not be compressed: %q", w.Header().Get("Content-Encoding"))
+    }
+    if w := get("/sse", "gzip, br"); w.Header().Get("Content-Encoding") != "" || !strings.HasPrefix(w.Body.String(), "event: endpoint") {
+        t.Errorf("SSE streams must not be compressed: %q", w.Header().Get("Content-Encoding"))
+    }
+    if w := get("/large", ""); w.Header().Get("Content-Encoding") != "" || w.Body.String() != large {
+        t.Error("responses must not be compressed without Accept-Encoding")
+    }
+}
@@ -0,0 +1,118 @@
+// -*- coding: utf-8 -*-
+// connlimit.go - concurrent connection limits for the HTTP transports
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file caps the number of open TCP connections (-max-connections) so a
+// misbehaving client pool cannot exhaust file descriptors. Connections are
+// counted through http.Server.ConnState; a request arriving while the server
+// is over the limit gets 503 with "Connection: close", which frees the
+// connection again. Every HTTP listener counts its own connections against
+// the limit, so a saturated public port leaves the admin and metrics
+// listeners reachable. SSE streams have their own cap, -max-sse-clients,
+// enforced by the SSE tracker in sse.go. Both rejection counters are reported
+// at /health, the connection counts per listener as well: connections open
+// and accepted since startup, and requests served and rejected.
+
+package fasttime
+
+import (
+    "net"
+    "net/http"
+    "sync"
+    "sync/atomic"
+)
+
+// connLimiter counts the open connections of one listener and rejects
+// requests above max
+type connLimiter struct {
+    max      atomic.Int64 // 0 = unlimited
+    open     atomic.Int64
+    accepted atomic.Int64
+    requests atomic.Int64
+    rejected atomic.Int64
+}
+
+// connLimiters holds the limiter of each HTTP listener by address
+type connLimiters struct {
+    mu     sync.Mutex
+    byAddr map[string]*connLimiter
+}
+
+// httpConns holds the connection counters consulted by /health
+var httpConns = &connLimiters{byAddr: make(map[string]*connLimiter)}
+
+// forListener returns a new limiter allowing max connections (0 = unlimited)
+// and reports it under addr, replacing any earlier one
+func (ls *connLimiters) forListener(addr string, max int) *connLimiter {
+    l := &connLimiter{}
+    l.max.Store(int64(max))
+    ls.mu.Lock()
+    ls.byAddr[addr] = l
+    ls.mu.Unlock()
+    return l
+}
+
+// connStats are the counters of one listener, or of all of them
+type connStats struct {
+    Open     int64 `json:"open"`
+    Accepted int64 `json:"accepted"`
+    Requests int64 `json:"requests"`
+    Rejected int64 `json:"rejected"`
+}
+
+// stats returns the totals over all listeners and the counters of each
+func (ls *connLimiters) stats() (connStats, map[string]connStats) {
+    ls.mu.Lock()
+    defer ls.mu.Unlock()
+    var total connStats
+    byAddr := make(map[string]connStats, len(ls.byAddr))
+    for addr, l := range ls.byAddr {
+        st := connStats{
+            Open:     l.open.Load(),
+            Accepted: l.accepted.Load(),
+            Requests: l.requests.Load(),
+            Rejected: l.rejected.Load(),
+        }
+        total.Open += st.Open
+        total.Accepted += st.Accepted
+        total.Requests += st.Requests
+        total.Rejected += st.Rejected
+        byAddr[addr] = st
+    }
+    return total, byAddr
+}
+
+// trackConn is an http.Server.ConnState hook
+func (l *connLimiter) trackConn(_ net.Conn, state http.ConnState) {
+    switch state {
+    case http.StateNew:
+        l.open.Add(1)
+        l.accepted.Add(1)
+    case http.StateClosed, http.StateHijacked:
+        l.open.Add(-1)
+    }
+}
+
+// saturated reports whether more connections are open than allowed
+func (l *connLimiter) saturated() bool {
+    max := l.max.Load()
+    return max > 0 && l.open.Load() > max
+}
+
+// middleware answers 503 and closes the connection while saturated
+func (l *connLimiter) middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        l.requests.Add(1)
+        if l.saturated() {
+            l.rejected.Add(1)
+            logAt(logWarn, "rejected request from %s: %d connections open (max %d)", r.RemoteAddr, l.open.Load(), l.max.Load())
+            w.Header().Set("Connection", "close")
+            w.Header().Set("Retry-After", "1")
+            writeJSONError(w, http.StatusServiceUnavailable, "Too many connections")
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
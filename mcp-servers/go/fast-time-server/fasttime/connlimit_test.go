@@ -0,0 +1,95 @@
+// -*- coding: utf-8 -*-
+// connlimit_test.go - Tests for the connection limit
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestConnLimiter(t *testing.T) {
+    l := &connLimiter{}
+    l.max.Store(2)
+    h := l.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+    }))
+    serve := func() *httptest.ResponseRecorder {
+        rec := httptest.NewRecorder()
+        h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+        return rec
+    }
+
+    for i := 0; i < 2; i++ {
+        l.trackConn(nil, http.StateNew)
+    }
+    if rec := serve(); rec.Code != http.StatusOK {
+        t.Errorf("at the limit: want 200, got %d", rec.Code)
+    }
+
+    l.trackConn(nil, http.StateNew)
+    rec := serve()
+    if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Connection") != "close" {
+        t.Errorf("over the limit: want 503 with Connection: close, got %d %v", rec.Code, rec.Header())
+    }
+    if l.rejected.Load() != 1 {
+        t.Errorf("want rejected counter 1, got %d", l.rejected.Load())
+    }
+
+    l.trackConn(nil, http.StateClosed)
+    if rec := serve(); rec.Code != http.StatusOK {
+        t.Errorf("after a close: want 200, got %d", rec.Code)
+    }
+    l.trackConn(nil, http.StateHijacked)
+    if n := l.open.Load(); n != 1 {
+        t.Errorf("want 1 open connection, got %d", n)
+    }
+    if a, r := l.accepted.Load(), l.requests.Load(); a != 3 || r != 3 {
+        t.Errorf("want 3 accepted connections and 3 requests, got %d and %d", a, r)
+    }
+}
+
+func TestConnLimitPerListener(t *testing.T) {
+    ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(http.StatusOK)
+    })
+    cfg := httpServerConfig{maxConnections: 1}
+    public := newHTTPServer("test-public:8080", ok, cfg)
+    admin := newHTTPServer("test-admin:9090", ok, cfg)
+
+    // Two connections on the public port saturate it, not the admin port
+    public.ConnState(nil, http.StateNew)
+    public.ConnState(nil, http.StateNew)
+    admin.ConnState(nil, http.StateNew)
+    for name, srv := range map[string]*http.Server{"public": public, "admin": admin} {
+        want := http.StatusOK
+        if name == "public" {
+            want = http.StatusServiceUnavailable
+        }
+        rec := httptest.NewRecorder()
+        srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+        if rec.Code != want {
+            t.Errorf("%s listener: want %d, got %d", name, want, rec.Code)
+        }
+    }
+
+    var h struct {
+        HTTP struct {
+            Listeners map[string]connStats `json:"listeners"`
+        } `json:"http_connections"`
+    }
+    if err := json.Unmarshal([]byte(healthJSON()), &h); err != nil {
+        t.Fatal(err)
+    }
+    if got := h.HTTP.Listeners["test-public:8080"]; got != (connStats{Open: 2, Accepted: 2, Requests: 1, Rejected: 1}) {
+        t.Errorf("public listener stats = %+v", got)
+    }
+    if got := h.HTTP.Listeners["test-admin:9090"]; got != (connStats{Open: 1, Accepted: 1, Requests: 1}) {
+        t.Errorf("admin listener stats = %+v", got)
+    }
+}
@@ -0,0 +1,214 @@
+// -*- coding: utf-8 -*-
+// cron.go - cron expressions evaluated in a timezone
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// parseCron reads the five-field cron syntax (minute hour day-of-month
+// month day-of-week) with lists, ranges, steps, month and weekday names and
+// the @hourly, @daily, @weekly, @monthly and @yearly shorthands. As in
+// Vixie cron, a day matches when either day field does if both are
+// restricted, and 0 or 7 is Sunday.
+//
+// next works on wall-clock time in the schedule's zone, so "0 9 * * MON-FRI"
+// stays at 09:00 across DST changes. A time skipped by a spring-forward
+// transition runs at the moment the clocks jump to ("30 2 * * *" runs at
+// 03:30 on that day in America/New_York); a time repeated in the autumn
+// runs once, at its first occurrence.
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// cronSearchYears bounds the search for the next run, so that expressions
+// such as "0 0 30 2 *" that never match fail instead of looping
+const cronSearchYears = 5
+
+// cronMacros are the @ shorthands
+var cronMacros = map[string]string{
+    "@yearly":   "0 0 1 1 *",
+    "@annually": "0 0 1 1 *",
+    "@monthly":  "0 0 1 * *",
+    "@weekly":   "0 0 * * 0",
+    "@daily":    "0 0 * * *",
+    "@midnight": "0 0 * * *",
+    "@hourly":   "0 * * * *",
+}
+
+// cronField describes the values of one field
+type cronField struct {
+    name     string
+    min, max int
+    names    []string // names[i] stands for min+i
+}
+
+var cronFields = [5]cronField{
+    {name: "minute", min: 0, max: 59},
+    {name: "hour", min: 0, max: 23},
+    {name: "day of month", min: 1, max: 31},
+    {name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+    {name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// cronSchedule is a parsed cron expression
+type cronSchedule struct {
+    expr   string
+    fields [5]uint64 // bit v set when value v matches
+    domAny bool      // day of month is *
+    dowAny bool      // day of week is *
+}
+
+// value reads one number or name of f
+func (f cronField) value(s string) (int, error) {
+    for i, n := range f.names {
+        if strings.EqualFold(s, n) {
+            return f.min + i, nil
+        }
+    }
+    v, err := strconv.Atoi(s)
+    if err != nil || v < f.min || v > f.max {
+        return 0, fmt.Errorf("invalid %s %q (want %d-%d)", f.name, s, f.min, f.max)
+    }
+    return v, nil
+}
+
+// parse reads a comma-separated list of values, ranges and steps
+func (f cronField) parse(spec string) (uint64, error) {
+    var bits uint64
+    for _, part := range strings.Split(spec, ",") {
+        rng, stepStr, hasStep := strings.Cut(part, "/")
+        step := 1
+        if hasStep {
+            var err error
+            if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+                return 0, fmt.Errorf("invalid step %q in %s", stepStr, f.name)
+            }
+        }
+
+        lo, hi := f.min, f.max
+        switch lostr, histr, isRange := strings.Cut(rng, "-"); {
+        case rng == "*":
+        case isRange:
+            var err error
+            if lo, err = f.value(lostr); err != nil {
+                return 0, err
+            }
+            if hi, err = f.value(histr); err != nil {
+                return 0, err
+            }
+            if hi < lo {
+                return 0, fmt.Errorf("invalid %s range %q", f.name, rng)
+            }
+        default:
+            v, err := f.value(rng)
+            if err != nil {
+                return 0, err
+            }
+            lo = v
+            if !hasStep {
+                hi = v
+            }
+        }
+        for v := lo; v <= hi; v += step {
+            bits |= 1 << uint(v)
+        }
+    }
+    return bits, nil
+}
+
+// parseCron parses a five-field cron expression or an @ shorthand
+func parseCron(expr string) (*cronSchedule, error) {
+    spec := strings.TrimSpace(expr)
+    if m, ok := cronMacros[strings.ToLower(spec)]; ok {
+        spec = m
+    }
+    parts := strings.Fields(spec)
+    if len(parts) != 5 {
+        return nil, errors.New("cron expression needs 5 fields: minute hour day-of-month month day-of-week")
+    }
+
+    c := &cronSchedule{expr: strings.TrimSpace(expr), domAny: parts[2] == "*", dowAny: parts[4] == "*"}
+    for i, f := range cronFields {
+        bits, err := f.parse(parts[i])
+        if err != nil {
+            return nil, err
+        }
+        c.fields[i] = bits
+    }
+    if c.fields[4]&(1<<7) != 0 {
+        c.fields[4] |= 1 // 7 is Sunday too
+    }
+    return c, nil
+}
+
+// has reports whether value v matches field i
+func (c *cronSchedule) has(i, v int) bool {
+    return c.fields[i]&(1<<uint(v)) != 0
+}
+
+// dayMatches applies the day-of-month / day-of-week rule to a civil date
+func (c *cronSchedule) dayMatches(d time.Time) bool {
+    dom, dow := c.has(2, d.Day()), c.has(4, int(d.Weekday()))
+    switch {
+    case c.domAny && c.dowAny:
+        return true
+    case c.domAny:
+        return dow
+    case c.dowAny:
+        return dom
+    }
+    return dom || dow
+}
+
+// next returns the first run strictly after after, in loc, or the zero time
+// if the expression matches no date in the next cronSearchYears years
+func (c *cronSchedule) next(after time.Time, loc *time.Location) time.Time {
+    local := after.In(loc)
+    day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+    h, m := local.Hour(), local.Minute()+1 // runs are on whole minutes
+    end := day.AddDate(cronSearchYears, 0, 0)
+
+    for ; day.Before(end); day, h, m = day.AddDate(0, 0, 1), 0, 0 {
+        if !c.has(3, int(day.Month())) || !c.dayMatches(day) {
+            continue
+        }
+        for ; h < 24; h, m = h+1, 0 {
+            if !c.has(1, h) {
+                continue
+            }
+            for ; m < 60; m++ {
+                if !c.has(0, m) {
+                    continue
+                }
+                t := forwardDate(day.Year(), day.Month(), day.Day(), h, m, 0, loc)
+                if t.After(after) {
+                    return t
+                }
+            }
+        }
+    }
+    return time.Time{}
+}
+
+// forwardDate is time.Date for a wall-clock time in loc, except that a time
+// skipped by a spring-forward transition moves forward by the size of the
+// gap: 02:30 on a day that jumps from 02:00 to 03:00 gives 03:30
+func forwardDate(year int, month time.Month, day, hour, min, sec int, loc *time.Location) time.Time {
+    t := time.Date(year, month, day, hour, min, sec, 0, loc)
+    want := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+    if gap := want.Sub(wallClock(t)); gap > 0 {
+        t = t.Add(gap)
+    }
+    return t
+}
+
+// String returns the expression as given
+func (c *cronSchedule) String() string {
+    return c.expr
+}
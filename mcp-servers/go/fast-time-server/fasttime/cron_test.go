@@ -0,0 +1,70 @@
+// -*- coding: utf-8 -*-
+// cron_test.go - Tests for cron expressions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseCron(t *testing.T) {
+    for _, expr := range []string{"* * * * *", "*/15 9-17 * * MON-FRI", "0 0 1,15 * *", "30 2 * JAN-MAR 0,7", "@daily", "@Hourly"} {
+        if _, err := parseCron(expr); err != nil {
+            t.Errorf("parseCron(%q): %v", expr, err)
+        }
+    }
+    for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "* * * FOO *", "@often"} {
+        if _, err := parseCron(expr); err == nil {
+            t.Errorf("parseCron(%q) should fail", expr)
+        }
+    }
+}
+
+func TestCronNext(t *testing.T) {
+    ny, _ := time.LoadLocation("America/New_York")
+    base := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC) // Wednesday, 07:00 EST
+
+    tests := []struct {
+        expr string
+        loc  *time.Location
+        want string
+    }{
+        {"*/15 * * * *", time.UTC, "2025-03-05T12:15:00Z"},
+        {"0 9 * * MON-FRI", ny, "2025-03-05T09:00:00-05:00"},
+        {"0 9 * * SAT", ny, "2025-03-08T09:00:00-05:00"},
+        {"0 9 * * 7", ny, "2025-03-09T09:00:00-04:00"}, // Sunday, after the DST change
+        {"@monthly", time.UTC, "2025-04-01T00:00:00Z"},
+        // Both day fields restricted: either may match (the 6th is a Thursday)
+        {"0 0 6 * MON", time.UTC, "2025-03-06T00:00:00Z"},
+        // 02:30 does not exist on 2025-03-09 in New York
+        {"30 2 9 3 *", ny, "2025-03-09T03:30:00-04:00"},
+        // 01:30 happens twice on 2025-11-02; the first one runs
+        {"30 1 2 11 *", ny, "2025-11-02T01:30:00-04:00"},
+        {"0 12 29 2 *", time.UTC, "2028-02-29T12:00:00Z"},
+    }
+    for _, tt := range tests {
+        c, err := parseCron(tt.expr)
+        if err != nil {
+            t.Fatalf("parseCron(%q): %v", tt.expr, err)
+        }
+        if got := c.next(base, tt.loc).Format(time.RFC3339); got != tt.want {
+            t.Errorf("%q next after %s = %s, want %s", tt.expr, base.Format(time.RFC3339), got, tt.want)
+        }
+    }
+
+    // Runs are strictly after the given time
+    c, _ := parseCron("0 12 * * *")
+    if got := c.next(base, time.UTC); !got.Equal(base.AddDate(0, 0, 1)) {
+        t.Errorf("next at a run time = %s, want the following day", got)
+    }
+
+    // A date that never exists gives the zero time
+    c, _ = parseCron("0 0 30 2 *")
+    if got := c.next(base, time.UTC); !got.IsZero() {
+        t.Errorf("0 0 30 2 * next = %s, want zero", got)
+    }
+}
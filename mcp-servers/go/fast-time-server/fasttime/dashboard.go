@@ -0,0 +1,208 @@
+// -*- coding: utf-8 -*-
+// dashboard.go - embedded operator dashboard for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file serves a small single-page dashboard for a quick health check
+// without external tooling. The page at /dashboard is a static shell with no
+// data of its own: it asks for the admin token and polls
+// GET /admin/dashboard/data, which reports the request rate, active SSE
+// clients, per-tool latencies, recent errors and a world clock. Both are
+// mounted together with the admin API.
+
+package fasttime
+
+import (
+    _ "embed"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+)
+
+// dashboardPath is where the dashboard page is served
+const dashboardPath = "/dashboard"
+
+const (
+    meterWindow     = 60 // seconds of request history kept for the rate
+    maxRecentErrors = 20 // errors kept for the dashboard
+)
+
+//go:embed static/dashboard.html
+var dashboardHTML []byte
+
+/* ------------------------------------------------------------------ */
+/*                          request meter                             */
+/* ------------------------------------------------------------------ */
+
+// requestMeter counts HTTP requests in one-second buckets
+type requestMeter struct {
+    mu      sync.Mutex
+    total   int64
+    counts  [meterWindow]int64
+    seconds [meterWindow]int64 // unix second each bucket currently holds
+}
+
+// requests is the process-wide request meter
+var requests = &requestMeter{}
+
+// hit counts one request at now
+func (m *requestMeter) hit(now time.Time) {
+    sec := now.Unix()
+    i := sec % meterWindow
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.seconds[i] != sec {
+        m.seconds[i], m.counts[i] = sec, 0
+    }
+    m.counts[i]++
+    m.total++
+}
+
+// series returns the per-second counts of the last meterWindow seconds
+// ending at now, oldest first, and the total since startup
+func (m *requestMeter) series(now time.Time) ([]int64, int64) {
+    end := now.Unix()
+    out := make([]int64, meterWindow)
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for k := 0; k < meterWindow; k++ {
+        sec := end - meterWindow + 1 + int64(k)
+        if i := sec % meterWindow; m.seconds[i] == sec {
+            out[k] = m.counts[i]
+        }
+    }
+    return out, m.total
+}
+
+// middleware counts every request and records server errors
+func (m *requestMeter) middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        m.hit(time.Now())
+        sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+        next.ServeHTTP(sw, r)
+        if sw.status >= http.StatusInternalServerError {
+            recentErrors.add("http", r.Method+" "+r.URL.Path+": "+http.StatusText(sw.status))
+        }
+    })
+}
+
+/* ------------------------------------------------------------------ */
+/*                           recent errors                            */
+/* ------------------------------------------------------------------ */
+
+// errorEntry is one recorded failure
+type errorEntry struct {
+    Time    string `json:"time"`
+    Source  string `json:"source"`
+    Message string `json:"message"`
+}
+
+// errorLog keeps the most recent failures, newest last
+type errorLog struct {
+    mu      sync.Mutex
+    entries []errorEntry
+}
+
+// recentErrors is the process-wide error log shown on the dashboard
+var recentErrors = &errorLog{}
+
+// add records a failure
+func (el *errorLog) add(source, msg string) {
+    el.mu.Lock()
+    defer el.mu.Unlock()
+    el.entries = append(el.entries, errorEntry{
+        Time:    time.Now().UTC().Format(time.RFC3339),
+        Source:  source,
+        Message: msg,
+    })
+    if n := len(el.entries); n > maxRecentErrors {
+        el.entries = append(el.entries[:0], el.entries[n-maxRecentErrors:]...)
+    }
+}
+
+// list returns the recorded failures, newest first
+func (el *errorLog) list() []errorEntry {
+    el.mu.Lock()
+    defer el.mu.Unlock()
+    out := make([]errorEntry, len(el.entries))
+    for i, e := range el.entries {
+        out[len(out)-1-i] = e
+    }
+    return out
+}
+
+/* ------------------------------------------------------------------ */
+/*                             handlers                               */
+/* ------------------------------------------------------------------ */
+
+// handleDashboard handles GET /dashboard
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet && r.Method != http.MethodHead {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    w.Header().Set("Cache-Control", "no-store")
+    w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'unsafe-inline'")
+    _, _ = w.Write(dashboardHTML)
+}
+
+// worldClock returns the current time in the dashboard cities, sorted by name
+func worldClock(now time.Time) []map[string]interface{} {
+    cities := make([]string, 0, len(worldCities))
+    for city := range worldCities {
+        cities = append(cities, city)
+    }
+    sort.Strings(cities)
+
+    out := make([]map[string]interface{}, 0, len(cities))
+    for _, city := range cities {
+        tz := worldCities[city]
+        loc, err := loadLocation(tz)
+        if err != nil {
+            continue
+        }
+        local := now.In(loc)
+        _, offset := local.Zone()
+        out = append(out, map[string]interface{}{
+            "city":           city,
+            "timezone":       tz,
+            "time":           local.Format(time.RFC3339),
+            "offset_seconds": offset,
+        })
+    }
+    return out
+}
+
+// handleDashboardData handles GET /admin/dashboard/data
+func handleDashboardData(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    now := time.Now()
+    series, total := requests.series(now)
+    var sum int64
+    for _, n := range series {
+        sum += n
+    }
+
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "name":           appName,
+        "version":        appVersion,
+        "uptime_seconds": int(time.Since(startTime).Seconds()),
+        "requests": map[string]interface{}{
+            "total":      total,
+            "per_second": float64(sum) / meterWindow,
+            "series":     series,
+        },
+        "sse_clients":   sseConns.active(),
+        "sessions":      sessions.count(),
+        "tools":         toolStats.snapshot(),
+        "recent_errors": recentErrors.list(),
+        "world_clock":   worldClock(now),
+        "server_time":   now.UTC().Format(time.RFC3339),
+    })
+}
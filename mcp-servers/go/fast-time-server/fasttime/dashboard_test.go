@@ -0,0 +1,106 @@
+// -*- coding: utf-8 -*-
+// dashboard_test.go - Tests for the operator dashboard
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestRequestMeterSeries(t *testing.T) {
+    m := &requestMeter{}
+    base := time.Unix(1_700_000_000, 0)
+    m.hit(base)
+    m.hit(base)
+    m.hit(base.Add(2 * time.Second))
+
+    series, total := m.series(base.Add(2 * time.Second))
+    if total != 3 {
+        t.Errorf("total = %d, want 3", total)
+    }
+    if len(series) != meterWindow || series[meterWindow-1] != 1 || series[meterWindow-3] != 2 {
+        t.Errorf("series tail = %v", series[meterWindow-3:])
+    }
+
+    // A bucket reused after a full window must not carry stale counts
+    m.hit(base.Add(meterWindow * time.Second))
+    series, _ = m.series(base.Add(meterWindow * time.Second))
+    if series[meterWindow-1] != 1 || series[0] != 0 {
+        t.Errorf("after wrap: first=%d last=%d, want 0 and 1", series[0], series[meterWindow-1])
+    }
+}
+
+func TestErrorLogKeepsNewest(t *testing.T) {
+    el := &errorLog{}
+    for i := 0; i < maxRecentErrors+5; i++ {
+        el.add("test", fmt.Sprintf("error %d", i))
+    }
+    got := el.list()
+    if len(got) != maxRecentErrors {
+        t.Fatalf("len = %d, want %d", len(got), maxRecentErrors)
+    }
+    if want := fmt.Sprintf("error %d", maxRecentErrors+4); got[0].Message != want {
+        t.Errorf("newest = %q, want %q", got[0].Message, want)
+    }
+}
+
+func TestDashboardRoutes(t *testing.T) {
+    failing := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusServiceUnavailable)
+    })
+    h := adminMiddleware(newBearerToken("admin-secret"), failing)
+    do := func(path, auth string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodGet, path, nil)
+        if auth != "" {
+            req.Header.Set("Authorization", "Bearer "+auth)
+        }
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        return w
+    }
+
+    // The page shell needs no token; it carries no data
+    if w := do("/dashboard", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "admin/dashboard/data") {
+        t.Errorf("/dashboard: %d", w.Code)
+    }
+    if w := do("/admin/dashboard/data", ""); w.Code != http.StatusUnauthorized {
+        t.Errorf("data without token: %d", w.Code)
+    }
+
+    _, before := requests.series(time.Now())
+    do("/api/v1/time", "")
+
+    w := do("/admin/dashboard/data", "admin-secret")
+    if w.Code != http.StatusOK {
+        t.Fatalf("data: %d %s", w.Code, w.Body)
+    }
+    var body struct {
+        Requests struct {
+            Total  int64   `json:"total"`
+            Series []int64 `json:"series"`
+        } `json:"requests"`
+        RecentErrors []errorEntry      `json:"recent_errors"`
+        WorldClock   []json.RawMessage `json:"world_clock"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatal(err)
+    }
+    if body.Requests.Total < before+2 || len(body.Requests.Series) != meterWindow {
+        t.Errorf("requests = %+v, want total >= %d", body.Requests, before+2)
+    }
+    if len(body.RecentErrors) == 0 || !strings.Contains(body.RecentErrors[0].Message, "/api/v1/time") {
+        t.Errorf("recent errors = %+v", body.RecentErrors)
+    }
+    if len(body.WorldClock) != len(worldCities) {
+        t.Errorf("world clock has %d cities, want %d", len(body.WorldClock), len(worldCities))
+    }
+}
@@ -0,0 +1,136 @@
+// -*- coding: utf-8 -*-
+// debug.go - pprof and runtime debug endpoints for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file exposes /debug/pprof/* and /debug/vars when the server is started
+// with -debug. Both are protected by a dedicated admin token (-admin-token or
+// ADMIN_TOKEN) that is independent of the regular client Bearer token.
+
+package fasttime
+
+import (
+    "crypto/subtle"
+    "net/http"
+    "net/http/pprof"
+    "runtime"
+    "runtime/debug"
+    "strings"
+    "time"
+)
+
+// debugPathPrefix is the URL prefix served by the debug handler
+const debugPathPrefix = "/debug/"
+
+// adminAuthMiddleware checks for the admin Bearer token
+func adminAuthMiddleware(token *bearerToken, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        const bearerPrefix = "Bearer "
+        authHeader := r.Header.Get("Authorization")
+        if !strings.HasPrefix(authHeader, bearerPrefix) {
+            logAt(logWarn, "missing admin authorization from %s for %s", r.RemoteAddr, r.URL.Path)
+            w.Header().Set("WWW-Authenticate", `Bearer realm="MCP Server Admin"`)
+            writeJSONError(w, http.StatusUnauthorized, "Admin authorization required")
+            return
+        }
+
+        provided := strings.TrimPrefix(authHeader, bearerPrefix)
+        if subtle.ConstantTimeCompare([]byte(provided), []byte(token.get())) != 1 {
+            logAt(logWarn, "invalid admin token from %s", r.RemoteAddr)
+            writeJSONError(w, http.StatusUnauthorized, "Invalid admin token")
+            return
+        }
+
+        next.ServeHTTP(w, r)
+    })
+}
+
+// debugMiddleware serves /debug/* with admin auth and passes everything else
+// to next. It sits in front of the regular auth chain so the admin token is
+// accepted on its own.
+func debugMiddleware(adminToken *bearerToken, next http.Handler) http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/debug/pprof/", pprof.Index)
+    mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+    mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+    mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+    mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+    mux.HandleFunc("/debug/vars", handleDebugVars)
+
+    debugHandler := loggingHTTPMiddleware(adminAuthMiddleware(adminToken, mux))
+
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if strings.HasPrefix(r.URL.Path, debugPathPrefix) {
+            debugHandler.ServeHTTP(w, r)
+            return
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// handleDebugVars handles GET /debug/vars with goroutine, memory and GC stats
+func handleDebugVars(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    writeJSON(w, http.StatusOK, debugVars())
+}
+
+// debugVars collects a runtime snapshot
+func debugVars() map[string]interface{} {
+    var ms runtime.MemStats
+    runtime.ReadMemStats(&ms)
+
+    var gc debug.GCStats
+    gc.PauseQuantiles = make([]time.Duration, 5) // min, 25%, 50%, 75%, max
+    debug.ReadGCStats(&gc)
+
+    var lastGC string
+    if !gc.LastGC.IsZero() {
+        lastGC = gc.LastGC.UTC().Format(time.RFC3339Nano)
+    }
+
+    return map[string]interface{}{
+        "uptime_seconds": int(time.Since(startTime).Seconds()),
+        "go_version":     runtime.Version(),
+        "goroutines":     runtime.NumGoroutine(),
+        "num_cpu":        runtime.NumCPU(),
+        "gomaxprocs":     runtime.GOMAXPROCS(0),
+        "memstats": map[string]interface{}{
+            "alloc_bytes":       ms.Alloc,
+            "total_alloc_bytes": ms.TotalAlloc,
+            "sys_bytes":         ms.Sys,
+            "heap_alloc_bytes":  ms.HeapAlloc,
+            "heap_inuse_bytes":  ms.HeapInuse,
+            "heap_idle_bytes":   ms.HeapIdle,
+            "heap_objects":      ms.HeapObjects,
+            "stack_inuse_bytes": ms.StackInuse,
+            "mallocs":           ms.Mallocs,
+            "frees":             ms.Frees,
+        },
+        "gc": map[string]interface{}{
+            "num_gc":          gc.NumGC,
+            "last_gc":         lastGC,
+            "pause_total_ns":  gc.PauseTotal.Nanoseconds(),
+            "pause_quantiles": durationsToNanos(gc.PauseQuantiles),
+            "next_gc_bytes":   ms.NextGC,
+            "gc_cpu_fraction": ms.GCCPUFraction,
+        },
+        "tzdata":        tzdata.stats(),
+        "tz_cache":      tzCache.stats(),
+        "outbound":      outbound.stats(),
+        "workers":       heavyPool.stats(),
+        "slow_requests": slowRequests.count(),
+    }
+}
+
+// durationsToNanos converts durations to integer nanoseconds for JSON output
+func durationsToNanos(ds []time.Duration) []int64 {
+    out := make([]int64, len(ds))
+    for i, d := range ds {
+        out[i] = d.Nanoseconds()
+    }
+    return out
+}
@@ -0,0 +1,72 @@
+// -*- coding: utf-8 -*-
+// debug_test.go - Tests for debug endpoints
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestDebugMiddleware(t *testing.T) {
+    const adminToken = "admin-secret"
+    next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+        w.WriteHeader(http.StatusTeapot)
+    })
+    // Regular auth guards everything else; debug endpoints use the admin token
+    h := debugMiddleware(newBearerToken(adminToken), authMiddleware(newBearerToken("client-secret"), next))
+
+    tests := []struct {
+        name       string
+        path       string
+        token      string
+        wantStatus int
+    }{
+        {"vars without token", "/debug/vars", "", http.StatusUnauthorized},
+        {"vars with client token", "/debug/vars", "client-secret", http.StatusUnauthorized},
+        {"vars with admin token", "/debug/vars", adminToken, http.StatusOK},
+        {"pprof index with admin token", "/debug/pprof/", adminToken, http.StatusOK},
+        {"other path with client token", "/other", "client-secret", http.StatusTeapot},
+        {"other path with admin token", "/other", adminToken, http.StatusUnauthorized},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+            if tt.token != "" {
+                req.Header.Set("Authorization", "Bearer "+tt.token)
+            }
+            w := httptest.NewRecorder()
+            h.ServeHTTP(w, req)
+            if w.Code != tt.wantStatus {
+                t.Errorf("want status %d, got %d", tt.wantStatus, w.Code)
+            }
+        })
+    }
+}
+
+func TestHandleDebugVars(t *testing.T) {
+    w := httptest.NewRecorder()
+    handleDebugVars(w, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+    if w.Code != http.StatusOK {
+        t.Fatalf("want status 200, got %d", w.Code)
+    }
+
+    var body map[string]interface{}
+    if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+        t.Fatalf("failed to decode response: %v", err)
+    }
+    if g, ok := body["goroutines"].(float64); !ok || g < 1 {
+        t.Errorf("goroutines should be positive, got %v", body["goroutines"])
+    }
+    for _, key := range []string{"memstats", "gc"} {
+        if _, ok := body[key].(map[string]interface{}); !ok {
+            t.Errorf("%s section missing", key)
+        }
+    }
+}
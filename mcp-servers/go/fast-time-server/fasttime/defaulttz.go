@@ -0,0 +1,66 @@
+// -*- coding: utf-8 -*-
+// defaulttz.go - default timezone selection
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file decides which zone get_system_time and GET /api/v1/time use when
+// the caller does not name one. In order of precedence:
+//
+//   - REST: the X-Default-Timezone request header
+//   - MCP:  "defaultTimezone" in the client's experimental capabilities
+//           sent with initialize, remembered for the session
+//   - the server-wide -default-timezone flag (UTC when unset)
+
+package fasttime
+
+import (
+    "context"
+    "net/http"
+    "strings"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultTZHeader is the REST request header naming the default zone
+const defaultTZHeader = "X-Default-Timezone"
+
+// defaultTZCapability is the experimental initialize capability naming the
+// session's default zone
+const defaultTZCapability = "defaultTimezone"
+
+// defaultTimezone is the server-wide default (set from -default-timezone)
+var defaultTimezone = "UTC"
+
+// defaultTimezoneFor returns the default zone for the MCP session in ctx
+func defaultTimezoneFor(ctx context.Context) string {
+    if id := sessionIDFrom(ctx); id != "" {
+        if tz := sessions.defaultTimezone(id); tz != "" {
+            return tz
+        }
+    }
+    return defaultTimezone
+}
+
+// requestDefaultTimezone returns the default zone for a REST request
+func requestDefaultTimezone(r *http.Request) string {
+    if tz := strings.TrimSpace(r.Header.Get(defaultTZHeader)); tz != "" {
+        return tz
+    }
+    return defaultTimezone
+}
+
+// initDefaultTimezone reads the session default from initialize; an unknown
+// zone is ignored so the session falls back to the server default
+func initDefaultTimezone(req *mcp.InitializeRequest) string {
+    tz, _ := req.Params.Capabilities.Experimental[defaultTZCapability].(string)
+    tz = strings.TrimSpace(tz)
+    if tz == "" {
+        return ""
+    }
+    if _, err := loadLocation(tz); err != nil {
+        logAt(logWarn, "ignoring invalid %s %q from client %s", defaultTZCapability, tz, req.Params.ClientInfo.Name)
+        return ""
+    }
+    return tz
+}
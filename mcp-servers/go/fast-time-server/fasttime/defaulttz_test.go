@@ -0,0 +1,90 @@
+// -*- coding: utf-8 -*-
+// defaulttz_test.go - Tests for default timezone selection
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// withDefaultTimezone sets the server default for the duration of a test
+func withDefaultTimezone(t *testing.T, tz string) {
+    old := defaultTimezone
+    defaultTimezone = tz
+    t.Cleanup(func() { defaultTimezone = old })
+}
+
+func TestGetSystemTimeDefault(t *testing.T) {
+    withDefaultTimezone(t, "Asia/Tokyo")
+
+    res, _ := handleGetSystemTime(context.Background(), testRequest("get_system_time", map[string]any{}))
+    text := res.Content[0].(mcp.TextContent).Text
+    if !strings.HasSuffix(text, "+09:00") {
+        t.Errorf("want Tokyo time, got %s", text)
+    }
+
+    res, _ = handleGetSystemTime(context.Background(), testRequest("get_system_time", map[string]any{"timezone": "UTC"}))
+    if text := res.Content[0].(mcp.TextContent).Text; !strings.HasSuffix(text, "Z") {
+        t.Errorf("explicit timezone should win, got %s", text)
+    }
+}
+
+func TestSessionDefaultTimezone(t *testing.T) {
+    withDefaultTimezone(t, "UTC")
+    sessions.add("tz-session", time.Now())
+    defer sessions.remove("tz-session")
+
+    req := &mcp.InitializeRequest{}
+    req.Params.Capabilities.Experimental = map[string]any{defaultTZCapability: "Europe/Berlin"}
+    if tz := initDefaultTimezone(req); tz != "Europe/Berlin" {
+        t.Fatalf("initDefaultTimezone = %q", tz)
+    }
+    sessions.setDefaultTimezone("tz-session", "Europe/Berlin")
+    if tz := sessions.defaultTimezone("tz-session"); tz != "Europe/Berlin" {
+        t.Errorf("session default = %q", tz)
+    }
+
+    req.Params.Capabilities.Experimental = map[string]any{defaultTZCapability: "Mars/Olympus"}
+    if tz := initDefaultTimezone(req); tz != "" {
+        t.Errorf("invalid zone should be ignored, got %q", tz)
+    }
+    if tz := defaultTimezoneFor(context.Background()); tz != "UTC" {
+        t.Errorf("without a session the server default applies, got %q", tz)
+    }
+}
+
+func TestRESTDefaultTimezoneHeader(t *testing.T) {
+    withDefaultTimezone(t, "Asia/Tokyo")
+
+    get := func(header string) TimeResponse {
+        req := httptest.NewRequest(http.MethodGet, "/api/v1/time", nil)
+        if header != "" {
+            req.Header.Set(defaultTZHeader, header)
+        }
+        rec := httptest.NewRecorder()
+        handleRESTGetTime(rec, req)
+        var body TimeResponse
+        if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+            t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+        }
+        return body
+    }
+
+    if body := get(""); body.Timezone != "Asia/Tokyo" {
+        t.Errorf("server default: got %s", body.Timezone)
+    }
+    if body := get("Europe/Paris"); body.Timezone != "Europe/Paris" {
+        t.Errorf("header default: got %s", body.Timezone)
+    }
+}
@@ -0,0 +1,90 @@
+// -*- coding: utf-8 -*-
+// docs.go - embedded Swagger UI for the REST API
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file serves interactive API documentation at /api/v1/docs, rendered
+// from /api/v1/openapi.json by a copy of Swagger UI embedded in the binary,
+// so the docs work offline and behind firewalls. The spec declares Bearer
+// authentication, and the UI's Authorize button sends the token with every
+// "Try it out" request. The docs page, its assets and the spec are public so
+// that a token can be entered there in the first place.
+
+package fasttime
+
+import (
+    "embed"
+    "io/fs"
+    "net/http"
+    "strings"
+)
+
+// docsAssetsPrefix is the URL prefix of the embedded Swagger UI assets
+const docsAssetsPrefix = "/api/v1/docs/"
+
+//go:embed static/swagger-ui/*.js static/swagger-ui/*.css
+var swaggerUI embed.FS
+
+// docsHTML is the Swagger UI page; assets are served from docsAssetsPrefix
+const docsHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Fast Time Server API Documentation</title>
+    <link rel="stylesheet" href="/api/v1/docs/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="/api/v1/docs/swagger-ui-bundle.js"></script>
+    <script>
+        window.onload = function() {
+            window.ui = SwaggerUIBundle({
+                url: "/api/v1/openapi.json",
+                dom_id: '#swagger-ui',
+                deepLinking: true,
+                persistAuthorization: true,
+                presets: [SwaggerUIBundle.presets.apis],
+                layout: "BaseLayout"
+            });
+        }
+    </script>
+</body>
+</html>`
+
+// isPublicDocsPath reports whether path is part of the API docs, which are
+// served without authentication
+func isPublicDocsPath(path string) bool {
+    return path == "/api/v1/docs" || path == "/api/v1/openapi.json" || path == "/api/v2/openapi.json" || strings.HasPrefix(path, docsAssetsPrefix)
+}
+
+// handleAPIDocs handles GET /api/v1/docs
+func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    w.WriteHeader(http.StatusOK)
+    _, _ = w.Write([]byte(docsHTML))
+}
+
+// docsAssetsHandler serves the embedded Swagger UI files under docsAssetsPrefix
+func docsAssetsHandler() http.Handler {
+    assets, err := fs.Sub(swaggerUI, "static/swagger-ui")
+    if err != nil {
+        panic(err) // the embedded directory is fixed at build time
+    }
+    files := http.StripPrefix(docsAssetsPrefix, http.FileServer(http.FS(assets)))
+
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == docsAssetsPrefix {
+            http.Redirect(w, r, "/api/v1/docs", http.StatusMovedPermanently)
+            return
+        }
+        // The assets only change with the binary
+        w.Header().Set("Cache-Control", "public, max-age=86400")
+        files.ServeHTTP(w, r)
+    })
+}
@@ -0,0 +1,63 @@
+// -*- coding: utf-8 -*-
+// docs_test.go - Tests for the embedded Swagger UI
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestAPIDocsServedOffline(t *testing.T) {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    h := authMiddleware(newBearerToken("secret"), mux)
+
+    tests := []struct {
+        path, contentType string
+        code              int
+    }{
+        {"/api/v1/docs", "text/html", http.StatusOK},
+        {"/api/v1/docs/swagger-ui-bundle.js", "javascript", http.StatusOK},
+        {"/api/v1/docs/swagger-ui.css", "text/css", http.StatusOK},
+        {"/api/v1/openapi.json", "application/json", http.StatusOK},
+        {"/api/v1/docs/missing.js", "", http.StatusNotFound},
+        {"/api/v1/docs/", "", http.StatusMovedPermanently},
+        {"/api/v1/time", "", http.StatusUnauthorized}, // everything else still needs the token
+    }
+    for _, tt := range tests {
+        req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        if w.Code != tt.code {
+            t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.code)
+            continue
+        }
+        if !strings.Contains(w.Header().Get("Content-Type"), tt.contentType) {
+            t.Errorf("%s: Content-Type = %q, want %q", tt.path, w.Header().Get("Content-Type"), tt.contentType)
+        }
+    }
+}
+
+func TestAPIDocsPageUsesEmbeddedAssets(t *testing.T) {
+    w := httptest.NewRecorder()
+    handleAPIDocs(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+    body := w.Body.String()
+    if strings.Contains(body, "https://") {
+        t.Error("docs page must not load assets from the network")
+    }
+    if !strings.Contains(body, "persistAuthorization") {
+        t.Error("docs page should keep the Bearer token between reloads")
+    }
+
+    spec := getOpenAPISpec()
+    schemes := spec["components"].(map[string]interface{})["securitySchemes"].(map[string]interface{})
+    if _, ok := schemes["bearerAuth"]; !ok {
+        t.Error("spec should declare bearerAuth so the UI offers Authorize")
+    }
+}
@@ -0,0 +1,432 @@
+// -*- coding: utf-8 -*-
+// doctor.go - deployment checks behind the doctor subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements Doctor, the pre-flight check run by
+// `fast-time-server doctor [server flags]`. It takes exactly the
+// configuration the server would be started with and, without serving
+// anything, checks:
+//
+//   - tzdata:  the timezone database loads, including -default-timezone
+//   - listen:  the address or pipe the transport would listen on is free
+//   - tls:     the certificate behind an https -public-url is valid and not
+//              about to expire
+//   - auth:    tokens and token files are usable and consistent
+//   - ntp:     the host clock agrees with -ntp-servers
+//   - config:  every file named by a flag (aliases, ACL, scoped tokens,
+//              plugins, downstream servers, translations, replay
+//              recordings, the -db database) parses, and plugin commands
+//              exist
+//
+// Each problem is printed with a suggested fix. The exit status is 1 when any
+// check fails; warnings alone exit 0.
+
+package fasttime
+
+import (
+    "context"
+    "crypto/tls"
+    "fmt"
+    "io"
+    "math"
+    "net"
+    "net/url"
+    "os"
+    "os/exec"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// doctorStatus is the outcome of one check
+type doctorStatus int
+
+const (
+    doctorOK doctorStatus = iota
+    doctorSkip
+    doctorWarn
+    doctorFail
+)
+
+// String returns the label printed for s
+func (s doctorStatus) String() string {
+    switch s {
+    case doctorOK:
+        return " OK "
+    case doctorSkip:
+        return "SKIP"
+    case doctorWarn:
+        return "WARN"
+    }
+    return "FAIL"
+}
+
+// doctorResult is one line of the report
+type doctorResult struct {
+    check  string
+    status doctorStatus
+    detail string
+    fix    string // what to do about a warning or failure
+}
+
+// serverFlags returns the value of a server flag by name, as a Config field
+// tagged with that name renders it
+type serverFlags func(name string) string
+
+// duration returns a duration flag, or 0 when unset or invalid
+func (fv serverFlags) duration(name string) time.Duration {
+    d, _ := time.ParseDuration(fv(name))
+    return d
+}
+
+// certExpiryWarning is how close to expiry a certificate draws a warning
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// defaultDoctorDrift is the NTP limit used when -ntp-max-drift is not set
+const defaultDoctorDrift = time.Second
+
+// doctorTimeout bounds the whole report, network checks included
+const doctorTimeout = 30 * time.Second
+
+// doctorChecks lists the checks in the order they run
+var doctorChecks = []func(context.Context, serverFlags) []doctorResult{
+    doctorTzdata,
+    doctorListen,
+    doctorTLS,
+    doctorAuth,
+    doctorNTP,
+    doctorConfig,
+}
+
+// Doctor checks a deployment of cfg without serving anything, prints the
+// report to out and returns the exit status: 1 when a check fails, else 0
+func Doctor(cfg Config, out io.Writer) int {
+    values := configValues(cfg)
+    return runDoctor(func(name string) string { return values[name] }, out)
+}
+
+// runDoctor runs every check, prints the report and returns the exit code
+func runDoctor(fv serverFlags, out io.Writer) int {
+    ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+    defer cancel()
+
+    fmt.Fprintf(out, "%s %s doctor\n\n", appName, appVersion)
+    var warnings, failures int
+    for _, check := range doctorChecks {
+        for _, r := range check(ctx, fv) {
+            fmt.Fprintf(out, "[%s] %-7s %s\n", r.status, r.check, r.detail)
+            if r.fix != "" && r.status >= doctorWarn {
+                fmt.Fprintf(out, "               fix: %s\n", r.fix)
+            }
+            switch r.status {
+            case doctorWarn:
+                warnings++
+            case doctorFail:
+                failures++
+            }
+        }
+    }
+    fmt.Fprintf(out, "\n%d failure(s), %d warning(s)\n", failures, warnings)
+    if failures > 0 {
+        return 1
+    }
+    return 0
+}
+
+// doctorTzdata checks that zones load, including -default-timezone
+func doctorTzdata(_ context.Context, fv serverFlags) []doctorResult {
+    fix := "install the tzdata package (apt install tzdata, apk add tzdata) or set ZONEINFO"
+    for _, zone := range []string{"America/New_York", "Europe/London", "Asia/Kolkata", "Australia/Lord_Howe"} {
+        if _, err := time.LoadLocation(zone); err != nil {
+            return []doctorResult{{"tzdata", doctorFail, fmt.Sprintf("cannot load %s: %v", zone, err), fix}}
+        }
+    }
+    res := []doctorResult{{check: "tzdata", status: doctorOK, detail: "timezone database " + tzdataVersion() + " loads"}}
+    if tz := fv("default-timezone"); tz != "" && tz != "UTC" && fv("aliases") == "" {
+        if _, err := time.LoadLocation(tz); err != nil {
+            res = append(res, doctorResult{"tzdata", doctorFail,
+                fmt.Sprintf("-default-timezone %q: %v", tz, err),
+                "use an IANA name such as Europe/Berlin (see GET /api/v1/timezones)"})
+        }
+    }
+    return res
+}
+
+// doctorListen checks that the listen address can be bound
+func doctorListen(_ context.Context, fv serverFlags) []doctorResult {
+    if fv("listeners") != "" {
+        return doctorListeners(fv("listeners"))
+    }
+    transport := strings.ToLower(fv("transport"))
+    if transport == "" || transport == "stdio" {
+        return []doctorResult{{check: "listen", status: doctorSkip, detail: "stdio transport does not listen"}}
+    }
+    if transport == "pipe" {
+        ln, err := listenPipe(fv("pipe"))
+        if err != nil {
+            return []doctorResult{{"listen", doctorFail, err.Error(), "stop the server already using it, or pick another -pipe"}}
+        }
+        defer ln.Close()
+        return []doctorResult{{check: "listen", status: doctorOK, detail: ln.Addr().String() + " is free"}}
+    }
+    if os.Getenv("LISTEN_FDS") != "" || os.Getenv(envListenFD) != "" {
+        return []doctorResult{{check: "listen", status: doctorSkip, detail: "socket is passed in by systemd or a previous process"}}
+    }
+    port, _ := strconv.Atoi(fv("port"))
+    addr := effectiveAddr(fv("addr"), fv("listen"), port)
+    ln, err := net.Listen("tcp", addr)
+    if err != nil {
+        return []doctorResult{{"listen", doctorFail, fmt.Sprintf("cannot bind %s: %v", addr, err),
+            "stop whatever uses the port, pick another -port, or allow low ports (setcap cap_net_bind_service=+ep)"}}
+    }
+    ln.Close()
+    return []doctorResult{{check: "listen", status: doctorOK, detail: addr + " is free"}}
+}
+
+// doctorListeners checks that every -listeners address can be bound
+func doctorListeners(value string) []doctorResult {
+    specs, err := parseListeners(value)
+    if err != nil {
+        return []doctorResult{{"listen", doctorFail, err.Error(), "use KIND=ADDR entries such as sse=:8080,admin=unix:/run/fast-time-admin.sock"}}
+    }
+    var res []doctorResult
+    for _, spec := range specs {
+        var ln net.Listener
+        if spec.kind == "pipe" {
+            ln, err = listenPipe(spec.addr)
+        } else {
+            ln, err = listenAddr(spec.addr)
+        }
+        if err != nil {
+            res = append(res, doctorResult{"listen", doctorFail, fmt.Sprintf("%s listener: %v", spec.kind, err),
+                "stop whatever uses the address, or pick another one in -listeners"})
+            continue
+        }
+        res = append(res, doctorResult{check: "listen", status: doctorOK, detail: fmt.Sprintf("%s listener: %s is free", spec.kind, ln.Addr())})
+        ln.Close()
+    }
+    return res
+}
+
+// doctorTLS checks the certificate served for an https -public-url
+func doctorTLS(ctx context.Context, fv serverFlags) []doctorResult {
+    raw := fv("public-url")
+    u, err := url.Parse(raw)
+    if raw == "" || err != nil || u.Scheme != "https" {
+        return []doctorResult{{check: "tls", status: doctorSkip, detail: "no https -public-url to check"}}
+    }
+    host := u.Host
+    if u.Port() == "" {
+        host = net.JoinHostPort(u.Hostname(), "443")
+    }
+    return []doctorResult{checkCertificate(ctx, host, u.Hostname(), currentTime())}
+}
+
+// checkCertificate verifies the certificate chain served at addr for name
+// and how long it remains valid at now
+func checkCertificate(ctx context.Context, addr, name string, now time.Time) doctorResult {
+    d := tls.Dialer{Config: &tls.Config{ServerName: name}}
+    conn, err := d.DialContext(ctx, "tcp", addr)
+    if err != nil {
+        return doctorResult{"tls", doctorFail, fmt.Sprintf("%s: %v", addr, err),
+            "serve a certificate for " + name + " signed by a trusted CA, covering this host name"}
+    }
+    defer conn.Close()
+    certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+    leaf := certs[0]
+    left := leaf.NotAfter.Sub(now)
+    detail := fmt.Sprintf("%s certificate expires %s (%d days)", name, leaf.NotAfter.UTC().Format(time.RFC3339), int(left.Hours()/24))
+    switch {
+    case left <= 0:
+        return doctorResult{"tls", doctorFail, detail, "renew the certificate"}
+    case left < certExpiryWarning:
+        return doctorResult{"tls", doctorWarn, detail, "renew the certificate soon"}
+    }
+    return doctorResult{check: "tls", status: doctorOK, detail: detail}
+}
+
+// doctorAuth checks that tokens resolve and fit together
+func doctorAuth(_ context.Context, fv serverFlags) []doctorResult {
+    var res []doctorResult
+    authTok, err := resolveToken(fv("auth-token"), fv("auth-token-file"), envAuthToken)
+    if err != nil {
+        res = append(res, doctorResult{"auth", doctorFail, err.Error(), "make -auth-token-file readable and non-empty"})
+        authTok = newBearerToken("")
+    }
+    adminTok, err := resolveToken(fv("admin-token"), fv("admin-token-file"), envAdminToken)
+    if err != nil {
+        res = append(res, doctorResult{"auth", doctorFail, err.Error(), "make -admin-token-file readable and non-empty"})
+        adminTok = newBearerToken("")
+    }
+    scoped := 0
+    if path := fv("auth-tokens-file"); path != "" {
+        reg, err := loadTokenRegistry(path)
+        if err != nil {
+            res = append(res, doctorResult{"auth", doctorFail, "-auth-tokens-file: " + err.Error(), "fix the JSON; see Scoped Tokens in the README"})
+        } else {
+            scoped = reg.count()
+        }
+    }
+
+    transport := strings.ToLower(fv("transport"))
+    authOn := authTok.enabled() || scoped > 0
+    if fv("debug") == "true" && !adminTok.enabled() {
+        res = append(res, doctorResult{"auth", doctorFail, "-debug requires an admin token", "set -admin-token, -admin-token-file or ADMIN_TOKEN"})
+    }
+    if authTok.enabled() && adminTok.enabled() && authTok.get() == adminTok.get() {
+        res = append(res, doctorResult{"auth", doctorWarn, "the client token is also the admin token", "give admins a separate -admin-token"})
+    }
+    switch {
+    case fv("listeners") != "":
+        specs, _ := parseListeners(fv("listeners"))
+        for _, spec := range specs {
+            local := spec.kind == "pipe" || strings.HasPrefix(spec.addr, unixAddrPrefix) || isLoopbackListen("", spec.addr)
+            switch {
+            case spec.kind == "admin" && !adminTok.enabled():
+                res = append(res, doctorResult{"auth", doctorFail, "the admin listener requires an admin token", "set -admin-token, -admin-token-file or ADMIN_TOKEN"})
+            case local || spec.kind == "admin":
+            case spec.kind == "metrics":
+                res = append(res, doctorResult{"auth", doctorWarn, "the metrics listener on " + spec.addr + " has no authentication",
+                    "bind it to 127.0.0.1, or restrict it with -allow-ips"})
+            case !authOn:
+                res = append(res, doctorResult{"auth", doctorWarn, fmt.Sprintf("no authentication on the %s listener %s", spec.kind, spec.addr),
+                    "set -auth-token (or AUTH_TOKEN), or listen on 127.0.0.1"})
+            }
+        }
+    case transport == "stdio" || transport == "" || transport == "pipe":
+        if authOn {
+            res = append(res, doctorResult{"auth", doctorWarn, "tokens are ignored by the stdio and pipe transports", "drop the token flags, or serve over sse/http"})
+        }
+    case !authOn && !isLoopbackListen(fv("listen"), fv("addr")):
+        res = append(res, doctorResult{"auth", doctorWarn, "no authentication on a non-loopback address",
+            "set -auth-token (or AUTH_TOKEN), or listen on 127.0.0.1"})
+    }
+    if len(res) == 0 {
+        detail := "no authentication (loopback or stdio)"
+        if authOn {
+            detail = fmt.Sprintf("bearer token %s, %d scoped token(s), admin token %s", onOff(authTok.enabled()), scoped, onOff(adminTok.enabled()))
+        }
+        res = append(res, doctorResult{check: "auth", status: doctorOK, detail: detail})
+    }
+    return res
+}
+
+// onOff renders a bool for the report
+func onOff(b bool) string {
+    if b {
+        return "set"
+    }
+    return "unset"
+}
+
+// isLoopbackListen reports whether the server would only listen on loopback
+func isLoopbackListen(listen, addr string) bool {
+    host := listen
+    if addr != "" {
+        host, _, _ = net.SplitHostPort(addr)
+    }
+    if host == "localhost" {
+        return true
+    }
+    ip := net.ParseIP(host)
+    return ip != nil && ip.IsLoopback()
+}
+
+// doctorNTP compares the host clock with -ntp-servers
+func doctorNTP(ctx context.Context, fv serverFlags) []doctorResult {
+    servers := parseNTPServers(fv("ntp-servers"))
+    if len(servers) == 0 {
+        return []doctorResult{{check: "ntp", status: doctorSkip, detail: "no -ntp-servers configured"}}
+    }
+    limit := fv.duration("ntp-max-drift")
+    if limit <= 0 {
+        limit = defaultDoctorDrift
+    }
+    rep := checkClockAccuracy(ctx, servers, 3)
+    if rep.Reachable == 0 {
+        return []doctorResult{{"ntp", doctorWarn, "no NTP server reachable: " + strings.Join(servers, ", "),
+            "allow outbound UDP port 123, or point -ntp-servers at an internal server"}}
+    }
+    detail := fmt.Sprintf("host clock offset %.1fms, jitter %.1fms (%d/%d servers)", rep.OffsetMs, rep.JitterMs, rep.Reachable, len(servers))
+    if math.Abs(rep.OffsetMs) > durationMs(limit) {
+        return []doctorResult{{"ntp", doctorFail, detail + fmt.Sprintf(", limit %s", limit),
+            "enable time sync (timedatectl set-ntp true, chronyd or ntpd)"}}
+    }
+    return []doctorResult{{check: "ntp", status: doctorOK, detail: detail}}
+}
+
+// doctorConfig parses every file and value the flags point at
+func doctorConfig(_ context.Context, fv serverFlags) []doctorResult {
+    var res []doctorResult
+    check := func(what string, err error, fix string) {
+        if err != nil {
+            res = append(res, doctorResult{"config", doctorFail, what + ": " + err.Error(), fix})
+            return
+        }
+        res = append(res, doctorResult{check: "config", status: doctorOK, detail: what + " parses"})
+    }
+
+    if path := fv("aliases"); path != "" {
+        _, err := newAliasRegistry().loadFile(path)
+        check("-aliases "+path, err, `use a JSON object of alias to IANA zone, e.g. {"HQ": "Europe/Berlin"}`)
+    }
+    if path := fv("plugins"); path != "" {
+        plugins, err := loadPlugins(path)
+        check("-plugins "+path, err, "fix the JSON; see Plugin Tools in the README")
+        for _, p := range plugins {
+            if _, err := exec.LookPath(p.Command[0]); err != nil {
+                res = append(res, doctorResult{"config", doctorFail, fmt.Sprintf("plugin %s: %v", p.Name, err), "install the command or give its full path"})
+            }
+        }
+    }
+    if path := fv("downstream"); path != "" {
+        _, err := loadDownstream(path)
+        check("-downstream "+path, err, "fix the JSON; see Downstream Servers in the README")
+    }
+    if path := fv("free-busy-calendars"); path != "" {
+        _, err := loadFreeBusyCalendars(path)
+        check("-free-busy-calendars "+path, err, "fix the JSON; see Free/Busy Calendars in the README")
+    }
+    if fv("allow-ips") != "" || fv("deny-ips") != "" || fv("ip-acl-file") != "" {
+        _, err := loadIPACL(fv("allow-ips"), fv("deny-ips"), fv("ip-acl-file"))
+        check("IP allow/deny lists", err, "use IPs or CIDRs such as 10.0.0.0/8")
+    }
+    if fv("trusted-proxies") != "" {
+        _, err := parseIPNets(fv("trusted-proxies"))
+        check("-trusted-proxies", err, "use IPs or CIDRs such as 10.0.0.0/8")
+    }
+    if fv("enable-tools") != "" || fv("disable-tools") != "" {
+        _, err := parseFeatureFilter(fv("enable-tools"), fv("disable-tools"))
+        check("-enable-tools/-disable-tools", err, "use tool names, prompt:NAME or resource:URI, with * wildcards")
+    }
+    if dir := fv("i18n-dir"); dir != "" {
+        _, err := newCatalogSet().loadDir(dir)
+        check("-i18n-dir "+dir, err, "name catalogs <locale>.json, e.g. de.json")
+    }
+    if path := fv("replay"); path != "" {
+        _, _, err := loadReplayer(path)
+        check("-replay "+path, err, "replay a file written by -record")
+    }
+    if v := fv("mock-time"); v != "" {
+        _, err := time.Parse(time.RFC3339, v)
+        check("-mock-time", err, "use RFC3339, e.g. 2025-01-01T00:00:00Z")
+    }
+    if v := fv("time-offset"); v != "" {
+        _, err := parseClockOffset(v)
+        check("-time-offset", err, "use a duration such as +3h or +30d")
+    }
+    if path := fv("db"); path != "" {
+        st, err := openStore(path)
+        if err == nil {
+            err = st.Ping()
+            st.Close()
+        }
+        check("-db "+path, err, "check the path and permissions of the SQLite database")
+    }
+    if len(res) == 0 {
+        res = append(res, doctorResult{check: "config", status: doctorSkip, detail: "no configuration files given"})
+    }
+    return res
+}
@@ -0,0 +1,176 @@
+// -*- coding: utf-8 -*-
+// doctor_test.go - Tests for the doctor subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// doctorFlags serves flag values from a map; unset flags are empty
+func doctorFlags(values map[string]string) serverFlags {
+    return func(name string) string { return values[name] }
+}
+
+// statuses returns the statuses of results in order
+func statuses(results []doctorResult) []doctorStatus {
+    out := make([]doctorStatus, len(results))
+    for i, r := range results {
+        out[i] = r.status
+    }
+    return out
+}
+
+func TestDoctorTzdata(t *testing.T) {
+    res := doctorTzdata(context.Background(), doctorFlags(map[string]string{"default-timezone": "Europe/Berlin"}))
+    if len(res) != 1 || res[0].status != doctorOK {
+        t.Errorf("valid zone: %+v", res)
+    }
+    res = doctorTzdata(context.Background(), doctorFlags(map[string]string{"default-timezone": "Mars/Olympus"}))
+    if len(res) != 2 || res[1].status != doctorFail || res[1].fix == "" {
+        t.Errorf("unknown zone: %+v", res)
+    }
+}
+
+func TestDoctorListen(t *testing.T) {
+    if res := doctorListen(context.Background(), doctorFlags(map[string]string{"transport": "stdio"})); res[0].status != doctorSkip {
+        t.Errorf("stdio: %+v", res)
+    }
+
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Skipf("tcp not available: %v", err)
+    }
+    defer ln.Close()
+    busy := doctorFlags(map[string]string{"transport": "http", "addr": ln.Addr().String()})
+    if res := doctorListen(context.Background(), busy); res[0].status != doctorFail || !strings.Contains(res[0].fix, "port") {
+        t.Errorf("port in use: %+v", res)
+    }
+    free := doctorFlags(map[string]string{"transport": "http", "addr": "127.0.0.1:0"})
+    if res := doctorListen(context.Background(), free); res[0].status != doctorOK {
+        t.Errorf("free port: %+v", res)
+    }
+}
+
+func TestCheckCertificate(t *testing.T) {
+    srv := httptest.NewTLSServer(http.NotFoundHandler())
+    defer srv.Close()
+    addr := srv.Listener.Addr().String()
+
+    // The test certificate is self-signed, so verification must fail
+    if r := checkCertificate(context.Background(), addr, "example.com", time.Now()); r.status != doctorFail {
+        t.Errorf("untrusted certificate: %+v", r)
+    }
+    if res := doctorTLS(context.Background(), doctorFlags(map[string]string{"public-url": "http://example.com"})); res[0].status != doctorSkip {
+        t.Errorf("plain http should be skipped: %+v", res)
+    }
+}
+
+func TestDoctorAuth(t *testing.T) {
+    for _, env := range []string{envAuthToken, envAdminToken} {
+        t.Setenv(env, "")
+        os.Unsetenv(env)
+    }
+    cases := []struct {
+        name  string
+        flags map[string]string
+        want  []doctorStatus
+    }{
+        {"loopback without auth", map[string]string{"transport": "http", "listen": "127.0.0.1"}, []doctorStatus{doctorOK}},
+        {"public without auth", map[string]string{"transport": "http", "listen": "0.0.0.0"}, []doctorStatus{doctorWarn}},
+        {"public with auth", map[string]string{"transport": "sse", "listen": "0.0.0.0", "auth-token": "s3cret"}, []doctorStatus{doctorOK}},
+        {"debug without admin", map[string]string{"transport": "sse", "listen": "localhost", "debug": "true"}, []doctorStatus{doctorFail}},
+        {"shared token", map[string]string{"transport": "http", "listen": "localhost", "auth-token": "x", "admin-token": "x"}, []doctorStatus{doctorWarn}},
+        {"stdio with token", map[string]string{"transport": "stdio", "auth-token": "x"}, []doctorStatus{doctorWarn}},
+        {"missing token file", map[string]string{"transport": "stdio", "auth-token-file": "/nonexistent/token"}, []doctorStatus{doctorFail}},
+    }
+    for _, c := range cases {
+        got := statuses(doctorAuth(context.Background(), doctorFlags(c.flags)))
+        if len(got) != len(c.want) || got[0] != c.want[0] {
+            t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+        }
+    }
+}
+
+func TestDoctorNTP(t *testing.T) {
+    if res := doctorNTP(context.Background(), doctorFlags(nil)); res[0].status != doctorSkip {
+        t.Errorf("no servers: %+v", res)
+    }
+    unreachable := doctorFlags(map[string]string{"ntp-servers": "127.0.0.1:1"})
+    if res := doctorNTP(context.Background(), unreachable); res[0].status != doctorWarn {
+        t.Errorf("unreachable: %+v", res)
+    }
+    skewed := doctorFlags(map[string]string{"ntp-servers": fakeNTPServer(t, 3*time.Second), "ntp-max-drift": "1s"})
+    if res := doctorNTP(context.Background(), skewed); res[0].status != doctorFail {
+        t.Errorf("3s drift: %+v", res)
+    }
+    if res := doctorNTP(context.Background(), doctorFlags(map[string]string{"ntp-servers": fakeNTPServer(t, 0)})); res[0].status != doctorOK {
+        t.Errorf("in sync: %+v", res)
+    }
+}
+
+func TestDoctorConfig(t *testing.T) {
+    if res := doctorConfig(context.Background(), doctorFlags(nil)); res[0].status != doctorSkip {
+        t.Errorf("nothing configured: %+v", res)
+    }
+
+    dir := t.TempDir()
+    good := filepath.Join(dir, "aliases.json")
+    bad := filepath.Join(dir, "bad.json")
+    os.WriteFile(good, []byte(`{"HQ": "Europe/Berlin"}`), 0o600)
+    os.WriteFile(bad, []byte(`{"HQ": `), 0o600)
+
+    res := doctorConfig(context.Background(), doctorFlags(map[string]string{
+        "aliases":     good,
+        "allow-ips":   "10.0.0.0/8",
+        "replay":      bad,
+        "time-offset": "+3h",
+        "mock-time":   "yesterday",
+    }))
+    want := []doctorStatus{doctorOK, doctorOK, doctorFail, doctorFail, doctorOK}
+    got := statuses(res)
+    if len(got) != len(want) {
+        t.Fatalf("results = %+v", res)
+    }
+    for i := range want {
+        if got[i] != want[i] {
+            t.Errorf("result %d = %+v, want %v", i, res[i], want[i])
+        }
+    }
+}
+
+func TestRunDoctorExitCode(t *testing.T) {
+    defer func(prev []func(context.Context, serverFlags) []doctorResult) { doctorChecks = prev }(doctorChecks)
+    doctorChecks = []func(context.Context, serverFlags) []doctorResult{
+        func(context.Context, serverFlags) []doctorResult {
+            return []doctorResult{{"one", doctorWarn, "something odd", "look into it"}}
+        },
+    }
+    var out bytes.Buffer
+    if code := runDoctor(doctorFlags(nil), &out); code != 0 {
+        t.Errorf("warnings only: exit %d", code)
+    }
+    if !strings.Contains(out.String(), "fix: look into it") || !strings.Contains(out.String(), "0 failure(s), 1 warning(s)") {
+        t.Errorf("report:\n%s", out.String())
+    }
+
+    doctorChecks = append(doctorChecks, func(context.Context, serverFlags) []doctorResult {
+        return []doctorResult{{"two", doctorFail, "broken", "fix it"}}
+    })
+    out.Reset()
+    if code := runDoctor(doctorFlags(nil), &out); code != 1 {
+        t.Errorf("failure: exit %d\n%s", code, out.String())
+    }
+}
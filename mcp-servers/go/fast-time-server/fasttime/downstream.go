@@ -0,0 +1,245 @@
+// -*- coding: utf-8 -*-
+// downstream.go - tools mounted from downstream MCP servers
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file turns the server into a small aggregator: the JSON file given
+// with -downstream names other MCP servers, and their tools are served next
+// to the built-in ones, behind this server's transports, authentication,
+// IP lists, filters and audit log:
+//
+//   {"servers": [
+//     {"name": "git", "command": ["mcp-server-git", "--repository", "/srv/repo"]},
+//     {"name": "weather", "url": "http://weather:9000/sse",
+//      "headers": {"Authorization": "Bearer ..."}, "timeout": "10s"},
+//     {"name": "hr", "url": "http://hr:8000/mcp", "transport": "http", "prefix": ""}
+//   ]}
+//
+// A server with a command is started over stdio; one with a URL is reached
+// over SSE, or streamable HTTP with "transport": "http". Each tool is
+// mounted as <prefix><tool>, the prefix defaulting to "<name>_", and calls
+// are forwarded as they are. Every downstream server must answer at startup;
+// a mounted tool whose server later goes away answers with a tool error.
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/client"
+    "github.com/mark3labs/mcp-go/client/transport"
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Timeouts for downstream servers
+const (
+    downstreamStartTimeout   = 30 * time.Second // connect, initialize and list tools
+    defaultDownstreamTimeout = 30 * time.Second // one forwarded call
+)
+
+// downstreamServer is a server declared in the -downstream file
+type downstreamServer struct {
+    Name      string            `json:"name"`
+    Command   []string          `json:"command"`
+    Env       map[string]string `json:"env"`
+    URL       string            `json:"url"`
+    Transport string            `json:"transport"`
+    Headers   map[string]string `json:"headers"`
+    Prefix    *string           `json:"prefix"`
+    Timeout   string            `json:"timeout"`
+
+    timeout time.Duration
+    client  *client.Client
+}
+
+// loadDownstream reads the servers declared in path
+func loadDownstream(path string) ([]*downstreamServer, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("read downstream file: %w", err)
+    }
+    var doc struct {
+        Servers []*downstreamServer `json:"servers"`
+    }
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("downstream file %s: %w", path, err)
+    }
+    names := make(map[string]bool, len(doc.Servers))
+    for i, d := range doc.Servers {
+        switch {
+        case d == nil || d.Name == "":
+            return nil, fmt.Errorf("downstream file %s: server %d has no name", path, i+1)
+        case names[d.Name]:
+            return nil, fmt.Errorf("downstream file %s: duplicate server %q", path, d.Name)
+        case (len(d.Command) == 0) == (d.URL == ""):
+            return nil, fmt.Errorf("downstream file %s: server %q needs either a command or a url", path, d.Name)
+        }
+        d.Transport = strings.ToLower(d.Transport)
+        switch {
+        case len(d.Command) > 0 && d.Transport != "" && d.Transport != "stdio":
+            return nil, fmt.Errorf("downstream file %s: server %q: a command is served over stdio, not %q", path, d.Name, d.Transport)
+        case len(d.Command) > 0:
+            d.Transport = "stdio"
+        case d.Transport == "":
+            d.Transport = "sse"
+        case d.Transport != "sse" && d.Transport != "http":
+            return nil, fmt.Errorf("downstream file %s: server %q: unknown transport %q (use sse or http)", path, d.Name, d.Transport)
+        }
+        d.timeout = defaultDownstreamTimeout
+        if d.Timeout != "" {
+            if d.timeout, err = time.ParseDuration(d.Timeout); err != nil || d.timeout <= 0 {
+                return nil, fmt.Errorf("downstream file %s: server %q: invalid timeout %q", path, d.Name, d.Timeout)
+            }
+        }
+        names[d.Name] = true
+    }
+    return doc.Servers, nil
+}
+
+// prefix returns what is put before the names of d's tools
+func (d *downstreamServer) prefix() string {
+    if d.Prefix != nil {
+        return *d.Prefix
+    }
+    return d.Name + "_"
+}
+
+// connect starts a client for d and initializes the session
+func (d *downstreamServer) connect(ctx context.Context) error {
+    var t transport.Interface
+    switch d.Transport {
+    case "stdio":
+        env := make([]string, 0, len(d.Env))
+        for k, v := range d.Env {
+            env = append(env, k+"="+v)
+        }
+        st := transport.NewStdio(d.Command[0], env, d.Command[1:]...)
+        // The process lives as long as the client, not as ctx
+        if err := st.Start(context.Background()); err != nil {
+            return err
+        }
+        go logDownstreamStderr(d.Name, st)
+        t = st
+    case "sse":
+        sse, err := transport.NewSSE(d.URL, transport.WithHeaders(d.Headers))
+        if err != nil {
+            return err
+        }
+        t = sse
+    case "http":
+        h, err := transport.NewStreamableHTTP(d.URL, transport.WithHTTPHeaders(d.Headers))
+        if err != nil {
+            return err
+        }
+        t = h
+    }
+    c := client.NewClient(t)
+    if err := c.Start(context.Background()); err != nil {
+        c.Close()
+        return err
+    }
+    initReq := mcp.InitializeRequest{}
+    initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+    initReq.Params.ClientInfo = mcp.Implementation{Name: appName, Version: appVersion}
+    if _, err := c.Initialize(ctx, initReq); err != nil {
+        c.Close()
+        return fmt.Errorf("initialize: %w", err)
+    }
+    d.client = c
+    return nil
+}
+
+// logDownstreamStderr copies what a stdio server writes on stderr to the
+// debug log; unread, it would fill the pipe and stall the server
+func logDownstreamStderr(name string, st *transport.Stdio) {
+    sc := bufio.NewScanner(st.Stderr())
+    for sc.Scan() {
+        logAt(logDebug, "downstream %s: %s", name, sc.Text())
+    }
+}
+
+// forward returns the handler calling tool name on d
+func (d *downstreamServer) forward(name string) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        ctx, cancel := context.WithTimeout(ctx, d.timeout)
+        defer cancel()
+        call := mcp.CallToolRequest{}
+        call.Params.Name = name
+        call.Params.Arguments = req.Params.Arguments
+        res, err := d.client.CallTool(ctx, call)
+        switch {
+        case errors.Is(ctx.Err(), context.DeadlineExceeded):
+            return mcp.NewToolResultError(fmt.Sprintf("downstream %s: %s timed out after %v", d.Name, name, d.timeout)), nil
+        case ctx.Err() != nil:
+            return nil, ctx.Err()
+        case err != nil:
+            return mcp.NewToolResultError(fmt.Sprintf("downstream %s: %v", d.Name, err)), nil
+        }
+        return res, nil
+    }
+}
+
+// Close ends the session with d
+func (d *downstreamServer) Close() error {
+    if d.client == nil {
+        return nil
+    }
+    return d.client.Close()
+}
+
+// listTools returns every tool of d, one tools/list page at a time
+func (d *downstreamServer) listTools(ctx context.Context) ([]mcp.Tool, error) {
+    var tools []mcp.Tool
+    req := mcp.ListToolsRequest{}
+    for {
+        res, err := d.client.ListToolsByPage(ctx, req)
+        if err != nil {
+            return nil, err
+        }
+        tools = append(tools, res.Tools...)
+        if res.NextCursor == "" {
+            return tools, nil
+        }
+        req.Params.Cursor = res.NextCursor
+    }
+}
+
+// mountDownstream connects to each server and adds its tools to s. The
+// servers are returned as they connect, for the caller to close, also on
+// error.
+func mountDownstream(s *server.MCPServer, servers []*downstreamServer) ([]*downstreamServer, error) {
+    var connected []*downstreamServer
+    for _, d := range servers {
+        ctx, cancel := context.WithTimeout(context.Background(), downstreamStartTimeout)
+        err := d.connect(ctx)
+        if err != nil {
+            cancel()
+            return connected, fmt.Errorf("downstream %s: %w", d.Name, err)
+        }
+        connected = append(connected, d)
+        tools, err := d.listTools(ctx)
+        cancel()
+        if err != nil {
+            return connected, fmt.Errorf("downstream %s: list tools: %w", d.Name, err)
+        }
+        for _, tool := range tools {
+            name := tool.Name
+            tool.Name = d.prefix() + name
+            if s.GetTool(tool.Name) != nil {
+                return connected, fmt.Errorf("downstream %s: tool %q clashes with a tool already served", d.Name, tool.Name)
+            }
+            s.AddTool(tool, d.forward(name))
+        }
+        logAt(logInfo, "downstream %s: mounted %d tool(s) over %s", d.Name, len(tools), d.Transport)
+    }
+    return connected, nil
+}
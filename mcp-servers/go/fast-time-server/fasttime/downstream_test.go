@@ -0,0 +1,176 @@
+// -*- coding: utf-8 -*-
+// downstream_test.go - Tests for tools mounted from downstream servers
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/base64"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// writeDownstream writes a -downstream file and returns its path
+func writeDownstream(t *testing.T, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "downstream.json")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+// downstreamTestServer returns an MCP server with a greet tool and a slow one
+func downstreamTestServer() *server.MCPServer {
+    s := server.NewMCPServer("downstream", "1.0", server.WithToolCapabilities(false))
+    s.AddTool(mcp.NewTool("greet", mcp.WithString("who", mcp.Required())), func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return mcp.NewToolResultText("hello " + req.GetString("who", "")), nil
+    })
+    s.AddTool(mcp.NewTool("slow"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        select {
+        case <-ctx.Done():
+        case <-time.After(5 * time.Second):
+        }
+        return mcp.NewToolResultText("late"), nil
+    })
+    return s
+}
+
+func TestLoadDownstream(t *testing.T) {
+    servers, err := loadDownstream(writeDownstream(t, `{"servers": [
+        {"name": "git", "command": ["mcp-server-git"]},
+        {"name": "weather", "url": "http://weather:9000/sse", "timeout": "5s"},
+        {"name": "hr", "url": "http://hr:8000/mcp", "transport": "HTTP", "prefix": ""}
+    ]}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(servers) != 3 {
+        t.Fatalf("servers = %d", len(servers))
+    }
+    got := []string{servers[0].Transport, servers[1].Transport, servers[2].Transport}
+    if strings.Join(got, ",") != "stdio,sse,http" {
+        t.Errorf("transports = %v", got)
+    }
+    if servers[0].prefix() != "git_" || servers[2].prefix() != "" || servers[1].timeout != 5*time.Second {
+        t.Errorf("servers = %+v", servers)
+    }
+
+    for name, content := range map[string]string{
+        "no name":   `{"servers": [{"url": "http://x"}]}`,
+        "duplicate": `{"servers": [{"name": "a", "url": "http://x"}, {"name": "a", "url": "http://y"}]}`,
+        "neither":   `{"servers": [{"name": "a"}]}`,
+        "both":      `{"servers": [{"name": "a", "url": "http://x", "command": ["x"]}]}`,
+        "transport": `{"servers": [{"name": "a", "url": "http://x", "transport": "ws"}]}`,
+        "stdio url": `{"servers": [{"name": "a", "command": ["x"], "transport": "sse"}]}`,
+        "timeout":   `{"servers": [{"name": "a", "url": "http://x", "timeout": "-1s"}]}`,
+    } {
+        if _, err := loadDownstream(writeDownstream(t, content)); err == nil {
+            t.Errorf("%s: loadDownstream should fail", name)
+        }
+    }
+}
+
+func TestMountDownstream(t *testing.T) {
+    sse := server.NewTestServer(downstreamTestServer())
+    defer sse.Close()
+    streamable := server.NewTestStreamableHTTPServer(downstreamTestServer())
+    defer streamable.Close()
+
+    servers, err := loadDownstream(writeDownstream(t, `{"servers": [
+        {"name": "a", "url": "`+sse.URL+`/sse", "timeout": "100ms"},
+        {"name": "b", "url": "`+streamable.URL+`", "transport": "http", "prefix": "remote."}
+    ]}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s := server.NewMCPServer("test", "1.0")
+    connected, err := mountDownstream(s, servers)
+    for _, d := range connected {
+        defer d.Close()
+    }
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, name := range []string{"a_greet", "remote.greet"} {
+        tool := s.GetTool(name)
+        if tool == nil {
+            t.Fatalf("%s not mounted", name)
+        }
+        req := mcp.CallToolRequest{}
+        req.Params.Name = name
+        req.Params.Arguments = map[string]any{"who": "world"}
+        res, err := tool.Handler(context.Background(), req)
+        if err != nil || res.IsError || res.Content[0].(mcp.TextContent).Text != "hello world" {
+            t.Errorf("%s = %+v, %v", name, res, err)
+        }
+    }
+
+    req := mcp.CallToolRequest{}
+    res, err := s.GetTool("a_slow").Handler(context.Background(), req)
+    if err != nil || !res.IsError || !strings.Contains(res.Content[0].(mcp.TextContent).Text, "timed out") {
+        t.Errorf("slow = %+v, %v", res, err)
+    }
+
+    // A second mount of the same server clashes
+    again, _ := loadDownstream(writeDownstream(t, `{"servers": [{"name": "a", "url": "`+sse.URL+`/sse"}]}`))
+    connected, err = mountDownstream(s, again)
+    for _, d := range connected {
+        d.Close()
+    }
+    if err == nil || !strings.Contains(err.Error(), "clashes") {
+        t.Errorf("clash: %v", err)
+    }
+}
+
+func TestMountDownstreamPaginated(t *testing.T) {
+    // mcp-go does not page tools/list itself; hand out one tool per page
+    hooks := &server.Hooks{}
+    hooks.AddAfterListTools(func(_ context.Context, _ any, req *mcp.ListToolsRequest, res *mcp.ListToolsResult) {
+        sort.Slice(res.Tools, func(i, j int) bool { return res.Tools[i].Name < res.Tools[j].Name })
+        raw, _ := base64.StdEncoding.DecodeString(string(req.Params.Cursor))
+        start, _ := strconv.Atoi(string(raw))
+        if start+1 < len(res.Tools) {
+            res.NextCursor = mcp.Cursor(base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(start + 1))))
+        }
+        res.Tools = res.Tools[start : start+1]
+    })
+    paged := server.NewMCPServer("paged", "1.0", server.WithToolCapabilities(false), server.WithHooks(hooks))
+    for _, name := range []string{"one", "two", "three"} {
+        paged.AddTool(mcp.NewTool(name), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+            return mcp.NewToolResultText("ok"), nil
+        })
+    }
+    ts := server.NewTestStreamableHTTPServer(paged)
+    defer ts.Close()
+
+    servers, err := loadDownstream(writeDownstream(t, `{"servers": [{"name": "p", "url": "`+ts.URL+`", "transport": "http"}]}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s := server.NewMCPServer("test", "1.0")
+    connected, err := mountDownstream(s, servers)
+    for _, d := range connected {
+        defer d.Close()
+    }
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, name := range []string{"p_one", "p_two", "p_three"} {
+        if s.GetTool(name) == nil {
+            t.Errorf("%s not mounted", name)
+        }
+    }
+}
@@ -0,0 +1,134 @@
+// -*- coding: utf-8 -*-
+// elicitation.go - ask the user for missing tool arguments
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file fills in missing required tool arguments through MCP elicitation.
+// When a call leaves out a required argument (convert_time without
+// source_timezone, for example) and the client declared the elicitation
+// capability in initialize, the server sends elicitation/create with a form
+// for the missing values and continues the call with the user's answers.
+//
+// The required arguments and their descriptions come from the tool's own
+// input schema, so every tool is covered without extra configuration. If the
+// client cannot elicit, or the user declines, the call runs unchanged and
+// fails with the usual "parameter is required" error.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// elicitationTimeout bounds how long a call waits for the user to answer
+const elicitationTimeout = 5 * time.Minute
+
+// errElicitationDeclined is reported when the user does not supply values
+var errElicitationDeclined = errors.New("user did not provide the missing arguments")
+
+// missingArguments returns the required primitive properties of schema that
+// args lacks or leaves empty, as an elicitation schema
+func missingArguments(schema mcp.ToolInputSchema, args map[string]any) (map[string]any, []string) {
+    props := make(map[string]any)
+    var missing []string
+    for _, name := range schema.Required {
+        if v, ok := args[name]; ok && v != nil && v != "" {
+            continue
+        }
+        p, _ := schema.Properties[name].(map[string]any)
+        typ, _ := p["type"].(string)
+        switch typ {
+        case "string", "number", "integer", "boolean":
+        default:
+            // Elicitation forms only carry flat primitive fields
+            continue
+        }
+        field := map[string]any{"type": typ, "title": name}
+        for _, k := range []string{"description", "enum"} {
+            if v, ok := p[k]; ok {
+                field[k] = v
+            }
+        }
+        props[name] = field
+        missing = append(missing, name)
+    }
+    return props, missing
+}
+
+// elicitArguments asks the user for the missing required arguments of tool
+// and returns them
+func elicitArguments(ctx context.Context, srv *server.MCPServer, tool *server.ServerTool, args map[string]any) (map[string]any, error) {
+    props, missing := missingArguments(tool.Tool.InputSchema, args)
+    if len(missing) == 0 {
+        return nil, nil
+    }
+
+    req := mcp.ElicitationRequest{}
+    req.Params.Message = fmt.Sprintf("%s needs %s to continue.", tool.Tool.Name, strings.Join(missing, ", "))
+    req.Params.RequestedSchema = map[string]any{
+        "type":       "object",
+        "properties": props,
+        "required":   missing,
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, elicitationTimeout)
+    defer cancel()
+    res, err := srv.RequestElicitation(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+    if res.Action != mcp.ElicitationResponseActionAccept {
+        return nil, fmt.Errorf("%w (%s)", errElicitationDeclined, res.Action)
+    }
+    content, _ := res.Content.(map[string]any)
+    values := make(map[string]any, len(missing))
+    for _, name := range missing {
+        if v, ok := content[name]; ok && v != nil && v != "" {
+            values[name] = v
+        }
+    }
+    return values, nil
+}
+
+// elicitationMiddleware completes calls that lack required arguments by
+// asking the user, when the session's client supports elicitation
+func elicitationMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        id := sessionIDFrom(ctx)
+        srv := server.ServerFromContext(ctx)
+        if id == "" || srv == nil || !sessions.elicitation(id) {
+            return next(ctx, req)
+        }
+        tool := srv.GetTool(req.Params.Name)
+        if tool == nil {
+            return next(ctx, req)
+        }
+
+        args := req.GetArguments()
+        values, err := elicitArguments(ctx, srv, tool, args)
+        if err != nil {
+            logAt(logDebug, "elicitation for %s: %v", req.Params.Name, err)
+            return next(ctx, req)
+        }
+        if len(values) > 0 {
+            merged := make(map[string]any, len(args)+len(values))
+            for k, v := range args {
+                merged[k] = v
+            }
+            for k, v := range values {
+                merged[k] = v
+            }
+            req.Params.Arguments = merged
+            logAt(logInfo, "elicitation for %s: user supplied %d argument(s)", req.Params.Name, len(values))
+        }
+        return next(ctx, req)
+    }
+}
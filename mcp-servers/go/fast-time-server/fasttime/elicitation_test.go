@@ -0,0 +1,128 @@
+// -*- coding: utf-8 -*-
+// elicitation_test.go - Tests for eliciting missing arguments
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// elicitingSession is a session whose client answers elicitation requests
+type elicitingSession struct {
+    fakeSession
+    action  mcp.ElicitationResponseAction
+    content map[string]any
+    got     *mcp.ElicitationRequest
+}
+
+func (s *elicitingSession) RequestElicitation(_ context.Context, req mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+    s.got = &req
+    res := &mcp.ElicitationResult{}
+    res.Action = s.action
+    res.Content = s.content
+    return res, nil
+}
+
+// callConvertTime calls convert_time through a server with the elicitation
+// middleware in sess
+func callConvertTime(t *testing.T, sess server.ClientSession, args string) mcp.CallToolResult {
+    t.Helper()
+    srv := server.NewMCPServer("test", "1.0",
+        server.WithToolCapabilities(false),
+        server.WithElicitation(),
+        server.WithToolHandlerMiddleware(elicitationMiddleware),
+    )
+    srv.AddTool(mcp.NewTool("convert_time",
+        mcp.WithString("time", mcp.Required(), mcp.Description("Time to convert")),
+        mcp.WithString("source_timezone", mcp.Required(), mcp.Description("Source IANA timezone name")),
+        mcp.WithString("target_timezone", mcp.Required(), mcp.Description("Target IANA timezone name")),
+    ), handleConvertTime)
+    ctx := srv.WithContext(context.Background(), sess)
+    msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"convert_time","arguments":` + args + `}}`
+    resp, ok := srv.HandleMessage(ctx, json.RawMessage(msg)).(mcp.JSONRPCResponse)
+    if !ok {
+        t.Fatalf("unexpected response %#v", resp)
+    }
+    return resp.Result.(mcp.CallToolResult)
+}
+
+func TestElicitMissingArguments(t *testing.T) {
+    sess := &elicitingSession{
+        fakeSession: fakeSession{id: "eliciting-session"},
+        action:      mcp.ElicitationResponseActionAccept,
+        content:     map[string]any{"source_timezone": "Europe/London"},
+    }
+    sessions.add(sess.id, time.Now())
+    defer sessions.remove(sess.id)
+    sessions.setElicitation(sess.id, true)
+
+    res := callConvertTime(t, sess, `{"time":"2025-01-15T12:00:00","target_timezone":"Asia/Tokyo"}`)
+    if res.IsError {
+        t.Fatalf("call should succeed after elicitation: %s", extractText(t, &res))
+    }
+    if !strings.Contains(extractText(t, &res), "21:00") {
+        t.Errorf("unexpected conversion: %s", extractText(t, &res))
+    }
+    if sess.got == nil || !strings.Contains(sess.got.Params.Message, "source_timezone") {
+        t.Fatalf("unexpected elicitation request %+v", sess.got)
+    }
+    schema := sess.got.Params.RequestedSchema.(map[string]any)
+    props := schema["properties"].(map[string]any)
+    if len(props) != 1 || props["source_timezone"].(map[string]any)["description"] != "Source IANA timezone name" {
+        t.Errorf("schema should ask only for source_timezone: %v", schema)
+    }
+
+    // Declining keeps the original error
+    sess.action, sess.content = mcp.ElicitationResponseActionDecline, nil
+    res = callConvertTime(t, sess, `{"time":"2025-01-15T12:00:00","target_timezone":"Asia/Tokyo"}`)
+    if !res.IsError {
+        t.Error("declined elicitation should fail the call")
+    }
+
+    // Nothing missing: no elicitation
+    sess.got = nil
+    callConvertTime(t, sess, `{"time":"2025-01-15T12:00:00","source_timezone":"UTC","target_timezone":"Asia/Tokyo"}`)
+    if sess.got != nil {
+        t.Error("complete call should not elicit")
+    }
+}
+
+func TestElicitationNotDeclared(t *testing.T) {
+    sess := &elicitingSession{fakeSession: fakeSession{id: "no-elicitation"}}
+    sessions.add(sess.id, time.Now())
+    defer sessions.remove(sess.id)
+
+    res := callConvertTime(t, sess, `{"time":"2025-01-15T12:00:00"}`)
+    if !res.IsError || sess.got != nil {
+        t.Error("without the capability the call should fail as before")
+    }
+}
+
+func TestMissingArguments(t *testing.T) {
+    tool := mcp.NewTool("x",
+        mcp.WithString("a", mcp.Required(), mcp.Enum("one", "two")),
+        mcp.WithNumber("b", mcp.Required()),
+        mcp.WithArray("c", mcp.Required()),
+        mcp.WithString("d"),
+    )
+    props, missing := missingArguments(tool.InputSchema, map[string]any{"b": 3.0})
+    if len(missing) != 1 || missing[0] != "a" {
+        t.Fatalf("missing = %v", missing)
+    }
+    if enum := props["a"].(map[string]any)["enum"]; enum == nil {
+        t.Error("enum should be carried into the form")
+    }
+    if _, missing := missingArguments(tool.InputSchema, map[string]any{"a": "", "b": 1.0}); len(missing) != 1 {
+        t.Errorf("empty string counts as missing, got %v", missing)
+    }
+}
@@ -0,0 +1,102 @@
+// -*- coding: utf-8 -*-
+// envelope.go - opt-in REST response envelope with timing metadata
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// A REST client that sends ?envelope=true (or the X-Envelope: true header)
+// gets every response wrapped as {"result": ..., "meta": {...}}, where meta
+// holds the request ID, the time the server spent on the request and the
+// timezone database release that answered it. This helps clients tell
+// network latency from server time and spot a server with stale tzdata.
+// Errors are wrapped too, with the usual error body as the result.
+//
+// The request ID is the client's X-Request-ID when it sent a usable one, or
+// a new random ID; it is echoed in the X-Request-ID response header.
+// negotiateMiddleware records the envelope request on the negotiatedWriter
+// and writeJSON wraps the response, so any negotiated format can carry it.
+
+package fasttime
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "net/http"
+    "regexp"
+    "strconv"
+    "time"
+)
+
+// Envelope request and request ID headers
+const (
+    envelopeHeader  = "X-Envelope"
+    requestIDHeader = "X-Request-ID"
+)
+
+// requestIDPattern matches client request IDs that are echoed as they are
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Envelope is a REST response wrapped with metadata
+type Envelope struct {
+    Result interface{}  `json:"result"`
+    Meta   EnvelopeMeta `json:"meta"`
+}
+
+// EnvelopeMeta describes how a response was served
+type EnvelopeMeta struct {
+    RequestID    string  `json:"request_id"`
+    ProcessingMS float64 `json:"processing_ms"`
+    Tzdata       string  `json:"tzdata"`
+}
+
+// envelopeState is the envelope requested for one response
+type envelopeState struct {
+    requestID string
+    start     time.Time
+}
+
+// wantsEnvelope reports whether r asks for the envelope
+func wantsEnvelope(r *http.Request) bool {
+    v := r.URL.Query().Get("envelope")
+    if v == "" {
+        v = r.Header.Get(envelopeHeader)
+    }
+    on, _ := strconv.ParseBool(v)
+    return on
+}
+
+// requestID returns the client's request ID for r, or a new one
+func requestID(r *http.Request) string {
+    if id := r.Header.Get(requestIDHeader); requestIDPattern.MatchString(id) {
+        return id
+    }
+    b := make([]byte, 8)
+    _, _ = rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
+// newEnvelopeState returns the envelope of r, or nil if it did not ask
+func newEnvelopeState(w http.ResponseWriter, r *http.Request) *envelopeState {
+    if !wantsEnvelope(r) {
+        return nil
+    }
+    env := &envelopeState{requestID: requestID(r), start: time.Now()}
+    w.Header().Set(requestIDHeader, env.requestID)
+    return env
+}
+
+// enveloped returns data wrapped in the envelope requested for w, or data
+func enveloped(w http.ResponseWriter, data interface{}) interface{} {
+    nw := negotiatedFrom(w)
+    if nw == nil || nw.envelope == nil {
+        return data
+    }
+    return Envelope{
+        Result: data,
+        Meta: EnvelopeMeta{
+            RequestID:    nw.envelope.requestID,
+            ProcessingMS: float64(time.Since(nw.envelope.start).Microseconds()) / 1000,
+            Tzdata:       tzdataVersion(),
+        },
+    }
+}
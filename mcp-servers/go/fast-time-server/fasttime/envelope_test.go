@@ -0,0 +1,72 @@
+// -*- coding: utf-8 -*-
+// envelope_test.go - tests for the REST response envelope
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+// envelopeGet serves a REST GET with the given headers
+func envelopeGet(path string, headers map[string]string) *httptest.ResponseRecorder {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    req := httptest.NewRequest(http.MethodGet, path, nil)
+    for k, v := range headers {
+        req.Header.Set(k, v)
+    }
+    w := httptest.NewRecorder()
+    mux.ServeHTTP(w, req)
+    return w
+}
+
+func TestResponseEnvelope(t *testing.T) {
+    w := envelopeGet("/api/v1/time/Asia/Tokyo?envelope=true", nil)
+    var env struct {
+        Result TimeResponse `json:"result"`
+        Meta   EnvelopeMeta `json:"meta"`
+    }
+    if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+        t.Fatal(err)
+    }
+    if w.Code != http.StatusOK || env.Result.Timezone != "Asia/Tokyo" || env.Result.Time == "" {
+        t.Errorf("result = %d %+v", w.Code, env.Result)
+    }
+    if len(env.Meta.RequestID) != 16 || env.Meta.RequestID != w.Header().Get(requestIDHeader) ||
+        env.Meta.Tzdata != tzdataVersion() || env.Meta.ProcessingMS < 0 {
+        t.Errorf("meta = %+v, header %q", env.Meta, w.Header().Get(requestIDHeader))
+    }
+
+    // By header, keeping the client's request ID, and for errors
+    w = envelopeGet("/api/v1/time/Mars/Base", map[string]string{envelopeHeader: "true", requestIDHeader: "req-42"})
+    body := w.Body.String()
+    if w.Code != http.StatusBadRequest || !strings.HasPrefix(body, `{"result":{"error":"Bad Request"`) ||
+        !strings.Contains(body, `"request_id":"req-42"`) || w.Header().Get(requestIDHeader) != "req-42" {
+        t.Errorf("error envelope: %d %s", w.Code, body)
+    }
+
+    // An unusable client ID is replaced
+    w = envelopeGet("/api/v1/time?envelope=1", map[string]string{requestIDHeader: "bad id\x01"})
+    if id := w.Header().Get(requestIDHeader); len(id) != 16 {
+        t.Errorf("request ID = %q", id)
+    }
+
+    // Other formats carry the envelope too
+    w = envelopeGet("/api/v1/time/UTC?envelope=true&format=yaml", nil)
+    if body := w.Body.String(); !strings.HasPrefix(body, "result:\n") || !strings.Contains(body, "  tzdata: ") {
+        t.Errorf("yaml envelope: %s", body)
+    }
+
+    // Off by default
+    w = envelopeGet("/api/v1/time/UTC?envelope=false", nil)
+    if strings.Contains(w.Body.String(), `"meta"`) || w.Header().Get(requestIDHeader) != "" {
+        t.Errorf("without envelope: %s", w.Body)
+    }
+}
@@ -0,0 +1,258 @@
+// -*- coding: utf-8 -*-
+// errors.go - machine-readable error codes
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file defines the error model shared by tool results, REST responses
+// and authentication failures: a stable code clients can branch on (for
+// example INVALID_TIMEZONE, UNPARSEABLE_TIME or MISSING_FIELD:time), the
+// human message, the offending field and a hint on how to fix the request.
+//
+// -error-format selects how the model is rendered:
+//
+//   - classic (default): REST errors keep their {error, message, code} body
+//     and gain error_code, field and hint; tool errors keep their text and
+//     carry the model as structured content
+//   - structured: REST errors are {"error": {code, message, field, hint},
+//     "status": N} and the text of a tool error is that same JSON
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "regexp"
+    "strings"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Error codes
+const (
+    codeInvalidTimezone  = "INVALID_TIMEZONE"
+    codeUnparseableTime  = "UNPARSEABLE_TIME"
+    codeMissingField     = "MISSING_FIELD"
+    codeInvalidArgument  = "INVALID_ARGUMENT"
+    codeUnauthorized     = "UNAUTHORIZED"
+    codeForbidden        = "FORBIDDEN"
+    codeNotFound         = "NOT_FOUND"
+    codeMethodNotAllowed = "METHOD_NOT_ALLOWED"
+    codeConflict         = "CONFLICT"
+    codeTooLarge         = "PAYLOAD_TOO_LARGE"
+    codeTimeout          = "TIMEOUT"
+    codeUnavailable      = "UNAVAILABLE"
+    codeBusy             = "BUSY"
+    codeInternal         = "INTERNAL"
+)
+
+// Error formats accepted by -error-format
+const (
+    errorFormatClassic    = "classic"
+    errorFormatStructured = "structured"
+)
+
+// errorFormat is the format chosen with -error-format
+var errorFormat = errorFormatClassic
+
+// parseErrorFormat validates an -error-format value; empty means classic
+func parseErrorFormat(s string) (string, error) {
+    switch s {
+    case "", errorFormatClassic:
+        return errorFormatClassic, nil
+    case errorFormatStructured:
+        return errorFormatStructured, nil
+    }
+    return "", fmt.Errorf("unknown -error-format %q (want classic or structured)", s)
+}
+
+// apiError is an error with a machine-readable code
+type apiError struct {
+    Code    string `json:"code"`
+    Message string `json:"message"`
+    Field   string `json:"field,omitempty"`
+    Hint    string `json:"hint,omitempty"`
+
+    Suggestions []string `json:"suggestions,omitempty"` // close matches for a bad value
+}
+
+func (e *apiError) Error() string { return e.Message }
+
+// errInvalidTimezone reports a zone name that does not load, with the
+// closest known zones
+func errInvalidTimezone(field, name string, err error) *apiError {
+    out := &apiError{
+        Code:    codeInvalidTimezone,
+        Message: fmt.Sprintf("invalid timezone %q: %v", name, err),
+        Field:   field,
+        Hint:    "use an IANA zone name such as Europe/London; GET /api/v1/timezones lists them",
+    }
+    if out.Suggestions = suggestTimezones(name); len(out.Suggestions) > 0 {
+        out.Message += " (did you mean " + strings.Join(out.Suggestions, ", ") + "?)"
+    }
+    return out
+}
+
+// errUnparseableTime reports a time value in none of the accepted layouts
+func errUnparseableTime(field, value string) *apiError {
+    return &apiError{
+        Code:    codeUnparseableTime,
+        Message: fmt.Sprintf("invalid time format: %s", value),
+        Field:   field,
+        Hint:    "use RFC3339, e.g. 2025-01-01T12:00:00Z",
+    }
+}
+
+// errMissingField reports a required field that was left out
+func errMissingField(field string) *apiError {
+    return &apiError{
+        Code:    codeMissingField + ":" + field,
+        Message: field + " parameter is required",
+        Field:   field,
+        Hint:    "set " + field,
+    }
+}
+
+// fieldError attributes err to field, keeping its message and code
+func fieldError(field string, err error) *apiError {
+    out := *classifyError(http.StatusBadRequest, err)
+    out.Message = err.Error()
+    out.Field = field
+    return &out
+}
+
+// statusCodes maps HTTP statuses to the code of errors that carry none
+var statusCodes = map[int]string{
+    http.StatusBadRequest:            codeInvalidArgument,
+    http.StatusUnauthorized:          codeUnauthorized,
+    http.StatusForbidden:             codeForbidden,
+    http.StatusNotFound:              codeNotFound,
+    http.StatusMethodNotAllowed:      codeMethodNotAllowed,
+    http.StatusRequestTimeout:        codeTimeout,
+    http.StatusConflict:              codeConflict,
+    http.StatusRequestEntityTooLarge: codeTooLarge,
+    http.StatusTooManyRequests:       codeBusy,
+    http.StatusServiceUnavailable:    codeUnavailable,
+}
+
+// Messages the handlers already use for the common failures
+var (
+    missingFieldRE = regexp.MustCompile(`^(?:([a-z_]+) parameter is required|required argument "([a-z_]+)" not found)`)
+    badZoneRE      = regexp.MustCompile(`(?i)^invalid (?:([a-z]+) )?timezone|unknown time zone`)
+    badTimeRE      = regexp.MustCompile(`(?i)^invalid (?:([a-z]+) )?time(?: format)?:|cannot parse`)
+)
+
+// classifyError returns err as an apiError, deriving the code from the
+// message when err carries none; status picks the fallback code
+func classifyError(status int, err error) *apiError {
+    var ae *apiError
+    if errors.As(err, &ae) {
+        return ae
+    }
+    msg := err.Error()
+    if m := missingFieldRE.FindStringSubmatch(msg); m != nil {
+        field := m[1] + m[2]
+        out := errMissingField(field)
+        out.Message = msg
+        return out
+    }
+    if m := badZoneRE.FindStringSubmatch(msg); m != nil {
+        out := errInvalidTimezone(prefixField(m[1], "timezone"), "", nil)
+        out.Message = msg
+        return out
+    }
+    if m := badTimeRE.FindStringSubmatch(msg); m != nil {
+        out := errUnparseableTime(prefixField(m[1], "time"), "")
+        out.Message = msg
+        return out
+    }
+    code, ok := statusCodes[status]
+    if !ok {
+        code = codeInternal
+    }
+    return &apiError{Code: code, Message: msg}
+}
+
+// prefixField names the field of "invalid source timezone" style messages
+func prefixField(qualifier, field string) string {
+    if qualifier == "" {
+        return field
+    }
+    return strings.ToLower(qualifier) + "_" + field
+}
+
+/* ------------------------------------------------------------------ */
+/*                              REST                                  */
+/* ------------------------------------------------------------------ */
+
+// StructuredErrorResponse is the REST error body with -error-format=structured
+type StructuredErrorResponse struct {
+    Error  *apiError `json:"error"`
+    Status int       `json:"status"`
+}
+
+func (e StructuredErrorResponse) plainText() string { return e.Error.Message }
+
+// writeAPIError writes err in the configured error format; /api/v2 errors
+// are always structured
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+    ae := classifyError(status, err)
+    if errorFormat == errorFormatStructured || responseVersion(w) >= apiV2 {
+        writeJSON(w, status, StructuredErrorResponse{Error: ae, Status: status})
+        return
+    }
+    writeJSON(w, status, ErrorResponse{
+        Error:     http.StatusText(status),
+        Message:   ae.Message,
+        Code:      status,
+        ErrorCode: ae.Code,
+        Field:     ae.Field,
+        Hint:      ae.Hint,
+
+        Suggestions: ae.Suggestions,
+    })
+}
+
+/* ------------------------------------------------------------------ */
+/*                              tools                                 */
+/* ------------------------------------------------------------------ */
+
+// toolError returns the error result for err in the configured format
+func toolError(err error) *mcp.CallToolResult {
+    res := mcp.NewToolResultError(err.Error())
+    attachToolError(res, classifyError(http.StatusBadRequest, err))
+    return res
+}
+
+// attachToolError adds the error model to an error result
+func attachToolError(res *mcp.CallToolResult, ae *apiError) {
+    body := map[string]any{"error": ae}
+    res.StructuredContent = body
+    if errorFormat == errorFormatStructured {
+        data, _ := json.Marshal(body)
+        res.Content = []mcp.Content{mcp.NewTextContent(string(data))}
+    }
+}
+
+// errorCodeMiddleware gives every tool error result an error code, so the
+// handlers can keep returning plain mcp.NewToolResultError messages
+func errorCodeMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        res, err := next(ctx, req)
+        if err != nil || res == nil || !res.IsError || res.StructuredContent != nil {
+            return res, err
+        }
+        var msg string
+        if len(res.Content) > 0 {
+            if tc, ok := mcp.AsTextContent(res.Content[0]); ok {
+                msg = tc.Text
+            }
+        }
+        attachToolError(res, classifyError(http.StatusBadRequest, errors.New(msg)))
+        return res, nil
+    }
+}
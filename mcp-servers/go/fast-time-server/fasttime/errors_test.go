@@ -0,0 +1,112 @@
+// -*- coding: utf-8 -*-
+// errors_test.go - tests for machine-readable error codes
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClassifyError(t *testing.T) {
+    for _, tc := range []struct {
+        status      int
+        msg         string
+        code, field string
+    }{
+        {400, "source_timezone parameter is required", "MISSING_FIELD:source_timezone", "source_timezone"},
+        {400, `required argument "period" not found`, "MISSING_FIELD:period", "period"},
+        {400, `invalid target timezone: invalid timezone "X": unknown time zone X`, "INVALID_TIMEZONE", "target_timezone"},
+        {400, "invalid from time: cannot parse", "UNPARSEABLE_TIME", "from_time"},
+        {400, "Invalid time: use RFC3339", "UNPARSEABLE_TIME", "time"},
+        {400, "Invalid request body", "INVALID_ARGUMENT", ""},
+        {401, "Invalid token", "UNAUTHORIZED", ""},
+        {404, "Unknown alias: HQ", "NOT_FOUND", ""},
+        {500, "disk full", "INTERNAL", ""},
+    } {
+        got := classifyError(tc.status, errors.New(tc.msg))
+        if got.Code != tc.code || got.Field != tc.field || got.Message != tc.msg {
+            t.Errorf("%d %q = %+v, want %s field %q", tc.status, tc.msg, got, tc.code, tc.field)
+        }
+    }
+
+    // Typed errors keep their code through wrapping
+    _, err := loadLocation("Mars/Base")
+    if got := classifyError(500, err); got.Code != codeInvalidTimezone || got.Hint == "" {
+        t.Errorf("loadLocation error = %+v", got)
+    }
+}
+
+func TestWriteAPIErrorFormats(t *testing.T) {
+    defer func() { errorFormat = errorFormatClassic }()
+
+    rec := httptest.NewRecorder()
+    handleRESTGetTime(rec, httptest.NewRequest(http.MethodGet, "/api/v1/time/Mars/Base", nil))
+    var classic ErrorResponse
+    if err := json.Unmarshal(rec.Body.Bytes(), &classic); err != nil {
+        t.Fatal(err)
+    }
+    if rec.Code != 400 || classic.Code != 400 || classic.ErrorCode != codeInvalidTimezone || classic.Field != "timezone" || classic.Hint == "" {
+        t.Errorf("classic = %d %+v", rec.Code, classic)
+    }
+
+    errorFormat = errorFormatStructured
+    rec = httptest.NewRecorder()
+    writeJSONError(rec, http.StatusUnauthorized, "Invalid token")
+    var structured struct {
+        Error  apiError `json:"error"`
+        Status int      `json:"status"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &structured); err != nil {
+        t.Fatal(err)
+    }
+    if structured.Status != 401 || structured.Error.Code != codeUnauthorized || structured.Error.Message != "Invalid token" {
+        t.Errorf("structured = %s", rec.Body)
+    }
+
+    if _, err := parseErrorFormat("xml"); err == nil {
+        t.Error("unknown -error-format accepted")
+    }
+}
+
+func TestToolErrorCodes(t *testing.T) {
+    defer func() { errorFormat = errorFormatClassic }()
+
+    handler := errorCodeMiddleware(handleConvertTime)
+    args := map[string]any{"time": "2025-01-01T00:00:00Z", "source_timezone": "UTC", "target_timezone": "Mars/Base"}
+    res, _ := handler(context.Background(), testRequest("convert_time", args))
+    body, _ := res.StructuredContent.(map[string]any)
+    ae, _ := body["error"].(*apiError)
+    if !res.IsError || ae == nil || ae.Code != codeInvalidTimezone || ae.Field != "target_timezone" {
+        t.Fatalf("convert_time = %+v", res)
+    }
+    if text := res.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, "invalid target timezone") {
+        t.Errorf("classic text = %q", text)
+    }
+
+    // Plain error results get a code from their message
+    errorFormat = errorFormatStructured
+    plain := errorCodeMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return mcp.NewToolResultError("period parameter is required"), nil
+    })
+    res, _ = plain(context.Background(), testRequest("start_end_of_period", nil))
+    var parsed struct {
+        Error apiError `json:"error"`
+    }
+    if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &parsed); err != nil {
+        t.Fatalf("structured text: %v", err)
+    }
+    if parsed.Error.Code != "MISSING_FIELD:period" || parsed.Error.Field != "period" {
+        t.Errorf("structured = %+v", parsed)
+    }
+}
@@ -0,0 +1,752 @@
+// -*- coding: utf-8 -*-
+// fasttime.go - the public Go API
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file is the entry point for programs that embed the time server; the
+// fast-time-server command is one of them:
+//
+//   cfg := fasttime.DefaultConfig()
+//   cfg.Transport = "http"
+//   cfg.Port = 9090
+//   srv, err := fasttime.New(cfg)
+//   if err != nil {
+//       log.Fatal(err)
+//   }
+//   srv.AddTool(myTool, myHandler)
+//   err = srv.Run(ctx)
+//
+// Config has a field for every flag of the server; the README's CLI Flags
+// table documents them. As with the command, AUTH_TOKEN, ADMIN_TOKEN,
+// ENABLE_TOOLS and DISABLE_TOOLS override the matching fields. Much of the
+// server's state (tokens, aliases, the clock, statistics, the log) lives in
+// package variables, so a program runs one Server at a time.
+
+// Package fasttime is an MCP server for time and timezone tools, with SSE,
+// streamable HTTP, REST, stdio and pipe transports.
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net/url"
+    "os"
+    "reflect"
+    "runtime"
+    "slices"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Name is the server name reported to clients and in /version
+const Name = appName
+
+// Version returns the server version, set at build time with
+// -ldflags "-X fast-time-server/fasttime.appVersion=..."
+func Version() string {
+    return appVersion
+}
+
+// Timezones returns the zones listed by GET /api/v1/timezones, e.g. for
+// shell completion
+func Timezones() []string {
+    return slices.Clone(knownTimezones)
+}
+
+// Config configures a Server. Each field is tagged with the flag of the
+// fast-time-server command that sets it; GET /admin/config and Doctor
+// report fields under those names.
+type Config struct {
+    Transport string `flag:"transport"` // stdio, sse, http, dual, rest or pipe
+    Addr      string `flag:"addr"`      // host:port; overrides Listen and Port
+    Listen    string `flag:"listen"`
+    Port      int    `flag:"port"`
+    Pipe      string `flag:"pipe"`
+    Listeners string `flag:"listeners"` // KIND=ADDR,...; replaces Transport
+    PublicURL string `flag:"public-url"`
+
+    AuthToken      string `flag:"auth-token"`
+    AuthTokenFile  string `flag:"auth-token-file"`
+    AuthTokensFile string `flag:"auth-tokens-file"`
+    AdminToken     string `flag:"admin-token"`
+    AdminTokenFile string `flag:"admin-token-file"`
+    Debug          bool   `flag:"debug"`
+
+    LogLevel    string        `flag:"log-level"`
+    LogOutput   string        `flag:"log-output"`
+    LogFile     string        `flag:"log-file"`
+    LogMaxSize  int64         `flag:"log-max-size"`
+    LogMaxAge   time.Duration `flag:"log-max-age"`
+    LogMaxFiles int           `flag:"log-max-files"`
+
+    SSEKeepAlive         time.Duration `flag:"sse-keepalive"`
+    SSEIdleTimeout       time.Duration `flag:"sse-idle-timeout"`
+    MaxSSEClients        int           `flag:"max-sse-clients"`
+    ResourcePushInterval time.Duration `flag:"resource-push-interval"`
+    Compress             bool          `flag:"compress"`
+    GraphQL              bool          `flag:"graphql"`
+
+    TrustedProxies string        `flag:"trusted-proxies"`
+    AllowIPs       string        `flag:"allow-ips"`
+    DenyIPs        string        `flag:"deny-ips"`
+    IPACLFile      string        `flag:"ip-acl-file"`
+    MaxBodySize    int64         `flag:"max-body-size"`
+    RequestTimeout time.Duration `flag:"request-timeout"`
+    ToolTimeout    time.Duration `flag:"tool-timeout"`
+    WorkerPool     int           `flag:"worker-pool"`
+    WorkerQueue    int           `flag:"worker-queue"`
+    SlowRequest    time.Duration `flag:"slow-request"`
+
+    ReadHeaderTimeout time.Duration `flag:"read-header-timeout"`
+    IdleTimeout       time.Duration `flag:"idle-timeout"`
+    WriteTimeout      time.Duration `flag:"write-timeout"`
+    MaxHeaderBytes    int           `flag:"max-header-bytes"`
+    MaxConnections    int           `flag:"max-connections"`
+    DrainTimeout      time.Duration `flag:"drain-timeout"`
+
+    Aliases         string        `flag:"aliases"`
+    Cities          string        `flag:"cities"`
+    FiscalCalendars string        `flag:"fiscal-calendars"`
+    FiscalCalendar  string        `flag:"fiscal-calendar"`
+    DB              string        `flag:"db"`
+    DefaultTimezone string        `flag:"default-timezone"`
+    ErrorFormat     string        `flag:"error-format"` // classic or structured
+    APIV1Sunset     string        `flag:"api-v1-sunset"`
+    IdempotencyTTL  time.Duration `flag:"idempotency-ttl"`
+    WebhookSecret   string        `flag:"webhook-secret"`
+    WebhookHosts    string        `flag:"webhook-allow-hosts"`
+    WebhookPrivate  bool          `flag:"webhook-allow-private"`
+    MaxSleep        time.Duration `flag:"max-sleep"`
+    EnableTools     string        `flag:"enable-tools"`
+    DisableTools    string        `flag:"disable-tools"`
+    I18nLocale      string        `flag:"i18n-locale"`
+    I18nDir         string        `flag:"i18n-dir"`
+    Plugins         string        `flag:"plugins"`
+    Downstream      string        `flag:"downstream"`
+    FreeBusy        string        `flag:"free-busy-calendars"`
+
+    OutboundTimeout         time.Duration `flag:"outbound-timeout"`
+    OutboundRetries         int           `flag:"outbound-retries"`
+    OutboundCacheTTL        time.Duration `flag:"outbound-cache-ttl"`
+    OutboundBreakerFailures int           `flag:"outbound-breaker-failures"`
+    OutboundBreakerCooldown time.Duration `flag:"outbound-breaker-cooldown"`
+    OutboundProxy           string        `flag:"outbound-proxy"`
+    RequiredBackends        string        `flag:"required-backends"`
+
+    AuditLog       string        `flag:"audit-log"`
+    AuditMaxSize   int64         `flag:"audit-max-size"`
+    AuditRetention time.Duration `flag:"audit-retention"`
+    Record         string        `flag:"record"`
+    Replay         string        `flag:"replay"`
+    MockTime       string        `flag:"mock-time"`
+    TimeOffset     string        `flag:"time-offset"`
+    NTPServers     string        `flag:"ntp-servers"`
+    NTPMaxDrift    time.Duration `flag:"ntp-max-drift"`
+
+    TzdataDir           string        `flag:"tzdata-dir"`
+    TzdataCheckInterval time.Duration `flag:"tzdata-check-interval"`
+    TzCacheSize         int           `flag:"tz-cache-size"`
+
+    PIDFile string `flag:"pid-file"`
+
+    // Ready, if set, is called once the server accepts connections
+    Ready func()
+}
+
+// DefaultConfig returns the configuration of the command without flags:
+// stdio, with HTTP listeners on 0.0.0.0:8080
+func DefaultConfig() Config {
+    return Config{
+        Transport:         "stdio",
+        Listen:            defaultListen,
+        Port:              defaultPort,
+        LogLevel:          defaultLogLevel,
+        LogMaxSize:        defaultLogMaxSize,
+        MaxBodySize:       defaultMaxBodySize,
+        WorkerPool:        runtime.NumCPU(),
+        WorkerQueue:       defaultWorkerQueue,
+        SlowRequest:       defaultSlowRequest,
+        ReadHeaderTimeout: defaultReadHeaderTimeout,
+        IdleTimeout:       defaultIdleTimeout,
+        MaxHeaderBytes:    defaultMaxHeaderBytes,
+        DrainTimeout:      defaultDrainTimeout,
+        DefaultTimezone:   "UTC",
+        MaxSleep:          defaultMaxSleep,
+        IdempotencyTTL:    defaultIdempotencyTTL,
+        AuditMaxSize:      defaultAuditMaxSize,
+        NTPServers:        defaultNTPServers,
+
+        TzdataCheckInterval: defaultTzdataCheckInterval,
+        TzCacheSize:         defaultTzCacheSize,
+
+        OutboundTimeout:         defaultOutboundTimeout,
+        OutboundRetries:         defaultOutboundRetries,
+        OutboundCacheTTL:        defaultOutboundCacheTTL,
+        OutboundBreakerFailures: defaultOutboundBreakerFailures,
+        OutboundBreakerCooldown: defaultOutboundBreakerCooldown,
+        RequiredBackends:        defaultRequiredBackend,
+    }
+}
+
+// configValues renders the tagged fields of cfg by flag name
+func configValues(cfg Config) map[string]string {
+    out := make(map[string]string)
+    v := reflect.ValueOf(cfg)
+    for i := 0; i < v.NumField(); i++ {
+        if name := v.Type().Field(i).Tag.Get("flag"); name != "" {
+            out[name] = fmt.Sprint(v.Field(i).Interface())
+        }
+    }
+    return out
+}
+
+// Server is a configured time server; Run serves it
+type Server struct {
+    cfg     Config
+    mcp     *server.MCPServer
+    specs   []listenerSpec
+    chain   *listenerConfig
+    closers []io.Closer
+    close   sync.Once
+
+    ctx  context.Context    // cancelled by Close to stop the background work
+    stop context.CancelFunc // of New and Run
+    wg   sync.WaitGroup
+}
+
+// runningConfig is the configuration reported by GET /admin/config
+var runningConfig = DefaultConfig()
+
+// onReady is Config.Ready of the running server
+var onReady func()
+
+// New checks cfg, opens the files and database it names and builds the MCP
+// server. The log is set up first, so a later failure is also logged there.
+func New(cfg Config) (srv *Server, err error) {
+    s := &Server{cfg: cfg}
+    s.ctx, s.stop = context.WithCancel(context.Background())
+    defer func() {
+        if err != nil {
+            if logOutput != io.Writer(os.Stderr) {
+                logAt(logError, "%v", err)
+            }
+            s.Close()
+        }
+    }()
+
+    /* ------------------------- logging setup ---------------------- */
+    out, err := openLogOutput(cfg.LogOutput, cfg.LogFile, cfg.LogMaxSize, cfg.LogMaxAge, cfg.LogMaxFiles)
+    if err != nil {
+        return nil, err
+    }
+    if c, ok := out.(io.Closer); ok && out != io.Writer(os.Stderr) {
+        // Log to stderr again once the sink is closed
+        s.closers = append(s.closers, closerFunc(func() error {
+            logOutput = os.Stderr
+            logger.SetFlags(log.LstdFlags)
+            setLogLevel(logLevel())
+            return c.Close()
+        }))
+    }
+    if _, ok := out.(leveledSink); ok {
+        logger.SetFlags(0) // syslog and the journal add their own timestamps
+    }
+    logOutput = out
+    setLogLevel(parseLvl(cfg.LogLevel))
+
+    logAt(logDebug, "starting %s %s", appName, appVersion)
+
+    /* ----------------------- configuration setup ------------------ */
+    s.specs, err = listenersFor(cfg.Listeners, cfg.Transport, effectiveAddr(cfg.Addr, cfg.Listen, cfg.Port), cfg.Pipe)
+    if err != nil {
+        return nil, err
+    }
+    // Token files win over environment variables, which win over flags
+    authTok, err := resolveToken(cfg.AuthToken, cfg.AuthTokenFile, envAuthToken)
+    if err != nil {
+        return nil, err
+    }
+    adminTok, err := resolveToken(cfg.AdminToken, cfg.AdminTokenFile, envAdminToken)
+    if err != nil {
+        return nil, err
+    }
+    if cfg.Debug && !adminTok.enabled() {
+        return nil, errors.New("-debug requires -admin-token (or ADMIN_TOKEN)")
+    }
+    // Environment variables win over flags, as for the tokens
+    if env := os.Getenv(envEnableTools); env != "" {
+        cfg.EnableTools = env
+    }
+    if env := os.Getenv(envDisableTools); env != "" {
+        cfg.DisableTools = env
+    }
+    if features, err = parseFeatureFilter(cfg.EnableTools, cfg.DisableTools); err != nil {
+        return nil, err
+    }
+    proxies, err := parseIPNets(cfg.TrustedProxies)
+    if err != nil {
+        return nil, err
+    }
+    acl, err := loadIPACL(cfg.AllowIPs, cfg.DenyIPs, cfg.IPACLFile)
+    if err != nil {
+        return nil, err
+    }
+    if cfg.AuthTokensFile != "" {
+        if callers, err = loadTokenRegistry(cfg.AuthTokensFile); err != nil {
+            return nil, err
+        }
+    }
+    authOn := authTok.enabled() || callers.enabled()
+    liveTokens["auth"] = authTok
+    liveTokens["admin"] = adminTok
+
+    maxSleep = cfg.MaxSleep
+    toolTimeout = cfg.ToolTimeout
+    heavyPool = newWorkerPool(cfg.WorkerPool, cfg.WorkerQueue)
+    slowRequests.setThreshold(cfg.SlowRequest)
+    drainTimeout = cfg.DrainTimeout
+    httpTuning = httpServerConfig{
+        readHeaderTimeout: cfg.ReadHeaderTimeout,
+        writeTimeout:      cfg.WriteTimeout,
+        idleTimeout:       cfg.IdleTimeout,
+        maxHeaderBytes:    cfg.MaxHeaderBytes,
+        maxConnections:    cfg.MaxConnections,
+    }
+    sseConns.setMax(cfg.MaxSSEClients)
+    s.chain = &listenerConfig{
+        authOn:    authOn,
+        authTok:   authTok,
+        adminTok:  adminTok,
+        debug:     cfg.Debug,
+        compress:  cfg.Compress,
+        graphql:   cfg.GraphQL,
+        maxBody:   cfg.MaxBodySize,
+        timeout:   cfg.RequestTimeout,
+        acl:       acl,
+        proxies:   proxies,
+        publicURL: cfg.PublicURL,
+        keepAlive: cfg.SSEKeepAlive,
+        idleTTL:   cfg.SSEIdleTimeout,
+        maxSSE:    cfg.MaxSSEClients,
+        pushEvery: cfg.ResourcePushInterval,
+        spawn:     s.background,
+    }
+
+    /* ----------------------- timezone aliases --------------------- */
+    // File aliases are loaded before storage so that they take precedence
+    if cfg.Aliases != "" {
+        n, err := tzAliases.loadFile(cfg.Aliases)
+        if err != nil {
+            return nil, fmt.Errorf("failed to load aliases: %w", err)
+        }
+        logAt(logInfo, "loaded %d timezone alias(es) from %s", n, cfg.Aliases)
+    }
+
+    /* --------------------------- tzdata --------------------------- */
+    // Loaded before anything resolves a zone
+    if cfg.TzCacheSize < 0 {
+        return nil, errors.New("-tz-cache-size must not be negative")
+    }
+    tzCache.resize(cfg.TzCacheSize)
+    if cfg.TzdataDir != "" {
+        if err := tzdata.use(cfg.TzdataDir); err != nil {
+            return nil, fmt.Errorf("invalid -tzdata-dir: %w", err)
+        }
+        if cfg.TzdataCheckInterval > 0 {
+            s.background(func(ctx context.Context) { tzdata.watch(ctx, cfg.TzdataCheckInterval) })
+        }
+    }
+
+    /* --------------------------- storage -------------------------- */
+    st, err := openStore(cfg.DB)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open database: %w", err)
+    }
+    s.closers = append(s.closers, st)
+    store = guardedStore{st}
+    backends = &backendRegistry{}
+    if cfg.DB != "" {
+        backends.register("store", "sqlite", storeProbe)
+        logAt(logInfo, "storage: using SQLite database %s", cfg.DB)
+    } else {
+        backends.register("store", "memory", storeProbe)
+    }
+    if n, err := tzAliases.useStore(store); err != nil {
+        return nil, fmt.Errorf("failed to load aliases: %w", err)
+    } else if n > 0 {
+        logAt(logInfo, "loaded %d timezone alias(es) from storage", n)
+    }
+    // Checked after the aliases are loaded so that an alias can be the default
+    if _, err := loadLocation(cfg.DefaultTimezone); err != nil {
+        return nil, fmt.Errorf("invalid -default-timezone: %w", err)
+    }
+    if cfg.Cities != "" {
+        entries, err := loadCityFile(cfg.Cities)
+        if err != nil {
+            return nil, fmt.Errorf("failed to load cities: %w", err)
+        }
+        useCities(entries)
+        logAt(logInfo, "loaded %d cities from %s", len(entries), cfg.Cities)
+    }
+    var fiscal []fiscalCalendar
+    if cfg.FiscalCalendars != "" {
+        if fiscal, err = loadFiscalCalendars(cfg.FiscalCalendars); err != nil {
+            return nil, fmt.Errorf("failed to load fiscal calendars: %w", err)
+        }
+        logAt(logInfo, "loaded %d fiscal calendar(s) from %s", len(fiscal), cfg.FiscalCalendars)
+    }
+    if err := useFiscalCalendars(fiscal, cfg.FiscalCalendar); err != nil {
+        return nil, fmt.Errorf("invalid -fiscal-calendar: %w", err)
+    }
+    defaultTimezone = cfg.DefaultTimezone
+    if errorFormat, err = parseErrorFormat(cfg.ErrorFormat); err != nil {
+        return nil, err
+    }
+    apiDeprecations = map[int]apiDeprecation{}
+    if cfg.APIV1Sunset != "" {
+        sunset, err := parseSunset(cfg.APIV1Sunset)
+        if err != nil {
+            return nil, fmt.Errorf("invalid -api-v1-sunset: %w", err)
+        }
+        apiDeprecations[apiV1] = apiDeprecation{sunset: sunset, successor: apiV2Prefix}
+        logAt(logInfo, "rest: /api/v1 is deprecated and sunsets on %s", sunset.Format(time.RFC3339))
+    }
+    if cfg.IdempotencyTTL < 0 {
+        return nil, errors.New("-idempotency-ttl must not be negative")
+    }
+    idempotency.setTTL(cfg.IdempotencyTTL)
+
+    /* --------------------- webhook schedules ---------------------- */
+    if authOn {
+        scheduler = newWebhookScheduler(cfg.WebhookSecret, parseHostList(cfg.WebhookHosts), cfg.WebhookPrivate)
+        s.background(scheduler.run)
+        if cfg.WebhookSecret == "" {
+            logAt(logWarn, "schedules: -webhook-secret is not set; webhook deliveries are unsigned")
+        }
+    } else if cfg.WebhookSecret != "" || cfg.WebhookHosts != "" || cfg.WebhookPrivate {
+        logAt(logWarn, "schedules: the schedule API needs -auth-token or -auth-tokens-file; webhook flags ignored")
+    }
+
+    /* -------------------------- audit log ------------------------- */
+    if cfg.AuditLog != "" {
+        sink, err := openAuditSink(cfg.AuditLog, cfg.AuditMaxSize)
+        if err != nil {
+            return nil, fmt.Errorf("failed to open audit log: %w", err)
+        }
+        s.closers = append(s.closers, sink)
+        auditLog = sink
+        if cfg.AuditRetention > 0 {
+            s.background(func(ctx context.Context) { runAuditPruner(ctx, sink, cfg.AuditRetention) })
+        }
+        logAt(logInfo, "audit: recording tool calls to %s", cfg.AuditLog)
+    }
+
+    /* ------------------------ record / replay --------------------- */
+    if cfg.Record != "" && cfg.Replay != "" {
+        return nil, errors.New("-record and -replay cannot be used together")
+    }
+    var rec *recorder
+    if cfg.Record != "" {
+        if rec, err = newRecorder(cfg.Record); err != nil {
+            return nil, fmt.Errorf("failed to open recording: %w", err)
+        }
+        s.closers = append(s.closers, rec)
+        logAt(logInfo, "record: writing MCP traffic to %s", cfg.Record)
+    }
+    if cfg.Replay != "" {
+        var n int
+        if replay, n, err = loadReplayer(cfg.Replay); err != nil {
+            return nil, fmt.Errorf("failed to load recording: %w", err)
+        }
+        logAt(logWarn, "replay: answering from %d recorded response(s) in %s", n, cfg.Replay)
+    }
+
+    /* --------------------------- mock clock ----------------------- */
+    if cfg.MockTime != "" && cfg.TimeOffset != "" {
+        return nil, errors.New("-mock-time and -time-offset cannot be used together")
+    }
+    if cfg.MockTime != "" {
+        t, err := time.Parse(time.RFC3339, cfg.MockTime)
+        if err != nil {
+            return nil, fmt.Errorf("invalid -mock-time %q: use RFC3339, e.g. 2025-01-01T00:00:00Z", cfg.MockTime)
+        }
+        clock.set(t)
+        logAt(logWarn, "clock: frozen at %s; answers do not follow the real time", t.Format(time.RFC3339))
+    }
+    if cfg.TimeOffset != "" {
+        d, err := parseClockOffset(cfg.TimeOffset)
+        if err != nil {
+            return nil, fmt.Errorf("invalid -time-offset: %w", err)
+        }
+        clock.shift(d)
+        logAt(logWarn, "clock: shifted by %s; answers do not follow the real time", formatClockOffset(d))
+    }
+
+    /* --------------------------- NTP drift ------------------------ */
+    ntpServers = parseNTPServers(cfg.NTPServers)
+    if len(ntpServers) > 0 {
+        backends.register("ntp", "ntp", nil)
+    }
+    if cfg.NTPMaxDrift > 0 {
+        if len(ntpServers) == 0 {
+            return nil, errors.New("-ntp-max-drift requires at least one -ntp-servers entry")
+        }
+        drift = &driftMonitor{maxDrift: cfg.NTPMaxDrift}
+        readinessChecks = append(readinessChecks, readinessCheck{"clock", drift.check})
+        s.background(drift.run)
+        logAt(logInfo, "ntp: checking host clock against %s every %s (limit %s)", strings.Join(ntpServers, ", "), ntpCheckInterval, cfg.NTPMaxDrift)
+    }
+
+    /* ------------------------- translations ----------------------- */
+    if cfg.I18nDir != "" {
+        n, err := translations.loadDir(cfg.I18nDir)
+        if err != nil {
+            return nil, fmt.Errorf("failed to load translations: %w", err)
+        }
+        logAt(logInfo, "loaded %d translation catalog(s) from %s", n, cfg.I18nDir)
+    }
+    if err := translations.setDefault(cfg.I18nLocale); err != nil {
+        return nil, fmt.Errorf("invalid -i18n-locale: %w", err)
+    }
+    if authOn && len(s.specs) > 0 {
+        logAt(logInfo, "authentication enabled with Bearer token")
+        if callers.enabled() {
+            logAt(logInfo, "loaded %d scoped token(s) from %s", callers.count(), cfg.AuthTokensFile)
+        }
+    }
+
+    /* ----------------------- build MCP server --------------------- */
+    // Resource subscriptions are answered on the SSE event stream only
+    subscribe := hasSSEListener(s.specs)
+
+    // Forget subscriptions, timers and reminders when their session goes away
+    // and stop their in-flight calls; record request ids for cancellation
+    // and keep the session list shown by /admin/sessions, refusing
+    // requests sent before initialize; tag static
+    // resource reads with an etag; translate listed descriptions and hide
+    // features turned off with -enable-tools / -disable-tools or not
+    // granted to the caller's scoped token
+    hooks := &server.Hooks{}
+    hooks.AddOnUnregisterSession(func(_ context.Context, sess server.ClientSession) {
+        resourceSubs.dropSession(sess.SessionID())
+        timers.dropSession(sess.SessionID())
+        if n := reminders.dropSession(sess.SessionID()); n > 0 {
+            logAt(logInfo, "session %s closed: dropped %d pending reminder(s)", sess.SessionID(), n)
+        }
+        if n := inflight.cancelSession(sess.SessionID()); n > 0 {
+            logAt(logInfo, "session %s closed: cancelled %d in-flight request(s)", sess.SessionID(), n)
+        }
+    })
+    installRequestIDs(hooks)
+    hooks.AddAfterReadResource(resourceETagHook)
+    sessions.trackSessions(hooks)
+    sessions.requireInitialize(hooks)
+    usage.trackUsage(hooks)
+    translations.install(hooks)
+    features.install(hooks)
+    installFeatureCheck(hooks, checkCallerScope)
+    if rec != nil {
+        rec.install(hooks)
+    }
+    if replay != nil {
+        replay.install(hooks)
+    }
+
+    // Create server with appropriate options
+    s.mcp = newMCPServer(hooks, subscribe)
+
+    /* ------------------------ plugin tools ------------------------ */
+    if cfg.Plugins != "" {
+        plugins, err := loadPlugins(cfg.Plugins)
+        if err != nil {
+            return nil, err
+        }
+        if err := registerPlugins(s.mcp, plugins); err != nil {
+            return nil, err
+        }
+        logAt(logInfo, "loaded %d plugin tool(s) from %s", len(plugins), cfg.Plugins)
+    }
+
+    /* ----------------------- outbound HTTP ------------------------ */
+    ocfg := outboundConfig{
+        timeout:         cfg.OutboundTimeout,
+        retries:         cfg.OutboundRetries,
+        cacheTTL:        cfg.OutboundCacheTTL,
+        breakerFailures: cfg.OutboundBreakerFailures,
+        breakerCooldown: cfg.OutboundBreakerCooldown,
+        proxy:           cfg.OutboundProxy,
+    }
+    if err := checkOutboundConfig(ocfg); err != nil {
+        return nil, err
+    }
+    outbound = newOutboundClient(ocfg)
+    if u, err := url.Parse(cfg.OutboundProxy); cfg.OutboundProxy != "" && err == nil {
+        logAt(logInfo, "outbound: calls go through proxy %s", u.Redacted())
+    }
+
+    /* ---------------------- downstream servers -------------------- */
+    if cfg.Downstream != "" {
+        servers, err := loadDownstream(cfg.Downstream)
+        if err != nil {
+            return nil, err
+        }
+        connected, err := mountDownstream(s.mcp, servers)
+        for _, d := range connected {
+            s.closers = append(s.closers, d)
+        }
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    /* --------------------- free/busy calendars -------------------- */
+    freeBusyCalendars = nil
+    if cfg.FreeBusy != "" {
+        if freeBusyCalendars, err = loadFreeBusyCalendars(cfg.FreeBusy); err != nil {
+            return nil, err
+        }
+        for _, c := range freeBusyCalendars {
+            backends.register(calendarBackend(c), c.Type, nil)
+        }
+        registerFreeBusyTools(s.mcp)
+        logAt(logInfo, "free/busy: %d calendar(s) from %s", len(freeBusyCalendars), cfg.FreeBusy)
+    }
+
+    /* -------------------------- backends -------------------------- */
+    if err := backends.require(cfg.RequiredBackends); err != nil {
+        return nil, err
+    }
+    s.background(backends.run)
+
+    if features.enabled() {
+        for _, name := range features.unknownTools(s.mcp.ListTools()) {
+            logAt(logWarn, "-enable-tools/-disable-tools: %q matches no tool", name)
+        }
+        logAt(logInfo, "feature filter: enable=%q disable=%q", cfg.EnableTools, cfg.DisableTools)
+    }
+    runningConfig = cfg
+    return s, nil
+}
+
+// MCPServer returns the underlying MCP server, for registering resources,
+// prompts or hooks of the embedding program
+func (s *Server) MCPServer() *server.MCPServer {
+    return s.mcp
+}
+
+// AddTool registers a tool of the embedding program. It passes through the
+// same middleware as the built-in tools: statistics, audit, usage, replay,
+// cancellation and the -enable-tools / -disable-tools filter.
+func (s *Server) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+    s.mcp.AddTool(tool, handler)
+}
+
+// Run serves until ctx is cancelled or a listener fails, then shuts the
+// listeners down and closes the server. A Server runs once.
+func (s *Server) Run(ctx context.Context) error {
+    defer s.Close()
+    onReady = s.cfg.Ready
+    defer func() { onReady = nil }()
+
+    /* --------------------------- pid file ------------------------- */
+    if s.cfg.PIDFile != "" {
+        if err := writePIDFile(s.cfg.PIDFile); err != nil {
+            return fmt.Errorf("-pid-file: %w", err)
+        }
+    }
+
+    /* -------------------- choose transport & serve ---------------- */
+    /* ---------------------------- stdio -------------------------- */
+    if len(s.specs) == 0 {
+        if s.chain.authOn {
+            logAt(logWarn, "auth-token is ignored for stdio transport")
+        }
+        logAt(logInfo, "serving via stdio transport")
+        serverReady()
+        err := newStdioServer(s.mcp).Listen(ctx, os.Stdin, os.Stdout)
+        if err != nil && !errors.Is(err, context.Canceled) {
+            return fmt.Errorf("stdio server error: %w", err)
+        }
+        return nil
+    }
+
+    /* -------------------------- listeners ------------------------- */
+    activeTransports = listenerTransports(s.specs)
+    return serveListeners(ctx, s.specs, s.mcp, s.chain)
+}
+
+// Close stops the background work of New and Run (webhook scheduler, audit
+// pruner, tzdata, NTP and backend checks, SSE reaper and resource pusher),
+// waits for it, removes the PID file and closes the log, database, audit
+// log and recording opened by New. Run calls it on return. Another Server
+// can be created once Close has returned.
+func (s *Server) Close() error {
+    var errs []error
+    s.close.Do(func() {
+        s.stop()
+        s.wg.Wait()
+        if s.cfg.PIDFile != "" {
+            removePIDFile()
+        }
+        for i := len(s.closers) - 1; i >= 0; i-- {
+            errs = append(errs, s.closers[i].Close())
+        }
+        resetServerState()
+    })
+    return errors.Join(errs...)
+}
+
+// background runs f in a goroutine until Close cancels its context
+func (s *Server) background(f func(ctx context.Context)) {
+    s.wg.Add(1)
+    go func() {
+        defer s.wg.Done()
+        f(s.ctx)
+    }()
+}
+
+// resetServerState drops the package state that points at the resources of
+// a closed Server, so that the next New starts from scratch
+func resetServerState() {
+    scheduler = nil
+    auditLog = nil
+    onReady = nil
+    if drift != nil {
+        drift = nil
+        checks := readinessChecks[:0:0]
+        for _, c := range readinessChecks {
+            if c.name != "clock" {
+                checks = append(checks, c)
+            }
+        }
+        readinessChecks = checks
+    }
+}
+
+// closerFunc adapts a function to io.Closer
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+// serverReady reports that the server is serving, to systemd and to
+// Config.Ready
+func serverReady() {
+    sdReady()
+    if onReady != nil {
+        onReady()
+    }
+}
+
+// Logf writes a line to the server log at level (debug, info, warn or
+// error), for programs that log alongside the server
+func Logf(level, format string, v ...any) {
+    logAt(parseLvl(level), format, v...)
+}
@@ -0,0 +1,164 @@
+// -*- coding: utf-8 -*-
+// fasttime_test.go - Tests for the public Go API
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "net"
+    "net/http"
+    "os"
+    "path/filepath"
+    "runtime"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestConfigValues(t *testing.T) {
+    cfg := DefaultConfig()
+    cfg.Compress = true
+    got := configValues(cfg)
+    if got["transport"] != "stdio" || got["port"] != "8080" || got["compress"] != "true" || got["read-header-timeout"] != "10s" {
+        t.Errorf("configValues = %v", got)
+    }
+    if _, ok := got["Ready"]; ok {
+        t.Error("untagged fields should be left out")
+    }
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+    for name, edit := range map[string]func(*Config){
+        "transport": func(c *Config) { c.Transport = "carrier-pigeon" },
+        "debug":     func(c *Config) { c.Debug = true },
+        "timezone":  func(c *Config) { c.DefaultTimezone = "Nowhere/X" },
+        "clock":     func(c *Config) { c.MockTime, c.TimeOffset = "2025-01-01T00:00:00Z", "+1h" },
+    } {
+        cfg := DefaultConfig()
+        cfg.LogLevel = "none"
+        edit(&cfg)
+        if _, err := New(cfg); err == nil {
+            t.Errorf("%s: New should fail", name)
+        }
+    }
+}
+
+// waitGoroutines waits for the goroutine count to drop back to want
+func waitGoroutines(t *testing.T, want int) {
+    t.Helper()
+    deadline := time.Now().Add(5 * time.Second)
+    for runtime.NumGoroutine() > want {
+        if time.Now().After(deadline) {
+            buf := make([]byte, 1<<16)
+            t.Fatalf("%d goroutines left running, want %d:\n%s", runtime.NumGoroutine(), want, buf[:runtime.Stack(buf, true)])
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+}
+
+func TestServerCloseStopsBackground(t *testing.T) {
+    dir := t.TempDir()
+    cfg := DefaultConfig()
+    cfg.LogLevel = "none"
+    cfg.AuthToken = "secret"
+    cfg.AuditLog = filepath.Join(dir, "audit.jsonl")
+    cfg.AuditRetention = time.Hour
+    before := runtime.NumGoroutine()
+
+    for i := 0; i < 2; i++ {
+        srv, err := New(cfg)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if scheduler == nil || auditLog == nil {
+            t.Fatal("New did not start the scheduler and audit log")
+        }
+        if err := srv.Close(); err != nil {
+            t.Fatal(err)
+        }
+        if scheduler != nil || auditLog != nil {
+            t.Error("Close left the scheduler or audit log of the closed server installed")
+        }
+        waitGoroutines(t, before)
+    }
+
+    // A New that fails after starting its background work stops it again
+    cfg.RequiredBackends = "no-such-backend"
+    if _, err := New(cfg); err == nil {
+        t.Fatal("New should fail on an unknown required backend")
+    }
+    waitGoroutines(t, before)
+}
+
+func TestServerRun(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("serves on a Unix socket")
+    }
+    dir := t.TempDir()
+    sock := filepath.Join(dir, "fts.sock")
+    ready := make(chan struct{})
+    cfg := DefaultConfig()
+    cfg.LogLevel = "none"
+    cfg.Listeners = "rest=" + unixAddrPrefix + sock
+    cfg.PIDFile = filepath.Join(dir, "fts.pid")
+    cfg.Ready = func() { close(ready) }
+    srv, err := New(cfg)
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv.AddTool(mcp.NewTool("embedded_tool"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return mcp.NewToolResultText("ok"), nil
+    })
+    if srv.MCPServer().GetTool("embedded_tool") == nil {
+        t.Error("AddTool did not register the tool")
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan error, 1)
+    go func() { done <- srv.Run(ctx) }()
+    select {
+    case <-ready:
+    case err := <-done:
+        t.Fatalf("Run = %v", err)
+    case <-time.After(5 * time.Second):
+        t.Fatal("server not ready")
+    }
+    if pid, err := readPIDFile(cfg.PIDFile); err != nil || pid != os.Getpid() {
+        t.Errorf("pid file = %d, %v", pid, err)
+    }
+
+    c := http.Client{Transport: &http.Transport{
+        DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+            return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+        },
+    }}
+    resp, err := c.Get("http://fts/health")
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        t.Errorf("/health = %d", resp.StatusCode)
+    }
+
+    cancel()
+    select {
+    case err := <-done:
+        if err != nil {
+            t.Errorf("Run = %v", err)
+        }
+    case <-time.After(shutdownTimeout + time.Second):
+        t.Fatal("Run did not return after cancel")
+    }
+    if _, err := os.Stat(cfg.PIDFile); !errors.Is(err, os.ErrNotExist) {
+        t.Errorf("pid file left behind: %v", err)
+    }
+    if err := srv.Close(); err != nil {
+        t.Errorf("second Close = %v", err)
+    }
+}
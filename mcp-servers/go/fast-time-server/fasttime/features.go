@@ -0,0 +1,226 @@
+// -*- coding: utf-8 -*-
+// features.go - per-deployment allow and deny lists for MCP features
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets operators expose only part of the server with
+// -enable-tools / -disable-tools (or ENABLE_TOOLS / DISABLE_TOOLS). Both take
+// a comma-separated list; plain entries name tools, and "prompt:" or
+// "resource:" entries name prompts and resource URIs. "*" matches anything
+// and a URI template's "{var}" matches any value:
+//
+//   -enable-tools=get_system_time,convert_time,prompt:compare_timezones
+//   -disable-tools='timer_*,sleep,wait_until,resource:markets://*'
+//
+// Deny entries always win. An enable list only restricts the kinds it names,
+// so listing tools does not hide every prompt. Hidden features are left out
+// of tools/list, prompts/list and resources/list, and calling one fails as
+// if it did not exist.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "strings"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Kinds of MCP feature
+const (
+    featureTool     = "tool"
+    featurePrompt   = "prompt"
+    featureResource = "resource"
+)
+
+// featurePattern is one list entry and its compiled form
+type featurePattern struct {
+    text string
+    re   *regexp.Regexp
+}
+
+// featureFilter decides which tools, prompts and resources are exposed
+type featureFilter struct {
+    enable  map[string][]featurePattern // by kind
+    disable map[string][]featurePattern
+}
+
+// features is the process-wide filter (set in main)
+var features = &featureFilter{}
+
+// featurePlaceholder matches "{var}" in a URI template
+var featurePlaceholder = regexp.MustCompile(`\\\{[^}]*\\\}`)
+
+// parseFeatureList parses a comma-separated list into patterns by kind
+func parseFeatureList(spec string) (map[string][]featurePattern, error) {
+    out := make(map[string][]featurePattern)
+    for _, entry := range strings.Split(spec, ",") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        kind, name := featureTool, entry
+        for _, k := range []string{featureTool, featurePrompt, featureResource} {
+            if rest, ok := strings.CutPrefix(entry, k+":"); ok {
+                kind, name = k, rest
+                break
+            }
+        }
+        if name == "" {
+            return nil, fmt.Errorf("empty %s name in %q", kind, entry)
+        }
+        expr := regexp.QuoteMeta(name)
+        expr = strings.ReplaceAll(expr, `\*`, `.*`)
+        expr = featurePlaceholder.ReplaceAllString(expr, `.*`)
+        out[kind] = append(out[kind], featurePattern{text: entry, re: regexp.MustCompile("^" + expr + "$")})
+    }
+    return out, nil
+}
+
+// parseFeatureFilter builds a filter from enable and deny lists
+func parseFeatureFilter(enableSpec, disableSpec string) (*featureFilter, error) {
+    enable, err := parseFeatureList(enableSpec)
+    if err != nil {
+        return nil, fmt.Errorf("-enable-tools: %w", err)
+    }
+    disable, err := parseFeatureList(disableSpec)
+    if err != nil {
+        return nil, fmt.Errorf("-disable-tools: %w", err)
+    }
+    return &featureFilter{enable: enable, disable: disable}, nil
+}
+
+// matchAny reports whether name matches one of the patterns
+func matchAny(patterns []featurePattern, name string) bool {
+    for _, p := range patterns {
+        if p.re.MatchString(name) {
+            return true
+        }
+    }
+    return false
+}
+
+// enabled reports whether any filtering is configured
+func (f *featureFilter) enabled() bool {
+    return len(f.enable) > 0 || len(f.disable) > 0
+}
+
+// allowed reports whether the feature of the given kind and name is exposed
+func (f *featureFilter) allowed(kind, name string) bool {
+    if matchAny(f.disable[kind], name) {
+        return false
+    }
+    if patterns := f.enable[kind]; len(patterns) > 0 {
+        return matchAny(patterns, name)
+    }
+    return true
+}
+
+// unknownTools returns the tool entries that match no registered tool,
+// which are most likely typos
+func (f *featureFilter) unknownTools(registered map[string]*server.ServerTool) []string {
+    var unknown []string
+    for _, list := range []map[string][]featurePattern{f.enable, f.disable} {
+        for _, p := range list[featureTool] {
+            found := false
+            for name := range registered {
+                if p.re.MatchString(name) {
+                    found = true
+                    break
+                }
+            }
+            if !found {
+                unknown = append(unknown, p.text)
+            }
+        }
+    }
+    return unknown
+}
+
+// featureCheck returns an error when the feature of the given kind and name
+// may not be used by the request in ctx
+type featureCheck func(ctx context.Context, kind, name string) error
+
+// check hides filtered features as if they did not exist
+func (f *featureFilter) check(_ context.Context, kind, name string) error {
+    if !f.allowed(kind, name) {
+        return fmt.Errorf("%s '%s' not found", kind, name)
+    }
+    return nil
+}
+
+// install registers the hooks that hide filtered features
+func (f *featureFilter) install(hooks *server.Hooks) {
+    installFeatureCheck(hooks, f.check)
+}
+
+// installFeatureCheck registers hooks that reject calls to features check
+// refuses before they are dispatched and leave them out of list results
+func installFeatureCheck(hooks *server.Hooks, check featureCheck) {
+    hooks.AddOnRequestInitialization(func(ctx context.Context, _ any, message any) error {
+        raw, ok := message.(json.RawMessage)
+        if !ok {
+            return nil
+        }
+        var msg struct {
+            Method string `json:"method"`
+            Params struct {
+                Name string `json:"name"`
+                URI  string `json:"uri"`
+            } `json:"params"`
+        }
+        if json.Unmarshal(raw, &msg) != nil {
+            return nil
+        }
+        switch mcp.MCPMethod(msg.Method) {
+        case mcp.MethodToolsCall:
+            return check(ctx, featureTool, msg.Params.Name)
+        case mcp.MethodPromptsGet:
+            return check(ctx, featurePrompt, msg.Params.Name)
+        case mcp.MethodResourcesRead:
+            return check(ctx, featureResource, msg.Params.URI)
+        }
+        return nil
+    })
+    hooks.AddAfterListTools(func(ctx context.Context, _ any, _ *mcp.ListToolsRequest, res *mcp.ListToolsResult) {
+        kept := res.Tools[:0]
+        for _, t := range res.Tools {
+            if check(ctx, featureTool, t.Name) == nil {
+                kept = append(kept, t)
+            }
+        }
+        res.Tools = kept
+    })
+    hooks.AddAfterListPrompts(func(ctx context.Context, _ any, _ *mcp.ListPromptsRequest, res *mcp.ListPromptsResult) {
+        kept := res.Prompts[:0]
+        for _, p := range res.Prompts {
+            if check(ctx, featurePrompt, p.Name) == nil {
+                kept = append(kept, p)
+            }
+        }
+        res.Prompts = kept
+    })
+    hooks.AddAfterListResources(func(ctx context.Context, _ any, _ *mcp.ListResourcesRequest, res *mcp.ListResourcesResult) {
+        kept := res.Resources[:0]
+        for _, r := range res.Resources {
+            if check(ctx, featureResource, r.URI) == nil {
+                kept = append(kept, r)
+            }
+        }
+        res.Resources = kept
+    })
+    hooks.AddAfterListResourceTemplates(func(ctx context.Context, _ any, _ *mcp.ListResourceTemplatesRequest, res *mcp.ListResourceTemplatesResult) {
+        kept := res.ResourceTemplates[:0]
+        for _, rt := range res.ResourceTemplates {
+            if rt.URITemplate == nil || rt.URITemplate.Template == nil || check(ctx, featureResource, rt.URITemplate.Raw()) == nil {
+                kept = append(kept, rt)
+            }
+        }
+        res.ResourceTemplates = kept
+    })
+}
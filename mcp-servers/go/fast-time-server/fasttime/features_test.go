@@ -0,0 +1,116 @@
+// -*- coding: utf-8 -*-
+// features_test.go - Tests for tool/prompt/resource allow and deny lists
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestFeatureFilterAllowed(t *testing.T) {
+    f, err := parseFeatureFilter("get_system_time, convert_time, timer_*, prompt:compare_timezones",
+        "timer_stop,resource:markets://exchanges/{code}")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        kind, name string
+        want       bool
+    }{
+        {featureTool, "get_system_time", true},
+        {featureTool, "timer_lap", true},
+        {featureTool, "timer_stop", false}, // deny wins over the wildcard
+        {featureTool, "is_dst", false},     // not in the enable list
+        {featurePrompt, "compare_timezones", true},
+        {featurePrompt, "schedule_meeting", false},
+        {featureResource, "timezone://info", true}, // no resource enable list
+        {featureResource, "markets://exchanges/NYSE", false},
+        {featureResource, "markets://exchanges/{code}", false},
+        {featureResource, "markets://exchanges", true},
+    }
+    for _, tt := range tests {
+        if got := f.allowed(tt.kind, tt.name); got != tt.want {
+            t.Errorf("allowed(%s, %s) = %v, want %v", tt.kind, tt.name, got, tt.want)
+        }
+    }
+
+    if (&featureFilter{}).enabled() || !f.enabled() {
+        t.Error("enabled() mismatch")
+    }
+    if _, err := parseFeatureFilter("prompt:", ""); err == nil {
+        t.Error("expected error for an empty prompt name")
+    }
+}
+
+func TestFeatureFilterUnknownTools(t *testing.T) {
+    f, _ := parseFeatureFilter("get_system_time,get_sytem_time", "timer_*,nope_*")
+    registered := map[string]*server.ServerTool{"get_system_time": nil, "timer_lap": nil}
+    got := strings.Join(f.unknownTools(registered), ",")
+    if got != "get_sytem_time,nope_*" {
+        t.Errorf("unknownTools = %q", got)
+    }
+}
+
+func TestFeatureFilterHooks(t *testing.T) {
+    f, _ := parseFeatureFilter("", "convert_time,prompt:schedule_meeting")
+    hooks := &server.Hooks{}
+    f.install(hooks)
+    srv := server.NewMCPServer("test", "1.0",
+        server.WithToolCapabilities(false),
+        server.WithPromptCapabilities(false),
+        server.WithHooks(hooks),
+    )
+    srv.AddTool(mcp.NewTool("get_system_time"), handleGetSystemTime)
+    srv.AddTool(mcp.NewTool("convert_time"), handleConvertTime)
+    promptHandler := func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+        return mcp.NewGetPromptResult("", nil), nil
+    }
+    srv.AddPrompt(mcp.NewPrompt("compare_timezones"), promptHandler)
+    srv.AddPrompt(mcp.NewPrompt("schedule_meeting"), promptHandler)
+
+    handle := func(msg string) mcp.JSONRPCMessage {
+        return srv.HandleMessage(context.Background(), json.RawMessage(msg))
+    }
+    decode := func(resp mcp.JSONRPCMessage, v any) {
+        t.Helper()
+        r, ok := resp.(mcp.JSONRPCResponse)
+        if !ok {
+            t.Fatalf("unexpected response %#v", resp)
+        }
+        data, _ := json.Marshal(r.Result)
+        _ = json.Unmarshal(data, v)
+    }
+
+    var tools mcp.ListToolsResult
+    decode(handle(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), &tools)
+    if len(tools.Tools) != 1 || tools.Tools[0].Name != "get_system_time" {
+        t.Errorf("tools/list = %+v", tools.Tools)
+    }
+    var prompts mcp.ListPromptsResult
+    decode(handle(`{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`), &prompts)
+    if len(prompts.Prompts) != 1 || prompts.Prompts[0].Name != "compare_timezones" {
+        t.Errorf("prompts/list = %+v", prompts.Prompts)
+    }
+
+    resp := handle(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"convert_time","arguments":{}}}`)
+    if e, ok := resp.(mcp.JSONRPCError); !ok || !strings.Contains(e.Error.Message, "not found") {
+        t.Errorf("hidden tool call: %#v", resp)
+    }
+    resp = handle(`{"jsonrpc":"2.0","id":4,"method":"prompts/get","params":{"name":"schedule_meeting"}}`)
+    if _, ok := resp.(mcp.JSONRPCError); !ok {
+        t.Errorf("hidden prompt get: %#v", resp)
+    }
+    resp = handle(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"get_system_time","arguments":{}}}`)
+    if _, ok := resp.(mcp.JSONRPCResponse); !ok {
+        t.Errorf("visible tool call failed: %#v", resp)
+    }
+}
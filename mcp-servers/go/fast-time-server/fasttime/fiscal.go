@@ -0,0 +1,327 @@
+// -*- coding: utf-8 -*-
+// fiscal.go - fiscal calendar definitions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file defines the fiscal years used by fiscal_quarter_of and
+// fiscal_period_boundaries (tools_fiscal.go). A fiscal calendar is either
+//
+//   - month based: the year starts on start_month/start_day and its twelve
+//     periods are calendar months, three to a quarter, or
+//   - week based (4-4-5, 4-5-4 or 5-4-4): the year ends on the week_ends_on
+//     weekday that is the last one in the month before start_month, or the
+//     one nearest its last day (year_end: nearest, as in the NRF retail
+//     calendar); quarters are 13 weeks split into periods by the pattern and
+//     the extra week of a 53-week year goes to the last period.
+//
+// naming says whether FY2025 is the year that ends (end) or starts (start)
+// in 2025. Built-in calendars cover common cases; -fiscal-calendars adds or
+// replaces calendars from a YAML or JSON list and -fiscal-calendar picks the
+// one tools use by default:
+//
+//   - name: acme
+//     start_month: 2
+//     pattern: 4-4-5
+//     week_ends_on: saturday
+//     year_end: nearest
+//     naming: start
+
+package fasttime
+
+import (
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+    "time"
+
+    "gopkg.in/yaml.v3"
+)
+
+// Fiscal calendar patterns
+const (
+    fiscalMonths = "months"
+    fiscal445    = "4-4-5"
+    fiscal454    = "4-5-4"
+    fiscal544    = "5-4-4"
+)
+
+// fiscalPatternWeeks lists the weeks of each period in a quarter
+var fiscalPatternWeeks = map[string][]int{
+    fiscal445: {4, 4, 5},
+    fiscal454: {4, 5, 4},
+    fiscal544: {5, 4, 4},
+}
+
+// fiscalCalendar defines a fiscal year
+type fiscalCalendar struct {
+    Name        string `json:"name" yaml:"name"`
+    Description string `json:"description,omitempty" yaml:"description"`
+    StartMonth  int    `json:"start_month" yaml:"start_month"`
+    StartDay    int    `json:"start_day,omitempty" yaml:"start_day"` // month based only
+    Pattern     string `json:"pattern" yaml:"pattern"`
+    WeekEndsOn  string `json:"week_ends_on,omitempty" yaml:"week_ends_on"` // week based only
+    YearEnd     string `json:"year_end,omitempty" yaml:"year_end"`         // last or nearest
+    Naming      string `json:"naming" yaml:"naming"`                       // end or start
+
+    weekEnd time.Weekday
+}
+
+// builtinFiscalCalendars are available without -fiscal-calendars
+var builtinFiscalCalendars = []fiscalCalendar{
+    {Name: "calendar", Description: "Calendar year", StartMonth: 1},
+    {Name: "us-federal", Description: "US federal government, October to September", StartMonth: 10},
+    {Name: "uk-tax", Description: "UK personal tax year, 6 April to 5 April", StartMonth: 4, StartDay: 6, Naming: "start"},
+    {Name: "india", Description: "India, April to March", StartMonth: 4},
+    {Name: "japan", Description: "Japan, April to March", StartMonth: 4, Naming: "start"},
+    {Name: "australia", Description: "Australia, July to June", StartMonth: 7},
+    {Name: "retail-454", Description: "NRF retail 4-5-4, ending the Saturday nearest 31 January", StartMonth: 2,
+        Pattern: fiscal454, WeekEndsOn: "saturday", YearEnd: "nearest", Naming: "start"},
+}
+
+// fiscalCalendars holds the calendars by name; defaultFiscalCalendar is
+// used when a tool names none
+var (
+    fiscalCalendars       = mustFiscalCalendars(builtinFiscalCalendars)
+    defaultFiscalCalendar = "calendar"
+)
+
+func mustFiscalCalendars(list []fiscalCalendar) map[string]*fiscalCalendar {
+    out := make(map[string]*fiscalCalendar, len(list))
+    for i := range list {
+        c := list[i]
+        if err := c.validate(); err != nil {
+            panic(err)
+        }
+        out[c.Name] = &c
+    }
+    return out
+}
+
+// validate checks a definition and fills in defaults
+func (c *fiscalCalendar) validate() error {
+    if !aliasNamePattern.MatchString(c.Name) {
+        return fmt.Errorf("invalid fiscal calendar name %q: use up to 64 letters, digits, '.', '_' or '-'", c.Name)
+    }
+    if c.StartMonth < 1 || c.StartMonth > 12 {
+        return fmt.Errorf("fiscal calendar %s: start_month must be 1-12", c.Name)
+    }
+    if c.Pattern == "" {
+        c.Pattern = fiscalMonths
+    }
+    if c.Naming == "" {
+        c.Naming = "end"
+    }
+    if c.Naming != "end" && c.Naming != "start" {
+        return fmt.Errorf("fiscal calendar %s: naming must be end or start", c.Name)
+    }
+    if c.Pattern == fiscalMonths {
+        if c.StartDay == 0 {
+            c.StartDay = 1
+        }
+        if c.StartDay < 1 || c.StartDay > 28 {
+            return fmt.Errorf("fiscal calendar %s: start_day must be 1-28", c.Name)
+        }
+        if c.WeekEndsOn != "" || c.YearEnd != "" {
+            return fmt.Errorf("fiscal calendar %s: week_ends_on and year_end need a 4-4-5, 4-5-4 or 5-4-4 pattern", c.Name)
+        }
+        return nil
+    }
+    if _, ok := fiscalPatternWeeks[c.Pattern]; !ok {
+        return fmt.Errorf("fiscal calendar %s: pattern must be months, 4-4-5, 4-5-4 or 5-4-4", c.Name)
+    }
+    if c.StartDay > 1 {
+        return fmt.Errorf("fiscal calendar %s: start_day only applies to month based calendars", c.Name)
+    }
+    c.StartDay = 0
+    if c.WeekEndsOn == "" {
+        c.WeekEndsOn = "saturday"
+    }
+    wd, err := parseWeekday(c.WeekEndsOn)
+    if err != nil {
+        return fmt.Errorf("fiscal calendar %s: week_ends_on: %w", c.Name, err)
+    }
+    c.weekEnd, c.WeekEndsOn = wd, strings.ToLower(wd.String())
+    if c.YearEnd == "" {
+        c.YearEnd = "last"
+    }
+    if c.YearEnd != "last" && c.YearEnd != "nearest" {
+        return fmt.Errorf("fiscal calendar %s: year_end must be last or nearest", c.Name)
+    }
+    return nil
+}
+
+// weekBased reports whether the calendar counts in weeks
+func (c *fiscalCalendar) weekBased() bool { return c.Pattern != fiscalMonths }
+
+// labelOffset is the number of calendar years between the start-year and
+// end-year names of a fiscal year
+func (c *fiscalCalendar) labelOffset() int {
+    if c.StartMonth == 1 && c.StartDay <= 1 {
+        return 0
+    }
+    return 1
+}
+
+// civilDate returns midnight UTC of a calendar date
+func civilDate(y int, m time.Month, d int) time.Time {
+    return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// yearEndIn returns the last day of the week-based fiscal year that ends
+// around the end of the month before StartMonth in calendar year y
+func (c *fiscalCalendar) yearEndIn(y int) time.Time {
+    endMonth := time.Month(c.StartMonth - 1)
+    if endMonth == 0 {
+        endMonth = time.December
+    }
+    last := civilDate(y, endMonth+1, 0)
+    back := (int(last.Weekday()) - int(c.weekEnd) + 7) % 7
+    if c.YearEnd == "nearest" && back > 3 {
+        return last.AddDate(0, 0, 7-back)
+    }
+    return last.AddDate(0, 0, -back)
+}
+
+// yearBounds returns the first and last day of fiscal year label
+func (c *fiscalCalendar) yearBounds(label int) (time.Time, time.Time) {
+    if c.weekBased() {
+        e := label
+        if c.Naming == "start" {
+            e += c.labelOffset()
+        }
+        return c.yearEndIn(e-1).AddDate(0, 0, 1), c.yearEndIn(e)
+    }
+    s := label
+    if c.Naming == "end" {
+        s -= c.labelOffset()
+    }
+    start := civilDate(s, time.Month(c.StartMonth), c.StartDay)
+    return start, start.AddDate(1, 0, -1)
+}
+
+// yearOf returns the label of the fiscal year containing day d
+func (c *fiscalCalendar) yearOf(d time.Time) int {
+    for label := d.Year() - 1; label <= d.Year()+1; label++ {
+        if start, end := c.yearBounds(label); !d.Before(start) && !d.After(end) {
+            return label
+        }
+    }
+    return d.Year() // not reached for valid calendars
+}
+
+// fiscalSpan is a fiscal year, quarter or period as inclusive dates
+type fiscalSpan struct {
+    Label string `json:"label"`
+    Start string `json:"start"`
+    End   string `json:"end"`
+    Days  int    `json:"days"`
+    Weeks int    `json:"weeks,omitempty"`
+
+    start, end time.Time
+}
+
+func (c *fiscalCalendar) span(label string, start, end time.Time) fiscalSpan {
+    days := int(end.Sub(start).Hours()/24) + 1
+    s := fiscalSpan{Label: label, Start: start.Format("2006-01-02"), End: end.Format("2006-01-02"), Days: days, start: start, end: end}
+    if c.weekBased() {
+        s.Weeks = days / 7
+    }
+    return s
+}
+
+// yearSpan returns fiscal year label
+func (c *fiscalCalendar) yearSpan(label int) fiscalSpan {
+    start, end := c.yearBounds(label)
+    return c.span(fmt.Sprintf("FY%d", label), start, end)
+}
+
+// periods returns the twelve periods of fiscal year label
+func (c *fiscalCalendar) periods(label int) []fiscalSpan {
+    start, end := c.yearBounds(label)
+    out := make([]fiscalSpan, 0, 12)
+    if !c.weekBased() {
+        for i := 0; i < 12; i++ {
+            ps := start.AddDate(0, i, 0)
+            out = append(out, c.span(fmt.Sprintf("FY%d P%d", label, i+1), ps, start.AddDate(0, i+1, -1)))
+        }
+        return out
+    }
+    weeks := fiscalPatternWeeks[c.Pattern]
+    cursor := start
+    for i := 0; i < 12; i++ {
+        pe := cursor.AddDate(0, 0, 7*weeks[i%3]-1)
+        if i == 11 {
+            pe = end // a 53rd week lengthens the last period
+        }
+        out = append(out, c.span(fmt.Sprintf("FY%d P%d", label, i+1), cursor, pe))
+        cursor = pe.AddDate(0, 0, 1)
+    }
+    return out
+}
+
+// quarters returns the four quarters of fiscal year label
+func (c *fiscalCalendar) quarters(label int) []fiscalSpan {
+    p := c.periods(label)
+    out := make([]fiscalSpan, 0, 4)
+    for q := 0; q < 4; q++ {
+        out = append(out, c.span(fmt.Sprintf("FY%d Q%d", label, q+1), p[3*q].start, p[3*q+2].end))
+    }
+    return out
+}
+
+/* ------------------------------------------------------------------ */
+/*                          configuration                             */
+/* ------------------------------------------------------------------ */
+
+// loadFiscalCalendars reads a -fiscal-calendars file
+func loadFiscalCalendars(path string) ([]fiscalCalendar, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var list []fiscalCalendar
+    if err := yaml.Unmarshal(data, &list); err != nil {
+        return nil, fmt.Errorf("%s: want a list of fiscal calendars: %w", path, err)
+    }
+    seen := make(map[string]bool)
+    for i := range list {
+        if err := list[i].validate(); err != nil {
+            return nil, fmt.Errorf("%s: %w", path, err)
+        }
+        if seen[list[i].Name] {
+            return nil, fmt.Errorf("%s: fiscal calendar %s is defined twice", path, list[i].Name)
+        }
+        seen[list[i].Name] = true
+    }
+    return list, nil
+}
+
+// useFiscalCalendars adds list to the built-in calendars, replacing any of
+// the same name, and makes def the default
+func useFiscalCalendars(list []fiscalCalendar, def string) error {
+    all := mustFiscalCalendars(builtinFiscalCalendars)
+    for i := range list {
+        c := list[i]
+        all[c.Name] = &c
+    }
+    if def == "" {
+        def = "calendar"
+    }
+    if _, ok := all[def]; !ok {
+        return fmt.Errorf("unknown fiscal calendar %q (have %s)", def, strings.Join(fiscalCalendarNames(all), ", "))
+    }
+    fiscalCalendars, defaultFiscalCalendar = all, def
+    return nil
+}
+
+// fiscalCalendarNames lists the calendar names in order
+func fiscalCalendarNames(m map[string]*fiscalCalendar) []string {
+    out := make([]string, 0, len(m))
+    for name := range m {
+        out = append(out, name)
+    }
+    sort.Strings(out)
+    return out
+}
@@ -0,0 +1,105 @@
+// -*- coding: utf-8 -*-
+// fiscal_test.go - Tests for fiscal calendar definitions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestFiscalCalendarYears(t *testing.T) {
+    cases := []struct {
+        calendar   string
+        date       string
+        label      int
+        start, end string
+    }{
+        {"calendar", "2025-06-30", 2025, "2025-01-01", "2025-12-31"},
+        {"us-federal", "2024-10-01", 2025, "2024-10-01", "2025-09-30"},
+        {"us-federal", "2024-09-30", 2024, "2023-10-01", "2024-09-30"},
+        {"uk-tax", "2025-04-05", 2024, "2024-04-06", "2025-04-05"},
+        {"uk-tax", "2025-04-06", 2025, "2025-04-06", "2026-04-05"},
+        {"japan", "2026-03-31", 2025, "2025-04-01", "2026-03-31"},
+        {"australia", "2025-07-01", 2026, "2025-07-01", "2026-06-30"},
+        // NRF: fiscal 2023 had 53 weeks, fiscal 2024 ended on 1 February 2025
+        {"retail-454", "2024-02-03", 2023, "2023-01-29", "2024-02-03"},
+        {"retail-454", "2025-02-01", 2024, "2024-02-04", "2025-02-01"},
+    }
+    for _, c := range cases {
+        cal := fiscalCalendars[c.calendar]
+        d, _ := time.Parse("2006-01-02", c.date)
+        label := cal.yearOf(d)
+        y := cal.yearSpan(label)
+        if label != c.label || y.Start != c.start || y.End != c.end {
+            t.Errorf("%s %s: FY%d %s..%s, want FY%d %s..%s", c.calendar, c.date, label, y.Start, y.End, c.label, c.start, c.end)
+        }
+    }
+
+    retail := fiscalCalendars["retail-454"]
+    if y := retail.yearSpan(2023); y.Weeks != 53 {
+        t.Errorf("retail FY2023 weeks = %d", y.Weeks)
+    }
+    p := retail.periods(2023)
+    if p[0].Weeks != 4 || p[1].Weeks != 5 || p[2].Weeks != 4 || p[11].Weeks != 5 || p[11].End != "2024-02-03" {
+        t.Errorf("retail FY2023 periods = %+v", p)
+    }
+    q := retail.quarters(2024)
+    if q[0].Start != "2024-02-04" || q[0].End != "2024-05-04" || q[0].Weeks != 13 || q[3].End != "2025-02-01" {
+        t.Errorf("retail FY2024 quarters = %+v", q)
+    }
+
+    // A 4-4-5 calendar ending on the last Saturday of December
+    c445 := &fiscalCalendar{Name: "c445", StartMonth: 1, Pattern: fiscal445}
+    if err := c445.validate(); err != nil {
+        t.Fatal(err)
+    }
+    if y := c445.yearSpan(2025); y.Start != "2024-12-29" || y.End != "2025-12-27" {
+        t.Errorf("4-4-5 FY2025 = %+v", y)
+    }
+}
+
+func TestFiscalCalendarFile(t *testing.T) {
+    t.Cleanup(func() { _ = useFiscalCalendars(nil, "") })
+    dir := t.TempDir()
+    write := func(name, data string) string {
+        p := filepath.Join(dir, name)
+        if err := os.WriteFile(p, []byte(data), 0o600); err != nil {
+            t.Fatal(err)
+        }
+        return p
+    }
+
+    path := write("ok.yaml", "- name: acme\n  start_month: 2\n  pattern: 4-4-5\n  week_ends_on: sun\n- name: calendar\n  start_month: 3\n")
+    list, err := loadFiscalCalendars(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := useFiscalCalendars(list, "acme"); err != nil {
+        t.Fatal(err)
+    }
+    if defaultFiscalCalendar != "acme" || fiscalCalendars["acme"].WeekEndsOn != "sunday" || fiscalCalendars["calendar"].StartMonth != 3 || fiscalCalendars["us-federal"] == nil {
+        t.Errorf("calendars = %v, default %s", fiscalCalendarNames(fiscalCalendars), defaultFiscalCalendar)
+    }
+    if err := useFiscalCalendars(nil, "missing"); err == nil || defaultFiscalCalendar != "acme" {
+        t.Errorf("unknown default: %v", err)
+    }
+
+    for name, data := range map[string]string{
+        "month.yaml":   "- name: x\n  start_month: 13\n",
+        "pattern.yaml": "- name: x\n  start_month: 1\n  pattern: 4-4-4\n",
+        "week.yaml":    "- name: x\n  start_month: 1\n  week_ends_on: friday\n",
+        "dup.yaml":     "- name: x\n  start_month: 1\n- name: x\n  start_month: 2\n",
+        "map.yaml":     "x: 1\n",
+    } {
+        if _, err := loadFiscalCalendars(write(name, data)); err == nil || !strings.Contains(err.Error(), name) {
+            t.Errorf("%s: %v", name, err)
+        }
+    }
+}
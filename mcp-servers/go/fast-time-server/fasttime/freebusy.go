@@ -0,0 +1,436 @@
+// -*- coding: utf-8 -*-
+// freebusy.go - CalDAV and Google Calendar free/busy sources
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// The JSON file given with -free-busy-calendars names calendars whose busy
+// times get_free_busy and meeting_overlap_windows may read:
+//
+//   {"calendars": [
+//     {"name": "ada", "type": "caldav", "url": "https://dav.example.com/calendars/ada/work/",
+//      "username": "ada", "password": "${ADA_DAV_PASSWORD}"},
+//     {"name": "grace", "type": "google", "calendar_id": "grace@example.com",
+//      "client_id": "...", "client_secret": "...", "refresh_token": "${GRACE_REFRESH_TOKEN}"}
+//   ]}
+//
+// A CalDAV calendar is asked with a free-busy-query REPORT (RFC 4791) and
+// answers with a VFREEBUSY, read with the parser of parse_ical. A Google
+// calendar is asked through the Calendar API freeBusy endpoint, with a fixed
+// "token" or an access token refreshed from an OAuth refresh token.
+// Credentials written as ${VAR} are read from the environment, so the file
+// itself need not hold secrets.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/url"
+    "os"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Free/busy defaults and limits
+const (
+    defaultFreeBusyTimeout = 10 * time.Second
+    googleFreeBusyURL      = "https://www.googleapis.com/calendar/v3/freeBusy"
+    googleTokenURL         = "https://oauth2.googleapis.com/token"
+)
+
+// caldavFreeBusyQuery is the body of the free-busy-query REPORT
+const caldavFreeBusyQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:free-busy-query xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <C:time-range start="%s" end="%s"/>
+</C:free-busy-query>
+`
+
+// freeBusyCalendar is a calendar declared in the -free-busy-calendars file
+type freeBusyCalendar struct {
+    Name    string `json:"name"`
+    Type    string `json:"type"` // caldav or google
+    URL     string `json:"url"`
+    Timeout string `json:"timeout"`
+
+    // CalDAV basic authentication
+    Username string `json:"username"`
+    Password string `json:"password"`
+
+    // Google: a fixed access token, or an OAuth client and refresh token
+    CalendarID   string `json:"calendar_id"`
+    Token        string `json:"token"`
+    ClientID     string `json:"client_id"`
+    ClientSecret string `json:"client_secret"`
+    RefreshToken string `json:"refresh_token"`
+    TokenURL     string `json:"token_url"`
+
+    timeout time.Duration
+
+    mu      sync.Mutex // guards access and expires
+    access  string
+    expires time.Time
+}
+
+// busyPeriod is a span a calendar reports as taken
+type busyPeriod struct {
+    interval
+    status string // busy, tentative or unavailable
+}
+
+// freeBusyCalendars are the configured calendars; nil without
+// -free-busy-calendars
+var freeBusyCalendars []*freeBusyCalendar
+
+// loadFreeBusyCalendars reads the calendars declared in path
+func loadFreeBusyCalendars(path string) ([]*freeBusyCalendar, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("read free/busy calendars file: %w", err)
+    }
+    var doc struct {
+        Calendars []*freeBusyCalendar `json:"calendars"`
+    }
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("free/busy calendars file %s: %w", path, err)
+    }
+    if len(doc.Calendars) == 0 {
+        return nil, fmt.Errorf("free/busy calendars file %s: no calendars", path)
+    }
+    names := make(map[string]bool, len(doc.Calendars))
+    for i, c := range doc.Calendars {
+        if c == nil || c.Name == "" {
+            return nil, fmt.Errorf("free/busy calendars file %s: calendar %d has no name", path, i+1)
+        }
+        if names[c.Name] {
+            return nil, fmt.Errorf("free/busy calendars file %s: duplicate calendar %q", path, c.Name)
+        }
+        names[c.Name] = true
+        if err := c.check(); err != nil {
+            return nil, fmt.Errorf("free/busy calendars file %s: calendar %q: %v", path, c.Name, err)
+        }
+    }
+    return doc.Calendars, nil
+}
+
+// check validates c, fills in defaults and reads ${VAR} credentials
+func (c *freeBusyCalendar) check() error {
+    for _, s := range []*string{&c.Username, &c.Password, &c.Token, &c.ClientID, &c.ClientSecret, &c.RefreshToken} {
+        *s = os.ExpandEnv(*s)
+    }
+    c.Type = strings.ToLower(c.Type)
+    switch c.Type {
+    case "caldav":
+        if c.URL == "" {
+            return fmt.Errorf("a caldav calendar needs a url")
+        }
+        if (c.Username == "") != (c.Password == "") {
+            return fmt.Errorf("give both username and password, or neither")
+        }
+    case "google":
+        if c.CalendarID == "" {
+            return fmt.Errorf("a google calendar needs a calendar_id")
+        }
+        if c.URL == "" {
+            c.URL = googleFreeBusyURL
+        }
+        if c.TokenURL == "" {
+            c.TokenURL = googleTokenURL
+        }
+        if c.Token == "" && (c.ClientID == "" || c.ClientSecret == "" || c.RefreshToken == "") {
+            return fmt.Errorf("a google calendar needs a token, or client_id, client_secret and refresh_token")
+        }
+    default:
+        return fmt.Errorf("unknown type %q (use caldav or google)", c.Type)
+    }
+    for _, raw := range []string{c.URL, c.TokenURL} {
+        if u, err := url.Parse(raw); raw != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+            return fmt.Errorf("invalid URL %q", raw)
+        }
+    }
+
+    c.timeout = defaultFreeBusyTimeout
+    if c.Timeout != "" {
+        var err error
+        if c.timeout, err = time.ParseDuration(c.Timeout); err != nil || c.timeout <= 0 {
+            return fmt.Errorf("invalid timeout %q", c.Timeout)
+        }
+    }
+    return nil
+}
+
+// busy returns the busy periods of c that overlap [from, to), clipped to it
+func (c *freeBusyCalendar) busy(ctx context.Context, from, to time.Time) ([]busyPeriod, error) {
+    var list []busyPeriod
+    var err error
+    if c.Type == "google" {
+        list, err = c.googleBusy(ctx, from, to)
+    } else {
+        list, err = c.caldavBusy(ctx, from, to)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    clipped := list[:0]
+    for _, b := range list {
+        if b.start.Before(from) {
+            b.start = from
+        }
+        if b.end.After(to) {
+            b.end = to
+        }
+        if b.start.Before(b.end) {
+            clipped = append(clipped, b)
+        }
+    }
+    sort.SliceStable(clipped, func(i, j int) bool { return clipped[i].start.Before(clipped[j].start) })
+    return clipped, nil
+}
+
+// call sends a request for c through the shared outbound client and
+// returns the body of a 200 answer; cache allows answers to be reused for
+// -outbound-cache-ttl. The calendar's backend breaker, not the client's
+// per-host one, decides whether it is called at all
+func (c *freeBusyCalendar) call(ctx context.Context, method, target string, header http.Header, body []byte, cache bool) ([]byte, error) {
+    resp, err := outbound.do(ctx, outboundRequest{
+        feature: "free-busy", method: method, url: target, header: header, body: body,
+        timeout: c.timeout, cache: cache, noBreaker: true,
+    })
+    if err != nil {
+        return nil, err
+    }
+    if resp.status != http.StatusOK {
+        if err := resp.err(); err != nil {
+            return nil, err
+        }
+        return nil, fmt.Errorf("HTTP %d", resp.status)
+    }
+    return resp.body, nil
+}
+
+// caldavBusy sends a free-busy-query REPORT
+func (c *freeBusyCalendar) caldavBusy(ctx context.Context, from, to time.Time) ([]busyPeriod, error) {
+    body := fmt.Sprintf(caldavFreeBusyQuery, from.UTC().Format(icalUTCLayout), to.UTC().Format(icalUTCLayout))
+    header := http.Header{}
+    header.Set("Content-Type", "application/xml; charset=utf-8")
+    header.Set("Depth", "1")
+    if c.Username != "" {
+        header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password)))
+    }
+    data, err := c.call(ctx, "REPORT", c.URL, header, []byte(body), true)
+    if err != nil {
+        return nil, err
+    }
+    cal, err := parseICalendar(string(data))
+    if err != nil {
+        return nil, fmt.Errorf("invalid free/busy answer: %v", err)
+    }
+    return parseVFreeBusy(cal)
+}
+
+// freeBusyStatus maps FBTYPE values to the reported status; FREE is skipped
+var freeBusyStatus = map[string]string{
+    "BUSY": "busy", "BUSY-TENTATIVE": "tentative", "BUSY-UNAVAILABLE": "unavailable",
+}
+
+// parseVFreeBusy reads the FREEBUSY periods of the VFREEBUSY components of
+// cal; periods are start/end or start/duration in UTC
+func parseVFreeBusy(cal *icalComponent) ([]busyPeriod, error) {
+    var out []busyPeriod
+    for _, fb := range cal.children {
+        if fb.name != "VFREEBUSY" {
+            continue
+        }
+        for i := range fb.props {
+            p := &fb.props[i]
+            if p.name != "FREEBUSY" {
+                continue
+            }
+            fbtype := strings.ToUpper(p.params["FBTYPE"])
+            if fbtype == "" {
+                fbtype = "BUSY"
+            }
+            status, ok := freeBusyStatus[fbtype]
+            if !ok {
+                continue // FREE or an extension
+            }
+            for _, period := range strings.Split(p.value, ",") {
+                s, e, _ := strings.Cut(strings.TrimSpace(period), "/")
+                start, err := parseICalDateTime(p, s)
+                if err != nil {
+                    return nil, err
+                }
+                b := busyPeriod{interval: interval{start: start.wall}, status: status}
+                if strings.HasPrefix(strings.ToUpper(e), "P") {
+                    pd, err := parseDurationFlexible(e)
+                    if err != nil {
+                        return nil, fmt.Errorf("invalid FREEBUSY duration %q", e)
+                    }
+                    b.end = b.start.Add(pd.d)
+                } else {
+                    end, err := parseICalDateTime(p, e)
+                    if err != nil {
+                        return nil, err
+                    }
+                    b.end = end.wall
+                }
+                out = append(out, b)
+            }
+        }
+    }
+    return out, nil
+}
+
+// googleBusy calls the Calendar API freeBusy endpoint
+func (c *freeBusyCalendar) googleBusy(ctx context.Context, from, to time.Time) ([]busyPeriod, error) {
+    token, err := c.accessToken(ctx)
+    if err != nil {
+        return nil, err
+    }
+    body, _ := json.Marshal(map[string]interface{}{
+        "timeMin": from.UTC().Format(time.RFC3339),
+        "timeMax": to.UTC().Format(time.RFC3339),
+        "items":   []map[string]string{{"id": c.CalendarID}},
+    })
+    header := http.Header{}
+    header.Set("Content-Type", "application/json")
+    header.Set("Authorization", "Bearer "+token)
+    data, err := c.call(ctx, http.MethodPost, c.URL, header, body, true)
+    if err != nil {
+        return nil, err
+    }
+
+    var doc struct {
+        Calendars map[string]struct {
+            Busy []struct {
+                Start time.Time `json:"start"`
+                End   time.Time `json:"end"`
+            } `json:"busy"`
+            Errors []struct {
+                Reason string `json:"reason"`
+            } `json:"errors"`
+        } `json:"calendars"`
+    }
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("invalid freeBusy answer: %v", err)
+    }
+    entry, ok := doc.Calendars[c.CalendarID]
+    if !ok {
+        return nil, fmt.Errorf("calendar %s missing from the freeBusy answer", c.CalendarID)
+    }
+    if len(entry.Errors) > 0 {
+        return nil, fmt.Errorf("calendar %s: %s", c.CalendarID, entry.Errors[0].Reason)
+    }
+    out := make([]busyPeriod, 0, len(entry.Busy))
+    for _, b := range entry.Busy {
+        out = append(out, busyPeriod{interval: interval{b.Start, b.End}, status: "busy"})
+    }
+    return out, nil
+}
+
+// accessToken returns the fixed token or a current one from the refresh
+// token, renewed a minute before it expires
+func (c *freeBusyCalendar) accessToken(ctx context.Context) (string, error) {
+    if c.Token != "" {
+        return c.Token, nil
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.access != "" && time.Now().Before(c.expires) {
+        return c.access, nil
+    }
+
+    form := url.Values{
+        "grant_type":    {"refresh_token"},
+        "client_id":     {c.ClientID},
+        "client_secret": {c.ClientSecret},
+        "refresh_token": {c.RefreshToken},
+    }
+    header := http.Header{}
+    header.Set("Content-Type", "application/x-www-form-urlencoded")
+    data, err := c.call(ctx, http.MethodPost, c.TokenURL, header, []byte(form.Encode()), false)
+    if err != nil {
+        return "", fmt.Errorf("refresh access token: %v", err)
+    }
+    var tok struct {
+        AccessToken string `json:"access_token"`
+        ExpiresIn   int    `json:"expires_in"`
+    }
+    if err := json.Unmarshal(data, &tok); err != nil || tok.AccessToken == "" {
+        return "", fmt.Errorf("refresh access token: no access_token in the answer")
+    }
+    c.access = tok.AccessToken
+    c.expires = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+    return c.access, nil
+}
+
+// calendarBusy is the answer of one calendar
+type calendarBusy struct {
+    cal  *freeBusyCalendar
+    busy []busyPeriod
+    err  error
+}
+
+// calendarBackend names the backend of c in backends.go
+func calendarBackend(c *freeBusyCalendar) string { return "calendar:" + c.Name }
+
+// fetchBusy asks the calendars in parallel; a calendar that is down is
+// not asked
+func fetchBusy(ctx context.Context, cals []*freeBusyCalendar, from, to time.Time) []calendarBusy {
+    out := make([]calendarBusy, len(cals))
+    var wg sync.WaitGroup
+    for i, c := range cals {
+        wg.Add(1)
+        go func(i int, c *freeBusyCalendar) {
+            defer wg.Done()
+            if err := backends.allow(calendarBackend(c)); err != nil {
+                out[i] = calendarBusy{cal: c, err: err}
+                return
+            }
+            busy, err := c.busy(ctx, from, to)
+            if ctx.Err() == nil {
+                backends.report(calendarBackend(c), err)
+            }
+            out[i] = calendarBusy{cal: c, busy: busy, err: err}
+        }(i, c)
+    }
+    wg.Wait()
+    return out
+}
+
+// lookupFreeBusyCalendars resolves a comma-separated list of calendar
+// names; empty means all of them
+func lookupFreeBusyCalendars(list string) ([]*freeBusyCalendar, error) {
+    if len(freeBusyCalendars) == 0 {
+        return nil, fmt.Errorf("no free/busy calendars are configured (start the server with -free-busy-calendars)")
+    }
+    if strings.TrimSpace(list) == "" {
+        return freeBusyCalendars, nil
+    }
+    var out []*freeBusyCalendar
+    for _, name := range strings.Split(list, ",") {
+        name = strings.TrimSpace(name)
+        found := false
+        for _, c := range freeBusyCalendars {
+            if c.Name == name {
+                out = append(out, c)
+                found = true
+                break
+            }
+        }
+        if !found {
+            known := make([]string, len(freeBusyCalendars))
+            for i, c := range freeBusyCalendars {
+                known[i] = c.Name
+            }
+            return nil, fmt.Errorf("unknown calendar %q (configured: %s)", name, strings.Join(known, ", "))
+        }
+    }
+    return out, nil
+}
@@ -0,0 +1,162 @@
+// -*- coding: utf-8 -*-
+// freebusy_test.go - tests for the CalDAV and Google free/busy sources
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// writeFreeBusy writes a -free-busy-calendars file and returns its path
+func writeFreeBusy(t *testing.T, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "calendars.json")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+// caldavServer answers free-busy-query REPORTs with fixed FREEBUSY lines
+func caldavServer(t *testing.T, lines string) *httptest.Server {
+    t.Helper()
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        if user, pass, _ := r.BasicAuth(); user != "ada" || pass != "s3cret" {
+            w.WriteHeader(http.StatusUnauthorized)
+            return
+        }
+        if r.Method != "REPORT" || r.Header.Get("Depth") != "1" || !strings.Contains(string(body), `<C:time-range start="`) {
+            t.Errorf("request %s %s:\n%s", r.Method, r.Header.Get("Depth"), body)
+        }
+        w.Header().Set("Content-Type", "text/calendar")
+        io.WriteString(w, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VFREEBUSY\r\nDTSTART:20250310T000000Z\r\nDTEND:20250312T000000Z\r\n"+lines+"END:VFREEBUSY\r\nEND:VCALENDAR\r\n")
+    }))
+    t.Cleanup(srv.Close)
+    return srv
+}
+
+func TestLoadFreeBusyCalendars(t *testing.T) {
+    t.Setenv("TEST_DAV_PASSWORD", "s3cret")
+    cals, err := loadFreeBusyCalendars(writeFreeBusy(t, `{"calendars": [
+        {"name": "ada", "type": "CalDAV", "url": "https://dav.example.com/ada/", "username": "ada", "password": "${TEST_DAV_PASSWORD}", "timeout": "3s"},
+        {"name": "grace", "type": "google", "calendar_id": "grace@example.com", "token": "t"}
+    ]}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if cals[0].Type != "caldav" || cals[0].Password != "s3cret" || cals[0].timeout != 3*time.Second ||
+        cals[1].URL != googleFreeBusyURL || cals[1].timeout != defaultFreeBusyTimeout {
+        t.Errorf("calendars = %+v / %+v", cals[0], cals[1])
+    }
+
+    for _, bad := range []string{
+        `{"calendars": []}`,
+        `{"calendars": [{"type": "caldav", "url": "https://dav.example.com/"}]}`,
+        `{"calendars": [{"name": "a", "type": "caldav", "url": "https://x/"}, {"name": "a", "type": "caldav", "url": "https://x/"}]}`,
+        `{"calendars": [{"name": "a", "type": "exchange"}]}`,
+        `{"calendars": [{"name": "a", "type": "caldav"}]}`,
+        `{"calendars": [{"name": "a", "type": "caldav", "url": "ftp://x/"}]}`,
+        `{"calendars": [{"name": "a", "type": "caldav", "url": "https://x/", "username": "ada"}]}`,
+        `{"calendars": [{"name": "a", "type": "google"}]}`,
+        `{"calendars": [{"name": "a", "type": "google", "calendar_id": "x", "client_id": "c"}]}`,
+        `{"calendars": [{"name": "a", "type": "caldav", "url": "https://x/", "timeout": "soon"}]}`,
+        `not json`,
+    } {
+        if _, err := loadFreeBusyCalendars(writeFreeBusy(t, bad)); err == nil {
+            t.Errorf("loadFreeBusyCalendars(%s) should fail", bad)
+        }
+    }
+}
+
+func TestCalDAVBusy(t *testing.T) {
+    srv := caldavServer(t, "FREEBUSY;FBTYPE=BUSY:20250310T140000Z/20250310T150000Z,20250311T090000Z/PT30M\r\n"+
+        "FREEBUSY;FBTYPE=FREE:20250310T160000Z/20250310T170000Z\r\n"+
+        "FREEBUSY;FBTYPE=BUSY-TENTATIVE:20250309T230000Z/20250310T010000Z\r\n")
+    c := &freeBusyCalendar{Name: "ada", Type: "caldav", URL: srv.URL, Username: "ada", Password: "s3cret"}
+    if err := c.check(); err != nil {
+        t.Fatal(err)
+    }
+    from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+    busy, err := c.busy(context.Background(), from, from.Add(48*time.Hour))
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Sorted, FREE left out, the tentative period clipped to the window
+    if len(busy) != 3 || !busy[0].start.Equal(from) || busy[0].status != "tentative" ||
+        busy[1].end.Sub(busy[1].start) != time.Hour || busy[2].end.Sub(busy[2].start) != 30*time.Minute {
+        t.Errorf("busy = %+v", busy)
+    }
+
+    c.Password = "wrong"
+    if _, err := c.busy(context.Background(), from, from.Add(48*time.Hour)); err == nil || !strings.Contains(err.Error(), "401") {
+        t.Errorf("bad credentials: %v", err)
+    }
+}
+
+func TestGoogleBusy(t *testing.T) {
+    var refreshes atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/token":
+            _ = r.ParseForm()
+            if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+                w.WriteHeader(http.StatusBadRequest)
+                return
+            }
+            refreshes.Add(1)
+            io.WriteString(w, `{"access_token": "access-1", "expires_in": 3600, "token_type": "Bearer"}`)
+        case "/freeBusy":
+            if r.Header.Get("Authorization") != "Bearer access-1" {
+                w.WriteHeader(http.StatusUnauthorized)
+                return
+            }
+            var body struct {
+                TimeMin string              `json:"timeMin"`
+                Items   []map[string]string `json:"items"`
+            }
+            _ = json.NewDecoder(r.Body).Decode(&body)
+            if body.TimeMin != "2025-03-10T00:00:00Z" || len(body.Items) != 1 {
+                t.Errorf("freeBusy request = %+v", body)
+            }
+            io.WriteString(w, `{"kind": "calendar#freeBusy", "calendars": {
+                "grace@example.com": {"busy": [{"start": "2025-03-10T10:00:00-04:00", "end": "2025-03-10T11:00:00-04:00"}]},
+                "missing@example.com": {"errors": [{"domain": "global", "reason": "notFound"}]}}}`)
+        }
+    }))
+    defer srv.Close()
+
+    c := &freeBusyCalendar{Name: "grace", Type: "google", CalendarID: "grace@example.com", URL: srv.URL + "/freeBusy",
+        ClientID: "id", ClientSecret: "secret", RefreshToken: "refresh", TokenURL: srv.URL + "/token"}
+    if err := c.check(); err != nil {
+        t.Fatal(err)
+    }
+    from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+    for i := 0; i < 2; i++ {
+        busy, err := c.busy(context.Background(), from, from.Add(24*time.Hour))
+        if err != nil || len(busy) != 1 || !busy[0].start.Equal(time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)) {
+            t.Fatalf("busy = %+v, %v", busy, err)
+        }
+    }
+    if n := refreshes.Load(); n != 1 {
+        t.Errorf("access token refreshed %d times, want 1", n)
+    }
+
+    c.CalendarID = "missing@example.com"
+    if _, err := c.busy(context.Background(), from, from.Add(24*time.Hour)); err == nil || !strings.Contains(err.Error(), "notFound") {
+        t.Errorf("calendar error = %v", err)
+    }
+}
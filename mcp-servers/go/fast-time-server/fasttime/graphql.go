@@ -0,0 +1,830 @@
+// -*- coding: utf-8 -*-
+// graphql.go - the /graphql endpoint
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// With -graphql the dual and rest listeners serve /graphql, a read-only
+// GraphQL API over the time, conversion, timezone and holiday data of the
+// REST API. A dashboard can fetch everything it shows in one round trip:
+//
+//   { utc: time(timezone: "UTC") { time }
+//     office: times(timezones: ["Europe/Berlin", "Asia/Tokyo"]) {
+//       timezone time utcOffset nextTransition { time utcOffset } }
+//     holidays(calendar: "NYSE") { date name } }
+//
+// All times in one query are taken from the same instant. Queries arrive as
+// POST {"query", "variables", "operationName"} (or application/graphql) or as
+// GET ?query=; a GET without a query answers the schema in SDL. Only queries
+// are supported, and introspection is limited to __typename.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "mime"
+    "net/http"
+    "slices"
+    "sort"
+    "strings"
+    "time"
+)
+
+// GraphQL endpoint limits
+const (
+    graphqlPath           = "/graphql"
+    graphqlMaxZones       = 100  // timezones of one times() field
+    graphqlMaxTransitions = 10   // transitions of one Timezone
+    graphqlMaxFields      = 2000 // fields resolved by one query
+)
+
+// graphqlRequest is the body of a POST /graphql
+type graphqlRequest struct {
+    Query         string                 `json:"query"`
+    OperationName string                 `json:"operationName"`
+    Variables     map[string]interface{} `json:"variables"`
+}
+
+/* ------------------------------------------------------------------ */
+/*                                schema                              */
+/* ------------------------------------------------------------------ */
+
+// gqlResolver computes a field from its parent value and arguments
+type gqlResolver func(e *gqlExec, parent interface{}, args map[string]interface{}) (interface{}, error)
+
+// gqlArgDef is an argument of a field; def is its default, if any
+type gqlArgDef struct {
+    name, typ string
+    def       interface{}
+}
+
+// gqlFieldDef is a field of an object type
+type gqlFieldDef struct {
+    name, typ, doc string
+    args           []gqlArgDef
+    resolve        gqlResolver
+}
+
+// gqlType is an object type
+type gqlType struct {
+    name, doc string
+    fields    []gqlFieldDef
+}
+
+// field returns the field called name, or nil
+func (t *gqlType) field(name string) *gqlFieldDef {
+    for i := range t.fields {
+        if t.fields[i].name == name {
+            return &t.fields[i]
+        }
+    }
+    return nil
+}
+
+// gqlSchema lists the object types, Query first
+var gqlSchema = graphqlSchemaTypes()
+
+// gqlLookup returns the object type called name, or nil for scalars
+func gqlLookup(name string) *gqlType {
+    for _, t := range gqlSchema {
+        if t.name == name {
+            return t
+        }
+    }
+    return nil
+}
+
+// gqlNamedType strips list and non-null wrappers from typ
+func gqlNamedType(typ string) string {
+    return strings.Trim(typ, "[]!")
+}
+
+// timeField returns a resolver computing a field of a Time
+func timeField(f func(t time.Time) interface{}) gqlResolver {
+    return func(_ *gqlExec, parent interface{}, _ map[string]interface{}) (interface{}, error) {
+        return f(parent.(time.Time)), nil
+    }
+}
+
+// graphqlSchemaTypes builds the schema served at /graphql
+func graphqlSchemaTypes() []*gqlType {
+    query := &gqlType{name: "Query", fields: []gqlFieldDef{
+        {name: "time", typ: "Time", doc: "The current time in a timezone (default: the X-Default-Timezone header or -default-timezone)",
+            args: []gqlArgDef{{name: "timezone", typ: "String"}}, resolve: resolveGQLTime},
+        {name: "times", typ: "[Time!]", doc: fmt.Sprintf("The current time in up to %d timezones", graphqlMaxZones),
+            args: []gqlArgDef{{name: "timezones", typ: "[String!]!"}}, resolve: resolveGQLTimes},
+        {name: "convert", typ: "Conversion", doc: "Convert a time (RFC 3339, or local to from) to another timezone",
+            args: []gqlArgDef{{name: "time", typ: "String!"}, {name: "from", typ: "String"}, {name: "to", typ: "String!"}}, resolve: resolveGQLConvert},
+        {name: "timezone", typ: "Timezone", doc: "A timezone and its upcoming offset changes",
+            args: []gqlArgDef{{name: "name", typ: "String!"}}, resolve: resolveGQLTimezone},
+        {name: "holidays", typ: "[Holiday!]", doc: "Holidays of a market (NYSE, LSE, ...) or custom calendar between two dates (default: the next 365 days)",
+            args: []gqlArgDef{{name: "calendar", typ: "String!"}, {name: "from", typ: "String"}, {name: "to", typ: "String"}}, resolve: resolveGQLHolidays},
+        {name: "calendars", typ: "[String!]", doc: "The market codes and custom holiday calendars", resolve: resolveGQLCalendars},
+    }}
+
+    timeType := &gqlType{name: "Time", doc: "An instant in a timezone", fields: []gqlFieldDef{
+        {name: "timezone", typ: "String!", resolve: timeField(func(t time.Time) interface{} { return t.Location().String() })},
+        {name: "time", typ: "String!", doc: "RFC 3339 with the zone's offset", resolve: timeField(func(t time.Time) interface{} { return t.Format(time.RFC3339) })},
+        {name: "utc", typ: "String!", resolve: timeField(func(t time.Time) interface{} { return t.UTC().Format(time.RFC3339) })},
+        {name: "unix", typ: "Float!", doc: "Seconds since the epoch", resolve: timeField(func(t time.Time) interface{} { return t.Unix() })},
+        {name: "utcOffset", typ: "String!", resolve: timeField(func(t time.Time) interface{} { _, o := t.Zone(); return formatOffset(o) })},
+        {name: "offsetSeconds", typ: "Int!", resolve: timeField(func(t time.Time) interface{} { _, o := t.Zone(); return o })},
+        {name: "abbreviation", typ: "String!", resolve: timeField(func(t time.Time) interface{} { a, _ := t.Zone(); return a })},
+        {name: "isDst", typ: "Boolean!", resolve: timeField(func(t time.Time) interface{} { return t.IsDST() })},
+        {name: "weekday", typ: "String!", resolve: timeField(func(t time.Time) interface{} { return t.Weekday().String() })},
+        {name: "nextTransition", typ: "Time", doc: "The first instant after this one with a different offset or abbreviation",
+            resolve: timeField(func(t time.Time) interface{} {
+                if next, ok := nextTransition(t); ok {
+                    return next
+                }
+                return nil
+            })},
+    }}
+
+    conversion := &gqlType{name: "Conversion", fields: []gqlFieldDef{
+        {name: "from", typ: "Time!", resolve: func(_ *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return p.([2]time.Time)[0], nil
+        }},
+        {name: "to", typ: "Time!", resolve: func(_ *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return p.([2]time.Time)[1], nil
+        }},
+    }}
+
+    timezone := &gqlType{name: "Timezone", fields: []gqlFieldDef{
+        {name: "name", typ: "String!", resolve: func(_ *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return p.(*time.Location).String(), nil
+        }},
+        {name: "now", typ: "Time!", resolve: func(e *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return e.now.In(p.(*time.Location)), nil
+        }},
+        {name: "observesDst", typ: "Boolean!", resolve: func(e *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return observesDST(e.now.In(p.(*time.Location))), nil
+        }},
+        {name: "transitions", typ: "[Time!]!", doc: fmt.Sprintf("The next offset changes, at most %d", graphqlMaxTransitions),
+            args: []gqlArgDef{{name: "count", typ: "Int", def: int64(2)}}, resolve: resolveGQLTransitions},
+    }}
+
+    holidayType := &gqlType{name: "Holiday", fields: []gqlFieldDef{
+        {name: "date", typ: "String!", resolve: func(_ *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return p.(holiday).Date, nil
+        }},
+        {name: "name", typ: "String!", resolve: func(_ *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            return p.(holiday).Name, nil
+        }},
+        {name: "weekday", typ: "String!", resolve: func(_ *gqlExec, p interface{}, _ map[string]interface{}) (interface{}, error) {
+            day, _ := time.Parse("2006-01-02", p.(holiday).Date)
+            return day.Weekday().String(), nil
+        }},
+    }}
+
+    return []*gqlType{query, timeType, conversion, timezone, holidayType}
+}
+
+// graphqlSDL renders the schema in the GraphQL schema definition language
+func graphqlSDL() string {
+    var b strings.Builder
+    for i, t := range gqlSchema {
+        if i > 0 {
+            b.WriteString("\n")
+        }
+        if t.doc != "" {
+            fmt.Fprintf(&b, "%q\n", t.doc)
+        }
+        fmt.Fprintf(&b, "type %s {\n", t.name)
+        for _, f := range t.fields {
+            if f.doc != "" {
+                fmt.Fprintf(&b, "  %q\n", f.doc)
+            }
+            b.WriteString("  " + f.name)
+            if len(f.args) > 0 {
+                args := make([]string, len(f.args))
+                for j, a := range f.args {
+                    args[j] = a.name + ": " + a.typ
+                    if a.def != nil {
+                        args[j] += fmt.Sprintf(" = %v", a.def)
+                    }
+                }
+                b.WriteString("(" + strings.Join(args, ", ") + ")")
+            }
+            b.WriteString(": " + f.typ + "\n")
+        }
+        b.WriteString("}\n")
+    }
+    return b.String()
+}
+
+/* ------------------------------------------------------------------ */
+/*                               resolvers                            */
+/* ------------------------------------------------------------------ */
+
+// zone returns the timezone argument v, defaulting to the request's
+// default timezone
+func (e *gqlExec) zone(v interface{}) string {
+    if tz, _ := v.(string); tz != "" {
+        return tz
+    }
+    return e.defaultTZ
+}
+
+// The Query resolvers call the shared time operations of service.go
+
+func resolveGQLTime(e *gqlExec, _ interface{}, args map[string]interface{}) (interface{}, error) {
+    t, err := timeIn(e.zone(args["timezone"]), e.now)
+    if err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
+func resolveGQLTimes(e *gqlExec, _ interface{}, args map[string]interface{}) (interface{}, error) {
+    zones := args["timezones"].([]interface{})
+    if len(zones) > graphqlMaxZones {
+        return nil, fmt.Errorf("at most %d timezones per field", graphqlMaxZones)
+    }
+    out := make([]interface{}, len(zones))
+    for i, tz := range zones {
+        t, err := timeIn(e.zone(tz), e.now)
+        if err != nil {
+            return nil, err
+        }
+        out[i] = t
+    }
+    return out, nil
+}
+
+func resolveGQLConvert(e *gqlExec, _ interface{}, args map[string]interface{}) (interface{}, error) {
+    src, dst, err := convertTime(args["time"].(string), e.zone(args["from"]), args["to"].(string))
+    if err != nil {
+        return nil, err
+    }
+    return [2]time.Time{src, dst}, nil
+}
+
+func resolveGQLTimezone(e *gqlExec, _ interface{}, args map[string]interface{}) (interface{}, error) {
+    name := args["name"].(string)
+    if strings.TrimSpace(name) == "" {
+        return nil, errors.New("name must not be empty")
+    }
+    loc, err := loadLocation(name)
+    if err != nil {
+        return nil, fieldError("name", err)
+    }
+    return loc, nil
+}
+
+func resolveGQLTransitions(e *gqlExec, parent interface{}, args map[string]interface{}) (interface{}, error) {
+    count := args["count"].(int64)
+    if count < 0 || count > graphqlMaxTransitions {
+        return nil, fmt.Errorf("count must be between 0 and %d", graphqlMaxTransitions)
+    }
+    out := []interface{}{}
+    t := e.now.In(parent.(*time.Location))
+    for len(out) < int(count) {
+        next, ok := nextTransition(t)
+        if !ok {
+            break
+        }
+        out = append(out, next)
+        t = next
+    }
+    return out, nil
+}
+
+func resolveGQLHolidays(e *gqlExec, _ interface{}, args map[string]interface{}) (interface{}, error) {
+    name := args["calendar"].(string)
+    var dates map[string]string
+    if ex, ok := exchanges[strings.ToUpper(strings.TrimSpace(name))]; ok {
+        dates = ex.holidays
+    } else {
+        var err error
+        if dates, err = holidayDates(name); err != nil {
+            return nil, err
+        }
+    }
+
+    from := e.now.UTC().Format("2006-01-02")
+    if s, _ := args["from"].(string); s != "" {
+        from = s
+    }
+    start, err := time.Parse("2006-01-02", from)
+    if err != nil {
+        return nil, fmt.Errorf("invalid from date %q: use YYYY-MM-DD", from)
+    }
+    to := start.AddDate(0, 0, 365).Format("2006-01-02")
+    if s, _ := args["to"].(string); s != "" {
+        if _, err := time.Parse("2006-01-02", s); err != nil {
+            return nil, fmt.Errorf("invalid to date %q: use YYYY-MM-DD", s)
+        }
+        to = s
+    }
+
+    keys := make([]string, 0, len(dates))
+    for date := range dates {
+        if date >= from && date <= to {
+            keys = append(keys, date)
+        }
+    }
+    sort.Strings(keys)
+    out := make([]interface{}, len(keys))
+    for i, date := range keys {
+        out[i] = holiday{Date: date, Name: dates[date]}
+    }
+    return out, nil
+}
+
+func resolveGQLCalendars(*gqlExec, interface{}, map[string]interface{}) (interface{}, error) {
+    var out []interface{}
+    for _, code := range exchangeCodes() {
+        out = append(out, code)
+    }
+    custom, err := store.ListCalendars()
+    if err != nil {
+        return nil, err
+    }
+    for _, c := range custom {
+        out = append(out, c.Name)
+    }
+    return out, nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                               execution                            */
+/* ------------------------------------------------------------------ */
+
+// gqlExec is the state of one request
+type gqlExec struct {
+    ctx       context.Context
+    doc       *gqlDocument
+    op        *gqlOperation
+    vars      map[string]interface{}
+    defaultTZ string
+    now       time.Time // every time in a query is taken at this instant
+    errors    []*gqlError
+    resolved  int
+    checked   map[string]bool // fragments already validated
+}
+
+// fail records a field error with the error code of errors.go under
+// extensions, as REST and MCP report it
+func (e *gqlExec) fail(s *gqlSelection, path []interface{}, err error) {
+    e.errors = append(e.errors, &gqlError{
+        Message:    err.Error(),
+        Locations:  []gqlLocation{s.loc},
+        Path:       path,
+        Extensions: map[string]interface{}{"code": classifyError(http.StatusBadRequest, err).Code},
+    })
+}
+
+// executeGraphQL runs a request and returns the response body and the
+// HTTP status: 400 when the request failed before execution
+func executeGraphQL(ctx context.Context, req graphqlRequest, defaultTZ string) (orderedObject, int) {
+    reject := func(errs ...*gqlError) (orderedObject, int) {
+        return orderedObject{{"errors", errs}}, http.StatusBadRequest
+    }
+    if strings.TrimSpace(req.Query) == "" {
+        return reject(&gqlError{Message: "Must provide query string."})
+    }
+    doc, err := parseGraphQL(req.Query)
+    if err != nil {
+        return reject(err.(*gqlError))
+    }
+
+    var op *gqlOperation
+    for i := range doc.ops {
+        if req.OperationName == "" && len(doc.ops) > 1 {
+            return reject(&gqlError{Message: "Must provide operation name if query contains multiple operations."})
+        }
+        if req.OperationName == "" || doc.ops[i].name == req.OperationName {
+            op = &doc.ops[i]
+            break
+        }
+    }
+    switch {
+    case op == nil:
+        return reject(&gqlError{Message: fmt.Sprintf("Unknown operation named %q.", req.OperationName)})
+    case op.kind != "query":
+        return reject(&gqlError{Message: fmt.Sprintf("Only queries are supported, not %s.", op.kind), Locations: []gqlLocation{op.loc}})
+    }
+
+    e := &gqlExec{ctx: ctx, doc: doc, op: op, defaultTZ: defaultTZ, now: currentTime(), checked: map[string]bool{}}
+    if errs := e.validate(gqlSchema[0], op.sels, nil); len(errs) > 0 {
+        return reject(errs...)
+    }
+    if errs := e.coerceVariables(op, req.Variables); len(errs) > 0 {
+        return reject(errs...)
+    }
+
+    data, _ := e.object(gqlSchema[0], nil, op.sels, nil)
+    out := orderedObject{}
+    if len(e.errors) > 0 {
+        out = append(out, orderedField{"errors", e.errors})
+    }
+    if data == nil {
+        return append(out, orderedField{"data", nil}), http.StatusOK
+    }
+    return append(out, orderedField{"data", data}), http.StatusOK
+}
+
+// validate checks sels against type t before anything runs
+func (e *gqlExec) validate(t *gqlType, sels []gqlSelection, spreading []string) []*gqlError {
+    var errs []*gqlError
+    at := func(s *gqlSelection, format string, args ...interface{}) {
+        errs = append(errs, &gqlError{Message: fmt.Sprintf(format, args...), Locations: []gqlLocation{s.loc}})
+    }
+    for i := range sels {
+        s := &sels[i]
+        if s.fragment {
+            on, inner, stack := s.typeCond, s.sels, spreading
+            if s.name != "" {
+                frag, ok := e.doc.fragments[s.name]
+                if !ok {
+                    at(s, "Unknown fragment %q.", s.name)
+                    continue
+                }
+                if slices.Contains(spreading, s.name) {
+                    at(s, "Cannot spread fragment %q within itself.", s.name)
+                    return errs
+                }
+                on, inner, stack = frag.on, frag.sels, append(spreading[:len(spreading):len(spreading)], s.name)
+            }
+            if on != "" && on != t.name {
+                if gqlLookup(on) == nil {
+                    at(s, "Unknown type %q.", on)
+                } else {
+                    at(s, "Fragment cannot be spread here as objects of type %q can never be of type %q.", t.name, on)
+                }
+                continue
+            }
+            // A fragment applies to one type only, so checking it once is enough
+            if s.name != "" && e.checked[s.name] {
+                continue
+            }
+            errs = append(errs, e.validate(t, inner, stack)...)
+            if s.name != "" {
+                e.checked[s.name] = true
+            }
+            continue
+        }
+
+        if s.name == "__typename" {
+            continue
+        }
+        def := t.field(s.name)
+        if def == nil {
+            at(s, "Cannot query field %q on type %q.", s.name, t.name)
+            continue
+        }
+        for name := range s.args {
+            if !def.hasArg(name) {
+                at(s, "Unknown argument %q on field \"%s.%s\".", name, t.name, s.name)
+            }
+        }
+        for _, a := range def.args {
+            if _, ok := s.args[a.name]; !ok && strings.HasSuffix(a.typ, "!") && a.def == nil {
+                at(s, "Field %q argument %q of type %q is required, but it was not provided.", s.name, a.name, a.typ)
+            }
+        }
+        inner := gqlLookup(gqlNamedType(def.typ))
+        switch {
+        case inner != nil && len(s.sels) == 0:
+            at(s, "Field %q of type %q must have a selection of subfields.", s.name, def.typ)
+        case inner == nil && len(s.sels) > 0:
+            at(s, "Field %q must not have a selection since type %q has no subfields.", s.name, def.typ)
+        case inner != nil:
+            errs = append(errs, e.validate(inner, s.sels, spreading)...)
+        }
+    }
+    return errs
+}
+
+// hasArg reports whether the field takes an argument called name
+func (f *gqlFieldDef) hasArg(name string) bool {
+    for _, a := range f.args {
+        if a.name == name {
+            return true
+        }
+    }
+    return false
+}
+
+// coerceVariables checks the supplied variables against the operation's
+// definitions and stores them, with defaults, in e.vars
+func (e *gqlExec) coerceVariables(op *gqlOperation, supplied map[string]interface{}) []*gqlError {
+    var errs []*gqlError
+    e.vars = map[string]interface{}{}
+    for _, def := range op.vars {
+        raw, ok := supplied[def.name]
+        if !ok {
+            if !def.hasDef {
+                if strings.HasSuffix(def.typ, "!") {
+                    errs = append(errs, &gqlError{Message: fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", def.name, def.typ), Locations: []gqlLocation{def.loc}})
+                }
+                continue
+            }
+            raw = def.def
+        }
+        v, err := gqlCoerce(def.typ, raw)
+        if err != nil {
+            errs = append(errs, &gqlError{Message: fmt.Sprintf("Variable \"$%s\" got invalid value: %v", def.name, err), Locations: []gqlLocation{def.loc}})
+            continue
+        }
+        e.vars[def.name] = v
+    }
+    return errs
+}
+
+// gqlCoerce converts an input value (a literal or decoded JSON) to the Go
+// value of typ: string, int64, float64, bool or []interface{}
+func gqlCoerce(typ string, v interface{}) (interface{}, error) {
+    base, nonNull := strings.CutSuffix(typ, "!")
+    if v == nil {
+        if nonNull {
+            return nil, fmt.Errorf("expected a non-null %s", typ)
+        }
+        return nil, nil
+    }
+    if inner, ok := strings.CutPrefix(base, "["); ok {
+        inner = strings.TrimSuffix(inner, "]")
+        items, isList := v.([]interface{})
+        if !isList {
+            items = []interface{}{v}
+        }
+        out := make([]interface{}, len(items))
+        for i, item := range items {
+            c, err := gqlCoerce(inner, item)
+            if err != nil {
+                return nil, fmt.Errorf("at index %d: %v", i, err)
+            }
+            out[i] = c
+        }
+        return out, nil
+    }
+
+    switch base {
+    case "String":
+        if s, ok := v.(string); ok {
+            return s, nil
+        }
+    case "Boolean":
+        if b, ok := v.(bool); ok {
+            return b, nil
+        }
+    case "Int":
+        n, ok := v.(int64)
+        if f, isFloat := v.(float64); isFloat && f == math.Trunc(f) {
+            n, ok = int64(f), true
+        }
+        if ok && n >= math.MinInt32 && n <= math.MaxInt32 {
+            return n, nil
+        }
+    case "Float":
+        switch n := v.(type) {
+        case int64:
+            return float64(n), nil
+        case float64:
+            return n, nil
+        }
+    }
+    return nil, fmt.Errorf("expected %s, found %v", base, gqlLiteral(v))
+}
+
+// gqlLiteral formats an input value for error messages
+func gqlLiteral(v interface{}) string {
+    switch val := v.(type) {
+    case string:
+        return fmt.Sprintf("%q", val)
+    case gqlEnum:
+        return string(val)
+    case gqlVariable:
+        return "$" + string(val)
+    }
+    b, _ := json.Marshal(v)
+    return string(b)
+}
+
+// resolveVars replaces variable references in a literal; a variable that
+// was not given reads as absent
+func (e *gqlExec) resolveVars(v interface{}) (interface{}, bool) {
+    switch val := v.(type) {
+    case gqlVariable:
+        out, ok := e.vars[string(val)]
+        return out, ok
+    case []interface{}:
+        out := make([]interface{}, len(val))
+        for i, item := range val {
+            out[i], _ = e.resolveVars(item)
+        }
+        return out, true
+    }
+    return v, true
+}
+
+// args coerces the arguments of a field
+func (e *gqlExec) args(def *gqlFieldDef, s *gqlSelection) (map[string]interface{}, error) {
+    out := make(map[string]interface{}, len(def.args))
+    for _, a := range def.args {
+        raw, ok := s.args[a.name]
+        if ok {
+            if _, isVar := raw.(gqlVariable); isVar && !e.isDefined(raw.(gqlVariable)) {
+                return nil, fmt.Errorf("variable \"$%s\" is not defined", raw)
+            }
+            raw, ok = e.resolveVars(raw)
+        }
+        if !ok {
+            raw = a.def
+        }
+        v, err := gqlCoerce(a.typ, raw)
+        if err != nil {
+            return nil, fmt.Errorf("argument %q: %v", a.name, err)
+        }
+        out[a.name] = v
+    }
+    return out, nil
+}
+
+// isDefined reports whether the operation declares the variable
+func (e *gqlExec) isDefined(name gqlVariable) bool {
+    for _, def := range e.op.vars {
+        if def.name == string(name) {
+            return true
+        }
+    }
+    return false
+}
+
+// included applies @skip and @include
+func (e *gqlExec) included(dirs []gqlDirective) bool {
+    for _, d := range dirs {
+        raw, _ := e.resolveVars(d.args["if"])
+        cond, _ := raw.(bool)
+        if (d.name == "skip" && cond) || (d.name == "include" && !cond) {
+            return false
+        }
+    }
+    return true
+}
+
+// gqlGroup is the selections sharing one response key
+type gqlGroup struct {
+    key  string
+    sels []*gqlSelection
+}
+
+// collect flattens fragments into the fields of sels, grouped by response
+// key in query order
+func (e *gqlExec) collect(t *gqlType, sels []gqlSelection, groups []gqlGroup, visited map[string]bool) []gqlGroup {
+    for i := range sels {
+        s := &sels[i]
+        if !e.included(s.directives) {
+            continue
+        }
+        switch {
+        case !s.fragment:
+            key, found := s.key(), false
+            for j := range groups {
+                if groups[j].key == key {
+                    groups[j].sels, found = append(groups[j].sels, s), true
+                    break
+                }
+            }
+            if !found {
+                groups = append(groups, gqlGroup{key, []*gqlSelection{s}})
+            }
+        case s.name != "":
+            if visited[s.name] {
+                continue
+            }
+            visited[s.name] = true
+            groups = e.collect(t, e.doc.fragments[s.name].sels, groups, visited)
+        default:
+            groups = e.collect(t, s.sels, groups, visited)
+        }
+    }
+    return groups
+}
+
+// object resolves the selected fields of parent; false means a non-null
+// field was null, so the object itself must be null
+func (e *gqlExec) object(t *gqlType, parent interface{}, sels []gqlSelection, path []interface{}) (orderedObject, bool) {
+    out := orderedObject{}
+    for _, g := range e.collect(t, sels, nil, map[string]bool{}) {
+        s := g.sels[0]
+        fieldPath := append(path[:len(path):len(path)], g.key)
+        if s.name == "__typename" {
+            out = append(out, orderedField{g.key, t.name})
+            continue
+        }
+        def := t.field(s.name)
+
+        // Past the limit fields are null; only the first one says why
+        var v interface{}
+        err := e.ctx.Err()
+        if e.resolved++; err == nil && e.resolved > graphqlMaxFields {
+            if e.resolved == graphqlMaxFields+1 {
+                err = fmt.Errorf("query resolves more than %d fields", graphqlMaxFields)
+            }
+        } else if err == nil {
+            var args map[string]interface{}
+            if args, err = e.args(def, s); err == nil {
+                v, err = def.resolve(e, parent, args)
+            }
+        }
+        if err != nil {
+            e.fail(s, fieldPath, err)
+            v = nil
+        }
+
+        var sub []gqlSelection
+        for _, gs := range g.sels {
+            sub = append(sub, gs.sels...)
+        }
+        c, ok := e.complete(def.typ, v, sub, fieldPath)
+        if !ok {
+            return nil, false
+        }
+        out = append(out, orderedField{g.key, c})
+    }
+    return out, true
+}
+
+// complete shapes a resolved value to its type; false means a null reached
+// a non-null type
+func (e *gqlExec) complete(typ string, v interface{}, sels []gqlSelection, path []interface{}) (interface{}, bool) {
+    base, nonNull := strings.CutSuffix(typ, "!")
+    if v == nil {
+        return nil, !nonNull
+    }
+    if inner, ok := strings.CutPrefix(base, "["); ok {
+        inner = strings.TrimSuffix(inner, "]")
+        items := v.([]interface{})
+        out := make([]interface{}, len(items))
+        for i, item := range items {
+            c, ok := e.complete(inner, item, sels, append(path[:len(path):len(path)], i))
+            if !ok {
+                return nil, !nonNull
+            }
+            out[i] = c
+        }
+        return out, true
+    }
+    if t := gqlLookup(base); t != nil {
+        obj, ok := e.object(t, v, sels, path)
+        if !ok {
+            return nil, !nonNull
+        }
+        return obj, true
+    }
+    return v, true
+}
+
+/* ------------------------------------------------------------------ */
+/*                                 HTTP                               */
+/* ------------------------------------------------------------------ */
+
+// handleGraphQL handles GET and POST /graphql
+func handleGraphQL(w http.ResponseWriter, r *http.Request) {
+    var req graphqlRequest
+    switch r.Method {
+    case http.MethodGet:
+        q := r.URL.Query()
+        if q.Get("query") == "" {
+            w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+            io.WriteString(w, graphqlSDL())
+            return
+        }
+        req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+        if v := q.Get("variables"); v != "" {
+            if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+                writeJSON(w, http.StatusBadRequest, orderedObject{{"errors", []*gqlError{{Message: "variables must be a JSON object"}}}})
+                return
+            }
+        }
+
+    case http.MethodPost:
+        mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+        if mediaType == "application/graphql" {
+            body, err := io.ReadAll(r.Body)
+            if err != nil {
+                writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+                return
+            }
+            req.Query = string(body)
+        } else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            writeJSON(w, http.StatusBadRequest, orderedObject{{"errors", []*gqlError{{Message: "body must be a JSON object with a query"}}}})
+            return
+        }
+
+    default:
+        w.Header().Set("Allow", "GET, POST")
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+
+    resp, code := executeGraphQL(r.Context(), req, requestDefaultTimezone(r))
+    writeJSON(w, code, resp)
+}
@@ -0,0 +1,689 @@
+// -*- coding: utf-8 -*-
+// graphql_parse.go - GraphQL query documents
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file parses the executable subset of the GraphQL language used by
+// /graphql (graphql.go): operations with variables, fields with aliases and
+// arguments, fragments, inline fragments and directives. Values are parsed
+// into Go values: string, int64, float64, bool, nil, gqlEnum, gqlVariable,
+// []interface{} and map[string]interface{}. Block strings and type system
+// definitions are not supported.
+
+package fasttime
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// graphqlMaxDepth bounds the nesting of selections and values
+const graphqlMaxDepth = 16
+
+// gqlLocation is a line and column in the query, both from 1
+type gqlLocation struct {
+    Line   int `json:"line"`
+    Column int `json:"column"`
+}
+
+// gqlError is one entry of the "errors" list of a response
+type gqlError struct {
+    Message   string        `json:"message"`
+    Locations []gqlLocation `json:"locations,omitempty"`
+    Path      []interface{} `json:"path,omitempty"`
+
+    Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *gqlError) Error() string { return e.Message }
+
+// gqlEnum is an enum value literal
+type gqlEnum string
+
+// gqlVariable is a $variable reference
+type gqlVariable string
+
+// gqlDirective is @name(args) on a selection
+type gqlDirective struct {
+    name string
+    args map[string]interface{}
+}
+
+// gqlSelection is a field, a fragment spread (fragment with name) or an
+// inline fragment (fragment without name)
+type gqlSelection struct {
+    alias, name string
+    args        map[string]interface{}
+    directives  []gqlDirective
+    sels        []gqlSelection
+    fragment    bool
+    typeCond    string // of an inline fragment
+    loc         gqlLocation
+}
+
+// key is the response key of a field
+func (s *gqlSelection) key() string {
+    if s.alias != "" {
+        return s.alias
+    }
+    return s.name
+}
+
+// gqlVarDef declares an operation variable
+type gqlVarDef struct {
+    name, typ string
+    def       interface{}
+    hasDef    bool
+    loc       gqlLocation
+}
+
+// gqlOperation is a query, mutation or subscription
+type gqlOperation struct {
+    kind, name string
+    vars       []gqlVarDef
+    sels       []gqlSelection
+    loc        gqlLocation
+}
+
+// gqlFragment is a named fragment definition
+type gqlFragment struct {
+    on   string
+    sels []gqlSelection
+    loc  gqlLocation
+}
+
+// gqlDocument is a parsed query document
+type gqlDocument struct {
+    ops       []gqlOperation
+    fragments map[string]gqlFragment
+}
+
+/* ------------------------------------------------------------------ */
+/*                                lexer                               */
+/* ------------------------------------------------------------------ */
+
+// gqlToken kinds
+const (
+    gqlEOF = iota
+    gqlPunct
+    gqlName
+    gqlInt
+    gqlFloat
+    gqlString
+)
+
+// gqlToken is one lexical token; text holds the decoded value of strings
+type gqlToken struct {
+    kind int
+    text string
+    loc  gqlLocation
+}
+
+// describe names t for error messages
+func (t gqlToken) describe() string {
+    switch t.kind {
+    case gqlEOF:
+        return "<EOF>"
+    case gqlString:
+        return strconv.Quote(t.text)
+    case gqlName, gqlInt, gqlFloat:
+        return t.text
+    }
+    return fmt.Sprintf("%q", t.text)
+}
+
+// syntaxError returns a gqlError at loc
+func syntaxError(loc gqlLocation, format string, args ...interface{}) *gqlError {
+    return &gqlError{Message: "Syntax Error: " + fmt.Sprintf(format, args...), Locations: []gqlLocation{loc}}
+}
+
+func isNameStart(c byte) bool {
+    return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// gqlTokenize splits src into tokens; commas and comments are dropped
+func gqlTokenize(src string) ([]gqlToken, error) {
+    var toks []gqlToken
+    line, lineStart := 1, 0
+    for i := 0; i < len(src); {
+        c := src[i]
+        loc := gqlLocation{line, i - lineStart + 1}
+        switch {
+        case c == '\n':
+            i++
+            line, lineStart = line+1, i
+        case c == ' ' || c == '\t' || c == '\r' || c == ',':
+            i++
+        case strings.HasPrefix(src[i:], "\uFEFF"): // byte order mark
+            i += len("\uFEFF")
+        case c == '#':
+            for i < len(src) && src[i] != '\n' {
+                i++
+            }
+        case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+            toks = append(toks, gqlToken{gqlPunct, string(c), loc})
+            i++
+        case c == '.':
+            if !strings.HasPrefix(src[i:], "...") {
+                return nil, syntaxError(loc, "unexpected %q", ".")
+            }
+            toks = append(toks, gqlToken{gqlPunct, "...", loc})
+            i += 3
+        case isNameStart(c):
+            j := i + 1
+            for j < len(src) && (isNameStart(src[j]) || isDigit(src[j])) {
+                j++
+            }
+            toks = append(toks, gqlToken{gqlName, src[i:j], loc})
+            i = j
+        case c == '-' || isDigit(c):
+            tok, n, err := lexNumber(src[i:], loc)
+            if err != nil {
+                return nil, err
+            }
+            toks = append(toks, tok)
+            i += n
+        case c == '"':
+            if strings.HasPrefix(src[i:], `"""`) {
+                return nil, syntaxError(loc, "block strings are not supported")
+            }
+            s, n, err := lexString(src[i:], loc)
+            if err != nil {
+                return nil, err
+            }
+            toks = append(toks, gqlToken{gqlString, s, loc})
+            i += n
+        default:
+            r, _ := utf8.DecodeRuneInString(src[i:])
+            return nil, syntaxError(loc, "unexpected character %q", r)
+        }
+    }
+    return append(toks, gqlToken{kind: gqlEOF, loc: gqlLocation{line, len(src) - lineStart + 1}}), nil
+}
+
+// lexNumber reads an IntValue or FloatValue at the start of s
+func lexNumber(s string, loc gqlLocation) (gqlToken, int, error) {
+    i, kind := 0, gqlInt
+    if s[i] == '-' {
+        i++
+    }
+    digits := func() bool {
+        start := i
+        for i < len(s) && isDigit(s[i]) {
+            i++
+        }
+        return i > start
+    }
+    if !digits() {
+        return gqlToken{}, 0, syntaxError(loc, "invalid number %q", s[:i])
+    }
+    if i < len(s) && s[i] == '.' {
+        i, kind = i+1, gqlFloat
+        if !digits() {
+            return gqlToken{}, 0, syntaxError(loc, "invalid number %q", s[:i])
+        }
+    }
+    if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+        i, kind = i+1, gqlFloat
+        if i < len(s) && (s[i] == '+' || s[i] == '-') {
+            i++
+        }
+        if !digits() {
+            return gqlToken{}, 0, syntaxError(loc, "invalid number %q", s[:i])
+        }
+    }
+    if i < len(s) && (isNameStart(s[i]) || s[i] == '.') {
+        return gqlToken{}, 0, syntaxError(loc, "invalid number %q", s[:i+1])
+    }
+    return gqlToken{kind, s[:i], loc}, i, nil
+}
+
+// lexString decodes the quoted string at the start of s
+func lexString(s string, loc gqlLocation) (string, int, error) {
+    var b strings.Builder
+    for i := 1; i < len(s); {
+        switch c := s[i]; {
+        case c == '"':
+            return b.String(), i + 1, nil
+        case c == '\n' || c == '\r':
+            return "", 0, syntaxError(loc, "unterminated string")
+        case c != '\\':
+            b.WriteByte(c)
+            i++
+        case i+1 >= len(s):
+            return "", 0, syntaxError(loc, "unterminated string")
+        default:
+            esc := s[i+1]
+            i += 2
+            switch esc {
+            case '"', '\\', '/':
+                b.WriteByte(esc)
+            case 'b':
+                b.WriteByte('\b')
+            case 'f':
+                b.WriteByte('\f')
+            case 'n':
+                b.WriteByte('\n')
+            case 'r':
+                b.WriteByte('\r')
+            case 't':
+                b.WriteByte('\t')
+            case 'u':
+                if i+4 > len(s) {
+                    return "", 0, syntaxError(loc, "invalid unicode escape")
+                }
+                r, err := strconv.ParseUint(s[i:i+4], 16, 32)
+                if err != nil {
+                    return "", 0, syntaxError(loc, "invalid unicode escape %q", `\u`+s[i:i+4])
+                }
+                b.WriteRune(rune(r))
+                i += 4
+            default:
+                return "", 0, syntaxError(loc, "invalid escape %q", `\`+string(esc))
+            }
+        }
+    }
+    return "", 0, syntaxError(loc, "unterminated string")
+}
+
+/* ------------------------------------------------------------------ */
+/*                                parser                              */
+/* ------------------------------------------------------------------ */
+
+// gqlParser reads a document from its tokens
+type gqlParser struct {
+    toks  []gqlToken
+    i     int
+    depth int
+}
+
+// parseGraphQL parses a query document
+func parseGraphQL(src string) (*gqlDocument, error) {
+    toks, err := gqlTokenize(src)
+    if err != nil {
+        return nil, err
+    }
+    p := &gqlParser{toks: toks}
+    doc := &gqlDocument{fragments: map[string]gqlFragment{}}
+    for p.peek().kind != gqlEOF {
+        t := p.peek()
+        switch {
+        case p.is("{"), p.is("query"), p.is("mutation"), p.is("subscription"):
+            op, err := p.operation()
+            if err != nil {
+                return nil, err
+            }
+            doc.ops = append(doc.ops, op)
+        case p.is("fragment"):
+            name, frag, err := p.fragment()
+            if err != nil {
+                return nil, err
+            }
+            if _, dup := doc.fragments[name]; dup {
+                return nil, &gqlError{Message: fmt.Sprintf("There can be only one fragment named %q.", name), Locations: []gqlLocation{t.loc}}
+            }
+            doc.fragments[name] = frag
+        default:
+            return nil, syntaxError(t.loc, "unexpected %s", t.describe())
+        }
+    }
+    if len(doc.ops) == 0 {
+        return nil, &gqlError{Message: "The document contains no operation."}
+    }
+    return doc, nil
+}
+
+func (p *gqlParser) peek() gqlToken { return p.toks[p.i] }
+
+func (p *gqlParser) next() gqlToken {
+    t := p.toks[p.i]
+    if t.kind != gqlEOF {
+        p.i++
+    }
+    return t
+}
+
+// is reports whether the next token is the punctuator or name text
+func (p *gqlParser) is(text string) bool {
+    t := p.peek()
+    return (t.kind == gqlPunct || t.kind == gqlName) && t.text == text
+}
+
+// expect consumes the punctuator or name text
+func (p *gqlParser) expect(text string) error {
+    if !p.is(text) {
+        t := p.peek()
+        return syntaxError(t.loc, "expected %q, found %s", text, t.describe())
+    }
+    p.next()
+    return nil
+}
+
+// name consumes a name
+func (p *gqlParser) name() (gqlToken, error) {
+    t := p.next()
+    if t.kind != gqlName {
+        return t, syntaxError(t.loc, "expected a name, found %s", t.describe())
+    }
+    return t, nil
+}
+
+// nest guards against deeply nested input
+func (p *gqlParser) nest() error {
+    if p.depth++; p.depth > graphqlMaxDepth {
+        return syntaxError(p.peek().loc, "nesting exceeds %d levels", graphqlMaxDepth)
+    }
+    return nil
+}
+
+func (p *gqlParser) operation() (gqlOperation, error) {
+    op := gqlOperation{kind: "query", loc: p.peek().loc}
+    if !p.is("{") {
+        op.kind = p.next().text
+        if p.peek().kind == gqlName {
+            op.name = p.next().text
+        }
+        if p.is("(") {
+            p.next()
+            for !p.is(")") {
+                def, err := p.varDef()
+                if err != nil {
+                    return op, err
+                }
+                op.vars = append(op.vars, def)
+            }
+            p.next()
+        }
+        if _, err := p.directives(false); err != nil {
+            return op, err
+        }
+    }
+    sels, err := p.selectionSet()
+    op.sels = sels
+    return op, err
+}
+
+func (p *gqlParser) varDef() (gqlVarDef, error) {
+    def := gqlVarDef{loc: p.peek().loc}
+    if err := p.expect("$"); err != nil {
+        return def, err
+    }
+    name, err := p.name()
+    if err != nil {
+        return def, err
+    }
+    def.name = name.text
+    if err := p.expect(":"); err != nil {
+        return def, err
+    }
+    if def.typ, err = p.typeRef(); err != nil {
+        return def, err
+    }
+    if p.is("=") {
+        p.next()
+        def.hasDef = true
+        if def.def, err = p.value(true); err != nil {
+            return def, err
+        }
+    }
+    _, err = p.directives(true)
+    return def, err
+}
+
+// typeRef reads a type such as [String!]! as text
+func (p *gqlParser) typeRef() (string, error) {
+    var typ string
+    if p.is("[") {
+        if err := p.nest(); err != nil {
+            return "", err
+        }
+        p.next()
+        inner, err := p.typeRef()
+        if err != nil {
+            return "", err
+        }
+        if err := p.expect("]"); err != nil {
+            return "", err
+        }
+        p.depth--
+        typ = "[" + inner + "]"
+    } else {
+        name, err := p.name()
+        if err != nil {
+            return "", err
+        }
+        typ = name.text
+    }
+    if p.is("!") {
+        p.next()
+        typ += "!"
+    }
+    return typ, nil
+}
+
+func (p *gqlParser) fragment() (string, gqlFragment, error) {
+    frag := gqlFragment{loc: p.next().loc}
+    name, err := p.name()
+    if err != nil {
+        return "", frag, err
+    }
+    if name.text == "on" {
+        return "", frag, syntaxError(name.loc, "unexpected name \"on\"")
+    }
+    if err := p.expect("on"); err != nil {
+        return "", frag, err
+    }
+    on, err := p.name()
+    if err != nil {
+        return "", frag, err
+    }
+    frag.on = on.text
+    if _, err := p.directives(false); err != nil {
+        return "", frag, err
+    }
+    frag.sels, err = p.selectionSet()
+    return name.text, frag, err
+}
+
+func (p *gqlParser) selectionSet() ([]gqlSelection, error) {
+    if err := p.nest(); err != nil {
+        return nil, err
+    }
+    if err := p.expect("{"); err != nil {
+        return nil, err
+    }
+    var sels []gqlSelection
+    for !p.is("}") {
+        if p.peek().kind == gqlEOF {
+            return nil, syntaxError(p.peek().loc, "expected \"}\", found <EOF>")
+        }
+        s, err := p.selection()
+        if err != nil {
+            return nil, err
+        }
+        sels = append(sels, s)
+    }
+    if len(sels) == 0 {
+        return nil, syntaxError(p.peek().loc, "expected a selection, found \"}\"")
+    }
+    p.next()
+    p.depth--
+    return sels, nil
+}
+
+func (p *gqlParser) selection() (gqlSelection, error) {
+    s := gqlSelection{loc: p.peek().loc}
+    var err error
+    if p.is("...") {
+        p.next()
+        s.fragment = true
+        switch {
+        case p.is("on"):
+            p.next()
+            on, err := p.name()
+            if err != nil {
+                return s, err
+            }
+            s.typeCond = on.text
+        case p.peek().kind == gqlName:
+            s.name = p.next().text
+            s.directives, err = p.directives(false)
+            return s, err
+        }
+        if s.directives, err = p.directives(false); err != nil {
+            return s, err
+        }
+        s.sels, err = p.selectionSet()
+        return s, err
+    }
+
+    name, err := p.name()
+    if err != nil {
+        return s, err
+    }
+    s.name = name.text
+    if p.is(":") {
+        p.next()
+        if name, err = p.name(); err != nil {
+            return s, err
+        }
+        s.alias, s.name = s.name, name.text
+    }
+    if s.args, err = p.arguments(false); err != nil {
+        return s, err
+    }
+    if s.directives, err = p.directives(false); err != nil {
+        return s, err
+    }
+    if p.is("{") {
+        s.sels, err = p.selectionSet()
+    }
+    return s, err
+}
+
+// arguments reads an optional (name: value ...) list
+func (p *gqlParser) arguments(isConst bool) (map[string]interface{}, error) {
+    if !p.is("(") {
+        return nil, nil
+    }
+    p.next()
+    args := map[string]interface{}{}
+    for !p.is(")") {
+        name, err := p.name()
+        if err != nil {
+            return nil, err
+        }
+        if _, dup := args[name.text]; dup {
+            return nil, &gqlError{Message: fmt.Sprintf("There can be only one argument named %q.", name.text), Locations: []gqlLocation{name.loc}}
+        }
+        if err := p.expect(":"); err != nil {
+            return nil, err
+        }
+        if args[name.text], err = p.value(isConst); err != nil {
+            return nil, err
+        }
+    }
+    p.next()
+    return args, nil
+}
+
+func (p *gqlParser) directives(isConst bool) ([]gqlDirective, error) {
+    var out []gqlDirective
+    for p.is("@") {
+        p.next()
+        name, err := p.name()
+        if err != nil {
+            return nil, err
+        }
+        args, err := p.arguments(isConst)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, gqlDirective{name.text, args})
+    }
+    return out, nil
+}
+
+// value reads a value literal; variables are refused when isConst is set
+func (p *gqlParser) value(isConst bool) (interface{}, error) {
+    t := p.next()
+    switch t.kind {
+    case gqlString:
+        return t.text, nil
+    case gqlInt:
+        n, err := strconv.ParseInt(t.text, 10, 64)
+        if err != nil {
+            return nil, syntaxError(t.loc, "integer %s out of range", t.text)
+        }
+        return n, nil
+    case gqlFloat:
+        f, err := strconv.ParseFloat(t.text, 64)
+        if err != nil {
+            return nil, syntaxError(t.loc, "float %s out of range", t.text)
+        }
+        return f, nil
+    case gqlName:
+        switch t.text {
+        case "true":
+            return true, nil
+        case "false":
+            return false, nil
+        case "null":
+            return nil, nil
+        }
+        return gqlEnum(t.text), nil
+    }
+
+    switch t.text {
+    case "$":
+        if isConst {
+            return nil, syntaxError(t.loc, "unexpected variable in a constant value")
+        }
+        name, err := p.name()
+        return gqlVariable(name.text), err
+    case "[":
+        if err := p.nest(); err != nil {
+            return nil, err
+        }
+        list := []interface{}{}
+        for !p.is("]") {
+            if p.peek().kind == gqlEOF {
+                return nil, syntaxError(p.peek().loc, "expected \"]\", found <EOF>")
+            }
+            v, err := p.value(isConst)
+            if err != nil {
+                return nil, err
+            }
+            list = append(list, v)
+        }
+        p.next()
+        p.depth--
+        return list, nil
+    case "{":
+        if err := p.nest(); err != nil {
+            return nil, err
+        }
+        obj := map[string]interface{}{}
+        for !p.is("}") {
+            name, err := p.name()
+            if err != nil {
+                return nil, err
+            }
+            if err := p.expect(":"); err != nil {
+                return nil, err
+            }
+            if obj[name.text], err = p.value(isConst); err != nil {
+                return nil, err
+            }
+        }
+        p.next()
+        p.depth--
+        return obj, nil
+    }
+    return nil, syntaxError(t.loc, "expected a value, found %s", t.describe())
+}
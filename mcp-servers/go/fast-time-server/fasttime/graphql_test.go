@@ -0,0 +1,236 @@
+// -*- coding: utf-8 -*-
+// graphql_test.go - tests for the /graphql endpoint
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+// runGraphQL executes query at 2025-03-08T12:00:00Z and returns the JSON
+// response and status
+func runGraphQL(t *testing.T, query string, vars map[string]interface{}) (string, int) {
+    t.Helper()
+    defer clock.reset()
+    clock.set(time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC))
+    resp, code := executeGraphQL(context.Background(), graphqlRequest{Query: query, Variables: vars}, "UTC")
+    b, err := json.Marshal(resp)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return string(b), code
+}
+
+func TestParseGraphQL(t *testing.T) {
+    doc, err := parseGraphQL(`
+        # dashboard
+        query Board($zones: [String!]! = ["UTC"], $n: Int) {
+            now: time(timezone: "Europe/Berlin") { ...T @include(if: true) }
+            ... on Query { calendars }
+        }
+        fragment T on Time { time, utcOffset }`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    op := doc.ops[0]
+    if op.name != "Board" || len(op.vars) != 2 || op.vars[0].typ != "[String!]!" || !op.vars[0].hasDef || op.vars[1].typ != "Int" {
+        t.Errorf("operation = %+v", op)
+    }
+    if f := op.sels[0]; f.alias != "now" || f.name != "time" || f.args["timezone"] != "Europe/Berlin" || !f.sels[0].fragment || f.sels[0].name != "T" {
+        t.Errorf("field = %+v", f)
+    }
+    if frag := doc.fragments["T"]; frag.on != "Time" || len(frag.sels) != 2 {
+        t.Errorf("fragment = %+v", frag)
+    }
+
+    for _, tc := range []struct{ query, want string }{
+        {"{ time { time }", "1:16"},
+        {"{ time(timezone: ) { time } }", "1:18"},
+        {"{\n  time(timezone: \"UTC) }", "2:18"},
+        {"{ time { time } } %", "1:19"},
+        {"{ a(n: 1.) }", "1:8"},
+        {"{ a(n: $x) }", ""}, // variables are fine in arguments
+        {"query($x: Int = $y) { a }", "1:17"},
+        {"{ }", "1:3"},
+        {"fragment on on Time { a }", "1:10"},
+        {strings.Repeat("{ a ", graphqlMaxDepth+1) + strings.Repeat("}", graphqlMaxDepth+1), "1:"},
+    } {
+        _, err := parseGraphQL(tc.query)
+        switch {
+        case tc.want == "" && err != nil:
+            t.Errorf("%q: %v", tc.query, err)
+        case tc.want == "":
+        case err == nil:
+            t.Errorf("%q parsed", tc.query)
+        default:
+            loc := err.(*gqlError).Locations[0]
+            if got := fmt.Sprintf("%d:%d", loc.Line, loc.Column); !strings.HasPrefix(got, tc.want) {
+                t.Errorf("%q: error at %s, want %s (%v)", tc.query, got, tc.want, err)
+            }
+        }
+    }
+}
+
+func TestGraphQLDashboardQuery(t *testing.T) {
+    got, code := runGraphQL(t, `
+        query Board($zones: [String!]!) {
+            __typename
+            utc: time { time }
+            times(timezones: $zones) { ...Zone nextTransition { time utcOffset isDst } }
+        }
+        fragment Zone on Time { timezone time abbreviation }`,
+        map[string]interface{}{"zones": []interface{}{"America/New_York", "Asia/Tokyo"}})
+    want := `{"data":{"__typename":"Query","utc":{"time":"2025-03-08T12:00:00Z"},"times":[` +
+        `{"timezone":"America/New_York","time":"2025-03-08T07:00:00-05:00","abbreviation":"EST","nextTransition":{"time":"2025-03-09T03:00:00-04:00","utcOffset":"-04:00","isDst":true}},` +
+        `{"timezone":"Asia/Tokyo","time":"2025-03-08T21:00:00+09:00","abbreviation":"JST","nextTransition":null}]}}`
+    if code != http.StatusOK || got != want {
+        t.Errorf("got %d %s\nwant %s", code, got, want)
+    }
+
+    got, _ = runGraphQL(t, `{
+        convert(time: "2025-03-08 09:00:00", from: "America/New_York", to: "Europe/London") { from { utc } to { time weekday } }
+        timezone(name: "Europe/London") { name observesDst transitions(count: 2) { time } }
+    }`, nil)
+    want = `{"data":{"convert":{"from":{"utc":"2025-03-08T14:00:00Z"},"to":{"time":"2025-03-08T14:00:00Z","weekday":"Saturday"}},` +
+        `"timezone":{"name":"Europe/London","observesDst":true,"transitions":[{"time":"2025-03-30T02:00:00+01:00"},{"time":"2025-10-26T01:00:00Z"}]}}}`
+    if got != want {
+        t.Errorf("got  %s\nwant %s", got, want)
+    }
+}
+
+func TestGraphQLHolidays(t *testing.T) {
+    st := newMemoryStore()
+    useTestStore(t, st)
+    if err := st.PutCalendar(holidayCalendar{Name: "acme", Holidays: []holiday{{Date: "2025-03-14", Name: "Founders Day"}, {Date: "2026-03-14", Name: "Founders Day"}}}); err != nil {
+        t.Fatal(err)
+    }
+
+    got, _ := runGraphQL(t, `{
+        nyse: holidays(calendar: "nyse", from: "2025-12-01", to: "2025-12-31") { date name weekday }
+        acme: holidays(calendar: "acme") { date }
+        calendars
+    }`, nil)
+    want := `{"data":{"nyse":[{"date":"2025-12-25","name":"Christmas Day","weekday":"Thursday"}],"acme":[{"date":"2025-03-14"}],` +
+        `"calendars":["HKEX","LSE","NASDAQ","NYSE","TSE","acme"]}}`
+    if got != want {
+        t.Errorf("got  %s\nwant %s", got, want)
+    }
+}
+
+func TestGraphQLFieldErrors(t *testing.T) {
+    // A failing field is null with an error; its siblings still answer
+    got, code := runGraphQL(t, `{ ok: time { timezone } bad: time(timezone: "Mars/Base") { timezone } }`, nil)
+    if code != http.StatusOK || !strings.Contains(got, `"data":{"ok":{"timezone":"UTC"},"bad":null}`) ||
+        !strings.Contains(got, `"path":["bad"]`) || !strings.Contains(got, `invalid timezone \"Mars/Base\"`) {
+        t.Errorf("got %d %s", code, got)
+    }
+
+    // A null in a non-null list element nulls the list
+    got, _ = runGraphQL(t, `{ times(timezones: ["UTC", "Nowhere"]) { time } }`, nil)
+    if !strings.Contains(got, `"data":{"times":null}`) {
+        t.Errorf("got %s", got)
+    }
+
+    got, _ = runGraphQL(t, `{ timezone(name: "UTC") { transitions(count: 50) { time } } }`, nil)
+    if !strings.Contains(got, `"path":["timezone","transitions"]`) || !strings.Contains(got, `"data":{"timezone":null}`) {
+        t.Errorf("got %s", got)
+    }
+
+    // Past the field limit the rest is null with a single error
+    var b strings.Builder
+    b.WriteString("{ times(timezones: [")
+    b.WriteString(strings.Repeat(`"UTC" `, graphqlMaxZones))
+    b.WriteString("]) {")
+    for i := 0; i < graphqlMaxFields/graphqlMaxZones; i++ {
+        fmt.Fprintf(&b, " f%d: time", i)
+    }
+    b.WriteString(" } }")
+    got, _ = runGraphQL(t, b.String(), nil)
+    if strings.Count(got, "more than") != 1 || !strings.Contains(got, `"data":{"times":null}`) {
+        t.Errorf("field limit: %.300s", got)
+    }
+}
+
+func TestGraphQLRequestErrors(t *testing.T) {
+    for _, tc := range []struct {
+        query string
+        vars  map[string]interface{}
+        want  string
+    }{
+        {``, nil, "Must provide query string"},
+        {`{ time { nope } }`, nil, `Cannot query field \"nope\" on type \"Time\"`},
+        {`{ time }`, nil, "must have a selection of subfields"},
+        {`{ calendars { name } }`, nil, "must not have a selection"},
+        {`{ convert(to: "UTC") { to { time } } }`, nil, `argument \"time\" of type \"String!\" is required`},
+        {`{ time(zone: "UTC") { time } }`, nil, `Unknown argument \"zone\"`},
+        {`{ ...Missing }`, nil, `Unknown fragment \"Missing\"`},
+        {`{ ...A } fragment A on Query { ...B } fragment B on Query { ...A }`, nil, "within itself"},
+        {`{ ... on Time { time } }`, nil, "can never be of type"},
+        {`mutation { time { time } }`, nil, "Only queries are supported"},
+        {`query A { calendars } query B { calendars }`, nil, "Must provide operation name"},
+        {`query($z: String!) { time(timezone: $z) { time } }`, nil, `Variable \"$z\" of required type`},
+        {`query($n: Int) { timezone(name: "UTC") { transitions(count: $n) { time } } }`, map[string]interface{}{"n": "two"}, "expected Int"},
+    } {
+        got, code := runGraphQL(t, tc.query, tc.vars)
+        if code != http.StatusBadRequest || strings.Contains(got, `"data"`) || !strings.Contains(got, tc.want) {
+            t.Errorf("%q: got %d %s", tc.query, code, got)
+        }
+    }
+
+    // The same fragment spread twice is not a cycle
+    if got, code := runGraphQL(t, `{ a: time { ...T } b: time { ...T } } fragment T on Time { time }`, nil); code != http.StatusOK {
+        t.Errorf("repeated fragment: %d %s", code, got)
+    }
+}
+
+func TestGraphQLHTTP(t *testing.T) {
+    mux := listenerMux("rest", nil, &listenerConfig{graphql: true})
+    do := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(method, target, strings.NewReader(body))
+        if contentType != "" {
+            req.Header.Set("Content-Type", contentType)
+        }
+        req.Header.Set(defaultTZHeader, "Asia/Tokyo")
+        rec := httptest.NewRecorder()
+        mux.ServeHTTP(rec, req)
+        return rec
+    }
+
+    if rec := do(http.MethodGet, graphqlPath, "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "type Query {") ||
+        !strings.Contains(rec.Body.String(), "transitions(count: Int = 2): [Time!]!") {
+        t.Errorf("schema: %d %s", rec.Code, rec.Body)
+    }
+    rec := do(http.MethodPost, graphqlPath, "application/json", `{"query":"query($z: String) { time(timezone: $z) { timezone } }","variables":{"z":"Europe/Paris"}}`)
+    if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"timezone":"Europe/Paris"`) {
+        t.Errorf("POST: %d %s", rec.Code, rec.Body)
+    }
+    if rec := do(http.MethodPost, graphqlPath, "application/graphql", `{ time { timezone } }`); !strings.Contains(rec.Body.String(), `"timezone":"Asia/Tokyo"`) {
+        t.Errorf("application/graphql: %d %s", rec.Code, rec.Body)
+    }
+    if rec := do(http.MethodGet, graphqlPath+"?query=%7Bcalendars%7D", "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"NYSE"`) {
+        t.Errorf("GET query: %d %s", rec.Code, rec.Body)
+    }
+    if rec := do(http.MethodPost, graphqlPath, "application/json", `not json`); rec.Code != http.StatusBadRequest {
+        t.Errorf("bad body: %d", rec.Code)
+    }
+    if rec := do(http.MethodPut, graphqlPath, "", ""); rec.Code != http.StatusMethodNotAllowed {
+        t.Errorf("PUT: %d", rec.Code)
+    }
+
+    // Off unless asked for
+    rec = httptest.NewRecorder()
+    listenerMux("rest", nil, &listenerConfig{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, graphqlPath, nil))
+    if rec.Code != http.StatusNotFound {
+        t.Errorf("without -graphql: %d", rec.Code)
+    }
+}
@@ -0,0 +1,148 @@
+// -*- coding: utf-8 -*-
+// holidays.go - custom holiday calendars
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file manages operator-defined holiday calendars (company shutdown
+// days, regional bank holidays, ...) kept in the Store. Calendars are edited
+// through the admin API and referenced by name from is_business_hours, which
+// treats their dates as non-working days:
+//
+//   GET    /admin/holidays          list calendars
+//   GET    /admin/holidays/{name}   show one calendar
+//   PUT    /admin/holidays/{name}   replace a calendar {"holidays": [{"date": "2025-12-24", "name": "..."}]}
+//   DELETE /admin/holidays/{name}   delete a calendar
+
+package fasttime
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "time"
+)
+
+// maxHolidaysPerCalendar bounds the size of a single calendar
+const maxHolidaysPerCalendar = 1000
+
+// validateCalendar checks names and dates and sorts the holidays by date
+func validateCalendar(c *holidayCalendar) error {
+    if !aliasNamePattern.MatchString(c.Name) {
+        return fmt.Errorf("invalid calendar name %q: use up to 64 letters, digits, '.', '_' or '-'", c.Name)
+    }
+    if len(c.Holidays) > maxHolidaysPerCalendar {
+        return fmt.Errorf("a calendar holds at most %d holidays", maxHolidaysPerCalendar)
+    }
+    seen := make(map[string]bool, len(c.Holidays))
+    for _, h := range c.Holidays {
+        if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+            return fmt.Errorf("invalid holiday date %q: use YYYY-MM-DD", h.Date)
+        }
+        if seen[h.Date] {
+            return fmt.Errorf("duplicate holiday date %s", h.Date)
+        }
+        seen[h.Date] = true
+    }
+    sort.Slice(c.Holidays, func(i, j int) bool { return c.Holidays[i].Date < c.Holidays[j].Date })
+    return nil
+}
+
+// holidayDates loads a calendar as a date -> name map
+func holidayDates(name string) (map[string]string, error) {
+    c, err := store.GetCalendar(name)
+    if errors.Is(err, errNotFound) {
+        return nil, fmt.Errorf("unknown holiday calendar %q", name)
+    }
+    if err != nil {
+        return nil, err
+    }
+    dates := make(map[string]string, len(c.Holidays))
+    for _, h := range c.Holidays {
+        dates[h.Date] = h.Name
+    }
+    return dates, nil
+}
+
+// handleAdminHolidays handles GET /admin/holidays
+func handleAdminHolidays(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    calendars, err := store.ListCalendars()
+    if err != nil {
+        writeJSONError(w, http.StatusInternalServerError, err.Error())
+        return
+    }
+    if calendars == nil {
+        calendars = []holidayCalendar{}
+    }
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "calendars":  calendars,
+        "count":      len(calendars),
+        "persistent": store.Persistent(),
+    })
+}
+
+// handleAdminHolidayCalendar handles GET, PUT and DELETE /admin/holidays/{name}
+func handleAdminHolidayCalendar(w http.ResponseWriter, r *http.Request) {
+    name := strings.TrimPrefix(r.URL.Path, "/admin/holidays/")
+    if name == "" || strings.Contains(name, "/") {
+        writeJSONError(w, http.StatusNotFound, "Calendar not specified")
+        return
+    }
+
+    switch r.Method {
+    case http.MethodGet:
+        c, err := store.GetCalendar(name)
+        switch {
+        case errors.Is(err, errNotFound):
+            writeJSONError(w, http.StatusNotFound, "Unknown calendar: "+name)
+        case err != nil:
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+        default:
+            writeJSON(w, http.StatusOK, c)
+        }
+
+    case http.MethodPut:
+        var c holidayCalendar
+        if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+            return
+        }
+        c.Name = name
+        if c.Holidays == nil {
+            c.Holidays = []holiday{}
+        }
+        if err := validateCalendar(&c); err != nil {
+            writeJSONError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        c.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+        if err := store.PutCalendar(c); err != nil {
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+            return
+        }
+        logAt(logInfo, "admin: holiday calendar %s saved (%d dates)", name, len(c.Holidays))
+        writeJSON(w, http.StatusOK, c)
+
+    case http.MethodDelete:
+        err := store.DeleteCalendar(name)
+        switch {
+        case errors.Is(err, errNotFound):
+            writeJSONError(w, http.StatusNotFound, "Unknown calendar: "+name)
+        case err != nil:
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+        default:
+            logAt(logInfo, "admin: holiday calendar %s deleted", name)
+            w.WriteHeader(http.StatusNoContent)
+        }
+
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
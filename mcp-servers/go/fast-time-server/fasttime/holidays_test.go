@@ -0,0 +1,92 @@
+// -*- coding: utf-8 -*-
+// holidays_test.go - tests for custom holiday calendars
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestAdminHolidayCalendars(t *testing.T) {
+    useTestStore(t, newMemoryStore())
+    h := adminMiddleware(newBearerToken("admin-secret"), http.NotFoundHandler())
+    do := func(method, path, body string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(method, path, strings.NewReader(body))
+        req.Header.Set("Authorization", "Bearer admin-secret")
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        return w
+    }
+
+    body := `{"holidays":[{"date":"2025-12-26","name":"Boxing Day"},{"date":"2025-12-24","name":"Christmas Eve"}]}`
+    if w := do(http.MethodPut, "/admin/holidays/acme", body); w.Code != http.StatusOK {
+        t.Fatalf("PUT: %d %s", w.Code, w.Body)
+    }
+    if w := do(http.MethodPut, "/admin/holidays/acme", `{"holidays":[{"date":"24/12/2025"}]}`); w.Code != http.StatusBadRequest {
+        t.Errorf("PUT bad date: %d", w.Code)
+    }
+
+    w := do(http.MethodGet, "/admin/holidays/acme", "")
+    var c holidayCalendar
+    if err := json.Unmarshal(w.Body.Bytes(), &c); err != nil || len(c.Holidays) != 2 || c.Holidays[0].Date != "2025-12-24" {
+        t.Fatalf("GET = %+v (%v), want two holidays sorted by date", c, err)
+    }
+    if w := do(http.MethodGet, "/admin/holidays", ""); !strings.Contains(w.Body.String(), `"count":1`) {
+        t.Errorf("list: %s", w.Body)
+    }
+    if w := do(http.MethodDelete, "/admin/holidays/acme", ""); w.Code != http.StatusNoContent {
+        t.Errorf("DELETE: %d", w.Code)
+    }
+    if w := do(http.MethodGet, "/admin/holidays/acme", ""); w.Code != http.StatusNotFound {
+        t.Errorf("GET after delete: %d", w.Code)
+    }
+}
+
+func TestBusinessHoursWithHolidayCalendar(t *testing.T) {
+    useTestStore(t, newMemoryStore())
+    if err := store.PutCalendar(holidayCalendar{Name: "acme", Holidays: []holiday{
+        {Date: "2025-12-24", Name: "Christmas Eve"},
+        {Date: "2025-12-25", Name: "Christmas Day"},
+    }}); err != nil {
+        t.Fatal(err)
+    }
+
+    res, err := handleIsBusinessHours(context.Background(), testRequest("is_business_hours", map[string]any{
+        "country":          "US",
+        "time":             "2025-12-24T10:00:00",
+        "holiday_calendar": "acme",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("is_business_hours failed: %v", err)
+    }
+    var out struct {
+        Open      bool   `json:"is_business_hours"`
+        Reason    string `json:"reason"`
+        Holiday   string `json:"holiday"`
+        NextStart string `json:"next_start"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    if out.Open || out.Reason != "holiday" || out.Holiday != "Christmas Eve" {
+        t.Errorf("got %+v, want closed for Christmas Eve", out)
+    }
+    if !strings.HasPrefix(out.NextStart, "2025-12-26T09:00:00") {
+        t.Errorf("next_start = %s, want 2025-12-26 09:00", out.NextStart)
+    }
+
+    res, _ = handleIsBusinessHours(context.Background(), testRequest("is_business_hours", map[string]any{
+        "country": "US", "holiday_calendar": "missing",
+    }))
+    if !res.IsError {
+        t.Error("unknown calendar should be a tool error")
+    }
+}
@@ -0,0 +1,298 @@
+// -*- coding: utf-8 -*-
+// i18n.go - translated tool, prompt and resource descriptions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file translates the descriptions returned by tools/list, prompts/list,
+// prompts/get and resources/list so that non-English agent frontends show
+// something meaningful. Catalogs are JSON files named after a BCP-47 tag; the
+// ones under i18n/ are built in and -i18n-dir adds or overrides others:
+//
+//   {
+//     "tools":     {"get_system_time": {"description": "...", "params": {"timezone": "..."}}},
+//     "prompts":   {"compare_timezones": {"description": "...", "arguments": {"timezones": "..."}}},
+//     "resources": {"timezone://info": {"description": "..."}}
+//   }
+//
+// The locale comes from "locale" in the client's experimental capabilities
+// sent with initialize, else from -i18n-locale. A tag falls back to its
+// language ("de-AT" uses "de"), and anything without a translation keeps
+// its English text. Names, URIs and schemas are never changed.
+
+package fasttime
+
+import (
+    "context"
+    "embed"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+//go:embed i18n/*.json
+var builtinCatalogs embed.FS
+
+// localeCapability is the experimental initialize capability naming the
+// session's locale
+const localeCapability = "locale"
+
+// toolText holds the translation of a tool
+type toolText struct {
+    Description string            `json:"description,omitempty"`
+    Params      map[string]string `json:"params,omitempty"`
+}
+
+// promptText holds the translation of a prompt
+type promptText struct {
+    Description string            `json:"description,omitempty"`
+    Arguments   map[string]string `json:"arguments,omitempty"`
+}
+
+// resourceText holds the translation of a resource or resource template
+type resourceText struct {
+    Description string `json:"description,omitempty"`
+}
+
+// catalog holds the translations for one locale
+type catalog struct {
+    Tools     map[string]toolText     `json:"tools,omitempty"`
+    Prompts   map[string]promptText   `json:"prompts,omitempty"`
+    Resources map[string]resourceText `json:"resources,omitempty"`
+}
+
+// catalogSet holds catalogs keyed by lower-case locale tag
+type catalogSet struct {
+    mu            sync.RWMutex
+    byTag         map[string]*catalog
+    defaultLocale string
+}
+
+// translations is the process-wide catalog set
+var translations = newCatalogSet()
+
+// newCatalogSet creates a set holding the built-in catalogs
+func newCatalogSet() *catalogSet {
+    cs := &catalogSet{byTag: make(map[string]*catalog)}
+    entries, _ := builtinCatalogs.ReadDir("i18n")
+    for _, e := range entries {
+        data, err := builtinCatalogs.ReadFile(path.Join("i18n", e.Name()))
+        if err == nil {
+            _ = cs.add(strings.TrimSuffix(e.Name(), ".json"), data)
+        }
+    }
+    return cs
+}
+
+// normalizeLocale lower-cases a tag and uses '-' as the separator
+func normalizeLocale(tag string) string {
+    return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// add parses and stores a catalog, replacing any with the same tag
+func (cs *catalogSet) add(tag string, data []byte) error {
+    var c catalog
+    if err := json.Unmarshal(data, &c); err != nil {
+        return fmt.Errorf("catalog %s: %w", tag, err)
+    }
+    cs.mu.Lock()
+    cs.byTag[normalizeLocale(tag)] = &c
+    cs.mu.Unlock()
+    return nil
+}
+
+// loadDir adds every *.json catalog in dir and returns how many were read
+func (cs *catalogSet) loadDir(dir string) (int, error) {
+    files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+    if err != nil {
+        return 0, err
+    }
+    for _, f := range files {
+        data, err := os.ReadFile(f)
+        if err != nil {
+            return 0, err
+        }
+        if err := cs.add(strings.TrimSuffix(filepath.Base(f), ".json"), data); err != nil {
+            return 0, err
+        }
+    }
+    return len(files), nil
+}
+
+// lookup returns the catalog for a tag, falling back to its language
+func (cs *catalogSet) lookup(tag string) (*catalog, string) {
+    tag = normalizeLocale(tag)
+    if tag == "" {
+        return nil, ""
+    }
+    cs.mu.RLock()
+    defer cs.mu.RUnlock()
+    for {
+        if c, ok := cs.byTag[tag]; ok {
+            return c, tag
+        }
+        i := strings.LastIndex(tag, "-")
+        if i < 0 {
+            return nil, ""
+        }
+        tag = tag[:i]
+    }
+}
+
+// locales returns the available catalog tags, sorted
+func (cs *catalogSet) locales() []string {
+    cs.mu.RLock()
+    tags := make([]string, 0, len(cs.byTag))
+    for tag := range cs.byTag {
+        tags = append(tags, tag)
+    }
+    cs.mu.RUnlock()
+    sort.Strings(tags)
+    return tags
+}
+
+// setDefault sets the locale used when the client names none
+func (cs *catalogSet) setDefault(tag string) error {
+    if tag != "" {
+        if c, _ := cs.lookup(tag); c == nil {
+            return fmt.Errorf("no translations for locale %q (available: %s)", tag, strings.Join(cs.locales(), ", "))
+        }
+    }
+    cs.mu.Lock()
+    cs.defaultLocale = tag
+    cs.mu.Unlock()
+    return nil
+}
+
+// catalogFor returns the catalog for the MCP session in ctx, or nil to keep
+// the English descriptions
+func (cs *catalogSet) catalogFor(ctx context.Context) *catalog {
+    tag := ""
+    if id := sessionIDFrom(ctx); id != "" {
+        tag = sessions.locale(id)
+    }
+    if tag == "" {
+        cs.mu.RLock()
+        tag = cs.defaultLocale
+        cs.mu.RUnlock()
+    }
+    c, _ := cs.lookup(tag)
+    return c
+}
+
+// initLocale reads the session locale from initialize
+func initLocale(req *mcp.InitializeRequest) string {
+    tag, _ := req.Params.Capabilities.Experimental[localeCapability].(string)
+    return strings.TrimSpace(tag)
+}
+
+// translateTool rewrites a listed tool's descriptions. The input schema's
+// property map is shared with the registered tool, so it is copied first.
+func (c *catalog) translateTool(tool *mcp.Tool) {
+    tt, ok := c.Tools[tool.Name]
+    if !ok {
+        return
+    }
+    if tt.Description != "" {
+        tool.Description = tt.Description
+    }
+    if len(tt.Params) == 0 || tool.InputSchema.Properties == nil {
+        return
+    }
+    props := make(map[string]any, len(tool.InputSchema.Properties))
+    for name, p := range tool.InputSchema.Properties {
+        if text, ok := tt.Params[name]; ok {
+            if schema, ok := p.(map[string]any); ok {
+                copied := make(map[string]any, len(schema))
+                for k, v := range schema {
+                    copied[k] = v
+                }
+                copied["description"] = text
+                p = copied
+            }
+        }
+        props[name] = p
+    }
+    tool.InputSchema.Properties = props
+}
+
+// translatePrompt rewrites a prompt's descriptions, copying the shared
+// argument slice first
+func (c *catalog) translatePrompt(prompt *mcp.Prompt) {
+    pt, ok := c.Prompts[prompt.Name]
+    if !ok {
+        return
+    }
+    if pt.Description != "" {
+        prompt.Description = pt.Description
+    }
+    if len(pt.Arguments) == 0 {
+        return
+    }
+    args := make([]mcp.PromptArgument, len(prompt.Arguments))
+    copy(args, prompt.Arguments)
+    for i := range args {
+        if text, ok := pt.Arguments[args[i].Name]; ok {
+            args[i].Description = text
+        }
+    }
+    prompt.Arguments = args
+}
+
+// resourceDescription returns the translated description for a URI
+func (c *catalog) resourceDescription(uri, fallback string) string {
+    if rt, ok := c.Resources[uri]; ok && rt.Description != "" {
+        return rt.Description
+    }
+    return fallback
+}
+
+// install registers the hooks that translate list and get results
+func (cs *catalogSet) install(hooks *server.Hooks) {
+    hooks.AddAfterListTools(func(ctx context.Context, _ any, _ *mcp.ListToolsRequest, res *mcp.ListToolsResult) {
+        if c := cs.catalogFor(ctx); c != nil {
+            for i := range res.Tools {
+                c.translateTool(&res.Tools[i])
+            }
+        }
+    })
+    hooks.AddAfterListPrompts(func(ctx context.Context, _ any, _ *mcp.ListPromptsRequest, res *mcp.ListPromptsResult) {
+        if c := cs.catalogFor(ctx); c != nil {
+            for i := range res.Prompts {
+                c.translatePrompt(&res.Prompts[i])
+            }
+        }
+    })
+    hooks.AddAfterGetPrompt(func(ctx context.Context, _ any, req *mcp.GetPromptRequest, res *mcp.GetPromptResult) {
+        if c := cs.catalogFor(ctx); c != nil {
+            if pt, ok := c.Prompts[req.Params.Name]; ok && pt.Description != "" {
+                res.Description = pt.Description
+            }
+        }
+    })
+    hooks.AddAfterListResources(func(ctx context.Context, _ any, _ *mcp.ListResourcesRequest, res *mcp.ListResourcesResult) {
+        if c := cs.catalogFor(ctx); c != nil {
+            for i := range res.Resources {
+                res.Resources[i].Description = c.resourceDescription(res.Resources[i].URI, res.Resources[i].Description)
+            }
+        }
+    })
+    hooks.AddAfterListResourceTemplates(func(ctx context.Context, _ any, _ *mcp.ListResourceTemplatesRequest, res *mcp.ListResourceTemplatesResult) {
+        if c := cs.catalogFor(ctx); c != nil {
+            for i := range res.ResourceTemplates {
+                rt := &res.ResourceTemplates[i]
+                if rt.URITemplate != nil && rt.URITemplate.Template != nil {
+                    rt.Description = c.resourceDescription(rt.URITemplate.Raw(), rt.Description)
+                }
+            }
+        }
+    })
+}
@@ -0,0 +1,149 @@
+// -*- coding: utf-8 -*-
+// i18n_test.go - Tests for translated descriptions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestBuiltinCatalogs(t *testing.T) {
+    cs := newCatalogSet()
+    for _, tag := range []string{"de", "es", "fr", "ja"} {
+        c, got := cs.lookup(tag)
+        if c == nil || got != tag {
+            t.Fatalf("missing built-in catalog %s", tag)
+        }
+        if c.Tools["get_system_time"].Description == "" || c.Prompts["compare_timezones"].Description == "" {
+            t.Errorf("catalog %s is incomplete", tag)
+        }
+    }
+}
+
+func TestCatalogLookupFallback(t *testing.T) {
+    cs := newCatalogSet()
+    if _, tag := cs.lookup("de_AT"); tag != "de" {
+        t.Errorf("de_AT should fall back to de, got %q", tag)
+    }
+    if _, tag := cs.lookup("JA-jp"); tag != "ja" {
+        t.Errorf("JA-jp should fall back to ja, got %q", tag)
+    }
+    if c, _ := cs.lookup("tlh"); c != nil {
+        t.Error("unknown locale should have no catalog")
+    }
+    if err := cs.setDefault("tlh"); err == nil {
+        t.Error("setDefault should reject a locale without translations")
+    }
+    if err := cs.setDefault(""); err != nil {
+        t.Errorf("empty default: %v", err)
+    }
+}
+
+func TestCatalogLoadDir(t *testing.T) {
+    dir := t.TempDir()
+    _ = os.WriteFile(filepath.Join(dir, "pt-BR.json"), []byte(`{"tools":{"is_dst":{"description":"Horário de verão"}}}`), 0o600)
+    cs := newCatalogSet()
+    if n, err := cs.loadDir(dir); err != nil || n != 1 {
+        t.Fatalf("loadDir = %d, %v", n, err)
+    }
+    if c, tag := cs.lookup("pt-br"); c == nil || tag != "pt-br" || c.Tools["is_dst"].Description != "Horário de verão" {
+        t.Errorf("pt-BR catalog not loaded: %v %q", c, tag)
+    }
+
+    _ = os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{`), 0o600)
+    if _, err := cs.loadDir(dir); err == nil {
+        t.Error("expected error for an invalid catalog")
+    }
+}
+
+func TestTranslateToolCopiesSchema(t *testing.T) {
+    tool := mcp.NewTool("get_system_time",
+        mcp.WithDescription("Get current system time"),
+        mcp.WithString("timezone", mcp.Description("IANA timezone")),
+    )
+    c := &catalog{Tools: map[string]toolText{
+        "get_system_time": {Description: "Systemzeit", Params: map[string]string{"timezone": "IANA-Zeitzone"}},
+    }}
+
+    listed := tool
+    c.translateTool(&listed)
+    if listed.Description != "Systemzeit" {
+        t.Errorf("description = %q", listed.Description)
+    }
+    if d := listed.InputSchema.Properties["timezone"].(map[string]any)["description"]; d != "IANA-Zeitzone" {
+        t.Errorf("param description = %v", d)
+    }
+    if d := tool.InputSchema.Properties["timezone"].(map[string]any)["description"]; d != "IANA timezone" {
+        t.Errorf("registered tool was modified: %v", d)
+    }
+}
+
+func TestTranslatedListsPerSession(t *testing.T) {
+    old := translations
+    translations = newCatalogSet()
+    defer func() { translations = old }()
+
+    hooks := &server.Hooks{}
+    sessions.trackSessions(hooks)
+    translations.install(hooks)
+    srv := server.NewMCPServer("test", "1.0",
+        server.WithToolCapabilities(false),
+        server.WithPromptCapabilities(false),
+        server.WithHooks(hooks),
+    )
+    srv.AddTool(mcp.NewTool("get_system_time", mcp.WithDescription("Get current system time in specified timezone")), handleGetSystemTime)
+    srv.AddPrompt(mcp.NewPrompt("compare_timezones",
+        mcp.WithPromptDescription("Compare current times across multiple time zones"),
+        mcp.WithArgument("timezones", mcp.ArgumentDescription("Comma-separated list of timezone IDs to compare")),
+    ), func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+        return mcp.NewGetPromptResult("Compare current times across multiple time zones", nil), nil
+    })
+
+    sessions.add("i18n-session", time.Now())
+    defer sessions.remove("i18n-session")
+    ctx := srv.WithContext(context.Background(), fakeSession{id: "i18n-session", ch: make(chan mcp.JSONRPCNotification, 1)})
+    call := func(msg string, v any) {
+        t.Helper()
+        resp, ok := srv.HandleMessage(ctx, json.RawMessage(msg)).(mcp.JSONRPCResponse)
+        if !ok {
+            t.Fatalf("unexpected response to %s", msg)
+        }
+        data, _ := json.Marshal(resp.Result)
+        _ = json.Unmarshal(data, v)
+    }
+
+    var tools mcp.ListToolsResult
+    call(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, &tools)
+    if tools.Tools[0].Description != "Get current system time in specified timezone" {
+        t.Errorf("without a locale descriptions stay English, got %q", tools.Tools[0].Description)
+    }
+
+    call(`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"t","version":"1"},"capabilities":{"experimental":{"locale":"de-CH"}}}}`, &struct{}{})
+    call(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`, &tools)
+    if tools.Tools[0].Description != "Aktuelle Systemzeit in der angegebenen Zeitzone abrufen" {
+        t.Errorf("session locale de-CH: got %q", tools.Tools[0].Description)
+    }
+
+    var prompts mcp.ListPromptsResult
+    call(`{"jsonrpc":"2.0","id":4,"method":"prompts/list"}`, &prompts)
+    if p := prompts.Prompts[0]; p.Description != "Aktuelle Uhrzeiten mehrerer Zeitzonen vergleichen" ||
+        p.Arguments[0].Description != "Kommagetrennte Liste der zu vergleichenden Zeitzonen" {
+        t.Errorf("prompt not translated: %+v", p)
+    }
+    var prompt mcp.GetPromptResult
+    call(`{"jsonrpc":"2.0","id":5,"method":"prompts/get","params":{"name":"compare_timezones"}}`, &prompt)
+    if prompt.Description != "Aktuelle Uhrzeiten mehrerer Zeitzonen vergleichen" {
+        t.Errorf("prompts/get description = %q", prompt.Description)
+    }
+}
@@ -0,0 +1,431 @@
+// -*- coding: utf-8 -*-
+// ical_parse.go - iCalendar reader
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// parseICalendar reads RFC 5545 text into a tree of components: lines are
+// unfolded, split into name, parameters and value, and nested by
+// BEGIN/END. Values stay raw; icalUnescape and parseICalDateTime decode
+// the TEXT and DATE/DATE-TIME forms that parse_ical needs.
+//
+// Times with a TZID are resolved by icalZones: IANA and Windows names
+// (also with a path prefix such as /mozilla.org/.../Europe/Berlin) use Go's
+// zone data, anything else the VTIMEZONE of the same file, whose
+// observances are expanded with their yearly RRULE. Floating times and
+// dates are taken in the caller's target zone.
+
+package fasttime
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// iCalendar input limits
+const (
+    icalMaxInput  = 1 << 20 // octets
+    icalMaxEvents = 1000
+    icalMaxDepth  = 8 // nested components
+)
+
+// icalProp is one content line
+type icalProp struct {
+    name   string
+    params map[string]string
+    value  string
+}
+
+// icalComponent is a BEGIN/END block
+type icalComponent struct {
+    name     string
+    props    []icalProp
+    children []*icalComponent
+}
+
+// prop returns the first property called name, or nil
+func (c *icalComponent) prop(name string) *icalProp {
+    for i := range c.props {
+        if c.props[i].name == name {
+            return &c.props[i]
+        }
+    }
+    return nil
+}
+
+// text returns the unescaped value of a TEXT property, or ""
+func (c *icalComponent) text(name string) string {
+    if p := c.prop(name); p != nil {
+        return icalUnescape(p.value)
+    }
+    return ""
+}
+
+// unfoldICal splits text into content lines, joining folded ones
+func unfoldICal(text string) []string {
+    var lines []string
+    for _, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+        switch {
+        case l == "":
+        case (l[0] == ' ' || l[0] == '\t') && len(lines) > 0:
+            lines[len(lines)-1] += l[1:]
+        default:
+            lines = append(lines, l)
+        }
+    }
+    return lines
+}
+
+// splitUnquoted splits s at sep outside double quotes, at most n parts
+// (n < 0: all)
+func splitUnquoted(s string, sep byte, n int) []string {
+    var out []string
+    quoted, from := false, 0
+    for i := 0; i < len(s) && n != len(out)+1; i++ {
+        switch {
+        case s[i] == '"':
+            quoted = !quoted
+        case s[i] == sep && !quoted:
+            out = append(out, s[from:i])
+            from = i + 1
+        }
+    }
+    return append(out, s[from:])
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value", honouring quoted
+// parameter values that contain ':' or ';'
+func parseContentLine(line string) (icalProp, error) {
+    p := icalProp{params: map[string]string{}}
+    parts := splitUnquoted(line, ':', 2)
+    if len(parts) != 2 {
+        return p, fmt.Errorf("invalid line %q", line)
+    }
+    head := splitUnquoted(parts[0], ';', -1)
+    p.name, p.value = strings.ToUpper(head[0]), parts[1]
+    if p.name == "" {
+        return p, fmt.Errorf("invalid line %q", line)
+    }
+    for _, param := range head[1:] {
+        name, value, ok := strings.Cut(param, "=")
+        if !ok || name == "" {
+            return p, fmt.Errorf("invalid parameter %q in %s", param, p.name)
+        }
+        if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+            value = value[1 : len(value)-1]
+        }
+        p.params[strings.ToUpper(name)] = value
+    }
+    return p, nil
+}
+
+// parseICalendar reads a calendar object and returns its VCALENDAR
+func parseICalendar(text string) (*icalComponent, error) {
+    if len(text) > icalMaxInput {
+        return nil, fmt.Errorf("calendar is larger than %d bytes", icalMaxInput)
+    }
+    var stack []*icalComponent
+    var root *icalComponent
+    for _, line := range unfoldICal(text) {
+        p, err := parseContentLine(line)
+        if err != nil {
+            return nil, err
+        }
+        switch p.name {
+        case "BEGIN":
+            c := &icalComponent{name: strings.ToUpper(p.value)}
+            switch {
+            case len(stack) == 0 && root != nil:
+                return nil, fmt.Errorf("content after END:%s", root.name)
+            case len(stack) == 0:
+                root = c
+            case len(stack) >= icalMaxDepth:
+                return nil, fmt.Errorf("components nested deeper than %d", icalMaxDepth)
+            default:
+                top := stack[len(stack)-1]
+                top.children = append(top.children, c)
+            }
+            stack = append(stack, c)
+        case "END":
+            if len(stack) == 0 || stack[len(stack)-1].name != strings.ToUpper(p.value) {
+                return nil, fmt.Errorf("unexpected END:%s", p.value)
+            }
+            stack = stack[:len(stack)-1]
+        default:
+            if len(stack) == 0 {
+                return nil, fmt.Errorf("property %s outside a component", p.name)
+            }
+            top := stack[len(stack)-1]
+            top.props = append(top.props, p)
+        }
+    }
+    switch {
+    case root == nil || root.name != "VCALENDAR":
+        return nil, fmt.Errorf("no BEGIN:VCALENDAR found")
+    case len(stack) > 0:
+        return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].name)
+    }
+    return root, nil
+}
+
+// icalUnescape decodes a TEXT value
+func icalUnescape(s string) string {
+    return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// icalDateTime is a DATE or DATE-TIME value; wall holds the written
+// fields in a UTC container
+type icalDateTime struct {
+    wall time.Time
+    utc  bool
+    tzid string
+    date bool
+}
+
+// parseICalDateTime reads one DATE or DATE-TIME value of p
+func parseICalDateTime(p *icalProp, value string) (icalDateTime, error) {
+    d := icalDateTime{tzid: p.params["TZID"]}
+    var err error
+    switch {
+    case p.params["VALUE"] == "DATE" || len(value) == len("20060102"):
+        d.wall, err = time.Parse("20060102", value)
+        d.date = true
+    case strings.HasSuffix(value, "Z"):
+        d.wall, err = time.Parse(icalUTCLayout, value)
+        d.utc = true
+    default:
+        d.wall, err = time.Parse(icalLocalLayout, value)
+    }
+    if err != nil {
+        return d, fmt.Errorf("invalid %s %q", p.name, value)
+    }
+    return d, nil
+}
+
+// parseICalDateTimes reads a comma-separated EXDATE or RDATE value; for
+// PERIOD values only the start is used
+func parseICalDateTimes(p *icalProp) ([]icalDateTime, error) {
+    var out []icalDateTime
+    for _, v := range strings.Split(p.value, ",") {
+        v, _, _ = strings.Cut(strings.TrimSpace(v), "/")
+        d, err := parseICalDateTime(p, v)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, d)
+    }
+    return out, nil
+}
+
+// icalObservance is a STANDARD or DAYLIGHT block of a VTIMEZONE
+type icalObservance struct {
+    start    time.Time // onset, a wall time in the offset before it
+    from, to int       // offsets in seconds
+    rule     *icalRule
+    rdates   []time.Time
+}
+
+// icalVTimezone is a zone defined by the calendar itself
+type icalVTimezone struct {
+    tzid  string
+    obs   []icalObservance
+    years map[int][]icalOnset // onsets of year Y-1 and Y, by Y
+}
+
+// icalOnset is one offset change
+type icalOnset struct {
+    at time.Time // wall time
+    to int
+}
+
+// parseICalOffset reads a UTC offset such as -0500 or +053000
+func parseICalOffset(s string) (int, error) {
+    if len(s) != 5 && len(s) != 7 || (s[0] != '+' && s[0] != '-') {
+        return 0, fmt.Errorf("invalid UTC offset %q", s)
+    }
+    secs := 0
+    for i, unit := range []int{3600, 60, 1} {
+        if 1+2*i >= len(s) {
+            break
+        }
+        n, err := strconv.Atoi(s[1+2*i : 3+2*i])
+        if err != nil {
+            return 0, fmt.Errorf("invalid UTC offset %q", s)
+        }
+        secs += n * unit
+    }
+    if s[0] == '-' {
+        secs = -secs
+    }
+    return secs, nil
+}
+
+// parseVTimezone reads a VTIMEZONE component
+func parseVTimezone(c *icalComponent) (*icalVTimezone, error) {
+    z := &icalVTimezone{tzid: c.text("TZID"), years: map[int][]icalOnset{}}
+    for _, o := range c.children {
+        if o.name != "STANDARD" && o.name != "DAYLIGHT" {
+            continue
+        }
+        var obs icalObservance
+        start, from, to := o.prop("DTSTART"), o.prop("TZOFFSETFROM"), o.prop("TZOFFSETTO")
+        if start == nil || from == nil || to == nil {
+            return nil, fmt.Errorf("VTIMEZONE %s: %s needs DTSTART, TZOFFSETFROM and TZOFFSETTO", z.tzid, o.name)
+        }
+        d, err := parseICalDateTime(start, start.value)
+        if err != nil {
+            return nil, fmt.Errorf("VTIMEZONE %s: %v", z.tzid, err)
+        }
+        obs.start = d.wall
+        if obs.from, err = parseICalOffset(from.value); err == nil {
+            obs.to, err = parseICalOffset(to.value)
+        }
+        if err != nil {
+            return nil, fmt.Errorf("VTIMEZONE %s: %v", z.tzid, err)
+        }
+        if p := o.prop("RRULE"); p != nil {
+            rule, err := parseRRule(p.value)
+            if err != nil {
+                return nil, fmt.Errorf("VTIMEZONE %s: %v", z.tzid, err)
+            }
+            obs.rule = &rule
+        }
+        for _, p := range o.props {
+            if p.name == "RDATE" {
+                dates, err := parseICalDateTimes(&p)
+                if err != nil {
+                    return nil, fmt.Errorf("VTIMEZONE %s: %v", z.tzid, err)
+                }
+                for _, d := range dates {
+                    obs.rdates = append(obs.rdates, d.wall)
+                }
+            }
+        }
+        z.obs = append(z.obs, obs)
+    }
+    if len(z.obs) == 0 {
+        return nil, fmt.Errorf("VTIMEZONE %s has no STANDARD or DAYLIGHT observance", z.tzid)
+    }
+    sort.Slice(z.obs, func(i, j int) bool { return z.obs[i].start.Before(z.obs[j].start) })
+    return z, nil
+}
+
+// onsets returns the offset changes of year-1 and year, in order
+func (z *icalVTimezone) onsets(year int) []icalOnset {
+    if list, ok := z.years[year]; ok {
+        return list
+    }
+    lo := time.Date(year-1, 1, 1, 0, 0, 0, 0, time.UTC)
+    hi := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+    var list []icalOnset
+    add := func(w time.Time, to int) {
+        if !w.Before(lo) && w.Before(hi) {
+            list = append(list, icalOnset{at: w, to: to})
+        }
+    }
+    for _, o := range z.obs {
+        add(o.start, o.to)
+        for _, d := range o.rdates {
+            add(d, o.to)
+        }
+        if o.rule != nil {
+            o.rule.expand(o.start, func(w time.Time) time.Time { return w }, hi, func(w, _ time.Time) bool {
+                add(w, o.to)
+                return true
+            })
+        }
+    }
+    sort.Slice(list, func(i, j int) bool { return list[i].at.Before(list[j].at) })
+    z.years[year] = list
+    return list
+}
+
+// offset returns the UTC offset in force at wall time w
+func (z *icalVTimezone) offset(w time.Time) int {
+    list := z.onsets(w.Year())
+    for i := len(list) - 1; i >= 0; i-- {
+        if !list[i].at.After(w) {
+            return list[i].to
+        }
+    }
+    // Before every onset in range: take the latest earlier observance
+    off := z.obs[0].from
+    for _, o := range z.obs {
+        if o.start.After(w) {
+            break
+        }
+        off = o.to
+    }
+    return off
+}
+
+// icalZones resolves the TZIDs of one calendar object
+type icalZones struct {
+    target *time.Location
+    custom map[string]*icalVTimezone
+}
+
+// newICalZones collects the VTIMEZONE components of cal
+func newICalZones(cal *icalComponent, target *time.Location) (*icalZones, error) {
+    z := &icalZones{target: target, custom: map[string]*icalVTimezone{}}
+    for _, c := range cal.children {
+        if c.name != "VTIMEZONE" {
+            continue
+        }
+        tz, err := parseVTimezone(c)
+        if err != nil {
+            return nil, err
+        }
+        z.custom[tz.tzid] = tz
+    }
+    return z, nil
+}
+
+// lookupZone finds an IANA or Windows zone for tzid, also when it carries
+// a path prefix
+func lookupZone(tzid string) *time.Location {
+    parts := strings.Split(strings.Trim(tzid, "/"), "/")
+    for i := range parts {
+        if loc, err := loadLocation(strings.Join(parts[i:], "/")); err == nil {
+            return loc
+        }
+    }
+    return nil
+}
+
+// resolver returns the function that turns wall times of d's zone into
+// instants
+func (z *icalZones) resolver(d icalDateTime) (func(time.Time) time.Time, error) {
+    atIn := func(loc *time.Location) func(time.Time) time.Time {
+        return func(w time.Time) time.Time {
+            return forwardDate(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), loc)
+        }
+    }
+    switch {
+    case d.utc:
+        return func(w time.Time) time.Time { return w }, nil
+    case d.tzid == "" || d.date:
+        return atIn(z.target), nil
+    }
+    if loc := lookupZone(d.tzid); loc != nil {
+        return atIn(loc), nil
+    }
+    if tz, ok := z.custom[d.tzid]; ok {
+        return func(w time.Time) time.Time {
+            return w.Add(-time.Duration(tz.offset(w)) * time.Second)
+        }, nil
+    }
+    return nil, fmt.Errorf("unknown TZID %q without a VTIMEZONE", d.tzid)
+}
+
+// instant resolves one value
+func (z *icalZones) instant(d icalDateTime) (time.Time, error) {
+    at, err := z.resolver(d)
+    if err != nil {
+        return time.Time{}, err
+    }
+    return at(d.wall), nil
+}
@@ -0,0 +1,238 @@
+// -*- coding: utf-8 -*-
+// idempotency.go - Idempotency-Key support for REST POST endpoints
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// A client that retries a POST after a network error cannot tell whether
+// the first attempt was processed. Sending an Idempotency-Key header makes
+// the retry safe: the first response to a key is kept for -idempotency-ttl
+// and a retry with the same key and the same request gets that response
+// again, marked with Idempotent-Replayed: true, instead of running twice.
+//
+// Keys are scoped to the caller's Authorization header, so two clients
+// cannot see each other's responses. Reusing a key for a different request
+// (method, path, Accept or body) is answered with 422; a retry that arrives
+// while the first attempt is still running gets 409. Server errors (5xx)
+// are not kept, so those retries run again.
+
+package fasttime
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// idempotencyHeader is the request header carrying the key
+const idempotencyHeader = "Idempotency-Key"
+
+// Default lifetime of a kept response, and bounds on keys
+const (
+    defaultIdempotencyTTL = 10 * time.Minute
+    maxIdempotencyKeys    = 10000
+    maxIdempotencyKeyLen  = 255
+)
+
+// idempotentResponse is a kept response, or a request still in progress
+type idempotentResponse struct {
+    fingerprint string
+    expires     time.Time
+    done        bool
+    status      int
+    header      http.Header
+    body        []byte
+}
+
+// idempotencyStore keeps responses by scoped key
+type idempotencyStore struct {
+    mu      sync.Mutex
+    ttl     time.Duration // 0 disables idempotency keys
+    entries map[string]*idempotentResponse
+}
+
+// idempotency is the process-wide store, sized by -idempotency-ttl
+var idempotency = newIdempotencyStore(defaultIdempotencyTTL)
+
+// newIdempotencyStore returns a store keeping responses for ttl
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+    return &idempotencyStore{ttl: ttl, entries: make(map[string]*idempotentResponse)}
+}
+
+// setTTL changes how long responses are kept and forgets the current ones
+func (s *idempotencyStore) setTTL(ttl time.Duration) {
+    s.mu.Lock()
+    s.ttl = ttl
+    s.entries = make(map[string]*idempotentResponse)
+    s.mu.Unlock()
+}
+
+// enabled reports whether idempotency keys are honoured
+func (s *idempotencyStore) enabled() bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.ttl > 0
+}
+
+// begin claims key for a request with fingerprint. It returns the kept
+// response of an earlier identical request, or an error status when the key
+// is busy or was used for another request; with neither the caller runs the
+// request and reports the outcome with finish.
+func (s *idempotencyStore) begin(key, fingerprint string, now time.Time) (*idempotentResponse, int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+        switch {
+        case e.fingerprint != fingerprint:
+            return nil, http.StatusUnprocessableEntity
+        case !e.done:
+            return nil, http.StatusConflict
+        }
+        return e, 0
+    }
+
+    if len(s.entries) >= maxIdempotencyKeys {
+        s.evict(now)
+    }
+    s.entries[key] = &idempotentResponse{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+    return nil, 0
+}
+
+// evict drops expired entries, or the one expiring first if none has; mu is held
+func (s *idempotencyStore) evict(now time.Time) {
+    var oldest string
+    for k, e := range s.entries {
+        if !now.Before(e.expires) {
+            delete(s.entries, k)
+        } else if oldest == "" || e.expires.Before(s.entries[oldest].expires) {
+            oldest = k
+        }
+    }
+    if len(s.entries) >= maxIdempotencyKeys {
+        delete(s.entries, oldest)
+    }
+}
+
+// finish keeps the response to key, or releases the key for server errors
+func (s *idempotencyStore) finish(key string, status int, header http.Header, body []byte) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    e, ok := s.entries[key]
+    if !ok {
+        return
+    }
+    if status >= http.StatusInternalServerError {
+        delete(s.entries, key)
+        return
+    }
+    e.done, e.status, e.header, e.body = true, status, header, body
+}
+
+// idempotencyFingerprint identifies a request for comparison with a retry
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+    h := sha256.New()
+    fmt.Fprintf(h, "%s\n%s\n%s\n%s\n", r.Method, requestPath(r), r.URL.RawQuery, r.Header.Get("Accept"))
+    h.Write(body)
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyScope keys the store by the caller's credentials
+func idempotencyScope(r *http.Request, key string) string {
+    sum := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+    return hex.EncodeToString(sum[:8]) + ":" + key
+}
+
+// recordingWriter passes a response through and keeps a copy of it
+type recordingWriter struct {
+    http.ResponseWriter
+    status int
+    header http.Header
+    body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+    if rw.status == 0 {
+        rw.status = code
+        rw.header = rw.ResponseWriter.Header().Clone()
+    }
+    rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+    if rw.status == 0 {
+        rw.WriteHeader(http.StatusOK)
+    }
+    rw.body.Write(b)
+    return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+    return rw.ResponseWriter
+}
+
+// idempotencyMiddleware answers retried POSTs that carry an Idempotency-Key
+// with the response to the first attempt
+func idempotencyMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        key := r.Header.Get(idempotencyHeader)
+        if r.Method != http.MethodPost || key == "" || !idempotency.enabled() {
+            next.ServeHTTP(w, r)
+            return
+        }
+        if len(key) > maxIdempotencyKeyLen {
+            writeAPIError(w, http.StatusBadRequest, fieldError(idempotencyHeader, fmt.Errorf("%s must be at most %d characters", idempotencyHeader, maxIdempotencyKeyLen)))
+            return
+        }
+
+        // The body is already bounded by -max-body-size
+        body, err := io.ReadAll(r.Body)
+        if err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Failed to read request body")
+            return
+        }
+        r.Body = io.NopCloser(bytes.NewReader(body))
+
+        scoped := idempotencyScope(r, key)
+        kept, status := idempotency.begin(scoped, idempotencyFingerprint(r, body), time.Now())
+        switch status {
+        case http.StatusConflict:
+            writeJSONError(w, status, "A request with this Idempotency-Key is still being processed")
+            return
+        case http.StatusUnprocessableEntity:
+            writeJSONError(w, status, "This Idempotency-Key was used for a different request")
+            return
+        }
+        if kept != nil {
+            h := w.Header()
+            for k, v := range kept.header {
+                h[k] = v
+            }
+            h.Set("Idempotent-Replayed", "true")
+            w.WriteHeader(kept.status)
+            _, _ = w.Write(kept.body)
+            logAt(logDebug, "idempotency: replayed %s %s", r.Method, requestPath(r))
+            return
+        }
+
+        // A handler that panics releases the key so the retry runs again
+        rw := &recordingWriter{ResponseWriter: w}
+        finished := false
+        defer func() {
+            if !finished {
+                idempotency.finish(scoped, http.StatusInternalServerError, nil, nil)
+            }
+        }()
+        next.ServeHTTP(rw, r)
+        if rw.status == 0 {
+            rw.status = http.StatusOK
+        }
+        idempotency.finish(scoped, rw.status, rw.header, rw.body.Bytes())
+        finished = true
+    })
+}
@@ -0,0 +1,111 @@
+// -*- coding: utf-8 -*-
+// idempotency_test.go - Tests for Idempotency-Key handling
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+    idempotency.setTTL(time.Minute)
+    defer idempotency.setTTL(defaultIdempotencyTTL)
+
+    calls := 0
+    h := idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls++
+        w.Header().Set("X-Call", strings.Repeat("x", calls))
+        writeJSON(w, http.StatusCreated, map[string]int{"call": calls})
+    }))
+    post := func(key, body, auth string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodPost, "/api/v1/convert", strings.NewReader(body))
+        if key != "" {
+            req.Header.Set(idempotencyHeader, key)
+        }
+        req.Header.Set("Authorization", auth)
+        w := httptest.NewRecorder()
+        h.ServeHTTP(w, req)
+        return w
+    }
+
+    first := post("k1", `{"a":1}`, "Bearer a")
+    retry := post("k1", `{"a":1}`, "Bearer a")
+    if calls != 1 || retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+        t.Errorf("retry ran again or changed: calls=%d %d %q", calls, retry.Code, retry.Body)
+    }
+    if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Header().Get("X-Call") != "x" {
+        t.Errorf("replayed headers: %v", retry.Header())
+    }
+    if first.Header().Get("Idempotent-Replayed") != "" {
+        t.Error("first response marked as replayed")
+    }
+
+    if w := post("k1", `{"a":2}`, "Bearer a"); w.Code != http.StatusUnprocessableEntity {
+        t.Errorf("reused key: status %d", w.Code)
+    }
+    // Another caller's key space is separate
+    if post("k1", `{"a":1}`, "Bearer b"); calls != 2 {
+        t.Errorf("other caller replayed: calls=%d", calls)
+    }
+    // Without a key nothing is kept
+    post("", `{"a":1}`, "Bearer a")
+    post("", `{"a":1}`, "Bearer a")
+    if calls != 4 {
+        t.Errorf("keyless requests: calls=%d", calls)
+    }
+    if w := post(strings.Repeat("k", maxIdempotencyKeyLen+1), `{}`, ""); w.Code != http.StatusBadRequest {
+        t.Errorf("long key: status %d", w.Code)
+    }
+}
+
+func TestIdempotencyStore(t *testing.T) {
+    s := newIdempotencyStore(time.Minute)
+    now := time.Now()
+    if kept, status := s.begin("k", "f", now); kept != nil || status != 0 {
+        t.Fatalf("first begin: %v %d", kept, status)
+    }
+    if _, status := s.begin("k", "f", now); status != http.StatusConflict {
+        t.Errorf("in-progress key: status %d", status)
+    }
+    // Server errors release the key
+    s.finish("k", http.StatusServiceUnavailable, nil, nil)
+    if kept, status := s.begin("k", "f", now); kept != nil || status != 0 {
+        t.Errorf("after 5xx: %v %d", kept, status)
+    }
+    s.finish("k", http.StatusOK, http.Header{}, []byte("ok"))
+    if kept, _ := s.begin("k", "f", now); kept == nil || string(kept.body) != "ok" {
+        t.Errorf("kept response: %v", kept)
+    }
+    // Expired keys start over
+    if kept, status := s.begin("k", "other", now.Add(2*time.Minute)); kept != nil || status != 0 {
+        t.Errorf("expired key: %v %d", kept, status)
+    }
+}
+
+func TestIdempotentBatchConvertNegotiated(t *testing.T) {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    body := `{"conversions":[{"time":"2025-01-10T10:00:00Z","from_timezone":"UTC","to_timezone":"Asia/Tokyo"}]}`
+    send := func() *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodPost, "/api/v2/convert/batch", strings.NewReader(body))
+        req.Header.Set(idempotencyHeader, "batch-negotiated")
+        req.Header.Set("Accept", "application/xml")
+        w := httptest.NewRecorder()
+        mux.ServeHTTP(w, req)
+        return w
+    }
+    first, retry := send(), send()
+    if !strings.HasPrefix(first.Header().Get("Content-Type"), "application/xml") {
+        t.Errorf("negotiation lost behind the recorder: %q", first.Header().Get("Content-Type"))
+    }
+    if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Body.String() != first.Body.String() {
+        t.Errorf("batch retry not replayed: %v %q", retry.Header(), retry.Body)
+    }
+}
@@ -0,0 +1,174 @@
+// -*- coding: utf-8 -*-
+// limits.go - request body size limits and per-request timeouts
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file protects the HTTP transports from abusive payloads and slow
+// handlers. -max-body-size rejects larger request bodies with 413 before any
+// handler sees them. -request-timeout answers with 408 when a handler has not
+// started its response in time, and cancels the request context so the tool
+// call stops. SSE event streams, including GET /api/v1/time/stream, are
+// long-lived by design and are exempt from the timeout.
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// defaultMaxBodySize is the default -max-body-size (1 MiB)
+const defaultMaxBodySize = 1 << 20
+
+// bodyLimitMiddleware rejects request bodies larger than max bytes
+func bodyLimitMiddleware(max int64, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.ContentLength > max {
+            writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds %d bytes", max))
+            return
+        }
+        if r.Body != nil && r.Body != http.NoBody {
+            // Read the body up front so oversized chunked uploads also get
+            // a 413 rather than a truncated-JSON error from the handler
+            data, err := io.ReadAll(io.LimitReader(r.Body, max+1))
+            _ = r.Body.Close()
+            if err != nil {
+                writeJSONError(w, http.StatusBadRequest, "Failed to read request body")
+                return
+            }
+            if int64(len(data)) > max {
+                writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds %d bytes", max))
+                return
+            }
+            r.Body = io.NopCloser(bytes.NewReader(data))
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// isEventStream reports whether r opens an SSE event stream: the MCP SSE
+// endpoint, the REST time stream (which plain clients such as curl -N open
+// without an Accept header) or any GET asking for text/event-stream
+func isEventStream(r *http.Request) bool {
+    return strings.HasSuffix(r.URL.Path, "/sse") ||
+        (r.Method == http.MethodGet && (r.URL.Path == restTimeStreamPath ||
+            strings.Contains(r.Header.Get("Accept"), "text/event-stream")))
+}
+
+// timeoutMiddleware bounds each request to d, except SSE event streams
+func timeoutMiddleware(d time.Duration, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if isEventStream(r) {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        ctx, cancel := context.WithTimeout(r.Context(), d)
+        defer cancel()
+
+        tw := &timeoutWriter{ResponseWriter: w, header: make(http.Header)}
+        done := make(chan struct{})
+        panicked := make(chan interface{}, 1)
+        go func() {
+            defer func() {
+                if p := recover(); p != nil {
+                    panicked <- p
+                }
+                close(done)
+            }()
+            next.ServeHTTP(tw, r.WithContext(ctx))
+        }()
+
+        select {
+        case <-done:
+        case <-ctx.Done():
+            tw.mu.Lock()
+            if !tw.started {
+                tw.timedOut = true
+                tw.mu.Unlock()
+                logAt(logWarn, "request %s %s timed out after %v", r.Method, r.URL.Path, d)
+                writeJSONError(w, http.StatusRequestTimeout, fmt.Sprintf("Request exceeded %v", d))
+                return
+            }
+            tw.mu.Unlock()
+            // The response is already under way; let the handler finish it
+            <-done
+        }
+        select {
+        case p := <-panicked:
+            panic(p)
+        default:
+        }
+    })
+}
+
+// timeoutWriter drops writes from a handler whose request already timed out.
+// The handler gets its own header map so that it never races with the 408.
+type timeoutWriter struct {
+    http.ResponseWriter
+    header   http.Header
+    mu       sync.Mutex
+    started  bool // handler began its response
+    timedOut bool // 408 sent; handler output is discarded
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+    return tw.header
+}
+
+// start copies the handler's headers once the response begins; mu is held
+func (tw *timeoutWriter) start() {
+    if tw.started {
+        return
+    }
+    tw.started = true
+    dst := tw.ResponseWriter.Header()
+    for k, v := range tw.header {
+        dst[k] = v
+    }
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+    tw.mu.Lock()
+    defer tw.mu.Unlock()
+    if tw.timedOut {
+        return
+    }
+    tw.start()
+    tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+    tw.mu.Lock()
+    defer tw.mu.Unlock()
+    if tw.timedOut {
+        return 0, http.ErrHandlerTimeout
+    }
+    tw.start()
+    return tw.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer (needed for streaming)
+func (tw *timeoutWriter) Flush() {
+    tw.mu.Lock()
+    defer tw.mu.Unlock()
+    if tw.timedOut {
+        return
+    }
+    if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+        tw.start()
+        f.Flush()
+    }
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+    return tw.ResponseWriter
+}
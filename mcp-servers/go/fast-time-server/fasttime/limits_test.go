@@ -0,0 +1,128 @@
+// -*- coding: utf-8 -*-
+// limits_test.go - Tests for body size limits and request timeouts
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bufio"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+    h := bodyLimitMiddleware(16, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := io.ReadAll(r.Body)
+        _, _ = w.Write(body)
+    }))
+
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"ok":true}`)))
+    if rec.Code != http.StatusOK || rec.Body.String() != `{"ok":true}` {
+        t.Errorf("small body: got %d %q", rec.Code, rec.Body.String())
+    }
+
+    rec = httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 17))))
+    if rec.Code != http.StatusRequestEntityTooLarge {
+        t.Fatalf("large body: got %d", rec.Code)
+    }
+    var body ErrorResponse
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != http.StatusRequestEntityTooLarge {
+        t.Errorf("want JSON error, got %q", rec.Body.String())
+    }
+
+    // Unknown length (chunked) bodies are checked as they are read
+    req := httptest.NewRequest(http.MethodPost, "/", io.MultiReader(strings.NewReader(strings.Repeat("x", 10)), strings.NewReader(strings.Repeat("y", 10))))
+    req.ContentLength = -1
+    rec = httptest.NewRecorder()
+    h.ServeHTTP(rec, req)
+    if rec.Code != http.StatusRequestEntityTooLarge {
+        t.Errorf("chunked large body: got %d", rec.Code)
+    }
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+    h := timeoutMiddleware(20*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/fast":
+            w.Header().Set("X-Handler", "fast")
+            _, _ = io.WriteString(w, "done")
+        default:
+            select {
+            case <-r.Context().Done():
+            case <-time.After(time.Second):
+            }
+            w.Header().Set("X-Handler", "slow")
+            _, _ = io.WriteString(w, "late")
+        }
+    }))
+
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+    if rec.Code != http.StatusOK || rec.Body.String() != "done" || rec.Header().Get("X-Handler") != "fast" {
+        t.Errorf("fast request: got %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+    }
+
+    rec = httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slow", nil))
+    if rec.Code != http.StatusRequestTimeout {
+        t.Fatalf("slow request: got %d", rec.Code)
+    }
+    if !strings.Contains(rec.Body.String(), "Request exceeded") {
+        t.Errorf("want JSON timeout error, got %q", rec.Body.String())
+    }
+}
+
+func TestTimeoutSkipsEventStreams(t *testing.T) {
+    h := timeoutMiddleware(time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if _, ok := r.Context().Deadline(); ok {
+            t.Errorf("%s should not have a deadline", r.URL.Path)
+        }
+    }))
+
+    h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+    req := httptest.NewRequest(http.MethodGet, "/http", nil)
+    req.Header.Set("Accept", "text/event-stream")
+    h.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestTimeoutSkipsRESTTimeStream(t *testing.T) {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    srv := httptest.NewServer(timeoutMiddleware(50*time.Millisecond, mux))
+    defer srv.Close()
+
+    // curl -N sends no Accept header; the stream must outlive the timeout
+    resp, err := http.Get(srv.URL + restTimeStreamPath + "?timezones=UTC&interval=100ms")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        t.Fatalf("status = %d", resp.StatusCode)
+    }
+    if countStreamEvents(t, resp.Body, 3) != 3 {
+        t.Error("stream ended before 3 events")
+    }
+}
+
+// countStreamEvents reads up to want "data:" lines from an event stream
+func countStreamEvents(t *testing.T, body io.Reader, want int) int {
+    t.Helper()
+    n := 0
+    scanner := bufio.NewScanner(body)
+    for n < want && scanner.Scan() {
+        if strings.HasPrefix(scanner.Text(), "data:") {
+            n++
+        }
+    }
+    return n
+}
@@ -0,0 +1,506 @@
+// -*- coding: utf-8 -*-
+// listeners.go - the sockets a server listens on
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file builds what the server listens on. By default that is one
+// listener made from -transport, -addr (or -listen and -port) and -pipe.
+// -listeners serves several at once, each with the handlers and middleware
+// chain of its kind:
+//
+//   -listeners 'sse=:8080,rest=:8081,metrics=127.0.0.1:9090,admin=unix:/run/fast-time-admin.sock'
+//
+//   sse, http, dual, rest  the transports of the same name
+//   pipe[=PATH]            the stdio protocol on a named pipe or Unix socket
+//   metrics                /debug/vars, /health, probes and /version without
+//                          auth; keep it on loopback or a private network
+//   admin                  /admin/*, /dashboard and, with -debug, /debug/*
+//                          behind -admin-token
+//
+// An HTTP address is host:port or unix:PATH. When an admin listener exists
+// the other listeners no longer serve /admin and /debug. Zero-downtime
+// upgrades and systemd socket activation hand over one socket, so they only
+// apply to a single listener.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "os"
+    "slices"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// listenerKinds lists the kinds accepted by -listeners
+var listenerKinds = []string{"sse", "http", "dual", "rest", "pipe", "metrics", "admin"}
+
+// unixAddrPrefix marks an HTTP address as a Unix socket path
+const unixAddrPrefix = "unix:"
+
+// listenerSpec is one socket to serve and what to serve on it
+type listenerSpec struct {
+    kind string
+    addr string // host:port, unix:PATH, or the pipe name
+}
+
+// listenerConfig holds the settings shared by every listener (set in main)
+type listenerConfig struct {
+    authOn    bool
+    authTok   *bearerToken
+    adminTok  *bearerToken
+    debug     bool
+    compress  bool
+    graphql   bool
+    maxBody   int64
+    timeout   time.Duration
+    acl       *ipACL
+    proxies   ipNets
+    publicURL string
+    keepAlive time.Duration
+    idleTTL   time.Duration
+    maxSSE    int
+    pushEvery time.Duration
+    adminPort bool // an admin listener serves /admin and /debug instead
+
+    spawn         func(func(context.Context)) // runs background work (Server.background)
+    sseBackground sync.Once                   // starts the SSE reaper and resource pusher once
+}
+
+// parseListeners parses the -listeners value
+func parseListeners(value string) ([]listenerSpec, error) {
+    var (
+        specs []listenerSpec
+        seen  = map[string]string{}
+    )
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        kind, addr, _ := strings.Cut(item, "=")
+        kind = strings.ToLower(strings.TrimSpace(kind))
+        addr = strings.TrimSpace(addr)
+        switch {
+        case !slices.Contains(listenerKinds, kind):
+            return nil, fmt.Errorf("-listeners: unknown kind %q (want one of %s)", kind, strings.Join(listenerKinds, ", "))
+        case addr == "" && kind != "pipe":
+            return nil, fmt.Errorf("-listeners: %s needs an address, e.g. %s=:8080", kind, kind)
+        }
+        key := addr
+        if kind == "pipe" {
+            key = "pipe:" + addr
+        }
+        if prev, ok := seen[key]; ok {
+            return nil, fmt.Errorf("-listeners: %s and %s both use %q", prev, kind, addr)
+        }
+        seen[key] = kind
+        specs = append(specs, listenerSpec{kind, addr})
+    }
+    if len(specs) == 0 {
+        return nil, errors.New("-listeners: no listeners given")
+    }
+    return specs, nil
+}
+
+// listenersFor returns the listeners to serve: those of -listeners if set,
+// else the one of -transport; stdio has none
+func listenersFor(listeners, transport, addr, pipe string) ([]listenerSpec, error) {
+    if listeners != "" {
+        return parseListeners(listeners)
+    }
+    switch transport = strings.ToLower(transport); transport {
+    case "stdio":
+        return nil, nil
+    case "pipe":
+        return []listenerSpec{{"pipe", pipe}}, nil
+    case "sse", "http", "dual", "rest":
+        return []listenerSpec{{transport, addr}}, nil
+    }
+    return nil, fmt.Errorf("unknown transport %q", transport)
+}
+
+// listenerTransports returns the protocols served by specs, for /version
+func listenerTransports(specs []listenerSpec) []string {
+    var out []string
+    for _, spec := range specs {
+        if spec.kind == "metrics" || spec.kind == "admin" {
+            continue
+        }
+        for _, t := range transportsFor(spec.kind) {
+            if !slices.Contains(out, t) {
+                out = append(out, t)
+            }
+        }
+    }
+    return out
+}
+
+// hasSSEListener reports whether any listener serves the SSE transport,
+// which is where resource subscriptions are answered
+func hasSSEListener(specs []listenerSpec) bool {
+    for _, spec := range specs {
+        if spec.kind == "sse" || spec.kind == "dual" {
+            return true
+        }
+    }
+    return false
+}
+
+// hasAdminListener reports whether specs include an admin listener
+func hasAdminListener(specs []listenerSpec) bool {
+    for _, spec := range specs {
+        if spec.kind == "admin" {
+            return true
+        }
+    }
+    return false
+}
+
+// listenerMux returns the routes served by a listener of kind
+func listenerMux(kind string, s *server.MCPServer, c *listenerConfig) http.Handler {
+    mux := http.NewServeMux()
+    switch kind {
+    case "sse":
+        // Configure SSE options - no base path for root serving
+        opts := append(sseKeepAliveOptions(c.keepAlive), server.WithSSEContextFunc(withRequestSlot))
+        if c.publicURL != "" {
+            // Ensure public URL doesn't have trailing slash
+            opts = append(opts, server.WithBaseURL(strings.TrimRight(c.publicURL, "/")))
+        }
+
+        // Register SSE handler at root
+        sseHandler := server.NewSSEServer(s, opts...)
+        mux.Handle("/", sseConns.middleware("/sse", subscribeMiddleware(sseHandler, sseHandler)))
+        startSSEBackground(s, c)
+
+    case "http":
+        // Register HTTP handler at root
+        httpHandler := server.NewStreamableHTTPServer(s, server.WithHTTPContextFunc(withRequestSlot))
+        mux.Handle("/", httpHandler)
+
+        // Add a helpful GET handler for root
+        mux.HandleFunc("/info", func(w http.ResponseWriter, _ *http.Request) {
+            w.Header().Set("Content-Type", "application/json")
+            fmt.Fprintf(w, `{"message":"MCP HTTP server ready","instructions":"Use POST requests with JSON-RPC 2.0 payloads","example":{"jsonrpc":"2.0","method":"tools/list","id":1}}`)
+        })
+
+    case "dual":
+        // Configure SSE handler for /sse and /messages
+        sseOpts := append(sseKeepAliveOptions(c.keepAlive), server.WithSSEContextFunc(withRequestSlot))
+        if c.publicURL != "" {
+            sseOpts = append(sseOpts, server.WithBaseURL(strings.TrimRight(c.publicURL, "/")))
+        }
+        sseServer := server.NewSSEServer(s, sseOpts...)
+        sseHandler := sseConns.middleware("/sse", subscribeMiddleware(sseServer, sseServer))
+        startSSEBackground(s, c)
+
+        // Configure HTTP handler for /http
+        httpHandler := server.NewStreamableHTTPServer(s, server.WithEndpointPath("/http"), server.WithHTTPContextFunc(withRequestSlot))
+
+        // Register handlers
+        mux.Handle("/sse", sseHandler)
+        mux.Handle("/messages", sseHandler) // Support plural (backward compatibility)
+        mux.Handle("/message", sseHandler)  // Support singular (MCP Gateway compatibility)
+        mux.Handle("/http", httpHandler)
+
+        // Register REST API handlers
+        registerRESTHandlers(mux)
+
+    case "rest":
+        // Register REST API handlers
+        registerRESTHandlers(mux)
+
+    case "metrics":
+        mux.HandleFunc("/debug/vars", handleDebugVars)
+    }
+    if c.graphql && (kind == "dual" || kind == "rest") {
+        mux.Handle(graphqlPath, slowHTTPMiddleware(http.HandlerFunc(handleGraphQL)))
+    }
+
+    // Register health and version endpoints
+    registerHealthAndVersion(mux)
+    return mux
+}
+
+// startSSEBackground launches the SSE reaper and resource pusher once,
+// however many SSE listeners there are
+func startSSEBackground(s *server.MCPServer, c *listenerConfig) {
+    c.sseBackground.Do(func() {
+        if c.idleTTL > 0 {
+            c.background(func(ctx context.Context) { sseConns.runReaper(ctx, c.idleTTL) })
+        }
+        c.background(func(ctx context.Context) { runResourcePusher(ctx, s, c.pushEvery) })
+    })
+}
+
+// background runs f with c.spawn, or for good when there is no Server
+func (c *listenerConfig) background(f func(context.Context)) {
+    if c.spawn != nil {
+        c.spawn(f)
+        return
+    }
+    go f(context.Background())
+}
+
+// listenerHandler wraps the routes of a listener of kind in its middleware
+// chain
+func listenerHandler(kind string, mux http.Handler, c *listenerConfig) http.Handler {
+    handler := mux
+    switch kind {
+    case "metrics":
+        handler = loggingHTTPMiddleware(handler)
+
+    case "admin":
+        // Only the admin routes; requests for anything else are counted
+        // where they arrive
+        handler = http.NotFoundHandler()
+        if c.debug {
+            handler = debugMiddleware(c.adminTok, handler)
+        }
+        handler = adminMiddleware(c.adminTok, handler)
+
+    default:
+        if c.maxBody > 0 {
+            handler = bodyLimitMiddleware(c.maxBody, handler)
+        }
+        if c.timeout > 0 {
+            handler = timeoutMiddleware(c.timeout, handler)
+        }
+        if kind == "dual" || kind == "rest" {
+            handler = corsMiddleware(handler) // Add CORS support for REST API
+        }
+        handler = loggingHTTPMiddleware(handler)
+        if c.authOn {
+            handler = authMiddleware(c.authTok, handler)
+        }
+        switch {
+        case c.adminPort:
+            handler = requests.middleware(handler)
+        default:
+            if c.debug {
+                handler = debugMiddleware(c.adminTok, handler)
+            }
+            if c.adminTok.enabled() {
+                handler = adminMiddleware(c.adminTok, handler)
+            }
+        }
+        if c.compress {
+            handler = compressMiddleware(handler)
+        }
+    }
+    if c.acl.enabled() {
+        handler = aclMiddleware(c.acl, handler)
+    }
+    if len(c.proxies) > 0 {
+        handler = realIPMiddleware(c.proxies, handler)
+    }
+    return handler
+}
+
+// logListener logs the endpoints of a listener of kind at addr
+func logListener(kind, addr string, c *listenerConfig) {
+    url := "http://" + addr
+    if strings.HasPrefix(addr, unixAddrPrefix) {
+        url = addr
+    }
+    switch kind {
+    case "sse":
+        logAt(logInfo, "SSE server ready on %s", url)
+        logAt(logInfo, "  MCP SSE events:   /sse")
+        logAt(logInfo, "  MCP SSE messages: /messages")
+    case "http":
+        logAt(logInfo, "HTTP server ready on %s", url)
+        logAt(logInfo, "  MCP endpoint:     / (POST with JSON-RPC)")
+        logAt(logInfo, "  Info:             /info")
+    case "dual":
+        logAt(logInfo, "DUAL server ready on %s", url)
+        logAt(logInfo, "  SSE events:       /sse")
+        logAt(logInfo, "  SSE messages:     /messages (plural) and /message (singular)")
+        logAt(logInfo, "  HTTP endpoint:    /http")
+        logAt(logInfo, "  REST API:         /api/v1/*")
+        logAt(logInfo, "  API Docs:         /api/v1/docs")
+    case "rest":
+        logAt(logInfo, "REST API server ready on %s", url)
+        logAt(logInfo, "  API Base:         /api/v1")
+        logAt(logInfo, "  API Docs:         /api/v1/docs")
+        logAt(logInfo, "  OpenAPI Spec:     /api/v1/openapi.json")
+    case "metrics":
+        logAt(logInfo, "metrics server ready on %s", url)
+        logAt(logInfo, "  Runtime stats:    /debug/vars")
+    case "admin":
+        logAt(logInfo, "admin server ready on %s", url)
+        logAt(logInfo, "  Admin API:        /admin/*")
+        logAt(logInfo, "  Dashboard:        %s", dashboardPath)
+        if c.debug {
+            logAt(logInfo, "  Profiling:        /debug/pprof/*, /debug/vars")
+        }
+        return
+    }
+    if c.graphql && (kind == "dual" || kind == "rest") {
+        logAt(logInfo, "  GraphQL:          %s", graphqlPath)
+    }
+    logAt(logInfo, "  Health check:     /health")
+    logAt(logInfo, "  Probes:           /livez, /readyz")
+    logAt(logInfo, "  Version info:     /version")
+
+    if (kind == "sse" || kind == "dual") && c.publicURL != "" {
+        logAt(logInfo, "  Public URL:       %s", c.publicURL)
+    }
+    if kind == "sse" || kind == "dual" {
+        logSSESettings(c.keepAlive, c.idleTTL, c.maxSSE)
+    }
+    if c.authOn && kind != "metrics" {
+        logAt(logInfo, "  Authentication:   Bearer token required")
+    }
+
+    // Example commands
+    switch kind {
+    case "http":
+        logAt(logInfo, "Test with: curl -X POST %s/ -H 'Content-Type: application/json' -d '{\"jsonrpc\":\"2.0\",\"method\":\"tools/list\",\"id\":1}'", url)
+    case "rest":
+        logAt(logInfo, "Test commands:")
+        logAt(logInfo, "  Get time:    curl %s/api/v1/time?timezone=UTC", url)
+        logAt(logInfo, "  Stream time: curl -N %s/api/v1/time/stream?timezones=UTC", url)
+        logAt(logInfo, "  List zones:  curl %s/api/v1/timezones", url)
+        logAt(logInfo, "  Echo test:   curl %s/api/v1/test/echo", url)
+    }
+}
+
+// listenAddr listens on a host:port or unix:PATH address
+func listenAddr(addr string) (net.Listener, error) {
+    if path, ok := strings.CutPrefix(addr, unixAddrPrefix); ok {
+        return listenUnix(path)
+    }
+    return net.Listen("tcp", addr)
+}
+
+// listenUnix listens on a Unix socket readable by this user only, replacing
+// a stale socket file left by a previous run
+func listenUnix(path string) (net.Listener, error) {
+    if fi, err := os.Lstat(path); err == nil {
+        if fi.Mode()&os.ModeSocket == 0 {
+            return nil, fmt.Errorf("%s exists and is not a socket", path)
+        }
+        // Refuse to take over a socket that something still answers on
+        if conn, err := net.Dial("unix", path); err == nil {
+            conn.Close()
+            return nil, fmt.Errorf("%s is in use", path)
+        }
+        if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+            return nil, err
+        }
+    }
+    ln, err := net.Listen("unix", path)
+    if err != nil {
+        return nil, err
+    }
+    if err := os.Chmod(path, 0o600); err != nil {
+        ln.Close()
+        return nil, err
+    }
+    return ln, nil
+}
+
+// serveListeners serves every listener in specs until one of them fails.
+// A single listener keeps socket handoff for upgrades and systemd.
+func serveListeners(ctx context.Context, specs []listenerSpec, s *server.MCPServer, c *listenerConfig) error {
+    c.adminPort = hasAdminListener(specs)
+    if c.adminPort && !c.adminTok.enabled() {
+        return errors.New("an admin listener requires -admin-token (or ADMIN_TOKEN)")
+    }
+    if len(specs) == 1 && specs[0].kind == "pipe" {
+        return serveSinglePipe(ctx, specs[0], s, c)
+    }
+    logServerSettings(httpTuning)
+
+    if len(specs) == 1 {
+        spec := specs[0]
+        logListener(spec.kind, spec.addr, c)
+        handler := listenerHandler(spec.kind, listenerMux(spec.kind, s, c), c)
+        err := serveHTTP(ctx, newHTTPServer(spec.addr, handler, httpTuning))
+        if err != nil && err != http.ErrServerClosed {
+            return fmt.Errorf("%s server error: %w", strings.ToUpper(spec.kind), err)
+        }
+        return nil
+    }
+
+    // Bind every socket before serving any, so a busy port fails the start
+    listeners := make([]net.Listener, len(specs))
+    for i, spec := range specs {
+        var err error
+        if spec.kind == "pipe" {
+            listeners[i], err = listenPipe(spec.addr)
+        } else {
+            listeners[i], err = listenAddr(spec.addr)
+        }
+        if err != nil {
+            for _, ln := range listeners[:i] {
+                ln.Close()
+            }
+            return fmt.Errorf("%s listener on %s: %w", spec.kind, spec.addr, err)
+        }
+    }
+
+    // The first listener to stop, or ctx, stops the others
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+    errs := make(chan error, len(specs))
+    for i, spec := range specs {
+        ln := listeners[i]
+        if spec.kind == "pipe" {
+            logAt(logInfo, "serving via pipe transport on %s", ln.Addr())
+            go func() { errs <- servePipe(ctx, s, ln) }()
+            continue
+        }
+        logListener(spec.kind, spec.addr, c)
+        srv := newHTTPServer(spec.addr, listenerHandler(spec.kind, listenerMux(spec.kind, s, c), c), httpTuning)
+        go func() {
+            wait := shutdownOnDone(ctx, srv)
+            err := srv.Serve(ln)
+            wait()
+            if err != nil && err != http.ErrServerClosed {
+                errs <- fmt.Errorf("%s listener on %s: %w", spec.kind, spec.addr, err)
+                return
+            }
+            errs <- nil
+        }()
+    }
+    logAt(logInfo, "serving %d listeners; upgrades with SIGUSR2 need a single listener", len(specs))
+    listenerState.accepting.Store(true)
+    serverReady()
+
+    var first error
+    for range specs {
+        if err := <-errs; err != nil && first == nil {
+            first = err
+        }
+        cancel()
+    }
+    return first
+}
+
+// serveSinglePipe serves the pipe transport as the only listener
+func serveSinglePipe(ctx context.Context, spec listenerSpec, s *server.MCPServer, c *listenerConfig) error {
+    if c.authOn {
+        logAt(logWarn, "auth-token is ignored for pipe transport")
+    }
+    ln, err := listenPipe(spec.addr)
+    if err != nil {
+        return fmt.Errorf("pipe: %w", err)
+    }
+    logAt(logInfo, "serving via pipe transport on %s", ln.Addr())
+    serverReady()
+    if err := servePipe(ctx, s, ln); err != nil {
+        return fmt.Errorf("pipe server error: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,180 @@
+// -*- coding: utf-8 -*-
+// listeners_test.go - Tests for listeners and their middleware chains
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "reflect"
+    "runtime"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestParseListeners(t *testing.T) {
+    got, err := parseListeners("sse=:8080, REST=127.0.0.1:8081,metrics=127.0.0.1:9090,admin=unix:/run/fts.sock,pipe")
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []listenerSpec{
+        {"sse", ":8080"},
+        {"rest", "127.0.0.1:8081"},
+        {"metrics", "127.0.0.1:9090"},
+        {"admin", "unix:/run/fts.sock"},
+        {"pipe", ""},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("parseListeners = %+v, want %+v", got, want)
+    }
+
+    for _, bad := range []string{"", "stdio=:1", "sse", "sse=:8080,rest=:8080", "pipe,pipe"} {
+        if _, err := parseListeners(bad); err == nil {
+            t.Errorf("parseListeners(%q) should fail", bad)
+        }
+    }
+}
+
+func TestListenersFor(t *testing.T) {
+    cases := []struct {
+        listeners, transport string
+        want                 []listenerSpec
+    }{
+        {"", "stdio", nil},
+        {"", "SSE", []listenerSpec{{"sse", "0.0.0.0:8080"}}},
+        {"", "pipe", []listenerSpec{{"pipe", "/tmp/fts.sock"}}},
+        {"http=:9000", "stdio", []listenerSpec{{"http", ":9000"}}},
+    }
+    for _, c := range cases {
+        got, err := listenersFor(c.listeners, c.transport, "0.0.0.0:8080", "/tmp/fts.sock")
+        if err != nil || !reflect.DeepEqual(got, c.want) {
+            t.Errorf("listenersFor(%q, %q) = %+v, %v", c.listeners, c.transport, got, err)
+        }
+    }
+    if _, err := listenersFor("", "carrier-pigeon", "", ""); err == nil {
+        t.Error("unknown transport should fail")
+    }
+}
+
+func TestListenerTransports(t *testing.T) {
+    specs := []listenerSpec{{"dual", ":1"}, {"sse", ":2"}, {"metrics", ":3"}, {"admin", ":4"}, {"pipe", ""}}
+    if got, want := listenerTransports(specs), []string{"sse", "http", "rest", "pipe"}; !reflect.DeepEqual(got, want) {
+        t.Errorf("listenerTransports = %q, want %q", got, want)
+    }
+    if !hasSSEListener(specs) || !hasAdminListener(specs) {
+        t.Error("sse and admin listeners not found")
+    }
+    if hasSSEListener(specs[2:]) {
+        t.Error("no sse listener expected")
+    }
+}
+
+// serveListener answers one request on a listener of kind
+func serveListener(kind string, c *listenerConfig, req *http.Request) *httptest.ResponseRecorder {
+    s := server.NewMCPServer("test", "1.0")
+    rec := httptest.NewRecorder()
+    listenerHandler(kind, listenerMux(kind, s, c), c).ServeHTTP(rec, req)
+    return rec
+}
+
+func TestListenerChains(t *testing.T) {
+    admin := newBearerToken("admin-secret")
+    c := &listenerConfig{authOn: true, authTok: newBearerToken("client-secret"), adminTok: admin, debug: true, acl: &ipACL{}}
+
+    // The metrics listener answers without any token
+    if rec := serveListener("metrics", c, httptest.NewRequest("GET", "/debug/vars", nil)); rec.Code != http.StatusOK {
+        t.Errorf("metrics /debug/vars = %d", rec.Code)
+    }
+
+    // Without an admin listener the REST listener serves /admin itself
+    req := httptest.NewRequest("GET", "/admin/config", nil)
+    req.Header.Set("Authorization", "Bearer admin-secret")
+    if rec := serveListener("rest", c, req); rec.Code != http.StatusOK {
+        t.Errorf("rest /admin/config = %d", rec.Code)
+    }
+
+    // With one it does not, and the admin listener serves nothing else
+    c.adminPort = true
+    if rec := serveListener("rest", c, req); rec.Code != http.StatusUnauthorized {
+        t.Errorf("rest /admin/config with an admin listener = %d, want 401 from client auth", rec.Code)
+    }
+    if rec := serveListener("admin", c, req); rec.Code != http.StatusOK {
+        t.Errorf("admin /admin/config = %d", rec.Code)
+    }
+    if rec := serveListener("admin", c, httptest.NewRequest("GET", "/api/v1/time", nil)); rec.Code != http.StatusNotFound {
+        t.Errorf("admin /api/v1/time = %d", rec.Code)
+    }
+
+    // Client auth applies to the REST listener
+    if rec := serveListener("rest", c, httptest.NewRequest("GET", "/api/v1/time", nil)); rec.Code != http.StatusUnauthorized {
+        t.Errorf("rest without token = %d", rec.Code)
+    }
+}
+
+func TestServeListenersNeedsAdminToken(t *testing.T) {
+    c := &listenerConfig{adminTok: newBearerToken(""), acl: &ipACL{}}
+    err := serveListeners(context.Background(), []listenerSpec{{"http", "127.0.0.1:0"}, {"admin", "127.0.0.1:0"}}, server.NewMCPServer("test", "1.0"), c)
+    if err == nil || !strings.Contains(err.Error(), "admin-token") {
+        t.Errorf("serveListeners = %v", err)
+    }
+}
+
+func TestServeListenersStopsWithContext(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    c := &listenerConfig{adminTok: newBearerToken(""), acl: &ipACL{}}
+    done := make(chan error, 1)
+    go func() {
+        done <- serveListeners(ctx, []listenerSpec{{"rest", "127.0.0.1:0"}, {"metrics", "127.0.0.1:0"}}, server.NewMCPServer("test", "1.0"), c)
+    }()
+    time.Sleep(50 * time.Millisecond)
+    cancel()
+    select {
+    case err := <-done:
+        if err != nil {
+            t.Errorf("serveListeners = %v", err)
+        }
+    case <-time.After(shutdownTimeout):
+        t.Fatal("serveListeners did not return after cancel")
+    }
+}
+
+func TestListenUnix(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("stale socket files are a Unix matter")
+    }
+    path := filepath.Join(t.TempDir(), "admin.sock")
+    ln, err := listenAddr(unixAddrPrefix + path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := listenUnix(path); err == nil || !strings.Contains(err.Error(), "in use") {
+        t.Errorf("live socket: %v", err)
+    }
+    if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+        t.Errorf("socket mode: %v %v", fi, err)
+    }
+
+    // A socket nobody answers on any more is replaced
+    ln.(*net.UnixListener).SetUnlinkOnClose(false)
+    ln.Close()
+    ln, err = listenUnix(path)
+    if err != nil {
+        t.Fatalf("stale socket: %v", err)
+    }
+    ln.Close()
+
+    os.WriteFile(path, []byte("x"), 0o600)
+    if _, err := listenUnix(path); err == nil {
+        t.Error("regular file should not be replaced")
+    }
+}
@@ -0,0 +1,173 @@
+// -*- coding: utf-8 -*-
+// logfile.go - size and age rotated log files
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements rotatingFile, an append-only file that renames itself
+// to path.<timestamp> and starts over once it reaches a size or age limit.
+// It backs -log-file and the JSON audit log. Writes and rotation share one
+// lock, so no line is lost or split while the file is switched.
+//
+// For hosts that rotate with logrotate instead, SIGUSR1 makes the server
+// reopen -log-file by name (see logfile_unix.go); use "copytruncate" or a
+// postrotate "kill -USR1" and set -log-max-size=0.
+
+package fasttime
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Log file defaults
+const (
+    defaultLogMaxSize = 100 << 20 // rotate log files at 100 MiB
+    rotatedLayout     = "20060102T150405.000000000Z"
+)
+
+// rotatingFile is an io.Writer appending to path with rotation
+type rotatingFile struct {
+    mu       sync.Mutex
+    path     string
+    maxSize  int64         // 0 disables size rotation
+    maxAge   time.Duration // 0 disables age rotation
+    maxFiles int           // rotated files kept; 0 keeps all
+    f        *os.File
+    size     int64
+    opened   time.Time
+}
+
+// openRotatingFile opens path for appending, creating it if needed
+func openRotatingFile(path string, maxSize int64, maxAge time.Duration, maxFiles int) (*rotatingFile, error) {
+    rf := &rotatingFile{path: path, maxSize: maxSize, maxAge: maxAge, maxFiles: maxFiles}
+    if err := rf.open(); err != nil {
+        return nil, err
+    }
+    return rf, nil
+}
+
+// open (re)opens the file at path; the caller holds mu or owns rf
+func (rf *rotatingFile) open() error {
+    f, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+    if err != nil {
+        return err
+    }
+    fi, err := f.Stat()
+    if err != nil {
+        _ = f.Close()
+        return err
+    }
+    rf.f, rf.size, rf.opened = f, fi.Size(), time.Now()
+    return nil
+}
+
+// Write appends p, rotating first when it would pass a limit. A failed
+// rotation keeps writing to the current file rather than dropping p.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+    if rf.due(len(p)) {
+        if err := rf.rotate(); err != nil {
+            fmt.Fprintf(os.Stderr, "rotate %s: %v\n", rf.path, err)
+        }
+    }
+    n, err := rf.f.Write(p)
+    rf.size += int64(n)
+    return n, err
+}
+
+// due reports whether the file must be rotated before writing n bytes
+func (rf *rotatingFile) due(n int) bool {
+    if rf.size == 0 {
+        return false
+    }
+    if rf.maxSize > 0 && rf.size+int64(n) > rf.maxSize {
+        return true
+    }
+    return rf.maxAge > 0 && time.Since(rf.opened) >= rf.maxAge
+}
+
+// rotate renames the file to path.<timestamp>, starts a new one and drops
+// rotated files beyond maxFiles; the caller holds mu
+func (rf *rotatingFile) rotate() error {
+    if err := rf.f.Close(); err != nil {
+        return err
+    }
+    rotated := rf.path + "." + time.Now().UTC().Format(rotatedLayout)
+    if err := os.Rename(rf.path, rotated); err != nil {
+        _ = rf.open()
+        return err
+    }
+    if err := rf.open(); err != nil {
+        return err
+    }
+    if rf.maxFiles > 0 {
+        files := rf.rotatedFiles()
+        for len(files) > rf.maxFiles {
+            _ = os.Remove(files[0])
+            files = files[1:]
+        }
+    }
+    return nil
+}
+
+// Rotate rotates the file now
+func (rf *rotatingFile) Rotate() error {
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+    return rf.rotate()
+}
+
+// Reopen closes the file and opens path again, picking up a file that was
+// moved away by an external tool
+func (rf *rotatingFile) Reopen() error {
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+    _ = rf.f.Close()
+    return rf.open()
+}
+
+// rotatedFiles returns the rotated copies of the file, oldest first
+func (rf *rotatingFile) rotatedFiles() []string {
+    matches, _ := filepath.Glob(rf.path + ".*")
+    files := matches[:0]
+    for _, m := range matches {
+        if _, err := time.Parse(rotatedLayout, strings.TrimPrefix(m, rf.path+".")); err == nil {
+            files = append(files, m)
+        }
+    }
+    sort.Strings(files) // the timestamp layout sorts chronologically
+    return files
+}
+
+// removeBefore deletes rotated files last written before the cutoff and
+// returns how many were removed; the current file is kept
+func (rf *rotatingFile) removeBefore(before time.Time) (int, error) {
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+    removed := 0
+    for _, f := range rf.rotatedFiles() {
+        fi, err := os.Stat(f)
+        if err != nil || !fi.ModTime().Before(before) {
+            continue
+        }
+        if err := os.Remove(f); err != nil {
+            return removed, err
+        }
+        removed++
+    }
+    return removed, nil
+}
+
+// Close closes the file
+func (rf *rotatingFile) Close() error {
+    rf.mu.Lock()
+    defer rf.mu.Unlock()
+    return rf.f.Close()
+}
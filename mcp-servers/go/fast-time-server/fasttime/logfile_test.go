@@ -0,0 +1,128 @@
+// -*- coding: utf-8 -*-
+// logfile_test.go - Tests for rotated log files
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bufio"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+// readAllLines returns the set of lines in the file and its rotated copies
+func readAllLines(t *testing.T, rf *rotatingFile) map[string]bool {
+    t.Helper()
+    seen := make(map[string]bool)
+    for _, f := range append(rf.rotatedFiles(), rf.path) {
+        fh, err := os.Open(f)
+        if err != nil {
+            t.Fatal(err)
+        }
+        sc := bufio.NewScanner(fh)
+        for sc.Scan() {
+            if seen[sc.Text()] {
+                t.Errorf("duplicate line %q", sc.Text())
+            }
+            seen[sc.Text()] = true
+        }
+        fh.Close()
+    }
+    return seen
+}
+
+func TestRotatingFileConcurrentWrites(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "server.log")
+    rf, err := openRotatingFile(path, 2048, 0, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rf.Close()
+
+    const writers, lines = 8, 50
+    var wg sync.WaitGroup
+    for w := 0; w < writers; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < lines; i++ {
+                fmt.Fprintf(rf, "writer %d line %d\n", w, i)
+            }
+        }(w)
+    }
+    wg.Wait()
+
+    if len(rf.rotatedFiles()) == 0 {
+        t.Error("expected rotation")
+    }
+    seen := readAllLines(t, rf)
+    if len(seen) != writers*lines {
+        t.Errorf("got %d distinct lines, want %d", len(seen), writers*lines)
+    }
+    for line := range seen {
+        if !strings.HasPrefix(line, "writer ") {
+            t.Errorf("split line %q", line)
+        }
+    }
+}
+
+func TestRotatingFileMaxFilesAndAge(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "server.log")
+    rf, err := openRotatingFile(path, 0, time.Hour, 2)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rf.Close()
+
+    for i := 0; i < 4; i++ {
+        fmt.Fprintf(rf, "line %d\n", i)
+        if err := rf.Rotate(); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if n := len(rf.rotatedFiles()); n != 2 {
+        t.Errorf("kept %d rotated files, want 2", n)
+    }
+
+    // Age rotation happens on the first write after the limit
+    fmt.Fprintln(rf, "fresh")
+    rf.mu.Lock()
+    rf.opened = time.Now().Add(-2 * time.Hour)
+    rf.mu.Unlock()
+    before := rf.rotatedFiles()
+    fmt.Fprintln(rf, "after")
+    after := rf.rotatedFiles()
+    if before[len(before)-1] == after[len(after)-1] {
+        t.Error("expected an age-based rotation")
+    }
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "server.log")
+    rf, err := openRotatingFile(path, 0, 0, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rf.Close()
+
+    fmt.Fprintln(rf, "before")
+    if err := os.Rename(path, filepath.Join(dir, "server.log.old")); err != nil {
+        t.Fatal(err)
+    }
+    if err := rf.Reopen(); err != nil {
+        t.Fatal(err)
+    }
+    fmt.Fprintln(rf, "after")
+    data, _ := os.ReadFile(path)
+    if string(data) != "after\n" {
+        t.Errorf("reopened file holds %q", data)
+    }
+}
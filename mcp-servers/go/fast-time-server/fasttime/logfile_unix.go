@@ -0,0 +1,33 @@
+// -*- coding: utf-8 -*-
+// logfile_unix.go - SIGUSR1 log file reopen
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file reopens -log-file on SIGUSR1 so external rotation tools can move
+// the file away and have the server start a new one.
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "os"
+    "os/signal"
+    "syscall"
+)
+
+// watchLogReopen reopens rf each time the process receives SIGUSR1
+func watchLogReopen(rf *rotatingFile) {
+    sig := make(chan os.Signal, 1)
+    signal.Notify(sig, syscall.SIGUSR1)
+    go func() {
+        for range sig {
+            if err := rf.Reopen(); err != nil {
+                logAt(logError, "reopening log file %s: %v", rf.path, err)
+                continue
+            }
+            logAt(logInfo, "log file %s reopened", rf.path)
+        }
+    }()
+}
@@ -0,0 +1,15 @@
+// -*- coding: utf-8 -*-
+// logfile_windows.go - log file reopen stub for Windows
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Windows has no SIGUSR1; -log-file relies on its own size and age
+// rotation there.
+
+//go:build windows
+
+package fasttime
+
+// watchLogReopen is a no-op on Windows
+func watchLogReopen(_ *rotatingFile) {}
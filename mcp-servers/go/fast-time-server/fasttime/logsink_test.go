@@ -0,0 +1,121 @@
+// -*- coding: utf-8 -*-
+// logsink_test.go - Tests for the log destinations
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bytes"
+    "encoding/binary"
+    "net"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// recordingSink captures leveled log lines
+type recordingSink struct {
+    bytes.Buffer
+    lines []string
+}
+
+func (r *recordingSink) writeAt(l logLvl, msg string) error {
+    r.lines = append(r.lines, l.String()+": "+msg)
+    return nil
+}
+
+func TestLogAtUsesLeveledSink(t *testing.T) {
+    sink := &recordingSink{}
+    defer func(prev logLvl) {
+        logOutput = os.Stderr
+        setLogLevel(prev)
+    }(logLevel())
+    logOutput = sink
+    setLogLevel(logInfo)
+
+    logAt(logWarn, "disk %d%% full", 91)
+    logAt(logDebug, "not shown")
+    if len(sink.lines) != 1 || sink.lines[0] != "warn: disk 91% full" {
+        t.Errorf("lines = %q", sink.lines)
+    }
+}
+
+func TestJournaldEntry(t *testing.T) {
+    entry := journaldEntry(syslogPriority(logWarn), "fast-time-server", "two\nlines")
+    head := "PRIORITY=4\nSYSLOG_IDENTIFIER=fast-time-server\nMESSAGE\n"
+    if !bytes.HasPrefix(entry, []byte(head)) {
+        t.Fatalf("entry = %q", entry)
+    }
+    rest := entry[len(head):]
+    if n := binary.LittleEndian.Uint64(rest[:8]); n != uint64(len("two\nlines")) {
+        t.Errorf("length = %d", n)
+    }
+    if string(rest[8:]) != "two\nlines\n" {
+        t.Errorf("message = %q", rest[8:])
+    }
+}
+
+func TestJournaldSink(t *testing.T) {
+    // Unix socket paths are short; t.TempDir() can exceed the limit
+    dir, err := os.MkdirTemp("", "jd")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer os.RemoveAll(dir)
+    path := filepath.Join(dir, "socket")
+    ln, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+    if err != nil {
+        t.Skipf("unixgram not available: %v", err)
+    }
+    defer ln.Close()
+
+    sink, err := newJournaldSink(path, "fts")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer sink.Close()
+    if err := sink.writeAt(logError, "boom"); err != nil {
+        t.Fatal(err)
+    }
+
+    buf := make([]byte, 512)
+    _ = ln.SetReadDeadline(time.Now().Add(2 * time.Second))
+    n, _, err := ln.ReadFromUnix(buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.HasPrefix(string(buf[:n]), "PRIORITY=3\nSYSLOG_IDENTIFIER=fts\n") {
+        t.Errorf("received %q", buf[:n])
+    }
+}
+
+func TestOpenLogOutput(t *testing.T) {
+    if w, err := openLogOutput("", "", 0, 0, 0); err != nil || w != os.Stderr {
+        t.Errorf("default = %v, %v", w, err)
+    }
+    path := filepath.Join(t.TempDir(), "server.log")
+    w, err := openLogOutput("", path, 0, 0, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if rf, ok := w.(*rotatingFile); !ok {
+        t.Errorf("-log-file alone = %T", w)
+    } else {
+        rf.Close()
+    }
+    if _, err := openLogOutput("file", "", 0, 0, 0); err == nil {
+        t.Error("file without -log-file should fail")
+    }
+    if _, err := openLogOutput("kafka", "", 0, 0, 0); err == nil {
+        t.Error("unknown output should fail")
+    }
+    if _, err := openLogOutput("journald", "", 0, 0, 0); err == nil {
+        if _, statErr := os.Stat(journaldSocket); statErr != nil {
+            t.Error("journald without a journal socket should fail")
+        }
+    }
+}
@@ -0,0 +1,63 @@
+// -*- coding: utf-8 -*-
+// logsink_unix.go - syslog log destination
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file sends log lines to the local syslog daemon for -log-output=syslog.
+// The Windows event log is not available here.
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "log/syslog"
+    "strings"
+)
+
+// syslogSink sends log lines to syslog with their level's severity
+type syslogSink struct {
+    w *syslog.Writer
+}
+
+// newSyslogSink connects to the local syslog daemon
+func newSyslogSink(tag string) (leveledSink, error) {
+    w, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, tag)
+    if err != nil {
+        return nil, fmt.Errorf("syslog: %w", err)
+    }
+    return &syslogSink{w: w}, nil
+}
+
+// writeAt implements leveledSink
+func (s *syslogSink) writeAt(l logLvl, msg string) error {
+    switch l {
+    case logError:
+        return s.w.Err(msg)
+    case logWarn:
+        return s.w.Warning(msg)
+    case logDebug:
+        return s.w.Debug(msg)
+    default:
+        return s.w.Info(msg)
+    }
+}
+
+// Write implements io.Writer at error severity
+func (s *syslogSink) Write(p []byte) (int, error) {
+    if err := s.w.Err(strings.TrimRight(string(p), "\n")); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+// Close closes the syslog connection
+func (s *syslogSink) Close() error { return s.w.Close() }
+
+// newEventLogSink reports that the event log is unavailable
+func newEventLogSink(_ string) (leveledSink, error) {
+    return nil, errors.New("the event log is only available on Windows")
+}
@@ -0,0 +1,67 @@
+// -*- coding: utf-8 -*-
+// logsink_windows.go - event log destination and syslog stub for Windows
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Windows has no syslog daemon; -log-output=syslog is rejected there.
+// -log-output=eventlog writes to the Application event log under the source
+// registered by `fast-time-server service install`.
+
+//go:build windows
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+
+    "golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogID is the event ID of every entry; the source is registered with
+// EventCreate's message file, which accepts IDs 1 to 1000
+const eventLogID = 1
+
+// newSyslogSink reports that syslog is unavailable
+func newSyslogSink(_ string) (leveledSink, error) {
+    return nil, errors.New("syslog is not available on Windows")
+}
+
+// eventLogSink writes log lines to the Windows event log
+type eventLogSink struct {
+    l *eventlog.Log
+}
+
+// newEventLogSink opens the event log under source
+func newEventLogSink(source string) (leveledSink, error) {
+    l, err := eventlog.Open(source)
+    if err != nil {
+        return nil, fmt.Errorf("eventlog: %w", err)
+    }
+    return &eventLogSink{l: l}, nil
+}
+
+// writeAt implements leveledSink; debug lines are logged as information
+func (e *eventLogSink) writeAt(l logLvl, msg string) error {
+    switch l {
+    case logError:
+        return e.l.Error(eventLogID, msg)
+    case logWarn:
+        return e.l.Warning(eventLogID, msg)
+    default:
+        return e.l.Info(eventLogID, msg)
+    }
+}
+
+// Write implements io.Writer as an error entry
+func (e *eventLogSink) Write(p []byte) (int, error) {
+    if err := e.l.Error(eventLogID, strings.TrimRight(string(p), "\n")); err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+// Close closes the event log handle
+func (e *eventLogSink) Close() error { return e.l.Close() }
@@ -0,0 +1,490 @@
+// -*- coding: utf-8 -*-
+// markets.go - exchange trading hours for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements the market hours subsystem: a built-in calendar of
+// major exchanges (NYSE, NASDAQ, LSE, TSE, HKEX) with regular sessions, lunch
+// breaks, early closes and holiday closures, exposed through the market_hours
+// tool and the markets:// resources. Holiday calendars are bundled for the
+// years listed in marketCalendarYears; outside them only weekends are known.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// marketLookaheadDays bounds the search for the next open or close
+const marketLookaheadDays = 14
+
+// marketCalendarYears lists the years covered by the bundled holiday calendars
+var marketCalendarYears = []int{2025, 2026}
+
+// marketSession is a trading session in local minutes since midnight
+type marketSession struct {
+    open, close int
+}
+
+// exchange describes one market's trading calendar
+type exchange struct {
+    code     string
+    name     string
+    tz       string
+    sessions []marketSession   // regular sessions; more than one means a lunch break
+    holidays map[string]string // local date -> holiday name
+    halfDays map[string]int    // local date -> early close in minutes since midnight
+}
+
+var (
+    usMarketHolidays = map[string]string{
+        "2025-01-01": "New Year's Day",
+        "2025-01-09": "National Day of Mourning for President Carter",
+        "2025-01-20": "Martin Luther King Jr. Day",
+        "2025-02-17": "Washington's Birthday",
+        "2025-04-18": "Good Friday",
+        "2025-05-26": "Memorial Day",
+        "2025-06-19": "Juneteenth",
+        "2025-07-04": "Independence Day",
+        "2025-09-01": "Labor Day",
+        "2025-11-27": "Thanksgiving Day",
+        "2025-12-25": "Christmas Day",
+        "2026-01-01": "New Year's Day",
+        "2026-01-19": "Martin Luther King Jr. Day",
+        "2026-02-16": "Washington's Birthday",
+        "2026-04-03": "Good Friday",
+        "2026-05-25": "Memorial Day",
+        "2026-06-19": "Juneteenth",
+        "2026-07-03": "Independence Day (observed)",
+        "2026-09-07": "Labor Day",
+        "2026-11-26": "Thanksgiving Day",
+        "2026-12-25": "Christmas Day",
+    }
+    usMarketHalfDays = map[string]int{
+        "2025-07-03": 13 * 60,
+        "2025-11-28": 13 * 60,
+        "2025-12-24": 13 * 60,
+        "2026-11-27": 13 * 60,
+        "2026-12-24": 13 * 60,
+    }
+)
+
+// exchanges is the built-in exchange calendar, keyed by code
+var exchanges = map[string]*exchange{
+    "NYSE": {
+        code:     "NYSE",
+        name:     "New York Stock Exchange",
+        tz:       "America/New_York",
+        sessions: []marketSession{{9*60 + 30, 16 * 60}},
+        holidays: usMarketHolidays,
+        halfDays: usMarketHalfDays,
+    },
+    "NASDAQ": {
+        code:     "NASDAQ",
+        name:     "Nasdaq Stock Market",
+        tz:       "America/New_York",
+        sessions: []marketSession{{9*60 + 30, 16 * 60}},
+        holidays: usMarketHolidays,
+        halfDays: usMarketHalfDays,
+    },
+    "LSE": {
+        code:     "LSE",
+        name:     "London Stock Exchange",
+        tz:       "Europe/London",
+        sessions: []marketSession{{8 * 60, 16*60 + 30}},
+        holidays: map[string]string{
+            "2025-01-01": "New Year's Day",
+            "2025-04-18": "Good Friday",
+            "2025-04-21": "Easter Monday",
+            "2025-05-05": "Early May Bank Holiday",
+            "2025-05-26": "Spring Bank Holiday",
+            "2025-08-25": "Summer Bank Holiday",
+            "2025-12-25": "Christmas Day",
+            "2025-12-26": "Boxing Day",
+            "2026-01-01": "New Year's Day",
+            "2026-04-03": "Good Friday",
+            "2026-04-06": "Easter Monday",
+            "2026-05-04": "Early May Bank Holiday",
+            "2026-05-25": "Spring Bank Holiday",
+            "2026-08-31": "Summer Bank Holiday",
+            "2026-12-25": "Christmas Day",
+            "2026-12-28": "Boxing Day (substitute)",
+        },
+        halfDays: map[string]int{
+            "2025-12-24": 12*60 + 30,
+            "2025-12-31": 12*60 + 30,
+            "2026-12-24": 12*60 + 30,
+            "2026-12-31": 12*60 + 30,
+        },
+    },
+    "TSE": {
+        code:     "TSE",
+        name:     "Tokyo Stock Exchange",
+        tz:       "Asia/Tokyo",
+        sessions: []marketSession{{9 * 60, 11*60 + 30}, {12*60 + 30, 15*60 + 30}},
+        holidays: map[string]string{
+            "2025-01-01": "New Year's Day",
+            "2025-01-02": "Market Holiday",
+            "2025-01-03": "Market Holiday",
+            "2025-01-13": "Coming of Age Day",
+            "2025-02-11": "National Foundation Day",
+            "2025-02-24": "Emperor's Birthday (observed)",
+            "2025-03-20": "Vernal Equinox Day",
+            "2025-04-29": "Showa Day",
+            "2025-05-05": "Children's Day",
+            "2025-05-06": "Greenery Day (observed)",
+            "2025-07-21": "Marine Day",
+            "2025-08-11": "Mountain Day",
+            "2025-09-15": "Respect for the Aged Day",
+            "2025-09-23": "Autumnal Equinox Day",
+            "2025-10-13": "Sports Day",
+            "2025-11-03": "Culture Day",
+            "2025-11-24": "Labor Thanksgiving Day (observed)",
+            "2025-12-31": "Market Holiday",
+            "2026-01-01": "New Year's Day",
+            "2026-01-02": "Market Holiday",
+            "2026-01-12": "Coming of Age Day",
+            "2026-02-11": "National Foundation Day",
+            "2026-02-23": "Emperor's Birthday",
+            "2026-03-20": "Vernal Equinox Day",
+            "2026-04-29": "Showa Day",
+            "2026-05-04": "Greenery Day",
+            "2026-05-05": "Children's Day",
+            "2026-05-06": "Constitution Day (observed)",
+            "2026-07-20": "Marine Day",
+            "2026-08-11": "Mountain Day",
+            "2026-09-21": "Respect for the Aged Day",
+            "2026-09-22": "Citizens' Holiday",
+            "2026-09-23": "Autumnal Equinox Day",
+            "2026-10-12": "Sports Day",
+            "2026-11-03": "Culture Day",
+            "2026-11-23": "Labor Thanksgiving Day",
+            "2026-12-31": "Market Holiday",
+        },
+        halfDays: map[string]int{},
+    },
+    "HKEX": {
+        code:     "HKEX",
+        name:     "Hong Kong Stock Exchange",
+        tz:       "Asia/Hong_Kong",
+        sessions: []marketSession{{9*60 + 30, 12 * 60}, {13 * 60, 16 * 60}},
+        holidays: map[string]string{
+            "2025-01-01": "New Year's Day",
+            "2025-01-29": "Lunar New Year",
+            "2025-01-30": "Lunar New Year",
+            "2025-01-31": "Lunar New Year",
+            "2025-04-04": "Ching Ming Festival",
+            "2025-04-18": "Good Friday",
+            "2025-04-21": "Easter Monday",
+            "2025-05-01": "Labour Day",
+            "2025-05-05": "Buddha's Birthday",
+            "2025-07-01": "HKSAR Establishment Day",
+            "2025-10-01": "National Day",
+            "2025-10-07": "Day after Mid-Autumn Festival",
+            "2025-10-29": "Chung Yeung Festival",
+            "2025-12-25": "Christmas Day",
+            "2025-12-26": "Boxing Day",
+            "2026-01-01": "New Year's Day",
+            "2026-02-17": "Lunar New Year",
+            "2026-02-18": "Lunar New Year",
+            "2026-02-19": "Lunar New Year",
+            "2026-04-03": "Good Friday",
+            "2026-04-06": "Ching Ming Festival (observed)",
+            "2026-04-07": "Easter Monday (observed)",
+            "2026-05-01": "Labour Day",
+            "2026-05-25": "Buddha's Birthday (observed)",
+            "2026-06-19": "Tuen Ng Festival",
+            "2026-07-01": "HKSAR Establishment Day",
+            "2026-10-01": "National Day",
+            "2026-10-19": "Chung Yeung Festival (observed)",
+            "2026-12-25": "Christmas Day",
+        },
+        halfDays: map[string]int{
+            "2025-01-28": 12 * 60,
+            "2025-12-24": 12 * 60,
+            "2025-12-31": 12 * 60,
+            "2026-02-16": 12 * 60,
+            "2026-12-24": 12 * 60,
+            "2026-12-31": 12 * 60,
+        },
+    },
+}
+
+// exchangeCodes returns the supported exchange codes in sorted order
+func exchangeCodes() []string {
+    codes := make([]string, 0, len(exchanges))
+    for c := range exchanges {
+        codes = append(codes, c)
+    }
+    sort.Strings(codes)
+    return codes
+}
+
+// findExchange looks up an exchange by code, case-insensitively
+func findExchange(code string) (*exchange, error) {
+    if ex, ok := exchanges[strings.ToUpper(strings.TrimSpace(code))]; ok {
+        return ex, nil
+    }
+    return nil, fmt.Errorf("unknown market %q (supported: %s)", code, strings.Join(exchangeCodes(), ", "))
+}
+
+// calendarCovers reports whether the bundled holiday calendars include year
+func calendarCovers(year int) bool {
+    for _, y := range marketCalendarYears {
+        if y == year {
+            return true
+        }
+    }
+    return false
+}
+
+// daySessions returns the trading intervals for the local date of day, and
+// the holiday name when the market is closed for one
+func (ex *exchange) daySessions(day time.Time) ([]interval, string) {
+    if isWeekend(day.Weekday()) {
+        return nil, ""
+    }
+    key := day.Format("2006-01-02")
+    if name, ok := ex.holidays[key]; ok {
+        return nil, name
+    }
+
+    earlyClose, half := ex.halfDays[key]
+    y, mo, d := day.Date()
+    var out []interval
+    for _, s := range ex.sessions {
+        closeMin := s.close
+        if half && earlyClose < closeMin {
+            closeMin = earlyClose
+        }
+        if closeMin <= s.open {
+            continue
+        }
+        out = append(out, interval{
+            time.Date(y, mo, d, 0, s.open, 0, 0, day.Location()),
+            time.Date(y, mo, d, 0, closeMin, 0, 0, day.Location()),
+        })
+    }
+    return out, ""
+}
+
+// status reports whether the exchange is open at t and when it next opens or closes
+func (ex *exchange) status(t time.Time) (map[string]interface{}, error) {
+    loc, err := loadLocation(ex.tz)
+    if err != nil {
+        return nil, err
+    }
+    local := t.In(loc)
+    todayKey := local.Format("2006-01-02")
+    today, holiday := ex.daySessions(local)
+
+    result := map[string]interface{}{
+        "market":     ex.code,
+        "name":       ex.name,
+        "timezone":   ex.tz,
+        "local_time": local.Format(time.RFC3339),
+        "open":       false,
+        "half_day":   len(today) > 0 && ex.halfDays[todayKey] > 0,
+    }
+
+    var current *interval
+    for i := range today {
+        if !local.Before(today[i].start) && local.Before(today[i].end) {
+            current = &today[i]
+        }
+    }
+
+    switch {
+    case current != nil:
+        result["open"] = true
+        result["status"] = "open"
+        result["next_close"] = current.end.Format(time.RFC3339)
+    case holiday != "":
+        result["status"] = "holiday"
+        result["holiday"] = holiday
+    case isWeekend(local.Weekday()):
+        result["status"] = "weekend"
+    case len(today) > 0 && local.Before(today[0].start):
+        result["status"] = "pre_open"
+    case len(today) > 0 && local.Before(today[len(today)-1].end):
+        result["status"] = "lunch_break"
+    default:
+        result["status"] = "closed"
+    }
+
+    // Find the next session start after t
+    y, mo, d := local.Date()
+    for i := 0; i <= marketLookaheadDays; i++ {
+        sessions, _ := ex.daySessions(time.Date(y, mo, d+i, 12, 0, 0, 0, loc))
+        for _, s := range sessions {
+            if s.start.After(local) {
+                result["next_open"] = s.start.Format(time.RFC3339)
+                i = marketLookaheadDays
+                break
+            }
+        }
+    }
+
+    if len(today) > 0 {
+        hours := make([]string, len(today))
+        for i, s := range today {
+            hours[i] = s.start.Format("15:04") + "-" + s.end.Format("15:04")
+        }
+        result["today_sessions"] = hours
+    }
+    if !calendarCovers(local.Year()) {
+        result["warning"] = fmt.Sprintf("holiday calendar not available for %d; only weekends are considered", local.Year())
+    }
+    return result, nil
+}
+
+// describe returns the static calendar data for an exchange
+func (ex *exchange) describe() map[string]interface{} {
+    sessions := make([]string, len(ex.sessions))
+    for i, s := range ex.sessions {
+        sessions[i] = fmt.Sprintf("%02d:%02d-%02d:%02d", s.open/60, s.open%60, s.close/60, s.close%60)
+    }
+    halfDays := make(map[string]string, len(ex.halfDays))
+    for d, m := range ex.halfDays {
+        halfDays[d] = fmt.Sprintf("%02d:%02d", m/60, m%60)
+    }
+    return map[string]interface{}{
+        "code":           ex.code,
+        "name":           ex.name,
+        "timezone":       ex.tz,
+        "sessions":       sessions,
+        "trading_days":   []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+        "holidays":       ex.holidays,
+        "early_closes":   halfDays,
+        "calendar_years": marketCalendarYears,
+    }
+}
+
+// handleMarketHours reports whether one or all markets are open at a time
+func handleMarketHours(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    t, err := timeArgIn(req, time.UTC)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    codes := exchangeCodes()
+    if market := req.GetString("market", ""); market != "" {
+        ex, err := findExchange(market)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        codes = []string{ex.code}
+    }
+
+    markets := make([]map[string]interface{}, 0, len(codes))
+    for _, c := range codes {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+        st, err := exchanges[c].status(t)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        markets = append(markets, st)
+    }
+
+    logAt(logInfo, "market_hours: %s at %s", strings.Join(codes, ","), t.UTC().Format(time.RFC3339))
+    if len(markets) == 1 {
+        return toolResultJSON(markets[0])
+    }
+    return toolResultJSON(map[string]interface{}{
+        "time":    t.UTC().Format(time.RFC3339),
+        "markets": markets,
+    })
+}
+
+// handleMarketsResource returns the calendar of every supported exchange
+func handleMarketsResource(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    list := make([]map[string]interface{}, 0, len(exchanges))
+    for _, c := range exchangeCodes() {
+        list = append(list, exchanges[c].describe())
+    }
+
+    jsonData, err := json.Marshal(map[string]interface{}{"exchanges": list})
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal market data: %w", err)
+    }
+
+    logAt(logInfo, "resource: markets requested")
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      "markets://exchanges",
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
+
+// handleMarketResource returns one exchange's calendar and current status
+func handleMarketResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    // Template variables arrive as []string
+    var code string
+    if vs, ok := req.Params.Arguments["code"].([]string); ok && len(vs) > 0 {
+        code = vs[0]
+    }
+    ex, err := findExchange(code)
+    if err != nil {
+        return nil, err
+    }
+
+    data := ex.describe()
+    if data["status"], err = ex.status(currentTime()); err != nil {
+        return nil, err
+    }
+    jsonData, err := json.Marshal(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal market data: %w", err)
+    }
+
+    logAt(logInfo, "resource: market %s requested", ex.code)
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      req.Params.URI,
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
+
+// registerMarketTools adds the market_hours tool and markets:// resources to the server
+func registerMarketTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("market_hours",
+        mcp.WithDescription("Check whether major exchanges (NYSE, NASDAQ, LSE, TSE, HKEX) are open at a time, including half-days, lunch breaks and holidays, with the next open/close"),
+        mcp.WithTitleAnnotation("Market Hours"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("market",
+            mcp.Description("Exchange code. Omit to report every supported market"),
+            mcp.Enum("NYSE", "NASDAQ", "LSE", "TSE", "HKEX"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time to check in RFC3339 (times without an offset are UTC). Defaults to now"),
+        ),
+    ), handleMarketHours)
+
+    s.AddResource(mcp.NewResource("markets://exchanges", "Exchange Calendars",
+        mcp.WithResourceDescription("Trading sessions, early closes and holiday closures for NYSE, NASDAQ, LSE, TSE and HKEX"),
+        mcp.WithMIMEType("application/json"),
+    ), cancellableResource(handleMarketsResource))
+
+    s.AddResourceTemplate(mcp.NewResourceTemplate("markets://exchanges/{code}", "Exchange Calendar",
+        mcp.WithTemplateDescription("Calendar and current open/closed status for one exchange"),
+        mcp.WithTemplateMIMEType("application/json"),
+    ), cancellableResourceTemplate(handleMarketResource))
+}
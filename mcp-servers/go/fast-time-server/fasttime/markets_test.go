@@ -0,0 +1,90 @@
+// -*- coding: utf-8 -*-
+// markets_test.go - Tests for exchange trading hours
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestExchangeStatus(t *testing.T) {
+    tests := []struct {
+        market, utc string
+        wantStatus  string
+        wantOpen    bool
+        wantNext    string // next_open or next_close depending on wantOpen
+    }{
+        {"NYSE", "2025-07-07T14:00:00Z", "open", true, "2025-07-07T16:00:00-04:00"},
+        {"NYSE", "2025-07-07T12:00:00Z", "pre_open", false, "2025-07-07T09:30:00-04:00"},
+        {"NYSE", "2025-07-04T15:00:00Z", "holiday", false, "2025-07-07T09:30:00-04:00"},
+        {"NYSE", "2025-07-03T16:30:00Z", "open", true, "2025-07-03T13:00:00-04:00"},
+        {"NASDAQ", "2025-07-05T15:00:00Z", "weekend", false, "2025-07-07T09:30:00-04:00"},
+        {"TSE", "2025-07-07T03:00:00Z", "lunch_break", false, "2025-07-07T12:30:00+09:00"},
+        {"HKEX", "2025-12-24T05:00:00Z", "closed", false, "2025-12-29T09:30:00+08:00"},
+        {"LSE", "2025-07-07T16:00:00Z", "closed", false, "2025-07-08T08:00:00+01:00"},
+    }
+    for _, tt := range tests {
+        ex, err := findExchange(tt.market)
+        if err != nil {
+            t.Fatal(err)
+        }
+        at, _ := time.Parse(time.RFC3339, tt.utc)
+        st, err := ex.status(at)
+        if err != nil {
+            t.Fatal(err)
+        }
+        key := "next_open"
+        if tt.wantOpen {
+            key = "next_close"
+        }
+        if st["status"] != tt.wantStatus || st["open"] != tt.wantOpen || st[key] != tt.wantNext {
+            t.Errorf("%s at %s: got status=%v open=%v %s=%v", tt.market, tt.utc, st["status"], st["open"], key, st[key])
+        }
+    }
+}
+
+func TestHandleMarketHours(t *testing.T) {
+    res, err := handleMarketHours(context.Background(), testRequest("market_hours", map[string]any{"time": "2025-07-07T14:00:00Z"}))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        Markets []map[string]interface{} `json:"markets"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if len(body.Markets) != len(exchanges) {
+        t.Errorf("want %d markets, got %d", len(exchanges), len(body.Markets))
+    }
+
+    res, _ = handleMarketHours(context.Background(), testRequest("market_hours", map[string]any{"market": "ASX"}))
+    if !res.IsError {
+        t.Error("expected error result for unknown market")
+    }
+}
+
+func TestHandleMarketResource(t *testing.T) {
+    req := mcp.ReadResourceRequest{}
+    req.Params.URI = "markets://exchanges/lse"
+    req.Params.Arguments = map[string]any{"code": []string{"lse"}}
+    contents, err := handleMarketResource(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body map[string]interface{}
+    if err := json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &body); err != nil {
+        t.Fatalf("resource is not JSON: %v", err)
+    }
+    if body["code"] != "LSE" || body["status"] == nil {
+        t.Errorf("unexpected resource body %v", body)
+    }
+}
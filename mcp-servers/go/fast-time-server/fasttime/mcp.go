@@ -0,0 +1,1689 @@
+// -*- coding: utf-8 -*-
+// mcp.go - the MCP server, its core tools and logging
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+// Authors: Mihai Criveti, Manav Gupta
+//
+// This file builds the MCP server with every tool, resource and prompt
+// registered, implements get_system_time and convert_time with their
+// resources and prompts, and holds the leveled logger, the timezone cache
+// and the HTTP middleware shared by all transports.
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "log"
+    "math"
+    "net"
+    "net/http"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+/* ------------------------------------------------------------------ */
+/*                             constants                              */
+/* ------------------------------------------------------------------ */
+
+const (
+    appName = "fast-time-server"
+
+    // Default values
+    defaultPort     = 8080
+    defaultListen   = "0.0.0.0"
+    defaultLogLevel = "info"
+
+    // Environment variables
+    envAuthToken    = "AUTH_TOKEN"
+    envAdminToken   = "ADMIN_TOKEN"
+    envEnableTools  = "ENABLE_TOOLS"
+    envDisableTools = "DISABLE_TOOLS"
+)
+
+/* ------------------------------------------------------------------ */
+/*                             logging                                */
+/* ------------------------------------------------------------------ */
+
+// logLvl represents logging verbosity levels
+type logLvl int
+
+const (
+    logNone logLvl = iota
+    logError
+    logWarn
+    logInfo
+    logDebug
+)
+
+var (
+    curLvl    atomic.Int32             // current logLvl; may change at runtime via /admin/log-level
+    logOutput io.Writer    = os.Stderr // where log lines go unless the level is "none"
+    logger                 = log.New(os.Stderr, "", log.LstdFlags)
+)
+
+func init() {
+    curLvl.Store(int32(logInfo))
+}
+
+// lookupLvl converts a string log level to logLvl type
+func lookupLvl(s string) (logLvl, bool) {
+    switch strings.ToLower(s) {
+    case "debug":
+        return logDebug, true
+    case "info":
+        return logInfo, true
+    case "warn", "warning":
+        return logWarn, true
+    case "error":
+        return logError, true
+    case "none", "off", "silent":
+        return logNone, true
+    default:
+        return logInfo, false
+    }
+}
+
+// parseLvl converts a string log level to logLvl type, defaulting to info
+func parseLvl(s string) logLvl {
+    l, _ := lookupLvl(s)
+    return l
+}
+
+// String returns the canonical name of a log level
+func (l logLvl) String() string {
+    switch l {
+    case logDebug:
+        return "debug"
+    case logInfo:
+        return "info"
+    case logWarn:
+        return "warn"
+    case logError:
+        return "error"
+    default:
+        return "none"
+    }
+}
+
+// logLevel returns the current log level
+func logLevel() logLvl {
+    return logLvl(curLvl.Load())
+}
+
+// setLogLevel changes the log level, silencing the logger for "none"
+func setLogLevel(l logLvl) {
+    curLvl.Store(int32(l))
+    if l == logNone {
+        logger.SetOutput(io.Discard)
+    } else {
+        logger.SetOutput(logOutput)
+    }
+}
+
+// logAt logs a message if the current log level permits
+func logAt(l logLvl, f string, v ...any) {
+    if logLevel() >= l {
+        if sink, ok := logOutput.(leveledSink); ok {
+            _ = sink.writeAt(l, fmt.Sprintf(f, v...))
+            return
+        }
+        logger.Printf(f, v...)
+    }
+}
+
+/* ------------------------------------------------------------------ */
+/*                    version / health helpers                        */
+/* ------------------------------------------------------------------ */
+
+// healthJSON returns server health status as JSON
+func healthJSON() string {
+    conns, listeners := httpConns.stats()
+    perListener, _ := json.Marshal(listeners)
+    return fmt.Sprintf(`{"status":"healthy","uptime_seconds":%d,"sse_connections":{"active":%d,"total":%d,"reaped":%d,"rejected":%d},"http_connections":{"open":%d,"accepted":%d,"requests":%d,"rejected":%d,"listeners":%s}}`,
+        int(time.Since(startTime).Seconds()),
+        sseConns.active(), sseConns.total.Load(), sseConns.reaped.Load(), sseConns.rejected.Load(),
+        conns.Open, conns.Accepted, conns.Requests, conns.Rejected, perListener)
+}
+
+var startTime = time.Now()
+
+/* ------------------------------------------------------------------ */
+/*                         timezone cache                             */
+/* ------------------------------------------------------------------ */
+
+// loadLocation loads a timezone location, using cache when possible
+func loadLocation(name string) (*time.Location, error) {
+    // Resolve operator-defined aliases such as "HQ"
+    name = tzAliases.resolve(name)
+
+    // Check cache first; entries from a replaced tzdata bundle are stale
+    bundle := tzdata.active()
+    if e, ok := tzCache.get(name, bundle); ok {
+        return e.loc, e.err
+    }
+
+    // Map legacy, Windows and UTC-offset names to their IANA zone
+    canon, loc, err := canonicalZone(name)
+    if err != nil {
+        return nil, cacheLocation(name, tzCacheEntry{err: errInvalidTimezone("", name, err), bundle: bundle})
+    }
+
+    // Load from -tzdata-dir or the system
+    if loc == nil {
+        if loc, err = loadZone(canon); err != nil {
+            return nil, cacheLocation(name, tzCacheEntry{err: errInvalidTimezone("", name, err), bundle: bundle})
+        }
+    } else {
+        canon = ""
+    }
+
+    // Cache for future use
+    cacheLocation(name, tzCacheEntry{loc: loc, zone: canon, bundle: bundle})
+    return loc, nil
+}
+
+// cacheLocation stores e for name and returns its error
+func cacheLocation(name string, e tzCacheEntry) error {
+    tzCache.put(name, e)
+    return e.err
+}
+
+/* ------------------------------------------------------------------ */
+/*                          time parsing                              */
+/* ------------------------------------------------------------------ */
+
+// inputTimeLayouts lists the layouts accepted for time arguments, in order
+var inputTimeLayouts = []string{
+    time.RFC3339,
+    "2006-01-02 15:04:05",
+    "2006-01-02T15:04:05",
+    "2006-01-02",
+}
+
+// parseTimeInLocation parses a time string, interpreting layouts without an
+// explicit offset in loc
+func parseTimeInLocation(value string, loc *time.Location) (time.Time, error) {
+    var err error
+    for _, layout := range inputTimeLayouts {
+        var t time.Time
+        if t, err = time.ParseInLocation(layout, value, loc); err == nil {
+            return t, nil
+        }
+    }
+    return time.Time{}, err
+}
+
+// timeArgIn parses the optional "time" argument in loc, defaulting to now
+func timeArgIn(req mcp.CallToolRequest, loc *time.Location) (time.Time, error) {
+    timeStr := req.GetString("time", "")
+    if timeStr == "" {
+        return currentTime().In(loc), nil
+    }
+    t, err := parseTimeInLocation(timeStr, loc)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("invalid time format: %v", err)
+    }
+    return t.In(loc), nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                       resource handlers                            */
+/* ------------------------------------------------------------------ */
+
+// handleTimezoneInfo returns comprehensive timezone information
+func handleTimezoneInfo(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    data := map[string]interface{}{
+        "timezones": []map[string]interface{}{
+            {
+                "id":           "America/New_York",
+                "name":         "Eastern Time",
+                "offset":       "-05:00",
+                "dst":          true,
+                "abbreviation": "EST/EDT",
+                "major_cities": []string{"New York", "Toronto", "Montreal"},
+                "population":   141000000,
+            },
+            {
+                "id":           "America/Chicago",
+                "name":         "Central Time",
+                "offset":       "-06:00",
+                "dst":          true,
+                "abbreviation": "CST/CDT",
+                "major_cities": []string{"Chicago", "Houston", "Mexico City"},
+                "population":   110000000,
+            },
+            {
+                "id":           "America/Denver",
+                "name":         "Mountain Time",
+                "offset":       "-07:00",
+                "dst":          true,
+                "abbreviation": "MST/MDT",
+                "major_cities": []string{"Denver", "Phoenix", "Calgary"},
+                "population":   35000000,
+            },
+            {
+                "id":           "America/Los_Angeles",
+                "name":         "Pacific Time",
+                "offset":       "-08:00",
+                "dst":          true,
+                "abbreviation": "PST/PDT",
+                "major_cities": []string{"Los Angeles", "San Francisco", "Seattle"},
+                "population":   53000000,
+            },
+            {
+                "id":           "Europe/London",
+                "name":         "Greenwich Mean Time",
+                "offset":       "+00:00",
+                "dst":          true,
+                "abbreviation": "GMT/BST",
+                "major_cities": []string{"London", "Dublin", "Lisbon"},
+                "population":   67000000,
+            },
+            {
+                "id":           "Europe/Paris",
+                "name":         "Central European Time",
+                "offset":       "+01:00",
+                "dst":          true,
+                "abbreviation": "CET/CEST",
+                "major_cities": []string{"Paris", "Madrid", "Rome"},
+                "population":   250000000,
+            },
+            {
+                "id":           "Europe/Moscow",
+                "name":         "Moscow Time",
+                "offset":       "+03:00",
+                "dst":          false,
+                "abbreviation": "MSK",
+                "major_cities": []string{"Moscow", "Istanbul", "Nairobi"},
+                "population":   250000000,
+            },
+            {
+                "id":           "Asia/Dubai",
+                "name":         "Gulf Standard Time",
+                "offset":       "+04:00",
+                "dst":          false,
+                "abbreviation": "GST",
+                "major_cities": []string{"Dubai", "Abu Dhabi", "Muscat"},
+                "population":   65000000,
+            },
+            {
+                "id":           "Asia/Shanghai",
+                "name":         "China Standard Time",
+                "offset":       "+08:00",
+                "dst":          false,
+                "abbreviation": "CST",
+                "major_cities": []string{"Shanghai", "Beijing", "Hong Kong"},
+                "population":   1400000000,
+            },
+            {
+                "id":           "Asia/Tokyo",
+                "name":         "Japan Standard Time",
+                "offset":       "+09:00",
+                "dst":          false,
+                "abbreviation": "JST",
+                "major_cities": []string{"Tokyo", "Osaka", "Yokohama"},
+                "population":   127000000,
+            },
+            {
+                "id":           "Australia/Sydney",
+                "name":         "Australian Eastern Time",
+                "offset":       "+10:00",
+                "dst":          true,
+                "abbreviation": "AEST/AEDT",
+                "major_cities": []string{"Sydney", "Melbourne", "Brisbane"},
+                "population":   25000000,
+            },
+        },
+        "timezone_groups": map[string][]string{
+            "us_timezones":     []string{"America/New_York", "America/Chicago", "America/Denver", "America/Los_Angeles"},
+            "europe_timezones": []string{"Europe/London", "Europe/Paris", "Europe/Berlin", "Europe/Moscow"},
+            "asia_timezones":   []string{"Asia/Tokyo", "Asia/Shanghai", "Asia/Singapore", "Asia/Dubai"},
+        },
+    }
+
+    // Offset and DST status change through the year, so fill them in live
+    now := currentTime()
+    for _, zone := range data["timezones"].([]map[string]interface{}) {
+        loc, err := loadLocation(zone["id"].(string))
+        if err != nil {
+            continue
+        }
+        t := now.In(loc)
+        abbr, offset := t.Zone()
+        zone["offset"] = formatOffset(offset)
+        zone["dst"] = t.IsDST()
+        zone["observes_dst"] = observesDST(t)
+        zone["current_abbreviation"] = abbr
+    }
+
+    jsonData, err := json.Marshal(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal timezone data: %w", err)
+    }
+
+    logAt(logInfo, "resource: timezone info requested")
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      "timezone://info",
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
+
+// worldCities are the cities shown by time://current/world and the
+// dashboard; -cities replaces them (cities.go)
+var worldCities = map[string]string{
+    "New York":    "America/New_York",
+    "Los Angeles": "America/Los_Angeles",
+    "London":      "Europe/London",
+    "Paris":       "Europe/Paris",
+    "Tokyo":       "Asia/Tokyo",
+    "Sydney":      "Australia/Sydney",
+    "Dubai":       "Asia/Dubai",
+    "Singapore":   "Asia/Singapore",
+    "Mumbai":      "Asia/Kolkata",
+    "Hong Kong":   "Asia/Hong_Kong",
+}
+
+// handleCurrentWorldTimes returns current time in major cities
+func handleCurrentWorldTimes(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    times := make(map[string]string)
+    now := currentTime()
+
+    for city, tz := range worldCities {
+        loc, err := loadLocation(tz)
+        if err != nil {
+            times[city] = "Error loading timezone"
+            continue
+        }
+        localTime := now.In(loc)
+        times[city] = localTime.Format("2006-01-02 15:04:05 MST")
+    }
+
+    data := map[string]interface{}{
+        "last_updated": now.UTC().Format(time.RFC3339),
+        "times":        times,
+    }
+
+    jsonData, err := json.Marshal(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal world times: %w", err)
+    }
+
+    logAt(logInfo, "resource: current world times requested")
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      "time://current/world",
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
+
+// handleTimeFormats returns examples of supported time formats
+func handleTimeFormats(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    data := map[string]interface{}{
+        "input_formats": []string{
+            "2006-01-02 15:04:05",
+            "2006-01-02T15:04:05Z",
+            "2006-01-02T15:04:05-07:00",
+            "Jan 2, 2006 3:04 PM",
+            "Monday, January 2, 2006",
+            "02/01/2006 15:04",
+        },
+        "output_formats": map[string]string{
+            "iso8601":        "2006-01-02T15:04:05Z07:00",
+            "rfc3339":        "2006-01-02T15:04:05Z",
+            "rfc822":         "Mon, 02 Jan 2006 15:04:05 MST",
+            "unix":           "1136214245",
+            "human_readable": "Monday, January 2, 2006 at 3:04 PM",
+            "short":          "1/2/06 3:04 PM",
+        },
+        "examples": []map[string]string{
+            {
+                "format":      "ISO 8601",
+                "example":     "2024-01-15T14:30:00-05:00",
+                "description": "Standard international format with timezone",
+            },
+            {
+                "format":      "Unix Timestamp",
+                "example":     "1705339800",
+                "description": "Seconds since January 1, 1970 UTC",
+            },
+            {
+                "format":      "RFC 3339",
+                "example":     "2024-01-15T14:30:00Z",
+                "description": "Internet standard format",
+            },
+        },
+    }
+
+    jsonData, err := json.Marshal(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal format data: %w", err)
+    }
+
+    logAt(logInfo, "resource: time formats requested")
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      "time://formats",
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
+
+// handleBusinessHours returns standard business hours across regions
+func handleBusinessHours(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    data := businessHoursData()
+
+    jsonData, err := json.Marshal(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal business hours: %w", err)
+    }
+
+    logAt(logInfo, "resource: business hours requested")
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      "time://business-hours",
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                        prompt handlers                             */
+/* ------------------------------------------------------------------ */
+
+// handleCompareTimezonesPrompt compares times across multiple timezones
+func handleCompareTimezonesPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    timezones := req.Params.Arguments["timezones"]
+    referenceTime := req.Params.Arguments["reference_time"]
+
+    if timezones == "" {
+        return nil, fmt.Errorf("timezones parameter is required")
+    }
+
+    tzList := strings.Split(timezones, ",")
+    for i := range tzList {
+        tzList[i] = strings.TrimSpace(tzList[i])
+    }
+
+    var baseTime time.Time
+    if referenceTime != "" {
+        var err error
+        baseTime, err = time.Parse(time.RFC3339, referenceTime)
+        if err != nil {
+            baseTime = currentTime()
+        }
+    } else {
+        baseTime = currentTime()
+    }
+
+    var promptText strings.Builder
+    promptText.WriteString("Compare the current time across these time zones:\n")
+    for _, tz := range tzList {
+        promptText.WriteString(fmt.Sprintf("- %s\n", tz))
+    }
+    promptText.WriteString(fmt.Sprintf("\nReference time: %s\n\n", baseTime.Format(time.RFC3339)))
+    promptText.WriteString("Show:\n")
+    promptText.WriteString("1. The current time in each timezone\n")
+    promptText.WriteString("2. The time difference from the first timezone\n")
+    promptText.WriteString("3. Whether it's business hours (9 AM - 5 PM)\n")
+    promptText.WriteString("4. The day of the week\n")
+    promptText.WriteString("\nThe compare_timestamps tool puts times from several zones on one axis and reports the gaps between them.\n")
+
+    logAt(logInfo, "prompt: compare_timezones for %s", timezones)
+    return &mcp.GetPromptResult{
+        Description: "Time zone comparison analysis",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+// handleScheduleMeetingPrompt finds optimal meeting times
+func handleScheduleMeetingPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    participants := req.Params.Arguments["participants"]
+    duration := req.Params.Arguments["duration"]
+    if duration == "" {
+        duration = "60"
+    }
+    preferredHours := req.Params.Arguments["preferred_hours"]
+    if preferredHours == "" {
+        preferredHours = "9 AM - 5 PM"
+    }
+    dateRange := req.Params.Arguments["date_range"]
+    if dateRange == "" {
+        dateRange = "next 7 days"
+    }
+
+    if participants == "" {
+        return nil, fmt.Errorf("participants parameter is required")
+    }
+
+    partList := strings.Split(participants, ",")
+    for i := range partList {
+        partList[i] = strings.TrimSpace(partList[i])
+    }
+
+    var promptText strings.Builder
+    promptText.WriteString("Find the best meeting time for participants in these locations:\n")
+    for _, p := range partList {
+        promptText.WriteString(fmt.Sprintf("- %s\n", p))
+    }
+    promptText.WriteString("\nMeeting details:\n")
+    promptText.WriteString(fmt.Sprintf("- Duration: %s minutes\n", duration))
+    promptText.WriteString(fmt.Sprintf("- Preferred hours: %s local time for each participant\n", preferredHours))
+    promptText.WriteString(fmt.Sprintf("- Date range: %s\n\n", dateRange))
+    promptText.WriteString("Consider:\n")
+    promptText.WriteString("1. Business hours overlap across all timezones\n")
+    promptText.WriteString("2. Avoid very early morning (before 8 AM) or late evening (after 7 PM)\n")
+    promptText.WriteString("3. Account for any timezone transitions (DST changes)\n")
+    promptText.WriteString("4. Suggest top 3 meeting times with pros/cons for each\n")
+
+    logAt(logInfo, "prompt: schedule_meeting for %s", participants)
+    return &mcp.GetPromptResult{
+        Description: "Meeting scheduler analysis",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+// handleConvertTimeDetailedPrompt converts time with detailed context
+func handleConvertTimeDetailedPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    timeStr := req.Params.Arguments["time"]
+    fromTz := req.Params.Arguments["from_timezone"]
+    toTzs := req.Params.Arguments["to_timezones"]
+    includeContext := req.Params.Arguments["include_context"]
+    if includeContext == "" {
+        includeContext = "false"
+    }
+
+    if timeStr == "" || fromTz == "" || toTzs == "" {
+        return nil, fmt.Errorf("time, from_timezone, and to_timezones are required")
+    }
+
+    tzList := strings.Split(toTzs, ",")
+    for i := range tzList {
+        tzList[i] = strings.TrimSpace(tzList[i])
+    }
+
+    var promptText strings.Builder
+    promptText.WriteString(fmt.Sprintf("Convert %s from %s to:\n", timeStr, fromTz))
+    for _, tz := range tzList {
+        promptText.WriteString(fmt.Sprintf("- %s\n", tz))
+    }
+
+    if includeContext == "true" {
+        promptText.WriteString("\nAlso provide:\n")
+        promptText.WriteString("1. Day of week in each timezone\n")
+        promptText.WriteString("2. Whether it's a business day\n")
+        promptText.WriteString("3. Any relevant holidays or observances\n")
+        promptText.WriteString("4. Time until/since this moment (relative to now)\n")
+        promptText.WriteString("5. Sunrise/sunset times if significantly different days\n")
+    }
+
+    logAt(logInfo, "prompt: convert_time_detailed from %s to %s", fromTz, toTzs)
+    return &mcp.GetPromptResult{
+        Description: "Detailed time conversion",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+// handlePlanTravelItineraryPrompt builds a travel itinerary planning prompt
+func handlePlanTravelItineraryPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    departure := req.Params.Arguments["departure"]
+    departureTz := req.Params.Arguments["departure_timezone"]
+    departureTime := req.Params.Arguments["departure_time"]
+    arrival := req.Params.Arguments["arrival"]
+    arrivalTz := req.Params.Arguments["arrival_timezone"]
+    flightDuration := req.Params.Arguments["flight_duration"]
+    arrivalTime := req.Params.Arguments["arrival_time"]
+    layovers := req.Params.Arguments["layovers"]
+
+    if departure == "" || departureTz == "" || departureTime == "" || arrival == "" || arrivalTz == "" {
+        return nil, fmt.Errorf("departure, departure_timezone, departure_time, arrival, and arrival_timezone are required")
+    }
+    if flightDuration == "" && arrivalTime == "" {
+        return nil, fmt.Errorf("either flight_duration or arrival_time is required")
+    }
+
+    var promptText strings.Builder
+    promptText.WriteString("Plan the timing of this travel itinerary:\n")
+    promptText.WriteString(fmt.Sprintf("- Depart: %s (%s) at %s local time\n", departure, departureTz, departureTime))
+    if layovers != "" {
+        promptText.WriteString("- Connections:\n")
+        for _, l := range strings.Split(layovers, ",") {
+            promptText.WriteString(fmt.Sprintf("  - %s\n", strings.TrimSpace(l)))
+        }
+    }
+    promptText.WriteString(fmt.Sprintf("- Arrive: %s (%s)", arrival, arrivalTz))
+    if arrivalTime != "" {
+        promptText.WriteString(fmt.Sprintf(" at %s local time", arrivalTime))
+    }
+    promptText.WriteString("\n")
+    if flightDuration != "" {
+        promptText.WriteString(fmt.Sprintf("- Total flight time: %s\n", flightDuration))
+    }
+    promptText.WriteString("\nPlease provide:\n")
+    promptText.WriteString("1. Local departure and arrival times for every leg, including the date (flights often land on a different day)\n")
+    promptText.WriteString("2. Layover durations at each connection, flagging any under 1 hour or over 6 hours\n")
+    promptText.WriteString("3. Total elapsed travel time versus the wall-clock difference between departure and arrival\n")
+    promptText.WriteString("4. The time difference between origin and destination, and any DST change during the trip\n")
+    promptText.WriteString("5. Jet-lag advice: when to sleep, eat and seek daylight before, during and after the trip\n")
+    promptText.WriteString("\nUse the flight_arrival_time and convert_time tools rather than computing offsets by hand.\n")
+
+    logAt(logInfo, "prompt: plan_travel_itinerary %s -> %s", departure, arrival)
+    return &mcp.GetPromptResult{
+        Description: "Travel itinerary planner",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+// maxDSTImpactDays bounds the date range of the daylight_saving_impact prompt
+const maxDSTImpactDays = 731
+
+// describeTransition renders an offset change such as
+// "2025-03-09 03:00 EDT: EST -> EDT (UTC-05:00 -> UTC-04:00), clocks go forward 1h"
+func describeTransition(before, after time.Time) string {
+    fromAbbr, fromOff := before.Zone()
+    toAbbr, toOff := after.Zone()
+    change := "abbreviation change only"
+    if d := time.Duration(toOff-fromOff) * time.Second; d > 0 {
+        change = "clocks go forward " + humanizeGap(d)
+    } else if d < 0 {
+        change = "clocks go back " + humanizeGap(-d)
+    }
+    return fmt.Sprintf("%s: %s -> %s (UTC%s -> UTC%s), %s",
+        after.Format("2006-01-02 15:04 MST"), fromAbbr, toAbbr, formatOffset(fromOff), formatOffset(toOff), change)
+}
+
+// handleDaylightSavingImpactPrompt asks for an analysis of the DST changes
+// of several zones over a date range, listing the transitions it found
+func handleDaylightSavingImpactPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    timezones := req.Params.Arguments["timezones"]
+    startDate := req.Params.Arguments["start_date"]
+    endDate := req.Params.Arguments["end_date"]
+    meetings := req.Params.Arguments["meetings"]
+
+    if timezones == "" {
+        return nil, fmt.Errorf("timezones parameter is required")
+    }
+
+    start := currentTime().UTC()
+    start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+    if startDate != "" {
+        var err error
+        if start, err = time.Parse("2006-01-02", startDate); err != nil {
+            return nil, fmt.Errorf("start_date must be YYYY-MM-DD")
+        }
+    }
+    end := start.AddDate(0, 0, 90)
+    if endDate != "" {
+        var err error
+        if end, err = time.Parse("2006-01-02", endDate); err != nil {
+            return nil, fmt.Errorf("end_date must be YYYY-MM-DD")
+        }
+    }
+    if end.Before(start) || end.Sub(start) > maxDSTImpactDays*24*time.Hour {
+        return nil, fmt.Errorf("end_date must be on or after start_date and at most %d days later", maxDSTImpactDays)
+    }
+
+    var promptText strings.Builder
+    promptText.WriteString(fmt.Sprintf("Analyze the impact of daylight saving time changes between %s and %s for these time zones:\n",
+        start.Format("2006-01-02"), end.Format("2006-01-02")))
+    for _, tz := range strings.Split(timezones, ",") {
+        tz = strings.TrimSpace(tz)
+        if tz == "" {
+            continue
+        }
+        loc, err := loadLocation(tz)
+        if err != nil {
+            return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+        }
+        // Transitions between local midnight of start_date and the end of end_date
+        t := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+        until := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, loc)
+        var changes []string
+        for {
+            next, ok := nextTransition(t)
+            if !ok || !next.Before(until) {
+                break
+            }
+            changes = append(changes, describeTransition(next.Add(-time.Second), next))
+            t = next
+        }
+        if len(changes) == 0 {
+            abbr, off := t.Zone()
+            promptText.WriteString(fmt.Sprintf("- %s: no changes, stays %s (UTC%s)\n", tz, abbr, formatOffset(off)))
+            continue
+        }
+        promptText.WriteString(fmt.Sprintf("- %s:\n", tz))
+        for _, c := range changes {
+            promptText.WriteString(fmt.Sprintf("  - %s\n", c))
+        }
+    }
+    if meetings != "" {
+        promptText.WriteString("\nRecurring meetings:\n")
+        for _, m := range strings.Split(meetings, ";") {
+            if m = strings.TrimSpace(m); m != "" {
+                promptText.WriteString(fmt.Sprintf("- %s\n", m))
+            }
+        }
+    }
+    promptText.WriteString("\nPlease provide:\n")
+    promptText.WriteString("1. A timeline of these changes in date order, with how the offset between each pair of zones changes and for how long\n")
+    promptText.WriteString("2. Which recurring meetings move in local time for some participants, by how much and between which dates\n")
+    promptText.WriteString("3. Meetings that fall outside working hours or onto another day after a change\n")
+    promptText.WriteString("4. What to warn participants about, and when to send each warning\n")
+    promptText.WriteString("\nUse the is_dst tool (its next_transition field) to check transitions and convert_time to verify meeting times on both sides of each change.\n")
+
+    logAt(logInfo, "prompt: daylight_saving_impact for %s", timezones)
+    return &mcp.GetPromptResult{
+        Description: "Daylight saving impact analysis",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+const (
+    // maxTrackedDeadlines caps the deadlines of the deadline_tracker prompt
+    maxTrackedDeadlines = 50
+    // deadlineWorkHorizon bounds the range over which working time is counted
+    deadlineWorkHorizon = 366 * 24 * time.Hour
+)
+
+// trackedDeadline is one entry of the deadline_tracker prompt
+type trackedDeadline struct {
+    label string
+    due   time.Time
+    loc   *time.Location
+}
+
+// parseDeadlines reads semicolon-separated "label | time | timezone"
+// entries; the label and timezone may be left out and the zone may also
+// follow the time
+func parseDeadlines(s string, def *time.Location) ([]trackedDeadline, error) {
+    var out []trackedDeadline
+    for _, entry := range strings.Split(s, ";") {
+        if entry = strings.TrimSpace(entry); entry == "" {
+            continue
+        }
+        fields := strings.Split(entry, "|")
+        for i := range fields {
+            fields[i] = strings.TrimSpace(fields[i])
+        }
+        var label, value, zone string
+        switch len(fields) {
+        case 1:
+            value = fields[0]
+        case 2:
+            label, value = fields[0], fields[1]
+        case 3:
+            label, value, zone = fields[0], fields[1], fields[2]
+        default:
+            return nil, fmt.Errorf("invalid deadline %q (use 'label | time | timezone')", entry)
+        }
+        loc := def
+        if zone != "" {
+            var err error
+            if loc, err = loadLocation(zone); err != nil {
+                return nil, fmt.Errorf("deadline %q: %w", entry, err)
+            }
+        }
+        due, loc, err := parseTimeWithZone(value, loc)
+        if err != nil {
+            return nil, fmt.Errorf("deadline %q: cannot parse time %q", entry, value)
+        }
+        if label == "" {
+            label = value
+        }
+        out = append(out, trackedDeadline{label, due.In(loc), loc})
+    }
+    if len(out) == 0 {
+        return nil, fmt.Errorf("deadlines parameter is required")
+    }
+    if len(out) > maxTrackedDeadlines {
+        return nil, fmt.Errorf("at most %d deadlines can be tracked", maxTrackedDeadlines)
+    }
+    return out, nil
+}
+
+// handleDeadlineTrackerPrompt orders deadlines across zones and asks which
+// are at risk, giving the time and working time left for each
+func handleDeadlineTrackerPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    deadlines := req.Params.Arguments["deadlines"]
+    tz := req.Params.Arguments["timezone"]
+    if tz == "" {
+        tz = "UTC"
+    }
+    hours := req.Params.Arguments["working_hours"]
+    if hours == "" {
+        hours = "09:00-17:00"
+    }
+    skipWeekends := req.Params.Arguments["skip_weekends"] != "false"
+    riskHours := 8.0
+    if v := req.Params.Arguments["risk_hours"]; v != "" {
+        n, err := strconv.ParseFloat(v, 64)
+        if err != nil || n < 0 {
+            return nil, fmt.Errorf("risk_hours must be a non-negative number")
+        }
+        riskHours = n
+    }
+
+    if deadlines == "" {
+        return nil, fmt.Errorf("deadlines parameter is required")
+    }
+    def, err := loadLocation(tz)
+    if err != nil {
+        return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+    }
+    wh, err := parseWorkingHours(hours)
+    if err != nil {
+        return nil, err
+    }
+    list, err := parseDeadlines(deadlines, def)
+    if err != nil {
+        return nil, err
+    }
+    now := currentTime()
+    if v := req.Params.Arguments["now"]; v != "" {
+        if now, err = parseTimeInLocation(v, def); err != nil {
+            return nil, fmt.Errorf("invalid now time %q", v)
+        }
+    }
+    sort.SliceStable(list, func(i, j int) bool { return list[i].due.Before(list[j].due) })
+
+    days := "Monday-Friday"
+    if !skipWeekends {
+        days = "every day"
+    }
+    var promptText strings.Builder
+    promptText.WriteString(fmt.Sprintf("Track these deadlines. Now is %s; working hours are %s, %s, in each deadline's own time zone.\n\n",
+        now.In(def).Format(time.RFC3339), formatClockRange(wh), days))
+    promptText.WriteString("In order of due time:\n")
+    for i, d := range list {
+        promptText.WriteString(fmt.Sprintf("%d. %s: due %s (%s)", i+1, d.label, d.due.Format("Mon 2006-01-02 15:04 MST"), d.loc))
+        left := d.due.Sub(now)
+        switch {
+        case left <= 0:
+            promptText.WriteString(fmt.Sprintf(" - OVERDUE by %s\n", humanizeGap(-left)))
+            continue
+        case left > deadlineWorkHorizon:
+            promptText.WriteString(fmt.Sprintf(" - in %s\n", humanizeGap(left)))
+            continue
+        }
+        var work time.Duration
+        for _, iv := range workIntervals(d.loc, wh, now, d.due, skipWeekends) {
+            work += iv.end.Sub(iv.start)
+        }
+        promptText.WriteString(fmt.Sprintf(" - in %s, %gh of working time left", humanizeGap(left), math.Round(work.Hours()*10)/10))
+        if work.Hours() < riskHours {
+            promptText.WriteString(" - AT RISK")
+        }
+        promptText.WriteString("\n")
+    }
+    promptText.WriteString(fmt.Sprintf("\nA deadline is at risk with less than %g working hours left.\n", riskHours))
+    promptText.WriteString("\nPlease provide:\n")
+    promptText.WriteString("1. The deadlines by urgency, with the time remaining in plain words\n")
+    promptText.WriteString("2. Which deadlines are at risk or overdue, and why (little working time, a weekend or off-hours due time)\n")
+    promptText.WriteString("3. Deadlines that fall on the same day or clash once converted to one time zone\n")
+    promptText.WriteString("4. A suggested order of work and what to escalate\n")
+    promptText.WriteString("\nUse the time_until tool for exact remaining time and is_business_hours to check a deadline against a country's workweek.\n")
+
+    logAt(logInfo, "prompt: deadline_tracker for %d deadline(s)", len(list))
+    return &mcp.GetPromptResult{
+        Description: "Deadline tracker",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+// handoverOverlapWindow is how far either side of a handover shared working
+// hours are looked for
+const handoverOverlapWindow = 12 * time.Hour
+
+// handleShiftHandoverSummaryPrompt asks for a structured handover brief
+// between two on-call regions, with the handover time and the shared working
+// hours around it worked out by the server
+func handleShiftHandoverSummaryPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+    outTz := req.Params.Arguments["outgoing_timezone"]
+    inTz := req.Params.Arguments["incoming_timezone"]
+    handover := req.Params.Arguments["handover_time"]
+    outTeam := req.Params.Arguments["outgoing_team"]
+    inTeam := req.Params.Arguments["incoming_team"]
+    date := req.Params.Arguments["date"]
+    hours := req.Params.Arguments["working_hours"]
+    if hours == "" {
+        hours = "09:00-17:00"
+    }
+    notes := req.Params.Arguments["notes"]
+
+    if outTz == "" || inTz == "" || handover == "" {
+        return nil, fmt.Errorf("outgoing_timezone, incoming_timezone, and handover_time are required")
+    }
+    outLoc, err := loadLocation(outTz)
+    if err != nil {
+        return nil, fmt.Errorf("invalid outgoing_timezone %q: %w", outTz, err)
+    }
+    inLoc, err := loadLocation(inTz)
+    if err != nil {
+        return nil, fmt.Errorf("invalid incoming_timezone %q: %w", inTz, err)
+    }
+    if outTeam == "" {
+        outTeam = outLoc.String()
+    }
+    if inTeam == "" {
+        inTeam = inLoc.String()
+    }
+    wh, err := parseWorkingHours(hours)
+    if err != nil {
+        return nil, err
+    }
+
+    // handover_time is HH:MM on date in the outgoing zone, or a full time
+    var at time.Time
+    if mins, cerr := parseClockMinutes(handover); cerr == nil {
+        day := currentTime().In(outLoc)
+        if date != "" {
+            if day, err = time.ParseInLocation("2006-01-02", date, outLoc); err != nil {
+                return nil, fmt.Errorf("date must be YYYY-MM-DD")
+            }
+        }
+        at = time.Date(day.Year(), day.Month(), day.Day(), 0, mins, 0, 0, outLoc)
+    } else if at, err = parseTimeInLocation(handover, outLoc); err != nil {
+        return nil, fmt.Errorf("handover_time must be HH:MM or a full time, got %q", handover)
+    }
+
+    from, to := at.Add(-handoverOverlapWindow), at.Add(handoverOverlapWindow)
+    overlap := intersectIntervals(workIntervals(outLoc, wh, from, to, true), workIntervals(inLoc, wh, from, to, true))
+    side := func(loc *time.Location) string {
+        if len(workIntervals(loc, wh, at, at.Add(time.Minute), true)) > 0 {
+            return "within working hours"
+        }
+        return "outside working hours"
+    }
+
+    var promptText strings.Builder
+    promptText.WriteString(fmt.Sprintf("Prepare a shift handover brief from %s (%s) to %s (%s).\n\n", outTeam, outLoc, inTeam, inLoc))
+    promptText.WriteString("Handover time:\n")
+    promptText.WriteString(fmt.Sprintf("- %s: %s, %s\n", outTeam, at.In(outLoc).Format("Mon 2006-01-02 15:04 MST"), side(outLoc)))
+    promptText.WriteString(fmt.Sprintf("- %s: %s, %s\n", inTeam, at.In(inLoc).Format("Mon 2006-01-02 15:04 MST"), side(inLoc)))
+    promptText.WriteString(fmt.Sprintf("- UTC: %s\n", at.UTC().Format("2006-01-02 15:04")))
+    promptText.WriteString(fmt.Sprintf("\nShared working hours (%s weekdays in both zones) within %s of the handover:\n",
+        formatClockRange(wh), humanizeGap(handoverOverlapWindow)))
+    if len(overlap) == 0 {
+        promptText.WriteString("- none: the handover has to be asynchronous or outside someone's working hours\n")
+    }
+    var shared time.Duration
+    for _, iv := range overlap {
+        shared += iv.end.Sub(iv.start)
+        promptText.WriteString(fmt.Sprintf("- %s-%s %s = %s-%s %s (%s)\n",
+            iv.start.In(outLoc).Format("Mon 15:04"), iv.end.In(outLoc).Format("15:04"), outTeam,
+            iv.start.In(inLoc).Format("Mon 15:04"), iv.end.In(inLoc).Format("15:04"), inTeam, humanizeGap(iv.end.Sub(iv.start))))
+    }
+    if shared > 0 {
+        promptText.WriteString(fmt.Sprintf("Total overlap: %s\n", humanizeGap(shared)))
+    }
+    if notes != "" {
+        promptText.WriteString(fmt.Sprintf("\nNotes from the outgoing shift:\n%s\n", notes))
+    }
+    promptText.WriteString("\nStructure the brief as:\n")
+    promptText.WriteString("1. Open incidents with status, owner and next step\n")
+    promptText.WriteString("2. Changes and deployments in progress or scheduled during the next shift, in the incoming team's local time\n")
+    promptText.WriteString("3. Risks and alerts to watch\n")
+    promptText.WriteString("4. Escalation contacts and when each team is reachable, using the overlap above\n")
+    promptText.WriteString("5. Whether to hand over live in the overlap or asynchronously, and what needs acknowledging\n")
+    promptText.WriteString("\nUse the meeting_overlap_windows tool for overlap on other days and convert_time for any time quoted in the brief.\n")
+
+    logAt(logInfo, "prompt: shift_handover_summary %s -> %s", outTeam, inTeam)
+    return &mcp.GetPromptResult{
+        Description: "Shift handover brief",
+        Messages: []mcp.PromptMessage{
+            {
+                Role:    mcp.RoleUser,
+                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
+            },
+        },
+    }, nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                         tool handlers                              */
+/* ------------------------------------------------------------------ */
+
+// toolResultJSON marshals v as the text content of a tool result
+func toolResultJSON(v any) (*mcp.CallToolResult, error) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal tool result: %w", err)
+    }
+    return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleGetSystemTime returns the current time in the specified timezone
+func handleGetSystemTime(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    // Get timezone parameter with the session or server default
+    tz := req.GetString("timezone", defaultTimezoneFor(ctx))
+
+    // Get current time in the specified timezone (service.go)
+    t, err := timeIn(tz, currentTime())
+    if err != nil {
+        return toolError(err), nil
+    }
+    now := t.Format(time.RFC3339)
+
+    logAt(logInfo, "get_system_time: timezone=%s result=%s", tz, now)
+    return mcp.NewToolResultText(now), nil
+}
+
+// handleConvertTime converts time between different timezones
+func handleConvertTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    // Get required parameters
+    timeStr, err := req.RequireString("time")
+    if err != nil {
+        return toolError(errMissingField("time")), nil
+    }
+
+    sourceTimezone, err := req.RequireString("source_timezone")
+    if err != nil {
+        return toolError(errMissingField("source_timezone")), nil
+    }
+
+    targetTimezone, err := req.RequireString("target_timezone")
+    if err != nil {
+        return toolError(errMissingField("target_timezone")), nil
+    }
+
+    // Parse the time string in the source timezone and convert it
+    // (service.go)
+    _, converted, err := convertTime(timeStr, sourceTimezone, targetTimezone)
+    switch errorField(err) {
+    case "from":
+        return toolError(fieldError("source_timezone", fmt.Errorf("invalid source timezone: %w", err))), nil
+    case "to":
+        return toolError(fieldError("target_timezone", fmt.Errorf("invalid target timezone: %w", err))), nil
+    }
+    if err != nil {
+        return toolError(err), nil
+    }
+    convertedTime := converted.Format(time.RFC3339)
+
+    logAt(logInfo, "convert_time: %s from %s to %s = %s", timeStr, sourceTimezone, targetTimezone, convertedTime)
+    return mcp.NewToolResultText(convertedTime), nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                       authentication middleware                    */
+/* ------------------------------------------------------------------ */
+
+// authMiddleware creates a middleware that checks for Bearer token authentication
+func authMiddleware(token *bearerToken, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        // Skip auth for health, version and probe endpoints and the API docs
+        if isProbePath(r.URL.Path) || isPublicDocsPath(r.URL.Path) {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        // Get Authorization header
+        authHeader := r.Header.Get("Authorization")
+        if authHeader == "" {
+            logAt(logWarn, "missing authorization header from %s for %s", r.RemoteAddr, r.URL.Path)
+            w.Header().Set("WWW-Authenticate", `Bearer realm="MCP Server"`)
+            writeJSONError(w, http.StatusUnauthorized, "Authorization required")
+            return
+        }
+
+        // Check Bearer token format
+        const bearerPrefix = "Bearer "
+        if !strings.HasPrefix(authHeader, bearerPrefix) {
+            logAt(logWarn, "invalid authorization format from %s", r.RemoteAddr)
+            writeJSONError(w, http.StatusUnauthorized, "Invalid authorization format")
+            return
+        }
+
+        // Verify token: the -auth-token token, or a scoped one
+        providedToken := strings.TrimPrefix(authHeader, bearerPrefix)
+        caller := callers.lookup(providedToken)
+        if caller == nil && (providedToken == "" || providedToken != token.get()) {
+            logAt(logWarn, "invalid token from %s", r.RemoteAddr)
+            writeJSONError(w, http.StatusUnauthorized, "Invalid token")
+            return
+        }
+
+        // Scoped tokens only reach the REST paths (and /graphql) they were
+        // granted; MCP features are checked per message by checkCallerScope
+        if caller != nil {
+            rest := strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == graphqlPath
+            if rest && !caller.allows(scopeREST, r.URL.Path) {
+                logAt(logWarn, "token %q from %s may not use %s", caller.Name, r.RemoteAddr, r.URL.Path)
+                writeJSONError(w, http.StatusForbidden, "Forbidden")
+                return
+            }
+            r = r.WithContext(withCaller(r.Context(), caller))
+        }
+
+        // Token valid, proceed with request
+        logAt(logDebug, "authenticated request from %s to %s", r.RemoteAddr, r.URL.Path)
+        next.ServeHTTP(w, r)
+    })
+}
+
+/* ------------------------------------------------------------------ */
+/*                        helper functions                            */
+/* ------------------------------------------------------------------ */
+
+// newMCPServer creates the MCP server with every tool, resource and prompt
+// registered. subscribe enables resource subscriptions (SSE transports).
+func newMCPServer(hooks *server.Hooks, subscribe bool) *server.MCPServer {
+    s := server.NewMCPServer(
+        appName,
+        appVersion,
+        server.WithToolCapabilities(false), // Static tool list (no list changed)
+        server.WithResourceCapabilities(subscribe, true),            // Enable resource capabilities (subscribe on SSE, list changed)
+        server.WithPromptCapabilities(true),                         // Enable prompt capabilities (list changed)
+        server.WithLogging(),                                        // Enable MCP protocol logging
+        server.WithRecovery(),                                       // Recover from panics in handlers
+        server.WithHooks(hooks),                                     // Track sessions and request ids
+        server.WithElicitation(),                                    // Ask users for missing tool arguments
+        server.WithToolHandlerMiddleware(errorCodeMiddleware),       // Give error results a machine-readable code
+        server.WithToolHandlerMiddleware(toolStatsMiddleware),       // Count calls for /admin/stats/tools
+        server.WithToolHandlerMiddleware(auditMiddleware),           // Record calls in the -audit-log
+        server.WithToolHandlerMiddleware(usageMiddleware),           // Attribute calls for /admin/usage
+        server.WithToolHandlerMiddleware(replayMiddleware),          // Answer from the -replay recording
+        server.WithToolHandlerMiddleware(cancellableToolMiddleware), // Make tool calls cancellable
+        server.WithToolHandlerMiddleware(elicitationMiddleware),     // Ask for missing required arguments
+        server.WithToolHandlerMiddleware(slowToolMiddleware),        // Record calls slower than -slow-request
+        server.WithToolHandlerMiddleware(toolGuardMiddleware),       // Apply -tool-timeout and isolate panics
+        server.WithToolHandlerMiddleware(workerPoolMiddleware),      // Run CPU-heavy tools in the worker pool
+    )
+
+    // Let tools ask the client's LLM for summaries (see sampling.go)
+    s.EnableSampling()
+
+    // Cancel in-flight requests on notifications/cancelled
+    s.AddNotificationHandler(methodNotificationCancelled, handleCancelledNotification)
+
+    /* ----------------------- register tools ----------------------- */
+    // Register get_system_time tool
+    getTimeTool := mcp.NewTool("get_system_time",
+        mcp.WithDescription("Get current system time in specified timezone"),
+        mcp.WithTitleAnnotation("Get System Time"),
+        mcp.WithReadOnlyHintAnnotation(true),     // This tool only reads, doesn't modify
+        mcp.WithDestructiveHintAnnotation(false), // Not destructive - only returns time
+        mcp.WithIdempotentHintAnnotation(false),  // Not idempotent - returns different time each call
+        mcp.WithOpenWorldHintAnnotation(false),   // No external access - uses only local system time
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone name (e.g., 'America/New_York', 'Europe/London'). Defaults to the server's default timezone (UTC unless configured)"),
+        ),
+    )
+    s.AddTool(getTimeTool, handleGetSystemTime)
+
+    // Register convert_time tool
+    convertTimeTool := mcp.NewTool("convert_time",
+        mcp.WithDescription("Convert time between different timezones"),
+        mcp.WithTitleAnnotation("Convert Time"),
+        mcp.WithReadOnlyHintAnnotation(true),     // This tool only converts, doesn't modify
+        mcp.WithDestructiveHintAnnotation(false), // Not destructive - only converts time
+        mcp.WithIdempotentHintAnnotation(true),   // Idempotent - same input gives same output
+        mcp.WithOpenWorldHintAnnotation(false),   // No external access - pure computation
+        mcp.WithString("time",
+            mcp.Required(),
+            mcp.Description("Time to convert in RFC3339 format or common formats like '2006-01-02 15:04:05'"),
+        ),
+        mcp.WithString("source_timezone",
+            mcp.Required(),
+            mcp.Description("Source IANA timezone name"),
+        ),
+        mcp.WithString("target_timezone",
+            mcp.Required(),
+            mcp.Description("Target IANA timezone name"),
+        ),
+    )
+    s.AddTool(convertTimeTool, handleConvertTime)
+
+    // Register epoch conversion tools
+    registerEpochTools(s)
+
+    // Register calendar period tools (round_time, truncate_time, start_end_of_period)
+    registerPeriodTools(s)
+
+    // Register is_dst
+    registerDSTTools(s)
+    registerLocaleTools(s)
+
+    // Register resolve_timezone_abbreviation
+    registerAbbreviationTools(s)
+
+    // Register flight_arrival_time
+    registerTravelTools(s)
+
+    // Register meeting_overlap_windows
+    registerMeetingTools(s)
+
+    // Register generate_rotation
+    registerRotationTools(s)
+
+    // Register age_and_elapsed and time_until
+    registerElapsedTools(s)
+
+    // Register parse_duration
+    registerDurationTools(s)
+
+    // Register convert_time_scale
+    registerTimeScaleTools(s)
+
+    // Register market_hours and the markets:// resources
+    registerMarketTools(s)
+
+    // Register is_business_hours
+    registerWorkweekTools(s)
+
+    // Register sleep and wait_until
+    registerSleepTools(s)
+
+    // Register timer_start, timer_lap, timer_stop and timer_status
+    registerTimerTools(s)
+
+    // Register set_reminder, list_reminders and cancel_reminder
+    registerReminderTools(s)
+
+    // Register create_ical_event and parse_ical
+    registerICalTools(s)
+
+    // Register save/list/delete_participant_group
+    registerGroupTools(s)
+
+    // Register check_clock_accuracy
+    registerNTPTools(s)
+
+    // Register get_precise_time
+    registerPreciseTools(s)
+
+    // Register compare_timestamps
+    registerCompareTools(s)
+
+    // Register get_world_times
+    registerWorldTools(s)
+
+    // Register fiscal_quarter_of and fiscal_period_boundaries
+    registerFiscalTools(s)
+
+    // Register get_shift_schedule
+    registerShiftTools(s)
+
+    /* ----------------------- register resources ---------------------- */
+    // Register timezone information resource
+    s.AddResource(mcp.NewResource("timezone://info", "Timezone Information",
+        mcp.WithResourceDescription("Comprehensive timezone information including offsets, DST, and major cities"),
+        mcp.WithMIMEType("application/json"),
+    ), cancellableResource(handleTimezoneInfo))
+
+    // Register current world times resource
+    s.AddResource(mcp.NewResource("time://current/world", "Current World Times",
+        mcp.WithResourceDescription("Current time in major cities around the world"),
+        mcp.WithMIMEType("application/json"),
+    ), cancellableResource(handleCurrentWorldTimes))
+
+    // Register time format examples resource
+    s.AddResource(mcp.NewResource("time://formats", "Time Formats",
+        mcp.WithResourceDescription("Examples of supported time formats for parsing and display"),
+        mcp.WithMIMEType("application/json"),
+    ), cancellableResource(handleTimeFormats))
+
+    // Register business hours resource
+    s.AddResource(mcp.NewResource("time://business-hours", "Business Hours",
+        mcp.WithResourceDescription("Standard workweek days, office hours and lunch breaks by country and region"),
+        mcp.WithMIMEType("application/json"),
+    ), cancellableResource(handleBusinessHours))
+
+    // Register single-zone detail template
+    s.AddResourceTemplate(mcp.NewResourceTemplate("timezone://zones/{+zone}", "Timezone Detail",
+        mcp.WithTemplateDescription("Offset, DST state, abbreviation, next transition, country and example cities of one zone"),
+        mcp.WithTemplateMIMEType("application/json"),
+    ), cancellableResourceTemplate(handleTimezoneDetailResource))
+
+    // Register per-client usage statistics resource
+    registerUsageResource(s)
+
+    /* ----------------------- register prompts ------------------------ */
+    // Register time zone comparison prompt
+    s.AddPrompt(mcp.NewPrompt("compare_timezones",
+        mcp.WithPromptDescription("Compare current times across multiple time zones"),
+        mcp.WithArgument("timezones",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Comma-separated list of timezone IDs to compare"),
+        ),
+        mcp.WithArgument("reference_time",
+            mcp.ArgumentDescription("Optional reference time (defaults to now)"),
+        ),
+    ), cancellablePrompt(handleCompareTimezonesPrompt))
+
+    // Register meeting scheduler prompt
+    s.AddPrompt(mcp.NewPrompt("schedule_meeting",
+        mcp.WithPromptDescription("Find optimal meeting time across multiple time zones"),
+        mcp.WithArgument("participants",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Comma-separated list of participant locations/timezones"),
+        ),
+        mcp.WithArgument("duration",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Meeting duration in minutes"),
+        ),
+        mcp.WithArgument("preferred_hours",
+            mcp.ArgumentDescription("Preferred time range (e.g., '9 AM - 5 PM')"),
+        ),
+        mcp.WithArgument("date_range",
+            mcp.ArgumentDescription("Date range to consider (e.g., 'next 7 days')"),
+        ),
+    ), cancellablePrompt(handleScheduleMeetingPrompt))
+
+    // Register time zone converter prompt
+    s.AddPrompt(mcp.NewPrompt("convert_time_detailed",
+        mcp.WithPromptDescription("Convert time with detailed context"),
+        mcp.WithArgument("time",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Time to convert"),
+        ),
+        mcp.WithArgument("from_timezone",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Source timezone"),
+        ),
+        mcp.WithArgument("to_timezones",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Comma-separated list of target timezones"),
+        ),
+        mcp.WithArgument("include_context",
+            mcp.ArgumentDescription("Whether to include contextual information (true/false)"),
+        ),
+    ), cancellablePrompt(handleConvertTimeDetailedPrompt))
+
+    // Register travel itinerary prompt
+    s.AddPrompt(mcp.NewPrompt("plan_travel_itinerary",
+        mcp.WithPromptDescription("Plan local arrival times, layovers and jet-lag advice for a trip across time zones"),
+        mcp.WithArgument("departure",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Departure airport or city"),
+        ),
+        mcp.WithArgument("departure_timezone",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Departure IANA timezone"),
+        ),
+        mcp.WithArgument("departure_time",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Local departure time"),
+        ),
+        mcp.WithArgument("arrival",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Arrival airport or city"),
+        ),
+        mcp.WithArgument("arrival_timezone",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Arrival IANA timezone"),
+        ),
+        mcp.WithArgument("flight_duration",
+            mcp.ArgumentDescription("Total flight time (e.g., '14h30m'); required if arrival_time is not given"),
+        ),
+        mcp.WithArgument("arrival_time",
+            mcp.ArgumentDescription("Scheduled local arrival time, if known"),
+        ),
+        mcp.WithArgument("layovers",
+            mcp.ArgumentDescription("Comma-separated connections (e.g., 'DXB 2h10m, SIN 1h45m')"),
+        ),
+    ), cancellablePrompt(handlePlanTravelItineraryPrompt))
+
+    // Register daylight saving impact prompt
+    s.AddPrompt(mcp.NewPrompt("daylight_saving_impact",
+        mcp.WithPromptDescription("Analyze upcoming DST changes across time zones, which recurring meetings shift, and what to warn participants about"),
+        mcp.WithArgument("timezones",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Comma-separated list of timezone IDs"),
+        ),
+        mcp.WithArgument("start_date",
+            mcp.ArgumentDescription("First day of the range (YYYY-MM-DD, defaults to today)"),
+        ),
+        mcp.WithArgument("end_date",
+            mcp.ArgumentDescription("Last day of the range (YYYY-MM-DD, defaults to 90 days after start_date)"),
+        ),
+        mcp.WithArgument("meetings",
+            mcp.ArgumentDescription("Semicolon-separated recurring meetings (e.g., 'Weekly sync Mon 09:00 America/New_York; Standup daily 10:00 Europe/Berlin')"),
+        ),
+    ), cancellablePrompt(handleDaylightSavingImpactPrompt))
+
+    // Register deadline tracker prompt
+    s.AddPrompt(mcp.NewPrompt("deadline_tracker",
+        mcp.WithPromptDescription("Order deadlines set in different time zones and find the ones at risk given working hours"),
+        mcp.WithArgument("deadlines",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Semicolon-separated 'label | time | timezone' entries (e.g., 'Q1 report | 2025-03-31 17:00:00 | America/New_York')"),
+        ),
+        mcp.WithArgument("timezone",
+            mcp.ArgumentDescription("Timezone of deadlines given without one (defaults to UTC)"),
+        ),
+        mcp.WithArgument("working_hours",
+            mcp.ArgumentDescription("Daily working hours in each deadline's zone (defaults to '09:00-17:00')"),
+        ),
+        mcp.WithArgument("skip_weekends",
+            mcp.ArgumentDescription("Whether weekends are non-working (true/false, defaults to true)"),
+        ),
+        mcp.WithArgument("risk_hours",
+            mcp.ArgumentDescription("Flag deadlines with fewer working hours left than this (defaults to 8)"),
+        ),
+        mcp.WithArgument("now",
+            mcp.ArgumentDescription("Reference time (defaults to now)"),
+        ),
+    ), cancellablePrompt(handleDeadlineTrackerPrompt))
+
+    // Register shift handover prompt
+    s.AddPrompt(mcp.NewPrompt("shift_handover_summary",
+        mcp.WithPromptDescription("Request a structured on-call handover brief between two regions, with the handover time and shared working hours worked out"),
+        mcp.WithArgument("outgoing_timezone",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("IANA timezone of the team handing over"),
+        ),
+        mcp.WithArgument("incoming_timezone",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("IANA timezone of the team taking over"),
+        ),
+        mcp.WithArgument("handover_time",
+            mcp.RequiredArgument(),
+            mcp.ArgumentDescription("Handover time as HH:MM in the outgoing timezone, or a full time"),
+        ),
+        mcp.WithArgument("date",
+            mcp.ArgumentDescription("Date of an HH:MM handover_time (YYYY-MM-DD, defaults to today in the outgoing timezone)"),
+        ),
+        mcp.WithArgument("outgoing_team",
+            mcp.ArgumentDescription("Name of the outgoing team or region (e.g., 'EMEA on-call')"),
+        ),
+        mcp.WithArgument("incoming_team",
+            mcp.ArgumentDescription("Name of the incoming team or region (e.g., 'AMER on-call')"),
+        ),
+        mcp.WithArgument("working_hours",
+            mcp.ArgumentDescription("Daily working hours in both zones (defaults to '09:00-17:00')"),
+        ),
+        mcp.WithArgument("notes",
+            mcp.ArgumentDescription("Free-form notes from the outgoing shift to include"),
+        ),
+    ), cancellablePrompt(handleShiftHandoverSummaryPrompt))
+
+    return s
+}
+
+// effectiveAddr determines the actual address to listen on
+func effectiveAddr(addrFlag, listen string, port int) string {
+    if addrFlag != "" {
+        return addrFlag
+    }
+    return fmt.Sprintf("%s:%d", listen, port)
+}
+
+// sseKeepAliveOptions returns the SSE options enabling keep-alive pings
+func sseKeepAliveOptions(interval time.Duration) []server.SSEOption {
+    if interval <= 0 {
+        return []server.SSEOption{}
+    }
+    return []server.SSEOption{server.WithKeepAliveInterval(interval)}
+}
+
+// logSSESettings prints the keep-alive, reaper and client cap configuration
+func logSSESettings(keepAlive, idle time.Duration, maxClients int) {
+    if keepAlive > 0 {
+        logAt(logInfo, "  SSE keep-alive:   every %v", keepAlive)
+    }
+    if idle > 0 {
+        logAt(logInfo, "  SSE idle timeout: %v", idle)
+    }
+    if maxClients > 0 {
+        logAt(logInfo, "  SSE client limit: %d", maxClients)
+    }
+}
+
+// registerHealthAndVersion adds health and version endpoints to the mux
+func registerHealthAndVersion(mux *http.ServeMux) {
+    // Health endpoint - JSON response
+    mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusOK)
+        _, _ = w.Write([]byte(healthJSON()))
+    })
+
+    // Version endpoint - JSON response
+    mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusOK)
+        _, _ = w.Write([]byte(versionJSON()))
+    })
+
+    // Kubernetes liveness and readiness probes
+    mux.HandleFunc("/livez", handleLivez)
+    mux.HandleFunc("/readyz", handleReadyz)
+}
+
+/* -------------------- HTTP middleware ----------------------------- */
+
+// loggingHTTPMiddleware provides request logging when log level permits
+func loggingHTTPMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if logLevel() < logInfo {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        start := time.Now()
+
+        // Wrap response writer to capture status code
+        rw := &statusWriter{ResponseWriter: w, status: http.StatusOK, written: false}
+
+        // Call the next handler
+        next.ServeHTTP(rw, r)
+
+        // Log the request with body size for POST requests
+        duration := time.Since(start)
+        if r.Method == "POST" && logLevel() >= logDebug {
+            logAt(logDebug, "%s %s %s %d (Content-Length: %s) %v",
+                r.RemoteAddr, r.Method, r.URL.Path, rw.status, r.Header.Get("Content-Length"), duration)
+        } else {
+            logAt(logInfo, "%s %s %s %d %v",
+                r.RemoteAddr, r.Method, r.URL.Path, rw.status, duration)
+        }
+    })
+}
+
+// statusWriter wraps http.ResponseWriter so we can capture the status code
+// *and* still pass through streaming-related interfaces (Flusher, Hijacker,
+// CloseNotifier) that SSE / HTTP streaming require.
+type statusWriter struct {
+    http.ResponseWriter
+    status  int
+    written bool
+}
+
+/* -------- core ResponseWriter behaviour -------- */
+
+func (sw *statusWriter) WriteHeader(code int) {
+    if !sw.written {
+        sw.status = code
+        sw.written = true
+        sw.ResponseWriter.WriteHeader(code)
+    }
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+    if !sw.written {
+        sw.WriteHeader(http.StatusOK)
+    }
+    return sw.ResponseWriter.Write(b)
+}
+
+/* -------- pass-through for streaming interfaces -------- */
+
+// Flush lets the underlying handler stream (needed for SSE)
+func (sw *statusWriter) Flush() {
+    if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+        if !sw.written {
+            sw.WriteHeader(http.StatusOK)
+        }
+        f.Flush()
+    }
+}
+
+// Hijack lets handlers switch to raw TCP (not used by SSE but good hygiene)
+func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    if h, ok := sw.ResponseWriter.(http.Hijacker); ok {
+        return h.Hijack()
+    }
+    return nil, nil, fmt.Errorf("hijacking not supported")
+}
+
+// CloseNotify keeps SSE clients informed if the peer goes away
+// Deprecated: Use Request.Context() instead. Kept for compatibility with older SSE implementations.
+func (sw *statusWriter) CloseNotify() <-chan bool {
+    // nolint:staticcheck // SA1019: http.CloseNotifier is deprecated but required for SSE compatibility
+    if cn, ok := sw.ResponseWriter.(http.CloseNotifier); ok {
+        return cn.CloseNotify()
+    }
+    // If the underlying writer doesn't support it, fabricate a never-closing chan
+    done := make(chan bool, 1)
+    return done
+}
@@ -1,8 +1,8 @@
-// main_test.go
+// mcp_test.go
 // Copyright 2025
 // SPDX-License-Identifier: Apache-2.0
 // Authors: Mihai Criveti
-package main
+package fasttime
 
 import (
     "context"
@@ -209,6 +209,160 @@ func TestHandleConvertTime(t *testing.T) {
     }
 }
 
+/* ------------------------------------------------------------------
+   prompts
+------------------------------------------------------------------ */
+
+func TestHandlePlanTravelItineraryPrompt(t *testing.T) {
+    req := mcp.GetPromptRequest{}
+    req.Params.Arguments = map[string]string{
+        "departure":          "SFO",
+        "departure_timezone": "America/Los_Angeles",
+        "departure_time":     "2025-03-08 22:30",
+        "arrival":            "BLR",
+        "arrival_timezone":   "Asia/Kolkata",
+        "flight_duration":    "21h",
+        "layovers":           "DXB 2h10m, BOM 1h",
+    }
+    res, err := handlePlanTravelItineraryPrompt(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    text := res.Messages[0].Content.(mcp.TextContent).Text
+    for _, want := range []string{"SFO (America/Los_Angeles)", "  - DXB 2h10m", "  - BOM 1h", "Jet-lag"} {
+        if !strings.Contains(text, want) {
+            t.Errorf("prompt missing %q:\n%s", want, text)
+        }
+    }
+
+    // Neither flight_duration nor arrival_time
+    delete(req.Params.Arguments, "flight_duration")
+    if _, err := handlePlanTravelItineraryPrompt(context.Background(), req); err == nil {
+        t.Error("expected error without flight_duration or arrival_time")
+    }
+}
+
+func TestHandleDaylightSavingImpactPrompt(t *testing.T) {
+    req := mcp.GetPromptRequest{}
+    req.Params.Arguments = map[string]string{
+        "timezones":  "America/New_York, Europe/London, Asia/Tokyo",
+        "start_date": "2025-03-01",
+        "end_date":   "2025-04-30",
+        "meetings":   "Weekly sync Mon 09:00 America/New_York; Standup daily 14:00 Europe/London",
+    }
+    res, err := handleDaylightSavingImpactPrompt(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    text := res.Messages[0].Content.(mcp.TextContent).Text
+    for _, want := range []string{
+        "2025-03-09 03:00 EDT: EST -> EDT (UTC-05:00 -> UTC-04:00), clocks go forward 1h",
+        "2025-03-30 02:00 BST: GMT -> BST (UTC+00:00 -> UTC+01:00), clocks go forward 1h",
+        "Asia/Tokyo: no changes, stays JST (UTC+09:00)",
+        "- Standup daily 14:00 Europe/London",
+        "is_dst",
+    } {
+        if !strings.Contains(text, want) {
+            t.Errorf("prompt missing %q:\n%s", want, text)
+        }
+    }
+
+    for _, args := range []map[string]string{
+        {},
+        {"timezones": "Mars/Base"},
+        {"timezones": "UTC", "start_date": "2025-05-01", "end_date": "2025-04-01"},
+        {"timezones": "UTC", "start_date": "2025-01-01", "end_date": "2030-01-01"},
+    } {
+        req.Params.Arguments = args
+        if _, err := handleDaylightSavingImpactPrompt(context.Background(), req); err == nil {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
+
+func TestHandleDeadlineTrackerPrompt(t *testing.T) {
+    req := mcp.GetPromptRequest{}
+    req.Params.Arguments = map[string]string{
+        "deadlines": "Q1 report | 2025-03-31 17:00:00 | America/New_York; Patch | 2025-03-10 15:00:00 Europe/London; 2025-03-01",
+        "now":       "2025-03-10T12:00:00Z",
+    }
+    res, err := handleDeadlineTrackerPrompt(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    text := res.Messages[0].Content.(mcp.TextContent).Text
+    for _, want := range []string{
+        "1. 2025-03-01: due Sat 2025-03-01 00:00 UTC (UTC) - OVERDUE by 9d 12h",
+        "2. Patch: due Mon 2025-03-10 15:00 GMT (Europe/London) - in 3h, 3h of working time left - AT RISK",
+        "3. Q1 report: due Mon 2025-03-31 17:00 EDT (America/New_York) - in 21d 9h, 128h of working time left\n",
+        "time_until",
+    } {
+        if !strings.Contains(text, want) {
+            t.Errorf("prompt missing %q:\n%s", want, text)
+        }
+    }
+
+    for _, args := range []map[string]string{
+        {},
+        {"deadlines": "Launch | someday"},
+        {"deadlines": "Launch | 2025-03-10 | Mars/Base"},
+        {"deadlines": "a | b | c | d"},
+        {"deadlines": "2025-03-10", "risk_hours": "lots"},
+    } {
+        req.Params.Arguments = args
+        if _, err := handleDeadlineTrackerPrompt(context.Background(), req); err == nil {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
+
+func TestHandleShiftHandoverSummaryPrompt(t *testing.T) {
+    req := mcp.GetPromptRequest{}
+    req.Params.Arguments = map[string]string{
+        "outgoing_timezone": "Europe/London",
+        "incoming_timezone": "America/New_York",
+        "handover_time":     "17:00",
+        "date":              "2025-03-10",
+        "outgoing_team":     "EMEA on-call",
+        "notes":             "INC-42 still open",
+    }
+    res, err := handleShiftHandoverSummaryPrompt(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    text := res.Messages[0].Content.(mcp.TextContent).Text
+    for _, want := range []string{
+        "from EMEA on-call (Europe/London) to America/New_York (America/New_York)",
+        "- EMEA on-call: Mon 2025-03-10 17:00 GMT, outside working hours",
+        "- America/New_York: Mon 2025-03-10 13:00 EDT, within working hours",
+        "- Mon 13:00-17:00 EMEA on-call = Mon 09:00-13:00 America/New_York (4h)",
+        "INC-42 still open",
+    } {
+        if !strings.Contains(text, want) {
+            t.Errorf("prompt missing %q:\n%s", want, text)
+        }
+    }
+
+    // London to Singapore shares no working hours
+    req.Params.Arguments["incoming_timezone"] = "Asia/Singapore"
+    res, err = handleShiftHandoverSummaryPrompt(context.Background(), req)
+    if err != nil || !strings.Contains(res.Messages[0].Content.(mcp.TextContent).Text, "- none:") {
+        t.Errorf("no overlap: %v", err)
+    }
+
+    for _, args := range []map[string]string{
+        {"outgoing_timezone": "UTC", "incoming_timezone": "UTC"},
+        {"outgoing_timezone": "Mars/Base", "incoming_timezone": "UTC", "handover_time": "09:00"},
+        {"outgoing_timezone": "UTC", "incoming_timezone": "UTC", "handover_time": "teatime"},
+        {"outgoing_timezone": "UTC", "incoming_timezone": "UTC", "handover_time": "09:00", "date": "tomorrow"},
+    } {
+        req.Params.Arguments = args
+        if _, err := handleShiftHandoverSummaryPrompt(context.Background(), req); err == nil {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
+
 /* ------------------------------------------------------------------
    auth middleware
 ------------------------------------------------------------------ */
@@ -218,7 +372,7 @@ func TestAuthMiddleware(t *testing.T) {
     okHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
         w.WriteHeader(http.StatusOK)
     })
-    mw := authMiddleware(token, okHandler)
+    mw := authMiddleware(newBearerToken(token), okHandler)
 
     // no header
     rec := httptest.NewRecorder()
@@ -260,7 +414,8 @@ func TestAuthMiddleware(t *testing.T) {
 ------------------------------------------------------------------ */
 
 func TestLoggingHTTPMiddleware(t *testing.T) {
-    curLvl = logDebug // ensure middleware logs
+    setLogLevel(logDebug) // ensure middleware logs
+    defer setLogLevel(logInfo)
     inner := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
         w.WriteHeader(http.StatusTeapot)
     })
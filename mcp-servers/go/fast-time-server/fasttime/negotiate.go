@@ -0,0 +1,443 @@
+// -*- coding: utf-8 -*-
+// negotiate.go - REST content negotiation for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets REST clients pick the response format with the Accept header
+// (or ?format= for quick shell use): JSON (default), XML, YAML, plain text,
+// or MessagePack and CBOR for machine-to-machine use (binformats.go).
+// Handlers keep calling writeJSON/writeJSONError; negotiateMiddleware wraps
+// the ResponseWriter with the chosen format and those helpers render through
+// it. Other responses, such as the SSE stream and the docs page, are written
+// directly and are not affected.
+//
+// Plain text is meant for scripts: time responses become just the timestamp,
+// conversions the converted time, errors their message, and anything else
+// flattened key=value lines.
+
+package fasttime
+
+import (
+    "bytes"
+    "encoding/json"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "mime"
+    "net/http"
+    "regexp"
+    "strconv"
+    "strings"
+
+    "gopkg.in/yaml.v3"
+)
+
+// respFormat is a negotiated response format
+type respFormat int
+
+const (
+    formatJSON respFormat = iota
+    formatXML
+    formatYAML
+    formatText
+    formatMsgPack
+    formatCBOR
+)
+
+// formatMediaTypes maps accepted media types to formats
+var formatMediaTypes = map[string]respFormat{
+    "application/json":   formatJSON,
+    "application/xml":    formatXML,
+    "text/xml":           formatXML,
+    "application/yaml":   formatYAML,
+    "application/x-yaml": formatYAML,
+    "text/yaml":          formatYAML,
+    "text/plain":         formatText,
+
+    "application/msgpack":     formatMsgPack,
+    "application/x-msgpack":   formatMsgPack,
+    "application/vnd.msgpack": formatMsgPack,
+    "application/cbor":        formatCBOR,
+}
+
+// formatNames maps ?format= values to formats
+var formatNames = map[string]respFormat{
+    "json": formatJSON,
+    "xml":  formatXML,
+    "yaml": formatYAML,
+    "yml":  formatYAML,
+    "text": formatText,
+    "txt":  formatText,
+
+    "msgpack": formatMsgPack,
+    "cbor":    formatCBOR,
+}
+
+// contentType returns the Content-Type header for a format
+func (f respFormat) contentType() string {
+    switch f {
+    case formatXML:
+        return "application/xml; charset=utf-8"
+    case formatYAML:
+        return "application/yaml; charset=utf-8"
+    case formatText:
+        return "text/plain; charset=utf-8"
+    case formatMsgPack:
+        return "application/msgpack"
+    case formatCBOR:
+        return "application/cbor"
+    default:
+        return "application/json"
+    }
+}
+
+// negotiateFormat picks the response format for r. An explicit ?format=
+// wins; otherwise the supported Accept entry with the highest q-value is
+// used, with JSON as the fallback for */* and unsupported types.
+func negotiateFormat(r *http.Request) respFormat {
+    if f, ok := formatNames[strings.ToLower(r.URL.Query().Get("format"))]; ok {
+        return f
+    }
+
+    best, bestQ := formatJSON, 0.0
+    for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+        mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+        if err != nil {
+            continue
+        }
+        f, ok := formatMediaTypes[mt]
+        if !ok {
+            continue
+        }
+        q := 1.0
+        if qs, ok := params["q"]; ok {
+            if q, err = strconv.ParseFloat(qs, 64); err != nil {
+                continue
+            }
+        }
+        if q > bestQ {
+            best, bestQ = f, q
+        }
+    }
+    return best
+}
+
+// negotiatedWriter carries the chosen format, the API version and the
+// requested envelope (envelope.go) to writeJSON
+type negotiatedWriter struct {
+    http.ResponseWriter
+    format   respFormat
+    version  int
+    envelope *envelopeState
+}
+
+// Flush passes through to the underlying writer (needed for streaming)
+func (nw *negotiatedWriter) Flush() {
+    if f, ok := nw.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (nw *negotiatedWriter) Unwrap() http.ResponseWriter {
+    return nw.ResponseWriter
+}
+
+// negotiateMiddleware records the response format requested by the client
+func negotiateMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Add("Vary", "Accept")
+        next.ServeHTTP(&negotiatedWriter{
+            ResponseWriter: w,
+            format:         negotiateFormat(r),
+            version:        apiVersion(r),
+            envelope:       newEnvelopeState(w, r),
+        }, r)
+    })
+}
+
+// negotiatedFrom finds the negotiatedWriter under w and any writers that
+// wrap it, such as the recorder of idempotency.go
+func negotiatedFrom(w http.ResponseWriter) *negotiatedWriter {
+    for {
+        switch v := w.(type) {
+        case *negotiatedWriter:
+            return v
+        case interface{ Unwrap() http.ResponseWriter }:
+            w = v.Unwrap()
+        default:
+            return nil
+        }
+    }
+}
+
+// responseFormat returns the format negotiated for w (JSON if none)
+func responseFormat(w http.ResponseWriter) respFormat {
+    if nw := negotiatedFrom(w); nw != nil {
+        return nw.format
+    }
+    return formatJSON
+}
+
+// responseVersion returns the REST API version of the request w answers
+func responseVersion(w http.ResponseWriter) int {
+    if nw := negotiatedFrom(w); nw != nil && nw.version != 0 {
+        return nw.version
+    }
+    return apiV1
+}
+
+/* ------------------------------------------------------------------ */
+/*                            rendering                               */
+/* ------------------------------------------------------------------ */
+
+// plainTexter is implemented by responses with a natural one-line text form
+type plainTexter interface {
+    plainText() string
+}
+
+func (t TimeResponse) plainText() string    { return t.Time }
+func (c ConvertResponse) plainText() string { return c.ConvertedTime }
+func (e ErrorResponse) plainText() string   { return e.Message }
+
+func (b BatchConvertResponse) plainText() string {
+    lines := make([]string, len(b.Results))
+    for i, r := range b.Results {
+        lines[i] = r.ConvertedTime
+    }
+    return strings.Join(lines, "\n")
+}
+
+// writeFormatted renders data as XML, YAML, text, MessagePack or CBOR with
+// the given status
+func writeFormatted(w http.ResponseWriter, f respFormat, code int, data interface{}) {
+    var buf bytes.Buffer
+    if err := encodeAs(&buf, f, data); err != nil {
+        logAt(logError, "Failed to encode %s response: %v", f.contentType(), err)
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusInternalServerError)
+        _ = json.NewEncoder(w).Encode(ErrorResponse{
+            Error:   http.StatusText(http.StatusInternalServerError),
+            Message: "Failed to encode response",
+            Code:    http.StatusInternalServerError,
+        })
+        return
+    }
+    w.Header().Set("Content-Type", f.contentType())
+    w.WriteHeader(code)
+    _, _ = w.Write(buf.Bytes())
+}
+
+// encodeAs writes data to out in format f. The value goes through JSON first
+// so that field names, omitempty and custom marshalers match the JSON output.
+func encodeAs(out io.Writer, f respFormat, data interface{}) error {
+    if pt, ok := data.(plainTexter); ok && f == formatText {
+        _, err := fmt.Fprintln(out, pt.plainText())
+        return err
+    }
+
+    raw, err := json.Marshal(data)
+    if err != nil {
+        return err
+    }
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.UseNumber()
+    tree, err := decodeOrdered(dec)
+    if err != nil {
+        return err
+    }
+
+    switch f {
+    case formatXML:
+        if _, err := io.WriteString(out, xml.Header); err != nil {
+            return err
+        }
+        if err := writeXML(out, "response", tree); err != nil {
+            return err
+        }
+        _, err = io.WriteString(out, "\n")
+        return err
+    case formatYAML:
+        enc := yaml.NewEncoder(out)
+        enc.SetIndent(2)
+        if err := enc.Encode(yamlNode(tree)); err != nil {
+            return err
+        }
+        return enc.Close()
+    case formatText:
+        var lines []string
+        flattenText("", tree, &lines)
+        _, err := io.WriteString(out, strings.Join(lines, "\n")+"\n")
+        return err
+    case formatMsgPack:
+        return writeMsgPack(out, tree)
+    case formatCBOR:
+        return writeCBOR(out, tree)
+    default:
+        return json.NewEncoder(out).Encode(data)
+    }
+}
+
+// orderedField is one member of a JSON object, kept in document order
+type orderedField struct {
+    key   string
+    value interface{}
+}
+
+// orderedObject is a JSON object that remembers its key order
+type orderedObject []orderedField
+
+// MarshalJSON writes the members in order
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+    buf := []byte{'{'}
+    for i, f := range o {
+        if i > 0 {
+            buf = append(buf, ',')
+        }
+        key, _ := json.Marshal(f.key)
+        value, err := json.Marshal(f.value)
+        if err != nil {
+            return nil, err
+        }
+        buf = append(append(append(buf, key...), ':'), value...)
+    }
+    return append(buf, '}'), nil
+}
+
+// decodeOrdered reads one JSON value into orderedObject, []interface{},
+// json.Number, string, bool or nil
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+    tok, err := dec.Token()
+    if err != nil {
+        return nil, err
+    }
+    switch tok {
+    case json.Delim('{'):
+        obj := orderedObject{}
+        for dec.More() {
+            kt, err := dec.Token()
+            if err != nil {
+                return nil, err
+            }
+            v, err := decodeOrdered(dec)
+            if err != nil {
+                return nil, err
+            }
+            obj = append(obj, orderedField{kt.(string), v})
+        }
+        _, err := dec.Token() // closing brace
+        return obj, err
+    case json.Delim('['):
+        arr := []interface{}{}
+        for dec.More() {
+            v, err := decodeOrdered(dec)
+            if err != nil {
+                return nil, err
+            }
+            arr = append(arr, v)
+        }
+        _, err := dec.Token() // closing bracket
+        return arr, err
+    default:
+        return tok, nil
+    }
+}
+
+// xmlNamePattern matches keys usable as XML element names as-is
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// writeXML writes v as an element; keys that are not valid element names
+// (such as "America/New_York") become <entry key="...">
+func writeXML(out io.Writer, name string, v interface{}) error {
+    open, closeTag := "<"+name+">", "</"+name+">"
+    if !xmlNamePattern.MatchString(name) || strings.HasPrefix(strings.ToLower(name), "xml") {
+        var attr bytes.Buffer
+        _ = xml.EscapeText(&attr, []byte(name))
+        open, closeTag = `<entry key="`+attr.String()+`">`, "</entry>"
+    }
+
+    if _, err := io.WriteString(out, open); err != nil {
+        return err
+    }
+    switch val := v.(type) {
+    case orderedObject:
+        for _, f := range val {
+            if err := writeXML(out, f.key, f.value); err != nil {
+                return err
+            }
+        }
+    case []interface{}:
+        for _, item := range val {
+            if err := writeXML(out, "item", item); err != nil {
+                return err
+            }
+        }
+    case nil:
+    default:
+        if err := xml.EscapeText(out, []byte(fmt.Sprint(val))); err != nil {
+            return err
+        }
+    }
+    _, err := io.WriteString(out, closeTag)
+    return err
+}
+
+// yamlNode converts a decoded value into a YAML node tree
+func yamlNode(v interface{}) *yaml.Node {
+    switch val := v.(type) {
+    case orderedObject:
+        n := &yaml.Node{Kind: yaml.MappingNode}
+        for _, f := range val {
+            n.Content = append(n.Content,
+                &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.key},
+                yamlNode(f.value))
+        }
+        return n
+    case []interface{}:
+        n := &yaml.Node{Kind: yaml.SequenceNode}
+        for _, item := range val {
+            n.Content = append(n.Content, yamlNode(item))
+        }
+        return n
+    case json.Number:
+        tag := "!!int"
+        if strings.ContainsAny(val.String(), ".eE") {
+            tag = "!!float"
+        }
+        return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: val.String()}
+    case bool:
+        return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(val)}
+    case nil:
+        return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+    default:
+        return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(val)}
+    }
+}
+
+// flattenText renders v as key=value lines, using dotted paths for nested
+// objects and [i] for array elements
+func flattenText(prefix string, v interface{}, lines *[]string) {
+    switch val := v.(type) {
+    case orderedObject:
+        for _, f := range val {
+            key := f.key
+            if prefix != "" {
+                key = prefix + "." + key
+            }
+            flattenText(key, f.value, lines)
+        }
+    case []interface{}:
+        for i, item := range val {
+            flattenText(fmt.Sprintf("%s[%d]", prefix, i), item, lines)
+        }
+    case nil:
+        *lines = append(*lines, prefix+"=")
+    default:
+        if prefix == "" {
+            *lines = append(*lines, fmt.Sprint(val))
+            return
+        }
+        *lines = append(*lines, prefix+"="+fmt.Sprint(val))
+    }
+}
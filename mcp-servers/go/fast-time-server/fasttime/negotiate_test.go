@@ -0,0 +1,186 @@
+// -*- coding: utf-8 -*-
+// negotiate_test.go - Tests for REST content negotiation
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bytes"
+    "encoding/hex"
+    "encoding/xml"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "gopkg.in/yaml.v3"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+    tests := []struct {
+        accept, query string
+        want          respFormat
+    }{
+        {"", "", formatJSON},
+        {"*/*", "", formatJSON},
+        {"application/xml", "", formatXML},
+        {"text/xml;q=0.9, application/json;q=0.5", "", formatXML},
+        {"application/json;q=0.5, application/yaml", "", formatYAML},
+        {"text/plain", "", formatText},
+        {"text/html, image/png", "", formatJSON},
+        {"application/json", "format=text", formatText},
+        {"", "format=yml", formatYAML},
+        {"application/xml", "format=bogus", formatXML},
+        {"application/msgpack", "", formatMsgPack},
+        {"application/cbor, application/json;q=0.9", "", formatCBOR},
+        {"", "format=cbor", formatCBOR},
+    }
+    for _, tt := range tests {
+        req := httptest.NewRequest(http.MethodGet, "/api/v1/time?"+tt.query, nil)
+        if tt.accept != "" {
+            req.Header.Set("Accept", tt.accept)
+        }
+        if got := negotiateFormat(req); got != tt.want {
+            t.Errorf("Accept %q, query %q: got %d, want %d", tt.accept, tt.query, got, tt.want)
+        }
+    }
+}
+
+func restGet(t *testing.T, path, accept string) *httptest.ResponseRecorder {
+    t.Helper()
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    req := httptest.NewRequest(http.MethodGet, path, nil)
+    req.Header.Set("Accept", accept)
+    w := httptest.NewRecorder()
+    mux.ServeHTTP(w, req)
+    return w
+}
+
+func TestRESTPlainTextTime(t *testing.T) {
+    w := restGet(t, "/api/v1/time?timezone=UTC", "text/plain")
+    if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+        t.Errorf("Content-Type = %q", ct)
+    }
+    body := strings.TrimSpace(w.Body.String())
+    if _, err := time.Parse(time.RFC3339, body); err != nil {
+        t.Errorf("plain text time should be a bare timestamp, got %q", body)
+    }
+    if !strings.Contains(w.Header().Get("Vary"), "Accept") {
+        t.Error("negotiated responses should vary on Accept")
+    }
+
+    w = restGet(t, "/api/v1/time?timezone=Nowhere/Else", "text/plain")
+    if w.Code != http.StatusBadRequest || strings.HasPrefix(w.Body.String(), "{") {
+        t.Errorf("error as text: %d %q", w.Code, w.Body)
+    }
+}
+
+func TestRESTXMLAndYAML(t *testing.T) {
+    w := restGet(t, "/api/v1/time/Asia/Tokyo", "application/xml")
+    var x struct {
+        XMLName  xml.Name `xml:"response"`
+        Timezone string   `xml:"timezone"`
+        Unix     int64    `xml:"unix"`
+    }
+    if err := xml.Unmarshal(w.Body.Bytes(), &x); err != nil {
+        t.Fatalf("invalid XML: %v\n%s", err, w.Body)
+    }
+    if x.Timezone != "Asia/Tokyo" || x.Unix == 0 {
+        t.Errorf("XML = %+v", x)
+    }
+
+    w = restGet(t, "/api/v1/time/Asia/Tokyo", "application/yaml")
+    var y map[string]interface{}
+    if err := yaml.Unmarshal(w.Body.Bytes(), &y); err != nil {
+        t.Fatalf("invalid YAML: %v\n%s", err, w.Body)
+    }
+    if y["timezone"] != "Asia/Tokyo" {
+        t.Errorf("YAML = %v", y)
+    }
+    // Field order follows the JSON output
+    if !strings.HasPrefix(w.Body.String(), "time:") {
+        t.Errorf("YAML should keep field order:\n%s", w.Body)
+    }
+}
+
+func TestEncodeAsNestedValues(t *testing.T) {
+    data := map[string]interface{}{
+        "zones": map[string]interface{}{"America/New_York": "EST"},
+        "list":  []interface{}{1, "two", nil, true},
+    }
+
+    var x strings.Builder
+    if err := encodeAs(&x, formatXML, data); err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(x.String(), `<entry key="America/New_York">EST</entry>`) ||
+        !strings.Contains(x.String(), "<item>two</item><item></item>") {
+        t.Errorf("XML:\n%s", x.String())
+    }
+
+    var txt strings.Builder
+    if err := encodeAs(&txt, formatText, data); err != nil {
+        t.Fatal(err)
+    }
+    want := "list[0]=1\nlist[1]=two\nlist[2]=\nlist[3]=true\nzones.America/New_York=EST\n"
+    if txt.String() != want {
+        t.Errorf("text = %q, want %q", txt.String(), want)
+    }
+}
+
+func TestEncodeBinaryFormats(t *testing.T) {
+    data := map[string]interface{}{"a": 1, "b": []interface{}{true, nil, "x"}, "big": 300, "f": 1.5, "n": -2}
+    for _, tt := range []struct {
+        f    respFormat
+        want string
+    }{
+        {formatMsgPack, "85" + "a161" + "01" + "a162" + "93c3c0a178" + "a3626967" + "cd012c" + "a166" + "cb3ff8000000000000" + "a16e" + "fe"},
+        {formatCBOR, "a5" + "6161" + "01" + "6162" + "83f5f66178" + "63626967" + "19012c" + "6166" + "fb3ff8000000000000" + "616e" + "21"},
+    } {
+        var buf bytes.Buffer
+        if err := encodeAs(&buf, tt.f, data); err != nil {
+            t.Fatal(err)
+        }
+        if got := hex.EncodeToString(buf.Bytes()); got != tt.want {
+            t.Errorf("%s:\n got %s\nwant %s", tt.f.contentType(), got, tt.want)
+        }
+    }
+
+    // Integer widths at the boundaries
+    for _, tt := range []struct {
+        n             int64
+        msgpack, cbor string
+    }{
+        {127, "7f", "187f"},
+        {-33, "d0df", "3820"},
+        {65536, "ce00010000", "1a00010000"},
+        {-1 << 40, "d3ffffff0000000000", "3b000000ffffffffff"},
+    } {
+        var mp, cb bytes.Buffer
+        _ = encodeAs(&mp, formatMsgPack, tt.n)
+        _ = encodeAs(&cb, formatCBOR, tt.n)
+        if hex.EncodeToString(mp.Bytes()) != tt.msgpack || hex.EncodeToString(cb.Bytes()) != tt.cbor {
+            t.Errorf("%d: msgpack %x, cbor %x", tt.n, mp.Bytes(), cb.Bytes())
+        }
+    }
+}
+
+func TestRESTMsgPack(t *testing.T) {
+    w := restGet(t, "/api/v1/time/Asia/Tokyo", "application/msgpack")
+    if ct := w.Header().Get("Content-Type"); ct != "application/msgpack" {
+        t.Errorf("Content-Type = %q", ct)
+    }
+    // A map whose first key is "time", as in the JSON output
+    if b := w.Body.Bytes(); len(b) < 6 || b[0]&0xf0 != 0x80 || string(b[2:6]) != "time" {
+        t.Errorf("body = %x", b)
+    }
+
+    w = restGet(t, "/api/v1/time?timezone=Nowhere/Else", "application/cbor")
+    if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != "application/cbor" || w.Body.Bytes()[0]&0xe0 != 0xa0 {
+        t.Errorf("error as CBOR: %d %q %x", w.Code, w.Header().Get("Content-Type"), w.Body.Bytes())
+    }
+}
@@ -4,7 +4,7 @@
 // Copyright 2025
 // SPDX-License-Identifier: Apache-2.0
 
-package main
+package fasttime
 
 // getOpenAPISpec returns the OpenAPI specification for the REST API
 func getOpenAPISpec() map[string]interface{} {
@@ -33,7 +33,7 @@ func getOpenAPISpec() map[string]interface{} {
                         {
                             "name":        "timezone",
                             "in":          "query",
-                            "description": "IANA timezone (default: UTC)",
+                            "description": "IANA timezone (default: X-Default-Timezone header, else the server default, UTC unless configured)",
                             "required":    false,
                             "schema": map[string]interface{}{
                                 "type":    "string",
@@ -41,6 +41,16 @@ func getOpenAPISpec() map[string]interface{} {
                                 "example": "America/New_York",
                             },
                         },
+                        {
+                            "name":        "X-Default-Timezone",
+                            "in":          "header",
+                            "description": "Timezone to use when none is given in the query",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "string",
+                                "example": "Europe/Berlin",
+                            },
+                        },
                     },
                     "responses": map[string]interface{}{
                         "200": map[string]interface{}{
@@ -106,10 +116,65 @@ func getOpenAPISpec() map[string]interface{} {
                     },
                 },
             },
+            "/api/v1/time/stream": map[string]interface{}{
+                "get": map[string]interface{}{
+                    "summary":     "Stream current time",
+                    "description": "Streams the current time in the selected timezones as Server-Sent Events",
+                    "parameters": []map[string]interface{}{
+                        {
+                            "name":        "timezones",
+                            "in":          "query",
+                            "description": "Comma-separated IANA timezones (default: UTC)",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "string",
+                                "default": "UTC",
+                                "example": "UTC,Asia/Tokyo",
+                            },
+                        },
+                        {
+                            "name":        "interval",
+                            "in":          "query",
+                            "description": "Time between events as a Go duration (100ms to 1h)",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "string",
+                                "default": "1s",
+                                "example": "5s",
+                            },
+                        },
+                    },
+                    "responses": map[string]interface{}{
+                        "200": map[string]interface{}{
+                            "description": "Stream of time events",
+                            "content": map[string]interface{}{
+                                "text/event-stream": map[string]interface{}{
+                                    "schema": map[string]interface{}{
+                                        "type": "string",
+                                    },
+                                },
+                            },
+                        },
+                        "400": map[string]interface{}{
+                            "description": "Invalid timezone or interval",
+                            "content": map[string]interface{}{
+                                "application/json": map[string]interface{}{
+                                    "schema": map[string]interface{}{
+                                        "$ref": "#/components/schemas/ErrorResponse",
+                                    },
+                                },
+                            },
+                        },
+                    },
+                },
+            },
             "/api/v1/convert": map[string]interface{}{
                 "post": map[string]interface{}{
                     "summary":     "Convert time between timezones",
                     "description": "Converts a given time from one timezone to another",
+                    "parameters": []map[string]interface{}{
+                        {"$ref": "#/components/parameters/IdempotencyKey"},
+                    },
                     "requestBody": map[string]interface{}{
                         "required": true,
                         "content": map[string]interface{}{
@@ -148,6 +213,9 @@ func getOpenAPISpec() map[string]interface{} {
                 "post": map[string]interface{}{
                     "summary":     "Batch convert times",
                     "description": "Convert multiple times between timezones in a single request",
+                    "parameters": []map[string]interface{}{
+                        {"$ref": "#/components/parameters/IdempotencyKey"},
+                    },
                     "requestBody": map[string]interface{}{
                         "required": true,
                         "content": map[string]interface{}{
@@ -172,10 +240,90 @@ func getOpenAPISpec() map[string]interface{} {
                     },
                 },
             },
+            "/api/v1/schedules": map[string]interface{}{
+                "get": map[string]interface{}{
+                    "summary":     "List webhook schedules",
+                    "description": "Schedules visible to the caller: its own for a scoped token, all for the main token. Needs authentication",
+                    "parameters": []map[string]interface{}{
+                        {
+                            "name":     "status",
+                            "in":       "query",
+                            "required": false,
+                            "schema": map[string]interface{}{
+                                "type": "string",
+                                "enum": []string{scheduleActive, scheduleCompleted, scheduleFailed},
+                            },
+                        },
+                    },
+                    "responses": map[string]interface{}{
+                        "200": map[string]interface{}{"description": "Schedules and their count"},
+                        "403": map[string]interface{}{"description": "Authentication is not enabled on the server"},
+                    },
+                },
+                "post": map[string]interface{}{
+                    "summary":     "Register a webhook schedule",
+                    "description": "POST url at a local time (at) or on a cron expression (cron) in timezone. Deliveries are retried with backoff and signed with -webhook-secret",
+                    "parameters": []map[string]interface{}{
+                        {"$ref": "#/components/parameters/IdempotencyKey"},
+                    },
+                    "requestBody": map[string]interface{}{
+                        "required": true,
+                        "content": map[string]interface{}{
+                            "application/json": map[string]interface{}{
+                                "schema": map[string]interface{}{
+                                    "$ref": "#/components/schemas/ScheduleRequest",
+                                },
+                            },
+                        },
+                    },
+                    "responses": map[string]interface{}{
+                        "201": map[string]interface{}{
+                            "description": "Schedule registered",
+                            "content": map[string]interface{}{
+                                "application/json": map[string]interface{}{
+                                    "schema": map[string]interface{}{
+                                        "$ref": "#/components/schemas/Schedule",
+                                    },
+                                },
+                            },
+                        },
+                        "400": map[string]interface{}{"description": "Invalid schedule"},
+                        "403": map[string]interface{}{"description": "Authentication is not enabled on the server"},
+                    },
+                },
+            },
+            "/api/v1/schedules/{id}": map[string]interface{}{
+                "parameters": []map[string]interface{}{
+                    {"name": "id", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}},
+                },
+                "get": map[string]interface{}{
+                    "summary": "Get a webhook schedule",
+                    "responses": map[string]interface{}{
+                        "200": map[string]interface{}{
+                            "description": "The schedule and its last delivery",
+                            "content": map[string]interface{}{
+                                "application/json": map[string]interface{}{
+                                    "schema": map[string]interface{}{
+                                        "$ref": "#/components/schemas/Schedule",
+                                    },
+                                },
+                            },
+                        },
+                        "404": map[string]interface{}{"description": "Unknown schedule"},
+                    },
+                },
+                "delete": map[string]interface{}{
+                    "summary": "Cancel a webhook schedule",
+                    "responses": map[string]interface{}{
+                        "204": map[string]interface{}{"description": "Cancelled"},
+                        "404": map[string]interface{}{"description": "Unknown schedule"},
+                    },
+                },
+            },
             "/api/v1/timezones": map[string]interface{}{
                 "get": map[string]interface{}{
                     "summary":     "List available timezones",
-                    "description": "Returns a list of available IANA timezones",
+                    "description": "Returns a list of available IANA timezones. With page or per_page the list is paged and the response carries Link (first, prev, next, last) and X-Total-Count headers",
                     "parameters": []map[string]interface{}{
                         {
                             "name":        "filter",
@@ -187,6 +335,59 @@ func getOpenAPISpec() map[string]interface{} {
                                 "example": "America",
                             },
                         },
+                        {
+                            "name":        "all",
+                            "in":          "query",
+                            "description": "List every canonical zone of the timezone database instead of the common ones",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "boolean",
+                                "default": false,
+                            },
+                        },
+                        {
+                            "name":        "offset",
+                            "in":          "query",
+                            "description": "Only zones currently at this UTC offset, e.g. +02:00 (encode + as %2B)",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "string",
+                                "example": "+02:00",
+                            },
+                        },
+                        {
+                            "name":        "sort",
+                            "in":          "query",
+                            "description": "Sort by name (id) or current UTC offset (offset); prefix - to reverse",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type": "string",
+                                "enum": []string{"id", "-id", "offset", "-offset"},
+                            },
+                        },
+                        {
+                            "name":        "page",
+                            "in":          "query",
+                            "description": "Page to return, from 1",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "integer",
+                                "minimum": 1,
+                                "default": 1,
+                            },
+                        },
+                        {
+                            "name":        "per_page",
+                            "in":          "query",
+                            "description": "Zones per page",
+                            "required":    false,
+                            "schema": map[string]interface{}{
+                                "type":    "integer",
+                                "minimum": 1,
+                                "maximum": maxTimezonesPerPage,
+                                "default": defaultTimezonesPerPage,
+                            },
+                        },
                     },
                     "responses": map[string]interface{}{
                         "200": map[string]interface{}{
@@ -203,6 +404,20 @@ func getOpenAPISpec() map[string]interface{} {
                                                 },
                                             },
                                             "count": map[string]interface{}{
+                                                "type":        "integer",
+                                                "description": "Zones in this response",
+                                            },
+                                            "total": map[string]interface{}{
+                                                "type":        "integer",
+                                                "description": "Zones matching the query (paged responses only)",
+                                            },
+                                            "page": map[string]interface{}{
+                                                "type": "integer",
+                                            },
+                                            "per_page": map[string]interface{}{
+                                                "type": "integer",
+                                            },
+                                            "total_pages": map[string]interface{}{
                                                 "type": "integer",
                                             },
                                         },
@@ -210,6 +425,49 @@ func getOpenAPISpec() map[string]interface{} {
                                 },
                             },
                         },
+                        "400": map[string]interface{}{
+                            "description": "Invalid offset, sort, page or per_page",
+                        },
+                    },
+                },
+            },
+            "/api/v1/timezones/{timezone}": map[string]interface{}{
+                "get": map[string]interface{}{
+                    "summary":     "Get timezone detail",
+                    "description": "Returns the offset, DST state, abbreviation, next transition, country and example cities of a timezone, as the timezone://zones/{zone} resource does",
+                    "parameters": []map[string]interface{}{
+                        {
+                            "name":        "timezone",
+                            "in":          "path",
+                            "description": "IANA timezone",
+                            "required":    true,
+                            "schema": map[string]interface{}{
+                                "type":    "string",
+                                "example": "Asia/Tokyo",
+                            },
+                        },
+                    },
+                    "responses": map[string]interface{}{
+                        "200": map[string]interface{}{
+                            "description": "Timezone detail",
+                            "content": map[string]interface{}{
+                                "application/json": map[string]interface{}{
+                                    "schema": map[string]interface{}{
+                                        "$ref": "#/components/schemas/TimezoneDetail",
+                                    },
+                                },
+                            },
+                        },
+                        "400": map[string]interface{}{
+                            "description": "Invalid timezone",
+                            "content": map[string]interface{}{
+                                "application/json": map[string]interface{}{
+                                    "schema": map[string]interface{}{
+                                        "$ref": "#/components/schemas/ErrorResponse",
+                                    },
+                                },
+                            },
+                        },
                     },
                 },
             },
@@ -570,7 +828,30 @@ func getOpenAPISpec() map[string]interface{} {
                 },
             },
         },
+        // Only enforced when the server runs with -auth-token
+        "security": []map[string]interface{}{
+            {"bearerAuth": []string{}},
+        },
         "components": map[string]interface{}{
+            "securitySchemes": map[string]interface{}{
+                "bearerAuth": map[string]interface{}{
+                    "type":        "http",
+                    "scheme":      "bearer",
+                    "description": "Value of -auth-token or AUTH_TOKEN",
+                },
+            },
+            "parameters": map[string]interface{}{
+                "IdempotencyKey": map[string]interface{}{
+                    "name":        "Idempotency-Key",
+                    "in":          "header",
+                    "description": "Replay the kept response of an earlier request with this key instead of processing it again",
+                    "required":    false,
+                    "schema": map[string]interface{}{
+                        "type":      "string",
+                        "maxLength": maxIdempotencyKeyLen,
+                    },
+                },
+            },
             "schemas": map[string]interface{}{
                 "TimeResponse": map[string]interface{}{
                     "type": "object",
@@ -666,6 +947,38 @@ func getOpenAPISpec() map[string]interface{} {
                         },
                     },
                 },
+                "ScheduleRequest": map[string]interface{}{
+                    "type":     "object",
+                    "required": []string{"url"},
+                    "properties": map[string]interface{}{
+                        "url":      map[string]interface{}{"type": "string", "example": "https://hooks.example.com/run"},
+                        "at":       map[string]interface{}{"type": "string", "description": "One-shot local time (or RFC3339)", "example": "2025-03-09T02:00"},
+                        "cron":     map[string]interface{}{"type": "string", "description": "Five-field cron expression or @daily style shorthand", "example": "0 9 * * MON-FRI"},
+                        "timezone": map[string]interface{}{"type": "string", "example": "America/New_York"},
+                        "payload":  map[string]interface{}{"description": "JSON passed through in each delivery"},
+                        "headers":  map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+                    },
+                },
+                "Schedule": map[string]interface{}{
+                    "type": "object",
+                    "properties": map[string]interface{}{
+                        "id":          map[string]interface{}{"type": "string"},
+                        "url":         map[string]interface{}{"type": "string"},
+                        "at":          map[string]interface{}{"type": "string"},
+                        "cron":        map[string]interface{}{"type": "string"},
+                        "timezone":    map[string]interface{}{"type": "string"},
+                        "status":      map[string]interface{}{"type": "string", "enum": []string{scheduleActive, scheduleCompleted, scheduleFailed}},
+                        "next_run":    map[string]interface{}{"type": "string", "format": "date-time"},
+                        "retry_at":    map[string]interface{}{"type": "string", "format": "date-time"},
+                        "attempt":     map[string]interface{}{"type": "integer"},
+                        "runs":        map[string]interface{}{"type": "integer"},
+                        "last_run":    map[string]interface{}{"type": "string", "format": "date-time"},
+                        "last_status": map[string]interface{}{"type": "integer"},
+                        "last_error":  map[string]interface{}{"type": "string"},
+                        "note":        map[string]interface{}{"type": "string", "description": "Set when the local time falls in a DST gap"},
+                        "created_at":  map[string]interface{}{"type": "string", "format": "date-time"},
+                    },
+                },
                 "TimezoneInfo": map[string]interface{}{
                     "type": "object",
                     "properties": map[string]interface{}{
@@ -693,6 +1006,60 @@ func getOpenAPISpec() map[string]interface{} {
                         },
                     },
                 },
+                "TimezoneDetail": map[string]interface{}{
+                    "type": "object",
+                    "properties": map[string]interface{}{
+                        "name": map[string]interface{}{
+                            "type":        "string",
+                            "description": "Timezone name",
+                        },
+                        "canonical": map[string]interface{}{
+                            "type":        "string",
+                            "description": "Canonical zone when name is a link",
+                        },
+                        "current_time": map[string]interface{}{
+                            "type":        "string",
+                            "description": "Current time in this timezone",
+                        },
+                        "utc_offset": map[string]interface{}{
+                            "type":    "string",
+                            "example": "+09:00",
+                        },
+                        "offset_seconds": map[string]interface{}{
+                            "type": "integer",
+                        },
+                        "abbreviation": map[string]interface{}{
+                            "type":    "string",
+                            "example": "JST",
+                        },
+                        "is_dst": map[string]interface{}{
+                            "type": "boolean",
+                        },
+                        "observes_dst": map[string]interface{}{
+                            "type": "boolean",
+                        },
+                        "next_transition": map[string]interface{}{
+                            "type":        "object",
+                            "description": "Next offset change: at, utc_offset, abbreviation, is_dst",
+                        },
+                        "country": map[string]interface{}{
+                            "type":        "object",
+                            "description": "ISO 3166 code and name of the zone's country",
+                        },
+                        "countries": map[string]interface{}{
+                            "type":        "array",
+                            "description": "All countries using the zone, when more than one",
+                        },
+                        "comment": map[string]interface{}{
+                            "type":        "string",
+                            "description": "Region covered, from zone1970.tab",
+                        },
+                        "example_cities": map[string]interface{}{
+                            "type":  "array",
+                            "items": map[string]interface{}{"type": "string"},
+                        },
+                    },
+                },
                 "ErrorResponse": map[string]interface{}{
                     "type": "object",
                     "properties": map[string]interface{}{
@@ -708,6 +1075,24 @@ func getOpenAPISpec() map[string]interface{} {
                             "type":        "integer",
                             "description": "HTTP status code",
                         },
+                        "error_code": map[string]interface{}{
+                            "type":        "string",
+                            "description": "Machine-readable error code",
+                            "example":     "INVALID_TIMEZONE",
+                        },
+                        "field": map[string]interface{}{
+                            "type":        "string",
+                            "description": "Request field the error is about",
+                        },
+                        "hint": map[string]interface{}{
+                            "type":        "string",
+                            "description": "How to correct the request",
+                        },
+                        "suggestions": map[string]interface{}{
+                            "type":        "array",
+                            "items":       map[string]interface{}{"type": "string"},
+                            "description": "Closest valid values, e.g. timezones for INVALID_TIMEZONE",
+                        },
                     },
                 },
             },
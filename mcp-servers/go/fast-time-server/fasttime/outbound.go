@@ -0,0 +1,568 @@
+// -*- coding: utf-8 -*-
+// outbound.go - shared client for outgoing HTTP calls
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Features that call other services over HTTP (free/busy calendars, webhook
+// deliveries) go through one client instead of building their own:
+//
+//   - every attempt has a timeout (-outbound-timeout, or the caller's own);
+//   - network errors, 429 and 5xx answers are retried -outbound-retries
+//     times with exponential backoff and jitter, honouring Retry-After;
+//   - a circuit breaker per host opens after -outbound-breaker-failures
+//     failed attempts in a row and rejects calls for
+//     -outbound-breaker-cooldown, then lets one trial call through. Calls
+//     for a registered backend (free/busy calendars) skip it: the backend's
+//     own breaker in backends.go is the one that decides;
+//   - 200 answers to cacheable reads are kept for -outbound-cache-ttl
+//     (shorter if the server says so with Cache-Control), keyed by method,
+//     URL, body and credentials;
+//   - -outbound-proxy sends everything through an HTTP proxy, by default
+//     the one named by HTTP_PROXY/HTTPS_PROXY;
+//   - calls marked publicOnly (webhooks, whose URLs come from API clients)
+//     refuse loopback, private, link-local and unspecified addresses. The
+//     check runs on the address actually dialled, after DNS, so a name
+//     that resolves or rebinds to an internal address is refused too. These
+//     calls connect directly: through a proxy the check would only see the
+//     proxy.
+//
+// Counters per feature and the breaker state per host are reported as
+// "outbound" in /debug/vars. NTP queries use UDP and keep their own client.
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "math/rand"
+    "net"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+)
+
+// Outbound defaults and limits
+const (
+    defaultOutboundTimeout         = 10 * time.Second
+    defaultOutboundRetries         = 2
+    defaultOutboundCacheTTL        = time.Minute
+    defaultOutboundBreakerFailures = 5
+    defaultOutboundBreakerCooldown = 30 * time.Second
+
+    outboundBackoffBase  = 200 * time.Millisecond // doubled after each attempt
+    outboundMaxBackoff   = 5 * time.Second        // also the longest Retry-After honoured
+    outboundMaxResponse  = 4 << 20                // bytes kept from an answer
+    outboundCacheEntries = 256
+    outboundMaxRetries   = 10
+    outboundErrorSnippet = 200 // bytes of an error answer quoted in errors
+)
+
+// errCircuitOpen is returned while a host's breaker rejects calls
+var errCircuitOpen = errors.New("circuit open")
+
+// errPrivateAddress is returned when a publicOnly call would reach an
+// internal address
+var errPrivateAddress = errors.New("refusing to connect to a non-public address")
+
+// outboundConfig configures the shared client
+type outboundConfig struct {
+    timeout         time.Duration
+    retries         int
+    cacheTTL        time.Duration // 0 disables the cache
+    breakerFailures int           // 0 disables the breaker
+    breakerCooldown time.Duration
+    proxy           string // empty: from the environment
+    backoff         time.Duration
+}
+
+// outboundRequest is one call; body is resent on each attempt
+type outboundRequest struct {
+    feature string // counted under this name, e.g. "free-busy"
+    method  string
+    url     string
+    header  http.Header
+    body    []byte
+    timeout time.Duration // per attempt; 0 uses -outbound-timeout
+
+    cache      bool // a read whose 200 answer may be cached
+    noRetry    bool // the caller retries on its own schedule
+    noRedirect bool // answer redirects instead of following them
+    noBreaker  bool // the caller is a backend with its own breaker
+    publicOnly bool // refuse loopback, private and link-local addresses
+}
+
+// outboundResponse is a fully read answer
+type outboundResponse struct {
+    status int
+    header http.Header
+    body   []byte
+    cached bool
+}
+
+// err returns an error for a non-2xx answer, quoting its start
+func (r *outboundResponse) err() error {
+    if r.status >= 200 && r.status <= 299 {
+        return nil
+    }
+    msg := strings.TrimSpace(string(r.body))
+    if len(msg) > outboundErrorSnippet {
+        msg = msg[:outboundErrorSnippet]
+    }
+    if msg == "" {
+        return fmt.Errorf("HTTP %d", r.status)
+    }
+    return fmt.Errorf("HTTP %d: %s", r.status, msg)
+}
+
+// outboundBreaker is the circuit breaker of one host
+type outboundBreaker struct {
+    failures  int       // consecutive failed attempts
+    openUntil time.Time // zero while closed
+    trial     bool      // a half-open trial call is running
+    opened    int       // times the breaker opened
+}
+
+// outboundStats counts the calls of one feature
+type outboundStats struct {
+    requests  int64 // calls, cache hits included
+    attempts  int64 // requests sent
+    retries   int64
+    failures  int64 // calls that ended in an error or a non-2xx answer
+    cacheHits int64
+    rejected  int64 // attempts refused by an open breaker
+    lastError string
+    totalTime time.Duration // of calls not answered from the cache
+}
+
+// outboundCacheEntry is a cached answer
+type outboundCacheEntry struct {
+    resp    outboundResponse
+    expires time.Time
+}
+
+// outboundClient is the shared client
+type outboundClient struct {
+    cfg      outboundConfig
+    client   *http.Client
+    noFollow *http.Client
+    public   http.RoundTripper // for publicOnly calls
+
+    mu       sync.Mutex // guards the maps below
+    breakers map[string]*outboundBreaker
+    features map[string]*outboundStats
+    cache    map[string]outboundCacheEntry
+}
+
+// outbound is the client used by all features; New replaces it with one
+// built from the flags
+var outbound = newOutboundClient(defaultOutboundConfig())
+
+// defaultOutboundConfig returns the configuration without flags
+func defaultOutboundConfig() outboundConfig {
+    return outboundConfig{
+        timeout:         defaultOutboundTimeout,
+        retries:         defaultOutboundRetries,
+        cacheTTL:        defaultOutboundCacheTTL,
+        breakerFailures: defaultOutboundBreakerFailures,
+        breakerCooldown: defaultOutboundBreakerCooldown,
+        backoff:         outboundBackoffBase,
+    }
+}
+
+// checkOutboundConfig validates the outbound flags
+func checkOutboundConfig(cfg outboundConfig) error {
+    switch {
+    case cfg.timeout <= 0:
+        return fmt.Errorf("-outbound-timeout must be positive")
+    case cfg.retries < 0 || cfg.retries > outboundMaxRetries:
+        return fmt.Errorf("-outbound-retries must be between 0 and %d", outboundMaxRetries)
+    case cfg.cacheTTL < 0:
+        return fmt.Errorf("-outbound-cache-ttl must not be negative")
+    case cfg.breakerFailures < 0:
+        return fmt.Errorf("-outbound-breaker-failures must not be negative")
+    case cfg.breakerFailures > 0 && cfg.breakerCooldown <= 0:
+        return fmt.Errorf("-outbound-breaker-cooldown must be positive")
+    }
+    if cfg.proxy != "" {
+        if u, err := url.Parse(cfg.proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+            return fmt.Errorf("invalid -outbound-proxy %q (use http://host:port)", cfg.proxy)
+        }
+    }
+    return nil
+}
+
+// newOutboundClient builds a client; cfg must have passed checkOutboundConfig
+func newOutboundClient(cfg outboundConfig) *outboundClient {
+    transport := http.DefaultTransport.(*http.Transport).Clone()
+    transport.Proxy = http.ProxyFromEnvironment
+    if cfg.proxy != "" {
+        u, _ := url.Parse(cfg.proxy)
+        transport.Proxy = http.ProxyURL(u)
+    }
+    if cfg.backoff <= 0 {
+        cfg.backoff = outboundBackoffBase
+    }
+    public := http.DefaultTransport.(*http.Transport).Clone()
+    public.Proxy = nil
+    public.DialContext = (&net.Dialer{
+        Timeout:   30 * time.Second,
+        KeepAlive: 30 * time.Second,
+        Control:   dialPublicOnly,
+    }).DialContext
+    return &outboundClient{
+        cfg:    cfg,
+        public: public,
+        client: &http.Client{Transport: transport},
+        noFollow: &http.Client{
+            Transport:     transport,
+            CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+        },
+        breakers: make(map[string]*outboundBreaker),
+        features: make(map[string]*outboundStats),
+        cache:    make(map[string]outboundCacheEntry),
+    }
+}
+
+// cacheKey identifies a cacheable request, credentials included so that
+// callers never see answers meant for another account
+func (r *outboundRequest) cacheKey() string {
+    h := sha256.New()
+    for _, s := range []string{r.method, r.url, r.header.Get("Authorization"), r.header.Get("Depth")} {
+        h.Write([]byte(s))
+        h.Write([]byte{0})
+    }
+    h.Write(r.body)
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// do runs r with retries, the breaker and the cache. A non-2xx answer is
+// returned as a response, not an error, once retries are used up.
+func (o *outboundClient) do(ctx context.Context, r outboundRequest) (*outboundResponse, error) {
+    if r.header == nil {
+        r.header = http.Header{}
+    }
+    u, err := url.Parse(r.url)
+    if err != nil || u.Host == "" {
+        return nil, fmt.Errorf("invalid URL %q", r.url)
+    }
+    host := u.Host
+    start := time.Now()
+
+    key := ""
+    if r.cache && o.cfg.cacheTTL > 0 {
+        key = r.cacheKey()
+        if resp, ok := o.cached(key); ok {
+            o.count(r.feature, func(s *outboundStats) { s.requests++; s.cacheHits++ })
+            return resp, nil
+        }
+    }
+    o.count(r.feature, func(s *outboundStats) { s.requests++ })
+
+    attempts := o.cfg.retries + 1
+    if r.noRetry {
+        attempts = 1
+    }
+    var resp *outboundResponse
+    for attempt := 0; attempt < attempts; attempt++ {
+        if attempt > 0 {
+            if err := sleepCtx(ctx, o.backoff(attempt, resp)); err != nil {
+                break
+            }
+            o.count(r.feature, func(s *outboundStats) { s.retries++ })
+        }
+        if !r.noBreaker {
+            if err = o.allow(host); err != nil {
+                o.count(r.feature, func(s *outboundStats) { s.rejected++ })
+                break
+            }
+        }
+        o.count(r.feature, func(s *outboundStats) { s.attempts++ })
+        resp, err = o.attempt(ctx, r)
+        failed := err != nil || resp.status == http.StatusTooManyRequests || resp.status >= 500
+        if !r.noBreaker {
+            o.record(host, failed)
+        }
+        if !failed || ctx.Err() != nil {
+            break
+        }
+    }
+
+    o.count(r.feature, func(s *outboundStats) {
+        s.totalTime += time.Since(start)
+        switch {
+        case err != nil:
+            s.failures++
+            s.lastError = err.Error()
+        case resp.err() != nil:
+            s.failures++
+            s.lastError = resp.err().Error()
+        }
+    })
+    if err != nil {
+        if errors.Is(err, errCircuitOpen) {
+            return nil, fmt.Errorf("%s: %w", host, err)
+        }
+        return nil, err
+    }
+    if key != "" && resp.status == http.StatusOK {
+        o.store(key, *resp)
+    }
+    return resp, nil
+}
+
+// attempt sends r once and reads the answer
+func (o *outboundClient) attempt(ctx context.Context, r outboundRequest) (*outboundResponse, error) {
+    timeout := r.timeout
+    if timeout <= 0 {
+        timeout = o.cfg.timeout
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, r.method, r.url, bytes.NewReader(r.body))
+    if err != nil {
+        return nil, err
+    }
+    req.Header = r.header.Clone()
+    if req.Header.Get("User-Agent") == "" {
+        req.Header.Set("User-Agent", appName+"/"+appVersion)
+    }
+    client := o.client
+    if r.noRedirect {
+        client = o.noFollow
+    }
+    if r.publicOnly {
+        c := *client
+        c.Transport = o.public
+        client = &c
+    }
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    body, err := io.ReadAll(io.LimitReader(resp.Body, outboundMaxResponse))
+    if err != nil {
+        return nil, err
+    }
+    return &outboundResponse{status: resp.StatusCode, header: resp.Header, body: body}, nil
+}
+
+// publicIP reports whether ip may be called by a publicOnly request
+func publicIP(ip net.IP) bool {
+    return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+        !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast()
+}
+
+// dialPublicOnly is the net.Dialer.Control of publicOnly calls: it sees the
+// resolved address about to be connected
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+    host, _, err := net.SplitHostPort(address)
+    if err != nil {
+        return err
+    }
+    if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+        return fmt.Errorf("%w %s", errPrivateAddress, host)
+    }
+    return nil
+}
+
+// backoff returns the wait before attempt (1, 2, ...): the Retry-After of
+// the last answer when given, else base * 2^(attempt-1) with up to 50%
+// jitter, capped at outboundMaxBackoff
+func (o *outboundClient) backoff(attempt int, last *outboundResponse) time.Duration {
+    if last != nil {
+        if secs, err := strconv.Atoi(last.header.Get("Retry-After")); err == nil && secs >= 0 {
+            return min(time.Duration(secs)*time.Second, outboundMaxBackoff)
+        }
+    }
+    d := o.cfg.backoff << (attempt - 1)
+    d += time.Duration(rand.Int63n(int64(d)/2 + 1))
+    return min(d, outboundMaxBackoff)
+}
+
+// sleepCtx waits for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+    t := time.NewTimer(d)
+    defer t.Stop()
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-t.C:
+        return nil
+    }
+}
+
+// allow reports whether host's breaker lets a call through
+func (o *outboundClient) allow(host string) error {
+    if o.cfg.breakerFailures == 0 {
+        return nil
+    }
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    b := o.breakers[host]
+    switch {
+    case b == nil || b.openUntil.IsZero():
+        return nil
+    case time.Now().Before(b.openUntil) || b.trial:
+        return errCircuitOpen
+    }
+    b.trial = true // half-open: one call decides
+    return nil
+}
+
+// record updates host's breaker with the outcome of an attempt
+func (o *outboundClient) record(host string, failed bool) {
+    if o.cfg.breakerFailures == 0 {
+        return
+    }
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    b := o.breakers[host]
+    if b == nil {
+        b = &outboundBreaker{}
+        o.breakers[host] = b
+    }
+    trial := b.trial
+    b.trial = false
+    if !failed {
+        b.failures, b.openUntil = 0, time.Time{}
+        return
+    }
+    b.failures++
+    if trial || b.failures >= o.cfg.breakerFailures {
+        if b.openUntil.IsZero() || trial {
+            b.opened++
+            logAt(logWarn, "outbound: circuit for %s open for %s after %d failure(s)", host, o.cfg.breakerCooldown, b.failures)
+        }
+        b.openUntil = time.Now().Add(o.cfg.breakerCooldown)
+    }
+}
+
+// cached returns a fresh cached answer
+func (o *outboundClient) cached(key string) (*outboundResponse, bool) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    e, ok := o.cache[key]
+    if !ok || time.Now().After(e.expires) {
+        delete(o.cache, key)
+        return nil, false
+    }
+    resp := e.resp
+    resp.cached = true
+    return &resp, true
+}
+
+// store caches resp unless its Cache-Control forbids it; max-age shortens
+// the TTL
+func (o *outboundClient) store(key string, resp outboundResponse) {
+    ttl := o.cfg.cacheTTL
+    for _, d := range strings.Split(strings.ToLower(resp.header.Get("Cache-Control")), ",") {
+        d = strings.TrimSpace(d)
+        switch {
+        case d == "no-store" || d == "no-cache" || d == "private":
+            return
+        case strings.HasPrefix(d, "max-age="):
+            if secs, err := strconv.Atoi(strings.TrimPrefix(d, "max-age=")); err == nil && time.Duration(secs)*time.Second < ttl {
+                ttl = time.Duration(secs) * time.Second
+            }
+        }
+    }
+    if ttl <= 0 {
+        return
+    }
+
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    now := time.Now()
+    if len(o.cache) >= outboundCacheEntries {
+        // Drop expired entries, then the one expiring first
+        oldest := ""
+        for k, e := range o.cache {
+            if now.After(e.expires) {
+                delete(o.cache, k)
+            } else if oldest == "" || e.expires.Before(o.cache[oldest].expires) {
+                oldest = k
+            }
+        }
+        if len(o.cache) >= outboundCacheEntries {
+            delete(o.cache, oldest)
+        }
+    }
+    o.cache[key] = outboundCacheEntry{resp: resp, expires: now.Add(ttl)}
+}
+
+// count updates the statistics of feature
+func (o *outboundClient) count(feature string, fn func(*outboundStats)) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    s := o.features[feature]
+    if s == nil {
+        s = &outboundStats{}
+        o.features[feature] = s
+    }
+    fn(s)
+}
+
+// stats reports the counters for /debug/vars
+func (o *outboundClient) stats() map[string]interface{} {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    features := make(map[string]interface{}, len(o.features))
+    for name, s := range o.features {
+        entry := map[string]interface{}{
+            "requests":           s.requests,
+            "attempts":           s.attempts,
+            "retries":            s.retries,
+            "failures":           s.failures,
+            "cache_hits":         s.cacheHits,
+            "circuit_rejections": s.rejected,
+        }
+        if n := s.requests - s.cacheHits; n > 0 {
+            entry["avg_ms"] = float64(s.totalTime.Microseconds()) / float64(n) / 1000
+        }
+        if s.lastError != "" {
+            entry["last_error"] = s.lastError
+        }
+        features[name] = entry
+    }
+
+    hosts := make([]string, 0, len(o.breakers))
+    for h := range o.breakers {
+        hosts = append(hosts, h)
+    }
+    sort.Strings(hosts)
+    breakers := make(map[string]interface{}, len(hosts))
+    now := time.Now()
+    for _, h := range hosts {
+        b := o.breakers[h]
+        state := "closed"
+        switch {
+        case b.trial || (!b.openUntil.IsZero() && !now.Before(b.openUntil)):
+            state = "half-open"
+        case !b.openUntil.IsZero():
+            state = "open"
+        }
+        entry := map[string]interface{}{"state": state, "failures": b.failures, "opened": b.opened}
+        if state == "open" {
+            entry["open_until"] = b.openUntil.UTC().Format(time.RFC3339)
+        }
+        breakers[h] = entry
+    }
+    return map[string]interface{}{
+        "features":      features,
+        "breakers":      breakers,
+        "cache_entries": len(o.cache),
+    }
+}
@@ -0,0 +1,253 @@
+// -*- coding: utf-8 -*-
+// outbound_test.go - tests for the shared outgoing HTTP client
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// useTestOutbound installs a fresh outbound client with a short backoff,
+// changed by edit, and restores the previous one after the test
+func useTestOutbound(t *testing.T, edit func(*outboundConfig)) *outboundClient {
+    t.Helper()
+    prev := outbound
+    t.Cleanup(func() { outbound = prev })
+    cfg := defaultOutboundConfig()
+    cfg.backoff = time.Millisecond
+    if edit != nil {
+        edit(&cfg)
+    }
+    if err := checkOutboundConfig(cfg); err != nil {
+        t.Fatal(err)
+    }
+    outbound = newOutboundClient(cfg)
+    return outbound
+}
+
+// get sends a GET for feature "test" through o
+func get(o *outboundClient, url string, cache bool) (*outboundResponse, error) {
+    return o.do(context.Background(), outboundRequest{feature: "test", method: http.MethodGet, url: url, cache: cache})
+}
+
+func TestOutboundRetries(t *testing.T) {
+    var calls atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if calls.Add(1) < 3 {
+            w.WriteHeader(http.StatusServiceUnavailable)
+            return
+        }
+        io.WriteString(w, "ok")
+    }))
+    defer srv.Close()
+
+    o := useTestOutbound(t, nil)
+    resp, err := get(o, srv.URL, false)
+    if err != nil || resp.status != http.StatusOK || string(resp.body) != "ok" || calls.Load() != 3 {
+        t.Fatalf("resp = %+v, %v after %d calls", resp, err, calls.Load())
+    }
+
+    // A 4xx answer is final and no failure of the host
+    notFound := httptest.NewServer(http.NotFoundHandler())
+    defer notFound.Close()
+    resp, err = get(o, notFound.URL, false)
+    if err != nil || resp.status != http.StatusNotFound || resp.err() == nil {
+        t.Fatalf("404: %+v, %v", resp, err)
+    }
+
+    // noRetry leaves retrying to the caller
+    calls.Store(0)
+    resp, _ = o.do(context.Background(), outboundRequest{feature: "test", method: http.MethodGet, url: srv.URL, noRetry: true})
+    if resp.status != http.StatusServiceUnavailable || calls.Load() != 1 {
+        t.Errorf("noRetry: %d after %d calls", resp.status, calls.Load())
+    }
+
+    stats := o.stats()["features"].(map[string]interface{})["test"].(map[string]interface{})
+    if stats["requests"] != int64(3) || stats["retries"] != int64(2) || stats["failures"] != int64(2) {
+        t.Errorf("stats = %v", stats)
+    }
+}
+
+func TestOutboundRetryAfter(t *testing.T) {
+    var calls atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if calls.Add(1) == 1 {
+            w.Header().Set("Retry-After", "1")
+            w.WriteHeader(http.StatusTooManyRequests)
+        }
+    }))
+    defer srv.Close()
+
+    o := useTestOutbound(t, nil)
+    start := time.Now()
+    if resp, err := get(o, srv.URL, false); err != nil || resp.status != http.StatusOK {
+        t.Fatalf("resp = %+v, %v", resp, err)
+    }
+    if waited := time.Since(start); waited < time.Second {
+        t.Errorf("retried after %s, want the 1s of Retry-After", waited)
+    }
+
+    // A cancelled context stops the wait
+    calls.Store(0)
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    resp, err := o.do(ctx, outboundRequest{feature: "test", method: http.MethodGet, url: srv.URL})
+    if err != nil || resp.status != http.StatusTooManyRequests || time.Since(start) > 3*time.Second {
+        t.Errorf("cancelled: %+v, %v", resp, err)
+    }
+}
+
+func TestOutboundBreaker(t *testing.T) {
+    var healthy atomic.Bool
+    var calls atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        calls.Add(1)
+        if !healthy.Load() {
+            w.WriteHeader(http.StatusBadGateway)
+        }
+    }))
+    defer srv.Close()
+
+    o := useTestOutbound(t, func(c *outboundConfig) {
+        c.retries, c.breakerFailures, c.breakerCooldown = 0, 2, 50*time.Millisecond
+    })
+    for i := 0; i < 2; i++ {
+        if resp, err := get(o, srv.URL, false); err != nil || resp.status != http.StatusBadGateway {
+            t.Fatalf("call %d: %+v, %v", i, resp, err)
+        }
+    }
+    if _, err := get(o, srv.URL, false); !errors.Is(err, errCircuitOpen) || calls.Load() != 2 {
+        t.Fatalf("open breaker: %v after %d calls", err, calls.Load())
+    }
+    host := strings.TrimPrefix(srv.URL, "http://")
+    if b := o.stats()["breakers"].(map[string]interface{})[host].(map[string]interface{}); b["state"] != "open" {
+        t.Errorf("breaker = %v", b)
+    }
+
+    // Backends with their own breaker are neither refused nor counted
+    for i := 0; i < 3; i++ {
+        resp, err := o.do(context.Background(), outboundRequest{feature: "test", method: http.MethodGet, url: srv.URL, noBreaker: true})
+        if err != nil || resp.status != http.StatusBadGateway {
+            t.Fatalf("noBreaker call %d: %+v, %v", i, resp, err)
+        }
+    }
+    if b := o.stats()["breakers"].(map[string]interface{})[host].(map[string]interface{}); b["opened"] != 1 {
+        t.Errorf("noBreaker calls reopened the breaker: %v", b)
+    }
+
+    // After the cooldown a failed trial opens it again at once
+    time.Sleep(60 * time.Millisecond)
+    if resp, err := get(o, srv.URL, false); err != nil || resp.status != http.StatusBadGateway {
+        t.Fatalf("trial: %+v, %v", resp, err)
+    }
+    if _, err := get(o, srv.URL, false); !errors.Is(err, errCircuitOpen) {
+        t.Fatalf("reopened breaker: %v", err)
+    }
+
+    // A successful trial closes it
+    time.Sleep(60 * time.Millisecond)
+    healthy.Store(true)
+    for i := 0; i < 3; i++ {
+        if resp, err := get(o, srv.URL, false); err != nil || resp.status != http.StatusOK {
+            t.Fatalf("closed breaker, call %d: %+v, %v", i, resp, err)
+        }
+    }
+    if b := o.stats()["breakers"].(map[string]interface{})[host].(map[string]interface{}); b["state"] != "closed" || b["opened"] != 2 {
+        t.Errorf("breaker = %v", b)
+    }
+}
+
+func TestOutboundCache(t *testing.T) {
+    var calls atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        n := calls.Add(1)
+        switch r.URL.Path {
+        case "/short":
+            w.Header().Set("Cache-Control", "max-age=0")
+        case "/private":
+            w.Header().Set("Cache-Control", "no-store")
+        }
+        io.WriteString(w, r.Header.Get("Authorization")+string(rune('0'+n)))
+    }))
+    defer srv.Close()
+
+    o := useTestOutbound(t, nil)
+    first, _ := get(o, srv.URL+"/data", true)
+    second, _ := get(o, srv.URL+"/data", true)
+    if calls.Load() != 1 || !second.cached || string(second.body) != string(first.body) {
+        t.Errorf("second call not cached: %d calls, %+v", calls.Load(), second)
+    }
+    if resp, _ := get(o, srv.URL+"/data", false); resp.cached || calls.Load() != 2 {
+        t.Errorf("uncacheable call answered from the cache")
+    }
+
+    // Credentials are part of the key
+    other, _ := o.do(context.Background(), outboundRequest{feature: "test", method: http.MethodGet, url: srv.URL + "/data",
+        header: http.Header{"Authorization": {"Bearer b"}}, cache: true})
+    if other.cached || !strings.HasPrefix(string(other.body), "Bearer b") {
+        t.Errorf("answer shared across credentials: %+v", other)
+    }
+
+    for _, path := range []string{"/short", "/private"} {
+        before := calls.Load()
+        get(o, srv.URL+path, true)
+        if resp, _ := get(o, srv.URL+path, true); resp.cached || calls.Load() != before+2 {
+            t.Errorf("%s was cached despite Cache-Control", path)
+        }
+    }
+
+    off := useTestOutbound(t, func(c *outboundConfig) { c.cacheTTL = 0 })
+    get(off, srv.URL+"/data", true)
+    if resp, _ := get(off, srv.URL+"/data", true); resp.cached {
+        t.Error("-outbound-cache-ttl=0 still caches")
+    }
+}
+
+func TestOutboundProxy(t *testing.T) {
+    var proxied atomic.Value
+    proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        proxied.Store(r.URL.String())
+        io.WriteString(w, "via proxy")
+    }))
+    defer proxy.Close()
+
+    o := useTestOutbound(t, func(c *outboundConfig) { c.proxy = proxy.URL })
+    resp, err := get(o, "http://calendar.invalid/free-busy", false)
+    if err != nil || string(resp.body) != "via proxy" || proxied.Load() != "http://calendar.invalid/free-busy" {
+        t.Fatalf("resp = %+v, %v, proxied %v", resp, err, proxied.Load())
+    }
+}
+
+func TestCheckOutboundConfig(t *testing.T) {
+    for _, edit := range []func(*outboundConfig){
+        func(c *outboundConfig) { c.timeout = 0 },
+        func(c *outboundConfig) { c.retries = -1 },
+        func(c *outboundConfig) { c.retries = outboundMaxRetries + 1 },
+        func(c *outboundConfig) { c.cacheTTL = -time.Second },
+        func(c *outboundConfig) { c.breakerFailures = -1 },
+        func(c *outboundConfig) { c.breakerCooldown = 0 },
+        func(c *outboundConfig) { c.proxy = "proxy:3128" },
+    } {
+        cfg := defaultOutboundConfig()
+        edit(&cfg)
+        if err := checkOutboundConfig(cfg); err == nil {
+            t.Errorf("checkOutboundConfig(%+v) should fail", cfg)
+        }
+    }
+    cfg := defaultOutboundConfig()
+    cfg.breakerFailures, cfg.breakerCooldown = 0, 0
+    if err := checkOutboundConfig(cfg); err != nil {
+        t.Errorf("disabled breaker: %v", err)
+    }
+}
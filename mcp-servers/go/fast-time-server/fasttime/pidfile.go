@@ -0,0 +1,110 @@
+// -*- coding: utf-8 -*-
+// pidfile.go - the -pid-file
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Config.PIDFile records the server's PID while Run serves. The file is
+// created exclusively, so two servers started at once cannot both take it; a
+// file naming a running process stops the second start, while one left by a
+// crash is replaced. Close removes it. A process started by a SIGUSR2 upgrade
+// (restart.go) takes the file over from its parent, and the parent then
+// leaves it alone.
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// pidFile is the -pid-file written by this process, if any
+var pidFile string
+
+// readPIDFile returns the process ID recorded in path
+func readPIDFile(path string) (int, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return 0, err
+    }
+    pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+    if err != nil || pid <= 0 {
+        return 0, fmt.Errorf("%s does not hold a process ID", path)
+    }
+    return pid, nil
+}
+
+// runningPID returns the live process other than this one that path names,
+// or 0
+func runningPID(path string) int {
+    pid, err := readPIDFile(path)
+    if err != nil || pid == os.Getpid() || !processAlive(pid) {
+        return 0
+    }
+    return pid
+}
+
+// CheckPIDFile fails when path names a running server other than this
+// process; the fast-time-server command checks it before -daemon
+func CheckPIDFile(path string) error {
+    if pid := runningPID(path); pid != 0 {
+        return fmt.Errorf("%s is already running as pid %d (%s)", appName, pid, path)
+    }
+    return nil
+}
+
+// writePIDFile records this process in path
+func writePIDFile(path string) error {
+    content := []byte(strconv.Itoa(os.Getpid()) + "\n")
+    for attempt := 0; attempt < 2; attempt++ {
+        f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+        if err == nil {
+            _, err = f.Write(content)
+            if cerr := f.Close(); err == nil {
+                err = cerr
+            }
+            if err != nil {
+                os.Remove(path)
+                return err
+            }
+            pidFile = path
+            return nil
+        }
+        if !errors.Is(err, fs.ErrExist) {
+            return err
+        }
+
+        pid := runningPID(path)
+        switch {
+        case pid != 0 && pid == os.Getppid() && os.Getenv(envListenFD) != "":
+            // Upgraded by SIGUSR2: the parent is draining and hands over
+            if err := os.WriteFile(path, content, 0o644); err != nil {
+                return err
+            }
+            pidFile = path
+            return nil
+        case pid != 0:
+            return fmt.Errorf("%s is already running as pid %d (%s)", appName, pid, path)
+        }
+        logAt(logWarn, "removing stale pid file %s", path)
+        if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+            return err
+        }
+    }
+    return fmt.Errorf("%s: could not be created", path)
+}
+
+// removePIDFile deletes the -pid-file unless another process has taken it
+func removePIDFile() {
+    if pidFile == "" {
+        return
+    }
+    if pid, err := readPIDFile(pidFile); err == nil && pid == os.Getpid() {
+        os.Remove(pidFile)
+    }
+    pidFile = ""
+}
@@ -0,0 +1,97 @@
+// -*- coding: utf-8 -*-
+// pidfile_test.go - Tests for PID files
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// deadPID returns a PID that names no running process
+func deadPID(t *testing.T) int {
+    for pid := 999999; pid > 900000; pid-- {
+        if !processAlive(pid) {
+            return pid
+        }
+    }
+    t.Skip("no free process ID found")
+    return 0
+}
+
+func TestWritePIDFile(t *testing.T) {
+    defer func() { pidFile = "" }()
+    path := filepath.Join(t.TempDir(), "server.pid")
+    if err := writePIDFile(path); err != nil {
+        t.Fatal(err)
+    }
+    if pid, err := readPIDFile(path); err != nil || pid != os.Getpid() {
+        t.Fatalf("readPIDFile = %d, %v", pid, err)
+    }
+    if err := CheckPIDFile(path); err != nil {
+        t.Errorf("own pid should not block: %v", err)
+    }
+    removePIDFile()
+    if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+        t.Errorf("pid file left behind: %v", err)
+    }
+}
+
+func TestWritePIDFileRunning(t *testing.T) {
+    defer func() { pidFile = "" }()
+    path := filepath.Join(t.TempDir(), "server.pid")
+    os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())+"\n"), 0o644)
+    if err := CheckPIDFile(path); err == nil || !strings.Contains(err.Error(), "already running") {
+        t.Errorf("CheckPIDFile = %v", err)
+    }
+    if err := writePIDFile(path); err == nil {
+        t.Fatal("writePIDFile should refuse a running server")
+    }
+    if pid, _ := readPIDFile(path); pid != os.Getppid() {
+        t.Errorf("pid file overwritten with %d", pid)
+    }
+
+    // A process started by a SIGUSR2 upgrade takes over from its parent
+    t.Setenv(envListenFD, "3")
+    if err := writePIDFile(path); err != nil {
+        t.Fatalf("upgrade: %v", err)
+    }
+    if pid, _ := readPIDFile(path); pid != os.Getpid() {
+        t.Errorf("upgrade left pid %d", pid)
+    }
+}
+
+func TestWritePIDFileStale(t *testing.T) {
+    defer func() { pidFile = "" }()
+    dir := t.TempDir()
+    for name, content := range map[string]string{
+        "dead.pid":    strconv.Itoa(deadPID(t)),
+        "garbage.pid": "not a pid",
+    } {
+        path := filepath.Join(dir, name)
+        os.WriteFile(path, []byte(content), 0o644)
+        if err := writePIDFile(path); err != nil {
+            t.Errorf("%s: %v", name, err)
+        }
+        if pid, _ := readPIDFile(path); pid != os.Getpid() {
+            t.Errorf("%s: pid = %d", name, pid)
+        }
+    }
+}
+
+func TestRemovePIDFileTakenOver(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "server.pid")
+    os.WriteFile(path, []byte("12345\n"), 0o644)
+    pidFile = path
+    removePIDFile()
+    if _, err := os.Stat(path); err != nil {
+        t.Errorf("pid file of another process removed: %v", err)
+    }
+}
@@ -0,0 +1,22 @@
+// -*- coding: utf-8 -*-
+// pidfile_unix.go - process checks for the -pid-file
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file checks whether a PID from a -pid-file is alive.
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "errors"
+    "syscall"
+)
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+    err := syscall.Kill(pid, 0)
+    return err == nil || errors.Is(err, syscall.EPERM)
+}
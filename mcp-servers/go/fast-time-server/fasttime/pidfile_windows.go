@@ -0,0 +1,27 @@
+// -*- coding: utf-8 -*-
+// pidfile_windows.go - process checks for the -pid-file on Windows
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file checks whether a PID from a -pid-file is alive.
+
+//go:build windows
+
+package fasttime
+
+import "golang.org/x/sys/windows"
+
+// stillActive is the exit code of a process that has not exited
+const stillActive = 259
+
+// processAlive reports whether a process with pid is running
+func processAlive(pid int) bool {
+    h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+    if err != nil {
+        return false
+    }
+    defer windows.CloseHandle(h)
+    var code uint32
+    return windows.GetExitCodeProcess(h, &code) == nil && code == stillActive
+}
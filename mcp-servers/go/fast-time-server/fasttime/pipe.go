@@ -0,0 +1,49 @@
+// -*- coding: utf-8 -*-
+// pipe.go - the pipe transport
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements -transport=pipe, the stdio protocol served on a local
+// pipe instead of the process's own stdin and stdout. It suits a server that
+// runs on its own, such as a Windows service, while local clients connect
+// to it as if they had started it:
+//
+//   Windows  a named pipe, \\.\pipe\fast-time-server by default
+//   others   a Unix socket, $TMPDIR/fast-time-server.sock by default
+//
+// MCP over stdio has a single session, so clients are served one at a time
+// in the order they connect; each sees a fresh session. -pipe changes the
+// name or path.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "net"
+
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// servePipe serves the stdio protocol to each client of ln in turn until
+// ctx is done, which closes ln and ends the current session
+func servePipe(ctx context.Context, s *server.MCPServer, ln net.Listener) error {
+    defer context.AfterFunc(ctx, func() { ln.Close() })()
+    for {
+        conn, err := ln.Accept()
+        if err != nil {
+            if errors.Is(err, net.ErrClosed) {
+                return nil
+            }
+            return err
+        }
+        logAt(logInfo, "pipe client connected")
+        err = newStdioServer(s).Listen(ctx, conn, conn)
+        conn.Close()
+        if err != nil && !errors.Is(err, context.Canceled) {
+            logAt(logDebug, "pipe session ended: %v", err)
+        }
+        logAt(logInfo, "pipe client disconnected")
+    }
+}
@@ -0,0 +1,106 @@
+// -*- coding: utf-8 -*-
+// pipe_test.go - Tests for the pipe transport on Unix sockets
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "net"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// pipeRequest sends one JSON-RPC request on conn and returns the response
+func pipeRequest(t *testing.T, conn net.Conn, r *bufio.Reader, req string) map[string]interface{} {
+    t.Helper()
+    conn.SetDeadline(time.Now().Add(5 * time.Second))
+    if _, err := conn.Write([]byte(req + "\n")); err != nil {
+        t.Fatal(err)
+    }
+    line, err := r.ReadString('\n')
+    if err != nil {
+        t.Fatal(err)
+    }
+    var resp map[string]interface{}
+    if err := json.Unmarshal([]byte(line), &resp); err != nil {
+        t.Fatalf("%v: %s", err, line)
+    }
+    return resp
+}
+
+func TestServePipe(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "fts.sock")
+    ln, err := listenPipe(path)
+    if err != nil {
+        t.Skipf("unix sockets not available: %v", err)
+    }
+    defer ln.Close()
+    if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+        t.Errorf("socket mode = %v, %v", fi.Mode(), err)
+    }
+    go servePipe(context.Background(), newMCPServer(&server.Hooks{}, false), ln)
+
+    // Clients are served one after the other, each with a fresh session
+    for i := 0; i < 2; i++ {
+        conn, err := net.Dial("unix", path)
+        if err != nil {
+            t.Fatal(err)
+        }
+        r := bufio.NewReader(conn)
+        resp := pipeRequest(t, conn, r, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+        if resp["result"] == nil {
+            t.Fatalf("initialize: %v", resp)
+        }
+        resp = pipeRequest(t, conn, r, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_system_time","arguments":{"timezone":"UTC"}}}`)
+        data, _ := json.Marshal(resp["result"])
+        if resp["error"] != nil || !strings.Contains(string(data), `Z"`) {
+            t.Errorf("get_system_time: %s", data)
+        }
+        conn.Close()
+    }
+}
+
+func TestListenPipeInUse(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "fts.sock")
+    ln, err := listenPipe(path)
+    if err != nil {
+        t.Skipf("unix sockets not available: %v", err)
+    }
+    if _, err := listenPipe(path); err == nil || !strings.Contains(err.Error(), "in use") {
+        t.Errorf("second listener: %v", err)
+    }
+    ln.Close()
+
+    // A stale socket file is replaced, a regular file is not
+    stale, err := net.Listen("unix", path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    stale.(*net.UnixListener).SetUnlinkOnClose(false)
+    stale.Close()
+    if ln, err := listenPipe(path); err != nil {
+        t.Errorf("stale socket: %v", err)
+    } else {
+        ln.Close()
+    }
+    file := filepath.Join(t.TempDir(), "plain")
+    os.WriteFile(file, nil, 0o600)
+    if _, err := listenPipe(file); err == nil {
+        t.Error("a regular file should not be replaced")
+    }
+    if got := pipePath(""); !strings.HasSuffix(got, appName+".sock") {
+        t.Errorf("default path = %q", got)
+    }
+}
@@ -0,0 +1,32 @@
+// -*- coding: utf-8 -*-
+// pipe_unix.go - the pipe transport on a Unix socket
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Outside Windows, -transport=pipe listens on a Unix socket. A stale socket
+// file left by a previous run is replaced; clients use socat or any Unix
+// socket client.
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "net"
+    "os"
+    "path/filepath"
+)
+
+// pipePath returns the socket path for -pipe
+func pipePath(name string) string {
+    if name == "" {
+        return filepath.Join(os.TempDir(), appName+".sock")
+    }
+    return name
+}
+
+// listenPipe listens on the Unix socket for -pipe
+func listenPipe(name string) (net.Listener, error) {
+    return listenUnix(pipePath(name))
+}
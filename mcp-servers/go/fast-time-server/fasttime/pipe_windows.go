@@ -0,0 +1,155 @@
+// -*- coding: utf-8 -*-
+// pipe_windows.go - the pipe transport on a Windows named pipe
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// On Windows, -transport=pipe creates a local named pipe. A new instance is
+// created as soon as a client connects, so later clients wait in line rather
+// than failing with ERROR_PIPE_BUSY. Remote clients are rejected, and the
+// default security descriptor lets the service account and administrators
+// write to the pipe.
+
+//go:build windows
+
+package fasttime
+
+import (
+    "errors"
+    "net"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "golang.org/x/sys/windows"
+)
+
+// pipeBufferSize is the buffer size of each pipe instance
+const pipeBufferSize = 64 << 10
+
+// pipePath returns the full pipe name for -pipe
+func pipePath(name string) string {
+    if name == "" {
+        name = appName
+    }
+    if strings.HasPrefix(name, `\\`) {
+        return name
+    }
+    return `\\.\pipe\` + name
+}
+
+// pipeAddr is the net.Addr of a named pipe
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeListener accepts clients of a named pipe
+type pipeListener struct {
+    path   string
+    mu     sync.Mutex
+    next   windows.Handle // the instance waiting for the next client
+    closed bool
+}
+
+// listenPipe creates the named pipe for -pipe
+func listenPipe(name string) (net.Listener, error) {
+    l := &pipeListener{path: pipePath(name)}
+    h, err := l.instance(true)
+    if err != nil {
+        return nil, &net.OpError{Op: "listen", Net: "pipe", Addr: l.Addr(), Err: err}
+    }
+    l.next = h
+    return l, nil
+}
+
+// instance creates a pipe instance; the first one fails if another process
+// owns the name
+func (l *pipeListener) instance(first bool) (windows.Handle, error) {
+    name, err := windows.UTF16PtrFromString(l.path)
+    if err != nil {
+        return windows.InvalidHandle, err
+    }
+    flags := uint32(windows.PIPE_ACCESS_DUPLEX)
+    if first {
+        flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+    }
+    mode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+    return windows.CreateNamedPipe(name, flags, mode, windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize, pipeBufferSize, 0, nil)
+}
+
+// Accept waits for the next client
+func (l *pipeListener) Accept() (net.Conn, error) {
+    l.mu.Lock()
+    h := l.next
+    closed := l.closed
+    l.mu.Unlock()
+    if closed {
+        return nil, net.ErrClosed
+    }
+
+    err := windows.ConnectNamedPipe(h, nil)
+    if err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+        return nil, &net.OpError{Op: "accept", Net: "pipe", Addr: l.Addr(), Err: err}
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.closed {
+        return nil, net.ErrClosed // Close releases the instance
+    }
+    next, err := l.instance(false)
+    if err != nil {
+        windows.CloseHandle(h)
+        return nil, &net.OpError{Op: "accept", Net: "pipe", Addr: l.Addr(), Err: err}
+    }
+    l.next = next
+    return &pipeConn{File: os.NewFile(uintptr(h), l.path), h: h, addr: l.Addr()}, nil
+}
+
+// Close stops accepting; a pending Accept is woken by connecting to it
+func (l *pipeListener) Close() error {
+    l.mu.Lock()
+    if l.closed {
+        l.mu.Unlock()
+        return nil
+    }
+    l.closed = true
+    l.mu.Unlock()
+
+    if name, err := windows.UTF16PtrFromString(l.path); err == nil {
+        h, err := windows.CreateFile(name, windows.GENERIC_READ, 0, nil, windows.OPEN_EXISTING, 0, 0)
+        if err == nil {
+            windows.CloseHandle(h)
+        }
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return windows.CloseHandle(l.next)
+}
+
+// Addr returns the pipe name
+func (l *pipeListener) Addr() net.Addr { return pipeAddr(l.path) }
+
+// pipeConn is a connected pipe instance
+type pipeConn struct {
+    *os.File
+    h    windows.Handle
+    addr net.Addr
+}
+
+// Close flushes what the client has not read yet and closes the instance
+func (c *pipeConn) Close() error {
+    windows.FlushFileBuffers(c.h)
+    windows.DisconnectNamedPipe(c.h)
+    return c.File.Close()
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr { return c.addr }
+
+// Deadlines are not supported on synchronous pipe handles
+func (c *pipeConn) SetDeadline(time.Time) error      { return errors.ErrUnsupported }
+func (c *pipeConn) SetReadDeadline(time.Time) error  { return errors.ErrUnsupported }
+func (c *pipeConn) SetWriteDeadline(time.Time) error { return errors.ErrUnsupported }
@@ -0,0 +1,240 @@
+// -*- coding: utf-8 -*-
+// plugins.go - external tools run as subprocesses
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets operators add their own tools, such as a company fiscal
+// calendar, without forking the server. The JSON file given with -plugins
+// declares each tool and the command that implements it:
+//
+//   {"tools": [
+//     {"name": "fiscal_quarter",
+//      "description": "Fiscal quarter and week of a date",
+//      "command": ["/opt/fiscal/bin/quarter", "--fy-start=02-01"],
+//      "input_schema": {"type": "object",
+//                       "properties": {"date": {"type": "string"}},
+//                       "required": ["date"]},
+//      "timeout": "5s"}
+//   ]}
+//
+// The command runs once per call with the tool arguments as a JSON object on
+// stdin and FAST_TIME_TOOL naming the tool. It inherits the server's
+// environment minus the bearer tokens (AUTH_TOKEN, ADMIN_TOKEN): a plugin
+// needs the usual PATH, HOME, TZ or proxy settings far more often than an
+// allowlist could anticipate, but never the server's credentials. Its stdout is the result text; a
+// non-zero exit is a tool error carrying its stderr. A cancelled call or one
+// running past its timeout (default 10s) kills the process. Plugin tools pass
+// through the same filters, scopes, audit log and statistics as the built-in
+// ones. Programs embedding the fasttime package register Go tools with
+// Server.AddTool instead.
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "regexp"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// envPluginTool names the tool a plugin command is run for
+const envPluginTool = "FAST_TIME_TOOL"
+
+// pluginHiddenEnv lists the variables not passed to plugin commands
+var pluginHiddenEnv = []string{envAuthToken, envAdminToken}
+
+// Limits of plugin commands
+const (
+    defaultPluginTimeout = 10 * time.Second
+    maxPluginOutput      = 1 << 20 // bytes of stdout kept per call
+    maxPluginStderr      = 4 << 10 // bytes of stderr quoted in errors
+)
+
+// pluginNameRE matches the tool names a plugin may declare
+var pluginNameRE = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// pluginTool is a tool declared in the -plugins file
+type pluginTool struct {
+    Name        string          `json:"name"`
+    Title       string          `json:"title"`
+    Description string          `json:"description"`
+    Command     []string        `json:"command"`
+    InputSchema json.RawMessage `json:"input_schema"`
+    Timeout     string          `json:"timeout"`
+    ReadOnly    bool            `json:"read_only"`
+
+    timeout time.Duration
+}
+
+// loadPlugins reads the tools declared in path
+func loadPlugins(path string) ([]*pluginTool, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("read plugins file: %w", err)
+    }
+    var doc struct {
+        Tools []*pluginTool `json:"tools"`
+    }
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("plugins file %s: %w", path, err)
+    }
+    names := make(map[string]bool, len(doc.Tools))
+    for i, p := range doc.Tools {
+        switch {
+        case p == nil || p.Name == "":
+            return nil, fmt.Errorf("plugins file %s: tool %d has no name", path, i+1)
+        case !pluginNameRE.MatchString(p.Name):
+            return nil, fmt.Errorf("plugins file %s: invalid tool name %q (use letters, digits, _ and -)", path, p.Name)
+        case names[p.Name]:
+            return nil, fmt.Errorf("plugins file %s: duplicate tool %q", path, p.Name)
+        case len(p.Command) == 0 || p.Command[0] == "":
+            return nil, fmt.Errorf("plugins file %s: tool %q has no command", path, p.Name)
+        }
+        if err := checkPluginSchema(p.InputSchema); err != nil {
+            return nil, fmt.Errorf("plugins file %s: tool %q: %w", path, p.Name, err)
+        }
+        p.timeout = defaultPluginTimeout
+        if p.Timeout != "" {
+            if p.timeout, err = time.ParseDuration(p.Timeout); err != nil || p.timeout <= 0 {
+                return nil, fmt.Errorf("plugins file %s: tool %q: invalid timeout %q", path, p.Name, p.Timeout)
+            }
+        }
+        names[p.Name] = true
+    }
+    return doc.Tools, nil
+}
+
+// checkPluginSchema accepts an empty schema or a JSON Schema object of
+// type "object", the only kind MCP tool arguments can have
+func checkPluginSchema(raw json.RawMessage) error {
+    if len(raw) == 0 {
+        return nil
+    }
+    var schema struct {
+        Type string `json:"type"`
+    }
+    if err := json.Unmarshal(raw, &schema); err != nil {
+        return fmt.Errorf("input_schema: %w", err)
+    }
+    if schema.Type != "object" {
+        return errors.New(`input_schema must have "type": "object"`)
+    }
+    return nil
+}
+
+// tool returns the MCP definition of p
+func (p *pluginTool) tool() mcp.Tool {
+    schema := p.InputSchema
+    if len(schema) == 0 {
+        schema = json.RawMessage(`{"type":"object","properties":{}}`)
+    }
+    t := mcp.NewToolWithRawSchema(p.Name, p.Description, schema)
+    t.Annotations.Title = p.Title
+    t.Annotations.ReadOnlyHint = mcp.ToBoolPtr(p.ReadOnly)
+    t.Annotations.OpenWorldHint = mcp.ToBoolPtr(true)
+    return t
+}
+
+// pluginEnv returns environ without the variables in pluginHiddenEnv
+func pluginEnv(environ []string) []string {
+    out := make([]string, 0, len(environ)+1)
+    for _, kv := range environ {
+        name, _, _ := strings.Cut(kv, "=")
+        hidden := false
+        for _, h := range pluginHiddenEnv {
+            hidden = hidden || strings.EqualFold(name, h) // Windows names ignore case
+        }
+        if !hidden {
+            out = append(out, kv)
+        }
+    }
+    return out
+}
+
+// handle runs the plugin command for one call
+func (p *pluginTool) handle(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    args, err := json.Marshal(req.GetArguments())
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    ctx, cancel := context.WithTimeout(ctx, p.timeout)
+    defer cancel()
+
+    cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+    cmd.Env = append(pluginEnv(os.Environ()), envPluginTool+"="+p.Name)
+    cmd.Stdin = bytes.NewReader(args)
+    stdout := &limitedBuffer{max: maxPluginOutput}
+    stderr := &limitedBuffer{max: maxPluginStderr}
+    cmd.Stdout, cmd.Stderr = stdout, stderr
+    cmd.WaitDelay = time.Second // children of a killed command may hold the pipes
+
+    start := time.Now()
+    err = cmd.Run()
+    logAt(logDebug, "plugin %s: %s in %v", p.Name, exitSummary(err), time.Since(start).Round(time.Millisecond))
+    switch {
+    case errors.Is(ctx.Err(), context.DeadlineExceeded):
+        return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %v", p.Name, p.timeout)), nil
+    case ctx.Err() != nil:
+        return nil, ctx.Err()
+    case err != nil:
+        msg := strings.TrimSpace(stderr.String())
+        if msg == "" {
+            msg = exitSummary(err)
+        }
+        return mcp.NewToolResultError(fmt.Sprintf("%s failed: %s", p.Name, msg)), nil
+    }
+    if stdout.truncated {
+        return mcp.NewToolResultError(fmt.Sprintf("%s wrote more than %d bytes", p.Name, maxPluginOutput)), nil
+    }
+    return mcp.NewToolResultText(strings.TrimRight(stdout.String(), "\r\n")), nil
+}
+
+// exitSummary describes how a plugin command ended
+func exitSummary(err error) string {
+    if err == nil {
+        return "ok"
+    }
+    return err.Error()
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+    bytes.Buffer
+    max       int
+    truncated bool
+}
+
+// Write implements io.Writer; it never fails so the command is not killed
+// by a broken pipe
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+    if room := b.max - b.Len(); len(p) > room {
+        b.truncated = true
+        b.Buffer.Write(p[:max(room, 0)])
+        return len(p), nil
+    }
+    return b.Buffer.Write(p)
+}
+
+// registerPlugins adds the plugin tools to s; a name taken by a built-in
+// tool is an error
+func registerPlugins(s *server.MCPServer, plugins []*pluginTool) error {
+    for _, p := range plugins {
+        if s.GetTool(p.Name) != nil {
+            return fmt.Errorf("plugin tool %q clashes with a built-in tool", p.Name)
+        }
+    }
+    for _, p := range plugins {
+        s.AddTool(p.tool(), p.handle)
+    }
+    return nil
+}
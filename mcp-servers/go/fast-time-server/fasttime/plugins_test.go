@@ -0,0 +1,121 @@
+// -*- coding: utf-8 -*-
+// plugins_test.go - Tests for subprocess plugin tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// writePlugins writes a -plugins file and returns its path
+func writePlugins(t *testing.T, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "plugins.json")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestLoadPlugins(t *testing.T) {
+    plugins, err := loadPlugins(writePlugins(t, `{"tools": [
+        {"name": "fiscal_quarter", "command": ["quarter"], "timeout": "2s",
+         "input_schema": {"type": "object", "properties": {"date": {"type": "string"}}}},
+        {"name": "noop", "command": ["true"]}
+    ]}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(plugins) != 2 || plugins[0].timeout != 2*time.Second || plugins[1].timeout != defaultPluginTimeout {
+        t.Errorf("plugins = %+v", plugins)
+    }
+    if _, ok := plugins[0].tool().InputSchema.Properties["date"]; ok {
+        t.Error("raw schema should not fill InputSchema")
+    }
+
+    for name, content := range map[string]string{
+        "no name":    `{"tools": [{"command": ["x"]}]}`,
+        "bad name":   `{"tools": [{"name": "a b", "command": ["x"]}]}`,
+        "duplicate":  `{"tools": [{"name": "a", "command": ["x"]}, {"name": "a", "command": ["y"]}]}`,
+        "no command": `{"tools": [{"name": "a"}]}`,
+        "schema":     `{"tools": [{"name": "a", "command": ["x"], "input_schema": {"type": "string"}}]}`,
+        "timeout":    `{"tools": [{"name": "a", "command": ["x"], "timeout": "soon"}]}`,
+        "json":       `{"tools": [`,
+    } {
+        if _, err := loadPlugins(writePlugins(t, content)); err == nil {
+            t.Errorf("%s: loadPlugins should fail", name)
+        }
+    }
+}
+
+func TestRegisterPluginsClash(t *testing.T) {
+    s := server.NewMCPServer("test", "1.0")
+    s.AddTool(mcp.NewTool("get_system_time"), handleGetSystemTime)
+    if err := registerPlugins(s, []*pluginTool{{Name: "get_system_time", Command: []string{"x"}}}); err == nil {
+        t.Error("a plugin should not replace a built-in tool")
+    }
+}
+
+func TestPluginHandle(t *testing.T) {
+    if runtime.GOOS == "windows" {
+        t.Skip("uses a shell script")
+    }
+    if _, err := exec.LookPath("sh"); err != nil {
+        t.Skip("no sh")
+    }
+    call := func(script string, timeout time.Duration) (*mcp.CallToolResult, string) {
+        p := &pluginTool{Name: "echo_tool", Command: []string{"sh", "-c", script}, timeout: timeout}
+        req := mcp.CallToolRequest{}
+        req.Params.Name = p.Name
+        req.Params.Arguments = map[string]any{"date": "2025-03-01"}
+        res, err := p.handle(context.Background(), req)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return res, res.Content[0].(mcp.TextContent).Text
+    }
+
+    res, text := call(`printf '%s ' "$FAST_TIME_TOOL"; cat; echo`, time.Second)
+    if res.IsError || text != `echo_tool {"date":"2025-03-01"}` {
+        t.Errorf("stdin/env: %q", text)
+    }
+    t.Setenv(envAuthToken, "secret")
+    t.Setenv(envAdminToken, "admin-secret")
+    t.Setenv("FAST_TIME_TEST_VAR", "kept")
+    res, text = call(`echo "$FAST_TIME_TEST_VAR:$AUTH_TOKEN:$ADMIN_TOKEN"`, time.Second)
+    if res.IsError || text != "kept::" {
+        t.Errorf("tokens leaked to the plugin: %q", text)
+    }
+    res, text = call(`echo "no such quarter" >&2; exit 3`, time.Second)
+    if !res.IsError || !strings.Contains(text, "no such quarter") {
+        t.Errorf("failure: %q", text)
+    }
+    res, text = call(`sleep 5`, 50*time.Millisecond)
+    if !res.IsError || !strings.Contains(text, "timed out") {
+        t.Errorf("timeout: %q", text)
+    }
+}
+
+func TestLimitedBuffer(t *testing.T) {
+    b := &limitedBuffer{max: 4}
+    if n, err := b.Write([]byte("abcdef")); n != 6 || err != nil {
+        t.Errorf("Write = %d, %v", n, err)
+    }
+    b.Write([]byte("gh"))
+    if b.String() != "abcd" || !b.truncated {
+        t.Errorf("buffer = %q truncated=%v", b.String(), b.truncated)
+    }
+}
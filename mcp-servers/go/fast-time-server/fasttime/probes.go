@@ -0,0 +1,129 @@
+// -*- coding: utf-8 -*-
+// probes.go - Kubernetes liveness and readiness endpoints
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file splits the health check into two probes. /livez answers 200 as
+// long as the process can serve HTTP at all; a failing liveness probe should
+// only ever mean "restart me". /readyz runs the individual checks below and
+// answers 503 when any of them fails, so the pod is taken out of rotation
+// without being restarted:
+//
+//   - tzdata:   the IANA timezone database can be loaded
+//   - listener: the server is accepting connections and not draining
+//   - clock:    the host clock is within -ntp-max-drift of NTP (tools_ntp.go;
+//               only with -ntp-max-drift)
+//   - one check per backend named by -required-backends (backends.go), by
+//     default store: the persistence backend (-db) answers
+//
+// The answer also lists every backend, so optional ones that are down show
+// up without failing the probe. /health is kept unchanged for existing
+// clients.
+
+package fasttime
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "sync/atomic"
+    "time"
+)
+
+// readinessCheck is a named check run by /readyz
+type readinessCheck struct {
+    name string
+    run  func() error
+}
+
+// checkResult is the outcome of one readiness check
+type checkResult struct {
+    Status    string `json:"status"` // "ok" or "fail"
+    Error     string `json:"error,omitempty"`
+    ElapsedMs int64  `json:"elapsed_ms"`
+}
+
+// listenerState reports whether an HTTP listener is accepting connections
+var listenerState struct {
+    accepting atomic.Bool
+    draining  atomic.Bool
+}
+
+// errNotAccepting and errDraining are reported by the listener check
+var (
+    errNotAccepting = errors.New("listener not accepting yet")
+    errDraining     = errors.New("draining after upgrade")
+)
+
+// readinessChecks lists the checks run by /readyz
+var readinessChecks = []readinessCheck{
+    {"tzdata", func() error {
+        _, err := time.LoadLocation("America/New_York")
+        return err
+    }},
+    {"listener", func() error {
+        switch {
+        case listenerState.draining.Load():
+            return errDraining
+        case !listenerState.accepting.Load():
+            return errNotAccepting
+        }
+        return nil
+    }},
+}
+
+// runReadiness runs checks and reports whether all passed
+func runReadiness(checks []readinessCheck) (map[string]checkResult, bool) {
+    results := make(map[string]checkResult, len(checks))
+    ready := true
+    for _, c := range checks {
+        start := time.Now()
+        err := c.run()
+        res := checkResult{Status: "ok", ElapsedMs: time.Since(start).Milliseconds()}
+        if err != nil {
+            res.Status, res.Error = "fail", err.Error()
+            ready = false
+        }
+        results[c.name] = res
+    }
+    return results, ready
+}
+
+// handleLivez handles GET /livez
+func handleLivez(w http.ResponseWriter, _ *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Cache-Control", "no-store")
+    w.WriteHeader(http.StatusOK)
+    _, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+// handleReadyz handles GET /readyz
+func handleReadyz(w http.ResponseWriter, _ *http.Request) {
+    checks := append(append([]readinessCheck(nil), readinessChecks...), backends.readinessChecks()...)
+    results, ready := runReadiness(checks)
+    _, list := backends.status()
+    status, code := "ok", http.StatusOK
+    if !ready {
+        status, code = "fail", http.StatusServiceUnavailable
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Cache-Control", "no-store")
+    w.WriteHeader(code)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "status":   status,
+        "checks":   results,
+        "backends": list,
+    })
+}
+
+// isProbePath reports whether path is a health, version or probe endpoint,
+// which are served without authentication
+func isProbePath(path string) bool {
+    switch path {
+    case "/health", "/version", "/livez", "/readyz":
+        return true
+    }
+    return false
+}
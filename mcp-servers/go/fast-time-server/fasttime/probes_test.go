@@ -0,0 +1,89 @@
+// -*- coding: utf-8 -*-
+// probes_test.go - Tests for the liveness and readiness endpoints
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestLivez(t *testing.T) {
+    rec := httptest.NewRecorder()
+    handleLivez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+    if rec.Code != http.StatusOK || rec.Body.String() != `{"status":"ok"}` {
+        t.Errorf("got %d %q", rec.Code, rec.Body.String())
+    }
+}
+
+func TestRunReadiness(t *testing.T) {
+    results, ready := runReadiness([]readinessCheck{
+        {"good", func() error { return nil }},
+        {"bad", func() error { return errors.New("backend down") }},
+    })
+    if ready {
+        t.Error("a failing check should make the server unready")
+    }
+    if results["good"].Status != "ok" || results["bad"].Status != "fail" || results["bad"].Error != "backend down" {
+        t.Errorf("unexpected results %+v", results)
+    }
+}
+
+func TestReadyz(t *testing.T) {
+    defer func() {
+        listenerState.accepting.Store(false)
+        listenerState.draining.Store(false)
+    }()
+
+    get := func() (int, map[string]checkResult) {
+        rec := httptest.NewRecorder()
+        handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+        var body struct {
+            Status string                 `json:"status"`
+            Checks map[string]checkResult `json:"checks"`
+        }
+        if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+            t.Fatalf("invalid JSON: %v", err)
+        }
+        return rec.Code, body.Checks
+    }
+
+    if code, checks := get(); code != http.StatusServiceUnavailable || checks["listener"].Status != "fail" {
+        t.Errorf("before serving: got %d %+v", code, checks)
+    }
+
+    listenerState.accepting.Store(true)
+    code, checks := get()
+    if code != http.StatusOK {
+        t.Errorf("while serving: got %d %+v", code, checks)
+    }
+    for _, name := range []string{"tzdata", "store", "listener"} {
+        if checks[name].Status != "ok" {
+            t.Errorf("check %s: %+v", name, checks[name])
+        }
+    }
+
+    listenerState.draining.Store(true)
+    if code, checks := get(); code != http.StatusServiceUnavailable || checks["listener"].Error != errDraining.Error() {
+        t.Errorf("while draining: got %d %+v", code, checks)
+    }
+}
+
+func TestProbesSkipAuth(t *testing.T) {
+    mux := http.NewServeMux()
+    registerHealthAndVersion(mux)
+    h := authMiddleware(newBearerToken("secret"), mux)
+    for _, path := range []string{"/livez", "/readyz"} {
+        rec := httptest.NewRecorder()
+        h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+        if rec.Code == http.StatusUnauthorized {
+            t.Errorf("%s should not require a token", path)
+        }
+    }
+}
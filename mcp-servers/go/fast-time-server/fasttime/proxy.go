@@ -0,0 +1,118 @@
+// -*- coding: utf-8 -*-
+// proxy.go - trusted proxy handling for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file resolves the real client address when the server runs behind the
+// MCP gateway or a load balancer. Forwarding headers are only believed when
+// the connection comes from an address listed in -trusted-proxies; otherwise a
+// client could spoof its address by sending the headers itself.
+//
+// X-Forwarded-For is read right to left, skipping trusted hops, so the first
+// untrusted address is the client as seen by the outermost trusted proxy.
+// X-Real-IP is used when X-Forwarded-For is absent. realIPMiddleware rewrites
+// r.RemoteAddr, so request logs, auth warnings and the SSE connection list all
+// show the resolved address.
+
+package fasttime
+
+import (
+    "fmt"
+    "net"
+    "net/http"
+    "strings"
+)
+
+// ipNets is a list of networks, such as the trusted proxies
+type ipNets []*net.IPNet
+
+// parseIPNets parses a comma-separated list of IPs and CIDRs
+func parseIPNets(spec string) (ipNets, error) {
+    var set ipNets
+    for _, item := range strings.Split(spec, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        if !strings.Contains(item, "/") {
+            ip := net.ParseIP(item)
+            if ip == nil {
+                return nil, fmt.Errorf("invalid IP address %q", item)
+            }
+            bits := 128
+            if ip.To4() != nil {
+                ip, bits = ip.To4(), 32
+            }
+            set = append(set, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+            continue
+        }
+        _, network, err := net.ParseCIDR(item)
+        if err != nil {
+            return nil, fmt.Errorf("invalid network %q: %w", item, err)
+        }
+        set = append(set, network)
+    }
+    return set, nil
+}
+
+// contains reports whether ip belongs to one of the networks
+func (ps ipNets) contains(ip net.IP) bool {
+    for _, n := range ps {
+        if n.Contains(ip) {
+            return true
+        }
+    }
+    return false
+}
+
+// remoteIP returns the IP part of a RemoteAddr value
+func remoteIP(addr string) string {
+    if host, _, err := net.SplitHostPort(addr); err == nil {
+        return host
+    }
+    return addr
+}
+
+// clientIP returns the address of the client that sent r, honouring
+// forwarding headers from trusted proxies
+func (ps ipNets) clientIP(r *http.Request) string {
+    peer := remoteIP(r.RemoteAddr)
+    ip := net.ParseIP(peer)
+    if ip == nil || !ps.contains(ip) {
+        return peer
+    }
+
+    if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+        hops := strings.Split(strings.Join(xff, ","), ",")
+        for i := len(hops) - 1; i >= 0; i-- {
+            hop := strings.TrimSpace(hops[i])
+            hopIP := net.ParseIP(hop)
+            if hopIP == nil {
+                break // malformed entry: stop trusting the chain
+            }
+            if !ps.contains(hopIP) {
+                return hop
+            }
+            peer = hop
+        }
+        return peer
+    }
+
+    if xrip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(xrip) != nil {
+        return xrip
+    }
+    return peer
+}
+
+// realIPMiddleware replaces r.RemoteAddr with the resolved client address
+func realIPMiddleware(ps ipNets, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if ip := ps.clientIP(r); ip != remoteIP(r.RemoteAddr) {
+            r2 := r.Clone(r.Context())
+            r2.RemoteAddr = ip
+            r = r2
+        }
+        next.ServeHTTP(w, r)
+    })
+}
@@ -0,0 +1,90 @@
+// -*- coding: utf-8 -*-
+// proxy_test.go - Tests for trusted proxy handling
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+    ps, err := parseIPNets("10.0.0.0/8, 127.0.0.1,::1")
+    if err != nil {
+        t.Fatalf("parse error: %v", err)
+    }
+    if len(ps) != 3 {
+        t.Fatalf("want 3 networks, got %d", len(ps))
+    }
+    if ps, _ := parseIPNets(""); len(ps) != 0 {
+        t.Errorf("empty spec should trust nothing, got %v", ps)
+    }
+    for _, bad := range []string{"10.0.0.300", "10.0.0.0/40", "proxy.local"} {
+        if _, err := parseIPNets(bad); err == nil {
+            t.Errorf("expected error for %q", bad)
+        }
+    }
+}
+
+func TestClientIP(t *testing.T) {
+    ps, _ := parseIPNets("10.0.0.0/8,127.0.0.1")
+
+    tests := []struct {
+        name   string
+        remote string
+        xff    string
+        xrip   string
+        want   string
+    }{
+        {"direct client", "203.0.113.7:5000", "", "", "203.0.113.7"},
+        {"untrusted peer ignores headers", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+        {"single proxy", "10.1.2.3:5000", "198.51.100.1", "", "198.51.100.1"},
+        {"proxy chain", "127.0.0.1:5000", "198.51.100.1, 10.0.0.5", "", "198.51.100.1"},
+        {"spoofed leftmost entry", "10.1.2.3:5000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+        {"all hops trusted", "10.1.2.3:5000", "10.9.9.9", "", "10.9.9.9"},
+        {"malformed hop", "10.1.2.3:5000", "garbage", "", "10.1.2.3"},
+        {"x-real-ip", "10.1.2.3:5000", "", "198.51.100.9", "198.51.100.9"},
+        {"trusted peer, no headers", "10.1.2.3:5000", "", "", "10.1.2.3"},
+    }
+    for _, tt := range tests {
+        req := httptest.NewRequest(http.MethodGet, "/", nil)
+        req.RemoteAddr = tt.remote
+        if tt.xff != "" {
+            req.Header.Set("X-Forwarded-For", tt.xff)
+        }
+        if tt.xrip != "" {
+            req.Header.Set("X-Real-IP", tt.xrip)
+        }
+        if got := ps.clientIP(req); got != tt.want {
+            t.Errorf("%s: clientIP = %q, want %q", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestRealIPMiddleware(t *testing.T) {
+    ps, _ := parseIPNets("10.0.0.0/8")
+    var seen string
+    h := realIPMiddleware(ps, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        seen = r.RemoteAddr
+    }))
+
+    req := httptest.NewRequest(http.MethodGet, "/", nil)
+    req.RemoteAddr = "10.0.0.1:4000"
+    req.Header.Set("X-Forwarded-For", "198.51.100.1")
+    h.ServeHTTP(httptest.NewRecorder(), req)
+    if seen != "198.51.100.1" {
+        t.Errorf("RemoteAddr = %q, want client address", seen)
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/", nil)
+    req.RemoteAddr = "203.0.113.7:4000"
+    req.Header.Set("X-Forwarded-For", "198.51.100.1")
+    h.ServeHTTP(httptest.NewRecorder(), req)
+    if seen != "203.0.113.7:4000" {
+        t.Errorf("untrusted peer should keep RemoteAddr, got %q", seen)
+    }
+}
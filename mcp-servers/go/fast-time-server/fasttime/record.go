@@ -0,0 +1,268 @@
+// -*- coding: utf-8 -*-
+// record.go - record and replay MCP sessions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file captures MCP traffic for regression tests and bug reports.
+// With -record=path every answered JSON-RPC request is appended to path as
+// one JSON line holding the method, the request and its result or error:
+//
+//   {"time":"...","session":"...","id":3,"method":"tools/call",
+//    "request":{"method":"tools/call","params":{...}},"result":{...}}
+//
+// With -replay=path the server answers from such a recording instead:
+// tools/call, resources/read and prompts/get return the recorded result for
+// the same tool and arguments, URI, or prompt and arguments. Repeated
+// requests get the recorded answers in order, then the last one again. Tool
+// handlers do not run during replay and an unrecorded call fails; resource
+// and prompt reads without a recording fall back to the live answer.
+// Everything else (initialize, the list methods) is served normally.
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// recordedExchange is one line of a recording
+type recordedExchange struct {
+    Time    time.Time       `json:"time"`
+    Session string          `json:"session,omitempty"`
+    ID      any             `json:"id,omitempty"`
+    Method  string          `json:"method"`
+    Request json.RawMessage `json:"request"`
+    Result  json.RawMessage `json:"result,omitempty"`
+    Error   string          `json:"error,omitempty"`
+}
+
+/* ------------------------------------------------------------------ */
+/*                               recording                            */
+/* ------------------------------------------------------------------ */
+
+// recorder appends exchanges to a file
+type recorder struct {
+    w *rotatingFile
+}
+
+// newRecorder opens path for appending
+func newRecorder(path string) (*recorder, error) {
+    w, err := openRotatingFile(path, 0, 0, 0)
+    if err != nil {
+        return nil, err
+    }
+    return &recorder{w: w}, nil
+}
+
+// write appends one exchange as a single line
+func (rc *recorder) write(ctx context.Context, id any, method mcp.MCPMethod, message, result any, err error) {
+    req, merr := json.Marshal(message)
+    if merr != nil {
+        logAt(logError, "record: encoding %s request: %v", method, merr)
+        return
+    }
+    ex := recordedExchange{
+        Time:    time.Now().UTC(),
+        Session: sessionIDFrom(ctx),
+        ID:      id,
+        Method:  string(method),
+        Request: req,
+    }
+    if err != nil {
+        ex.Error = err.Error()
+    } else if ex.Result, merr = json.Marshal(result); merr != nil {
+        logAt(logError, "record: encoding %s result: %v", method, merr)
+        return
+    }
+    line, merr := json.Marshal(ex)
+    if merr != nil {
+        logAt(logError, "record: %v", merr)
+        return
+    }
+    if _, werr := rc.w.Write(append(line, '\n')); werr != nil {
+        logAt(logError, "record: %v", werr)
+    }
+}
+
+// install registers the hooks that record every answered request
+func (rc *recorder) install(hooks *server.Hooks) {
+    hooks.AddOnSuccess(func(ctx context.Context, id any, method mcp.MCPMethod, message any, result any) {
+        rc.write(ctx, id, method, message, result, nil)
+    })
+    hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+        rc.write(ctx, id, method, message, nil, err)
+    })
+}
+
+// Close closes the recording
+func (rc *recorder) Close() error { return rc.w.Close() }
+
+/* ------------------------------------------------------------------ */
+/*                                replay                              */
+/* ------------------------------------------------------------------ */
+
+// errNotRecorded is returned for tool calls missing from the recording
+var errNotRecorded = errors.New("no recorded response")
+
+// replayQueue holds the recorded answers to one request, in order
+type replayQueue struct {
+    answers []recordedExchange
+    next    int
+}
+
+// replayer answers requests from a recording
+type replayer struct {
+    mu    sync.Mutex
+    byKey map[string]*replayQueue
+}
+
+// replayKey identifies a request by method and what it asks for
+func replayKey(method, name string, args any) string {
+    return method + " " + name + " " + hashArguments(args)
+}
+
+// exchangeKey returns the replay key of a recorded request, or "" for
+// methods that are not replayed
+func exchangeKey(ex recordedExchange) (string, error) {
+    switch mcp.MCPMethod(ex.Method) {
+    case mcp.MethodToolsCall:
+        var req mcp.CallToolRequest
+        if err := json.Unmarshal(ex.Request, &req); err != nil {
+            return "", err
+        }
+        return replayKey(ex.Method, req.Params.Name, req.Params.Arguments), nil
+    case mcp.MethodResourcesRead:
+        var req mcp.ReadResourceRequest
+        if err := json.Unmarshal(ex.Request, &req); err != nil {
+            return "", err
+        }
+        return replayKey(ex.Method, req.Params.URI, nil), nil
+    case mcp.MethodPromptsGet:
+        var req mcp.GetPromptRequest
+        if err := json.Unmarshal(ex.Request, &req); err != nil {
+            return "", err
+        }
+        return replayKey(ex.Method, req.Params.Name, req.Params.Arguments), nil
+    }
+    return "", nil
+}
+
+// loadReplayer reads a recording made with -record
+func loadReplayer(path string) (*replayer, int, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer f.Close()
+
+    rp := &replayer{byKey: make(map[string]*replayQueue)}
+    sc := bufio.NewScanner(f)
+    sc.Buffer(make([]byte, 64*1024), 16<<20)
+    line, n := 0, 0
+    for sc.Scan() {
+        line++
+        if len(sc.Bytes()) == 0 {
+            continue
+        }
+        var ex recordedExchange
+        if err := json.Unmarshal(sc.Bytes(), &ex); err != nil {
+            return nil, 0, fmt.Errorf("%s:%d: %w", path, line, err)
+        }
+        key, err := exchangeKey(ex)
+        if err != nil {
+            return nil, 0, fmt.Errorf("%s:%d: %s request: %w", path, line, ex.Method, err)
+        }
+        if key == "" {
+            continue
+        }
+        q := rp.byKey[key]
+        if q == nil {
+            q = &replayQueue{}
+            rp.byKey[key] = q
+        }
+        q.answers = append(q.answers, ex)
+        n++
+    }
+    if err := sc.Err(); err != nil {
+        return nil, 0, err
+    }
+    return rp, n, nil
+}
+
+// answer returns the next recorded answer for key
+func (rp *replayer) answer(key string) (recordedExchange, bool) {
+    rp.mu.Lock()
+    defer rp.mu.Unlock()
+    q := rp.byKey[key]
+    if q == nil {
+        return recordedExchange{}, false
+    }
+    ex := q.answers[q.next]
+    if q.next < len(q.answers)-1 {
+        q.next++
+    }
+    return ex, true
+}
+
+// replay is the process-wide replayer (nil unless -replay is set)
+var replay *replayer
+
+// replayMiddleware answers tool calls from the recording without running
+// the tool when -replay is set
+func replayMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        rp := replay
+        if rp == nil {
+            return next(ctx, req)
+        }
+        ex, ok := rp.answer(replayKey(string(mcp.MethodToolsCall), req.Params.Name, req.Params.Arguments))
+        if !ok {
+            return nil, fmt.Errorf("replay: %w for tool %s with these arguments", errNotRecorded, req.Params.Name)
+        }
+        if ex.Error != "" {
+            return nil, errors.New(ex.Error)
+        }
+        return mcp.ParseCallToolResult(&ex.Result)
+    }
+}
+
+// install registers the hooks that swap resource and prompt results for
+// recorded ones
+func (rp *replayer) install(hooks *server.Hooks) {
+    hooks.AddAfterReadResource(func(_ context.Context, _ any, req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult) {
+        ex, ok := rp.answer(replayKey(string(mcp.MethodResourcesRead), req.Params.URI, nil))
+        if !ok || ex.Error != "" {
+            logAt(logWarn, "replay: no recorded contents for %s; serving live data", req.Params.URI)
+            return
+        }
+        recorded, err := mcp.ParseReadResourceResult(&ex.Result)
+        if err != nil {
+            logAt(logError, "replay: %s: %v", req.Params.URI, err)
+            return
+        }
+        *res = *recorded
+    })
+    hooks.AddAfterGetPrompt(func(_ context.Context, _ any, req *mcp.GetPromptRequest, res *mcp.GetPromptResult) {
+        ex, ok := rp.answer(replayKey(string(mcp.MethodPromptsGet), req.Params.Name, req.Params.Arguments))
+        if !ok || ex.Error != "" {
+            logAt(logWarn, "replay: no recorded result for prompt %s; serving live data", req.Params.Name)
+            return
+        }
+        recorded, err := mcp.ParseGetPromptResult(&ex.Result)
+        if err != nil {
+            logAt(logError, "replay: prompt %s: %v", req.Params.Name, err)
+            return
+        }
+        *res = *recorded
+    })
+}
@@ -0,0 +1,115 @@
+// -*- coding: utf-8 -*-
+// record_test.go - Tests for recording and replaying MCP sessions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// countingServer builds a server whose "counter" tool returns a new value
+// on every call and whose "state://now" resource does the same
+func countingServer(hooks *server.Hooks) *server.MCPServer {
+    n := 0
+    srv := server.NewMCPServer("test", "1.0",
+        server.WithToolCapabilities(false),
+        server.WithResourceCapabilities(false, false),
+        server.WithHooks(hooks),
+        server.WithToolHandlerMiddleware(replayMiddleware),
+    )
+    srv.AddTool(mcp.NewTool("counter"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        n++
+        return mcp.NewToolResultText(strings.Repeat("x", n)), nil
+    })
+    srv.AddResource(mcp.NewResource("state://now", "Now"), func(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+        n++
+        return []mcp.ResourceContents{mcp.TextResourceContents{URI: req.Params.URI, Text: strings.Repeat("r", n)}}, nil
+    })
+    return srv
+}
+
+// callText returns the text of a tools/call or resources/read response
+func callText(t *testing.T, srv *server.MCPServer, msg string) string {
+    t.Helper()
+    resp := srv.HandleMessage(context.Background(), json.RawMessage(msg))
+    r, ok := resp.(mcp.JSONRPCResponse)
+    if !ok {
+        return "error"
+    }
+    switch res := r.Result.(type) {
+    case mcp.CallToolResult:
+        return extractText(t, &res)
+    case mcp.ReadResourceResult:
+        return res.Contents[0].(mcp.TextResourceContents).Text
+    }
+    t.Fatalf("unexpected result %T", r.Result)
+    return ""
+}
+
+func TestRecordAndReplay(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "session.jsonl")
+    rec, err := newRecorder(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    hooks := &server.Hooks{}
+    rec.install(hooks)
+    srv := countingServer(hooks)
+
+    call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"counter","arguments":{"a":1}}}`
+    read := `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"state://now"}}`
+    var recorded []string
+    for _, msg := range []string{call, call, read} {
+        recorded = append(recorded, callText(t, srv, msg))
+    }
+    rec.Close()
+
+    rp, n, err := loadReplayer(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if n != 3 {
+        t.Errorf("loaded %d answers, want 3", n)
+    }
+    defer func() { replay = nil }()
+    replay = rp
+    hooks = &server.Hooks{}
+    rp.install(hooks)
+    srv = countingServer(hooks)
+
+    // The live server would count from 1 again; replay repeats the recording
+    // and then keeps answering with the last recorded value
+    want := []string{recorded[0], recorded[1], recorded[2], recorded[1]}
+    for i, msg := range []string{call, call, read, call} {
+        if got := callText(t, srv, msg); got != want[i] {
+            t.Errorf("replay %d = %q, want %q", i, got, want[i])
+        }
+    }
+
+    other := `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"counter","arguments":{"a":2}}}`
+    if got := callText(t, srv, other); got != "error" {
+        t.Errorf("unrecorded call = %q, want an error", got)
+    }
+}
+
+func TestLoadReplayerErrors(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "bad.jsonl")
+    _ = os.WriteFile(path, []byte("{\"method\":\"ping\",\"request\":{}}\nnot json\n"), 0o600)
+    if _, _, err := loadReplayer(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+        t.Errorf("err = %v, want line 2", err)
+    }
+    if _, _, err := loadReplayer(filepath.Join(t.TempDir(), "missing")); err == nil {
+        t.Error("missing file should fail")
+    }
+}
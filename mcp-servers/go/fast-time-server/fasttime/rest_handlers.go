@@ -7,10 +7,11 @@
 // This file implements REST API endpoints that complement the MCP protocol,
 // providing direct HTTP access to time-related operations.
 
-package main
+package fasttime
 
 import (
     "encoding/json"
+    "errors"
     "fmt"
     "net/http"
     "strings"
@@ -60,26 +61,45 @@ type TimezoneInfo struct {
     Abbreviation string `json:"abbreviation"`
 }
 
+// restConvertFields names the service's conversion fields in REST requests
+var restConvertFields = map[string]string{"from": "from_timezone", "to": "to_timezone"}
+
+// newConvertResponse renders a conversion from src to dst
+func newConvertResponse(src, dst time.Time) ConvertResponse {
+    return ConvertResponse{
+        OriginalTime:  src.Format(time.RFC3339),
+        FromTimezone:  src.Location().String(),
+        ConvertedTime: dst.Format(time.RFC3339),
+        ToTimezone:    dst.Location().String(),
+        Unix:          dst.Unix(),
+    }
+}
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
-    Error   string `json:"error"`
-    Message string `json:"message"`
-    Code    int    `json:"code"`
+    Error     string `json:"error"`
+    Message   string `json:"message"`
+    Code      int    `json:"code"`
+    ErrorCode string `json:"error_code,omitempty"` // machine-readable, see errors.go
+    Field     string `json:"field,omitempty"`
+    Hint      string `json:"hint,omitempty"`
+
+    Suggestions []string `json:"suggestions,omitempty"`
 }
 
-// writeJSONError writes a JSON error response
+// writeJSONError writes an error response (JSON unless another format was negotiated)
 func writeJSONError(w http.ResponseWriter, code int, message string) {
-    w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(code)
-    _ = json.NewEncoder(w).Encode(ErrorResponse{
-        Error:   http.StatusText(code),
-        Message: message,
-        Code:    code,
-    })
+    writeAPIError(w, code, errors.New(message))
 }
 
-// writeJSON writes a JSON response
+// writeJSON writes a response (JSON unless another format was negotiated),
+// in the envelope if one was asked for
 func writeJSON(w http.ResponseWriter, code int, data interface{}) {
+    data = enveloped(w, data)
+    if f := responseFormat(w); f != formatJSON {
+        writeFormatted(w, f, code, data)
+        return
+    }
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(code)
     if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -103,22 +123,19 @@ func handleRESTGetTime(w http.ResponseWriter, r *http.Request) {
         timezone = r.URL.Query().Get("timezone")
     }
     if timezone == "" {
-        timezone = "UTC"
+        timezone = requestDefaultTimezone(r)
     }
 
-    // Load timezone location
-    loc, err := time.LoadLocation(timezone)
+    // Get current time in the specified timezone (service.go)
+    now, err := timeIn(timezone, currentTime())
     if err != nil {
-        writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid timezone: %s", timezone))
+        writeAPIError(w, http.StatusBadRequest, err)
         return
     }
 
-    // Get current time in the specified timezone
-    now := time.Now().In(loc)
-
     response := TimeResponse{
         Time:     now.Format(time.RFC3339),
-        Timezone: timezone,
+        Timezone: now.Location().String(),
         Unix:     now.Unix(),
         UTC:      now.UTC().Format(time.RFC3339),
     }
@@ -139,44 +156,14 @@ func handleRESTConvertTime(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    // Parse the input time
-    t, err := time.Parse(time.RFC3339, req.Time)
-    if err != nil {
-        // Try parsing without timezone
-        t, err = time.Parse("2006-01-02 15:04:05", req.Time)
-        if err != nil {
-            writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid time format: %s", req.Time))
-            return
-        }
-    }
-
-    // Load source timezone
-    fromLoc, err := time.LoadLocation(req.FromTimezone)
-    if err != nil {
-        writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid source timezone: %s", req.FromTimezone))
-        return
-    }
-
-    // Load target timezone
-    toLoc, err := time.LoadLocation(req.ToTimezone)
+    // Parse the time in the source timezone and convert it (service.go)
+    src, dst, err := convertTime(req.Time, req.FromTimezone, req.ToTimezone)
     if err != nil {
-        writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid target timezone: %s", req.ToTimezone))
+        writeAPIError(w, http.StatusBadRequest, renameField(err, restConvertFields))
         return
     }
 
-    // Convert time
-    sourceTime := t.In(fromLoc)
-    convertedTime := sourceTime.In(toLoc)
-
-    response := ConvertResponse{
-        OriginalTime:  sourceTime.Format(time.RFC3339),
-        FromTimezone:  req.FromTimezone,
-        ConvertedTime: convertedTime.Format(time.RFC3339),
-        ToTimezone:    req.ToTimezone,
-        Unix:          convertedTime.Unix(),
-    }
-
-    writeJSON(w, http.StatusOK, response)
+    writeJSON(w, http.StatusOK, newConvertResponse(src, dst))
 }
 
 // handleRESTBatchConvert handles POST /api/v1/convert/batch
@@ -194,37 +181,16 @@ func handleRESTBatchConvert(w http.ResponseWriter, r *http.Request) {
 
     var results []ConvertResponse
     for _, conv := range req.Conversions {
-        // Parse the input time
-        t, err := time.Parse(time.RFC3339, conv.Time)
-        if err != nil {
-            // Try parsing without timezone
-            t, err = time.Parse("2006-01-02 15:04:05", conv.Time)
-            if err != nil {
-                continue // Skip invalid entries
-            }
-        }
-
-        // Load timezones
-        fromLoc, err := time.LoadLocation(conv.FromTimezone)
-        if err != nil {
-            continue
+        // Stop converting once the client has disconnected
+        if r.Context().Err() != nil {
+            logAt(logDebug, "batch convert cancelled after %d result(s)", len(results))
+            return
         }
-        toLoc, err := time.LoadLocation(conv.ToTimezone)
+        src, dst, err := convertTime(conv.Time, conv.FromTimezone, conv.ToTimezone)
         if err != nil {
-            continue
+            continue // Skip invalid entries
         }
-
-        // Convert time
-        sourceTime := t.In(fromLoc)
-        convertedTime := sourceTime.In(toLoc)
-
-        results = append(results, ConvertResponse{
-            OriginalTime:  sourceTime.Format(time.RFC3339),
-            FromTimezone:  conv.FromTimezone,
-            ConvertedTime: convertedTime.Format(time.RFC3339),
-            ToTimezone:    conv.ToTimezone,
-            Unix:          convertedTime.Unix(),
-        })
+        results = append(results, newConvertResponse(src, dst))
     }
 
     response := BatchConvertResponse{
@@ -234,6 +200,24 @@ func handleRESTBatchConvert(w http.ResponseWriter, r *http.Request) {
     writeJSON(w, http.StatusOK, response)
 }
 
+// knownTimezones are the zones listed by GET /api/v1/timezones and offered by
+// shell completion
+var knownTimezones = []string{
+    "UTC", "America/New_York", "America/Chicago", "America/Denver",
+    "America/Los_Angeles", "America/Toronto", "America/Vancouver",
+    "America/Mexico_City", "America/Sao_Paulo", "America/Buenos_Aires",
+    "Europe/London", "Europe/Paris", "Europe/Berlin", "Europe/Rome",
+    "Europe/Madrid", "Europe/Amsterdam", "Europe/Brussels", "Europe/Zurich",
+    "Europe/Stockholm", "Europe/Oslo", "Europe/Copenhagen", "Europe/Helsinki",
+    "Europe/Moscow", "Europe/Istanbul", "Europe/Athens", "Europe/Warsaw",
+    "Asia/Tokyo", "Asia/Shanghai", "Asia/Hong_Kong", "Asia/Singapore",
+    "Asia/Seoul", "Asia/Taipei", "Asia/Bangkok", "Asia/Jakarta",
+    "Asia/Kolkata", "Asia/Dubai", "Asia/Tel_Aviv", "Asia/Riyadh",
+    "Australia/Sydney", "Australia/Melbourne", "Australia/Brisbane",
+    "Australia/Perth", "Pacific/Auckland", "Pacific/Fiji",
+    "Africa/Cairo", "Africa/Lagos", "Africa/Johannesburg", "Africa/Nairobi",
+}
+
 // handleRESTListTimezones handles GET /api/v1/timezones
 func handleRESTListTimezones(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
@@ -241,37 +225,12 @@ func handleRESTListTimezones(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    filter := r.URL.Query().Get("filter")
-
-    // Get all known timezones
-    var timezones []string
-    for _, tz := range []string{
-        "UTC", "America/New_York", "America/Chicago", "America/Denver",
-        "America/Los_Angeles", "America/Toronto", "America/Vancouver",
-        "America/Mexico_City", "America/Sao_Paulo", "America/Buenos_Aires",
-        "Europe/London", "Europe/Paris", "Europe/Berlin", "Europe/Rome",
-        "Europe/Madrid", "Europe/Amsterdam", "Europe/Brussels", "Europe/Zurich",
-        "Europe/Stockholm", "Europe/Oslo", "Europe/Copenhagen", "Europe/Helsinki",
-        "Europe/Moscow", "Europe/Istanbul", "Europe/Athens", "Europe/Warsaw",
-        "Asia/Tokyo", "Asia/Shanghai", "Asia/Hong_Kong", "Asia/Singapore",
-        "Asia/Seoul", "Asia/Taipei", "Asia/Bangkok", "Asia/Jakarta",
-        "Asia/Kolkata", "Asia/Dubai", "Asia/Tel_Aviv", "Asia/Riyadh",
-        "Australia/Sydney", "Australia/Melbourne", "Australia/Brisbane",
-        "Australia/Perth", "Pacific/Auckland", "Pacific/Fiji",
-        "Africa/Cairo", "Africa/Lagos", "Africa/Johannesburg", "Africa/Nairobi",
-    } {
-        if filter == "" || strings.Contains(strings.ToLower(tz), strings.ToLower(filter)) {
-            timezones = append(timezones, tz)
-        }
-    }
-
-    writeJSON(w, http.StatusOK, map[string]interface{}{
-        "timezones": timezones,
-        "count":     len(timezones),
-    })
+    // Filtering, sorting and paging are in tzlist.go
+    writeTimezoneList(w, r)
 }
 
-// handleRESTTimezoneInfo handles GET /api/v1/timezones/{timezone}/info
+// handleRESTTimezoneInfo handles GET /api/v1/timezones/{timezone} and
+// GET /api/v1/timezones/{timezone}/info
 func handleRESTTimezoneInfo(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
         writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -280,27 +239,32 @@ func handleRESTTimezoneInfo(w http.ResponseWriter, r *http.Request) {
 
     // Extract timezone from path
     path := strings.TrimPrefix(r.URL.Path, "/api/v1/timezones/")
-    path = strings.TrimSuffix(path, "/info")
-    timezone := path
+    timezone, short := strings.CutSuffix(path, "/info")
 
     if timezone == "" {
-        writeJSONError(w, http.StatusBadRequest, "Timezone not specified")
+        writeAPIError(w, http.StatusBadRequest, errMissingField("timezone"))
         return
     }
 
     // Load timezone location
-    loc, err := time.LoadLocation(timezone)
+    loc, err := loadLocation(timezone)
     if err != nil {
-        writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid timezone: %s", timezone))
+        writeAPIError(w, http.StatusBadRequest, errInvalidTimezone("timezone", timezone, err))
+        return
+    }
+
+    // Without /info: the detail of the timezone://zones/{zone} resource
+    if !short {
+        writeJSON(w, http.StatusOK, timezoneDetail(loc, currentTime()))
         return
     }
 
     // Get current time in the timezone
-    now := time.Now().In(loc)
+    now := currentTime().In(loc)
     _, offset := now.Zone()
 
     info := TimezoneInfo{
-        Name:         timezone,
+        Name:         loc.String(),
         Offset:       fmt.Sprintf("%+d:%02d", offset/3600, (offset%3600)/60),
         CurrentTime:  now.Format(time.RFC3339),
         IsDST:        now.IsDST(),
@@ -324,7 +288,7 @@ func handleRESTTestEcho(w http.ResponseWriter, r *http.Request) {
 
     writeJSON(w, http.StatusOK, map[string]string{
         "echo":      message,
-        "timestamp": time.Now().Format(time.RFC3339),
+        "timestamp": currentTime().Format(time.RFC3339),
         "server":    "fast-time-server",
     })
 }
@@ -345,7 +309,7 @@ func handleRESTTestValidate(w http.ResponseWriter, r *http.Request) {
     writeJSON(w, http.StatusOK, map[string]interface{}{
         "valid":     true,
         "received":  body,
-        "timestamp": time.Now().Format(time.RFC3339),
+        "timestamp": currentTime().Format(time.RFC3339),
     })
 }
 
@@ -361,6 +325,9 @@ func handleRESTTestPerformance(w http.ResponseWriter, r *http.Request) {
     // Perform some operations to measure
     testOps := 1000
     for i := 0; i < testOps; i++ {
+        if r.Context().Err() != nil {
+            return
+        }
         _ = time.Now().Format(time.RFC3339)
     }
 
@@ -371,58 +338,19 @@ func handleRESTTestPerformance(w http.ResponseWriter, r *http.Request) {
         "duration_ms":    duration.Milliseconds(),
         "duration_ns":    duration.Nanoseconds(),
         "ops_per_second": float64(testOps) / duration.Seconds(),
-        "server_time":    time.Now().Format(time.RFC3339),
+        "server_time":    currentTime().Format(time.RFC3339),
     })
 }
 
-// handleOpenAPISpec handles GET /api/v1/openapi.json
+// handleOpenAPISpec handles GET /api/v1/openapi.json and /api/v2/openapi.json
 func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
         writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
         return
     }
 
-    spec := getOpenAPISpec()
-    writeJSON(w, http.StatusOK, spec)
-}
-
-// handleAPIDocs handles GET /api/v1/docs
-func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
-    if r.Method != http.MethodGet {
-        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
-        return
-    }
-
-    // Serve a simple HTML page with Swagger UI
-    html := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <title>Fast Time Server API Documentation</title>
-    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
-</head>
-<body>
-    <div id="swagger-ui"></div>
-    <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
-    <script>
-        window.onload = function() {
-            SwaggerUIBundle({
-                url: "/api/v1/openapi.json",
-                dom_id: '#swagger-ui',
-                presets: [
-                    SwaggerUIBundle.presets.apis,
-                    SwaggerUIBundle.SwaggerUIStandalonePreset
-                ],
-                layout: "BaseLayout"
-            });
-        }
-    </script>
-</body>
-</html>`
-
-    w.Header().Set("Content-Type", "text/html; charset=utf-8")
-    w.WriteHeader(http.StatusOK)
-    _, _ = w.Write([]byte(html))
+    spec := openAPISpecFor(apiVersion(r))
+    writeCacheable(w, r, spec, staticMaxAge)
 }
 
 // handleRESTListResources handles GET /api/v1/resources
@@ -454,15 +382,15 @@ func handleRESTListResources(w http.ResponseWriter, r *http.Request) {
         {
             "uri":         "time://business-hours",
             "name":        "Business Hours",
-            "description": "Standard business hours across different regions",
+            "description": "Standard workweek days, office hours and lunch breaks by country and region",
             "mime_type":   "application/json",
         },
     }
 
-    writeJSON(w, http.StatusOK, map[string]interface{}{
+    writeCacheable(w, r, map[string]interface{}{
         "resources": resources,
         "count":     len(resources),
-    })
+    }, staticMaxAge)
 }
 
 // handleRESTGetResource handles GET /api/v1/resources/{uri}
@@ -486,7 +414,7 @@ func handleRESTGetResource(w http.ResponseWriter, r *http.Request) {
     case "timezone-info":
         // Return timezone information
         data := getTimezoneInfoData()
-        writeJSON(w, http.StatusOK, data)
+        writeCacheable(w, r, data, staticMaxAge)
 
     case "current-world":
         // Return current world times
@@ -496,12 +424,12 @@ func handleRESTGetResource(w http.ResponseWriter, r *http.Request) {
     case "time-formats":
         // Return time format examples
         data := getTimeFormatsData()
-        writeJSON(w, http.StatusOK, data)
+        writeCacheable(w, r, data, staticMaxAge)
 
     case "business-hours":
         // Return business hours
-        data := getBusinessHoursData()
-        writeJSON(w, http.StatusOK, data)
+        data := businessHoursData()
+        writeCacheable(w, r, data, staticMaxAge)
 
     default:
         writeJSONError(w, http.StatusNotFound, fmt.Sprintf("Resource not found: %s", resourceURI))
@@ -586,10 +514,10 @@ func handleRESTListPrompts(w http.ResponseWriter, r *http.Request) {
         },
     }
 
-    writeJSON(w, http.StatusOK, map[string]interface{}{
+    writeCacheable(w, r, map[string]interface{}{
         "prompts": prompts,
         "count":   len(prompts),
-    })
+    }, staticMaxAge)
 }
 
 // handleRESTExecutePrompt handles POST /api/v1/prompts/{name}/execute
@@ -637,18 +565,30 @@ func handleRESTExecutePrompt(w http.ResponseWriter, r *http.Request) {
     })
 }
 
-// registerRESTHandlers registers all REST API handlers
-func registerRESTHandlers(mux *http.ServeMux) {
+// registerRESTHandlers registers all REST API handlers under /api/v1/ with
+// content negotiation, and serves /api/v2/ with the same handlers
+// (apiversion.go)
+func registerRESTHandlers(root *http.ServeMux) {
+    mux := http.NewServeMux()
+    api := slowHTTPMiddleware(negotiateMiddleware(idempotencyMiddleware(mux)))
+    root.Handle(apiV1Prefix+"/", versionMiddleware(apiV1, api))
+    root.Handle(apiV2Prefix+"/", versionMiddleware(apiV2, api))
+
     // Time operations
     mux.HandleFunc("/api/v1/time", handleRESTGetTime)
     mux.HandleFunc("/api/v1/time/", handleRESTGetTime) // With timezone in path
+    mux.HandleFunc(restTimeStreamPath, handleRESTTimeStream)
     mux.HandleFunc("/api/v1/convert", handleRESTConvertTime)
-    mux.HandleFunc("/api/v1/convert/batch", handleRESTBatchConvert)
+    mux.HandleFunc("/api/v1/convert/batch", heavyHandler(handleRESTBatchConvert))
 
     // Timezone operations
     mux.HandleFunc("/api/v1/timezones", handleRESTListTimezones)
     mux.HandleFunc("/api/v1/timezones/", handleRESTTimezoneInfo) // With timezone in path
 
+    // Webhook schedules
+    mux.HandleFunc(schedulesPath, handleRESTSchedules)
+    mux.HandleFunc(schedulesPath+"/", handleRESTSchedule) // With schedule ID in path
+
     // Resource operations
     mux.HandleFunc("/api/v1/resources", handleRESTListResources)
     mux.HandleFunc("/api/v1/resources/", handleRESTGetResource) // With resource URI in path
@@ -665,6 +605,7 @@ func registerRESTHandlers(mux *http.ServeMux) {
     // Documentation
     mux.HandleFunc("/api/v1/openapi.json", handleOpenAPISpec)
     mux.HandleFunc("/api/v1/docs", handleAPIDocs)
+    mux.Handle(docsAssetsPrefix, docsAssetsHandler())
 }
 
 // Helper functions for resource data
@@ -719,10 +660,10 @@ func getCurrentWorldTimesData() map[string]interface{} {
     }
 
     times := make(map[string]string)
-    now := time.Now()
+    now := currentTime()
 
     for city, tz := range cities {
-        if loc, err := time.LoadLocation(tz); err == nil {
+        if loc, err := loadLocation(tz); err == nil {
             localTime := now.In(loc)
             times[city] = localTime.Format("2006-01-02 15:04:05 MST")
         }
@@ -750,23 +691,6 @@ func getTimeFormatsData() map[string]interface{} {
     }
 }
 
-func getBusinessHoursData() map[string]interface{} {
-    return map[string]interface{}{
-        "regions": map[string]interface{}{
-            "north_america": map[string]interface{}{
-                "standard_hours": "9:00 AM - 5:00 PM",
-                "lunch_break":    "12:00 PM - 1:00 PM",
-                "working_days":   []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
-            },
-            "europe": map[string]interface{}{
-                "standard_hours": "9:00 AM - 6:00 PM",
-                "lunch_break":    "1:00 PM - 2:00 PM",
-                "working_days":   []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
-            },
-        },
-    }
-}
-
 // Helper functions for generating prompts
 func generateCompareTimezonesPrompt(args map[string]string) string {
     timezones := args["timezones"]
@@ -830,7 +754,8 @@ func corsMiddleware(next http.Handler) http.Handler {
         // Set CORS headers
         w.Header().Set("Access-Control-Allow-Origin", "*")
         w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+defaultTZHeader+", "+envelopeHeader+", "+requestIDHeader)
+        w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
         w.Header().Set("Access-Control-Max-Age", "3600")
 
         // Handle preflight requests
@@ -4,7 +4,7 @@
 // Copyright 2025
 // SPDX-License-Identifier: Apache-2.0
 
-package main
+package fasttime
 
 import (
     "bytes"
@@ -220,6 +220,47 @@ func TestHandleRESTListTimezones(t *testing.T) {
     }
 }
 
+func TestHandleRESTTimezoneInfo(t *testing.T) {
+    tests := []struct {
+        url        string
+        wantStatus int
+        wantKey    string
+    }{
+        {"/api/v1/timezones/Europe/London", http.StatusOK, "example_cities"},
+        {"/api/v1/timezones/Europe/London", http.StatusOK, "next_transition"},
+        {"/api/v1/timezones/Europe/London/info", http.StatusOK, "current_time"},
+        {"/api/v1/timezones/Mars/Olympus", http.StatusBadRequest, ""},
+    }
+
+    for _, tt := range tests {
+        req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+        w := httptest.NewRecorder()
+        handleRESTTimezoneInfo(w, req)
+        if w.Code != tt.wantStatus {
+            t.Errorf("%s: want status %d, got %d", tt.url, tt.wantStatus, w.Code)
+            continue
+        }
+        if tt.wantKey == "" {
+            continue
+        }
+        var body map[string]interface{}
+        if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+            t.Fatalf("failed to decode response: %v", err)
+        }
+        if _, ok := body[tt.wantKey]; !ok {
+            t.Errorf("%s: %s missing from %v", tt.url, tt.wantKey, body)
+        }
+    }
+
+    // The /info form keeps its original shape
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/timezones/Europe/London/info", nil)
+    w := httptest.NewRecorder()
+    handleRESTTimezoneInfo(w, req)
+    if strings.Contains(w.Body.String(), "example_cities") {
+        t.Errorf("/info should not include the detail fields: %s", w.Body.String())
+    }
+}
+
 func TestHandleRESTTestEcho(t *testing.T) {
     tests := []struct {
         name        string
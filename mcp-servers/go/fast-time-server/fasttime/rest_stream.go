@@ -0,0 +1,149 @@
+// -*- coding: utf-8 -*-
+// rest_stream.go - streaming world clock for the REST API
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements GET /api/v1/time/stream, a plain Server-Sent Events
+// feed of the current time in a set of zones. It is meant for dashboards that
+// want a ticking clock without speaking MCP: one "time" event is sent as soon
+// as the client connects and then once per interval until it disconnects.
+
+package fasttime
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strings"
+    "time"
+)
+
+// restTimeStreamPath is the route of the stream
+const restTimeStreamPath = "/api/v1/time/stream"
+
+// Bounds and default for the stream interval
+const (
+    defaultStreamInterval = time.Second
+    minStreamInterval     = 100 * time.Millisecond
+    maxStreamInterval     = time.Hour
+)
+
+// TimeStreamEvent is the payload of each "time" event on the stream
+type TimeStreamEvent struct {
+    UTC   string         `json:"utc"`
+    Unix  int64          `json:"unix"`
+    Times []TimeResponse `json:"times"`
+}
+
+// parseStreamZones splits the timezones parameter and loads each zone
+func parseStreamZones(raw string) ([]string, []*time.Location, error) {
+    if strings.TrimSpace(raw) == "" {
+        raw = "UTC"
+    }
+    var names []string
+    var locs []*time.Location
+    for _, name := range strings.Split(raw, ",") {
+        name = strings.TrimSpace(name)
+        if name == "" {
+            continue
+        }
+        loc, err := loadLocation(name)
+        if err != nil {
+            return nil, nil, fmt.Errorf("invalid timezone: %s", name)
+        }
+        names = append(names, name)
+        locs = append(locs, loc)
+    }
+    return names, locs, nil
+}
+
+// parseStreamInterval reads the interval parameter within the allowed bounds
+func parseStreamInterval(raw string) (time.Duration, error) {
+    if raw == "" {
+        return defaultStreamInterval, nil
+    }
+    d, err := time.ParseDuration(raw)
+    if err != nil {
+        return 0, fmt.Errorf("invalid interval: %s", raw)
+    }
+    if d < minStreamInterval || d > maxStreamInterval {
+        return 0, fmt.Errorf("interval must be between %s and %s", minStreamInterval, maxStreamInterval)
+    }
+    return d, nil
+}
+
+// newTimeStreamEvent builds the event for now in every zone
+func newTimeStreamEvent(now time.Time, names []string, locs []*time.Location) TimeStreamEvent {
+    ev := TimeStreamEvent{
+        UTC:   now.UTC().Format(time.RFC3339),
+        Unix:  now.Unix(),
+        Times: make([]TimeResponse, len(names)),
+    }
+    for i, loc := range locs {
+        ev.Times[i] = TimeResponse{
+            Time:     now.In(loc).Format(time.RFC3339),
+            Timezone: names[i],
+            Unix:     now.Unix(),
+            UTC:      ev.UTC,
+        }
+    }
+    return ev
+}
+
+// handleRESTTimeStream handles GET /api/v1/time/stream
+func handleRESTTimeStream(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+
+    names, locs, err := parseStreamZones(r.URL.Query().Get("timezones"))
+    if err != nil {
+        writeJSONError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    interval, err := parseStreamInterval(r.URL.Query().Get("interval"))
+    if err != nil {
+        writeJSONError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    flusher, ok := w.(http.Flusher)
+    if !ok {
+        writeJSONError(w, http.StatusInternalServerError, "Streaming not supported")
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+    w.Header().Set("Connection", "keep-alive")
+    w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+    w.WriteHeader(http.StatusOK)
+
+    logAt(logDebug, "time stream opened: zones=%v interval=%s", names, interval)
+    defer logAt(logDebug, "time stream closed")
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    now := currentTime()
+    for id := 1; ; id++ {
+        data, err := json.Marshal(newTimeStreamEvent(now, names, locs))
+        if err != nil {
+            logAt(logError, "time stream: failed to encode event: %v", err)
+            return
+        }
+        if _, err := fmt.Fprintf(w, "id: %d\nevent: time\ndata: %s\n\n", id, data); err != nil {
+            return
+        }
+        flusher.Flush()
+
+        select {
+        case <-r.Context().Done():
+            return
+        case <-ticker.C:
+            now = currentTime()
+        }
+    }
+}
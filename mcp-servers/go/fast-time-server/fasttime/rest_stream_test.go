@@ -0,0 +1,87 @@
+// -*- coding: utf-8 -*-
+// rest_stream_test.go - Tests for the REST time stream
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestHandleRESTTimeStreamValidation(t *testing.T) {
+    tests := []struct {
+        name   string
+        method string
+        query  string
+        code   int
+    }{
+        {"wrong method", http.MethodPost, "", http.StatusMethodNotAllowed},
+        {"bad zone", http.MethodGet, "?timezones=UTC,Mars/Olympus", http.StatusBadRequest},
+        {"bad interval", http.MethodGet, "?interval=soon", http.StatusBadRequest},
+        {"interval too short", http.MethodGet, "?interval=1ms", http.StatusBadRequest},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            w := httptest.NewRecorder()
+            handleRESTTimeStream(w, httptest.NewRequest(tt.method, "/api/v1/time/stream"+tt.query, nil))
+            if w.Code != tt.code {
+                t.Errorf("status = %d, want %d", w.Code, tt.code)
+            }
+        })
+    }
+}
+
+func TestHandleRESTTimeStreamEvents(t *testing.T) {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    srv := httptest.NewServer(mux)
+    defer srv.Close()
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    req, _ := http.NewRequestWithContext(ctx, http.MethodGet,
+        srv.URL+"/api/v1/time/stream?timezones=UTC,Asia/Tokyo&interval=100ms", nil)
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        t.Fatalf("request failed: %v", err)
+    }
+    defer resp.Body.Close()
+
+    if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+        t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+    }
+
+    // Read two events to confirm the stream keeps ticking
+    scanner := bufio.NewScanner(resp.Body)
+    var events []TimeStreamEvent
+    for len(events) < 2 && scanner.Scan() {
+        line := scanner.Text()
+        if !strings.HasPrefix(line, "data: ") {
+            continue
+        }
+        var ev TimeStreamEvent
+        if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+            t.Fatalf("bad event %q: %v", line, err)
+        }
+        events = append(events, ev)
+    }
+    if len(events) != 2 {
+        t.Fatalf("got %d events, want 2", len(events))
+    }
+    if len(events[0].Times) != 2 || events[0].Times[1].Timezone != "Asia/Tokyo" {
+        t.Errorf("unexpected zones: %+v", events[0].Times)
+    }
+    if !strings.HasSuffix(events[0].Times[1].Time, "+09:00") {
+        t.Errorf("Tokyo time = %s, want +09:00 offset", events[0].Times[1].Time)
+    }
+}
@@ -0,0 +1,63 @@
+// -*- coding: utf-8 -*-
+// restart.go - zero-downtime binary upgrades
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements socket handoff for zero-downtime restarts. On SIGUSR2
+// the running server starts a new copy of its executable (typically a freshly
+// installed binary at the same path) with the same arguments, passing the
+// listening socket as an extra file descriptor named by FAST_TIME_LISTEN_FD.
+// The new process serves on the inherited socket right away, so no connection
+// is refused, while the old one stops accepting and lets open requests and SSE
+// streams drain for up to -drain-timeout before exiting. Clients of a closed
+// SSE stream reconnect to the new process.
+//
+// The signal handling lives in restart_unix.go; on Windows upgrades are not
+// supported and SIGUSR2 does not exist.
+
+package fasttime
+
+import (
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "time"
+)
+
+// envListenFD names the inherited listener descriptor in the new process
+const envListenFD = "FAST_TIME_LISTEN_FD"
+
+// defaultDrainTimeout is the default -drain-timeout
+const defaultDrainTimeout = time.Minute
+
+// drainTimeout bounds how long an upgraded process waits for open
+// connections before exiting (set from -drain-timeout)
+var drainTimeout = defaultDrainTimeout
+
+// inheritedListener returns the socket handed over by a previous process,
+// or nil when there is none
+func inheritedListener() (net.Listener, error) {
+    v := os.Getenv(envListenFD)
+    if v == "" {
+        return nil, nil
+    }
+    os.Unsetenv(envListenFD)
+    fd, err := strconv.Atoi(v)
+    if err != nil || fd < 3 {
+        return nil, fmt.Errorf("invalid %s=%q", envListenFD, v)
+    }
+    f := os.NewFile(uintptr(fd), "inherited-listener")
+    defer f.Close() // FileListener dups the descriptor
+    return net.FileListener(f)
+}
+
+// listenerFile returns a duplicate descriptor of ln for handing over
+func listenerFile(ln net.Listener) (*os.File, error) {
+    fl, ok := ln.(interface{ File() (*os.File, error) })
+    if !ok {
+        return nil, fmt.Errorf("listener %T cannot be handed over", ln)
+    }
+    return fl.File()
+}
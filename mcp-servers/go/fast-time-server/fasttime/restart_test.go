@@ -0,0 +1,72 @@
+// -*- coding: utf-8 -*-
+// restart_test.go - Tests for listener handoff
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "net"
+    "os"
+    "strconv"
+    "syscall"
+    "testing"
+)
+
+func TestInheritedListener(t *testing.T) {
+    t.Setenv(envListenFD, "")
+    if ln, err := inheritedListener(); ln != nil || err != nil {
+        t.Fatalf("want no listener, got %v, %v", ln, err)
+    }
+
+    orig, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer orig.Close()
+    f, err := listenerFile(orig)
+    if err != nil {
+        t.Fatalf("listenerFile: %v", err)
+    }
+    // inheritedListener takes ownership of the descriptor, as after exec
+    fd, err := syscall.Dup(int(f.Fd()))
+    f.Close()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    t.Setenv(envListenFD, strconv.Itoa(fd))
+    ln, err := inheritedListener()
+    if err != nil || ln == nil {
+        t.Fatalf("inheritedListener: %v, %v", ln, err)
+    }
+    defer ln.Close()
+    if ln.Addr().String() != orig.Addr().String() {
+        t.Errorf("inherited %s, want %s", ln.Addr(), orig.Addr())
+    }
+    if os.Getenv(envListenFD) != "" {
+        t.Error("the variable should be cleared for child processes")
+    }
+
+    t.Setenv(envListenFD, "not-a-number")
+    if _, err := inheritedListener(); err == nil {
+        t.Error("expected error for malformed descriptor")
+    }
+}
+
+// plainListener hides the File method of the wrapped listener
+type plainListener struct{ net.Listener }
+
+func TestListenerFileUnsupported(t *testing.T) {
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer ln.Close()
+    if _, err := listenerFile(plainListener{ln}); err == nil {
+        t.Error("expected error for a listener without File")
+    }
+}
@@ -0,0 +1,78 @@
+// -*- coding: utf-8 -*-
+// restart_unix.go - SIGUSR2 upgrade trigger
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file starts the replacement process on SIGUSR2 and drains the current
+// one; see restart.go for the overall mechanism.
+
+//go:build !windows
+
+package fasttime
+
+import (
+    "context"
+    "net"
+    "net/http"
+    "os"
+    "os/exec"
+    "os/signal"
+    "strconv"
+    "syscall"
+)
+
+// watchUpgrade hands ln over to a new process on SIGUSR2 and shuts srv down.
+// The returned channel is closed once the old connections have drained.
+func watchUpgrade(srv *http.Server, ln net.Listener) <-chan struct{} {
+    drained := make(chan struct{})
+    sig := make(chan os.Signal, 1)
+    signal.Notify(sig, syscall.SIGUSR2)
+
+    go func() {
+        for range sig {
+            pid, err := startUpgrade(ln)
+            if err != nil {
+                logAt(logError, "upgrade failed, continuing to serve: %v", err)
+                continue
+            }
+            signal.Stop(sig)
+            logAt(logInfo, "upgrade: handed listener to pid %d; draining for up to %v", pid, drainTimeout)
+            _, _ = sdNotify("MAINPID=" + strconv.Itoa(pid))
+
+            listenerState.draining.Store(true)
+            ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+            if err := srv.Shutdown(ctx); err != nil {
+                logAt(logWarn, "upgrade: closing connections still open after %v", drainTimeout)
+                _ = srv.Close()
+            }
+            cancel()
+            close(drained)
+            return
+        }
+    }()
+    return drained
+}
+
+// startUpgrade launches the executable again with ln as an extra file
+func startUpgrade(ln net.Listener) (int, error) {
+    exe, err := os.Executable()
+    if err != nil {
+        return 0, err
+    }
+    f, err := listenerFile(ln)
+    if err != nil {
+        return 0, err
+    }
+    defer f.Close()
+
+    cmd := exec.Command(exe, os.Args[1:]...)
+    cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+    cmd.ExtraFiles = []*os.File{f} // becomes fd 3
+    cmd.Env = append(os.Environ(), envListenFD+"=3")
+    if err := cmd.Start(); err != nil {
+        return 0, err
+    }
+    go func() { _ = cmd.Process.Release() }()
+    return cmd.Process.Pid, nil
+}
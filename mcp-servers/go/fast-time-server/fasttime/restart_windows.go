@@ -0,0 +1,22 @@
+// -*- coding: utf-8 -*-
+// restart_windows.go - upgrade stub for Windows
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Windows has no SIGUSR2 or descriptor inheritance of this kind, so
+// zero-downtime upgrades are not available there.
+
+//go:build windows
+
+package fasttime
+
+import (
+    "net"
+    "net/http"
+)
+
+// watchUpgrade is a no-op on Windows; the nil channel is never waited on
+func watchUpgrade(_ *http.Server, _ net.Listener) <-chan struct{} {
+    return nil
+}
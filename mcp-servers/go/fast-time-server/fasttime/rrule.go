@@ -0,0 +1,457 @@
+// -*- coding: utf-8 -*-
+// rrule.go - iCalendar recurrence rules
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// parseRRule reads an RFC 5545 RRULE value and expand lists its
+// occurrences. Expansion works on wall-clock times, like cron.go: a weekly
+// 09:00 meeting stays at 09:00 across DST changes, and the caller turns each
+// wall time into an instant with the event's zone.
+//
+// FREQ=DAILY, WEEKLY, MONTHLY and YEARLY are expanded with INTERVAL, COUNT,
+// UNTIL, BYMONTH, BYMONTHDAY, BYDAY (with ordinals such as 2SU or -1FR),
+// BYSETPOS and WKST; HOURLY and MINUTELY only with INTERVAL, COUNT and
+// UNTIL. Other parts are accepted but reported through unsupported, so that
+// callers can say the occurrences were not expanded instead of listing
+// wrong ones.
+
+package fasttime
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// rruleMaxPeriods bounds the periods (days, weeks, months or years) scanned
+// by expand, so that rules which rarely or never match terminate
+const rruleMaxPeriods = 100000
+
+// icalFrequencies are the FREQ values of an RRULE
+var icalFrequencies = map[string]bool{
+    "SECONDLY": true, "MINUTELY": true, "HOURLY": true, "DAILY": true,
+    "WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+}
+
+// icalWeekdays maps RRULE day names to weekdays
+var icalWeekdays = map[string]time.Weekday{
+    "SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+    "TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// rruleDay is one BYDAY entry: a weekday with an optional ordinal (0 = every)
+type rruleDay struct {
+    ord int
+    day time.Weekday
+}
+
+// icalRule is a parsed RRULE
+type icalRule struct {
+    text     string // normalized value, without the RRULE: prefix
+    freq     string
+    interval int
+    count    int
+
+    until     time.Time // zero without UNTIL; a wall time unless untilUTC
+    untilUTC  bool
+    untilDate bool // UNTIL is a DATE
+
+    byMonth    []int
+    byMonthDay []int
+    byDay      []rruleDay
+    bySetPos   []int
+    wkst       time.Weekday
+
+    unsupported string // first part expand cannot honour
+}
+
+// rruleInts parses a comma-separated list of integers within ±[lo, hi]
+func rruleInts(name, value string, lo, hi int, signed bool) ([]int, error) {
+    var out []int
+    for _, s := range strings.Split(value, ",") {
+        n, err := strconv.Atoi(s)
+        abs := n
+        if abs < 0 && signed {
+            abs = -abs
+        }
+        if err != nil || abs < lo || abs > hi {
+            return nil, fmt.Errorf("invalid rrule %s value %q", name, s)
+        }
+        out = append(out, n)
+    }
+    return out, nil
+}
+
+// parseRRuleUntil reads an UNTIL value: a DATE, a local DATE-TIME or UTC
+func (r *icalRule) parseUntil(value string) error {
+    var err error
+    switch {
+    case strings.HasSuffix(value, "Z"):
+        r.until, err = time.Parse(icalUTCLayout, value)
+        r.untilUTC = true
+    case len(value) == len("20060102"):
+        r.until, err = time.Parse("20060102", value)
+        r.untilDate = true
+    default:
+        r.until, err = time.Parse(icalLocalLayout, value)
+    }
+    if err != nil {
+        return fmt.Errorf("invalid rrule UNTIL %q (want e.g. 20251231T235959Z)", value)
+    }
+    return nil
+}
+
+// parseRRule checks an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"
+func parseRRule(raw string) (icalRule, error) {
+    text := strings.ToUpper(strings.TrimSpace(raw))
+    text = strings.TrimPrefix(text, "RRULE:")
+    r := icalRule{interval: 1, wkst: time.Monday}
+    seen := map[string]bool{}
+    for _, part := range strings.Split(text, ";") {
+        name, value, ok := strings.Cut(part, "=")
+        if !ok || value == "" {
+            return r, fmt.Errorf("invalid rrule part %q (want NAME=VALUE)", part)
+        }
+        if seen[name] {
+            return r, fmt.Errorf("rrule part %s given twice", name)
+        }
+        seen[name] = true
+
+        var err error
+        switch name {
+        case "FREQ":
+            if !icalFrequencies[value] {
+                return r, fmt.Errorf("invalid rrule FREQ %q", value)
+            }
+            r.freq = value
+        case "INTERVAL", "COUNT":
+            n, cerr := strconv.Atoi(value)
+            if cerr != nil || n < 1 {
+                return r, fmt.Errorf("rrule %s must be a positive number", name)
+            }
+            if name == "COUNT" {
+                r.count = n
+            } else {
+                r.interval = n
+            }
+        case "UNTIL":
+            err = r.parseUntil(value)
+        case "WKST":
+            wd, ok := icalWeekdays[value]
+            if !ok {
+                return r, fmt.Errorf("invalid rrule WKST %q", value)
+            }
+            r.wkst = wd
+        case "BYMONTH":
+            r.byMonth, err = rruleInts(name, value, 1, 12, false)
+        case "BYMONTHDAY":
+            r.byMonthDay, err = rruleInts(name, value, 1, 31, true)
+        case "BYSETPOS":
+            r.bySetPos, err = rruleInts(name, value, 1, 366, true)
+        case "BYDAY":
+            for _, s := range strings.Split(value, ",") {
+                wd, ok := icalWeekdays[s[max(len(s)-2, 0):]]
+                ord := 0
+                if n := s[:max(len(s)-2, 0)]; n != "" {
+                    var aerr error
+                    ord, aerr = strconv.Atoi(n)
+                    ok = ok && aerr == nil && ord != 0 && ord >= -53 && ord <= 53
+                }
+                if !ok {
+                    return r, fmt.Errorf("invalid rrule BYDAY value %q", s)
+                }
+                r.byDay = append(r.byDay, rruleDay{ord: ord, day: wd})
+            }
+        case "BYYEARDAY", "BYWEEKNO":
+            _, err = rruleInts(name, value, 1, 366, true)
+            r.unsupportedPart(name)
+        case "BYHOUR", "BYMINUTE", "BYSECOND":
+            _, err = rruleInts(name, value, 0, 60, false)
+            r.unsupportedPart(name)
+        default:
+            return r, fmt.Errorf("unsupported rrule part %s", name)
+        }
+        if err != nil {
+            return r, err
+        }
+    }
+
+    switch {
+    case r.freq == "":
+        return r, fmt.Errorf("rrule needs FREQ")
+    case r.count > 0 && !r.until.IsZero():
+        return r, fmt.Errorf("rrule may not have both COUNT and UNTIL")
+    case r.freq == "SECONDLY":
+        r.unsupportedPart("FREQ=SECONDLY")
+    case r.freq == "HOURLY" || r.freq == "MINUTELY":
+        for _, p := range []string{"BYMONTH", "BYMONTHDAY", "BYDAY", "BYSETPOS"} {
+            if seen[p] {
+                r.unsupportedPart(p + " with FREQ=" + r.freq)
+            }
+        }
+    }
+    for _, d := range r.byDay {
+        if d.ord != 0 && r.freq != "MONTHLY" && r.freq != "YEARLY" {
+            return r, fmt.Errorf("rrule BYDAY ordinals need FREQ=MONTHLY or YEARLY")
+        }
+    }
+    r.text = text
+    return r, nil
+}
+
+// unsupportedPart records the first part expand cannot honour
+func (r *icalRule) unsupportedPart(name string) {
+    if r.unsupported == "" {
+        r.unsupported = name
+    }
+}
+
+// civil returns the date of a wall time as midnight UTC
+func civil(t time.Time) time.Time {
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysIn returns the number of days of a month
+func daysIn(year int, month time.Month) int {
+    return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// matchDays returns the days of [first, last] that BYDAY selects, with
+// ordinals counted within that span
+func (r *icalRule) matchDays(first, last time.Time) []time.Time {
+    var out []time.Time
+    for _, bd := range r.byDay {
+        var all []time.Time
+        for d := first.AddDate(0, 0, (int(bd.day)-int(first.Weekday())+7)%7); !d.After(last); d = d.AddDate(0, 0, 7) {
+            all = append(all, d)
+        }
+        switch {
+        case bd.ord == 0:
+            out = append(out, all...)
+        case bd.ord > 0 && bd.ord <= len(all):
+            out = append(out, all[bd.ord-1])
+        case bd.ord < 0 && -bd.ord <= len(all):
+            out = append(out, all[len(all)+bd.ord])
+        }
+    }
+    return out
+}
+
+// monthDays returns the candidate days of one month; day is the day of
+// month of DTSTART, used when neither BYMONTHDAY nor BYDAY is given
+func (r *icalRule) monthDays(year int, month time.Month, day int) []time.Time {
+    n := daysIn(year, month)
+    first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+    if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+        if day > n {
+            return nil // e.g. the 31st in a 30-day month is skipped
+        }
+        return []time.Time{first.AddDate(0, 0, day-1)}
+    }
+
+    var byMonthDay []time.Time
+    for _, md := range r.byMonthDay {
+        if md < 0 {
+            md = n + md + 1
+        }
+        if md >= 1 && md <= n {
+            byMonthDay = append(byMonthDay, first.AddDate(0, 0, md-1))
+        }
+    }
+    if len(r.byDay) == 0 {
+        return byMonthDay
+    }
+    byDay := r.matchDays(first, first.AddDate(0, 0, n-1))
+    if len(r.byMonthDay) == 0 {
+        return byDay
+    }
+    return intersectDays(byMonthDay, byDay)
+}
+
+// intersectDays returns the days in both a and b
+func intersectDays(a, b []time.Time) []time.Time {
+    var out []time.Time
+    for _, x := range a {
+        for _, y := range b {
+            if x.Equal(y) {
+                out = append(out, x)
+                break
+            }
+        }
+    }
+    return out
+}
+
+// hasInt reports whether v is in list
+func hasInt(list []int, v int) bool {
+    for _, x := range list {
+        if x == v {
+            return true
+        }
+    }
+    return false
+}
+
+// keep applies BYMONTH, BYMONTHDAY and BYDAY as filters (DAILY and WEEKLY)
+func (r *icalRule) keep(d time.Time) bool {
+    if len(r.byMonth) > 0 && !hasInt(r.byMonth, int(d.Month())) {
+        return false
+    }
+    if len(r.byMonthDay) > 0 {
+        n := daysIn(d.Year(), d.Month())
+        if !hasInt(r.byMonthDay, d.Day()) && !hasInt(r.byMonthDay, d.Day()-n-1) {
+            return false
+        }
+    }
+    if len(r.byDay) > 0 {
+        found := false
+        for _, bd := range r.byDay {
+            found = found || bd.day == d.Weekday()
+        }
+        return found
+    }
+    return true
+}
+
+// periodDays returns the candidate days of period k after DTSTART's date
+func (r *icalRule) periodDays(start time.Time, k int) []time.Time {
+    var days []time.Time
+    switch r.freq {
+    case "DAILY":
+        if d := start.AddDate(0, 0, k*r.interval); r.keep(d) {
+            days = append(days, d)
+        }
+    case "WEEKLY":
+        week := start.AddDate(0, 0, -((int(start.Weekday())-int(r.wkst)+7)%7)+7*k*r.interval)
+        for i := 0; i < 7; i++ {
+            d := week.AddDate(0, 0, i)
+            if (len(r.byDay) > 0 || d.Weekday() == start.Weekday()) && r.keep(d) {
+                days = append(days, d)
+            }
+        }
+    case "MONTHLY":
+        m := time.Date(start.Year(), start.Month()+time.Month(k*r.interval), 1, 0, 0, 0, 0, time.UTC)
+        if len(r.byMonth) == 0 || hasInt(r.byMonth, int(m.Month())) {
+            days = r.monthDays(m.Year(), m.Month(), start.Day())
+        }
+    case "YEARLY":
+        year := start.Year() + k*r.interval
+        switch {
+        case len(r.byMonth) == 0 && len(r.byMonthDay) == 0 && len(r.byDay) == 0:
+            days = r.monthDays(year, start.Month(), start.Day())
+        case len(r.byMonth) == 0 && len(r.byDay) > 0:
+            // Ordinals count within the year, e.g. 20MO is the 20th Monday
+            days = r.matchDays(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC))
+            if len(r.byMonthDay) > 0 {
+                filter := *r
+                filter.byDay = nil
+                var kept []time.Time
+                for _, d := range days {
+                    if filter.keep(d) {
+                        kept = append(kept, d)
+                    }
+                }
+                days = kept
+            }
+        default:
+            months := r.byMonth
+            if len(months) == 0 {
+                months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+            }
+            for _, m := range months {
+                days = append(days, r.monthDays(year, time.Month(m), start.Day())...)
+            }
+        }
+    }
+
+    sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+    uniq := days[:0]
+    for i, d := range days {
+        if i == 0 || !d.Equal(days[i-1]) {
+            uniq = append(uniq, d)
+        }
+    }
+    days = uniq
+
+    if len(r.bySetPos) > 0 {
+        var picked []time.Time
+        for i, d := range days {
+            if hasInt(r.bySetPos, i+1) || hasInt(r.bySetPos, i-len(days)) {
+                picked = append(picked, d)
+            }
+        }
+        days = picked
+    }
+    return days
+}
+
+// pastUntil reports whether the occurrence at wall time w (instant at) is
+// after UNTIL
+func (r *icalRule) pastUntil(w, at time.Time) bool {
+    switch {
+    case r.until.IsZero():
+        return false
+    case r.untilUTC:
+        return at.After(r.until)
+    case r.untilDate:
+        return civil(w).After(r.until)
+    }
+    return w.After(r.until)
+}
+
+// expand calls fn with the wall time and instant of each occurrence in
+// order, starting with DTSTART (start, a wall time), until fn returns false,
+// COUNT or UNTIL is reached or an occurrence falls after end. instant turns
+// a wall time into an instant in the event's zone.
+func (r *icalRule) expand(start time.Time, instant func(time.Time) time.Time, end time.Time, fn func(wall, at time.Time) bool) {
+    n := 0
+    emit := func(w time.Time) bool {
+        at := instant(w)
+        if r.pastUntil(w, at) || at.After(end) || (r.count > 0 && n >= r.count) {
+            return false
+        }
+        n++
+        return fn(w, at)
+    }
+    if !emit(start) {
+        return
+    }
+
+    clock := start.Sub(civil(start))
+    if r.freq == "HOURLY" || r.freq == "MINUTELY" {
+        step := time.Hour
+        if r.freq == "MINUTELY" {
+            step = time.Minute
+        }
+        for k := 1; k < rruleMaxPeriods; k++ {
+            if !emit(start.Add(time.Duration(k*r.interval) * step)) {
+                return
+            }
+        }
+        return
+    }
+
+    day := civil(start)
+    for k := 0; k < rruleMaxPeriods; k++ {
+        days := r.periodDays(day, k)
+        for _, d := range days {
+            w := d.Add(clock)
+            if !w.After(start) {
+                continue
+            }
+            if !emit(w) {
+                return
+            }
+        }
+        // Stop once whole periods lie past the end of the window
+        if len(days) > 0 && instant(days[0]).After(end) {
+            return
+        }
+        if len(days) == 0 && r.freq == "DAILY" && instant(day.AddDate(0, 0, k*r.interval)).After(end) {
+            return
+        }
+    }
+}
@@ -0,0 +1,89 @@
+// -*- coding: utf-8 -*-
+// rrule_test.go - tests for RRULE parsing and expansion
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+// expandRule lists occurrences of rule from a DTSTART wall time in loc
+func expandRule(t *testing.T, rule, start string, loc *time.Location, end time.Time) []string {
+    t.Helper()
+    r, err := parseRRule(rule)
+    if err != nil {
+        t.Fatalf("parseRRule(%q): %v", rule, err)
+    }
+    wall, _ := time.Parse(icalLocalLayout, start)
+    var out []string
+    r.expand(wall, func(w time.Time) time.Time {
+        return forwardDate(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), loc)
+    }, end, func(w, at time.Time) bool {
+        out = append(out, at.In(loc).Format("2006-01-02T15:04Z07:00"))
+        return len(out) < 50
+    })
+    return out
+}
+
+func TestRRuleExpand(t *testing.T) {
+    ny, _ := time.LoadLocation("America/New_York")
+    end := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+    for _, tc := range []struct {
+        rule, start string
+        want        []string
+    }{
+        // Wall time kept across the March DST change
+        {"FREQ=WEEKLY;COUNT=3", "20250303T090000", []string{"2025-03-03T09:00-05:00", "2025-03-10T09:00-04:00", "2025-03-17T09:00-04:00"}},
+        {"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", "20250303T090000", []string{"2025-03-03T09:00-05:00", "2025-03-05T09:00-05:00", "2025-03-10T09:00-04:00", "2025-03-12T09:00-04:00"}},
+        {"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;UNTIL=20250401T000000Z", "20250304T100000", []string{"2025-03-04T10:00-05:00", "2025-03-18T10:00-04:00"}},
+        {"FREQ=DAILY;UNTIL=20250105", "20250103T080000", []string{"2025-01-03T08:00-05:00", "2025-01-04T08:00-05:00", "2025-01-05T08:00-05:00"}},
+        {"FREQ=DAILY;BYDAY=SA,SU;COUNT=3", "20250104T080000", []string{"2025-01-04T08:00-05:00", "2025-01-05T08:00-05:00", "2025-01-11T08:00-05:00"}},
+        // Last Friday, the 31st (skipping short months), last workday
+        {"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", "20250131T150000", []string{"2025-01-31T15:00-05:00", "2025-02-28T15:00-05:00", "2025-03-28T15:00-04:00"}},
+        {"FREQ=MONTHLY;COUNT=3", "20250131T150000", []string{"2025-01-31T15:00-05:00", "2025-03-31T15:00-04:00", "2025-05-31T15:00-04:00"}},
+        {"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3", "20250131T170000", []string{"2025-01-31T17:00-05:00", "2025-02-28T17:00-05:00", "2025-03-31T17:00-04:00"}},
+        {"FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR;COUNT=2", "20250613T120000", []string{"2025-06-13T12:00-04:00", "2026-02-13T12:00-05:00"}},
+        {"FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=3", "20250201T000000", []string{"2025-02-01T00:00-05:00", "2025-02-28T00:00-05:00", "2025-03-01T00:00-05:00"}},
+        // US DST onset and Thanksgiving
+        {"FREQ=YEARLY;BYMONTH=3;BYDAY=2SU;COUNT=2", "20250309T030000", []string{"2025-03-09T03:00-04:00", "2026-03-08T03:00-04:00"}},
+        {"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2", "20251127T120000", []string{"2025-11-27T12:00-05:00", "2026-11-26T12:00-05:00"}},
+        {"FREQ=YEARLY;COUNT=3", "20240229T090000", []string{"2024-02-29T09:00-05:00", "2028-02-29T09:00-05:00"}},
+        {"FREQ=HOURLY;INTERVAL=6;COUNT=3", "20250309T000000", []string{"2025-03-09T00:00-05:00", "2025-03-09T06:00-04:00", "2025-03-09T12:00-04:00"}},
+    } {
+        got := expandRule(t, tc.rule, tc.start, ny, end)
+        if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+            t.Errorf("%s from %s = %v, want %v", tc.rule, tc.start, got, tc.want)
+        }
+    }
+}
+
+func TestRRuleExpandStopsAtEnd(t *testing.T) {
+    got := expandRule(t, "FREQ=DAILY", "20250101T090000", time.UTC, time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC))
+    if len(got) != 3 {
+        t.Errorf("occurrences until the end = %v", got)
+    }
+    // A rule that never matches terminates
+    if got := expandRule(t, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "20250101T090000", time.UTC, time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)); len(got) != 1 {
+        t.Errorf("impossible rule = %v", got)
+    }
+}
+
+func TestParseRRule(t *testing.T) {
+    r, err := parseRRule("rrule:freq=monthly;byday=2mo,-1fr;until=20251231;wkst=su")
+    if err != nil || r.freq != "MONTHLY" || !r.untilDate || r.wkst != time.Sunday || len(r.byDay) != 2 || r.byDay[1].ord != -1 || r.unsupported != "" {
+        t.Errorf("parseRRule = %+v, %v", r, err)
+    }
+    if r, _ := parseRRule("FREQ=YEARLY;BYWEEKNO=20"); r.unsupported != "BYWEEKNO" {
+        t.Errorf("unsupported = %q", r.unsupported)
+    }
+    for _, bad := range []string{"", "FREQ=DAILY;COUNT=0", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYDAY=XX", "FREQ=MONTHLY;BYMONTHDAY=32", "FREQ=DAILY;UNTIL=soon"} {
+        if _, err := parseRRule(bad); err == nil {
+            t.Errorf("parseRRule(%q) should fail", bad)
+        }
+    }
+}
@@ -0,0 +1,115 @@
+// -*- coding: utf-8 -*-
+// sampling.go - natural-language summaries through MCP sampling
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets scheduling tools ask the connected client's LLM to describe
+// their results. When a tool is called with summarize=true and the client
+// declared the sampling capability in initialize, the computed result is sent
+// back with sampling/createMessage and the reply is returned as "summary"
+// next to the structured data. Sampling is best effort: if the client cannot
+// or will not answer, the data is returned unchanged with "summary_error".
+//
+// mcp-go carries server-to-client requests over stdio and streamable HTTP;
+// SSE sessions report sampling as unavailable.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    samplingMaxTokens = 400              // length cap for a summary
+    samplingTimeout   = 30 * time.Second // how long to wait for the client
+)
+
+// errSamplingUnsupported is reported when the session cannot sample
+var errSamplingUnsupported = errors.New("client does not support sampling")
+
+// samplingSystemPrompt instructs the client model
+const samplingSystemPrompt = "You summarize the output of a time and scheduling tool for a person. " +
+    "Answer in two to four plain sentences. Mention concrete local times and timezones, " +
+    "use only the data given and do not invent information."
+
+// summarizeWithSampling asks the client's model to describe data; what says
+// what the data is, e.g. "meeting windows"
+func summarizeWithSampling(ctx context.Context, what string, data interface{}) (string, string, error) {
+    srv := server.ServerFromContext(ctx)
+    id := sessionIDFrom(ctx)
+    if srv == nil || id == "" || !sessions.sampling(id) {
+        return "", "", errSamplingUnsupported
+    }
+    body, err := json.Marshal(data)
+    if err != nil {
+        return "", "", err
+    }
+
+    req := mcp.CreateMessageRequest{}
+    req.Messages = []mcp.SamplingMessage{{
+        Role:    mcp.RoleUser,
+        Content: mcp.NewTextContent(fmt.Sprintf("Summarize these %s:\n\n%s", what, body)),
+    }}
+    req.SystemPrompt = samplingSystemPrompt
+    req.MaxTokens = samplingMaxTokens
+    req.Temperature = 0.2
+    req.ModelPreferences = &mcp.ModelPreferences{SpeedPriority: 0.8, CostPriority: 0.5}
+
+    ctx, cancel := context.WithTimeout(ctx, samplingTimeout)
+    defer cancel()
+    start := time.Now()
+    res, err := srv.RequestSampling(ctx, req)
+    if err != nil {
+        if strings.Contains(err.Error(), "does not support sampling") {
+            return "", "", errSamplingUnsupported
+        }
+        return "", "", err
+    }
+    text := samplingText(res.Content)
+    if text == "" {
+        return "", "", errors.New("client returned no text")
+    }
+    logAt(logDebug, "sampling: %s summarized by %s in %s", what, res.Model, time.Since(start).Round(time.Millisecond))
+    return text, res.Model, nil
+}
+
+// samplingText extracts the text of a sampled message, which arrives as a
+// decoded JSON object
+func samplingText(content interface{}) string {
+    switch c := content.(type) {
+    case mcp.TextContent:
+        return strings.TrimSpace(c.Text)
+    case *mcp.TextContent:
+        return strings.TrimSpace(c.Text)
+    case map[string]interface{}:
+        if c["type"] == "text" {
+            text, _ := c["text"].(string)
+            return strings.TrimSpace(text)
+        }
+    }
+    return ""
+}
+
+// addSummary adds a sampled summary to result when the call asked for one
+func addSummary(ctx context.Context, req mcp.CallToolRequest, what string, result map[string]interface{}) {
+    if !req.GetBool("summarize", false) {
+        return
+    }
+    summary, model, err := summarizeWithSampling(ctx, what, result)
+    if err != nil {
+        logAt(logDebug, "sampling: no summary for %s: %v", what, err)
+        result["summary_error"] = err.Error()
+        return
+    }
+    result["summary"] = summary
+    result["summary_model"] = model
+}
@@ -0,0 +1,125 @@
+// -*- coding: utf-8 -*-
+// sampling_test.go - Tests for sampled summaries
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// samplingSession is a session whose client answers sampling requests
+type samplingSession struct {
+    fakeSession
+    reply string
+    err   error
+    got   *mcp.CreateMessageRequest
+}
+
+func (s *samplingSession) RequestSampling(_ context.Context, req mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+    s.got = &req
+    if s.err != nil {
+        return nil, s.err
+    }
+    res := &mcp.CreateMessageResult{Model: "test-model"}
+    res.Role = mcp.RoleAssistant
+    // Content arrives from the client as decoded JSON
+    res.Content = map[string]interface{}{"type": "text", "text": " " + s.reply + "\n"}
+    return res, nil
+}
+
+// callMeetingTool calls meeting_overlap_windows through the server in sess
+func callMeetingTool(t *testing.T, sess server.ClientSession, args string) map[string]interface{} {
+    t.Helper()
+    srv := server.NewMCPServer("test", "1.0", server.WithToolCapabilities(false))
+    registerMeetingTools(srv)
+    ctx := srv.WithContext(context.Background(), sess)
+    msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"meeting_overlap_windows","arguments":` + args + `}}`
+    resp, ok := srv.HandleMessage(ctx, json.RawMessage(msg)).(mcp.JSONRPCResponse)
+    if !ok {
+        t.Fatalf("unexpected response %#v", resp)
+    }
+    res := resp.Result.(mcp.CallToolResult)
+    var body map[string]interface{}
+    if err := json.Unmarshal([]byte(extractText(t, &res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    return body
+}
+
+func TestSamplingSummary(t *testing.T) {
+    sess := &samplingSession{fakeSession: fakeSession{id: "sampling-session"}, reply: "Tuesday 15:00 UTC suits everyone."}
+    sessions.add(sess.id, time.Now())
+    defer sessions.remove(sess.id)
+    sessions.setSampling(sess.id, true)
+
+    const args = `{"timezones":"Europe/London,America/New_York","start_date":"2025-06-02","days":1,"summarize":true}`
+    body := callMeetingTool(t, sess, args)
+    if body["summary"] != "Tuesday 15:00 UTC suits everyone." || body["summary_model"] != "test-model" {
+        t.Errorf("unexpected summary fields: %v / %v", body["summary"], body["summary_model"])
+    }
+    if _, ok := body["windows"]; !ok {
+        t.Error("structured windows must still be returned")
+    }
+    if sess.got == nil || sess.got.MaxTokens != samplingMaxTokens || sess.got.SystemPrompt == "" {
+        t.Fatalf("unexpected sampling request %+v", sess.got)
+    }
+    text := samplingText(sess.got.Messages[0].Content)
+    if !strings.Contains(text, "meeting windows") || !strings.Contains(text, "America/New_York") {
+        t.Errorf("prompt does not carry the result: %q", text)
+    }
+
+    // The client refusing is reported without failing the tool
+    sess.err = errors.New("user rejected sampling request")
+    body = callMeetingTool(t, sess, args)
+    if body["summary"] != nil || !strings.Contains(body["summary_error"].(string), "rejected") {
+        t.Errorf("want summary_error, got %v", body)
+    }
+
+    // Not asked for: no sampling request at all
+    sess.got = nil
+    body = callMeetingTool(t, sess, `{"timezones":"Europe/London","start_date":"2025-06-02","days":1}`)
+    if sess.got != nil || body["summary_error"] != nil {
+        t.Error("summarize=false should not sample")
+    }
+}
+
+func TestSamplingUnsupported(t *testing.T) {
+    // Declared no sampling capability
+    sess := &samplingSession{fakeSession: fakeSession{id: "no-sampling"}}
+    sessions.add(sess.id, time.Now())
+    defer sessions.remove(sess.id)
+    body := callMeetingTool(t, sess, `{"timezones":"Europe/London","start_date":"2025-06-02","days":1,"summarize":true}`)
+    if body["summary_error"] != errSamplingUnsupported.Error() || sess.got != nil {
+        t.Errorf("want unsupported, got %v", body["summary_error"])
+    }
+
+    // Session type cannot carry server-to-client requests
+    plain := fakeSession{id: "plain-session"}
+    sessions.add(plain.id, time.Now())
+    defer sessions.remove(plain.id)
+    sessions.setSampling(plain.id, true)
+    body = callMeetingTool(t, plain, `{"timezones":"Europe/London","start_date":"2025-06-02","days":1,"summarize":true}`)
+    if body["summary_error"] != errSamplingUnsupported.Error() {
+        t.Errorf("want unsupported, got %v", body["summary_error"])
+    }
+}
+
+func TestSamplingText(t *testing.T) {
+    if got := samplingText(mcp.NewTextContent(" hi ")); got != "hi" {
+        t.Errorf("TextContent: %q", got)
+    }
+    if got := samplingText(map[string]interface{}{"type": "image", "data": "..."}); got != "" {
+        t.Errorf("image content should give no text, got %q", got)
+    }
+}
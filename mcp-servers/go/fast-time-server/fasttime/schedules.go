@@ -0,0 +1,624 @@
+// -*- coding: utf-8 -*-
+// schedules.go - webhook scheduler for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file turns the server into a small time-trigger service for agent
+// workflows. Authenticated clients register webhooks that the server POSTs
+// at a local time in a zone, once or on a cron schedule (cron.go):
+//
+//   POST   /api/v1/schedules       {"url": "https://hooks.example.com/run",
+//                                    "at": "2025-03-09T02:00", "timezone": "America/New_York",
+//                                    "payload": {"job": "nightly"}}
+//                                   or {"url": ..., "cron": "0 9 * * MON-FRI", "timezone": ...}
+//   GET    /api/v1/schedules       the caller's schedules
+//   GET    /api/v1/schedules/{id}  one schedule with its last delivery
+//   DELETE /api/v1/schedules/{id}  cancel and remove it
+//
+// Schedules live in the Store, so with -db they survive restarts; a run
+// missed while the server was down fires once when it is back. A delivery
+// that fails (no answer or a non-2xx status) is retried with exponential
+// backoff up to maxDeliveryAttempts times; after that a one-shot schedule
+// is marked failed and a cron schedule moves on to its next run. Completed
+// and failed schedules are removed finishedScheduleTTL after they finish.
+//
+// Deliveries are signed when -webhook-secret is set: X-Fast-Time-Signature
+// is "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Redirects are
+// not followed, and -webhook-allow-hosts limits the hosts that may be
+// called. Loopback, private, link-local and unspecified addresses are
+// refused unless -webhook-allow-private is set, both for IP literals when a
+// schedule is registered and for the address dialled at delivery (see
+// outbound.go), so that API clients cannot reach internal services through
+// the server. The API is only served when authentication is enabled, and a
+// scoped token only sees the schedules it created.
+
+package fasttime
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Schedule states
+const (
+    scheduleActive    = "scheduled"
+    scheduleCompleted = "completed"
+    scheduleFailed    = "failed"
+)
+
+// Scheduler limits and timings
+const (
+    schedulesPath           = "/api/v1/schedules"
+    maxSchedulesPerOwner    = 1000
+    maxScheduleHeaders      = 20
+    maxDeliveryAttempts     = 5
+    deliveryRetryBase       = 30 * time.Second // doubled after each failure
+    deliveryTimeout         = 10 * time.Second
+    schedulerTick           = time.Second
+    schedulePruneInterval   = time.Hour
+    finishedScheduleTTL     = 7 * 24 * time.Hour // completed and failed schedules are kept this long
+    maxConcurrentDeliveries = 8
+)
+
+// reservedWebhookHeaders are set by the scheduler and cannot be overridden
+var reservedWebhookHeaders = map[string]bool{
+    "Content-Type": true, "Content-Length": true, "Host": true, "User-Agent": true,
+    "X-Fast-Time-Schedule": true, "X-Fast-Time-Delivery": true, "X-Fast-Time-Signature": true,
+}
+
+// webhookSchedule is a registered webhook and its delivery state
+type webhookSchedule struct {
+    ID         string            `json:"id"`
+    URL        string            `json:"url"`
+    At         string            `json:"at,omitempty"`   // one-shot time as given
+    Cron       string            `json:"cron,omitempty"` // or a cron expression
+    Timezone   string            `json:"timezone"`
+    Payload    json.RawMessage   `json:"payload,omitempty"`
+    Headers    map[string]string `json:"headers,omitempty"`
+    Status     string            `json:"status"`
+    NextRun    string            `json:"next_run,omitempty"` // run due next, RFC3339 in Timezone
+    RetryAt    string            `json:"retry_at,omitempty"` // set while a failed delivery waits
+    Attempt    int               `json:"attempt,omitempty"`  // failed deliveries of NextRun
+    Runs       int               `json:"runs"`               // successful deliveries
+    LastRun    string            `json:"last_run,omitempty"`
+    LastStatus int               `json:"last_status,omitempty"`
+    LastError  string            `json:"last_error,omitempty"`
+    FinishedAt string            `json:"finished_at,omitempty"` // when it completed or failed
+    Note       string            `json:"note,omitempty"`
+    Owner      string            `json:"owner,omitempty"` // scoped token that created it
+    CreatedAt  string            `json:"created_at"`
+}
+
+// dueAt returns when the schedule should be delivered next
+func (s webhookSchedule) dueAt() (time.Time, bool) {
+    at := s.NextRun
+    if s.RetryAt != "" {
+        at = s.RetryAt
+    }
+    t, err := time.Parse(time.RFC3339, at)
+    return t, err == nil
+}
+
+// finishedAt returns when a completed or failed schedule finished; older
+// records without FinishedAt count from their last run
+func (s webhookSchedule) finishedAt() (time.Time, bool) {
+    at := s.FinishedAt
+    if at == "" {
+        at = s.LastRun
+    }
+    if at == "" {
+        at = s.CreatedAt
+    }
+    t, err := time.Parse(time.RFC3339, at)
+    return t, err == nil
+}
+
+// scheduleRequest is the body of POST /api/v1/schedules
+type scheduleRequest struct {
+    URL      string            `json:"url"`
+    At       string            `json:"at"`
+    Cron     string            `json:"cron"`
+    Timezone string            `json:"timezone"`
+    Payload  json.RawMessage   `json:"payload"`
+    Headers  map[string]string `json:"headers"`
+}
+
+// scheduleTimeLayouts add minute precision to the layouts of parseTimeInLocation
+var scheduleTimeLayouts = []string{"2006-01-02T15:04", "2006-01-02 15:04"}
+
+// parseScheduleTime reads a one-shot time in loc. A local time that the
+// zone skips (a spring-forward gap) moves to the time the clocks jump to,
+// and note says so.
+func parseScheduleTime(value string, loc *time.Location) (t time.Time, note string, err error) {
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return t, "", nil
+    }
+    var wall time.Time
+    for _, layout := range append(inputTimeLayouts, scheduleTimeLayouts...) {
+        if wall, err = time.Parse(layout, value); err == nil {
+            break
+        }
+    }
+    if err != nil {
+        return time.Time{}, "", fmt.Errorf("invalid time %q: use RFC3339 or 2006-01-02T15:04 local to timezone", value)
+    }
+    t = forwardDate(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), loc)
+    if local := t.In(loc); local.Hour() != wall.Hour() || local.Minute() != wall.Minute() {
+        note = fmt.Sprintf("%s does not exist in %s (the clocks skip it); using %s",
+            wall.Format("2006-01-02 15:04"), loc, local.Format(time.RFC3339))
+    }
+    return t, note, nil
+}
+
+// parseHostList splits a -webhook-allow-hosts value
+func parseHostList(list string) []string {
+    var out []string
+    for _, h := range strings.Split(list, ",") {
+        if h = strings.TrimSpace(h); h != "" {
+            out = append(out, h)
+        }
+    }
+    return out
+}
+
+// hostAllowed reports whether host matches one of the -webhook-allow-hosts
+// patterns ("hooks.example.com" or "*.example.com"); no patterns allow all
+func hostAllowed(host string, allow []string) bool {
+    if len(allow) == 0 {
+        return true
+    }
+    host = strings.ToLower(host)
+    for _, p := range allow {
+        p = strings.ToLower(p)
+        if p == host || (strings.HasPrefix(p, "*.") && strings.HasSuffix(host, p[1:])) {
+            return true
+        }
+    }
+    return false
+}
+
+// newScheduleID returns a random schedule ID
+func newScheduleID() string {
+    b := make([]byte, 8)
+    _, _ = rand.Read(b)
+    return "sch_" + hex.EncodeToString(b)
+}
+
+// webhookScheduler delivers due schedules
+type webhookScheduler struct {
+    secret       []byte
+    allow        []string
+    allowPrivate bool // -webhook-allow-private
+
+    mu       sync.Mutex
+    inflight map[string]bool
+    sem      chan struct{}
+    wg       sync.WaitGroup
+}
+
+// scheduler is the running scheduler; nil when authentication is off and
+// the schedule API is not served
+var scheduler *webhookScheduler
+
+// newWebhookScheduler returns a scheduler signing with secret (if any) and
+// calling only hosts matching allow, and internal addresses only when
+// allowPrivate is set
+func newWebhookScheduler(secret string, allow []string, allowPrivate bool) *webhookScheduler {
+    return &webhookScheduler{
+        secret:       []byte(secret),
+        allow:        allow,
+        allowPrivate: allowPrivate,
+        inflight:     make(map[string]bool),
+        sem:          make(chan struct{}, maxConcurrentDeliveries),
+    }
+}
+
+// checkURL reports why raw may not be called as a webhook
+func (ws *webhookScheduler) checkURL(raw string) error {
+    u, err := url.Parse(raw)
+    switch {
+    case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+        return errors.New("url must be an absolute http or https URL")
+    case !hostAllowed(u.Hostname(), ws.allow):
+        return fmt.Errorf("host %s is not in -webhook-allow-hosts", u.Hostname())
+    }
+    // Names are checked when they are dialled, after DNS
+    if ip := net.ParseIP(u.Hostname()); ip != nil && !ws.allowPrivate && !publicIP(ip) {
+        return fmt.Errorf("%w %s (start the server with -webhook-allow-private to allow it)", errPrivateAddress, ip)
+    }
+    return nil
+}
+
+// newSchedule validates req and builds the schedule it asks for
+func (ws *webhookScheduler) newSchedule(req scheduleRequest, owner string, now time.Time) (webhookSchedule, error) {
+    if req.URL == "" {
+        return webhookSchedule{}, errMissingField("url")
+    }
+    if err := ws.checkURL(req.URL); err != nil {
+        return webhookSchedule{}, fieldError("url", err)
+    }
+    switch {
+    case (req.At == "") == (req.Cron == ""):
+        return webhookSchedule{}, fieldError("at", errors.New("give exactly one of at (one-shot) or cron (recurring)"))
+    case len(req.Headers) > maxScheduleHeaders:
+        return webhookSchedule{}, fieldError("headers", fmt.Errorf("at most %d headers", maxScheduleHeaders))
+    }
+    for k := range req.Headers {
+        if reservedWebhookHeaders[http.CanonicalHeaderKey(k)] {
+            return webhookSchedule{}, fieldError("headers", fmt.Errorf("header %s is set by the scheduler", k))
+        }
+    }
+    zone := req.Timezone
+    if zone == "" {
+        zone = "UTC"
+    }
+    loc, err := loadLocation(zone)
+    if err != nil {
+        return webhookSchedule{}, errInvalidTimezone("timezone", zone, err)
+    }
+
+    s := webhookSchedule{
+        ID:        newScheduleID(),
+        URL:       req.URL,
+        At:        req.At,
+        Cron:      strings.TrimSpace(req.Cron),
+        Timezone:  loc.String(),
+        Payload:   req.Payload,
+        Headers:   req.Headers,
+        Status:    scheduleActive,
+        Owner:     owner,
+        CreatedAt: now.UTC().Format(time.RFC3339),
+    }
+    if s.At != "" {
+        t, note, err := parseScheduleTime(s.At, loc)
+        if err != nil {
+            return webhookSchedule{}, fieldError("at", err)
+        }
+        if !t.After(now) {
+            return webhookSchedule{}, fieldError("at", fmt.Errorf("%s is in the past", t.In(loc).Format(time.RFC3339)))
+        }
+        s.NextRun, s.Note = t.In(loc).Format(time.RFC3339), note
+        return s, nil
+    }
+    c, err := parseCron(s.Cron)
+    if err != nil {
+        return webhookSchedule{}, fieldError("cron", err)
+    }
+    next := c.next(now, loc)
+    if next.IsZero() {
+        return webhookSchedule{}, fieldError("cron", fmt.Errorf("%q matches no time in the next %d years", s.Cron, cronSearchYears))
+    }
+    s.NextRun = next.Format(time.RFC3339)
+    return s, nil
+}
+
+// run delivers due schedules and prunes finished ones until ctx is done,
+// then cancels the deliveries still out and waits for them
+func (ws *webhookScheduler) run(ctx context.Context) {
+    ticker := time.NewTicker(schedulerTick)
+    defer ticker.Stop()
+    prune := time.NewTicker(schedulePruneInterval)
+    defer prune.Stop()
+    ws.prune(currentTime())
+    for {
+        select {
+        case <-ctx.Done():
+            ws.wg.Wait()
+            return
+        case <-ticker.C:
+            ws.runDue(ctx, currentTime())
+        case <-prune.C:
+            ws.prune(currentTime())
+        }
+    }
+}
+
+// prune removes the schedules that finished more than finishedScheduleTTL
+// before now
+func (ws *webhookScheduler) prune(now time.Time) {
+    if n, err := store.PruneSchedules(now.Add(-finishedScheduleTTL)); err != nil {
+        logAt(logWarn, "schedules: pruning: %v", err)
+    } else if n > 0 {
+        logAt(logInfo, "schedules: removed %d finished schedule(s) older than %v", n, finishedScheduleTTL)
+    }
+}
+
+// runDue starts a delivery for every schedule due at now
+func (ws *webhookScheduler) runDue(ctx context.Context, now time.Time) {
+    list, err := store.ListDueSchedules(now)
+    if err != nil {
+        logAt(logWarn, "schedules: %v", err)
+        return
+    }
+    for _, s := range list {
+        ws.mu.Lock()
+        busy := ws.inflight[s.ID]
+        ws.inflight[s.ID] = true
+        ws.mu.Unlock()
+        if busy {
+            continue
+        }
+
+        ws.wg.Add(1)
+        go func(s webhookSchedule) {
+            defer ws.wg.Done()
+            ws.sem <- struct{}{}
+            ws.fire(ctx, s, now)
+            <-ws.sem
+            ws.mu.Lock()
+            delete(ws.inflight, s.ID)
+            ws.mu.Unlock()
+        }(s)
+    }
+}
+
+// fire delivers one due schedule and records the outcome
+func (ws *webhookScheduler) fire(ctx context.Context, s webhookSchedule, now time.Time) {
+    status, err := ws.deliver(ctx, s, now)
+    if ctx.Err() != nil {
+        return // shutting down; the run stays due and fires after a restart
+    }
+
+    // The schedule may have been cancelled while the request was out; the
+    // update is atomic so that a DELETE in the meantime is not undone
+    uerr := store.UpdateSchedule(s.ID, func(cur *webhookSchedule) error {
+        recordDelivery(cur, s, now, status, err)
+        return nil
+    })
+    switch {
+    case errors.Is(uerr, errNotFound):
+        logAt(logInfo, "schedules: %s was cancelled during its delivery", s.ID)
+    case uerr != nil:
+        logAt(logError, "schedules: saving %s: %v", s.ID, uerr)
+    }
+}
+
+// recordDelivery updates cur, the stored version of s, with the outcome of
+// the delivery of s at now
+func recordDelivery(cur *webhookSchedule, s webhookSchedule, now time.Time, status int, err error) {
+    cur.LastRun, cur.LastStatus, cur.LastError = now.UTC().Format(time.RFC3339), status, ""
+    switch {
+    case err == nil:
+        cur.Runs++
+        cur.Attempt, cur.RetryAt = 0, ""
+        advanceSchedule(cur, now)
+        logAt(logInfo, "schedules: delivered %s to %s (%d)", cur.ID, cur.URL, status)
+    case cur.Attempt+1 < maxDeliveryAttempts:
+        cur.LastError = err.Error()
+        cur.Attempt++
+        cur.RetryAt = now.Add(deliveryRetryBase << (cur.Attempt - 1)).UTC().Format(time.RFC3339)
+        logAt(logWarn, "schedules: delivery of %s failed (attempt %d): %v; retrying at %s", cur.ID, cur.Attempt, err, cur.RetryAt)
+    default:
+        cur.LastError = err.Error()
+        cur.Attempt, cur.RetryAt = 0, ""
+        if cur.Cron == "" {
+            finishSchedule(cur, scheduleFailed, now)
+        } else {
+            advanceSchedule(cur, now)
+        }
+        logAt(logWarn, "schedules: giving up on run %s of %s after %d attempts: %v", s.NextRun, cur.ID, maxDeliveryAttempts, err)
+    }
+}
+
+// advanceSchedule moves s to its next run after now, or completes it. Runs
+// missed while the server was down are skipped.
+func advanceSchedule(s *webhookSchedule, now time.Time) {
+    if s.Cron == "" {
+        finishSchedule(s, scheduleCompleted, now)
+        return
+    }
+    c, err := parseCron(s.Cron)
+    loc, lerr := loadLocation(s.Timezone)
+    if err != nil || lerr != nil {
+        finishSchedule(s, scheduleFailed, now)
+        return
+    }
+    next := c.next(now, loc)
+    if next.IsZero() {
+        finishSchedule(s, scheduleCompleted, now)
+        return
+    }
+    s.NextRun = next.Format(time.RFC3339)
+}
+
+// finishSchedule ends s with status (completed or failed) at now
+func finishSchedule(s *webhookSchedule, status string, now time.Time) {
+    s.Status, s.NextRun, s.FinishedAt = status, "", now.UTC().Format(time.RFC3339)
+}
+
+// webhookDelivery is the body POSTed to the webhook
+type webhookDelivery struct {
+    ScheduleID   string          `json:"schedule_id"`
+    ScheduledFor string          `json:"scheduled_for"`
+    FiredAt      string          `json:"fired_at"`
+    Timezone     string          `json:"timezone"`
+    Attempt      int             `json:"attempt"`
+    Payload      json.RawMessage `json:"payload,omitempty"`
+}
+
+// sign returns the X-Fast-Time-Signature value for body sent at ts
+func (ws *webhookScheduler) sign(body []byte, ts int64) string {
+    mac := hmac.New(sha256.New, ws.secret)
+    fmt.Fprintf(mac, "%d.", ts)
+    mac.Write(body)
+    return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// deliver POSTs s to its URL and returns the HTTP status
+func (ws *webhookScheduler) deliver(ctx context.Context, s webhookSchedule, now time.Time) (int, error) {
+    if err := ws.checkURL(s.URL); err != nil {
+        return 0, err
+    }
+    body, err := json.Marshal(webhookDelivery{
+        ScheduleID:   s.ID,
+        ScheduledFor: s.NextRun,
+        FiredAt:      now.UTC().Format(time.RFC3339),
+        Timezone:     s.Timezone,
+        Attempt:      s.Attempt + 1,
+        Payload:      s.Payload,
+    })
+    if err != nil {
+        return 0, err
+    }
+
+    header := http.Header{}
+    for k, v := range s.Headers {
+        header.Set(k, v)
+    }
+    header.Set("Content-Type", "application/json")
+    header.Set("User-Agent", appName+"/"+appVersion)
+    header.Set("X-Fast-Time-Schedule", s.ID)
+    header.Set("X-Fast-Time-Delivery", fmt.Sprintf("%s-%d-%d", s.ID, s.Runs+1, s.Attempt+1))
+    if len(ws.secret) > 0 {
+        // Signed with the real time so receivers can reject stale replays
+        header.Set("X-Fast-Time-Signature", ws.sign(body, time.Now().Unix()))
+    }
+
+    // Failed deliveries are retried on the schedule's own timetable, and a
+    // redirect could lead past -webhook-allow-hosts
+    resp, err := outbound.do(ctx, outboundRequest{
+        feature: "webhooks", method: http.MethodPost, url: s.URL, header: header, body: body,
+        timeout: deliveryTimeout, noRetry: true, noRedirect: true, publicOnly: !ws.allowPrivate,
+    })
+    if err != nil {
+        return 0, err
+    }
+    if resp.status < 200 || resp.status > 299 {
+        return resp.status, fmt.Errorf("webhook answered %d %s", resp.status, http.StatusText(resp.status))
+    }
+    return resp.status, nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                              REST API                              */
+/* ------------------------------------------------------------------ */
+
+// scheduleOwner returns the owner recorded for schedules created by r: the
+// scoped token's name, or "" for the -auth-token token, which sees all
+func scheduleOwner(r *http.Request) (string, bool) {
+    if c := callerFrom(r.Context()); c != nil {
+        return c.Name, false
+    }
+    return "", true
+}
+
+// visibleSchedule loads schedule id if r may see it
+func visibleSchedule(r *http.Request, id string) (webhookSchedule, error) {
+    s, err := store.GetSchedule(id)
+    if err != nil {
+        return s, err
+    }
+    if owner, all := scheduleOwner(r); !all && s.Owner != owner {
+        return webhookSchedule{}, errNotFound
+    }
+    return s, nil
+}
+
+// handleRESTSchedules handles GET and POST /api/v1/schedules
+func handleRESTSchedules(w http.ResponseWriter, r *http.Request) {
+    if scheduler == nil {
+        writeJSONError(w, http.StatusForbidden, "Schedules need authentication: start the server with -auth-token or -auth-tokens-file")
+        return
+    }
+    owner, all := scheduleOwner(r)
+
+    switch r.Method {
+    case http.MethodGet:
+        list, err := store.ListSchedules()
+        if err != nil {
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+            return
+        }
+        status := r.URL.Query().Get("status")
+        out := []webhookSchedule{}
+        for _, s := range list {
+            if (all || s.Owner == owner) && (status == "" || s.Status == status) {
+                out = append(out, s)
+            }
+        }
+        writeJSON(w, http.StatusOK, map[string]interface{}{"schedules": out, "count": len(out)})
+
+    case http.MethodPost:
+        var req scheduleRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Invalid request body")
+            return
+        }
+        s, err := scheduler.newSchedule(req, owner, currentTime())
+        if err != nil {
+            writeAPIError(w, http.StatusBadRequest, err)
+            return
+        }
+        switch err := store.CreateSchedule(s, maxSchedulesPerOwner); {
+        case errors.Is(err, errLimitReached):
+            writeJSONError(w, http.StatusConflict, fmt.Sprintf("At most %d active schedules per token", maxSchedulesPerOwner))
+            return
+        case err != nil:
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+            return
+        }
+        logAt(logInfo, "schedules: %s registered for %s, next run %s", s.ID, s.URL, s.NextRun)
+        w.Header().Set("Location", strings.TrimSuffix(requestPath(r), "/")+"/"+s.ID)
+        writeJSON(w, http.StatusCreated, s)
+
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
+
+// handleRESTSchedule handles GET and DELETE /api/v1/schedules/{id}
+func handleRESTSchedule(w http.ResponseWriter, r *http.Request) {
+    if scheduler == nil {
+        writeJSONError(w, http.StatusForbidden, "Schedules need authentication: start the server with -auth-token or -auth-tokens-file")
+        return
+    }
+    id := strings.TrimPrefix(r.URL.Path, schedulesPath+"/")
+    if id == "" || strings.Contains(id, "/") {
+        writeJSONError(w, http.StatusNotFound, "Schedule not specified")
+        return
+    }
+
+    switch r.Method {
+    case http.MethodGet:
+        s, err := visibleSchedule(r, id)
+        switch {
+        case errors.Is(err, errNotFound):
+            writeJSONError(w, http.StatusNotFound, "Unknown schedule: "+id)
+        case err != nil:
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+        default:
+            writeJSON(w, http.StatusOK, s)
+        }
+
+    case http.MethodDelete:
+        _, err := visibleSchedule(r, id)
+        if err == nil {
+            err = store.DeleteSchedule(id)
+        }
+        switch {
+        case errors.Is(err, errNotFound):
+            writeJSONError(w, http.StatusNotFound, "Unknown schedule: "+id)
+        case err != nil:
+            writeJSONError(w, http.StatusInternalServerError, err.Error())
+        default:
+            logAt(logInfo, "schedules: %s cancelled", id)
+            w.WriteHeader(http.StatusNoContent)
+        }
+
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
@@ -0,0 +1,380 @@
+// -*- coding: utf-8 -*-
+// schedules_test.go - Tests for the webhook scheduler
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// useTestScheduler installs a scheduler and an empty store for one test
+func useTestScheduler(t *testing.T, secret string) *webhookScheduler {
+    t.Helper()
+    useTestStore(t, newMemoryStore())
+    old := scheduler
+    scheduler = newWebhookScheduler(secret, nil, true) // the test hooks listen on 127.0.0.1
+    t.Cleanup(func() { scheduler = old })
+    return scheduler
+}
+
+func TestParseScheduleTime(t *testing.T) {
+    ny, _ := time.LoadLocation("America/New_York")
+    tests := []struct {
+        in, want string
+        note     bool
+    }{
+        {"2025-03-10T09:30", "2025-03-10T09:30:00-04:00", false},
+        {"2025-03-10 09:30:15", "2025-03-10T09:30:15-04:00", false},
+        {"2025-03-10T09:30:00Z", "2025-03-10T05:30:00-04:00", false},
+        {"2025-03-09T02:00", "2025-03-09T03:00:00-04:00", true}, // skipped by DST
+    }
+    for _, tt := range tests {
+        got, note, err := parseScheduleTime(tt.in, ny)
+        if err != nil || got.In(ny).Format(time.RFC3339) != tt.want || (note != "") != tt.note {
+            t.Errorf("parseScheduleTime(%q) = %s, %q, %v; want %s", tt.in, got.In(ny).Format(time.RFC3339), note, err, tt.want)
+        }
+    }
+    if _, _, err := parseScheduleTime("tomorrow", ny); err == nil {
+        t.Error("parseScheduleTime(tomorrow) should fail")
+    }
+}
+
+func TestHostAllowed(t *testing.T) {
+    allow := parseHostList("hooks.example.com, *.internal.test")
+    for host, want := range map[string]bool{
+        "hooks.example.com": true,
+        "HOOKS.example.com": true,
+        "a.internal.test":   true,
+        "internal.test":     false,
+        "evil.com":          false,
+    } {
+        if got := hostAllowed(host, allow); got != want {
+            t.Errorf("hostAllowed(%q) = %v, want %v", host, got, want)
+        }
+    }
+    if !hostAllowed("anything", nil) {
+        t.Error("an empty allow list should allow any host")
+    }
+}
+
+func TestNewScheduleValidation(t *testing.T) {
+    ws := newWebhookScheduler("", []string{"example.com"}, false)
+    now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+    bad := []scheduleRequest{
+        {At: "2025-03-02T10:00"},
+        {URL: "ftp://example.com/x", At: "2025-03-02T10:00"},
+        {URL: "https://other.com/x", At: "2025-03-02T10:00"},
+        {URL: "https://example.com/x"},
+        {URL: "https://example.com/x", At: "2025-03-02T10:00", Cron: "@daily"},
+        {URL: "https://example.com/x", At: "2025-02-01T10:00"},
+        {URL: "https://example.com/x", Cron: "0 0 30 2 *"},
+        {URL: "https://example.com/x", At: "2025-03-02T10:00", Timezone: "Mars/Olympus"},
+        {URL: "https://example.com/x", At: "2025-03-02T10:00", Headers: map[string]string{"x-fast-time-signature": "forged"}},
+    }
+    for _, req := range bad {
+        if _, err := ws.newSchedule(req, "", now); err == nil {
+            t.Errorf("newSchedule(%+v) should fail", req)
+        }
+    }
+
+    s, err := ws.newSchedule(scheduleRequest{URL: "https://example.com/x", At: "2025-03-09T02:00", Timezone: "America/New_York"}, "ci", now)
+    if err != nil || s.NextRun != "2025-03-09T03:00:00-04:00" || s.Note == "" || s.Owner != "ci" || s.Status != scheduleActive {
+        t.Errorf("one-shot in a DST gap = %+v, %v", s, err)
+    }
+    s, err = ws.newSchedule(scheduleRequest{URL: "https://example.com/x", Cron: "0 9 * * MON", Timezone: "Europe/Berlin"}, "", now)
+    if err != nil || s.NextRun != "2025-03-03T09:00:00+01:00" {
+        t.Errorf("cron schedule = %+v, %v", s, err)
+    }
+}
+
+// hookRecorder is a webhook receiver answering with the next status in codes
+type hookRecorder struct {
+    mu     sync.Mutex
+    codes  []int
+    bodies []webhookDelivery
+    sigs   []string
+    raw    [][]byte
+}
+
+func (h *hookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    body, _ := io.ReadAll(r.Body)
+    var d webhookDelivery
+    _ = json.Unmarshal(body, &d)
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.bodies = append(h.bodies, d)
+    h.sigs = append(h.sigs, r.Header.Get("X-Fast-Time-Signature"))
+    h.raw = append(h.raw, body)
+    code := http.StatusOK
+    if len(h.codes) > 0 {
+        code, h.codes = h.codes[0], h.codes[1:]
+    }
+    w.WriteHeader(code)
+}
+
+func TestWebhookPrivateAddresses(t *testing.T) {
+    useTestStore(t, newMemoryStore())
+    var hits atomic.Int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits.Add(1) }))
+    defer srv.Close()
+    _, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+    now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+    // IP literals are refused when the schedule is registered
+    ws := newWebhookScheduler("", nil, false)
+    for _, u := range []string{"http://127.0.0.1/", "http://10.1.2.3/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]:8080/", "http://0.0.0.0/"} {
+        _, err := ws.newSchedule(scheduleRequest{URL: u, At: "2025-03-02T10:00"}, "", now)
+        if err == nil || !strings.Contains(err.Error(), errPrivateAddress.Error()) {
+            t.Errorf("%s: want a non-public address error, got %v", u, err)
+        }
+    }
+    if _, err := ws.newSchedule(scheduleRequest{URL: "https://93.184.216.34/hook", At: "2025-03-02T10:00"}, "", now); err != nil {
+        t.Errorf("public address refused: %v", err)
+    }
+
+    // A name that resolves to loopback passes registration but not the dial
+    s, err := ws.newSchedule(scheduleRequest{URL: "http://localhost:" + port + "/", At: "2025-03-02T10:00"}, "", now)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := ws.deliver(context.Background(), s, now); !errors.Is(err, errPrivateAddress) {
+        t.Errorf("delivery to localhost: want errPrivateAddress, got %v", err)
+    }
+    if hits.Load() != 0 {
+        t.Error("the loopback hook was called")
+    }
+
+    // -webhook-allow-private lets both through
+    ws = newWebhookScheduler("", nil, true)
+    for _, u := range []string{srv.URL + "/", "http://localhost:" + port + "/"} {
+        s, err := ws.newSchedule(scheduleRequest{URL: u, At: "2025-03-02T10:00"}, "", now)
+        if err != nil {
+            t.Fatalf("%s: %v", u, err)
+        }
+        if _, err := ws.deliver(context.Background(), s, now); err != nil {
+            t.Errorf("%s with -webhook-allow-private: %v", u, err)
+        }
+    }
+    if hits.Load() != 2 {
+        t.Errorf("want 2 deliveries, got %d", hits.Load())
+    }
+}
+
+func TestSchedulerDelivery(t *testing.T) {
+    ws := useTestScheduler(t, "s3cret")
+    hook := &hookRecorder{codes: []int{http.StatusInternalServerError}}
+    srv := httptest.NewServer(hook)
+    defer srv.Close()
+
+    now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+    oneShot, _ := ws.newSchedule(scheduleRequest{URL: srv.URL, At: "2025-03-01T09:00", Payload: json.RawMessage(`{"job":"nightly"}`)}, "", now)
+    daily, _ := ws.newSchedule(scheduleRequest{URL: srv.URL + "/daily", Cron: "0 9 * * *"}, "", now)
+    _ = store.PutSchedule(oneShot)
+    _ = store.PutSchedule(daily)
+
+    step := func(at time.Time) {
+        ws.runDue(context.Background(), at)
+        ws.wg.Wait()
+    }
+
+    // Nothing is due yet
+    step(now)
+    if len(hook.bodies) != 0 {
+        t.Fatalf("deliveries before due time: %d", len(hook.bodies))
+    }
+
+    // Both fire; the first answer is a 500, so one of them is retried
+    due := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+    step(due)
+    if len(hook.bodies) != 2 {
+        t.Fatalf("deliveries at due time = %d, want 2", len(hook.bodies))
+    }
+    for i, sig := range hook.sigs {
+        if !strings.HasPrefix(sig, "t=") {
+            t.Fatalf("signature = %q", sig)
+        }
+        ts := strings.TrimPrefix(strings.SplitN(sig, ",", 2)[0], "t=")
+        var unix int64
+        _ = json.Unmarshal([]byte(ts), &unix)
+        if want := ws.sign(hook.raw[i], unix); sig != want {
+            t.Errorf("signature = %q, want %q", sig, want)
+        }
+    }
+
+    var failed webhookSchedule
+    for _, id := range []string{oneShot.ID, daily.ID} {
+        if s, _ := store.GetSchedule(id); s.Attempt == 1 {
+            failed = s
+        }
+    }
+    if failed.ID == "" || failed.LastStatus != http.StatusInternalServerError || failed.RetryAt != "2025-03-01T09:00:30Z" {
+        t.Fatalf("failed delivery not scheduled for retry: %+v", failed)
+    }
+
+    // The retry succeeds
+    step(due.Add(deliveryRetryBase))
+    if len(hook.bodies) != 3 || hook.bodies[2].Attempt != 2 || hook.bodies[2].ScheduleID != failed.ID {
+        t.Fatalf("retry delivery = %+v", hook.bodies[len(hook.bodies)-1])
+    }
+
+    got, _ := store.GetSchedule(oneShot.ID)
+    if got.Status != scheduleCompleted || got.Runs != 1 || got.NextRun != "" {
+        t.Errorf("one-shot after delivery = %+v", got)
+    }
+    got, _ = store.GetSchedule(daily.ID)
+    if got.Status != scheduleActive || got.Runs != 1 || got.NextRun != "2025-03-02T09:00:00Z" || got.RetryAt != "" {
+        t.Errorf("cron after delivery = %+v", got)
+    }
+    for _, d := range hook.bodies {
+        if d.ScheduleID == oneShot.ID && string(d.Payload) != `{"job":"nightly"}` {
+            t.Errorf("payload = %s", d.Payload)
+        }
+    }
+}
+
+func TestSchedulerGivesUp(t *testing.T) {
+    ws := useTestScheduler(t, "")
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("X-Fast-Time-Signature") != "" {
+            t.Error("unsigned scheduler sent a signature")
+        }
+        w.WriteHeader(http.StatusBadGateway)
+    }))
+    defer srv.Close()
+
+    now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+    s, _ := ws.newSchedule(scheduleRequest{URL: srv.URL, At: "2025-03-01T08:01"}, "", now)
+    _ = store.PutSchedule(s)
+    for at := now.Add(time.Minute); ; at = at.Add(time.Hour) {
+        ws.runDue(context.Background(), at)
+        ws.wg.Wait()
+        if got, _ := store.GetSchedule(s.ID); got.Status != scheduleActive {
+            if got.Status != scheduleFailed || got.LastStatus != http.StatusBadGateway || got.LastError == "" {
+                t.Errorf("after %d attempts = %+v", maxDeliveryAttempts, got)
+            }
+            break
+        }
+        if at.After(now.Add(24 * time.Hour)) {
+            t.Fatal("schedule never gave up")
+        }
+    }
+}
+
+func TestScheduleDeletedDuringDelivery(t *testing.T) {
+    ws := useTestScheduler(t, "")
+    arrived, release := make(chan struct{}), make(chan struct{})
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        close(arrived)
+        <-release // a slow receiver
+    }))
+    defer srv.Close()
+
+    now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+    s, _ := ws.newSchedule(scheduleRequest{URL: srv.URL, Cron: "*/5 * * * *"}, "", now)
+    _ = store.PutSchedule(s)
+    due, _ := s.dueAt()
+    ws.runDue(context.Background(), due)
+
+    // Cancel the schedule while its delivery is out
+    <-arrived
+    rec := httptest.NewRecorder()
+    handleRESTSchedule(rec, httptest.NewRequest(http.MethodDelete, schedulesPath+"/"+s.ID, nil))
+    if rec.Code != http.StatusNoContent {
+        t.Fatalf("DELETE = %d %s", rec.Code, rec.Body)
+    }
+    close(release)
+    ws.wg.Wait()
+
+    if got, err := store.GetSchedule(s.ID); !errors.Is(err, errNotFound) {
+        t.Fatalf("the delivery brought the cancelled schedule back: %+v", got)
+    }
+    ws.runDue(context.Background(), due.Add(time.Hour))
+    ws.wg.Wait()
+}
+
+func TestRESTSchedules(t *testing.T) {
+    useTestScheduler(t, "")
+    mux := http.NewServeMux()
+    mux.HandleFunc(schedulesPath, handleRESTSchedules)
+    mux.HandleFunc(schedulesPath+"/", handleRESTSchedule)
+
+    do := func(method, path, body string, caller *callerToken) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(method, path, strings.NewReader(body))
+        if caller != nil {
+            req = req.WithContext(withCaller(req.Context(), caller))
+        }
+        w := httptest.NewRecorder()
+        mux.ServeHTTP(w, req)
+        return w
+    }
+    ci, ops := &callerToken{Name: "ci"}, &callerToken{Name: "ops"}
+
+    future := time.Now().Add(48 * time.Hour).UTC().Format("2006-01-02T15:04")
+    w := do(http.MethodPost, schedulesPath, `{"url":"https://example.com/hook","at":"`+future+`"}`, ci)
+    if w.Code != http.StatusCreated {
+        t.Fatalf("create = %d %s", w.Code, w.Body)
+    }
+    var created webhookSchedule
+    _ = json.Unmarshal(w.Body.Bytes(), &created)
+    if loc := w.Header().Get("Location"); loc != schedulesPath+"/"+created.ID {
+        t.Errorf("Location = %q", loc)
+    }
+    if w := do(http.MethodPost, schedulesPath, `{"url":"https://example.com/hook"}`, ci); w.Code != http.StatusBadRequest {
+        t.Errorf("create without a time = %d", w.Code)
+    }
+
+    list := func(caller *callerToken, query string) int {
+        var out struct{ Count int }
+        _ = json.Unmarshal(do(http.MethodGet, schedulesPath+query, "", caller).Body.Bytes(), &out)
+        return out.Count
+    }
+    if n := list(ci, ""); n != 1 {
+        t.Errorf("ci sees %d schedules, want 1", n)
+    }
+    if n := list(ops, ""); n != 0 {
+        t.Errorf("ops sees %d schedules, want 0", n)
+    }
+    if n := list(nil, "?status=completed"); n != 0 {
+        t.Errorf("completed schedules = %d, want 0", n)
+    }
+    if n := list(nil, ""); n != 1 {
+        t.Errorf("main token sees %d schedules, want 1", n)
+    }
+
+    path := schedulesPath + "/" + created.ID
+    if w := do(http.MethodGet, path, "", ops); w.Code != http.StatusNotFound {
+        t.Errorf("ops reads ci's schedule: %d", w.Code)
+    }
+    if w := do(http.MethodDelete, path, "", ops); w.Code != http.StatusNotFound {
+        t.Errorf("ops cancels ci's schedule: %d", w.Code)
+    }
+    if w := do(http.MethodGet, path, "", ci); w.Code != http.StatusOK {
+        t.Errorf("get = %d", w.Code)
+    }
+    if w := do(http.MethodDelete, path, "", ci); w.Code != http.StatusNoContent {
+        t.Errorf("cancel = %d", w.Code)
+    }
+    if w := do(http.MethodGet, path, "", ci); w.Code != http.StatusNotFound {
+        t.Errorf("get after cancel = %d", w.Code)
+    }
+
+    // Without authentication the API is not served
+    scheduler = nil
+    if w := do(http.MethodGet, schedulesPath, "", nil); w.Code != http.StatusForbidden {
+        t.Errorf("without a scheduler = %d, want 403", w.Code)
+    }
+}
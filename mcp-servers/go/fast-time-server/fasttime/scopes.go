@@ -0,0 +1,189 @@
+// -*- coding: utf-8 -*-
+// scopes.go - named client tokens limited to some tools and endpoints
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file adds scoped client tokens next to the single -auth-token. The
+// JSON file given with -auth-tokens-file names each token and the features
+// it may use:
+//
+//   {"tokens": [
+//     {"name": "dashboard", "token": "...", "scopes": ["*"]},
+//     {"name": "ci", "token": "...", "scopes": ["get_system_time", "resource:*", "rest:/api/v1/time*"]}
+//   ]}
+//
+// Scopes use the -enable-tools syntax (plain tool names, "prompt:" and
+// "resource:" entries, "*" wildcards) plus "rest:" path patterns for the REST
+// API; a lone "*" grants everything. Whatever a token is not granted is left
+// out of list results and refused with "forbidden" before the handler runs.
+// The -auth-token token and stdio sessions keep full access, and
+// POST /admin/tokens/reload re-reads the file.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "regexp"
+    "strings"
+    "sync"
+)
+
+// scopeREST is the kind of REST API path scopes
+const scopeREST = "rest"
+
+// callerToken is a named client token and what it may use
+type callerToken struct {
+    Name   string   `json:"name"`
+    Token  string   `json:"token"`
+    Scopes []string `json:"scopes"`
+
+    all     bool                        // granted "*"
+    allowed map[string][]featurePattern // by kind
+}
+
+// tokenRegistry holds the scoped tokens keyed by token value
+type tokenRegistry struct {
+    mu      sync.RWMutex
+    byToken map[string]*callerToken
+    file    string
+}
+
+// callers is the process-wide registry (loaded in main)
+var callers = &tokenRegistry{}
+
+// callerKey is the context key for the authenticated callerToken
+type callerKey struct{}
+
+// withCaller returns ctx carrying the caller's token
+func withCaller(ctx context.Context, c *callerToken) context.Context {
+    return context.WithValue(ctx, callerKey{}, c)
+}
+
+// callerFrom returns the scoped token a request was made with, or nil when
+// it used the -auth-token token or no token at all
+func callerFrom(ctx context.Context) *callerToken {
+    c, _ := ctx.Value(callerKey{}).(*callerToken)
+    return c
+}
+
+// parseScopes compiles a token's scope list
+func parseScopes(scopes []string) (map[string][]featurePattern, bool, error) {
+    out := make(map[string][]featurePattern)
+    for _, s := range scopes {
+        s = strings.TrimSpace(s)
+        if s == "*" {
+            return nil, true, nil
+        }
+        if path, ok := strings.CutPrefix(s, scopeREST+":"); ok {
+            if !strings.HasPrefix(path, "/") {
+                return nil, false, fmt.Errorf("REST scope %q must start with /", s)
+            }
+            expr := strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, `.*`)
+            out[scopeREST] = append(out[scopeREST], featurePattern{text: s, re: regexp.MustCompile("^" + expr + "$")})
+            continue
+        }
+        parsed, err := parseFeatureList(s)
+        if err != nil {
+            return nil, false, err
+        }
+        for kind, patterns := range parsed {
+            out[kind] = append(out[kind], patterns...)
+        }
+    }
+    return out, false, nil
+}
+
+// allows reports whether the token may use the feature or REST path
+func (c *callerToken) allows(kind, name string) bool {
+    return c.all || matchAny(c.allowed[kind], name)
+}
+
+// loadTokenRegistry reads the scoped tokens from path
+func loadTokenRegistry(path string) (*tokenRegistry, error) {
+    r := &tokenRegistry{file: path}
+    if _, err := r.reload(); err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// reload re-reads the token file and returns how many tokens it holds; on
+// error the tokens in use are kept
+func (r *tokenRegistry) reload() (int, error) {
+    if r.file == "" {
+        return 0, fmt.Errorf("tokens are not read from a file")
+    }
+    data, err := os.ReadFile(r.file)
+    if err != nil {
+        return 0, fmt.Errorf("read tokens file: %w", err)
+    }
+    var doc struct {
+        Tokens []*callerToken `json:"tokens"`
+    }
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return 0, fmt.Errorf("tokens file %s: %w", r.file, err)
+    }
+
+    byToken := make(map[string]*callerToken, len(doc.Tokens))
+    names := make(map[string]bool, len(doc.Tokens))
+    for i, c := range doc.Tokens {
+        switch {
+        case c == nil || c.Name == "":
+            return 0, fmt.Errorf("tokens file %s: token %d has no name", r.file, i+1)
+        case strings.TrimSpace(c.Token) == "":
+            return 0, fmt.Errorf("tokens file %s: token %q is empty", r.file, c.Name)
+        case names[c.Name]:
+            return 0, fmt.Errorf("tokens file %s: duplicate name %q", r.file, c.Name)
+        case byToken[c.Token] != nil:
+            return 0, fmt.Errorf("tokens file %s: %q reuses the token of %q", r.file, c.Name, byToken[c.Token].Name)
+        }
+        if c.allowed, c.all, err = parseScopes(c.Scopes); err != nil {
+            return 0, fmt.Errorf("tokens file %s: token %q: %w", r.file, c.Name, err)
+        }
+        names[c.Name] = true
+        byToken[c.Token] = c
+    }
+
+    r.mu.Lock()
+    r.byToken = byToken
+    r.mu.Unlock()
+    return len(byToken), nil
+}
+
+// lookup returns the scoped token with the given value, or nil
+func (r *tokenRegistry) lookup(token string) *callerToken {
+    if r == nil || token == "" {
+        return nil
+    }
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.byToken[token]
+}
+
+// count returns the number of scoped tokens
+func (r *tokenRegistry) count() int {
+    if r == nil {
+        return 0
+    }
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return len(r.byToken)
+}
+
+// enabled reports whether scoped tokens are configured
+func (r *tokenRegistry) enabled() bool {
+    return r != nil && r.file != ""
+}
+
+// checkCallerScope refuses features the request's token was not granted
+func checkCallerScope(ctx context.Context, kind, name string) error {
+    c := callerFrom(ctx)
+    if c == nil || c.allows(kind, name) {
+        return nil
+    }
+    return fmt.Errorf("forbidden: token %q may not use %s '%s'", c.Name, kind, name)
+}
@@ -0,0 +1,182 @@
+// -*- coding: utf-8 -*-
+// scopes_test.go - Tests for scoped client tokens
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// writeTokensFile writes a scoped tokens file and loads it
+func writeTokensFile(t *testing.T, content string) (*tokenRegistry, string) {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "tokens.json")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    r, err := loadTokenRegistry(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return r, path
+}
+
+const testTokensFile = `{"tokens": [
+  {"name": "admin", "token": "all-secret", "scopes": ["*"]},
+  {"name": "ci", "token": "ci-secret", "scopes": ["get_system_time", "prompt:compare_*", "resource:timezone://*", "rest:/api/v1/time*"]}
+]}`
+
+func TestCallerTokenAllows(t *testing.T) {
+    r, _ := writeTokensFile(t, testTokensFile)
+    ci := r.lookup("ci-secret")
+    if ci == nil || ci.Name != "ci" {
+        t.Fatalf("lookup(ci-secret) = %+v", ci)
+    }
+    tests := []struct {
+        kind, name string
+        want       bool
+    }{
+        {featureTool, "get_system_time", true},
+        {featureTool, "convert_time", false},
+        {featurePrompt, "compare_timezones", true},
+        {featurePrompt, "schedule_meeting", false},
+        {featureResource, "timezone://info", true},
+        {featureResource, "markets://exchanges", false},
+        {scopeREST, "/api/v1/time/UTC", true},
+        {scopeREST, "/api/v1/convert/batch", false},
+    }
+    for _, tt := range tests {
+        if got := ci.allows(tt.kind, tt.name); got != tt.want {
+            t.Errorf("allows(%s, %s) = %v, want %v", tt.kind, tt.name, got, tt.want)
+        }
+    }
+    if all := r.lookup("all-secret"); all == nil || !all.allows(scopeREST, "/api/v1/convert/batch") {
+        t.Errorf("'*' token should allow everything: %+v", all)
+    }
+    if r.lookup("nope") != nil || r.lookup("") != nil {
+        t.Error("unknown tokens should not match")
+    }
+}
+
+func TestTokenRegistryErrors(t *testing.T) {
+    for _, content := range []string{
+        `{"tokens": [{"token": "x"}]}`,
+        `{"tokens": [{"name": "a", "token": ""}]}`,
+        `{"tokens": [{"name": "a", "token": "x"}, {"name": "a", "token": "y"}]}`,
+        `{"tokens": [{"name": "a", "token": "x"}, {"name": "b", "token": "x"}]}`,
+        `{"tokens": [{"name": "a", "token": "x", "scopes": ["rest:api"]}]}`,
+        `not json`,
+    } {
+        path := filepath.Join(t.TempDir(), "tokens.json")
+        _ = os.WriteFile(path, []byte(content), 0o600)
+        if _, err := loadTokenRegistry(path); err == nil {
+            t.Errorf("expected an error for %s", content)
+        }
+    }
+}
+
+func TestTokenRegistryReloadKeepsTokensOnError(t *testing.T) {
+    r, path := writeTokensFile(t, testTokensFile)
+    _ = os.WriteFile(path, []byte(`{"tokens": [{"name": "ci"}]}`), 0o600)
+    if _, err := r.reload(); err == nil {
+        t.Fatal("expected reload error")
+    }
+    if r.lookup("ci-secret") == nil {
+        t.Error("tokens should be kept after a failed reload")
+    }
+}
+
+func TestAuthMiddlewareScopedTokens(t *testing.T) {
+    r, _ := writeTokensFile(t, testTokensFile)
+    defer func(prev *tokenRegistry) { callers = prev }(callers)
+    callers = r
+
+    var seen *callerToken
+    handler := authMiddleware(newBearerToken("main-secret"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        seen = callerFrom(r.Context())
+        w.WriteHeader(http.StatusOK)
+    }))
+
+    tests := []struct {
+        token, path string
+        want        int
+        caller      string
+    }{
+        {"main-secret", "/api/v1/convert/batch", http.StatusOK, ""},
+        {"ci-secret", "/api/v1/time", http.StatusOK, "ci"},
+        {"ci-secret", "/api/v1/convert/batch", http.StatusForbidden, ""},
+        {"ci-secret", "/messages", http.StatusOK, "ci"},
+        {"all-secret", "/api/v1/convert/batch", http.StatusOK, "admin"},
+        {"wrong", "/api/v1/time", http.StatusUnauthorized, ""},
+        {"", "/api/v1/time", http.StatusUnauthorized, ""},
+    }
+    for _, tt := range tests {
+        seen = nil
+        req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+        req.Header.Set("Authorization", "Bearer "+tt.token)
+        rec := httptest.NewRecorder()
+        handler.ServeHTTP(rec, req)
+        if rec.Code != tt.want {
+            t.Errorf("%s %s: status %d, want %d", tt.token, tt.path, rec.Code, tt.want)
+        }
+        name := ""
+        if seen != nil {
+            name = seen.Name
+        }
+        if name != tt.caller {
+            t.Errorf("%s %s: caller %q, want %q", tt.token, tt.path, name, tt.caller)
+        }
+    }
+}
+
+func TestCallerScopeHooks(t *testing.T) {
+    r, _ := writeTokensFile(t, testTokensFile)
+    hooks := &server.Hooks{}
+    installFeatureCheck(hooks, checkCallerScope)
+    srv := server.NewMCPServer("test", "1.0",
+        server.WithToolCapabilities(false),
+        server.WithHooks(hooks),
+    )
+    srv.AddTool(mcp.NewTool("get_system_time"), handleGetSystemTime)
+    srv.AddTool(mcp.NewTool("convert_time"), handleConvertTime)
+
+    ci := withCaller(context.Background(), r.lookup("ci-secret"))
+    resp := srv.HandleMessage(ci, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+    res, ok := resp.(mcp.JSONRPCResponse)
+    if !ok {
+        t.Fatalf("tools/list: %#v", resp)
+    }
+    data, _ := json.Marshal(res.Result)
+    var tools mcp.ListToolsResult
+    _ = json.Unmarshal(data, &tools)
+    if len(tools.Tools) != 1 || tools.Tools[0].Name != "get_system_time" {
+        t.Errorf("tools/list = %+v", tools.Tools)
+    }
+
+    call := json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"convert_time","arguments":{}}}`)
+    resp = srv.HandleMessage(ci, call)
+    if e, ok := resp.(mcp.JSONRPCError); !ok || !strings.Contains(e.Error.Message, "forbidden") {
+        t.Errorf("out of scope call: %#v", resp)
+    }
+
+    // Without a scoped token (-auth-token or stdio) everything is allowed
+    resp = srv.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`))
+    data, _ = json.Marshal(resp.(mcp.JSONRPCResponse).Result)
+    _ = json.Unmarshal(data, &tools)
+    if len(tools.Tools) != 2 {
+        t.Errorf("unscoped tools/list = %+v", tools.Tools)
+    }
+}
@@ -0,0 +1,158 @@
+// -*- coding: utf-8 -*-
+// server.go - http.Server construction for the HTTP transports
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file builds the http.Server shared by the sse, http, dual and rest
+// transports, with the timeouts an internet-facing deployment needs:
+// ReadHeaderTimeout against slowloris clients, IdleTimeout for keep-alive
+// connections, MaxHeaderBytes, and a write timeout.
+//
+// http.Server.WriteTimeout applies to the whole connection and would cut SSE
+// streams off, so the server-level value stays zero and the write timeout is
+// set per request with http.ResponseController, skipping event streams.
+// There is deliberately no ReadTimeout for the same reason: its deadline also
+// ends the background read that keeps a streaming request's context alive.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "net"
+    "net/http"
+    "time"
+)
+
+// httpServerConfig holds the tunable http.Server settings
+type httpServerConfig struct {
+    readHeaderTimeout time.Duration
+    writeTimeout      time.Duration
+    idleTimeout       time.Duration
+    maxHeaderBytes    int
+    maxConnections    int
+}
+
+// Defaults for the http.Server flags
+const (
+    defaultReadHeaderTimeout = 10 * time.Second
+    defaultIdleTimeout       = 120 * time.Second
+    defaultMaxHeaderBytes    = http.DefaultMaxHeaderBytes
+)
+
+// httpTuning is the configuration used by newHTTPServer (set from flags)
+var httpTuning = httpServerConfig{
+    readHeaderTimeout: defaultReadHeaderTimeout,
+    idleTimeout:       defaultIdleTimeout,
+    maxHeaderBytes:    defaultMaxHeaderBytes,
+}
+
+// newHTTPServer returns an http.Server for addr configured from cfg
+func newHTTPServer(addr string, handler http.Handler, cfg httpServerConfig) *http.Server {
+    if cfg.writeTimeout > 0 {
+        handler = writeDeadlineMiddleware(cfg.writeTimeout, handler)
+    }
+    conns := httpConns.forListener(addr, cfg.maxConnections)
+    if cfg.maxConnections > 0 {
+        handler = conns.middleware(handler)
+    }
+    return &http.Server{
+        Addr:              addr,
+        Handler:           handler,
+        ReadHeaderTimeout: cfg.readHeaderTimeout,
+        IdleTimeout:       cfg.idleTimeout,
+        MaxHeaderBytes:    cfg.maxHeaderBytes,
+        ConnState:         conns.trackConn,
+    }
+}
+
+// shutdownTimeout bounds how long a server stopped by its context waits for
+// open requests; SSE streams never finish on their own, so it is short
+const shutdownTimeout = 5 * time.Second
+
+// serveHTTP runs srv on a socket handed over by a previous process or by
+// systemd, if any, or on srv.Addr, until ctx is done. After an upgrade it
+// returns once the old connections have drained.
+func serveHTTP(ctx context.Context, srv *http.Server) error {
+    ln, err := listenHTTP(srv.Addr)
+    if err != nil {
+        return err
+    }
+    listenerState.accepting.Store(true)
+    serverReady()
+    drained := watchUpgrade(srv, ln)
+    wait := shutdownOnDone(ctx, srv)
+    err = srv.Serve(ln)
+    wait()
+    if errors.Is(err, http.ErrServerClosed) && drained != nil && ctx.Err() == nil {
+        <-drained
+    }
+    return err
+}
+
+// shutdownOnDone shuts srv down when ctx is done, closing the connections
+// still open after shutdownTimeout; /readyz fails from then on. The returned
+// function stops watching ctx, or waits for a shutdown already under way.
+func shutdownOnDone(ctx context.Context, srv *http.Server) (wait func()) {
+    done := make(chan struct{})
+    stop := context.AfterFunc(ctx, func() {
+        defer close(done)
+        listenerState.accepting.Store(false)
+        sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+        defer cancel()
+        if err := srv.Shutdown(sctx); err != nil {
+            logAt(logWarn, "shutdown: closing connections still open after %v", shutdownTimeout)
+            srv.Close()
+        }
+    })
+    return func() {
+        if !stop() {
+            <-done
+        }
+    }
+}
+
+// listenHTTP picks the listening socket for serveHTTP
+func listenHTTP(addr string) (net.Listener, error) {
+    ln, err := inheritedListener()
+    if err != nil || ln != nil {
+        if ln != nil {
+            logAt(logInfo, "  Listening on inherited socket %s", ln.Addr())
+        }
+        return ln, err
+    }
+    if ln, err = systemdListener(); err != nil || ln != nil {
+        if ln != nil {
+            logAt(logInfo, "  Listening on systemd socket %s (ignoring %s)", ln.Addr(), addr)
+        }
+        return ln, err
+    }
+    return listenAddr(addr)
+}
+
+// writeDeadlineMiddleware sets a write deadline on each response except SSE
+// event streams, which clear it: the deadline is per connection, so one left
+// by an earlier keep-alive request would otherwise cut the stream off
+func writeDeadlineMiddleware(d time.Duration, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var deadline time.Time
+        if !isEventStream(r) {
+            deadline = time.Now().Add(d)
+        }
+        if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+            logAt(logDebug, "cannot set write deadline for %s: %v", r.URL.Path, err)
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// logServerSettings logs the write timeout and connection cap when set
+func logServerSettings(cfg httpServerConfig) {
+    if cfg.maxConnections > 0 {
+        logAt(logInfo, "  Max connections:  %d", cfg.maxConnections)
+    }
+    if cfg.writeTimeout > 0 {
+        logAt(logInfo, "  Write timeout:    %v (SSE streams excluded)", cfg.writeTimeout)
+    }
+}
@@ -0,0 +1,113 @@
+// -*- coding: utf-8 -*-
+// server_test.go - Tests for http.Server construction
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+    cfg := httpServerConfig{
+        readHeaderTimeout: 5 * time.Second,
+        idleTimeout:       time.Minute,
+        writeTimeout:      time.Second,
+        maxHeaderBytes:    4096,
+    }
+    srv := newHTTPServer(":0", http.NotFoundHandler(), cfg)
+    if srv.ReadHeaderTimeout != cfg.readHeaderTimeout || srv.IdleTimeout != cfg.idleTimeout || srv.MaxHeaderBytes != 4096 {
+        t.Errorf("settings not applied: %+v", srv)
+    }
+    if srv.WriteTimeout != 0 || srv.ReadTimeout != 0 {
+        t.Error("connection-wide timeouts must stay zero so SSE streams survive")
+    }
+}
+
+func TestWriteDeadlineSkipsEventStreams(t *testing.T) {
+    slowErr := make(chan error, 1)
+    h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.URL.Path == "/sse" {
+            w.Header().Set("Content-Type", "text/event-stream")
+            for i := 0; i < 3; i++ {
+                _, _ = io.WriteString(w, "data: tick\n\n")
+                w.(http.Flusher).Flush()
+                time.Sleep(60 * time.Millisecond)
+            }
+            return
+        }
+        time.Sleep(150 * time.Millisecond)
+        _, _ = io.WriteString(w, "too late")
+        slowErr <- http.NewResponseController(w).Flush()
+    })
+    srv := httptest.NewUnstartedServer(nil)
+    srv.Config = newHTTPServer("", h, httpServerConfig{writeTimeout: 100 * time.Millisecond})
+    srv.Start()
+    defer srv.Close()
+
+    // A slow ordinary response misses its deadline: the handler's write
+    // fails and the client never sees the body
+    var body []byte
+    resp, err := http.Get(srv.URL + "/slow")
+    if err == nil {
+        body, err = io.ReadAll(resp.Body)
+        resp.Body.Close()
+    }
+    if err == nil || string(body) == "too late" {
+        t.Errorf("slow response was not cut off: body %q, err %v", body, err)
+    }
+    select {
+    case err := <-slowErr:
+        if !errors.Is(err, os.ErrDeadlineExceeded) {
+            t.Errorf("handler write error = %v, want deadline exceeded", err)
+        }
+    case <-time.After(2 * time.Second):
+        t.Error("slow handler did not finish")
+    }
+
+    // An event stream outlives the write timeout
+    resp, err = http.Get(srv.URL + "/sse")
+    if err != nil {
+        t.Fatalf("GET /sse: %v", err)
+    }
+    defer resp.Body.Close()
+    events := 0
+    scanner := bufio.NewScanner(resp.Body)
+    for scanner.Scan() {
+        if strings.HasPrefix(scanner.Text(), "data:") {
+            events++
+        }
+    }
+    if events != 3 {
+        t.Errorf("want 3 events, got %d (err %v)", events, scanner.Err())
+    }
+}
+
+func TestWriteDeadlineSkipsRESTTimeStream(t *testing.T) {
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    srv := httptest.NewUnstartedServer(nil)
+    srv.Config = newHTTPServer("", mux, httpServerConfig{writeTimeout: 100 * time.Millisecond})
+    srv.Start()
+    defer srv.Close()
+
+    // No Accept header, as with curl -N
+    resp, err := http.Get(srv.URL + restTimeStreamPath + "?timezones=UTC&interval=100ms")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    if n := countStreamEvents(t, resp.Body, 4); n != 4 {
+        t.Errorf("stream cut off after %d events", n)
+    }
+}
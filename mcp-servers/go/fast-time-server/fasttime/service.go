@@ -0,0 +1,76 @@
+// -*- coding: utf-8 -*-
+// service.go - time operations shared by every transport
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// The MCP tools, the REST handlers and the GraphQL resolvers each read
+// their own inputs and render their own output, but the operations in
+// between live here so that every transport answers alike: the same zone
+// resolution (aliases, legacy names, tzdata), the same time layouts and the
+// same errors. A gRPC service would be one more caller of these functions;
+// service_test.go checks the transports against each other.
+//
+// Errors are *apiError values naming the input that was rejected with the
+// service's own field names ("timezone", "time", "from" and "to").
+// Transports map them to their parameter names with renameField.
+
+package fasttime
+
+import (
+    "errors"
+    "time"
+)
+
+// timeIn returns now in the zone tz; callers pass currentTime(), or one
+// instant for several zones
+func timeIn(tz string, now time.Time) (time.Time, error) {
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return time.Time{}, fieldError("timezone", err)
+    }
+    return now.In(loc), nil
+}
+
+// convertTime parses value in the zone from (a value with an offset keeps
+// it) and returns it in from and in to
+func convertTime(value, from, to string) (src, dst time.Time, err error) {
+    fromLoc, err := loadLocation(from)
+    if err != nil {
+        return src, dst, fieldError("from", err)
+    }
+    toLoc, err := loadLocation(to)
+    if err != nil {
+        return src, dst, fieldError("to", err)
+    }
+    t, err := parseTimeInLocation(value, fromLoc)
+    if err != nil {
+        return src, dst, errUnparseableTime("time", value)
+    }
+    return t.In(fromLoc), t.In(toLoc), nil
+}
+
+// errorField returns the field a service error rejected, or ""
+func errorField(err error) string {
+    var ae *apiError
+    if errors.As(err, &ae) {
+        return ae.Field
+    }
+    return ""
+}
+
+// renameField returns err with its field renamed to the transport's name
+// for it in names
+func renameField(err error, names map[string]string) error {
+    var ae *apiError
+    if !errors.As(err, &ae) {
+        return err
+    }
+    name, ok := names[ae.Field]
+    if !ok {
+        return err
+    }
+    out := *ae
+    out.Field = name
+    return &out
+}
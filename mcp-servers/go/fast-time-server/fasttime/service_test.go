@@ -0,0 +1,172 @@
+// -*- coding: utf-8 -*-
+// service_test.go - parity of the transports over the shared operations
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// answer is what a transport returned: a time, or an error code
+type answer struct {
+    value, code string
+}
+
+// toolAnswer calls a tool handler and reads the text or error code of its
+// result
+func toolAnswer(t *testing.T, handler server.ToolHandlerFunc, req mcp.CallToolRequest) answer {
+    t.Helper()
+    res, err := errorCodeMiddleware(handler)(context.Background(), req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if res.IsError {
+        body, _ := res.StructuredContent.(map[string]any)
+        ae, _ := body["error"].(*apiError)
+        if ae == nil {
+            t.Fatalf("tool error without a code: %+v", res)
+        }
+        return answer{code: ae.Code}
+    }
+    return answer{value: res.Content[0].(mcp.TextContent).Text}
+}
+
+// restAnswer runs a REST handler and reads field of its body or its error code
+func restAnswer(t *testing.T, handler http.HandlerFunc, req *http.Request, field string) answer {
+    t.Helper()
+    rec := httptest.NewRecorder()
+    handler(rec, req)
+    var body map[string]interface{}
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatal(err)
+    }
+    if rec.Code != http.StatusOK {
+        code, _ := body["error_code"].(string)
+        return answer{code: code}
+    }
+    value, _ := body[field].(string)
+    return answer{value: value}
+}
+
+// graphqlAnswer runs a query and reads the string at path of its data or
+// the code of its first error
+func graphqlAnswer(t *testing.T, query string, vars map[string]interface{}, path ...string) answer {
+    t.Helper()
+    resp, _ := executeGraphQL(context.Background(), graphqlRequest{Query: query, Variables: vars}, "UTC")
+    b, _ := json.Marshal(resp)
+    var body struct {
+        Data   map[string]interface{} `json:"data"`
+        Errors []gqlError             `json:"errors"`
+    }
+    if err := json.Unmarshal(b, &body); err != nil {
+        t.Fatal(err)
+    }
+    if len(body.Errors) > 0 {
+        code, _ := body.Errors[0].Extensions["code"].(string)
+        return answer{code: code}
+    }
+    var v interface{} = body.Data
+    for _, key := range path {
+        v = v.(map[string]interface{})[key]
+    }
+    return answer{value: v.(string)}
+}
+
+// transports answer the same request over MCP, REST and GraphQL
+type transports map[string]func(t *testing.T) answer
+
+// checkParity fails unless every transport gave want
+func checkParity(t *testing.T, name string, want answer, all transports) {
+    t.Helper()
+    for transport, run := range all {
+        if got := run(t); got != want {
+            t.Errorf("%s over %s = %+v, want %+v", name, transport, got, want)
+        }
+    }
+}
+
+func TestTransportParityTime(t *testing.T) {
+    defer clock.reset()
+    clock.set(time.Date(2025, 3, 9, 7, 30, 0, 0, time.UTC))
+
+    for _, tc := range []struct {
+        tz   string
+        want answer
+    }{
+        {"UTC", answer{value: "2025-03-09T07:30:00Z"}},
+        {"America/New_York", answer{value: "2025-03-09T03:30:00-04:00"}},
+        {"Asia/Kolkata", answer{value: "2025-03-09T13:00:00+05:30"}},
+        {"US/Pacific", answer{value: "2025-03-08T23:30:00-08:00"}},
+        {"Mars/Base", answer{code: codeInvalidTimezone}},
+    } {
+        checkParity(t, tc.tz, tc.want, transports{
+            "mcp": func(t *testing.T) answer {
+                return toolAnswer(t, handleGetSystemTime, testRequest("get_system_time", map[string]any{"timezone": tc.tz}))
+            },
+            "rest": func(t *testing.T) answer {
+                return restAnswer(t, handleRESTGetTime,
+                    httptest.NewRequest(http.MethodGet, "/api/v1/time?timezone="+url.QueryEscape(tc.tz), nil), "time")
+            },
+            "graphql": func(t *testing.T) answer {
+                return graphqlAnswer(t, `query($tz: String) { time(timezone: $tz) { time } }`,
+                    map[string]interface{}{"tz": tc.tz}, "time", "time")
+            },
+        })
+    }
+}
+
+func TestTransportParityConvert(t *testing.T) {
+    for _, tc := range []struct {
+        time, from, to string
+        want           answer
+    }{
+        {"2025-01-10T10:00:00Z", "UTC", "Asia/Tokyo", answer{value: "2025-01-10T19:00:00+09:00"}},
+        {"2025-01-10T10:00:00+01:00", "America/New_York", "UTC", answer{value: "2025-01-10T09:00:00Z"}},
+        {"2025-01-10 10:00:00", "Asia/Kolkata", "UTC", answer{value: "2025-01-10T04:30:00Z"}},
+        {"2025-07-01T09:00:00", "Europe/Berlin", "America/Los_Angeles", answer{value: "2025-07-01T00:00:00-07:00"}},
+        {"2025-01-10", "Australia/Sydney", "UTC", answer{value: "2025-01-09T13:00:00Z"}},
+        {"next tuesday", "UTC", "UTC", answer{code: codeUnparseableTime}},
+        {"2025-01-10T10:00:00Z", "Mars/Base", "UTC", answer{code: codeInvalidTimezone}},
+        {"2025-01-10T10:00:00Z", "UTC", "Mars/Base", answer{code: codeInvalidTimezone}},
+    } {
+        checkParity(t, tc.time+" "+tc.from+" -> "+tc.to, tc.want, transports{
+            "mcp": func(t *testing.T) answer {
+                args := map[string]any{"time": tc.time, "source_timezone": tc.from, "target_timezone": tc.to}
+                return toolAnswer(t, handleConvertTime, testRequest("convert_time", args))
+            },
+            "rest": func(t *testing.T) answer {
+                body, _ := json.Marshal(ConvertRequest{Time: tc.time, FromTimezone: tc.from, ToTimezone: tc.to})
+                return restAnswer(t, handleRESTConvertTime,
+                    httptest.NewRequest(http.MethodPost, "/api/v1/convert", bytes.NewReader(body)), "converted_time")
+            },
+            "graphql": func(t *testing.T) answer {
+                return graphqlAnswer(t, `query($t: String!, $f: String, $to: String!) { convert(time: $t, from: $f, to: $to) { to { time } } }`,
+                    map[string]interface{}{"t": tc.time, "f": tc.from, "to": tc.to}, "convert", "to", "time")
+            },
+        })
+    }
+}
+
+func TestRenameField(t *testing.T) {
+    _, _, err := convertTime("2025-01-10T10:00:00Z", "UTC", "Mars/Base")
+    if errorField(err) != "to" {
+        t.Fatalf("field = %q", errorField(err))
+    }
+    renamed := renameField(err, restConvertFields)
+    if errorField(renamed) != "to_timezone" || errorField(err) != "to" || renamed.Error() != err.Error() {
+        t.Errorf("renamed = %+v, original %+v", renamed, err)
+    }
+}
@@ -0,0 +1,274 @@
+// -*- coding: utf-8 -*-
+// sessions.go - registry of connected MCP sessions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file records MCP sessions as the server registers and unregisters
+// them, together with the client that initialized each one and its preferred
+// default timezone and locale, so operators can list who is connected through
+// GET /admin/sessions. Handlers look up the calling client with clientFrom,
+// and requests that arrive out of order (anything but ping before
+// initialize, or a second initialize) are refused with a JSON-RPC error
+// before they reach the library's dispatch.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// sessionInfo describes one registered session
+type sessionInfo struct {
+    ID              string    `json:"id"`
+    ConnectedAt     time.Time `json:"connected_at"`
+    ClientName      string    `json:"client_name,omitempty"`
+    ClientVersion   string    `json:"client_version,omitempty"`
+    ProtocolVersion string    `json:"protocol_version,omitempty"`
+    Initialized     bool      `json:"initialized"`
+    DefaultTimezone string    `json:"default_timezone,omitempty"`
+    Locale          string    `json:"locale,omitempty"`
+    Sampling        bool      `json:"sampling,omitempty"`    // client accepts sampling/createMessage
+    Elicitation     bool      `json:"elicitation,omitempty"` // client accepts elicitation/create
+
+    Capabilities *mcp.ClientCapabilities `json:"capabilities,omitempty"`
+}
+
+// sessionRegistry tracks the registered sessions by id
+type sessionRegistry struct {
+    mu   sync.Mutex
+    byID map[string]*sessionInfo
+}
+
+// sessions is the process-wide session registry
+var sessions = newSessionRegistry()
+
+// newSessionRegistry creates an empty registry
+func newSessionRegistry() *sessionRegistry {
+    return &sessionRegistry{byID: make(map[string]*sessionInfo)}
+}
+
+// add records a new session
+func (sr *sessionRegistry) add(id string, now time.Time) {
+    sr.mu.Lock()
+    sr.byID[id] = &sessionInfo{ID: id, ConnectedAt: now.UTC()}
+    sr.mu.Unlock()
+}
+
+// remove forgets a session
+func (sr *sessionRegistry) remove(id string) {
+    sr.mu.Lock()
+    delete(sr.byID, id)
+    sr.mu.Unlock()
+}
+
+// setClient records the client details sent in initialize
+func (sr *sessionRegistry) setClient(id string, client mcp.Implementation, protocol string) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        info.ClientName = client.Name
+        info.ClientVersion = client.Version
+        info.ProtocolVersion = protocol
+        info.Initialized = true
+    }
+}
+
+// setCapabilities records the capabilities the client declared in initialize
+func (sr *sessionRegistry) setCapabilities(id string, caps mcp.ClientCapabilities) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        info.Capabilities = &caps
+        info.Sampling = caps.Sampling != nil
+        info.Elicitation = caps.Elicitation != nil
+    }
+}
+
+// setSampling records whether the client declared the sampling capability
+func (sr *sessionRegistry) setSampling(id string, ok bool) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, found := sr.byID[id]; found {
+        info.Sampling = ok
+    }
+}
+
+// setElicitation records whether the client declared the elicitation capability
+func (sr *sessionRegistry) setElicitation(id string, ok bool) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, found := sr.byID[id]; found {
+        info.Elicitation = ok
+    }
+}
+
+// get returns a copy of the session's details
+func (sr *sessionRegistry) get(id string) (sessionInfo, bool) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        return *info, true
+    }
+    return sessionInfo{}, false
+}
+
+// clientFrom returns the details of the session a handler is serving,
+// false when the request did not arrive on a registered session
+func clientFrom(ctx context.Context) (sessionInfo, bool) {
+    return sessions.get(sessionIDFrom(ctx))
+}
+
+// elicitation reports whether the session's client accepts elicitation requests
+func (sr *sessionRegistry) elicitation(id string) bool {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        return info.Elicitation
+    }
+    return false
+}
+
+// sampling reports whether the session's client accepts sampling requests
+func (sr *sessionRegistry) sampling(id string) bool {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        return info.Sampling
+    }
+    return false
+}
+
+// list returns copies of all sessions, oldest first
+func (sr *sessionRegistry) list() []sessionInfo {
+    sr.mu.Lock()
+    out := make([]sessionInfo, 0, len(sr.byID))
+    for _, info := range sr.byID {
+        out = append(out, *info)
+    }
+    sr.mu.Unlock()
+    sort.Slice(out, func(i, j int) bool {
+        if !out[i].ConnectedAt.Equal(out[j].ConnectedAt) {
+            return out[i].ConnectedAt.Before(out[j].ConnectedAt)
+        }
+        return out[i].ID < out[j].ID
+    })
+    return out
+}
+
+// setDefaultTimezone records the session's preferred default zone
+func (sr *sessionRegistry) setDefaultTimezone(id, tz string) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        info.DefaultTimezone = tz
+    }
+}
+
+// defaultTimezone returns the session's preferred default zone, if any
+func (sr *sessionRegistry) defaultTimezone(id string) string {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        return info.DefaultTimezone
+    }
+    return ""
+}
+
+// setLocale records the session's preferred locale
+func (sr *sessionRegistry) setLocale(id, tag string) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        info.Locale = tag
+    }
+}
+
+// locale returns the session's preferred locale, if any
+func (sr *sessionRegistry) locale(id string) string {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if info, ok := sr.byID[id]; ok {
+        return info.Locale
+    }
+    return ""
+}
+
+// count returns the number of registered sessions
+func (sr *sessionRegistry) count() int {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    return len(sr.byID)
+}
+
+// trackSessions installs the hooks that keep the registry up to date
+func (sr *sessionRegistry) trackSessions(hooks *server.Hooks) {
+    hooks.AddOnRegisterSession(func(_ context.Context, sess server.ClientSession) {
+        sr.add(sess.SessionID(), time.Now())
+    })
+    hooks.AddOnUnregisterSession(func(_ context.Context, sess server.ClientSession) {
+        sr.remove(sess.SessionID())
+    })
+    hooks.AddAfterInitialize(func(ctx context.Context, _ any, req *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+        if id := sessionIDFrom(ctx); id != "" {
+            sr.setClient(id, req.Params.ClientInfo, req.Params.ProtocolVersion)
+            sr.setCapabilities(id, req.Params.Capabilities)
+            if tz := initDefaultTimezone(req); tz != "" {
+                sr.setDefaultTimezone(id, tz)
+            }
+            if tag := initLocale(req); tag != "" {
+                sr.setLocale(id, tag)
+            }
+        }
+    })
+}
+
+// errNotInitialized and errAlreadyInitialized describe the refused sequences
+var (
+    errNotInitialized     = errors.New("session not initialized")
+    errAlreadyInitialized = errors.New("session already initialized")
+)
+
+// requireInitialize installs a hook that refuses requests sent before
+// initialize, and a repeated initialize, on sessions that keep state
+// between requests. Streamable HTTP requests carry a fresh session per
+// initialize and are left to the transport's session-id check.
+func (sr *sessionRegistry) requireInitialize(hooks *server.Hooks) {
+    hooks.AddOnRequestInitialization(func(ctx context.Context, _ any, message any) error {
+        sess := server.ClientSessionFromContext(ctx)
+        raw, ok := message.(json.RawMessage)
+        if sess == nil || !ok {
+            return nil
+        }
+        var msg struct {
+            Method string `json:"method"`
+        }
+        if json.Unmarshal(raw, &msg) != nil {
+            return nil
+        }
+        switch mcp.MCPMethod(msg.Method) {
+        case mcp.MethodPing:
+            return nil
+        case mcp.MethodInitialize:
+            if info, ok := sr.get(sess.SessionID()); ok && info.Initialized {
+                logAt(logWarn, "session %s: refused repeated initialize", sess.SessionID())
+                return errAlreadyInitialized
+            }
+            return nil
+        }
+        if !sess.Initialized() {
+            logAt(logWarn, "session %s: refused %s before initialize", sess.SessionID(), msg.Method)
+            return fmt.Errorf("%w: send initialize before %s", errNotInitialized, msg.Method)
+        }
+        return nil
+    })
+}
@@ -0,0 +1,79 @@
+// -*- coding: utf-8 -*-
+// sessions_test.go - tests for session tracking and initialize ordering
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "sync/atomic"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// orderedSession is a ClientSession that starts uninitialized, like the
+// stdio and SSE sessions do
+type orderedSession struct {
+    fakeSession
+    initialized atomic.Bool
+}
+
+func (o *orderedSession) Initialize()       { o.initialized.Store(true) }
+func (o *orderedSession) Initialized() bool { return o.initialized.Load() }
+
+func TestRequireInitialize(t *testing.T) {
+    hooks := &server.Hooks{}
+    sessions.trackSessions(hooks)
+    sessions.requireInitialize(hooks)
+    s := newMCPServer(hooks, false)
+    s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        info, ok := clientFrom(ctx)
+        if !ok {
+            return mcp.NewToolResultError("no session"), nil
+        }
+        roots := info.Capabilities != nil && info.Capabilities.Roots != nil
+        return mcp.NewToolResultText(info.ClientName + " " + info.ClientVersion + " " + map[bool]string{true: "roots", false: "-"}[roots]), nil
+    })
+
+    sess := &orderedSession{fakeSession: fakeSession{id: "ordered-session", ch: make(chan mcp.JSONRPCNotification, 4)}}
+    ctx := s.WithContext(context.Background(), sess)
+    if err := s.RegisterSession(ctx, sess); err != nil {
+        t.Fatal(err)
+    }
+    defer s.UnregisterSession(ctx, sess.id)
+
+    send := func(msg string) string {
+        data, err := json.Marshal(s.HandleMessage(ctx, json.RawMessage(msg)))
+        if err != nil {
+            t.Fatal(err)
+        }
+        return string(data)
+    }
+    const initialize = `{"jsonrpc":"2.0","id":%d,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"roots":{}},"clientInfo":{"name":"probe","version":"2.0"}}}`
+    const call = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"whoami"}}`
+
+    if out := send(call); !strings.Contains(out, `"code":-32600`) || !strings.Contains(out, "before tools/call") {
+        t.Errorf("tools/call before initialize = %s", out)
+    }
+    if out := send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`); strings.Contains(out, `"error"`) {
+        t.Errorf("ping before initialize = %s", out)
+    }
+    if out := send(strings.Replace(initialize, "%d", "4", 1)); strings.Contains(out, `"error"`) {
+        t.Fatalf("initialize = %s", out)
+    }
+    if out := send(call); !strings.Contains(out, "probe 2.0 roots") {
+        t.Errorf("tools/call after initialize = %s", out)
+    }
+    if out := send(strings.Replace(initialize, "%d", "5", 1)); !strings.Contains(out, `"code":-32600`) || !strings.Contains(out, "already initialized") {
+        t.Errorf("repeated initialize = %s", out)
+    }
+    if info, _ := sessions.get(sess.id); !info.Initialized || info.ClientName != "probe" {
+        t.Errorf("registry = %+v", info)
+    }
+}
@@ -0,0 +1,203 @@
+// -*- coding: utf-8 -*-
+// slowlog.go - slow request log
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Every REST and GraphQL request and every tool call (on any transport) that
+// takes longer than -slow-request is logged at warn level and kept in a ring
+// of the last maxSlowRequests, with what is needed to triage it: the path or
+// tool, the outcome, the size of the arguments or request body, and the
+// client (scoped token, MCP client name, remote address and session).
+// GET /admin/slow lists the ring, newest first, with the threshold and the
+// number of slow requests since startup; DELETE /admin/slow empties both.
+//
+// MCP messages are timed per tool call rather than per HTTP request, so a
+// slow tool is reported once by name whichever transport carried it. Event
+// streams are long-lived by design and are not timed.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    defaultSlowRequest = time.Second // default -slow-request
+    maxSlowRequests    = 100         // slow requests kept for /admin/slow
+)
+
+// slowRequest is one request that exceeded the threshold
+type slowRequest struct {
+    Time       string  `json:"time"`
+    Kind       string  `json:"kind"` // "http" or "tool"
+    Method     string  `json:"method,omitempty"`
+    Path       string  `json:"path,omitempty"`
+    HTTPStatus int     `json:"http_status,omitempty"`
+    Tool       string  `json:"tool,omitempty"`
+    Status     string  `json:"status"` // ok, error or cancelled
+    DurationMS float64 `json:"duration_ms"`
+    ArgsBytes  int64   `json:"args_bytes"`
+    Client     string  `json:"client"`
+    RemoteAddr string  `json:"remote_addr,omitempty"`
+    Session    string  `json:"session,omitempty"`
+}
+
+// slowLog keeps the most recent slow requests, newest last
+type slowLog struct {
+    mu        sync.Mutex
+    threshold time.Duration // 0 disables
+    entries   []slowRequest
+    total     int64
+}
+
+// slowRequests is the process-wide slow request log (-slow-request)
+var slowRequests = &slowLog{threshold: defaultSlowRequest}
+
+// setThreshold changes the latency above which requests are recorded
+func (sl *slowLog) setThreshold(d time.Duration) {
+    sl.mu.Lock()
+    sl.threshold = d
+    sl.mu.Unlock()
+}
+
+// isSlow reports whether a request that took d is recorded
+func (sl *slowLog) isSlow(d time.Duration) bool {
+    sl.mu.Lock()
+    defer sl.mu.Unlock()
+    return sl.threshold > 0 && d > sl.threshold
+}
+
+// add logs and records a slow request
+func (sl *slowLog) add(e slowRequest) {
+    what := e.Method + " " + e.Path
+    if e.Kind == "tool" {
+        what = "tool " + e.Tool
+    }
+    logAt(logWarn, "slow request: %s took %.1fms (%s, %d argument bytes, client %s)",
+        what, e.DurationMS, e.Status, e.ArgsBytes, e.Client)
+
+    sl.mu.Lock()
+    defer sl.mu.Unlock()
+    sl.total++
+    sl.entries = append(sl.entries, e)
+    if n := len(sl.entries); n > maxSlowRequests {
+        sl.entries = append(sl.entries[:0], sl.entries[n-maxSlowRequests:]...)
+    }
+}
+
+// snapshot returns the threshold, the count since startup and the recorded
+// requests, newest first
+func (sl *slowLog) snapshot() map[string]interface{} {
+    sl.mu.Lock()
+    defer sl.mu.Unlock()
+    list := make([]slowRequest, len(sl.entries))
+    for i, e := range sl.entries {
+        list[len(list)-1-i] = e
+    }
+    return map[string]interface{}{
+        "threshold_ms": sl.threshold.Milliseconds(),
+        "total":        sl.total,
+        "requests":     list,
+    }
+}
+
+// count returns the number of slow requests since startup or the last reset
+func (sl *slowLog) count() int64 {
+    sl.mu.Lock()
+    defer sl.mu.Unlock()
+    return sl.total
+}
+
+// reset empties the ring and the count
+func (sl *slowLog) reset() {
+    sl.mu.Lock()
+    sl.entries, sl.total = nil, 0
+    sl.mu.Unlock()
+}
+
+// newSlowRequest fills in the fields common to both kinds
+func newSlowRequest(ctx context.Context, kind string, start time.Time) slowRequest {
+    return slowRequest{
+        Time:       start.UTC().Format(time.RFC3339Nano),
+        Kind:       kind,
+        DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+        Client:     usageClient(ctx),
+        Session:    sessionIDFrom(ctx),
+    }
+}
+
+// slowHTTPMiddleware records REST and GraphQL requests slower than the
+// threshold
+func slowHTTPMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if isEventStream(r) {
+            next.ServeHTTP(w, r)
+            return
+        }
+        start := time.Now()
+        sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+        next.ServeHTTP(sw, r)
+        if !slowRequests.isSlow(time.Since(start)) {
+            return
+        }
+
+        e := newSlowRequest(r.Context(), "http", start)
+        e.Method, e.Path, e.HTTPStatus = r.Method, r.URL.Path, sw.status
+        e.RemoteAddr = r.RemoteAddr
+        e.ArgsBytes = int64(len(r.URL.RawQuery))
+        if r.ContentLength > 0 {
+            e.ArgsBytes += r.ContentLength
+        }
+        switch {
+        case r.Context().Err() != nil:
+            e.Status = "cancelled"
+        case sw.status >= http.StatusBadRequest:
+            e.Status = "error"
+        default:
+            e.Status = "ok"
+        }
+        slowRequests.add(e)
+    })
+}
+
+// slowToolMiddleware records tool calls slower than the threshold
+func slowToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        start := time.Now()
+        res, err := next(ctx, req)
+        if !slowRequests.isSlow(time.Since(start)) {
+            return res, err
+        }
+
+        e := newSlowRequest(ctx, "tool", start)
+        e.Tool = req.Params.Name
+        if args, merr := json.Marshal(req.Params.Arguments); merr == nil && req.Params.Arguments != nil {
+            e.ArgsBytes = int64(len(args))
+        }
+        e.Status = newAuditRecord(ctx, req, start, res, err).Status
+        slowRequests.add(e)
+        return res, err
+    }
+}
+
+// handleAdminSlow handles GET and DELETE /admin/slow
+func handleAdminSlow(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodGet:
+        writeJSON(w, http.StatusOK, slowRequests.snapshot())
+    case http.MethodDelete:
+        slowRequests.reset()
+        logAt(logInfo, "admin: slow request log cleared")
+        writeJSON(w, http.StatusOK, map[string]string{"status": "cleared"})
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
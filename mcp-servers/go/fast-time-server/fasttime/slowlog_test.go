@@ -0,0 +1,143 @@
+// -*- coding: utf-8 -*-
+// slowlog_test.go - tests for the slow request log
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// useTestSlowLog replaces slowRequests for the duration of a test
+func useTestSlowLog(t *testing.T, threshold time.Duration) *slowLog {
+    t.Helper()
+    old := slowRequests
+    slowRequests = &slowLog{threshold: threshold}
+    t.Cleanup(func() { slowRequests = old })
+    return slowRequests
+}
+
+// slowHandler sleeps for d before answering
+func slowHandler(d time.Duration) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(d)
+        writeJSONError(w, http.StatusBadRequest, "bad")
+    }
+}
+
+func TestSlowToolMiddleware(t *testing.T) {
+    sl := useTestSlowLog(t, 5*time.Millisecond)
+    tool := slowToolMiddleware(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        if req.Params.Name == "slow" {
+            time.Sleep(10 * time.Millisecond)
+        }
+        return mcp.NewToolResultText("ok"), nil
+    })
+
+    ctx := withCaller(context.Background(), &callerToken{Name: "ci"})
+    for _, name := range []string{"fast", "slow"} {
+        if _, err := tool(ctx, testRequest(name, map[string]any{"timezone": "UTC"})); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    reqs := sl.snapshot()["requests"].([]slowRequest)
+    if len(reqs) != 1 {
+        t.Fatalf("want only the slow call recorded, got %+v", reqs)
+    }
+    got := reqs[0]
+    if got.Kind != "tool" || got.Tool != "slow" || got.Status != "ok" || got.Client != "token:ci" {
+        t.Errorf("unexpected entry %+v", got)
+    }
+    if got.ArgsBytes != int64(len(`{"timezone":"UTC"}`)) || got.DurationMS < 10 {
+        t.Errorf("want the argument size and duration, got %+v", got)
+    }
+}
+
+func TestSlowHTTPMiddleware(t *testing.T) {
+    sl := useTestSlowLog(t, 5*time.Millisecond)
+    h := slowHTTPMiddleware(slowHandler(10 * time.Millisecond))
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/convert?x=1", strings.NewReader(`{"time":"now"}`))
+    h.ServeHTTP(httptest.NewRecorder(), req)
+
+    // Event streams are never timed
+    stream := httptest.NewRequest(http.MethodGet, "/api/v1/time/stream", nil)
+    stream.Header.Set("Accept", "text/event-stream")
+    h.ServeHTTP(httptest.NewRecorder(), stream)
+
+    reqs := sl.snapshot()["requests"].([]slowRequest)
+    if len(reqs) != 1 {
+        t.Fatalf("want one slow request, got %+v", reqs)
+    }
+    got := reqs[0]
+    if got.Kind != "http" || got.Method != http.MethodPost || got.Path != "/api/v1/convert" ||
+        got.HTTPStatus != http.StatusBadRequest || got.Status != "error" || got.Client != "anonymous" {
+        t.Errorf("unexpected entry %+v", got)
+    }
+    if got.ArgsBytes != int64(len("x=1")+len(`{"time":"now"}`)) {
+        t.Errorf("want query and body size, got %d", got.ArgsBytes)
+    }
+
+    // A zero threshold disables the log
+    sl.setThreshold(0)
+    h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/time", nil))
+    if n := sl.count(); n != 1 {
+        t.Errorf("disabled log recorded a request: %d", n)
+    }
+}
+
+func TestSlowLogRing(t *testing.T) {
+    sl := useTestSlowLog(t, time.Millisecond)
+    for i := 0; i < maxSlowRequests+5; i++ {
+        sl.add(slowRequest{Kind: "tool", Tool: "t", ArgsBytes: int64(i)})
+    }
+    reqs := sl.snapshot()["requests"].([]slowRequest)
+    if len(reqs) != maxSlowRequests || sl.count() != maxSlowRequests+5 {
+        t.Fatalf("want %d kept of %d, got %d of %d", maxSlowRequests, maxSlowRequests+5, len(reqs), sl.count())
+    }
+    if reqs[0].ArgsBytes != maxSlowRequests+4 || reqs[len(reqs)-1].ArgsBytes != 5 {
+        t.Errorf("want newest first, got %d ... %d", reqs[0].ArgsBytes, reqs[len(reqs)-1].ArgsBytes)
+    }
+}
+
+func TestAdminSlow(t *testing.T) {
+    sl := useTestSlowLog(t, time.Second)
+    sl.add(slowRequest{Kind: "tool", Tool: "parse_ical", Status: "ok"})
+
+    rec := httptest.NewRecorder()
+    handleAdminSlow(rec, httptest.NewRequest(http.MethodGet, "/admin/slow", nil))
+    var body struct {
+        ThresholdMS int64         `json:"threshold_ms"`
+        Total       int64         `json:"total"`
+        Requests    []slowRequest `json:"requests"`
+    }
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatal(err)
+    }
+    if body.ThresholdMS != 1000 || body.Total != 1 || len(body.Requests) != 1 || body.Requests[0].Tool != "parse_ical" {
+        t.Errorf("unexpected /admin/slow %s", rec.Body)
+    }
+
+    rec = httptest.NewRecorder()
+    handleAdminSlow(rec, httptest.NewRequest(http.MethodDelete, "/admin/slow", nil))
+    if rec.Code != http.StatusOK || sl.count() != 0 || len(sl.snapshot()["requests"].([]slowRequest)) != 0 {
+        t.Errorf("DELETE did not clear the log: %d %s", rec.Code, rec.Body)
+    }
+
+    rec = httptest.NewRecorder()
+    handleAdminSlow(rec, httptest.NewRequest(http.MethodPost, "/admin/slow", nil))
+    if rec.Code != http.StatusMethodNotAllowed {
+        t.Errorf("POST: want 405, got %d", rec.Code)
+    }
+}
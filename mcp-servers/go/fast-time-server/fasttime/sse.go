@@ -0,0 +1,235 @@
+// -*- coding: utf-8 -*-
+// sse.go - SSE connection tracking, keep-alive and stale connection reaping
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file tracks long-lived SSE streams so that the server can report the
+// number of active connections at /health and close streams whose clients
+// have gone quiet for longer than a configured threshold, and caps the number
+// of concurrent streams (-max-sse-clients). Keep-alive pings
+// themselves are emitted by the mcp-go SSE server (see -sse-keepalive); a
+// client that answers those pings counts as active.
+
+package fasttime
+
+import (
+    "context"
+    "net/http"
+    "regexp"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// sessionIDPattern extracts the session id from the initial endpoint event
+var sessionIDPattern = regexp.MustCompile(`sessionId=([0-9A-Za-z-]+)`)
+
+// sseConn describes a single open SSE stream
+type sseConn struct {
+    remote   string
+    started  time.Time
+    lastSeen atomic.Int64 // unix nanoseconds of the last client activity
+    cancel   context.CancelFunc
+}
+
+// touch records client activity on the connection
+func (c *sseConn) touch(now time.Time) {
+    c.lastSeen.Store(now.UnixNano())
+}
+
+// idleFor reports how long the connection has been without client activity
+func (c *sseConn) idleFor(now time.Time) time.Duration {
+    return now.Sub(time.Unix(0, c.lastSeen.Load()))
+}
+
+// sseTracker keeps the set of open SSE streams keyed by MCP session id
+type sseTracker struct {
+    mu       sync.Mutex
+    conns    map[*sseConn]string // conn -> session id ("" until known)
+    byID     map[string]*sseConn
+    total    atomic.Int64
+    reaped   atomic.Int64
+    rejected atomic.Int64
+    max      int // 0 = unlimited
+}
+
+// sseConns is the process-wide tracker consulted by /health
+var sseConns = newSSETracker()
+
+// newSSETracker creates an empty tracker
+func newSSETracker() *sseTracker {
+    return &sseTracker{
+        conns: make(map[*sseConn]string),
+        byID:  make(map[string]*sseConn),
+    }
+}
+
+// add registers a new stream, or reports false when the cap is reached
+func (t *sseTracker) add(c *sseConn) bool {
+    t.mu.Lock()
+    if t.max > 0 && len(t.conns) >= t.max {
+        t.mu.Unlock()
+        t.rejected.Add(1)
+        return false
+    }
+    t.conns[c] = ""
+    t.mu.Unlock()
+    t.total.Add(1)
+    return true
+}
+
+// setMax sets the stream cap (0 = unlimited)
+func (t *sseTracker) setMax(n int) {
+    t.mu.Lock()
+    t.max = n
+    t.mu.Unlock()
+}
+
+// bind associates a stream with its MCP session id once it is known
+func (t *sseTracker) bind(c *sseConn, sessionID string) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if _, ok := t.conns[c]; !ok {
+        return
+    }
+    t.conns[c] = sessionID
+    t.byID[sessionID] = c
+}
+
+// remove unregisters a stream
+func (t *sseTracker) remove(c *sseConn) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if id := t.conns[c]; id != "" {
+        delete(t.byID, id)
+    }
+    delete(t.conns, c)
+}
+
+// touchSession records activity for the stream owning sessionID
+func (t *sseTracker) touchSession(sessionID string, now time.Time) {
+    t.mu.Lock()
+    c := t.byID[sessionID]
+    t.mu.Unlock()
+    if c != nil {
+        c.touch(now)
+    }
+}
+
+// active returns the number of currently open streams
+func (t *sseTracker) active() int {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return len(t.conns)
+}
+
+// sessionIdle reports how long the stream of sessionID has been idle, and
+// whether the session has an open stream at all
+func (t *sseTracker) sessionIdle(sessionID string, now time.Time) (time.Duration, bool) {
+    t.mu.Lock()
+    c := t.byID[sessionID]
+    t.mu.Unlock()
+    if c == nil {
+        return 0, false
+    }
+    return c.idleFor(now), true
+}
+
+// reapIdle closes every stream idle for longer than maxIdle and returns the count
+func (t *sseTracker) reapIdle(now time.Time, maxIdle time.Duration) int {
+    t.mu.Lock()
+    var stale []*sseConn
+    for c := range t.conns {
+        if c.idleFor(now) > maxIdle {
+            stale = append(stale, c)
+        }
+    }
+    t.mu.Unlock()
+
+    for _, c := range stale {
+        logAt(logInfo, "sse: closing stale connection from %s (idle %v, open %v)",
+            c.remote, c.idleFor(now).Round(time.Second), now.Sub(c.started).Round(time.Second))
+        c.cancel()
+    }
+    t.reaped.Add(int64(len(stale)))
+    return len(stale)
+}
+
+// runReaper periodically closes idle streams until ctx is done
+func (t *sseTracker) runReaper(ctx context.Context, maxIdle time.Duration) {
+    interval := maxIdle / 4
+    if interval < time.Second {
+        interval = time.Second
+    }
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            t.reapIdle(now, maxIdle)
+        }
+    }
+}
+
+// middleware wraps the SSE handler so streams on ssePath are tracked and any
+// request carrying a sessionId query parameter counts as client activity
+func (t *sseTracker) middleware(ssePath string, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if id := r.URL.Query().Get("sessionId"); id != "" {
+            t.touchSession(id, time.Now())
+        }
+
+        if r.URL.Path != ssePath || r.Method != http.MethodGet {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        ctx, cancel := context.WithCancel(r.Context())
+        defer cancel()
+
+        c := &sseConn{remote: r.RemoteAddr, started: time.Now(), cancel: cancel}
+        c.touch(c.started)
+        if !t.add(c) {
+            logAt(logWarn, "sse: rejected stream from %s: client limit reached", r.RemoteAddr)
+            w.Header().Set("Retry-After", "5")
+            writeJSONError(w, http.StatusServiceUnavailable, "Too many SSE clients")
+            return
+        }
+        defer t.remove(c)
+
+        next.ServeHTTP(&sseWriter{ResponseWriter: w, tracker: t, conn: c}, r.WithContext(ctx))
+    })
+}
+
+// sseWriter sniffs the session id from the initial endpoint event
+type sseWriter struct {
+    http.ResponseWriter
+    tracker *sseTracker
+    conn    *sseConn
+    bound   bool
+}
+
+func (sw *sseWriter) Write(b []byte) (int, error) {
+    if !sw.bound {
+        if m := sessionIDPattern.FindSubmatch(b); m != nil {
+            sw.tracker.bind(sw.conn, string(m[1]))
+            sw.bound = true
+        }
+    }
+    return sw.ResponseWriter.Write(b)
+}
+
+// Flush passes through to the underlying writer (required for SSE)
+func (sw *sseWriter) Flush() {
+    if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (sw *sseWriter) Unwrap() http.ResponseWriter {
+    return sw.ResponseWriter
+}
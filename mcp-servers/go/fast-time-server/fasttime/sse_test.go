@@ -0,0 +1,136 @@
+// -*- coding: utf-8 -*-
+// sse_test.go - Tests for SSE connection tracking and reaping
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestSSETrackerMiddleware(t *testing.T) {
+    tr := newSSETracker()
+    seen := make(chan int, 1)
+    release := make(chan struct{})
+
+    inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        _, _ = w.Write([]byte("event: endpoint\ndata: /message?sessionId=abc-123\r\n\r\n"))
+        seen <- tr.active()
+        select {
+        case <-release:
+        case <-r.Context().Done():
+        }
+    })
+    h := tr.middleware("/sse", inner)
+
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+    }()
+
+    if n := <-seen; n != 1 {
+        t.Fatalf("want 1 active connection, got %d", n)
+    }
+
+    tr.mu.Lock()
+    c := tr.byID["abc-123"]
+    tr.mu.Unlock()
+    if c == nil {
+        t.Fatal("session id was not sniffed from endpoint event")
+    }
+
+    // A message POST for the session refreshes activity
+    before := c.lastSeen.Load()
+    time.Sleep(time.Millisecond)
+    msg := httptest.NewRequest(http.MethodPost, "/message?sessionId=abc-123", strings.NewReader(`{}`))
+    tr.middleware("/sse", http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), msg)
+    if c.lastSeen.Load() <= before {
+        t.Error("message POST did not refresh connection activity")
+    }
+
+    close(release)
+    <-done
+    if n := tr.active(); n != 0 {
+        t.Errorf("want 0 active connections after close, got %d", n)
+    }
+    if n := tr.total.Load(); n != 1 {
+        t.Errorf("want total 1, got %d", n)
+    }
+}
+
+func TestSSETrackerReapIdle(t *testing.T) {
+    tr := newSSETracker()
+    h := tr.middleware("/sse", http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+        <-r.Context().Done()
+    }))
+
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+    }()
+
+    deadline := time.Now().Add(time.Second)
+    for tr.active() == 0 && time.Now().Before(deadline) {
+        time.Sleep(time.Millisecond)
+    }
+
+    if n := tr.reapIdle(time.Now(), time.Hour); n != 0 {
+        t.Fatalf("fresh connection should not be reaped, reaped %d", n)
+    }
+    if n := tr.reapIdle(time.Now().Add(2*time.Hour), time.Hour); n != 1 {
+        t.Fatalf("want 1 reaped connection, got %d", n)
+    }
+
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("reaped connection was not closed")
+    }
+    if tr.reaped.Load() != 1 {
+        t.Errorf("want reaped counter 1, got %d", tr.reaped.Load())
+    }
+}
+
+func TestSSETrackerClientLimit(t *testing.T) {
+    tr := newSSETracker()
+    tr.setMax(1)
+    entered := make(chan struct{}, 2)
+    release := make(chan struct{})
+    h := tr.middleware("/sse", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        entered <- struct{}{}
+        <-release
+    }))
+
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+    }()
+    <-entered
+
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+    if rec.Code != http.StatusServiceUnavailable {
+        t.Errorf("second stream: want 503, got %d", rec.Code)
+    }
+    if tr.rejected.Load() != 1 {
+        t.Errorf("want rejected counter 1, got %d", tr.rejected.Load())
+    }
+
+    close(release)
+    <-done
+    go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+    select {
+    case <-entered:
+    case <-time.After(time.Second):
+        t.Error("stream should be accepted once a slot is free")
+    }
+}
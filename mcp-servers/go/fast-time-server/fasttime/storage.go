@@ -0,0 +1,339 @@
+// -*- coding: utf-8 -*-
+// storage.go - persistence layer for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file defines Store, the interface behind everything the server keeps
+// between requests: timezone aliases, saved meeting participant groups,
+// custom holiday calendars and webhook schedules. Without -db the server uses memoryStore and the
+// data lives for the lifetime of the process; with -db=path.sqlite it uses
+// sqliteStore (storage_sqlite.go). Other backends only need to implement
+// Store and be selected in openStore.
+
+package fasttime
+
+import (
+    "errors"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// errNotFound is returned by Store lookups for missing records
+var errNotFound = errors.New("not found")
+
+// errLimitReached is returned by CreateSchedule when the owner is at quota
+var errLimitReached = errors.New("limit reached")
+
+// participantGroup is a saved set of meeting participants
+type participantGroup struct {
+    Name         string   `json:"name"`
+    Timezones    []string `json:"timezones"`
+    WorkingHours string   `json:"working_hours,omitempty"`
+    UpdatedAt    string   `json:"updated_at,omitempty"`
+}
+
+// holiday is one closed date of a holiday calendar
+type holiday struct {
+    Date string `json:"date"` // YYYY-MM-DD
+    Name string `json:"name,omitempty"`
+}
+
+// holidayCalendar is a named list of closed dates
+type holidayCalendar struct {
+    Name      string    `json:"name"`
+    Holidays  []holiday `json:"holidays"`
+    UpdatedAt string    `json:"updated_at,omitempty"`
+}
+
+// Store persists aliases, participant groups, holiday calendars and webhook
+// schedules. Names are case-insensitive; Put replaces any record with the
+// same name (or schedule ID). UpdateSchedule is an atomic read-modify-write:
+// fn sees the stored schedule and its changes are saved unless fn fails,
+// and a schedule deleted meanwhile gives errNotFound instead of coming back.
+// CreateSchedule checks the owner's quota and inserts in one step.
+type Store interface {
+    ListAliases() ([]tzAlias, error)
+    PutAlias(a tzAlias) error
+    DeleteAlias(name string) error
+
+    ListGroups() ([]participantGroup, error)
+    GetGroup(name string) (participantGroup, error)
+    PutGroup(g participantGroup) error
+    DeleteGroup(name string) error
+
+    ListCalendars() ([]holidayCalendar, error)
+    GetCalendar(name string) (holidayCalendar, error)
+    PutCalendar(c holidayCalendar) error
+    DeleteCalendar(name string) error
+
+    ListSchedules() ([]webhookSchedule, error)
+    GetSchedule(id string) (webhookSchedule, error)
+    PutSchedule(s webhookSchedule) error
+    UpdateSchedule(id string, fn func(*webhookSchedule) error) error
+    DeleteSchedule(id string) error
+    // CreateSchedule adds s unless its owner has maxActive active schedules
+    CreateSchedule(s webhookSchedule, maxActive int) error
+    // ListDueSchedules returns the active schedules due at or before now
+    ListDueSchedules(now time.Time) ([]webhookSchedule, error)
+    // PruneSchedules removes the completed and failed schedules that
+    // finished before before
+    PruneSchedules(before time.Time) (int, error)
+
+    // Persistent reports whether data survives a restart
+    Persistent() bool
+    // Ping checks that the backend is reachable
+    Ping() error
+    Close() error
+}
+
+// store is the process-wide Store (replaced by openStore when -db is set)
+var store Store = newMemoryStore()
+
+// openStore opens the backend selected by path; an empty path keeps data in memory
+func openStore(path string) (Store, error) {
+    if path == "" {
+        return newMemoryStore(), nil
+    }
+    return openSQLiteStore(path)
+}
+
+// memoryStore is a Store that keeps everything in process memory
+type memoryStore struct {
+    mu        sync.RWMutex
+    aliases   map[string]tzAlias
+    groups    map[string]participantGroup
+    calendars map[string]holidayCalendar
+    schedules map[string]webhookSchedule
+}
+
+// newMemoryStore creates an empty in-memory store
+func newMemoryStore() *memoryStore {
+    return &memoryStore{
+        aliases:   make(map[string]tzAlias),
+        groups:    make(map[string]participantGroup),
+        calendars: make(map[string]holidayCalendar),
+        schedules: make(map[string]webhookSchedule),
+    }
+}
+
+// sortedValues returns the values of m ordered by lower-case key
+func sortedValues[T any](m map[string]T) []T {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    out := make([]T, len(keys))
+    for i, k := range keys {
+        out[i] = m[k]
+    }
+    return out
+}
+
+// ListAliases implements Store
+func (m *memoryStore) ListAliases() ([]tzAlias, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return sortedValues(m.aliases), nil
+}
+
+// PutAlias implements Store
+func (m *memoryStore) PutAlias(a tzAlias) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.aliases[strings.ToLower(a.Name)] = a
+    return nil
+}
+
+// DeleteAlias implements Store
+func (m *memoryStore) DeleteAlias(name string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if _, ok := m.aliases[strings.ToLower(name)]; !ok {
+        return errNotFound
+    }
+    delete(m.aliases, strings.ToLower(name))
+    return nil
+}
+
+// ListGroups implements Store
+func (m *memoryStore) ListGroups() ([]participantGroup, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return sortedValues(m.groups), nil
+}
+
+// GetGroup implements Store
+func (m *memoryStore) GetGroup(name string) (participantGroup, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    g, ok := m.groups[strings.ToLower(name)]
+    if !ok {
+        return participantGroup{}, errNotFound
+    }
+    return g, nil
+}
+
+// PutGroup implements Store
+func (m *memoryStore) PutGroup(g participantGroup) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.groups[strings.ToLower(g.Name)] = g
+    return nil
+}
+
+// DeleteGroup implements Store
+func (m *memoryStore) DeleteGroup(name string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if _, ok := m.groups[strings.ToLower(name)]; !ok {
+        return errNotFound
+    }
+    delete(m.groups, strings.ToLower(name))
+    return nil
+}
+
+// ListCalendars implements Store
+func (m *memoryStore) ListCalendars() ([]holidayCalendar, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return sortedValues(m.calendars), nil
+}
+
+// GetCalendar implements Store
+func (m *memoryStore) GetCalendar(name string) (holidayCalendar, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    c, ok := m.calendars[strings.ToLower(name)]
+    if !ok {
+        return holidayCalendar{}, errNotFound
+    }
+    return c, nil
+}
+
+// PutCalendar implements Store
+func (m *memoryStore) PutCalendar(c holidayCalendar) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.calendars[strings.ToLower(c.Name)] = c
+    return nil
+}
+
+// DeleteCalendar implements Store
+func (m *memoryStore) DeleteCalendar(name string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if _, ok := m.calendars[strings.ToLower(name)]; !ok {
+        return errNotFound
+    }
+    delete(m.calendars, strings.ToLower(name))
+    return nil
+}
+
+// ListSchedules implements Store
+func (m *memoryStore) ListSchedules() ([]webhookSchedule, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return sortedValues(m.schedules), nil
+}
+
+// GetSchedule implements Store
+func (m *memoryStore) GetSchedule(id string) (webhookSchedule, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    s, ok := m.schedules[strings.ToLower(id)]
+    if !ok {
+        return webhookSchedule{}, errNotFound
+    }
+    return s, nil
+}
+
+// PutSchedule implements Store
+func (m *memoryStore) PutSchedule(s webhookSchedule) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.schedules[strings.ToLower(s.ID)] = s
+    return nil
+}
+
+// UpdateSchedule implements Store
+func (m *memoryStore) UpdateSchedule(id string, fn func(*webhookSchedule) error) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    s, ok := m.schedules[strings.ToLower(id)]
+    if !ok {
+        return errNotFound
+    }
+    if err := fn(&s); err != nil {
+        return err
+    }
+    m.schedules[strings.ToLower(id)] = s
+    return nil
+}
+
+// CreateSchedule implements Store
+func (m *memoryStore) CreateSchedule(s webhookSchedule, maxActive int) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    active := 0
+    for _, other := range m.schedules {
+        if other.Owner == s.Owner && other.Status == scheduleActive {
+            active++
+        }
+    }
+    if active >= maxActive {
+        return errLimitReached
+    }
+    m.schedules[strings.ToLower(s.ID)] = s
+    return nil
+}
+
+// ListDueSchedules implements Store
+func (m *memoryStore) ListDueSchedules(now time.Time) ([]webhookSchedule, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    var out []webhookSchedule
+    for _, s := range sortedValues(m.schedules) {
+        if due, ok := s.dueAt(); ok && s.Status == scheduleActive && !due.After(now) {
+            out = append(out, s)
+        }
+    }
+    return out, nil
+}
+
+// PruneSchedules implements Store
+func (m *memoryStore) PruneSchedules(before time.Time) (int, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    n := 0
+    for id, s := range m.schedules {
+        if at, ok := s.finishedAt(); ok && s.Status != scheduleActive && at.Before(before) {
+            delete(m.schedules, id)
+            n++
+        }
+    }
+    return n, nil
+}
+
+// DeleteSchedule implements Store
+func (m *memoryStore) DeleteSchedule(id string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if _, ok := m.schedules[strings.ToLower(id)]; !ok {
+        return errNotFound
+    }
+    delete(m.schedules, strings.ToLower(id))
+    return nil
+}
+
+// Persistent implements Store
+func (m *memoryStore) Persistent() bool { return false }
+
+// Ping always succeeds for the in-memory store
+func (m *memoryStore) Ping() error { return nil }
+
+// Close implements Store
+func (m *memoryStore) Close() error { return nil }
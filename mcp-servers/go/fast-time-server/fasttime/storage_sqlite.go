@@ -0,0 +1,496 @@
+// -*- coding: utf-8 -*-
+// storage_sqlite.go - SQLite backend for the persistence layer
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements Store on top of SQLite using the pure Go
+// modernc.org/sqlite driver, so the binary still builds without cgo. The
+// schema is managed by numbered migrations recorded in schema_migrations;
+// each migration runs once, in order, inside a transaction. Append new
+// migrations to sqliteMigrations and never edit released ones.
+
+package fasttime
+
+import (
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    _ "modernc.org/sqlite" // Pure Go SQLite driver
+)
+
+// sqliteMigrations are applied in order; migration i has version i+1
+var sqliteMigrations = []string{
+    // 1: timezone aliases (compatible with databases created before migrations)
+    `CREATE TABLE IF NOT EXISTS timezone_aliases (
+        name       TEXT PRIMARY KEY COLLATE NOCASE,
+        timezone   TEXT NOT NULL,
+        updated_at TEXT NOT NULL
+    )`,
+    // 2: saved meeting participant groups; timezones is a JSON array
+    `CREATE TABLE participant_groups (
+        name          TEXT PRIMARY KEY COLLATE NOCASE,
+        timezones     TEXT NOT NULL,
+        working_hours TEXT NOT NULL DEFAULT '',
+        updated_at    TEXT NOT NULL
+    )`,
+    // 3: custom holiday calendars
+    `CREATE TABLE holiday_calendars (
+        name       TEXT PRIMARY KEY COLLATE NOCASE,
+        updated_at TEXT NOT NULL
+    );
+    CREATE TABLE holidays (
+        calendar TEXT NOT NULL COLLATE NOCASE,
+        date     TEXT NOT NULL,
+        name     TEXT NOT NULL DEFAULT '',
+        PRIMARY KEY (calendar, date)
+    )`,
+    // 4: webhook schedules; the schedule itself is kept as JSON, with the
+    // fields the scheduler selects on in their own columns
+    `CREATE TABLE webhook_schedules (
+        id         TEXT PRIMARY KEY COLLATE NOCASE,
+        owner      TEXT NOT NULL DEFAULT '',
+        status     TEXT NOT NULL,
+        next_run   TEXT NOT NULL DEFAULT '',
+        data       TEXT NOT NULL,
+        updated_at TEXT NOT NULL
+    );
+    CREATE INDEX webhook_schedules_status ON webhook_schedules (status, next_run)`,
+    // 5: when each active schedule is due, in UTC so that it sorts (next_run
+    // carries the schedule's own offset), so the scheduler reads only due
+    // rows; and an index for the per-owner quota
+    `ALTER TABLE webhook_schedules ADD COLUMN due_at TEXT NOT NULL DEFAULT '';
+    UPDATE webhook_schedules SET due_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ',
+        COALESCE(NULLIF(json_extract(data, '$.retry_at'), ''), next_run)), '')
+        WHERE status = 'scheduled';
+    CREATE INDEX webhook_schedules_due ON webhook_schedules (status, due_at);
+    CREATE INDEX webhook_schedules_owner ON webhook_schedules (owner, status)`,
+}
+
+// sqliteStore is a Store backed by a SQLite database file
+type sqliteStore struct {
+    db   *sql.DB
+    path string
+}
+
+// openSQLiteStore opens (creating if needed) the database at path and
+// brings its schema up to date
+func openSQLiteStore(path string) (*sqliteStore, error) {
+    db, err := sql.Open("sqlite", path)
+    if err != nil {
+        return nil, err
+    }
+    db.SetMaxOpenConns(1) // SQLite allows a single writer; serialise access
+    st := &sqliteStore{db: db, path: path}
+    if err := st.migrate(); err != nil {
+        _ = db.Close()
+        return nil, fmt.Errorf("migrate %s: %w", path, err)
+    }
+    return st, nil
+}
+
+// schemaVersion returns the number of applied migrations
+func (s *sqliteStore) schemaVersion() (int, error) {
+    var v sql.NullInt64
+    err := s.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&v)
+    return int(v.Int64), err
+}
+
+// migrate applies pending migrations
+func (s *sqliteStore) migrate() error {
+    if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+        version    INTEGER PRIMARY KEY,
+        applied_at TEXT NOT NULL
+    )`); err != nil {
+        return err
+    }
+    current, err := s.schemaVersion()
+    if err != nil {
+        return err
+    }
+    if current > len(sqliteMigrations) {
+        return fmt.Errorf("database schema version %d is newer than this binary supports (%d)", current, len(sqliteMigrations))
+    }
+
+    for i := current; i < len(sqliteMigrations); i++ {
+        tx, err := s.db.Begin()
+        if err != nil {
+            return err
+        }
+        if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+            _ = tx.Rollback()
+            return fmt.Errorf("migration %d: %w", i+1, err)
+        }
+        if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+            i+1, time.Now().UTC().Format(time.RFC3339)); err != nil {
+            _ = tx.Rollback()
+            return err
+        }
+        if err := tx.Commit(); err != nil {
+            return err
+        }
+        logAt(logInfo, "storage: applied migration %d to %s", i+1, s.path)
+    }
+    return nil
+}
+
+// deleteByName runs a DELETE and maps "no rows" to errNotFound
+func (s *sqliteStore) deleteByName(query, name string) error {
+    res, err := s.db.Exec(query, name)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return errNotFound
+    }
+    return nil
+}
+
+// ListAliases implements Store
+func (s *sqliteStore) ListAliases() ([]tzAlias, error) {
+    rows, err := s.db.Query(`SELECT name, timezone, updated_at FROM timezone_aliases ORDER BY name`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var out []tzAlias
+    for rows.Next() {
+        a := tzAlias{Source: aliasSourceAPI}
+        if err := rows.Scan(&a.Name, &a.Timezone, &a.UpdatedAt); err != nil {
+            return nil, err
+        }
+        out = append(out, a)
+    }
+    return out, rows.Err()
+}
+
+// PutAlias implements Store
+func (s *sqliteStore) PutAlias(a tzAlias) error {
+    _, err := s.db.Exec(`INSERT INTO timezone_aliases (name, timezone, updated_at) VALUES (?, ?, ?)
+        ON CONFLICT(name) DO UPDATE SET name = excluded.name, timezone = excluded.timezone, updated_at = excluded.updated_at`,
+        a.Name, a.Timezone, a.UpdatedAt)
+    return err
+}
+
+// DeleteAlias implements Store
+func (s *sqliteStore) DeleteAlias(name string) error {
+    return s.deleteByName(`DELETE FROM timezone_aliases WHERE name = ?`, name)
+}
+
+// scanGroup reads one participant_groups row
+func scanGroup(row interface{ Scan(...any) error }) (participantGroup, error) {
+    var g participantGroup
+    var zones string
+    if err := row.Scan(&g.Name, &zones, &g.WorkingHours, &g.UpdatedAt); err != nil {
+        return g, err
+    }
+    if err := json.Unmarshal([]byte(zones), &g.Timezones); err != nil {
+        return g, fmt.Errorf("group %s: %w", g.Name, err)
+    }
+    return g, nil
+}
+
+// ListGroups implements Store
+func (s *sqliteStore) ListGroups() ([]participantGroup, error) {
+    rows, err := s.db.Query(`SELECT name, timezones, working_hours, updated_at FROM participant_groups ORDER BY name`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var out []participantGroup
+    for rows.Next() {
+        g, err := scanGroup(rows)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, g)
+    }
+    return out, rows.Err()
+}
+
+// GetGroup implements Store
+func (s *sqliteStore) GetGroup(name string) (participantGroup, error) {
+    g, err := scanGroup(s.db.QueryRow(
+        `SELECT name, timezones, working_hours, updated_at FROM participant_groups WHERE name = ?`, name))
+    if errors.Is(err, sql.ErrNoRows) {
+        return g, errNotFound
+    }
+    return g, err
+}
+
+// PutGroup implements Store
+func (s *sqliteStore) PutGroup(g participantGroup) error {
+    zones, err := json.Marshal(g.Timezones)
+    if err != nil {
+        return err
+    }
+    _, err = s.db.Exec(`INSERT INTO participant_groups (name, timezones, working_hours, updated_at) VALUES (?, ?, ?, ?)
+        ON CONFLICT(name) DO UPDATE SET name = excluded.name, timezones = excluded.timezones,
+            working_hours = excluded.working_hours, updated_at = excluded.updated_at`,
+        g.Name, string(zones), g.WorkingHours, g.UpdatedAt)
+    return err
+}
+
+// DeleteGroup implements Store
+func (s *sqliteStore) DeleteGroup(name string) error {
+    return s.deleteByName(`DELETE FROM participant_groups WHERE name = ?`, name)
+}
+
+// calendarHolidays loads the dates of one calendar
+func (s *sqliteStore) calendarHolidays(name string) ([]holiday, error) {
+    rows, err := s.db.Query(`SELECT date, name FROM holidays WHERE calendar = ? ORDER BY date`, name)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    out := []holiday{}
+    for rows.Next() {
+        var h holiday
+        if err := rows.Scan(&h.Date, &h.Name); err != nil {
+            return nil, err
+        }
+        out = append(out, h)
+    }
+    return out, rows.Err()
+}
+
+// ListCalendars implements Store
+func (s *sqliteStore) ListCalendars() ([]holidayCalendar, error) {
+    rows, err := s.db.Query(`SELECT name, updated_at FROM holiday_calendars ORDER BY name`)
+    if err != nil {
+        return nil, err
+    }
+    var out []holidayCalendar
+    for rows.Next() {
+        var c holidayCalendar
+        if err := rows.Scan(&c.Name, &c.UpdatedAt); err != nil {
+            rows.Close()
+            return nil, err
+        }
+        out = append(out, c)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    // Load dates after closing the cursor: the pool holds a single connection
+    for i := range out {
+        if out[i].Holidays, err = s.calendarHolidays(out[i].Name); err != nil {
+            return nil, err
+        }
+    }
+    return out, nil
+}
+
+// GetCalendar implements Store
+func (s *sqliteStore) GetCalendar(name string) (holidayCalendar, error) {
+    var c holidayCalendar
+    err := s.db.QueryRow(`SELECT name, updated_at FROM holiday_calendars WHERE name = ?`, name).Scan(&c.Name, &c.UpdatedAt)
+    if errors.Is(err, sql.ErrNoRows) {
+        return c, errNotFound
+    }
+    if err != nil {
+        return c, err
+    }
+    c.Holidays, err = s.calendarHolidays(c.Name)
+    return c, err
+}
+
+// PutCalendar implements Store
+func (s *sqliteStore) PutCalendar(c holidayCalendar) error {
+    tx, err := s.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer func() { _ = tx.Rollback() }()
+
+    if _, err := tx.Exec(`INSERT INTO holiday_calendars (name, updated_at) VALUES (?, ?)
+        ON CONFLICT(name) DO UPDATE SET name = excluded.name, updated_at = excluded.updated_at`,
+        c.Name, c.UpdatedAt); err != nil {
+        return err
+    }
+    if _, err := tx.Exec(`DELETE FROM holidays WHERE calendar = ?`, c.Name); err != nil {
+        return err
+    }
+    for _, h := range c.Holidays {
+        if _, err := tx.Exec(`INSERT INTO holidays (calendar, date, name) VALUES (?, ?, ?)`, c.Name, h.Date, h.Name); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// DeleteCalendar implements Store
+func (s *sqliteStore) DeleteCalendar(name string) error {
+    tx, err := s.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer func() { _ = tx.Rollback() }()
+
+    res, err := tx.Exec(`DELETE FROM holiday_calendars WHERE name = ?`, name)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return errNotFound
+    }
+    if _, err := tx.Exec(`DELETE FROM holidays WHERE calendar = ?`, name); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// scanSchedule reads the data column of one webhook_schedules row
+func scanSchedule(row interface{ Scan(...any) error }) (webhookSchedule, error) {
+    var ws webhookSchedule
+    var data string
+    if err := row.Scan(&data); err != nil {
+        return ws, err
+    }
+    if err := json.Unmarshal([]byte(data), &ws); err != nil {
+        return ws, fmt.Errorf("schedule: %w", err)
+    }
+    return ws, nil
+}
+
+// ListSchedules implements Store
+func (s *sqliteStore) ListSchedules() ([]webhookSchedule, error) {
+    return s.querySchedules(`SELECT data FROM webhook_schedules ORDER BY id`)
+}
+
+// ListDueSchedules implements Store
+func (s *sqliteStore) ListDueSchedules(now time.Time) ([]webhookSchedule, error) {
+    return s.querySchedules(`SELECT data FROM webhook_schedules WHERE status = ? AND due_at != '' AND due_at <= ? ORDER BY due_at`,
+        scheduleActive, now.UTC().Format(time.RFC3339))
+}
+
+// querySchedules returns the schedules selected by query
+func (s *sqliteStore) querySchedules(query string, args ...any) ([]webhookSchedule, error) {
+    rows, err := s.db.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    var out []webhookSchedule
+    for rows.Next() {
+        ws, err := scanSchedule(rows)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, ws)
+    }
+    return out, rows.Err()
+}
+
+// GetSchedule implements Store
+func (s *sqliteStore) GetSchedule(id string) (webhookSchedule, error) {
+    ws, err := scanSchedule(s.db.QueryRow(`SELECT data FROM webhook_schedules WHERE id = ?`, id))
+    if errors.Is(err, sql.ErrNoRows) {
+        return ws, errNotFound
+    }
+    return ws, err
+}
+
+// PutSchedule implements Store
+func (s *sqliteStore) PutSchedule(ws webhookSchedule) error {
+    return putSchedule(s.db, ws)
+}
+
+// putSchedule writes ws with db, the database or a transaction
+func putSchedule(db interface {
+    Exec(query string, args ...any) (sql.Result, error)
+}, ws webhookSchedule) error {
+    data, err := json.Marshal(ws)
+    if err != nil {
+        return err
+    }
+    dueAt := ""
+    if due, ok := ws.dueAt(); ok && ws.Status == scheduleActive {
+        dueAt = due.UTC().Format(time.RFC3339)
+    }
+    _, err = db.Exec(`INSERT INTO webhook_schedules (id, owner, status, next_run, due_at, data, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(id) DO UPDATE SET owner = excluded.owner, status = excluded.status, next_run = excluded.next_run,
+            due_at = excluded.due_at, data = excluded.data, updated_at = excluded.updated_at`,
+        ws.ID, ws.Owner, ws.Status, ws.NextRun, dueAt, string(data), time.Now().UTC().Format(time.RFC3339))
+    return err
+}
+
+// CreateSchedule implements Store
+func (s *sqliteStore) CreateSchedule(ws webhookSchedule, maxActive int) error {
+    tx, err := s.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer func() { _ = tx.Rollback() }()
+
+    var active int
+    if err := tx.QueryRow(`SELECT COUNT(*) FROM webhook_schedules WHERE owner = ? AND status = ?`,
+        ws.Owner, scheduleActive).Scan(&active); err != nil {
+        return err
+    }
+    if active >= maxActive {
+        return errLimitReached
+    }
+    if err := putSchedule(tx, ws); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// PruneSchedules implements Store; schedules finished before finished_at
+// was recorded count from their last write
+func (s *sqliteStore) PruneSchedules(before time.Time) (int, error) {
+    res, err := s.db.Exec(`DELETE FROM webhook_schedules WHERE status != ?
+        AND COALESCE(NULLIF(json_extract(data, '$.finished_at'), ''), updated_at) < ?`,
+        scheduleActive, before.UTC().Format(time.RFC3339))
+    if err != nil {
+        return 0, err
+    }
+    n, err := res.RowsAffected()
+    return int(n), err
+}
+
+// UpdateSchedule implements Store
+func (s *sqliteStore) UpdateSchedule(id string, fn func(*webhookSchedule) error) error {
+    tx, err := s.db.Begin()
+    if err != nil {
+        return err
+    }
+    defer func() { _ = tx.Rollback() }()
+
+    ws, err := scanSchedule(tx.QueryRow(`SELECT data FROM webhook_schedules WHERE id = ?`, id))
+    if errors.Is(err, sql.ErrNoRows) {
+        return errNotFound
+    }
+    if err != nil {
+        return err
+    }
+    if err := fn(&ws); err != nil {
+        return err
+    }
+    if err := putSchedule(tx, ws); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// DeleteSchedule implements Store
+func (s *sqliteStore) DeleteSchedule(id string) error {
+    return s.deleteByName(`DELETE FROM webhook_schedules WHERE id = ?`, id)
+}
+
+// Persistent implements Store
+func (s *sqliteStore) Persistent() bool { return true }
+
+// Ping checks that the database file is still usable
+func (s *sqliteStore) Ping() error {
+    _, err := s.schemaVersion()
+    return err
+}
+
+// Close implements Store
+func (s *sqliteStore) Close() error { return s.db.Close() }
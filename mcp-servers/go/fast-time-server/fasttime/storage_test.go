@@ -0,0 +1,228 @@
+// -*- coding: utf-8 -*-
+// storage_test.go - tests for the persistence layer
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "path/filepath"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// useTestStore swaps the process-wide store for the duration of a test
+func useTestStore(t *testing.T, st Store) {
+    t.Helper()
+    old := store
+    store = st
+    t.Cleanup(func() { store = old })
+}
+
+// exerciseStore runs the same checks against any Store implementation
+func exerciseStore(t *testing.T, st Store) {
+    t.Helper()
+
+    // Aliases
+    if err := st.PutAlias(tzAlias{Name: "HQ", Timezone: "Europe/Berlin", UpdatedAt: "2025-01-01T00:00:00Z"}); err != nil {
+        t.Fatalf("PutAlias: %v", err)
+    }
+    if err := st.PutAlias(tzAlias{Name: "hq", Timezone: "Europe/Paris", UpdatedAt: "2025-01-02T00:00:00Z"}); err != nil {
+        t.Fatalf("PutAlias replace: %v", err)
+    }
+    aliases, err := st.ListAliases()
+    if err != nil || len(aliases) != 1 || aliases[0].Timezone != "Europe/Paris" {
+        t.Fatalf("ListAliases = %+v, %v", aliases, err)
+    }
+    if err := st.DeleteAlias("HQ"); err != nil {
+        t.Fatalf("DeleteAlias: %v", err)
+    }
+    if err := st.DeleteAlias("HQ"); !errors.Is(err, errNotFound) {
+        t.Errorf("second DeleteAlias = %v, want errNotFound", err)
+    }
+
+    // Participant groups
+    g := participantGroup{Name: "core", Timezones: []string{"UTC", "Asia/Tokyo"}, WorkingHours: "08:00-16:00", UpdatedAt: "2025-01-01T00:00:00Z"}
+    if err := st.PutGroup(g); err != nil {
+        t.Fatalf("PutGroup: %v", err)
+    }
+    got, err := st.GetGroup("CORE")
+    if err != nil || len(got.Timezones) != 2 || got.WorkingHours != "08:00-16:00" {
+        t.Fatalf("GetGroup = %+v, %v", got, err)
+    }
+    if _, err := st.GetGroup("missing"); !errors.Is(err, errNotFound) {
+        t.Errorf("GetGroup(missing) = %v, want errNotFound", err)
+    }
+    if groups, _ := st.ListGroups(); len(groups) != 1 {
+        t.Errorf("ListGroups = %+v", groups)
+    }
+    if err := st.DeleteGroup("core"); err != nil {
+        t.Fatalf("DeleteGroup: %v", err)
+    }
+
+    // Holiday calendars
+    c := holidayCalendar{Name: "acme", UpdatedAt: "2025-01-01T00:00:00Z", Holidays: []holiday{
+        {Date: "2025-12-24", Name: "Christmas Eve"},
+        {Date: "2025-12-31", Name: "New Year's Eve"},
+    }}
+    if err := st.PutCalendar(c); err != nil {
+        t.Fatalf("PutCalendar: %v", err)
+    }
+    c.Holidays = c.Holidays[:1]
+    if err := st.PutCalendar(c); err != nil {
+        t.Fatalf("PutCalendar replace: %v", err)
+    }
+    gotCal, err := st.GetCalendar("ACME")
+    if err != nil || len(gotCal.Holidays) != 1 || gotCal.Holidays[0].Name != "Christmas Eve" {
+        t.Fatalf("GetCalendar = %+v, %v", gotCal, err)
+    }
+    if cals, _ := st.ListCalendars(); len(cals) != 1 || len(cals[0].Holidays) != 1 {
+        t.Errorf("ListCalendars = %+v", cals)
+    }
+    if err := st.DeleteCalendar("acme"); err != nil {
+        t.Fatalf("DeleteCalendar: %v", err)
+    }
+    if _, err := st.GetCalendar("acme"); !errors.Is(err, errNotFound) {
+        t.Errorf("GetCalendar after delete = %v, want errNotFound", err)
+    }
+
+    // Webhook schedules
+    sch := webhookSchedule{ID: "sch_1", URL: "https://example.com/hook", Cron: "0 9 * * *", Timezone: "UTC",
+        Payload: []byte(`{"job":"x"}`), Status: scheduleActive, NextRun: "2025-01-02T09:00:00Z", Owner: "ci"}
+    if err := st.PutSchedule(sch); err != nil {
+        t.Fatalf("PutSchedule: %v", err)
+    }
+    sch.Runs, sch.NextRun = 1, "2025-01-03T09:00:00Z"
+    if err := st.PutSchedule(sch); err != nil {
+        t.Fatalf("PutSchedule replace: %v", err)
+    }
+    gotSch, err := st.GetSchedule("sch_1")
+    if err != nil || gotSch.Runs != 1 || gotSch.NextRun != "2025-01-03T09:00:00Z" || string(gotSch.Payload) != `{"job":"x"}` {
+        t.Fatalf("GetSchedule = %+v, %v", gotSch, err)
+    }
+    if list, _ := st.ListSchedules(); len(list) != 1 || list[0].Owner != "ci" {
+        t.Errorf("ListSchedules = %+v", list)
+    }
+    if err := st.UpdateSchedule("sch_1", func(s *webhookSchedule) error { s.Runs++; return nil }); err != nil {
+        t.Fatalf("UpdateSchedule: %v", err)
+    }
+    failed := errors.New("no")
+    if err := st.UpdateSchedule("sch_1", func(s *webhookSchedule) error { s.Runs = 99; return failed }); err != failed {
+        t.Errorf("UpdateSchedule with a failing fn = %v", err)
+    }
+    if got, _ := st.GetSchedule("sch_1"); got.Runs != 2 {
+        t.Errorf("Runs after updates = %d, want 2", got.Runs)
+    }
+    if err := st.DeleteSchedule("sch_1"); err != nil {
+        t.Fatalf("DeleteSchedule: %v", err)
+    }
+    if _, err := st.GetSchedule("sch_1"); !errors.Is(err, errNotFound) {
+        t.Errorf("GetSchedule after delete = %v, want errNotFound", err)
+    }
+    if err := st.UpdateSchedule("sch_1", func(*webhookSchedule) error { return nil }); !errors.Is(err, errNotFound) {
+        t.Errorf("UpdateSchedule after delete = %v, want errNotFound", err)
+    }
+
+    // Due schedules, the per-owner quota and pruning
+    for _, s := range []webhookSchedule{
+        {ID: "sch_done", Status: scheduleCompleted, FinishedAt: "2025-01-01T00:00:00Z", Owner: "ci"},
+        {ID: "sch_failed", Status: scheduleFailed, FinishedAt: "2025-01-05T00:00:00Z", Owner: "ci"},
+        {ID: "sch_due", Status: scheduleActive, NextRun: "2025-01-02T09:00:00+01:00", Owner: "ci"},
+        {ID: "sch_retry", Status: scheduleActive, NextRun: "2025-01-01T00:00:00Z", RetryAt: "2025-01-02T07:30:00Z", Owner: "ci"},
+        {ID: "sch_later", Status: scheduleActive, NextRun: "2025-01-02T09:00:00Z", Owner: "ops"},
+    } {
+        if err := st.CreateSchedule(s, 2); err != nil {
+            t.Fatalf("CreateSchedule(%s): %v", s.ID, err)
+        }
+    }
+    if err := st.CreateSchedule(webhookSchedule{ID: "sch_over", Status: scheduleActive, Owner: "ci"}, 2); !errors.Is(err, errLimitReached) {
+        t.Errorf("CreateSchedule over the quota = %v, want errLimitReached", err)
+    }
+    due, err := st.ListDueSchedules(time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC))
+    if err != nil || len(due) != 2 || due[0].ID == due[1].ID || (due[0].ID != "sch_due" && due[0].ID != "sch_retry") {
+        t.Errorf("ListDueSchedules = %+v, %v", due, err)
+    }
+    if n, err := st.PruneSchedules(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)); err != nil || n != 1 {
+        t.Errorf("PruneSchedules = %d, %v; want 1", n, err)
+    }
+    if _, err := st.GetSchedule("sch_done"); !errors.Is(err, errNotFound) {
+        t.Errorf("pruned schedule still there: %v", err)
+    }
+    if list, _ := st.ListSchedules(); len(list) != 4 {
+        t.Errorf("after pruning %d schedules are left, want 4", len(list))
+    }
+}
+
+func TestMemoryStore(t *testing.T) {
+    st := newMemoryStore()
+    exerciseStore(t, st)
+    if st.Persistent() {
+        t.Error("memory store should not be persistent")
+    }
+}
+
+func TestCreateScheduleQuotaIsAtomic(t *testing.T) {
+    st := newMemoryStore()
+    var created atomic.Int32
+    var wg sync.WaitGroup
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            if st.CreateSchedule(webhookSchedule{ID: fmt.Sprintf("sch_%d", i), Status: scheduleActive, Owner: "ci"}, 5) == nil {
+                created.Add(1)
+            }
+        }(i)
+    }
+    wg.Wait()
+    if n := created.Load(); n != 5 {
+        t.Errorf("%d schedules created concurrently, want the quota of 5", n)
+    }
+}
+
+func TestSQLiteStore(t *testing.T) {
+    st, err := openSQLiteStore(filepath.Join(t.TempDir(), "fast-time.sqlite"))
+    if err != nil {
+        t.Fatalf("openSQLiteStore: %v", err)
+    }
+    defer st.Close()
+    exerciseStore(t, st)
+    if !st.Persistent() {
+        t.Error("SQLite store should be persistent")
+    }
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "fast-time.sqlite")
+    st, err := openSQLiteStore(path)
+    if err != nil {
+        t.Fatalf("open: %v", err)
+    }
+    if v, _ := st.schemaVersion(); v != len(sqliteMigrations) {
+        t.Errorf("schema version = %d, want %d", v, len(sqliteMigrations))
+    }
+    if err := st.PutAlias(tzAlias{Name: "HQ", Timezone: "Europe/Berlin", UpdatedAt: "x"}); err != nil {
+        t.Fatal(err)
+    }
+
+    // Simulate a database written by a newer binary
+    if _, err := st.db.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, 'x')`, len(sqliteMigrations)+1); err != nil {
+        t.Fatal(err)
+    }
+    st.Close()
+    if _, err := openSQLiteStore(path); err == nil {
+        t.Error("opening a newer schema should fail")
+    }
+}
+
+func TestOpenStore(t *testing.T) {
+    st, err := openStore("")
+    if err != nil || st.Persistent() {
+        t.Fatalf("openStore(\"\") = %T, %v; want memory store", st, err)
+    }
+}
@@ -0,0 +1,225 @@
+// -*- coding: utf-8 -*-
+// subscriptions.go - MCP resource subscriptions with push updates
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements resources/subscribe and resources/unsubscribe for the
+// SSE transport and periodically pushes notifications/resources/updated for
+// time://current/world to subscribed sessions. mcp-go does not dispatch the
+// subscribe methods itself, so they are intercepted on the SSE message
+// endpoint and answered over the session's event stream. Each update carries
+// the standard uri field plus the fresh contents, so clients do not need a
+// follow-up read.
+
+package fasttime
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// worldTimesURI is the resource pushed to subscribers
+const worldTimesURI = "time://current/world"
+
+// JSON-RPC methods handled by subscribeMiddleware
+const (
+    methodResourcesSubscribe   = "resources/subscribe"
+    methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// maxSubscribeBody bounds the JSON-RPC body inspected by the interceptor
+const maxSubscribeBody = 1 << 20
+
+// subscribableResources maps URIs that support subscriptions to their read handlers
+var subscribableResources = map[string]server.ResourceHandlerFunc{
+    worldTimesURI: handleCurrentWorldTimes,
+}
+
+// resourceSubscriptions records which sessions subscribe to which URIs
+type resourceSubscriptions struct {
+    mu        sync.Mutex
+    bySession map[string]map[string]bool
+}
+
+// resourceSubs is the process-wide subscription registry
+var resourceSubs = newResourceSubscriptions()
+
+// newResourceSubscriptions creates an empty registry
+func newResourceSubscriptions() *resourceSubscriptions {
+    return &resourceSubscriptions{bySession: make(map[string]map[string]bool)}
+}
+
+// subscribe adds uri to the session's subscriptions
+func (rs *resourceSubscriptions) subscribe(sessionID, uri string) {
+    rs.mu.Lock()
+    defer rs.mu.Unlock()
+    if rs.bySession[sessionID] == nil {
+        rs.bySession[sessionID] = make(map[string]bool)
+    }
+    rs.bySession[sessionID][uri] = true
+}
+
+// unsubscribe removes uri from the session's subscriptions
+func (rs *resourceSubscriptions) unsubscribe(sessionID, uri string) {
+    rs.mu.Lock()
+    defer rs.mu.Unlock()
+    delete(rs.bySession[sessionID], uri)
+    if len(rs.bySession[sessionID]) == 0 {
+        delete(rs.bySession, sessionID)
+    }
+}
+
+// dropSession forgets every subscription held by a session
+func (rs *resourceSubscriptions) dropSession(sessionID string) {
+    rs.mu.Lock()
+    delete(rs.bySession, sessionID)
+    rs.mu.Unlock()
+}
+
+// countFor returns the number of resources a session is subscribed to
+func (rs *resourceSubscriptions) countFor(sessionID string) int {
+    rs.mu.Lock()
+    defer rs.mu.Unlock()
+    return len(rs.bySession[sessionID])
+}
+
+// subscribers returns the sessions subscribed to uri in sorted order
+func (rs *resourceSubscriptions) subscribers(uri string) []string {
+    rs.mu.Lock()
+    defer rs.mu.Unlock()
+    var out []string
+    for id, uris := range rs.bySession {
+        if uris[uri] {
+            out = append(out, id)
+        }
+    }
+    sort.Strings(out)
+    return out
+}
+
+// subscribeRequest is the subset of a JSON-RPC request the interceptor needs
+type subscribeRequest struct {
+    JSONRPC string          `json:"jsonrpc"`
+    ID      mcp.RequestId   `json:"id"`
+    Method  string          `json:"method"`
+    Params  json.RawMessage `json:"params"`
+}
+
+// subscribeMiddleware answers resources/subscribe and resources/unsubscribe
+// posted to the SSE message endpoint; every other request passes through
+func subscribeMiddleware(sse *server.SSEServer, next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        sessionID := r.URL.Query().Get("sessionId")
+        if r.Method != http.MethodPost || sessionID == "" {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        body, err := io.ReadAll(io.LimitReader(r.Body, maxSubscribeBody))
+        _ = r.Body.Close()
+        if err != nil {
+            writeJSONError(w, http.StatusBadRequest, "Failed to read request body")
+            return
+        }
+        r.Body = io.NopCloser(bytes.NewReader(body))
+
+        var req subscribeRequest
+        if json.Unmarshal(body, &req) != nil ||
+            (req.Method != methodResourcesSubscribe && req.Method != methodResourcesUnsubscribe) {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        var params mcp.SubscribeParams
+        _ = json.Unmarshal(req.Params, &params)
+
+        var resp any
+        switch _, ok := subscribableResources[params.URI]; {
+        case !ok || !features.allowed(featureResource, params.URI) || checkCallerScope(r.Context(), featureResource, params.URI) != nil:
+            resp = mcp.NewJSONRPCError(req.ID, mcp.INVALID_PARAMS, "resource does not support subscriptions: "+params.URI, nil)
+        case req.Method == methodResourcesSubscribe:
+            resourceSubs.subscribe(sessionID, params.URI)
+            logAt(logInfo, "session %s subscribed to %s", sessionID, params.URI)
+            resp = mcp.NewJSONRPCResponse(req.ID, mcp.Result{})
+        default:
+            resourceSubs.unsubscribe(sessionID, params.URI)
+            logAt(logInfo, "session %s unsubscribed from %s", sessionID, params.URI)
+            resp = mcp.NewJSONRPCResponse(req.ID, mcp.Result{})
+        }
+
+        // Like the SSE server itself, acknowledge the POST and reply on the stream
+        if err := sse.SendEventToSession(sessionID, resp); err != nil {
+            resourceSubs.dropSession(sessionID)
+            writeJSONError(w, http.StatusNotFound, "Invalid session ID")
+            return
+        }
+        w.WriteHeader(http.StatusAccepted)
+    })
+}
+
+// nextPushDelay returns how long to wait before the next push; a zero
+// interval aligns pushes to minute boundaries
+func nextPushDelay(now time.Time, interval time.Duration) time.Duration {
+    if interval > 0 {
+        return interval
+    }
+    return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
+
+// pushResourceUpdates sends the current contents of uri to every subscriber
+func pushResourceUpdates(ctx context.Context, s *server.MCPServer, uri string) int {
+    ids := resourceSubs.subscribers(uri)
+    if len(ids) == 0 {
+        return 0
+    }
+
+    req := mcp.ReadResourceRequest{}
+    req.Params.URI = uri
+    contents, err := subscribableResources[uri](ctx, req)
+    if err != nil {
+        logAt(logError, "resource push: failed to read %s: %v", uri, err)
+        return 0
+    }
+
+    sent := 0
+    for _, id := range ids {
+        err := s.SendNotificationToSpecificClient(id, string(mcp.MethodNotificationResourceUpdated), map[string]any{
+            "uri":      uri,
+            "contents": contents,
+        })
+        if err != nil {
+            logAt(logDebug, "resource push: dropping session %s: %v", id, err)
+            resourceSubs.dropSession(id)
+            continue
+        }
+        sent++
+    }
+    return sent
+}
+
+// runResourcePusher pushes subscribed resources until ctx is done
+func runResourcePusher(ctx context.Context, s *server.MCPServer, interval time.Duration) {
+    timer := time.NewTimer(nextPushDelay(time.Now(), interval))
+    defer timer.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-timer.C:
+            if n := pushResourceUpdates(ctx, s, worldTimesURI); n > 0 {
+                logAt(logDebug, "resource push: sent %s to %d session(s)", worldTimesURI, n)
+            }
+            timer.Reset(nextPushDelay(time.Now(), interval))
+        }
+    }
+}
@@ -0,0 +1,85 @@
+// -*- coding: utf-8 -*-
+// subscriptions_test.go - tests for resource subscriptions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestResourceSubscriptionsRegistry(t *testing.T) {
+    rs := newResourceSubscriptions()
+    rs.subscribe("b", worldTimesURI)
+    rs.subscribe("a", worldTimesURI)
+    rs.subscribe("a", "time://formats")
+
+    if got := rs.subscribers(worldTimesURI); !reflect.DeepEqual(got, []string{"a", "b"}) {
+        t.Fatalf("subscribers = %v, want [a b]", got)
+    }
+
+    rs.unsubscribe("b", worldTimesURI)
+    if got := rs.subscribers(worldTimesURI); !reflect.DeepEqual(got, []string{"a"}) {
+        t.Fatalf("after unsubscribe = %v, want [a]", got)
+    }
+    if _, ok := rs.bySession["b"]; ok {
+        t.Fatal("session without subscriptions should be removed")
+    }
+
+    rs.dropSession("a")
+    if got := rs.subscribers("time://formats"); len(got) != 0 {
+        t.Fatalf("after drop = %v, want none", got)
+    }
+}
+
+func TestNextPushDelay(t *testing.T) {
+    now := time.Date(2025, 6, 1, 12, 0, 45, 0, time.UTC)
+    if got := nextPushDelay(now, 0); got != 15*time.Second {
+        t.Errorf("minute-aligned delay = %v, want 15s", got)
+    }
+    if got := nextPushDelay(now, 5*time.Second); got != 5*time.Second {
+        t.Errorf("fixed delay = %v, want 5s", got)
+    }
+}
+
+func TestSubscribeMiddleware(t *testing.T) {
+    sse := server.NewSSEServer(server.NewMCPServer("test", "1.0"))
+    passed := false
+    h := subscribeMiddleware(sse, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        passed = true
+        w.WriteHeader(http.StatusTeapot)
+    }))
+
+    // Other methods reach the SSE server untouched
+    body := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+    rec := httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/message?sessionId=s1", strings.NewReader(body)))
+    if !passed || rec.Code != http.StatusTeapot {
+        t.Fatalf("tools/list was not passed through (code %d)", rec.Code)
+    }
+
+    // Subscribe for an unknown session is rejected without recording it
+    passed = false
+    body = `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"time://current/world"}}`
+    rec = httptest.NewRecorder()
+    h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/message?sessionId=missing", strings.NewReader(body)))
+    if passed {
+        t.Fatal("resources/subscribe should be intercepted")
+    }
+    if rec.Code != http.StatusNotFound {
+        t.Fatalf("code = %d, want 404", rec.Code)
+    }
+    if got := resourceSubs.subscribers(worldTimesURI); len(got) != 0 {
+        t.Fatalf("subscribers = %v, want none", got)
+    }
+}
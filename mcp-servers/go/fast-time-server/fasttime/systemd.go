@@ -0,0 +1,121 @@
+// -*- coding: utf-8 -*-
+// systemd.go - systemd socket activation and sd_notify support
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file lets fast-time-server run as a socket-activated systemd service:
+// when systemd passes a listening socket (LISTEN_PID/LISTEN_FDS) the HTTP
+// transports serve on it instead of binding -addr/-port themselves. Under
+// Type=notify the server reports READY=1 once it is serving and, when the unit
+// sets WatchdogSec=, sends WATCHDOG=1 at half the configured interval.
+//
+// Example units:
+//
+//   # fast-time-server.socket
+//   [Socket]
+//   ListenStream=8080
+//
+//   # fast-time-server.service
+//   [Service]
+//   Type=notify
+//   WatchdogSec=30s
+//   ExecStart=/usr/local/bin/fast-time-server -transport=sse
+//
+// Everything here is a no-op outside systemd.
+
+package fasttime
+
+import (
+    "net"
+    "os"
+    "strconv"
+    "time"
+)
+
+// sdListenFDsStart is the first file descriptor passed by systemd
+const sdListenFDsStart = 3
+
+// systemdListener returns the first socket passed by systemd, or nil when the
+// process was not socket activated
+func systemdListener() (net.Listener, error) {
+    pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+    if err != nil || pid != os.Getpid() {
+        return nil, nil
+    }
+    n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+    if err != nil || n < 1 {
+        return nil, nil
+    }
+    // Children must not believe the sockets are theirs
+    os.Unsetenv("LISTEN_PID")
+    os.Unsetenv("LISTEN_FDS")
+    os.Unsetenv("LISTEN_FDNAMES")
+
+    if n > 1 {
+        logAt(logWarn, "systemd passed %d sockets; serving on the first only", n)
+    }
+    f := os.NewFile(sdListenFDsStart, "LISTEN_FD_3")
+    defer f.Close() // FileListener dups the descriptor
+    return net.FileListener(f)
+}
+
+// sdNotify sends a state string such as "READY=1" to systemd. It reports
+// false without error when NOTIFY_SOCKET is unset.
+func sdNotify(state string) (bool, error) {
+    path := os.Getenv("NOTIFY_SOCKET")
+    if path == "" {
+        return false, nil
+    }
+    if path[0] == '@' {
+        path = "\x00" + path[1:] // abstract socket
+    }
+    conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+    if err != nil {
+        return false, err
+    }
+    defer conn.Close()
+    if _, err := conn.Write([]byte(state)); err != nil {
+        return false, err
+    }
+    return true, nil
+}
+
+// sdWatchdogInterval returns how often to ping the systemd watchdog, or 0
+// when the watchdog is not enabled for this process
+func sdWatchdogInterval() time.Duration {
+    usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+    if err != nil || usec <= 0 {
+        return 0
+    }
+    if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+        return 0
+    }
+    return time.Duration(usec) * time.Microsecond / 2
+}
+
+// sdReady tells systemd the server is up and starts the watchdog pinger
+func sdReady() {
+    ok, err := sdNotify("READY=1")
+    if err != nil {
+        logAt(logWarn, "sd_notify READY failed: %v", err)
+        return
+    }
+    if !ok {
+        return
+    }
+    logAt(logDebug, "notified systemd: READY=1")
+
+    if interval := sdWatchdogInterval(); interval > 0 {
+        logAt(logInfo, "  systemd watchdog: every %v", interval)
+        go func() {
+            ticker := time.NewTicker(interval)
+            defer ticker.Stop()
+            for range ticker.C {
+                if _, err := sdNotify("WATCHDOG=1"); err != nil {
+                    logAt(logWarn, "sd_notify WATCHDOG failed: %v", err)
+                }
+            }
+        }()
+    }
+}
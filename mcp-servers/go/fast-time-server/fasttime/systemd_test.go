@@ -0,0 +1,77 @@
+// -*- coding: utf-8 -*-
+// systemd_test.go - Tests for socket activation and sd_notify
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "testing"
+    "time"
+)
+
+func TestSystemdListenerNotActivated(t *testing.T) {
+    t.Setenv("LISTEN_PID", "")
+    t.Setenv("LISTEN_FDS", "")
+    if ln, err := systemdListener(); ln != nil || err != nil {
+        t.Errorf("want no listener, got %v, %v", ln, err)
+    }
+
+    // Sockets meant for another process are ignored
+    t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+    t.Setenv("LISTEN_FDS", "1")
+    if ln, err := systemdListener(); ln != nil || err != nil {
+        t.Errorf("want no listener for a foreign pid, got %v, %v", ln, err)
+    }
+}
+
+func TestSdNotify(t *testing.T) {
+    t.Setenv("NOTIFY_SOCKET", "")
+    if ok, err := sdNotify("READY=1"); ok || err != nil {
+        t.Errorf("without NOTIFY_SOCKET: got %v, %v", ok, err)
+    }
+
+    if runtime.GOOS == "windows" {
+        t.Skip("unixgram sockets are not available")
+    }
+    path := filepath.Join(t.TempDir(), "notify.sock")
+    conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+    if err != nil {
+        t.Skipf("cannot create notify socket: %v", err)
+    }
+    defer conn.Close()
+
+    t.Setenv("NOTIFY_SOCKET", path)
+    if ok, err := sdNotify("READY=1"); !ok || err != nil {
+        t.Fatalf("sdNotify: got %v, %v", ok, err)
+    }
+    _ = conn.SetReadDeadline(time.Now().Add(time.Second))
+    buf := make([]byte, 64)
+    n, _, err := conn.ReadFromUnix(buf)
+    if err != nil || string(buf[:n]) != "READY=1" {
+        t.Errorf("received %q, %v", buf[:n], err)
+    }
+}
+
+func TestSdWatchdogInterval(t *testing.T) {
+    t.Setenv("WATCHDOG_USEC", "")
+    t.Setenv("WATCHDOG_PID", "")
+    if d := sdWatchdogInterval(); d != 0 {
+        t.Errorf("watchdog disabled: got %v", d)
+    }
+
+    t.Setenv("WATCHDOG_USEC", "30000000")
+    if d := sdWatchdogInterval(); d != 15*time.Second {
+        t.Errorf("want half of 30s, got %v", d)
+    }
+    t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+    if d := sdWatchdogInterval(); d != 0 {
+        t.Errorf("watchdog for another pid: got %v", d)
+    }
+}
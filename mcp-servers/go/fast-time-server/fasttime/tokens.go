@@ -0,0 +1,94 @@
+// -*- coding: utf-8 -*-
+// tokens.go - reloadable Bearer tokens for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file holds the client and admin Bearer tokens. A token is set from a
+// flag or environment variable at startup, or read from a file
+// (-auth-token-file, -admin-token-file) that POST /admin/tokens/reload
+// re-reads, so tokens can be rotated without a restart.
+
+package fasttime
+
+import (
+    "fmt"
+    "os"
+    "strings"
+    "sync"
+)
+
+// liveTokens are the tokens in use keyed by role ("auth", "admin"); main
+// fills it in before serving so the admin API can report and reload them
+var liveTokens = map[string]*bearerToken{}
+
+// bearerToken is a token that can be replaced while the server runs
+type bearerToken struct {
+    mu   sync.RWMutex
+    val  string
+    file string // source re-read by reload ("" when fixed)
+}
+
+// newBearerToken returns a fixed token
+func newBearerToken(val string) *bearerToken {
+    return &bearerToken{val: val}
+}
+
+// newBearerTokenFile returns a token read from path
+func newBearerTokenFile(path string) (*bearerToken, error) {
+    t := &bearerToken{file: path}
+    if _, err := t.reload(); err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
+// get returns the current token
+func (t *bearerToken) get() string {
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    return t.val
+}
+
+// enabled reports whether a token is configured
+func (t *bearerToken) enabled() bool {
+    return t != nil && t.get() != ""
+}
+
+// reloadable reports whether the token comes from a file
+func (t *bearerToken) reloadable() bool {
+    return t != nil && t.file != ""
+}
+
+// reload re-reads the token file and reports whether the token changed
+func (t *bearerToken) reload() (bool, error) {
+    if t.file == "" {
+        return false, fmt.Errorf("token is not read from a file")
+    }
+    data, err := os.ReadFile(t.file)
+    if err != nil {
+        return false, fmt.Errorf("read token file: %w", err)
+    }
+    val := strings.TrimSpace(string(data))
+    if val == "" {
+        return false, fmt.Errorf("token file %s is empty", t.file)
+    }
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    changed := val != t.val
+    t.val = val
+    return changed, nil
+}
+
+// resolveToken picks the token source: a file, else the environment
+// variable, else the flag value
+func resolveToken(flagVal, file, envVar string) (*bearerToken, error) {
+    if file != "" {
+        return newBearerTokenFile(file)
+    }
+    if env := os.Getenv(envVar); env != "" {
+        return newBearerToken(env), nil
+    }
+    return newBearerToken(flagVal), nil
+}
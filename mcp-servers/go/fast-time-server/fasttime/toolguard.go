@@ -0,0 +1,103 @@
+// -*- coding: utf-8 -*-
+// toolguard.go - per-tool timeouts and panic isolation
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file keeps one misbehaving tool from stalling a session. With
+// -tool-timeout every tool call runs on a context with that deadline; when
+// the handler has not returned in time the caller gets a TIMEOUT error
+// result straight away and the handler, whose context is now done, is left
+// to stop on its own. A handler that panics gets an INTERNAL error result
+// instead of taking down the JSON-RPC request. Both are counted per tool and
+// reported as "timeouts" and "panics" by GET /admin/stats/tools.
+//
+// The guard runs after elicitation (elicitation.go), so time spent asking the
+// user for missing arguments does not count against the timeout. It runs
+// before the worker pool (workpool.go), so time a CPU-heavy tool waits for
+// a free worker does count.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "runtime/debug"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// toolTimeout bounds every tool call (set from -tool-timeout; 0 disables)
+var toolTimeout time.Duration
+
+// errToolTimeout is the cause of a context ended by toolTimeout
+var errToolTimeout = errors.New("tool timeout")
+
+// toolOutcome is what a tool handler returned
+type toolOutcome struct {
+    res *mcp.CallToolResult
+    err error
+}
+
+// toolGuardMiddleware runs each tool call within toolTimeout and turns
+// panics into error results
+func toolGuardMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        d := toolTimeout
+        if d <= 0 {
+            return recoveredCall(ctx, next, req)
+        }
+
+        ctx, cancel := context.WithTimeoutCause(ctx, d, errToolTimeout)
+        defer cancel()
+        done := make(chan toolOutcome, 1)
+        go func() {
+            res, err := recoveredCall(ctx, next, req)
+            done <- toolOutcome{res, err}
+        }()
+
+        select {
+        case out := <-done:
+            // A handler that gave up because of the deadline still timed out
+            failed := out.err != nil || out.res == nil || out.res.IsError
+            if !failed || context.Cause(ctx) != errToolTimeout {
+                return out.res, out.err
+            }
+        case <-ctx.Done():
+            if context.Cause(ctx) != errToolTimeout {
+                return nil, ctx.Err() // cancelled by the client or the request
+            }
+        }
+        return toolTimedOut(req.Params.Name, d), nil
+    }
+}
+
+// toolTimedOut counts a timeout of tool and returns its error result
+func toolTimedOut(tool string, d time.Duration) *mcp.CallToolResult {
+    toolStats.addTimeout(tool)
+    logAt(logWarn, "tool %s timed out after %v", tool, d)
+    return toolError(&apiError{
+        Code:    codeTimeout,
+        Message: fmt.Sprintf("%s did not finish within the tool timeout of %v", tool, d),
+        Hint:    "retry with a smaller request",
+    })
+}
+
+// recoveredCall calls next, turning a panic into an error result
+func recoveredCall(ctx context.Context, next server.ToolHandlerFunc, req mcp.CallToolRequest) (res *mcp.CallToolResult, err error) {
+    defer func() {
+        if p := recover(); p != nil {
+            tool := req.Params.Name
+            toolStats.addPanic(tool)
+            logAt(logError, "tool %s panicked: %v\n%s", tool, p, debug.Stack())
+            res, err = toolError(&apiError{
+                Code:    codeInternal,
+                Message: fmt.Sprintf("%s failed with an internal error", tool),
+            }), nil
+        }
+    }()
+    return next(ctx, req)
+}
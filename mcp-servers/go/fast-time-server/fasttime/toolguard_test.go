@@ -0,0 +1,108 @@
+// -*- coding: utf-8 -*-
+// toolguard_test.go - tests for tool timeouts and panic isolation
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// guardedStat returns the timeouts and panics counted for tool
+func guardedStat(tool string) (timeouts, panics int64) {
+    toolStats.mu.Lock()
+    defer toolStats.mu.Unlock()
+    if st := toolStats.byTool[tool]; st != nil {
+        return st.timeouts, st.panics
+    }
+    return 0, 0
+}
+
+// guardCode calls tool with handler and returns the error code of its result
+func guardCode(t *testing.T, handler server.ToolHandlerFunc, tool string) string {
+    t.Helper()
+    res, err := handler(context.Background(), testRequest(tool, nil))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !res.IsError {
+        return ""
+    }
+    return res.StructuredContent.(map[string]any)["error"].(*apiError).Code
+}
+
+func TestToolGuardTimeout(t *testing.T) {
+    toolStats.reset()
+    defer toolStats.reset()
+    defer func(d time.Duration) { toolTimeout = d }(toolTimeout)
+    toolTimeout = 20 * time.Millisecond
+
+    // A handler that ignores its context is abandoned
+    release := make(chan struct{})
+    defer close(release)
+    stuck := toolGuardMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        <-release
+        return mcp.NewToolResultText("late"), nil
+    })
+    start := time.Now()
+    if code := guardCode(t, stuck, "stuck"); code != codeTimeout || time.Since(start) > time.Second {
+        t.Errorf("stuck: code %q after %v", code, time.Since(start))
+    }
+
+    // A handler that stops at the deadline also timed out
+    polite := toolGuardMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        <-ctx.Done()
+        return nil, ctx.Err()
+    })
+    if code := guardCode(t, polite, "polite"); code != codeTimeout {
+        t.Errorf("polite: code %q", code)
+    }
+
+    // Fast calls pass through and the client's cancellation is not a timeout
+    fast := toolGuardMiddleware(handleGetSystemTime)
+    if code := guardCode(t, fast, "get_system_time"); code != "" {
+        t.Errorf("fast: code %q", code)
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    if _, err := polite(ctx, testRequest("cancelled", nil)); err != context.Canceled {
+        t.Errorf("cancelled: %v", err)
+    }
+
+    for tool, want := range map[string]int64{"stuck": 1, "polite": 1, "get_system_time": 0, "cancelled": 0} {
+        if got, _ := guardedStat(tool); got != want {
+            t.Errorf("%s timeouts = %d, want %d", tool, got, want)
+        }
+    }
+}
+
+func TestToolGuardPanic(t *testing.T) {
+    toolStats.reset()
+    defer toolStats.reset()
+    for _, d := range []time.Duration{0, time.Second} {
+        func() {
+            defer func(old time.Duration) { toolTimeout = old }(toolTimeout)
+            toolTimeout = d
+            handler := toolStatsMiddleware(toolGuardMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+                panic("boom")
+            }))
+            if code := guardCode(t, handler, "explode"); code != codeInternal {
+                t.Errorf("timeout %v: code %q", d, code)
+            }
+        }()
+    }
+    if _, panics := guardedStat("explode"); panics != 2 {
+        t.Errorf("panics = %d", panics)
+    }
+    stats := toolStats.snapshot()
+    if len(stats) != 1 || stats[0]["panics"] != int64(2) || stats[0]["errors"] != int64(2) {
+        t.Errorf("snapshot = %v", stats)
+    }
+}
@@ -0,0 +1,161 @@
+// -*- coding: utf-8 -*-
+// tools_abbrev.go - timezone abbreviation resolution for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements resolve_timezone_abbreviation. Abbreviations such as
+// "CST" or "IST" name several unrelated zones, so the tool lists every known
+// candidate with its offset and whether it is in use at the requested time,
+// and flags ambiguity explicitly instead of guessing.
+
+package fasttime
+
+import (
+    "context"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// abbrevCandidate is one zone an abbreviation may refer to
+type abbrevCandidate struct {
+    zone   string // representative IANA zone
+    name   string // expanded meaning of the abbreviation
+    offset int    // UTC offset the abbreviation denotes, in seconds
+}
+
+// tzAbbreviations maps common abbreviations to their candidate zones
+var tzAbbreviations = map[string][]abbrevCandidate{
+    "UTC":  {{"UTC", "Coordinated Universal Time", 0}},
+    "GMT":  {{"Europe/London", "Greenwich Mean Time", 0}},
+    "BST":  {{"Europe/London", "British Summer Time", 3600}, {"Asia/Dhaka", "Bangladesh Standard Time", 6 * 3600}},
+    "IST":  {{"Asia/Kolkata", "India Standard Time", 5*3600 + 1800}, {"Europe/Dublin", "Irish Standard Time", 3600}, {"Asia/Jerusalem", "Israel Standard Time", 2 * 3600}},
+    "CST":  {{"America/Chicago", "Central Standard Time (North America)", -6 * 3600}, {"Asia/Shanghai", "China Standard Time", 8 * 3600}, {"America/Havana", "Cuba Standard Time", -5 * 3600}},
+    "CDT":  {{"America/Chicago", "Central Daylight Time (North America)", -5 * 3600}, {"America/Havana", "Cuba Daylight Time", -4 * 3600}},
+    "EST":  {{"America/New_York", "Eastern Standard Time (North America)", -5 * 3600}},
+    "EDT":  {{"America/New_York", "Eastern Daylight Time (North America)", -4 * 3600}},
+    "MST":  {{"America/Denver", "Mountain Standard Time", -7 * 3600}, {"America/Phoenix", "Mountain Standard Time (no DST)", -7 * 3600}},
+    "MDT":  {{"America/Denver", "Mountain Daylight Time", -6 * 3600}},
+    "PST":  {{"America/Los_Angeles", "Pacific Standard Time", -8 * 3600}, {"Asia/Manila", "Philippine Standard Time", 8 * 3600}},
+    "PDT":  {{"America/Los_Angeles", "Pacific Daylight Time", -7 * 3600}},
+    "AKST": {{"America/Anchorage", "Alaska Standard Time", -9 * 3600}},
+    "HST":  {{"Pacific/Honolulu", "Hawaii Standard Time", -10 * 3600}},
+    "AST":  {{"America/Halifax", "Atlantic Standard Time", -4 * 3600}, {"Asia/Riyadh", "Arabia Standard Time", 3 * 3600}},
+    "ADT":  {{"America/Halifax", "Atlantic Daylight Time", -3 * 3600}},
+    "NST":  {{"America/St_Johns", "Newfoundland Standard Time", -3*3600 - 1800}},
+    "WET":  {{"Europe/Lisbon", "Western European Time", 0}},
+    "CET":  {{"Europe/Paris", "Central European Time", 3600}},
+    "CEST": {{"Europe/Paris", "Central European Summer Time", 2 * 3600}},
+    "EET":  {{"Europe/Athens", "Eastern European Time", 2 * 3600}},
+    "EEST": {{"Europe/Athens", "Eastern European Summer Time", 3 * 3600}},
+    "MSK":  {{"Europe/Moscow", "Moscow Standard Time", 3 * 3600}},
+    "GST":  {{"Asia/Dubai", "Gulf Standard Time", 4 * 3600}, {"Atlantic/South_Georgia", "South Georgia Time", -2 * 3600}},
+    "PKT":  {{"Asia/Karachi", "Pakistan Standard Time", 5 * 3600}},
+    "SGT":  {{"Asia/Singapore", "Singapore Time", 8 * 3600}},
+    "HKT":  {{"Asia/Hong_Kong", "Hong Kong Time", 8 * 3600}},
+    "JST":  {{"Asia/Tokyo", "Japan Standard Time", 9 * 3600}},
+    "KST":  {{"Asia/Seoul", "Korea Standard Time", 9 * 3600}},
+    "AEST": {{"Australia/Sydney", "Australian Eastern Standard Time", 10 * 3600}, {"Australia/Brisbane", "Australian Eastern Standard Time (no DST)", 10 * 3600}},
+    "AEDT": {{"Australia/Sydney", "Australian Eastern Daylight Time", 11 * 3600}},
+    "ACST": {{"Australia/Adelaide", "Australian Central Standard Time", 9*3600 + 1800}},
+    "AWST": {{"Australia/Perth", "Australian Western Standard Time", 8 * 3600}},
+    "NZST": {{"Pacific/Auckland", "New Zealand Standard Time", 12 * 3600}},
+    "NZDT": {{"Pacific/Auckland", "New Zealand Daylight Time", 13 * 3600}},
+    "SAST": {{"Africa/Johannesburg", "South Africa Standard Time", 2 * 3600}},
+    "WAT":  {{"Africa/Lagos", "West Africa Time", 3600}},
+    "EAT":  {{"Africa/Nairobi", "East Africa Time", 3 * 3600}},
+    "BRT":  {{"America/Sao_Paulo", "Brasilia Time", -3 * 3600}},
+    "ART":  {{"America/Argentina/Buenos_Aires", "Argentina Time", -3 * 3600}},
+}
+
+// knownAbbreviations returns the supported abbreviations in sorted order
+func knownAbbreviations() []string {
+    out := make([]string, 0, len(tzAbbreviations))
+    for k := range tzAbbreviations {
+        out = append(out, k)
+    }
+    sort.Strings(out)
+    return out
+}
+
+// resolveAbbreviation describes every candidate for abbr at time t
+func resolveAbbreviation(abbr string, t time.Time) ([]map[string]interface{}, bool) {
+    cands, ok := tzAbbreviations[strings.ToUpper(strings.TrimSpace(abbr))]
+    if !ok {
+        return nil, false
+    }
+
+    out := make([]map[string]interface{}, 0, len(cands))
+    for _, c := range cands {
+        entry := map[string]interface{}{
+            "zone":       c.zone,
+            "name":       c.name,
+            "utc_offset": formatOffset(c.offset),
+        }
+        if loc, err := loadLocation(c.zone); err == nil {
+            local := t.In(loc)
+            curAbbr, curOffset := local.Zone()
+            entry["current_utc_offset"] = formatOffset(curOffset)
+            entry["current_abbreviation"] = curAbbr
+            // Zones like America/Chicago only use "CST" for part of the year
+            entry["in_effect"] = curOffset == c.offset
+        }
+        out = append(out, entry)
+    }
+    return out, true
+}
+
+// handleResolveAbbreviation maps a timezone abbreviation to candidate IANA zones
+func handleResolveAbbreviation(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    abbr, err := req.RequireString("abbreviation")
+    if err != nil {
+        return mcp.NewToolResultError("abbreviation parameter is required"), nil
+    }
+
+    t, err := timeArgIn(req, time.UTC)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    candidates, ok := resolveAbbreviation(abbr, t)
+    if !ok {
+        return mcp.NewToolResultError("unknown timezone abbreviation " + strings.ToUpper(abbr) +
+            "; known abbreviations: " + strings.Join(knownAbbreviations(), ", ")), nil
+    }
+
+    result := map[string]interface{}{
+        "abbreviation": strings.ToUpper(strings.TrimSpace(abbr)),
+        "time":         t.UTC().Format(time.RFC3339),
+        "ambiguous":    len(candidates) > 1,
+        "candidates":   candidates,
+    }
+    if len(candidates) > 1 {
+        result["note"] = "abbreviation is ambiguous; confirm the intended zone and use its IANA name"
+    }
+
+    logAt(logInfo, "resolve_timezone_abbreviation: %s -> %d candidates", abbr, len(candidates))
+    return toolResultJSON(result)
+}
+
+// registerAbbreviationTools adds resolve_timezone_abbreviation to the server
+func registerAbbreviationTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("resolve_timezone_abbreviation",
+        mcp.WithDescription("Map a timezone abbreviation like CST or IST to candidate IANA zones with their offsets, flagging ambiguous abbreviations"),
+        mcp.WithTitleAnnotation("Resolve Timezone Abbreviation"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // in_effect depends on the current date when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("abbreviation",
+            mcp.Required(),
+            mcp.Description("Timezone abbreviation (e.g., 'CST', 'IST', 'PST')"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time used to check which candidates are in effect. Defaults to now"),
+        ),
+    ), handleResolveAbbreviation)
+}
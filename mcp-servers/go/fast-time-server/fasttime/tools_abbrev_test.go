@@ -0,0 +1,67 @@
+// -*- coding: utf-8 -*-
+// tools_abbrev_test.go - Tests for timezone abbreviation resolution
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+)
+
+func TestTZAbbreviationZonesLoad(t *testing.T) {
+    for abbr, cands := range tzAbbreviations {
+        for _, c := range cands {
+            if _, err := loadLocation(c.zone); err != nil {
+                t.Errorf("%s candidate %s does not load: %v", abbr, c.zone, err)
+            }
+        }
+    }
+}
+
+func TestHandleResolveAbbreviation(t *testing.T) {
+    type candidate struct {
+        Zone     string `json:"zone"`
+        InEffect bool   `json:"in_effect"`
+    }
+    type body struct {
+        Ambiguous  bool        `json:"ambiguous"`
+        Candidates []candidate `json:"candidates"`
+    }
+    call := func(args map[string]any) body {
+        t.Helper()
+        res, err := handleResolveAbbreviation(context.Background(), testRequest("resolve_timezone_abbreviation", args))
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        var b body
+        if err := json.Unmarshal([]byte(extractText(t, res)), &b); err != nil {
+            t.Fatalf("result is not JSON: %v", err)
+        }
+        return b
+    }
+
+    // In July Chicago is on CDT, so only China Standard Time matches CST
+    b := call(map[string]any{"abbreviation": "cst", "time": "2025-07-01T00:00:00Z"})
+    if !b.Ambiguous || len(b.Candidates) != 3 {
+        t.Fatalf("CST should be ambiguous with 3 candidates, got %+v", b)
+    }
+    for _, c := range b.Candidates {
+        want := c.Zone == "Asia/Shanghai"
+        if c.InEffect != want {
+            t.Errorf("July CST %s in_effect = %v, want %v", c.Zone, c.InEffect, want)
+        }
+    }
+
+    if b := call(map[string]any{"abbreviation": "JST"}); b.Ambiguous || len(b.Candidates) != 1 {
+        t.Errorf("JST should be unambiguous, got %+v", b)
+    }
+
+    res, _ := handleResolveAbbreviation(context.Background(), testRequest("resolve_timezone_abbreviation", map[string]any{"abbreviation": "XYZ"}))
+    if !res.IsError {
+        t.Error("expected error result for unknown abbreviation")
+    }
+}
@@ -0,0 +1,285 @@
+// -*- coding: utf-8 -*-
+// tools_compare.go - timestamp ordering tool for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements compare_timestamps, the computation behind the
+// compare_timezones prompt: it puts timestamps written in different zones
+// on one axis, sorts them, groups the ones that denote the same instant
+// ("09:00 in New York" and "14:00 in London") and reports the gap between
+// every pair.
+//
+// Each timestamp is either a string, optionally followed by a zone
+// ("2025-03-10 09:00 America/New_York"), or an object
+// {"time": ..., "timezone": ..., "label": ...}. Times without an offset are
+// read in their own zone, else in the timezone argument, which is also the
+// zone of the common axis; times with an offset are reported in it. Entries no more than `tolerance` apart are
+// simultaneous; simultaneous entries in the same zone are duplicates.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// maxCompareTimestamps caps the timestamps of one compare_timestamps call
+const maxCompareTimestamps = 50
+
+// comparedTimestamp is one input placed on the common axis
+type comparedTimestamp struct {
+    Index    int    `json:"index"` // position in the input
+    Label    string `json:"label,omitempty"`
+    Input    string `json:"input"`
+    Timezone string `json:"timezone"`
+    Local    string `json:"local"` // in its own zone
+    Axis     string `json:"axis"`  // in the common zone
+    Unix     int64  `json:"unix"`
+    Group    int    `json:"group"` // entries with the same group are simultaneous
+
+    SecondsAfterEarliest float64 `json:"seconds_after_earliest"`
+
+    at time.Time
+}
+
+// timestampGap is the time between two neighbours on the axis
+type timestampGap struct {
+    From      int     `json:"from"`
+    To        int     `json:"to"`
+    Seconds   float64 `json:"seconds"`
+    Humanized string  `json:"humanized"`
+}
+
+// parseTimeWithZone reads value in loc or, failing that, in the zone that
+// follows it ("2025-03-10 09:00:00 America/New_York"); it returns the zone used
+func parseTimeWithZone(value string, loc *time.Location) (time.Time, *time.Location, error) {
+    at, err := parseTimeInLocation(value, loc)
+    if err == nil {
+        return at, loc, nil
+    }
+    if cut := strings.LastIndexByte(value, ' '); cut > 0 {
+        if zl, zerr := loadLocation(value[cut+1:]); zerr == nil {
+            if zat, zerr := parseTimeInLocation(strings.TrimSpace(value[:cut]), zl); zerr == nil {
+                return zat, zl, nil
+            }
+        }
+    }
+    return at, loc, err
+}
+
+// parseCompareItem reads one element of the timestamps argument
+func parseCompareItem(i int, item any, def *time.Location) (*comparedTimestamp, error) {
+    var value, zone, label string
+    switch v := item.(type) {
+    case string:
+        value = strings.TrimSpace(v)
+    case map[string]any:
+        value, _ = v["time"].(string)
+        zone, _ = v["timezone"].(string)
+        label, _ = v["label"].(string)
+    default:
+        return nil, fmt.Errorf("timestamps[%d]: want a string or an object with time and timezone", i)
+    }
+    if value == "" {
+        return nil, fmt.Errorf("timestamps[%d]: time is empty", i)
+    }
+
+    loc := def
+    if zone != "" {
+        var err error
+        if loc, err = loadLocation(zone); err != nil {
+            return nil, fieldError(fmt.Sprintf("timestamps[%d].timezone", i), err)
+        }
+    }
+    var at time.Time
+    var err error
+    if zone != "" {
+        at, err = parseTimeInLocation(value, loc)
+    } else {
+        at, loc, err = parseTimeWithZone(value, loc)
+    }
+    if err != nil {
+        return nil, errUnparseableTime(fmt.Sprintf("timestamps[%d]", i), value)
+    }
+    // A time with its own offset is reported in that offset
+    zone = loc.String()
+    if at.Location() != loc {
+        if zone = at.Location().String(); zone == "" {
+            _, off := at.Zone()
+            zone = "UTC" + formatOffset(off)
+        }
+    }
+    return &comparedTimestamp{
+        Index:    i,
+        Label:    label,
+        Input:    value,
+        Timezone: zone,
+        Local:    at.Format(time.RFC3339Nano),
+        Unix:     at.Unix(),
+        at:       at,
+    }, nil
+}
+
+// humanizeGap renders a gap such as "5h 30m"
+func humanizeGap(d time.Duration) string {
+    s, _ := formatDurationStyle(d, "short")
+    return s
+}
+
+// handleCompareTimestamps sorts timestamps on a common axis and reports
+// simultaneous entries and the gaps between them
+func handleCompareTimestamps(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    raw, ok := req.GetArguments()["timestamps"].([]any)
+    if !ok || len(raw) == 0 {
+        return toolError(errMissingField("timestamps")), nil
+    }
+    if len(raw) > maxCompareTimestamps {
+        return mcp.NewToolResultError(fmt.Sprintf("at most %d timestamps can be compared", maxCompareTimestamps)), nil
+    }
+
+    tz := req.GetString("timezone", "UTC")
+    axis, err := loadLocation(tz)
+    if err != nil {
+        return toolError(fieldError("timezone", err)), nil
+    }
+    var tolerance time.Duration
+    if s := req.GetString("tolerance", ""); s != "" {
+        pd, err := parseDurationFlexible(s)
+        if err != nil || pd.d < 0 {
+            return mcp.NewToolResultError(fmt.Sprintf("invalid tolerance %q: use a duration such as 1s or 5m", s)), nil
+        }
+        tolerance = pd.d
+    }
+
+    items := make([]*comparedTimestamp, len(raw))
+    for i, v := range raw {
+        if items[i], err = parseCompareItem(i, v, axis); err != nil {
+            return toolError(err), nil
+        }
+    }
+
+    // Pairwise gaps in input order: pairwise_seconds[i][j] is j minus i
+    pairwise := make([][]float64, len(items))
+    for i, a := range items {
+        pairwise[i] = make([]float64, len(items))
+        for j, b := range items {
+            pairwise[i][j] = b.at.Sub(a.at).Seconds()
+        }
+    }
+
+    sorted := append([]*comparedTimestamp(nil), items...)
+    sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].at.Before(sorted[j].at) })
+    ordered := true
+    for i := 1; i < len(items); i++ {
+        if items[i].at.Before(items[i-1].at) {
+            ordered = false
+            break
+        }
+    }
+
+    // Neighbours within tolerance share a group
+    earliest := sorted[0].at
+    gaps := make([]timestampGap, 0, len(sorted)-1)
+    group := 0
+    for i, ts := range sorted {
+        if i > 0 {
+            d := ts.at.Sub(sorted[i-1].at)
+            if d > tolerance {
+                group++
+            }
+            gaps = append(gaps, timestampGap{From: sorted[i-1].Index, To: ts.Index, Seconds: d.Seconds(), Humanized: humanizeGap(d)})
+        }
+        ts.Group = group
+        ts.Axis = ts.at.In(axis).Format(time.RFC3339Nano)
+        ts.SecondsAfterEarliest = ts.at.Sub(earliest).Seconds()
+    }
+
+    byGroup := make(map[int][]int)
+    byZone := make(map[string][]int)
+    for _, ts := range sorted {
+        byGroup[ts.Group] = append(byGroup[ts.Group], ts.Index)
+        key := fmt.Sprintf("%d|%s", ts.Group, ts.Timezone)
+        byZone[key] = append(byZone[key], ts.Index)
+    }
+    simultaneous := [][]int{}
+    for g := 0; g <= group; g++ {
+        if len(byGroup[g]) > 1 {
+            simultaneous = append(simultaneous, byGroup[g])
+        }
+    }
+    duplicates := [][]int{}
+    for _, ts := range sorted {
+        key := fmt.Sprintf("%d|%s", ts.Group, ts.Timezone)
+        if idx := byZone[key]; len(idx) > 1 && idx[0] == ts.Index {
+            duplicates = append(duplicates, idx)
+        }
+    }
+
+    span := sorted[len(sorted)-1].at.Sub(earliest)
+    result := map[string]interface{}{
+        "timezone":          axis.String(),
+        "count":             len(items),
+        "sorted":            sorted,
+        "already_ordered":   ordered,
+        "earliest":          sorted[0].Index,
+        "latest":            sorted[len(sorted)-1].Index,
+        "span_seconds":      span.Seconds(),
+        "span":              humanizeGap(span),
+        "gaps":              gaps,
+        "pairwise_seconds":  pairwise,
+        "simultaneous":      simultaneous,
+        "duplicates":        duplicates,
+        "distinct_instants": group + 1,
+    }
+    if tolerance > 0 {
+        result["tolerance_seconds"] = tolerance.Seconds()
+    }
+
+    logAt(logInfo, "compare_timestamps: %d timestamp(s), %d distinct instant(s)", len(items), group+1)
+    return toolResultJSON(result)
+}
+
+// registerCompareTools adds compare_timestamps to the server
+func registerCompareTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("compare_timestamps",
+        mcp.WithDescription("Sort timestamps given in different timezones on a common axis, flag ones that denote the same instant, and report the gaps between them"),
+        mcp.WithTitleAnnotation("Compare Timestamps"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithArray("timestamps",
+            mcp.Required(),
+            mcp.Description("Timestamps to compare: strings such as '2025-03-10T09:00:00-04:00' or '2025-03-10 09:00 America/New_York', or objects {time, timezone, label}"),
+            mcp.MinItems(1),
+            mcp.MaxItems(maxCompareTimestamps),
+            mcp.Items(map[string]any{
+                "anyOf": []any{
+                    map[string]any{"type": "string"},
+                    map[string]any{
+                        "type": "object",
+                        "properties": map[string]any{
+                            "time":     map[string]any{"type": "string"},
+                            "timezone": map[string]any{"type": "string"},
+                            "label":    map[string]any{"type": "string"},
+                        },
+                        "required": []string{"time"},
+                    },
+                },
+            }),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone of the common axis, also used for times without an offset or zone. Defaults to UTC"),
+        ),
+        mcp.WithString("tolerance",
+            mcp.Description("Treat timestamps at most this far apart as simultaneous, e.g. '1s' or '5m'. Defaults to exact equality"),
+        ),
+    ), handleCompareTimestamps)
+}
@@ -0,0 +1,87 @@
+// -*- coding: utf-8 -*-
+// tools_compare_test.go - Tests for compare_timestamps
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "reflect"
+    "testing"
+)
+
+func TestHandleCompareTimestamps(t *testing.T) {
+    res, err := handleCompareTimestamps(context.Background(), testRequest("compare_timestamps", map[string]any{
+        "timestamps": []any{
+            "2025-03-10 13:00:00 Europe/London",
+            map[string]any{"time": "2025-03-10 09:00:00", "timezone": "America/New_York", "label": "standup"},
+            "2025-03-10T12:00:00Z",
+            "2025-03-10 13:00:00 Europe/London",
+        },
+        "timezone": "Asia/Tokyo",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("compare_timestamps = %v, %v", res, err)
+    }
+    var body struct {
+        Timezone string `json:"timezone"`
+        Sorted   []struct {
+            Index    int    `json:"index"`
+            Label    string `json:"label"`
+            Timezone string `json:"timezone"`
+            Axis     string `json:"axis"`
+            Group    int    `json:"group"`
+        } `json:"sorted"`
+        Ordered      bool           `json:"already_ordered"`
+        Simultaneous [][]int        `json:"simultaneous"`
+        Duplicates   [][]int        `json:"duplicates"`
+        Gaps         []timestampGap `json:"gaps"`
+        Pairwise     [][]float64    `json:"pairwise_seconds"`
+        Distinct     int            `json:"distinct_instants"`
+        Span         string         `json:"span"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatal(err)
+    }
+
+    // 12:00Z comes first; 13:00 London (GMT) and 09:00 New York (EDT) are 13:00Z
+    var order []int
+    for _, s := range body.Sorted {
+        order = append(order, s.Index)
+    }
+    if !reflect.DeepEqual(order, []int{2, 0, 1, 3}) || body.Ordered {
+        t.Errorf("order = %v, already_ordered = %v", order, body.Ordered)
+    }
+    if body.Sorted[0].Axis != "2025-03-10T21:00:00+09:00" || body.Sorted[0].Timezone != "UTC" || body.Sorted[2].Label != "standup" || body.Sorted[2].Timezone != "America/New_York" {
+        t.Errorf("sorted = %+v", body.Sorted)
+    }
+    if !reflect.DeepEqual(body.Simultaneous, [][]int{{0, 1, 3}}) || !reflect.DeepEqual(body.Duplicates, [][]int{{0, 3}}) || body.Distinct != 2 {
+        t.Errorf("simultaneous = %v, duplicates = %v, distinct = %d", body.Simultaneous, body.Duplicates, body.Distinct)
+    }
+    if body.Gaps[0].Seconds != 3600 || body.Gaps[0].Humanized != "1h" || body.Pairwise[2][0] != 3600 || body.Pairwise[0][2] != -3600 || body.Span != "1h" {
+        t.Errorf("gaps = %+v, pairwise = %v, span = %s", body.Gaps, body.Pairwise, body.Span)
+    }
+
+    // With a tolerance, near instants are simultaneous
+    res, _ = handleCompareTimestamps(context.Background(), testRequest("compare_timestamps", map[string]any{
+        "timestamps": []any{"2025-03-10T12:00:00Z", "2025-03-10T12:00:00.4Z"},
+        "tolerance":  "1s",
+    }))
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil || body.Distinct != 1 {
+        t.Errorf("tolerance: %s", extractText(t, res))
+    }
+
+    for _, args := range []map[string]any{
+        {},
+        {"timestamps": []any{"yesterday"}},
+        {"timestamps": []any{map[string]any{"time": "2025-03-10", "timezone": "Mars/Base"}}},
+        {"timestamps": []any{"2025-03-10"}, "tolerance": "soon"},
+    } {
+        if res, _ := handleCompareTimestamps(context.Background(), testRequest("compare_timestamps", args)); !res.IsError {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
@@ -0,0 +1,137 @@
+// -*- coding: utf-8 -*-
+// tools_dst.go - daylight saving time tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements is_dst, which reports whether daylight saving time is
+// in effect for a timestamp in a zone, along with the UTC offset, the zone
+// abbreviation and the next offset transition. The same lookup keeps the
+// dst/offset fields of the timezone://info resource accurate year-round.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// transitionSearchHorizon bounds the forward search for the next offset change
+const transitionSearchHorizon = 366 * 24 * time.Hour
+
+// formatOffset renders a UTC offset in seconds as ±HH:MM
+func formatOffset(secs int) string {
+    sign := '+'
+    if secs < 0 {
+        sign = '-'
+        secs = -secs
+    }
+    return fmt.Sprintf("%c%02d:%02d", sign, secs/3600, (secs%3600)/60)
+}
+
+// nextTransition finds the first instant after t at which the zone's UTC
+// offset or abbreviation changes, within the search horizon
+func nextTransition(t time.Time) (time.Time, bool) {
+    name, offset := t.Zone()
+    changed := func(u time.Time) bool {
+        n, o := u.Zone()
+        return n != name || o != offset
+    }
+
+    // Step a day at a time, then bisect down to the second
+    lo := t
+    for hi := t.Add(24 * time.Hour); hi.Sub(t) <= transitionSearchHorizon; hi = hi.Add(24 * time.Hour) {
+        if changed(hi) {
+            for hi.Sub(lo) > time.Second {
+                mid := lo.Add(hi.Sub(lo) / 2)
+                if changed(mid) {
+                    hi = mid
+                } else {
+                    lo = mid
+                }
+            }
+            return hi.Truncate(time.Second), true
+        }
+        lo = hi
+    }
+    return time.Time{}, false
+}
+
+// observesDST reports whether the zone has any DST period in the year around t
+func observesDST(t time.Time) bool {
+    for i := 0; i < 12; i++ {
+        if t.AddDate(0, i, 0).IsDST() {
+            return true
+        }
+    }
+    return false
+}
+
+// dstInfo describes the DST state of t in its location
+func dstInfo(t time.Time) map[string]interface{} {
+    abbr, offset := t.Zone()
+    info := map[string]interface{}{
+        "is_dst":         t.IsDST(),
+        "observes_dst":   observesDST(t),
+        "utc_offset":     formatOffset(offset),
+        "offset_seconds": offset,
+        "abbreviation":   abbr,
+    }
+    if next, ok := nextTransition(t); ok {
+        nextAbbr, nextOffset := next.Zone()
+        info["next_transition"] = map[string]interface{}{
+            "at":           next.Format(time.RFC3339),
+            "utc_offset":   formatOffset(nextOffset),
+            "abbreviation": nextAbbr,
+            "is_dst":       next.IsDST(),
+        }
+    }
+    return info
+}
+
+// handleIsDST reports whether DST is in effect for a time in a zone
+func handleIsDST(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    tz, err := req.RequireString("timezone")
+    if err != nil {
+        return mcp.NewToolResultError("timezone parameter is required"), nil
+    }
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    t, err := timeArgIn(req, loc)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    result := dstInfo(t)
+    result["time"] = t.Format(time.RFC3339)
+    result["timezone"] = tz
+
+    logAt(logInfo, "is_dst: %s at %s = %v", tz, t.Format(time.RFC3339), result["is_dst"])
+    return toolResultJSON(result)
+}
+
+// registerDSTTools adds is_dst to the server
+func registerDSTTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("is_dst",
+        mcp.WithDescription("Report whether daylight saving time is in effect for a time in a timezone, with the UTC offset, abbreviation and next transition"),
+        mcp.WithTitleAnnotation("Is DST"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("timezone",
+            mcp.Required(),
+            mcp.Description("IANA timezone name (e.g., 'America/New_York')"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time in RFC3339 or common formats. Defaults to now"),
+        ),
+    ), handleIsDST)
+}
@@ -0,0 +1,75 @@
+// -*- coding: utf-8 -*-
+// tools_dst_test.go - Tests for daylight saving time tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestFormatOffset(t *testing.T) {
+    tests := map[int]string{0: "+00:00", 19800: "+05:30", -18000: "-05:00", -9000: "-02:30"}
+    for secs, want := range tests {
+        if got := formatOffset(secs); got != want {
+            t.Errorf("formatOffset(%d) = %s, want %s", secs, got, want)
+        }
+    }
+}
+
+func TestNextTransition(t *testing.T) {
+    ny, _ := loadLocation("America/New_York")
+    next, ok := nextTransition(time.Date(2025, 1, 15, 0, 0, 0, 0, ny))
+    if !ok {
+        t.Fatal("expected a transition for America/New_York")
+    }
+    if got := next.UTC().Format(time.RFC3339); got != "2025-03-09T07:00:00Z" {
+        t.Errorf("want spring-forward at 2025-03-09T07:00:00Z, got %s", got)
+    }
+
+    tokyo, _ := loadLocation("Asia/Tokyo")
+    if _, ok := nextTransition(time.Date(2025, 1, 15, 0, 0, 0, 0, tokyo)); ok {
+        t.Error("Asia/Tokyo should have no transition")
+    }
+}
+
+func TestHandleIsDST(t *testing.T) {
+    tests := []struct {
+        tz, time   string
+        wantDST    bool
+        wantOffset string
+        wantAbbr   string
+    }{
+        {"America/New_York", "2025-07-01T12:00:00", true, "-04:00", "EDT"},
+        {"America/New_York", "2025-01-01T12:00:00", false, "-05:00", "EST"},
+        {"Australia/Sydney", "2025-01-01T12:00:00", true, "+11:00", "AEDT"},
+        {"Asia/Kolkata", "2025-07-01T12:00:00", false, "+05:30", "IST"},
+    }
+    for _, tt := range tests {
+        res, err := handleIsDST(context.Background(), testRequest("is_dst", map[string]any{"timezone": tt.tz, "time": tt.time}))
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        var body struct {
+            IsDST        bool   `json:"is_dst"`
+            UTCOffset    string `json:"utc_offset"`
+            Abbreviation string `json:"abbreviation"`
+        }
+        if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+            t.Fatalf("result is not JSON: %v", err)
+        }
+        if body.IsDST != tt.wantDST || body.UTCOffset != tt.wantOffset || body.Abbreviation != tt.wantAbbr {
+            t.Errorf("%s at %s: got %+v", tt.tz, tt.time, body)
+        }
+    }
+
+    res, _ := handleIsDST(context.Background(), testRequest("is_dst", map[string]any{"timezone": "Mars/Olympus"}))
+    if !res.IsError {
+        t.Error("expected error result for invalid timezone")
+    }
+}
@@ -0,0 +1,330 @@
+// -*- coding: utf-8 -*-
+// tools_duration.go - duration parsing and formatting for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements parse_duration, which accepts Go durations ("2h30m"),
+// ISO 8601 durations ("PT2H30M"), clock form ("2:30:00") and loose phrases
+// ("2 hours 30 min") and normalizes them into seconds, a Go duration, an ISO
+// 8601 duration and a humanized string. In format mode it renders a duration
+// or a number of seconds in a chosen style instead.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    nominalDay   = 24 * time.Hour
+    nominalWeek  = 7 * nominalDay
+    nominalMonth = 30 * nominalDay  // ISO 8601 months are calendar-dependent
+    nominalYear  = 365 * nominalDay // ISO 8601 years are calendar-dependent
+)
+
+var (
+    isoDurationPattern   = regexp.MustCompile(`^(-)?P(?:([\d.]+)Y)?(?:([\d.]+)M)?(?:([\d.]+)W)?(?:([\d.]+)D)?(?:T(?:([\d.]+)H)?(?:([\d.]+)M)?(?:([\d.]+)S)?)?$`)
+    clockDurationPattern = regexp.MustCompile(`^(-)?(\d+):([0-5]\d)(?::([0-5]\d(?:\.\d+)?))?$`)
+    looseTermPattern     = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-zµ]+)`)
+    looseFillerPattern   = regexp.MustCompile(`^(?:\s|,|and)*$`)
+)
+
+// looseUnits maps unit words used in loose phrases to their length
+var looseUnits = map[string]time.Duration{
+    "ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+    "us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+    "ms": time.Millisecond, "msec": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+    "s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+    "m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+    "h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+    "d": nominalDay, "day": nominalDay, "days": nominalDay,
+    "w": nominalWeek, "wk": nominalWeek, "wks": nominalWeek, "week": nominalWeek, "weeks": nominalWeek,
+}
+
+// parsedDuration is a normalized duration and the syntax it was read from
+type parsedDuration struct {
+    d       time.Duration
+    format  string // go, iso8601, clock, loose or seconds
+    nominal bool   // true when ISO years or months were approximated
+}
+
+// parseDurationFlexible parses any supported duration syntax
+func parseDurationFlexible(s string) (parsedDuration, error) {
+    raw := strings.TrimSpace(s)
+    if raw == "" {
+        return parsedDuration{}, fmt.Errorf("duration is empty")
+    }
+
+    if d, err := time.ParseDuration(raw); err == nil {
+        return parsedDuration{d: d, format: "go"}, nil
+    }
+    if m := isoDurationPattern.FindStringSubmatch(strings.ToUpper(raw)); m != nil && raw != "P" && !strings.HasSuffix(strings.ToUpper(raw), "T") {
+        return parseISODuration(m)
+    }
+    if m := clockDurationPattern.FindStringSubmatch(raw); m != nil {
+        h, _ := strconv.Atoi(m[2])
+        mi, _ := strconv.Atoi(m[3])
+        var sec float64
+        if m[4] != "" {
+            sec, _ = strconv.ParseFloat(m[4], 64)
+        }
+        d := time.Duration(h)*time.Hour + time.Duration(mi)*time.Minute + time.Duration(sec*float64(time.Second))
+        if m[1] != "" {
+            d = -d
+        }
+        return parsedDuration{d: d, format: "clock"}, nil
+    }
+    if secs, err := strconv.ParseFloat(raw, 64); err == nil {
+        return parsedDuration{d: time.Duration(secs * float64(time.Second)), format: "seconds"}, nil
+    }
+    return parseLooseDuration(raw)
+}
+
+// parseISODuration converts the submatches of isoDurationPattern
+func parseISODuration(m []string) (parsedDuration, error) {
+    units := []time.Duration{nominalYear, nominalMonth, nominalWeek, nominalDay, time.Hour, time.Minute, time.Second}
+    var total float64
+    pd := parsedDuration{format: "iso8601"}
+    for i, unit := range units {
+        v := m[i+2]
+        if v == "" {
+            continue
+        }
+        n, err := strconv.ParseFloat(v, 64)
+        if err != nil {
+            return parsedDuration{}, fmt.Errorf("invalid ISO 8601 number %q", v)
+        }
+        if i < 2 && n != 0 {
+            pd.nominal = true
+        }
+        total += n * float64(unit)
+    }
+    if total > math.MaxInt64 {
+        return parsedDuration{}, fmt.Errorf("duration is too long")
+    }
+    pd.d = time.Duration(total)
+    if m[1] != "" {
+        pd.d = -pd.d
+    }
+    return pd, nil
+}
+
+// parseLooseDuration parses phrases such as "2 hours 30 min" or "1 day, 4h and 5m"
+func parseLooseDuration(raw string) (parsedDuration, error) {
+    s := strings.ToLower(raw)
+    negative := strings.HasPrefix(s, "-")
+    s = strings.TrimPrefix(s, "-")
+
+    var total float64
+    matches := looseTermPattern.FindAllStringSubmatchIndex(s, -1)
+    if len(matches) == 0 {
+        return parsedDuration{}, fmt.Errorf("unrecognized duration %q (use e.g. '2h30m', 'PT2H30M', '2:30:00' or '2 hours 30 min')", raw)
+    }
+    prev := 0
+    for _, idx := range matches {
+        if !looseFillerPattern.MatchString(s[prev:idx[0]]) {
+            return parsedDuration{}, fmt.Errorf("unrecognized text %q in duration", strings.TrimSpace(s[prev:idx[0]]))
+        }
+        n, _ := strconv.ParseFloat(s[idx[2]:idx[3]], 64)
+        unit, ok := looseUnits[s[idx[4]:idx[5]]]
+        if !ok {
+            return parsedDuration{}, fmt.Errorf("unknown duration unit %q", s[idx[4]:idx[5]])
+        }
+        total += n * float64(unit)
+        prev = idx[1]
+    }
+    if rest := s[prev:]; !looseFillerPattern.MatchString(rest) {
+        return parsedDuration{}, fmt.Errorf("unrecognized text %q in duration", strings.TrimSpace(rest))
+    }
+
+    d := time.Duration(total)
+    if negative {
+        d = -d
+    }
+    return parsedDuration{d: d, format: "loose"}, nil
+}
+
+// durationParts splits an absolute duration into days, hours, minutes, seconds and nanoseconds
+func durationParts(d time.Duration) (days, hours, mins, secs int64, nanos int64) {
+    if d < 0 {
+        d = -d
+    }
+    days = int64(d / nominalDay)
+    hours = int64(d % nominalDay / time.Hour)
+    mins = int64(d % time.Hour / time.Minute)
+    secs = int64(d % time.Minute / time.Second)
+    nanos = int64(d % time.Second)
+    return
+}
+
+// formatSeconds renders whole seconds plus a trimmed fractional part
+func formatSeconds(secs, nanos int64) string {
+    if nanos == 0 {
+        return strconv.FormatInt(secs, 10)
+    }
+    return strings.TrimRight(fmt.Sprintf("%d.%09d", secs, nanos), "0")
+}
+
+// formatISODuration renders d as an ISO 8601 duration using days and clock units
+func formatISODuration(d time.Duration) string {
+    if d == 0 {
+        return "PT0S"
+    }
+    days, hours, mins, secs, nanos := durationParts(d)
+    var b strings.Builder
+    if d < 0 {
+        b.WriteByte('-')
+    }
+    b.WriteByte('P')
+    if days > 0 {
+        fmt.Fprintf(&b, "%dD", days)
+    }
+    if hours > 0 || mins > 0 || secs > 0 || nanos > 0 {
+        b.WriteByte('T')
+        if hours > 0 {
+            fmt.Fprintf(&b, "%dH", hours)
+        }
+        if mins > 0 {
+            fmt.Fprintf(&b, "%dM", mins)
+        }
+        if secs > 0 || nanos > 0 {
+            fmt.Fprintf(&b, "%sS", formatSeconds(secs, nanos))
+        }
+    }
+    return b.String()
+}
+
+// formatDurationStyle renders d as "long" (2 hours, 30 minutes), "short" (2h 30m) or "clock" (02:30:00)
+func formatDurationStyle(d time.Duration, style string) (string, error) {
+    days, hours, mins, secs, nanos := durationParts(d)
+    sign := ""
+    if d < 0 {
+        sign = "-"
+    }
+
+    switch style {
+    case "clock":
+        return fmt.Sprintf("%s%02d:%02d:%02d", sign, days*24+hours, mins, secs), nil
+    case "short", "long":
+        units := []struct {
+            n          int64
+            short, one string
+        }{{days, "d", "day"}, {hours, "h", "hour"}, {mins, "m", "minute"}, {secs, "s", "second"}}
+        var parts []string
+        for _, u := range units {
+            if u.n == 0 {
+                continue
+            }
+            if style == "short" {
+                parts = append(parts, fmt.Sprintf("%d%s", u.n, u.short))
+            } else {
+                parts = append(parts, pluralize(int(u.n), u.one))
+            }
+        }
+        if len(parts) == 0 {
+            if nanos > 0 {
+                if style == "short" {
+                    return sign + time.Duration(nanos).String(), nil
+                }
+                return sign + "less than a second", nil
+            }
+            if style == "short" {
+                return "0s", nil
+            }
+            return "0 seconds", nil
+        }
+        if style == "short" {
+            return sign + strings.Join(parts, " "), nil
+        }
+        return sign + strings.Join(parts, ", "), nil
+    default:
+        return "", fmt.Errorf("unknown style %q (use long, short or clock)", style)
+    }
+}
+
+// handleParseDuration parses or formats a duration
+func handleParseDuration(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    input := stringArg(req, "duration")
+    if input == "" {
+        return mcp.NewToolResultError("duration parameter is required"), nil
+    }
+
+    mode := req.GetString("mode", "parse")
+    if mode != "parse" && mode != "format" {
+        return mcp.NewToolResultError(fmt.Sprintf("unknown mode %q (use parse or format)", mode)), nil
+    }
+    style := req.GetString("style", "long")
+
+    pd, err := parseDurationFlexible(input)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    formatted, err := formatDurationStyle(pd.d, style)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    humanized, _ := formatDurationStyle(pd.d, "long")
+
+    days, hours, mins, secs, nanos := durationParts(pd.d)
+    result := map[string]interface{}{
+        "input":        input,
+        "input_format": pd.format,
+        "seconds":      pd.d.Seconds(),
+        "milliseconds": pd.d.Milliseconds(),
+        "go":           pd.d.String(),
+        "iso8601":      formatISODuration(pd.d),
+        "humanized":    humanized,
+        "negative":     pd.d < 0,
+        "components": map[string]interface{}{
+            "days":        days,
+            "hours":       hours,
+            "minutes":     mins,
+            "seconds":     secs,
+            "nanoseconds": nanos,
+        },
+    }
+    if mode == "format" {
+        result["formatted"] = formatted
+        result["style"] = style
+    }
+    if pd.nominal {
+        result["warning"] = "ISO 8601 years and months were approximated as 365 and 30 days"
+    }
+
+    logAt(logInfo, "parse_duration: %s %q = %s", mode, input, pd.d)
+    return toolResultJSON(result)
+}
+
+// registerDurationTools adds parse_duration to the server
+func registerDurationTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("parse_duration",
+        mcp.WithDescription("Parse a duration (Go '2h30m', ISO 8601 'PT2H30M', clock '2:30:00' or phrases like '2 hours 30 min') into canonical forms, or humanize one in format mode"),
+        mcp.WithTitleAnnotation("Parse Duration"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("duration",
+            mcp.Required(),
+            mcp.Description("Duration to parse; a bare number is read as seconds"),
+        ),
+        mcp.WithString("mode",
+            mcp.Description("parse (default) normalizes the duration; format also renders it in the requested style"),
+            mcp.Enum("parse", "format"),
+        ),
+        mcp.WithString("style",
+            mcp.Description("Output style for format mode. Defaults to long"),
+            mcp.Enum("long", "short", "clock"),
+        ),
+    ), handleParseDuration)
+}
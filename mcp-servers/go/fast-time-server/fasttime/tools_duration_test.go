@@ -0,0 +1,99 @@
+// -*- coding: utf-8 -*-
+// tools_duration_test.go - Tests for duration parsing and formatting
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestParseDurationFlexible(t *testing.T) {
+    tests := []struct {
+        in     string
+        want   time.Duration
+        format string
+    }{
+        {"2h30m", 150 * time.Minute, "go"},
+        {"PT2H30M", 150 * time.Minute, "iso8601"},
+        {"p1dt0.5s", 24*time.Hour + 500*time.Millisecond, "iso8601"},
+        {"P2W", 14 * 24 * time.Hour, "iso8601"},
+        {"2:30:00", 150 * time.Minute, "clock"},
+        {"90", 90 * time.Second, "seconds"},
+        {"2 hours 30 min", 150 * time.Minute, "loose"},
+        {"1 day, 4h and 5 mins", 28*time.Hour + 5*time.Minute, "loose"},
+        {"1.5 hours", 90 * time.Minute, "loose"},
+    }
+    for _, tt := range tests {
+        pd, err := parseDurationFlexible(tt.in)
+        if err != nil {
+            t.Errorf("parseDurationFlexible(%q) error: %v", tt.in, err)
+            continue
+        }
+        if pd.d != tt.want || pd.format != tt.format {
+            t.Errorf("parseDurationFlexible(%q) = %v (%s), want %v (%s)", tt.in, pd.d, pd.format, tt.want, tt.format)
+        }
+    }
+    for _, bad := range []string{"", "P", "PT", "soon", "2 fortnights", "2 hours please"} {
+        if _, err := parseDurationFlexible(bad); err == nil {
+            t.Errorf("parseDurationFlexible(%q) should fail", bad)
+        }
+    }
+}
+
+func TestFormatDuration(t *testing.T) {
+    d := 26*time.Hour + 3*time.Minute + 4*time.Second
+    if got := formatISODuration(d); got != "P1DT2H3M4S" {
+        t.Errorf("formatISODuration = %s", got)
+    }
+    if got := formatISODuration(1500 * time.Millisecond); got != "PT1.5S" {
+        t.Errorf("formatISODuration(1.5s) = %s", got)
+    }
+    styles := map[string]string{
+        "long":  "1 day, 2 hours, 3 minutes, 4 seconds",
+        "short": "1d 2h 3m 4s",
+        "clock": "26:03:04",
+    }
+    for style, want := range styles {
+        if got, _ := formatDurationStyle(d, style); got != want {
+            t.Errorf("formatDurationStyle(%s) = %q, want %q", style, got, want)
+        }
+    }
+}
+
+func TestHandleParseDuration(t *testing.T) {
+    res, err := handleParseDuration(context.Background(), testRequest("parse_duration", map[string]any{
+        "duration": float64(5400),
+        "mode":     "format",
+        "style":    "short",
+    }))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        Formatted string  `json:"formatted"`
+        ISO8601   string  `json:"iso8601"`
+        Seconds   float64 `json:"seconds"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.Formatted != "1h 30m" || body.ISO8601 != "PT1H30M" || body.Seconds != 5400 {
+        t.Errorf("unexpected result %+v", body)
+    }
+
+    res, _ = handleParseDuration(context.Background(), testRequest("parse_duration", map[string]any{"duration": "P1M"}))
+    if text := extractText(t, res); res.IsError || !json.Valid([]byte(text)) {
+        t.Fatalf("P1M should parse, got %s", text)
+    }
+
+    res, _ = handleParseDuration(context.Background(), testRequest("parse_duration", map[string]any{"duration": "a while"}))
+    if !res.IsError {
+        t.Error("expected error result for unparseable duration")
+    }
+}
@@ -0,0 +1,250 @@
+// -*- coding: utf-8 -*-
+// tools_elapsed.go - elapsed time tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements age_and_elapsed, which breaks the time between two
+// instants into calendar years, months, days, hours, minutes and seconds, and
+// time_until, a countdown to a target that reads "3 days ago" once the target
+// has passed.
+// Months are counted on the wall-clock calendar of the requested timezone;
+// a month added to Jan 31 ends on the last day of February.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// calendarSpan is a calendar-aware breakdown of the time between two instants
+type calendarSpan struct {
+    Years   int `json:"years"`
+    Months  int `json:"months"`
+    Days    int `json:"days"`
+    Hours   int `json:"hours"`
+    Minutes int `json:"minutes"`
+    Seconds int `json:"seconds"`
+}
+
+// daysInMonth returns the number of days in the given month
+func daysInMonth(y int, m time.Month) int {
+    return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// addMonthsClamped adds n months to t, clamping the day to the end of
+// shorter months instead of overflowing as time.AddDate does
+func addMonthsClamped(t time.Time, n int) time.Time {
+    y, m, d := t.Date()
+    h, mi, s := t.Clock()
+    target := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+    if last := daysInMonth(target.Year(), target.Month()); d > last {
+        d = last
+    }
+    return time.Date(target.Year(), target.Month(), d, h, mi, s, t.Nanosecond(), t.Location())
+}
+
+// calendarDiff breaks the span from a to b into calendar units on the wall
+// clock of loc. a must not be after b.
+func calendarDiff(a, b time.Time, loc *time.Location) calendarSpan {
+    a, b = wallClock(a.In(loc)), wallClock(b.In(loc))
+
+    months := (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+    anchor := addMonthsClamped(a, months)
+    for months > 0 && anchor.After(b) {
+        months--
+        anchor = addMonthsClamped(a, months)
+    }
+
+    rest := b.Sub(anchor)
+    return calendarSpan{
+        Years:   months / 12,
+        Months:  months % 12,
+        Days:    int(rest / (24 * time.Hour)),
+        Hours:   int(rest % (24 * time.Hour) / time.Hour),
+        Minutes: int(rest % time.Hour / time.Minute),
+        Seconds: int(rest % time.Minute / time.Second),
+    }
+}
+
+// pluralize formats n with a singular or plural unit
+func pluralize(n int, unit string) string {
+    if n == 1 {
+        return fmt.Sprintf("1 %s", unit)
+    }
+    return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// humanize renders the two most significant non-zero units, e.g. "2 years, 3 months"
+func (sp calendarSpan) humanize() string {
+    units := []struct {
+        n    int
+        name string
+    }{
+        {sp.Years, "year"}, {sp.Months, "month"}, {sp.Days, "day"},
+        {sp.Hours, "hour"}, {sp.Minutes, "minute"}, {sp.Seconds, "second"},
+    }
+    var parts []string
+    for _, u := range units {
+        if u.n == 0 {
+            if len(parts) > 0 {
+                break
+            }
+            continue
+        }
+        parts = append(parts, pluralize(u.n, u.name))
+        if len(parts) == 2 {
+            break
+        }
+    }
+    if len(parts) == 0 {
+        return "0 seconds"
+    }
+    return strings.Join(parts, ", ")
+}
+
+// spanResult builds the shared JSON fields describing the span between from and to
+func spanResult(from, to time.Time, loc *time.Location) (map[string]interface{}, calendarSpan, bool) {
+    negative := to.Before(from)
+    if negative {
+        from, to = to, from
+    }
+    sp := calendarDiff(from, to, loc)
+    total := to.Sub(from)
+    return map[string]interface{}{
+        "breakdown":     sp,
+        "total_days":    int(total.Hours() / 24),
+        "total_hours":   int(total.Hours()),
+        "total_seconds": int64(total.Seconds()),
+        "negative":      negative,
+    }, sp, negative
+}
+
+// handleAgeAndElapsed computes the calendar-aware span between a past time and now or a reference
+func handleAgeAndElapsed(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    fromStr, err := req.RequireString("from")
+    if err != nil {
+        return mcp.NewToolResultError("from parameter is required"), nil
+    }
+
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    from, err := parseTimeInLocation(fromStr, loc)
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("invalid from time: %v", err)), nil
+    }
+    to := currentTime().In(loc)
+    if toStr := req.GetString("to", ""); toStr != "" {
+        if to, err = parseTimeInLocation(toStr, loc); err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("invalid to time: %v", err)), nil
+        }
+    }
+
+    result, sp, negative := spanResult(from, to, loc)
+    result["from"] = from.In(loc).Format(time.RFC3339)
+    result["to"] = to.In(loc).Format(time.RFC3339)
+    result["timezone"] = tz
+    result["age_years"] = sp.Years
+    if negative {
+        result["humanized"] = sp.humanize() + " in the future"
+    } else {
+        result["humanized"] = sp.humanize()
+    }
+
+    logAt(logInfo, "age_and_elapsed: %s to %s = %s", result["from"], result["to"], result["humanized"])
+    return toolResultJSON(result)
+}
+
+// handleTimeUntil returns the remaining time until a target
+func handleTimeUntil(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    targetStr, err := req.RequireString("target")
+    if err != nil {
+        return mcp.NewToolResultError("target parameter is required"), nil
+    }
+
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    target, err := parseTimeInLocation(targetStr, loc)
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("invalid target time: %v", err)), nil
+    }
+    now := currentTime().In(loc)
+    if nowStr := req.GetString("now", ""); nowStr != "" {
+        if now, err = parseTimeInLocation(nowStr, loc); err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("invalid now time: %v", err)), nil
+        }
+    }
+
+    result, sp, past := spanResult(now, target, loc)
+    result["target"] = target.In(loc).Format(time.RFC3339)
+    result["now"] = now.In(loc).Format(time.RFC3339)
+    result["timezone"] = tz
+    result["past"] = past
+    result["seconds_remaining"] = int64(target.Sub(now).Seconds())
+    switch {
+    case target.Equal(now):
+        result["humanized"] = "now"
+    case past:
+        result["humanized"] = sp.humanize() + " ago"
+    default:
+        result["humanized"] = "in " + sp.humanize()
+    }
+
+    logAt(logInfo, "time_until: %s = %s", result["target"], result["humanized"])
+    return toolResultJSON(result)
+}
+
+// registerElapsedTools adds age_and_elapsed and time_until to the server
+func registerElapsedTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("age_and_elapsed",
+        mcp.WithDescription("Compute exact age or elapsed time between a past time and now (or a reference time) in calendar years, months, days, hours, minutes and seconds"),
+        mcp.WithTitleAnnotation("Age and Elapsed Time"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when to is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("from",
+            mcp.Required(),
+            mcp.Description("Start time, e.g. a birth date '1990-05-17' or an RFC3339 timestamp"),
+        ),
+        mcp.WithString("to",
+            mcp.Description("Reference time. Defaults to now"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone whose calendar defines day and month boundaries. Defaults to UTC"),
+        ),
+    ), handleAgeAndElapsed)
+
+    s.AddTool(mcp.NewTool("time_until",
+        mcp.WithDescription("Countdown to a target time: remaining duration as structured fields and a humanized string, or how long ago it was if already past"),
+        mcp.WithTitleAnnotation("Time Until"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Depends on the current time
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("target",
+            mcp.Required(),
+            mcp.Description("Target time in RFC3339 or common formats; times without an offset use the timezone"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for the target and the calendar breakdown. Defaults to UTC"),
+        ),
+        mcp.WithString("now",
+            mcp.Description("Reference time to count from. Defaults to the current time"),
+        ),
+    ), handleTimeUntil)
+}
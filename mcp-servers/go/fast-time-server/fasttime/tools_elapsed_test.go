@@ -0,0 +1,112 @@
+// -*- coding: utf-8 -*-
+// tools_elapsed_test.go - Tests for elapsed time tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestCalendarDiff(t *testing.T) {
+    d := func(s string) time.Time {
+        v, _ := time.Parse("2006-01-02 15:04:05", s)
+        return v
+    }
+    tests := []struct {
+        from, to string
+        want     calendarSpan
+    }{
+        {"1990-05-17 00:00:00", "2025-05-16 00:00:00", calendarSpan{Years: 34, Months: 11, Days: 29}},
+        {"1990-05-17 00:00:00", "2025-05-17 00:00:00", calendarSpan{Years: 35}},
+        {"2025-01-30 00:00:00", "2025-02-27 00:00:00", calendarSpan{Days: 28}},
+        {"2025-01-31 00:00:00", "2025-02-28 00:00:00", calendarSpan{Months: 1}},
+        {"2025-01-31 00:00:00", "2025-03-01 00:00:00", calendarSpan{Months: 1, Days: 1}},
+        {"2024-02-29 12:00:00", "2025-02-28 11:30:15", calendarSpan{Months: 11, Days: 29, Hours: 23, Minutes: 30, Seconds: 15}},
+    }
+    for _, tt := range tests {
+        if got := calendarDiff(d(tt.from), d(tt.to), time.UTC); got != tt.want {
+            t.Errorf("calendarDiff(%s, %s) = %+v, want %+v", tt.from, tt.to, got, tt.want)
+        }
+    }
+}
+
+func TestCalendarSpanHumanize(t *testing.T) {
+    tests := []struct {
+        sp   calendarSpan
+        want string
+    }{
+        {calendarSpan{Years: 2, Months: 3, Days: 5}, "2 years, 3 months"},
+        {calendarSpan{Years: 1, Days: 5}, "1 year"},
+        {calendarSpan{Hours: 3, Minutes: 1}, "3 hours, 1 minute"},
+        {calendarSpan{}, "0 seconds"},
+    }
+    for _, tt := range tests {
+        if got := tt.sp.humanize(); got != tt.want {
+            t.Errorf("humanize(%+v) = %q, want %q", tt.sp, got, tt.want)
+        }
+    }
+}
+
+func TestHandleAgeAndElapsed(t *testing.T) {
+    res, err := handleAgeAndElapsed(context.Background(), testRequest("age_and_elapsed", map[string]any{
+        "from": "1990-05-17",
+        "to":   "2025-08-01",
+    }))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        AgeYears  int    `json:"age_years"`
+        Humanized string `json:"humanized"`
+        Negative  bool   `json:"negative"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.AgeYears != 35 || body.Humanized != "35 years, 2 months" || body.Negative {
+        t.Errorf("unexpected result %+v", body)
+    }
+
+    res, _ = handleAgeAndElapsed(context.Background(), testRequest("age_and_elapsed", map[string]any{"from": "yesterday-ish"}))
+    if !res.IsError {
+        t.Error("expected error result for invalid from time")
+    }
+}
+
+func TestHandleTimeUntil(t *testing.T) {
+    tests := []struct {
+        target, now string
+        want        string
+        past        bool
+    }{
+        {"2025-12-25 09:00:00", "2025-12-22 06:30:00", "in 3 days, 2 hours", false},
+        {"2025-12-22 06:30:00", "2025-12-25 06:30:00", "3 days ago", true},
+        {"2025-12-25 09:00:00", "2025-12-25 09:00:00", "now", false},
+    }
+    for _, tt := range tests {
+        res, err := handleTimeUntil(context.Background(), testRequest("time_until", map[string]any{
+            "target":   tt.target,
+            "now":      tt.now,
+            "timezone": "Europe/Berlin",
+        }))
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        var body struct {
+            Humanized string `json:"humanized"`
+            Past      bool   `json:"past"`
+        }
+        if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+            t.Fatalf("result is not JSON: %v", err)
+        }
+        if body.Humanized != tt.want || body.Past != tt.past {
+            t.Errorf("time_until(%s from %s) = %+v, want %q past=%v", tt.target, tt.now, body, tt.want, tt.past)
+        }
+    }
+}
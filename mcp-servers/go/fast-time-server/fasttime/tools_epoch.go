@@ -0,0 +1,258 @@
+// -*- coding: utf-8 -*-
+// tools_epoch.go - epoch timestamp conversion tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements get_unix_time and from_unix_time, which convert between
+// RFC3339 strings and Unix epoch timestamps in second, millisecond,
+// microsecond or nanosecond precision. from_unix_time can detect the
+// precision from the magnitude of the value, since APIs rarely say which one
+// they use.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// epochPrecision describes one supported timestamp precision
+type epochPrecision struct {
+    name      string
+    perSecond int64
+}
+
+var (
+    precisionSeconds = epochPrecision{"seconds", 1}
+    precisionMillis  = epochPrecision{"milliseconds", 1_000}
+    precisionMicros  = epochPrecision{"microseconds", 1_000_000}
+    precisionNanos   = epochPrecision{"nanoseconds", 1_000_000_000}
+)
+
+// parseEpochPrecision resolves a precision name or abbreviation
+func parseEpochPrecision(name string) (epochPrecision, error) {
+    switch strings.ToLower(strings.TrimSpace(name)) {
+    case "", "s", "sec", "second", "seconds":
+        return precisionSeconds, nil
+    case "ms", "milli", "millis", "millisecond", "milliseconds":
+        return precisionMillis, nil
+    case "us", "µs", "micro", "micros", "microsecond", "microseconds":
+        return precisionMicros, nil
+    case "ns", "nano", "nanos", "nanosecond", "nanoseconds":
+        return precisionNanos, nil
+    default:
+        return epochPrecision{}, fmt.Errorf("unknown precision %q (use seconds, milliseconds, microseconds or nanoseconds)", name)
+    }
+}
+
+// detectEpochPrecision guesses the precision from the number of integer digits.
+// Second timestamps have at most 11 digits until the year 5138.
+func detectEpochPrecision(intDigits string) epochPrecision {
+    switch n := len(strings.TrimLeft(intDigits, "0")); {
+    case n <= 11:
+        return precisionSeconds
+    case n <= 14:
+        return precisionMillis
+    case n <= 17:
+        return precisionMicros
+    default:
+        return precisionNanos
+    }
+}
+
+// parseEpoch converts a decimal epoch string into a time. An empty or "auto"
+// precision triggers detection; the precision actually used is returned.
+func parseEpoch(value, precision string) (time.Time, epochPrecision, error) {
+    value = strings.TrimSpace(value)
+    digits, negative := strings.CutPrefix(value, "-")
+    if !negative {
+        digits, _ = strings.CutPrefix(digits, "+")
+    }
+
+    // At most one sign: ParseUint refuses any that is left
+    intPart, fracPart, _ := strings.Cut(digits, ".")
+    if intPart == "" {
+        intPart = "0"
+    }
+    u, err := strconv.ParseUint(intPart, 10, 63)
+    if err != nil {
+        return time.Time{}, epochPrecision{}, fmt.Errorf("invalid timestamp %q", value)
+    }
+    ip := int64(u)
+
+    var p epochPrecision
+    if strings.EqualFold(precision, "auto") || precision == "" {
+        p = detectEpochPrecision(intPart)
+    } else if p, err = parseEpochPrecision(precision); err != nil {
+        return time.Time{}, epochPrecision{}, err
+    }
+
+    // Sub-unit fractions are kept to nanosecond resolution
+    unitNanos := int64(time.Second) / p.perSecond
+    var fracNanos int64
+    if fracPart != "" {
+        if len(fracPart) > 9 {
+            fracPart = fracPart[:9]
+        }
+        f, err := strconv.ParseUint(fracPart, 10, 63)
+        if err != nil {
+            return time.Time{}, epochPrecision{}, fmt.Errorf("invalid timestamp fraction %q", fracPart)
+        }
+        scale := int64(1)
+        for range fracPart {
+            scale *= 10
+        }
+        fracNanos = int64(f) * unitNanos / scale
+    }
+
+    sec := ip / p.perSecond
+    nsec := (ip%p.perSecond)*unitNanos + fracNanos
+    if negative {
+        sec, nsec = -sec, -nsec
+    }
+    return time.Unix(sec, nsec), p, nil
+}
+
+// epochIn expresses t as an integer timestamp in precision p
+func epochIn(t time.Time, p epochPrecision) int64 {
+    switch p.perSecond {
+    case precisionMillis.perSecond:
+        return t.UnixMilli()
+    case precisionMicros.perSecond:
+        return t.UnixMicro()
+    case precisionNanos.perSecond:
+        return t.UnixNano()
+    default:
+        return t.Unix()
+    }
+}
+
+// stringArg returns an argument as a string, accepting JSON numbers as well
+func stringArg(req mcp.CallToolRequest, key string) string {
+    switch v := req.GetArguments()[key].(type) {
+    case string:
+        return v
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    case nil:
+        return ""
+    default:
+        return fmt.Sprint(v)
+    }
+}
+
+// handleGetUnixTime converts a time (default now) to an epoch timestamp
+func handleGetUnixTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    p, err := parseEpochPrecision(req.GetString("precision", "seconds"))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    t := currentTime()
+    if timeStr := req.GetString("time", ""); timeStr != "" {
+        if t, err = parseTimeInLocation(timeStr, loc); err != nil {
+            return mcp.NewToolResultError(fmt.Sprintf("invalid time format: %v", err)), nil
+        }
+    }
+
+    result := map[string]interface{}{
+        "timestamp":    epochIn(t, p),
+        "precision":    p.name,
+        "time":         t.In(loc).Format(time.RFC3339Nano),
+        "timezone":     loc.String(),
+        "seconds":      t.Unix(),
+        "milliseconds": t.UnixMilli(),
+        "microseconds": t.UnixMicro(),
+        "nanoseconds":  t.UnixNano(),
+    }
+
+    logAt(logInfo, "get_unix_time: timezone=%s precision=%s result=%d", tz, p.name, result["timestamp"])
+    return toolResultJSON(result)
+}
+
+// handleFromUnixTime converts an epoch timestamp to RFC3339 in a timezone
+func handleFromUnixTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    value := stringArg(req, "timestamp")
+    if value == "" {
+        return mcp.NewToolResultError("timestamp parameter is required"), nil
+    }
+
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    precision := req.GetString("precision", "auto")
+    t, p, err := parseEpoch(value, precision)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    result := map[string]interface{}{
+        "time":               t.In(loc).Format(time.RFC3339Nano),
+        "utc":                t.UTC().Format(time.RFC3339Nano),
+        "timezone":           loc.String(),
+        "precision":          p.name,
+        "precision_detected": strings.EqualFold(precision, "auto") || precision == "",
+        "seconds":            t.Unix(),
+    }
+
+    logAt(logInfo, "from_unix_time: %s (%s) timezone=%s", value, p.name, tz)
+    return toolResultJSON(result)
+}
+
+// registerEpochTools adds get_unix_time and from_unix_time to the server
+func registerEpochTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("get_unix_time",
+        mcp.WithDescription("Convert a time (default: now) to a Unix epoch timestamp in seconds, milliseconds, microseconds or nanoseconds"),
+        mcp.WithTitleAnnotation("Get Unix Time"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("time",
+            mcp.Description("Time in RFC3339 or common formats like '2006-01-02 15:04:05'. Defaults to now"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone used for times without an offset and for the RFC3339 echo. Defaults to UTC"),
+        ),
+        mcp.WithString("precision",
+            mcp.Description("Timestamp precision. Defaults to seconds"),
+            mcp.Enum("seconds", "milliseconds", "microseconds", "nanoseconds"),
+        ),
+    ), handleGetUnixTime)
+
+    s.AddTool(mcp.NewTool("from_unix_time",
+        mcp.WithDescription("Convert a Unix epoch timestamp to an RFC3339 time in a timezone, detecting second/milli/micro/nano precision if not given"),
+        mcp.WithTitleAnnotation("From Unix Time"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("timestamp",
+            mcp.Required(),
+            mcp.Description("Epoch timestamp, e.g. '1736503200000'. Pass as a string to keep nanosecond precision; decimals are allowed"),
+        ),
+        mcp.WithString("precision",
+            mcp.Description("Precision of the timestamp. Defaults to auto (detected from the number of digits)"),
+            mcp.Enum("auto", "seconds", "milliseconds", "microseconds", "nanoseconds"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for the result. Defaults to UTC"),
+        ),
+    ), handleFromUnixTime)
+}
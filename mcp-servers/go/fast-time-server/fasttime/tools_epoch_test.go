@@ -0,0 +1,130 @@
+// -*- coding: utf-8 -*-
+// tools_epoch_test.go - Tests for epoch conversion tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestParseEpoch(t *testing.T) {
+    want := time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC)
+    tests := []struct {
+        name      string
+        value     string
+        precision string
+        wantTime  time.Time
+        wantPrec  string
+    }{
+        {"auto seconds", "1736503200", "auto", want, "seconds"},
+        {"auto millis", "1736503200000", "auto", want, "milliseconds"},
+        {"auto micros", "1736503200000000", "", want, "microseconds"},
+        {"auto nanos", "1736503200000000000", "auto", want, "nanoseconds"},
+        {"explicit ms", "1736503200123", "ms", want.Add(123 * time.Millisecond), "milliseconds"},
+        {"fractional seconds", "1736503200.5", "seconds", want.Add(500 * time.Millisecond), "seconds"},
+        {"negative seconds", "-86400", "seconds", time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), "seconds"},
+        {"explicit plus", "+86400", "seconds", time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), "seconds"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, p, err := parseEpoch(tt.value, tt.precision)
+            if err != nil {
+                t.Fatalf("unexpected error: %v", err)
+            }
+            if !got.Equal(tt.wantTime) {
+                t.Errorf("want %v, got %v", tt.wantTime, got.UTC())
+            }
+            if p.name != tt.wantPrec {
+                t.Errorf("want precision %s, got %s", tt.wantPrec, p.name)
+            }
+        })
+    }
+
+    for _, value := range []string{"abc", "--5", "+-5", "-+5", "1.-5"} {
+        if _, _, err := parseEpoch(value, "auto"); err == nil {
+            t.Errorf("expected error for %q", value)
+        }
+    }
+    if _, _, err := parseEpoch("1", "fortnights"); err == nil {
+        t.Error("expected error for unknown precision")
+    }
+}
+
+func TestHandleGetUnixTime(t *testing.T) {
+    req := testRequest("get_unix_time", map[string]any{
+        "time":      "2025-01-10 19:00:00",
+        "timezone":  "Asia/Tokyo",
+        "precision": "milliseconds",
+    })
+    res, err := handleGetUnixTime(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+
+    var body struct {
+        Timestamp int64  `json:"timestamp"`
+        Precision string `json:"precision"`
+        Time      string `json:"time"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.Timestamp != 1736503200000 || body.Precision != "milliseconds" {
+        t.Errorf("unexpected result: %+v", body)
+    }
+    if body.Time != "2025-01-10T19:00:00+09:00" {
+        t.Errorf("want Tokyo time echo, got %s", body.Time)
+    }
+
+    // invalid precision -> error
+    res, _ = handleGetUnixTime(context.Background(), testRequest("get_unix_time", map[string]any{"precision": "weeks"}))
+    if !res.IsError {
+        t.Error("expected error result for invalid precision")
+    }
+}
+
+func TestHandleFromUnixTime(t *testing.T) {
+    req := testRequest("from_unix_time", map[string]any{
+        "timestamp": "1736503200000",
+        "timezone":  "America/New_York",
+    })
+    res, err := handleFromUnixTime(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+
+    var body struct {
+        Time      string `json:"time"`
+        UTC       string `json:"utc"`
+        Precision string `json:"precision"`
+        Detected  bool   `json:"precision_detected"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.Time != "2025-01-10T05:00:00-05:00" || body.UTC != "2025-01-10T10:00:00Z" {
+        t.Errorf("unexpected times: %+v", body)
+    }
+    if body.Precision != "milliseconds" || !body.Detected {
+        t.Errorf("precision should be detected as milliseconds: %+v", body)
+    }
+
+    // numeric argument is accepted too
+    res, _ = handleFromUnixTime(context.Background(), testRequest("from_unix_time", map[string]any{"timestamp": float64(1736503200)}))
+    if res.IsError {
+        t.Errorf("numeric timestamp should be accepted: %+v", res)
+    }
+
+    // missing timestamp -> error
+    res, _ = handleFromUnixTime(context.Background(), testRequest("from_unix_time", map[string]any{}))
+    if !res.IsError {
+        t.Error("expected error result for missing timestamp")
+    }
+}
@@ -0,0 +1,241 @@
+// -*- coding: utf-8 -*-
+// tools_fiscal.go - fiscal calendar tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements fiscal_quarter_of, which places a date in the fiscal
+// year, quarter, period and week of a fiscal calendar (fiscal.go), and
+// fiscal_period_boundaries, which lists the quarters or periods of a fiscal
+// year. Both take a calendar name and may override its start_month, pattern
+// and naming for one call, so agents need not define a calendar for a
+// one-off question.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// fiscalCalendarArg returns the calendar named by the request with any
+// start_month, pattern and naming overrides applied
+func fiscalCalendarArg(req mcp.CallToolRequest) (*fiscalCalendar, error) {
+    name := req.GetString("calendar", defaultFiscalCalendar)
+    base, ok := fiscalCalendars[name]
+    if !ok {
+        return nil, fieldError("calendar", fmt.Errorf("unknown fiscal calendar %q (have %s)", name, strings.Join(fiscalCalendarNames(fiscalCalendars), ", ")))
+    }
+    month := req.GetInt("start_month", 0)
+    pattern := req.GetString("pattern", "")
+    naming := req.GetString("naming", "")
+    if month == 0 && pattern == "" && naming == "" {
+        return base, nil
+    }
+
+    c := *base
+    if month != 0 {
+        c.StartMonth, c.StartDay = month, 0
+    }
+    if pattern != "" {
+        c.Pattern = pattern
+    }
+    if naming != "" {
+        c.Naming = naming
+    }
+    if c.Pattern == fiscalMonths {
+        c.WeekEndsOn, c.YearEnd = "", ""
+    } else if !base.weekBased() {
+        c.StartDay = 0
+    }
+    if err := c.validate(); err != nil {
+        return nil, err
+    }
+    return &c, nil
+}
+
+// spanIndex returns the position of the span containing day d
+func spanIndex(spans []fiscalSpan, d time.Time) int {
+    for i, s := range spans {
+        if !d.Before(s.start) && !d.After(s.end) {
+            return i
+        }
+    }
+    return -1
+}
+
+// handleFiscalQuarterOf places a date in its fiscal year, quarter and period
+func handleFiscalQuarterOf(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    cal, err := fiscalCalendarArg(req)
+    if err != nil {
+        return toolError(err), nil
+    }
+    loc, err := loadLocation(req.GetString("timezone", "UTC"))
+    if err != nil {
+        return toolError(fieldError("timezone", err)), nil
+    }
+    t, err := timeArgIn(req, loc)
+    if err != nil {
+        return toolError(fieldError("time", err)), nil
+    }
+    local := t.In(loc)
+    day := civilDate(local.Year(), local.Month(), local.Day())
+
+    label := cal.yearOf(day)
+    year := cal.yearSpan(label)
+    quarters := cal.quarters(label)
+    periods := cal.periods(label)
+    q, p := spanIndex(quarters, day), spanIndex(periods, day)
+    dayOfYear := int(day.Sub(year.start).Hours()/24) + 1
+
+    result := map[string]interface{}{
+        "calendar":                  cal.Name,
+        "definition":                cal,
+        "time":                      local.Format(time.RFC3339),
+        "date":                      day.Format("2006-01-02"),
+        "fiscal_year":               label,
+        "fiscal_year_label":         year.Label,
+        "quarter":                   q + 1,
+        "quarter_label":             quarters[q].Label,
+        "period":                    p + 1,
+        "week":                      (dayOfYear-1)/7 + 1,
+        "day_of_year":               dayOfYear,
+        "year_bounds":               year,
+        "quarter_bounds":            quarters[q],
+        "period_bounds":             periods[p],
+        "days_remaining_in_quarter": int(quarters[q].end.Sub(day).Hours() / 24),
+        "days_remaining_in_year":    int(year.end.Sub(day).Hours() / 24),
+    }
+
+    logAt(logInfo, "fiscal_quarter_of: %s is %s on calendar %s", day.Format("2006-01-02"), quarters[q].Label, cal.Name)
+    return toolResultJSON(result)
+}
+
+// handleFiscalPeriodBoundaries lists the quarters or periods of a fiscal year
+func handleFiscalPeriodBoundaries(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    cal, err := fiscalCalendarArg(req)
+    if err != nil {
+        return toolError(err), nil
+    }
+    loc, err := loadLocation(req.GetString("timezone", "UTC"))
+    if err != nil {
+        return toolError(fieldError("timezone", err)), nil
+    }
+    now := currentTime().In(loc)
+    label := req.GetInt("fiscal_year", cal.yearOf(civilDate(now.Year(), now.Month(), now.Day())))
+    if label < 1 || label > 9998 {
+        return mcp.NewToolResultError("fiscal_year must be between 1 and 9998"), nil
+    }
+    quarter := req.GetInt("quarter", 0)
+    if quarter < 0 || quarter > 4 {
+        return mcp.NewToolResultError("quarter must be between 1 and 4"), nil
+    }
+
+    var spans []fiscalSpan
+    granularity := strings.ToLower(req.GetString("granularity", "quarter"))
+    switch granularity {
+    case "year":
+        spans = []fiscalSpan{cal.yearSpan(label)}
+    case "quarter":
+        spans = cal.quarters(label)
+        if quarter > 0 {
+            spans = spans[quarter-1 : quarter]
+        }
+    case "period", "month":
+        granularity = "period"
+        spans = cal.periods(label)
+        if quarter > 0 {
+            spans = spans[3*(quarter-1) : 3*quarter]
+        }
+    default:
+        return mcp.NewToolResultError(fmt.Sprintf("invalid granularity %q: use year, quarter or period", granularity)), nil
+    }
+
+    year := cal.yearSpan(label)
+    result := map[string]interface{}{
+        "calendar":          cal.Name,
+        "definition":        cal,
+        "fiscal_year":       label,
+        "fiscal_year_label": year.Label,
+        "year_bounds":       year,
+        "granularity":       granularity,
+        "boundaries":        spans,
+    }
+    if cal.weekBased() {
+        result["weeks_in_year"] = year.Weeks
+    }
+
+    logAt(logInfo, "fiscal_period_boundaries: %s %s on calendar %s", year.Label, granularity, cal.Name)
+    return toolResultJSON(result)
+}
+
+// fiscalCalendarOptions are the calendar arguments shared by the fiscal tools
+func fiscalCalendarOptions() []mcp.ToolOption {
+    return []mcp.ToolOption{
+        mcp.WithString("calendar",
+            mcp.Description(fmt.Sprintf("Fiscal calendar name: calendar, us-federal, uk-tax, india, japan, australia, retail-454 or one from -fiscal-calendars. Defaults to %s", defaultFiscalCalendar)),
+        ),
+        mcp.WithNumber("start_month",
+            mcp.Description("Override the month (1-12) in which the fiscal year starts"),
+            mcp.Min(1),
+            mcp.Max(12),
+        ),
+        mcp.WithString("pattern",
+            mcp.Description("Override the period pattern: months, or weeks per period with 4-4-5, 4-5-4 or 5-4-4"),
+            mcp.Enum(fiscalMonths, fiscal445, fiscal454, fiscal544),
+        ),
+        mcp.WithString("naming",
+            mcp.Description("Override whether FY2025 is the fiscal year that ends (end) or starts (start) in 2025"),
+            mcp.Enum("end", "start"),
+        ),
+    }
+}
+
+// registerFiscalTools adds fiscal_quarter_of and fiscal_period_boundaries to the server
+func registerFiscalTools(s *server.MCPServer) {
+    opts := append([]mcp.ToolOption{
+        mcp.WithDescription("Translate a date into its fiscal year, quarter, period and week on a configurable fiscal calendar (start month or 4-4-5 style week patterns)"),
+        mcp.WithTitleAnnotation("Fiscal Quarter Of"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Depends on the current time when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("time",
+            mcp.Description("Date or time to place, RFC3339 or local to timezone. Defaults to now"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone whose calendar date is used. Defaults to UTC"),
+        ),
+    }, fiscalCalendarOptions()...)
+    s.AddTool(mcp.NewTool("fiscal_quarter_of", opts...), handleFiscalQuarterOf)
+
+    opts = append([]mcp.ToolOption{
+        mcp.WithDescription("List the start and end dates of the quarters or periods of a fiscal year on a configurable fiscal calendar"),
+        mcp.WithTitleAnnotation("Fiscal Period Boundaries"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Depends on the current time when fiscal_year is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithNumber("fiscal_year",
+            mcp.Description("Fiscal year, e.g. 2025 for FY2025. Defaults to the current fiscal year"),
+        ),
+        mcp.WithString("granularity",
+            mcp.Description("Boundaries to list: year, quarter or period. Defaults to quarter"),
+            mcp.Enum("year", "quarter", "period"),
+        ),
+        mcp.WithNumber("quarter",
+            mcp.Description("Only list this quarter (1-4), or its periods"),
+            mcp.Min(1),
+            mcp.Max(4),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone that decides the current fiscal year. Defaults to UTC"),
+        ),
+    }, fiscalCalendarOptions()...)
+    s.AddTool(mcp.NewTool("fiscal_period_boundaries", opts...), handleFiscalPeriodBoundaries)
+}
@@ -0,0 +1,100 @@
+// -*- coding: utf-8 -*-
+// tools_fiscal_test.go - Tests for the fiscal calendar tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+)
+
+func TestHandleFiscalQuarterOf(t *testing.T) {
+    res, err := handleFiscalQuarterOf(context.Background(), testRequest("fiscal_quarter_of", map[string]any{
+        "time":     "2025-03-15T23:30:00Z",
+        "timezone": "Asia/Tokyo", // already 16 March
+        "calendar": "us-federal",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("fiscal_quarter_of = %v, %v", res, err)
+    }
+    var body struct {
+        Date      string     `json:"date"`
+        Year      int        `json:"fiscal_year"`
+        Label     string     `json:"quarter_label"`
+        Quarter   int        `json:"quarter"`
+        Period    int        `json:"period"`
+        Week      int        `json:"week"`
+        Remaining int        `json:"days_remaining_in_quarter"`
+        Bounds    fiscalSpan `json:"quarter_bounds"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatal(err)
+    }
+    if body.Date != "2025-03-16" || body.Year != 2025 || body.Label != "FY2025 Q2" || body.Quarter != 2 || body.Period != 6 ||
+        body.Week != 24 || body.Remaining != 15 || body.Bounds.Start != "2025-01-01" || body.Bounds.End != "2025-03-31" {
+        t.Errorf("body = %+v", body)
+    }
+
+    // Overrides turn the calendar year into a 4-4-5 year starting in July
+    res, _ = handleFiscalQuarterOf(context.Background(), testRequest("fiscal_quarter_of", map[string]any{
+        "time": "2025-06-29", "start_month": float64(7), "pattern": "4-4-5",
+    }))
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil || body.Year != 2026 || body.Quarter != 1 || body.Period != 1 {
+        t.Errorf("override: %s", extractText(t, res))
+    }
+
+    for _, args := range []map[string]any{
+        {"calendar": "lunar"},
+        {"start_month": float64(13)},
+        {"pattern": "3-3-3"},
+        {"timezone": "Mars/Base"},
+        {"time": "someday"},
+    } {
+        if res, _ := handleFiscalQuarterOf(context.Background(), testRequest("fiscal_quarter_of", args)); !res.IsError {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
+
+func TestHandleFiscalPeriodBoundaries(t *testing.T) {
+    res, err := handleFiscalPeriodBoundaries(context.Background(), testRequest("fiscal_period_boundaries", map[string]any{
+        "fiscal_year": float64(2023),
+        "calendar":    "retail-454",
+        "granularity": "period",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("fiscal_period_boundaries = %v, %v", res, err)
+    }
+    var body struct {
+        Weeks      int          `json:"weeks_in_year"`
+        Boundaries []fiscalSpan `json:"boundaries"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatal(err)
+    }
+    if body.Weeks != 53 || len(body.Boundaries) != 12 || body.Boundaries[0].Start != "2023-01-29" || body.Boundaries[11].Weeks != 5 {
+        t.Errorf("body = %+v", body)
+    }
+
+    res, _ = handleFiscalPeriodBoundaries(context.Background(), testRequest("fiscal_period_boundaries", map[string]any{
+        "fiscal_year": float64(2025), "calendar": "india", "quarter": float64(4),
+    }))
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil || len(body.Boundaries) != 1 ||
+        body.Boundaries[0].Label != "FY2025 Q4" || body.Boundaries[0].Start != "2025-01-01" || body.Boundaries[0].End != "2025-03-31" {
+        t.Errorf("india Q4: %s", extractText(t, res))
+    }
+
+    for _, args := range []map[string]any{
+        {"granularity": "week"},
+        {"quarter": float64(5)},
+        {"fiscal_year": float64(0)},
+    } {
+        if res, _ := handleFiscalPeriodBoundaries(context.Background(), testRequest("fiscal_period_boundaries", args)); !res.IsError {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
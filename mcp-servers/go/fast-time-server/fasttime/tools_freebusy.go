@@ -0,0 +1,237 @@
+// -*- coding: utf-8 -*-
+// tools_freebusy.go - free/busy tool for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements get_free_busy, registered only when
+// -free-busy-calendars names calendars (freebusy.go). It asks each calendar
+// for its busy periods in a window, merges them, and returns the free
+// windows left, optionally only within everyone's working hours, so that
+// meeting suggestions rest on real availability. meeting_overlap_windows
+// uses the same busy periods through its calendars parameter.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// get_free_busy defaults and limits
+const (
+    defaultFreeBusyDays    = 7
+    maxFreeBusyDays        = 31
+    defaultFreeSlotMinutes = 30
+)
+
+// mergeIntervals returns the union of intervals as a sorted list
+func mergeIntervals(list []interval) []interval {
+    sorted := append([]interval(nil), list...)
+    sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+    var out []interval
+    for _, iv := range sorted {
+        if n := len(out); n > 0 && !iv.start.After(out[n-1].end) {
+            if iv.end.After(out[n-1].end) {
+                out[n-1].end = iv.end
+            }
+            continue
+        }
+        out = append(out, iv)
+    }
+    return out
+}
+
+// subtractIntervals removes the sorted, merged busy list from the sorted
+// list free
+func subtractIntervals(free, busy []interval) []interval {
+    var out []interval
+    j := 0
+    for _, iv := range free {
+        s := iv.start
+        for j < len(busy) && !busy[j].end.After(s) {
+            j++
+        }
+        for k := j; k < len(busy) && busy[k].start.Before(iv.end); k++ {
+            if busy[k].start.After(s) {
+                out = append(out, interval{s, busy[k].start})
+            }
+            if busy[k].end.After(s) {
+                s = busy[k].end
+            }
+        }
+        if s.Before(iv.end) {
+            out = append(out, interval{s, iv.end})
+        }
+    }
+    return out
+}
+
+// calendarBusyIntervals asks cals for their busy periods in [from, to) and
+// returns them merged; any failing calendar is an error, since a meeting
+// could otherwise be put on top of its events
+func calendarBusyIntervals(ctx context.Context, list string, from, to time.Time) ([]interval, []string, error) {
+    cals, err := lookupFreeBusyCalendars(list)
+    if err != nil {
+        return nil, nil, err
+    }
+    var busy []interval
+    names := make([]string, 0, len(cals))
+    for _, r := range fetchBusy(ctx, cals, from, to) {
+        if r.err != nil {
+            return nil, nil, fmt.Errorf("calendar %s: %v", r.cal.Name, r.err)
+        }
+        for _, b := range r.busy {
+            busy = append(busy, b.interval)
+        }
+        names = append(names, r.cal.Name)
+    }
+    return mergeIntervals(busy), names, nil
+}
+
+// handleGetFreeBusy reports the busy and free times of configured calendars
+func handleGetFreeBusy(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    cals, err := lookupFreeBusyCalendars(req.GetString("calendars", ""))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    loc, err := loadLocation(req.GetString("timezone", defaultTimezoneFor(ctx)))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    from := currentTime()
+    if raw := req.GetString("from", ""); raw != "" {
+        if from, _, err = parseScheduleTime(raw, loc); err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+    }
+    to := from.AddDate(0, 0, defaultFreeBusyDays)
+    if raw := req.GetString("to", ""); raw != "" {
+        if to, _, err = parseScheduleTime(raw, loc); err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+    }
+    switch {
+    case !to.After(from):
+        return mcp.NewToolResultError("to must be after from"), nil
+    case to.Sub(from) > maxFreeBusyDays*24*time.Hour:
+        return mcp.NewToolResultError(fmt.Sprintf("the window may span at most %d days", maxFreeBusyDays)), nil
+    }
+
+    slot := time.Duration(req.GetInt("duration", defaultFreeSlotMinutes)) * time.Minute
+    if slot <= 0 {
+        return mcp.NewToolResultError("duration must be a positive number of minutes"), nil
+    }
+    window := []interval{{from, to}}
+    if raw := req.GetString("working_hours", ""); raw != "" {
+        wh, err := parseWorkingHours(raw)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        window = workIntervals(loc, wh, from, to, req.GetBool("skip_weekends", true))
+    }
+
+    results := fetchBusy(ctx, cals, from, to)
+    var all []interval
+    var failed []string
+    calendars := make([]map[string]interface{}, 0, len(results))
+    for _, r := range results {
+        entry := map[string]interface{}{"name": r.cal.Name, "type": r.cal.Type}
+        if r.err != nil {
+            entry["error"] = r.err.Error()
+            failed = append(failed, fmt.Sprintf("%s: %v", r.cal.Name, r.err))
+            calendars = append(calendars, entry)
+            continue
+        }
+        busy := make([]map[string]interface{}, 0, len(r.busy))
+        for _, b := range r.busy {
+            busy = append(busy, map[string]interface{}{
+                "start":  b.start.In(loc).Format(time.RFC3339),
+                "end":    b.end.In(loc).Format(time.RFC3339),
+                "status": b.status,
+            })
+            all = append(all, b.interval)
+        }
+        entry["busy"] = busy
+        calendars = append(calendars, entry)
+    }
+    if len(failed) == len(results) {
+        return mcp.NewToolResultError("no calendar answered: " + strings.Join(failed, "; ")), nil
+    }
+
+    merged := mergeIntervals(all)
+    busy := make([]map[string]interface{}, 0, len(merged))
+    for _, iv := range merged {
+        busy = append(busy, map[string]interface{}{
+            "start": iv.start.In(loc).Format(time.RFC3339),
+            "end":   iv.end.In(loc).Format(time.RFC3339),
+        })
+    }
+    free := make([]map[string]interface{}, 0)
+    for _, iv := range subtractIntervals(window, merged) {
+        if iv.end.Sub(iv.start) < slot {
+            continue
+        }
+        free = append(free, map[string]interface{}{
+            "start":   iv.start.In(loc).Format(time.RFC3339),
+            "end":     iv.end.In(loc).Format(time.RFC3339),
+            "minutes": int(iv.end.Sub(iv.start).Minutes()),
+        })
+    }
+
+    result := map[string]interface{}{
+        "timezone":  loc.String(),
+        "from":      from.In(loc).Format(time.RFC3339),
+        "to":        to.In(loc).Format(time.RFC3339),
+        "calendars": calendars,
+        "busy":      busy,
+        "free":      free,
+    }
+    if len(failed) > 0 {
+        // Free windows may overlap events of the calendars that failed
+        result["note"] = "free windows leave out calendars that did not answer: " + strings.Join(failed, "; ")
+    }
+
+    logAt(logInfo, "get_free_busy: %d calendars, %d busy, %d free", len(cals), len(busy), len(free))
+    return toolResultJSON(result)
+}
+
+// registerFreeBusyTools adds get_free_busy to the server
+func registerFreeBusyTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("get_free_busy",
+        mcp.WithDescription("Read busy times from the configured CalDAV and Google calendars and list the free windows between them, optionally within working hours"),
+        mcp.WithTitleAnnotation("Get Free/Busy"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Calendars change between calls
+        mcp.WithOpenWorldHintAnnotation(true),   // Calls calendar servers
+        mcp.WithString("calendars",
+            mcp.Description("Comma-separated calendar names from -free-busy-calendars. Defaults to all"),
+        ),
+        mcp.WithString("from",
+            mcp.Description("Start of the window, RFC3339 or local to timezone. Defaults to now"),
+        ),
+        mcp.WithString("to",
+            mcp.Description("End of the window (at most 31 days after from). Defaults to 7 days after from"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for the output, local times and working hours. Defaults to the session or server default"),
+        ),
+        mcp.WithString("working_hours",
+            mcp.Description("Only list free time within these local hours, as HH:MM-HH:MM"),
+        ),
+        mcp.WithBoolean("skip_weekends",
+            mcp.Description("With working_hours, leave out Saturday and Sunday. Defaults to true"),
+        ),
+        mcp.WithNumber("duration",
+            mcp.Description("Shortest free window listed, in minutes. Defaults to 30"),
+        ),
+    ), handleGetFreeBusy)
+}
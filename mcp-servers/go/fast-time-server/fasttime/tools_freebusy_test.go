@@ -0,0 +1,182 @@
+// -*- coding: utf-8 -*-
+// tools_freebusy_test.go - tests for get_free_busy
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// useTestCalendars installs CalDAV calendars served by lines per name
+func useTestCalendars(t *testing.T, calendars map[string]string) {
+    t.Helper()
+    useTestOutbound(t, nil)
+    prev := freeBusyCalendars
+    t.Cleanup(func() { freeBusyCalendars = prev })
+    freeBusyCalendars = nil
+    for _, name := range []string{"ada", "bob", "down"} {
+        lines, ok := calendars[name]
+        if !ok {
+            continue
+        }
+        url := caldavServer(t, lines).URL
+        if name == "down" {
+            dead := httptest.NewServer(http.NotFoundHandler())
+            dead.Close()
+            url = dead.URL
+        }
+        c := &freeBusyCalendar{Name: name, Type: "caldav", URL: url, Username: "ada", Password: "s3cret"}
+        if err := c.check(); err != nil {
+            t.Fatal(err)
+        }
+        freeBusyCalendars = append(freeBusyCalendars, c)
+    }
+}
+
+// freeBusyCall calls get_free_busy; ok is false for a tool error
+func freeBusyCall(t *testing.T, args map[string]any) (out map[string]interface{}, ok bool) {
+    t.Helper()
+    res, err := handleGetFreeBusy(context.Background(), testRequest("get_free_busy", args))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if res.IsError {
+        return map[string]interface{}{"error": res.Content}, false
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    return out, true
+}
+
+// callFreeBusy calls get_free_busy and fails the test on a tool error
+func callFreeBusy(t *testing.T, args map[string]any) map[string]interface{} {
+    t.Helper()
+    out, ok := freeBusyCall(t, args)
+    if !ok {
+        t.Fatalf("get_free_busy(%v): %v", args, out["error"])
+    }
+    return out
+}
+
+func TestIntervalSetOperations(t *testing.T) {
+    at := func(h int) time.Time { return time.Date(2025, 3, 10, h, 0, 0, 0, time.UTC) }
+    merged := mergeIntervals([]interval{{at(13), at(15)}, {at(9), at(10)}, {at(14), at(16)}, {at(10), at(11)}})
+    if len(merged) != 2 || !merged[0].end.Equal(at(11)) || !merged[1].start.Equal(at(13)) || !merged[1].end.Equal(at(16)) {
+        t.Errorf("merged = %v", merged)
+    }
+    free := subtractIntervals([]interval{{at(8), at(12)}, {at(12), at(18)}}, merged)
+    want := []interval{{at(8), at(9)}, {at(11), at(12)}, {at(12), at(13)}, {at(16), at(18)}}
+    if len(free) != len(want) {
+        t.Fatalf("free = %v", free)
+    }
+    for i := range want {
+        if !free[i].start.Equal(want[i].start) || !free[i].end.Equal(want[i].end) {
+            t.Errorf("free[%d] = %v, want %v", i, free[i], want[i])
+        }
+    }
+}
+
+func TestGetFreeBusy(t *testing.T) {
+    useTestCalendars(t, map[string]string{
+        "ada": "FREEBUSY:20250310T140000Z/20250310T150000Z\r\n",
+        "bob": "FREEBUSY;FBTYPE=BUSY-UNAVAILABLE:20250310T143000Z/20250310T160000Z,20250311T130000Z/PT1H\r\n",
+    })
+    out := callFreeBusy(t, map[string]any{
+        "from": "2025-03-10T00:00:00Z", "to": "2025-03-12T00:00:00Z",
+        "timezone": "America/New_York", "working_hours": "09:00-17:00", "duration": 60,
+    })
+    busy := out["busy"].([]interface{})
+    if len(busy) != 2 || busy[0].(map[string]interface{})["start"] != "2025-03-10T10:00:00-04:00" || busy[0].(map[string]interface{})["end"] != "2025-03-10T12:00:00-04:00" {
+        t.Errorf("busy = %v", busy)
+    }
+    free := out["free"].([]interface{})
+    // 9-10 and 12-17 on Monday; 9-17 on Tuesday less 9-10
+    want := []string{"2025-03-10T09:00:00-04:00", "2025-03-10T12:00:00-04:00", "2025-03-11T10:00:00-04:00"}
+    if len(free) != len(want) {
+        t.Fatalf("free = %v", free)
+    }
+    for i, w := range want {
+        if free[i].(map[string]interface{})["start"] != w {
+            t.Errorf("free[%d] = %v, want start %s", i, free[i], w)
+        }
+    }
+    cals := out["calendars"].([]interface{})
+    if b := cals[1].(map[string]interface{})["busy"].([]interface{}); b[0].(map[string]interface{})["status"] != "unavailable" {
+        t.Errorf("bob = %v", cals[1])
+    }
+}
+
+func TestGetFreeBusyPartialFailure(t *testing.T) {
+    useTestCalendars(t, map[string]string{"ada": "FREEBUSY:20250310T140000Z/20250310T150000Z\r\n", "down": ""})
+    out := callFreeBusy(t, map[string]any{"from": "2025-03-10T00:00:00Z", "to": "2025-03-12T00:00:00Z"})
+    if out["note"] == nil || out["calendars"].([]interface{})[1].(map[string]interface{})["error"] == nil {
+        t.Errorf("failed calendar not reported: %v", out)
+    }
+    if out, ok := freeBusyCall(t, map[string]any{"calendars": "down"}); ok {
+        t.Errorf("all calendars failing should be an error: %v", out)
+    }
+}
+
+func TestGetFreeBusyErrors(t *testing.T) {
+    freeBusyCalendars = nil
+    if _, ok := freeBusyCall(t, nil); ok {
+        t.Error("get_free_busy without calendars should fail")
+    }
+    useTestCalendars(t, map[string]string{"ada": ""})
+    for _, args := range []map[string]any{
+        {"calendars": "nobody"},
+        {"timezone": "Mars/Olympus"},
+        {"from": "2025-03-10T00:00:00Z", "to": "2025-03-09T00:00:00Z"},
+        {"from": "2025-03-10T00:00:00Z", "to": "2025-06-10T00:00:00Z"},
+        {"working_hours": "late"},
+        {"duration": 0},
+        {"from": "someday"},
+    } {
+        if out, ok := freeBusyCall(t, args); ok {
+            t.Errorf("get_free_busy(%v) should fail: %v", args, out)
+        }
+    }
+}
+
+func TestMeetingOverlapWindowsWithCalendars(t *testing.T) {
+    // Busy 13:00-16:00 UTC on the Monday leaves 16:00-17:00 for London
+    useTestCalendars(t, map[string]string{"ada": "FREEBUSY:20250310T130000Z/20250310T160000Z\r\n"})
+    res, err := handleMeetingOverlapWindows(context.Background(), testRequest("meeting_overlap_windows", map[string]any{
+        "timezones": "Europe/London", "start_date": "2025-03-10", "days": 1, "calendars": "ada",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("meeting_overlap_windows: %v %v", err, res)
+    }
+    var out map[string]interface{}
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatal(err)
+    }
+    windows := out["windows"].([]interface{})
+    if len(windows) != 2 || out["calendars"].([]interface{})[0] != "ada" {
+        t.Fatalf("windows = %v", out)
+    }
+    for _, w := range windows {
+        s, _ := time.Parse(time.RFC3339, w.(map[string]interface{})["start"].(string))
+        e, _ := time.Parse(time.RFC3339, w.(map[string]interface{})["end"].(string))
+        if s.Before(time.Date(2025, 3, 10, 16, 0, 0, 0, time.UTC)) && e.After(time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC)) {
+            t.Errorf("window %v overlaps the busy time", w)
+        }
+    }
+
+    freeBusyCalendars = nil
+    res, _ = handleMeetingOverlapWindows(context.Background(), testRequest("meeting_overlap_windows", map[string]any{
+        "timezones": "Europe/London", "calendars": "ada",
+    }))
+    if !res.IsError {
+        t.Error("calendars without -free-busy-calendars should fail")
+    }
+}
@@ -0,0 +1,172 @@
+// -*- coding: utf-8 -*-
+// tools_groups.go - saved meeting participant groups
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements save_participant_group, list_participant_groups and
+// delete_participant_group. A group stores the timezones (and optionally the
+// working hours) of a recurring set of meeting participants so that
+// meeting_overlap_windows can be called with group="platform-team" instead of
+// repeating the zone list. Groups live in the Store and survive restarts when
+// -db is set.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// maxGroupMembers bounds the number of zones in a participant group
+const maxGroupMembers = 50
+
+// splitZones parses a comma-separated zone list, validating every entry
+func splitZones(arg string) ([]string, error) {
+    var zones []string
+    for _, tz := range strings.Split(arg, ",") {
+        tz = strings.TrimSpace(tz)
+        if tz == "" {
+            continue
+        }
+        if _, err := loadLocation(tz); err != nil {
+            return nil, err
+        }
+        zones = append(zones, tz)
+    }
+    if len(zones) == 0 {
+        return nil, fmt.Errorf("at least one timezone is required")
+    }
+    return zones, nil
+}
+
+// lookupGroup loads a saved participant group by name
+func lookupGroup(name string) (participantGroup, error) {
+    g, err := store.GetGroup(name)
+    if errors.Is(err, errNotFound) {
+        return g, fmt.Errorf("unknown participant group %q", name)
+    }
+    return g, err
+}
+
+// handleSaveParticipantGroup creates or replaces a participant group
+func handleSaveParticipantGroup(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    name := strings.TrimSpace(req.GetString("name", ""))
+    if !aliasNamePattern.MatchString(name) {
+        return mcp.NewToolResultError("name must be up to 64 letters, digits, '.', '_' or '-'"), nil
+    }
+    tzArg, err := req.RequireString("timezones")
+    if err != nil {
+        return mcp.NewToolResultError("timezones parameter is required"), nil
+    }
+    zones, err := splitZones(tzArg)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    if len(zones) > maxGroupMembers {
+        return mcp.NewToolResultError(fmt.Sprintf("a group holds at most %d timezones", maxGroupMembers)), nil
+    }
+    wh := req.GetString("working_hours", "")
+    if wh != "" {
+        if _, err := parseWorkingHours(wh); err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+    }
+
+    g := participantGroup{Name: name, Timezones: zones, WorkingHours: wh, UpdatedAt: time.Now().UTC().Format(time.RFC3339)}
+    if err := store.PutGroup(g); err != nil {
+        return nil, fmt.Errorf("save participant group: %w", err)
+    }
+
+    logAt(logInfo, "save_participant_group: %s (%d zones)", name, len(zones))
+    return toolResultJSON(map[string]interface{}{
+        "group":      g,
+        "persistent": store.Persistent(),
+    })
+}
+
+// handleListParticipantGroups lists saved participant groups
+func handleListParticipantGroups(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    groups, err := store.ListGroups()
+    if err != nil {
+        return nil, fmt.Errorf("list participant groups: %w", err)
+    }
+    if groups == nil {
+        groups = []participantGroup{}
+    }
+    return toolResultJSON(map[string]interface{}{
+        "groups": groups,
+        "count":  len(groups),
+    })
+}
+
+// handleDeleteParticipantGroup removes a participant group
+func handleDeleteParticipantGroup(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    name, err := req.RequireString("name")
+    if err != nil {
+        return mcp.NewToolResultError("name parameter is required"), nil
+    }
+    err = store.DeleteGroup(name)
+    if errors.Is(err, errNotFound) {
+        return mcp.NewToolResultError(fmt.Sprintf("unknown participant group %q", name)), nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("delete participant group: %w", err)
+    }
+
+    logAt(logInfo, "delete_participant_group: %s", name)
+    return toolResultJSON(map[string]interface{}{
+        "deleted": name,
+    })
+}
+
+// registerGroupTools adds the participant group tools to the server
+func registerGroupTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("save_participant_group",
+        mcp.WithDescription("Save a named group of meeting participant timezones for use with meeting_overlap_windows"),
+        mcp.WithTitleAnnotation("Save Participant Group"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(true), // Replaces a group of the same name
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("name",
+            mcp.Required(),
+            mcp.Description("Group name, e.g. 'platform-team'"),
+        ),
+        mcp.WithString("timezones",
+            mcp.Required(),
+            mcp.Description("Comma-separated IANA timezones (or aliases) of the participants"),
+        ),
+        mcp.WithString("working_hours",
+            mcp.Description("Default working hours for the group as HH:MM-HH:MM"),
+        ),
+    ), handleSaveParticipantGroup)
+
+    s.AddTool(mcp.NewTool("list_participant_groups",
+        mcp.WithDescription("List saved meeting participant groups"),
+        mcp.WithTitleAnnotation("List Participant Groups"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+    ), handleListParticipantGroups)
+
+    s.AddTool(mcp.NewTool("delete_participant_group",
+        mcp.WithDescription("Delete a saved meeting participant group"),
+        mcp.WithTitleAnnotation("Delete Participant Group"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(true),
+        mcp.WithIdempotentHintAnnotation(false),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("name",
+            mcp.Required(),
+            mcp.Description("Group name"),
+        ),
+    ), handleDeleteParticipantGroup)
+}
@@ -0,0 +1,95 @@
+// -*- coding: utf-8 -*-
+// tools_groups_test.go - tests for participant group tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+)
+
+func TestParticipantGroupTools(t *testing.T) {
+    useTestStore(t, newMemoryStore())
+    ctx := context.Background()
+
+    res, err := handleSaveParticipantGroup(ctx, testRequest("save_participant_group", map[string]any{
+        "name":          "apac",
+        "timezones":     "Asia/Tokyo, Australia/Sydney",
+        "working_hours": "08:00-18:00",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("save failed: %v %s", err, extractText(t, res))
+    }
+
+    for _, args := range []map[string]any{
+        {"name": "bad/name", "timezones": "UTC"},
+        {"name": "x", "timezones": "Mars/Base"},
+        {"name": "x", "timezones": "UTC", "working_hours": "late"},
+    } {
+        res, _ := handleSaveParticipantGroup(ctx, testRequest("save_participant_group", args))
+        if !res.IsError {
+            t.Errorf("save %v: expected tool error", args)
+        }
+    }
+
+    res, _ = handleListParticipantGroups(ctx, testRequest("list_participant_groups", nil))
+    var list struct {
+        Count  int                `json:"count"`
+        Groups []participantGroup `json:"groups"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &list); err != nil || list.Count != 1 || len(list.Groups[0].Timezones) != 2 {
+        t.Fatalf("list = %+v (%v)", list, err)
+    }
+
+    res, _ = handleDeleteParticipantGroup(ctx, testRequest("delete_participant_group", map[string]any{"name": "APAC"}))
+    if res.IsError {
+        t.Fatalf("delete failed: %s", extractText(t, res))
+    }
+    res, _ = handleDeleteParticipantGroup(ctx, testRequest("delete_participant_group", map[string]any{"name": "apac"}))
+    if !res.IsError {
+        t.Error("deleting a missing group should fail")
+    }
+}
+
+func TestMeetingOverlapWithGroup(t *testing.T) {
+    useTestStore(t, newMemoryStore())
+    if err := store.PutGroup(participantGroup{Name: "eu-us", Timezones: []string{"Europe/London", "America/New_York"}, WorkingHours: "08:00-18:00"}); err != nil {
+        t.Fatal(err)
+    }
+
+    res, err := handleMeetingOverlapWindows(context.Background(), testRequest("meeting_overlap_windows", map[string]any{
+        "group":      "eu-us",
+        "start_date": "2025-03-03",
+        "days":       float64(1),
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("overlap with group failed: %v %s", err, extractText(t, res))
+    }
+    var out struct {
+        Timezones []string `json:"timezones"`
+        Windows   []struct {
+            Start string `json:"start"`
+            End   string `json:"end"`
+        } `json:"windows"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    // London 08:00-18:00 GMT and New York 08:00-18:00 EST overlap 13:00-18:00 UTC
+    if len(out.Windows) != 1 || out.Windows[0].Start != "2025-03-03T13:00:00Z" || out.Windows[0].End != "2025-03-03T18:00:00Z" {
+        t.Errorf("windows = %+v", out.Windows)
+    }
+
+    res, _ = handleMeetingOverlapWindows(context.Background(), testRequest("meeting_overlap_windows", map[string]any{"group": "nobody"}))
+    if !res.IsError {
+        t.Error("unknown group should be a tool error")
+    }
+    res, _ = handleMeetingOverlapWindows(context.Background(), testRequest("meeting_overlap_windows", nil))
+    if !res.IsError {
+        t.Error("missing timezones and group should be a tool error")
+    }
+}
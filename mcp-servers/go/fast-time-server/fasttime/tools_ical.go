@@ -0,0 +1,770 @@
+// -*- coding: utf-8 -*-
+// tools_ical.go - iCalendar event tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements create_ical_event, which turns meeting parameters
+// into an RFC 5545 calendar object that agents can attach to an invitation.
+// The event is returned as an embedded text/calendar resource (base64 blob)
+// next to a JSON summary.
+//
+// Times are written in the event's zone (DTSTART;TZID=...) with a VTIMEZONE
+// built from Go's zone data: one observance per offset change, from the
+// start of the event's year until the recurrence ends (at most
+// icalMaxTimezoneYears later), so calendar clients agree on the wall time
+// of every occurrence without knowing the zone themselves. UTC events use
+// the "Z" form and need no VTIMEZONE. RRULE values are checked for the
+// common parts (FREQ, INTERVAL, COUNT, UNTIL, BYxxx, WKST) and written as
+// given.
+//
+// parse_ical goes the other way: it reads a calendar object (ical_parse.go)
+// and lists each event with its occurrences in a window, expanded by
+// rrule.go and shown in the caller's timezone.
+
+package fasttime
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/hex"
+    "fmt"
+    "net/mail"
+    "sort"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// iCalendar limits
+const (
+    icalMaxTimezoneYears = 10 // years of offset changes written for recurring events
+    icalMaxAttendees     = 100
+    icalLineLimit        = 75 // octets per line before folding
+    icalDefaultDuration  = time.Hour
+
+    icalDefaultWindow      = 90 * 24 * time.Hour // parse_ical "to" after "from"
+    icalDefaultOccurrences = 100
+    icalMaxOccurrences     = 1000
+)
+
+// icalLocalLayout and icalUTCLayout are the DATE-TIME forms of RFC 5545
+const (
+    icalLocalLayout = "20060102T150405"
+    icalUTCLayout   = "20060102T150405Z"
+)
+
+// icalEscape escapes a TEXT value
+func icalEscape(s string) string {
+    return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icalWriter builds a calendar object with CRLF line endings and folding
+type icalWriter struct {
+    b strings.Builder
+}
+
+// line writes one content line, folding it at icalLineLimit octets without
+// splitting a UTF-8 sequence
+func (w *icalWriter) line(name, value string) {
+    s := name + ":" + value
+    limit := icalLineLimit
+    for len(s) > limit {
+        cut := limit
+        for cut > 0 && !utf8.RuneStart(s[cut]) {
+            cut--
+        }
+        w.b.WriteString(s[:cut] + "\r\n ")
+        s = s[cut:]
+        limit = icalLineLimit - 1 // the leading space counts
+    }
+    w.b.WriteString(s + "\r\n")
+}
+
+// icalOffset renders a UTC offset in seconds as ±HHMM
+func icalOffset(secs int) string {
+    return strings.Replace(formatOffset(secs), ":", "", 1)
+}
+
+// writeVTimezone describes loc from the start of from's year until the end
+// of until's year
+func (w *icalWriter) writeVTimezone(loc *time.Location, from, until time.Time) {
+    w.line("BEGIN", "VTIMEZONE")
+    w.line("TZID", loc.String())
+
+    observance := func(at time.Time, fromOff int) {
+        name, off := at.Zone()
+        kind := "STANDARD"
+        if at.IsDST() {
+            kind = "DAYLIGHT"
+        }
+        w.line("BEGIN", kind)
+        // The onset is written in the local time in force before it
+        w.line("DTSTART", at.UTC().Add(time.Duration(fromOff)*time.Second).Format(icalLocalLayout))
+        w.line("TZOFFSETFROM", icalOffset(fromOff))
+        w.line("TZOFFSETTO", icalOffset(off))
+        w.line("TZNAME", icalEscape(name))
+        w.line("END", kind)
+    }
+
+    t := time.Date(from.In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+    end := time.Date(until.In(loc).Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+    _, off := t.Zone()
+    observance(t, off)
+    for {
+        next, ok := nextTransition(t)
+        if !ok || !next.Before(end) {
+            break
+        }
+        next = next.In(loc)
+        observance(next, off)
+        _, off = next.Zone()
+        t = next
+    }
+    w.line("END", "VTIMEZONE")
+}
+
+// icalTime renders t as a DTSTART/DTEND property in loc
+func icalTime(t time.Time, loc *time.Location) (params, value string) {
+    if loc.String() == "UTC" {
+        return "", t.UTC().Format(icalUTCLayout)
+    }
+    return ";TZID=" + loc.String(), t.In(loc).Format(icalLocalLayout)
+}
+
+// icalEvent is the input of create_ical_event
+type icalEvent struct {
+    uid         string
+    summary     string
+    description string
+    location    string
+    organizer   *mail.Address
+    attendees   []*mail.Address
+    start, end  time.Time
+    loc         *time.Location
+    rule        *icalRule
+    alarm       time.Duration // 0 for none
+    stamp       time.Time
+}
+
+// render returns the VCALENDAR text of e
+func (e *icalEvent) render() string {
+    w := &icalWriter{}
+    w.line("BEGIN", "VCALENDAR")
+    w.line("VERSION", "2.0")
+    w.line("PRODID", "-//"+appName+"//"+appVersion+"//EN")
+    w.line("CALSCALE", "GREGORIAN")
+    if len(e.attendees) > 0 {
+        w.line("METHOD", "REQUEST")
+    } else {
+        w.line("METHOD", "PUBLISH")
+    }
+
+    if e.loc.String() != "UTC" {
+        until := e.end
+        if e.rule != nil {
+            until = e.start.AddDate(icalMaxTimezoneYears, 0, 0)
+            if !e.rule.until.IsZero() && e.rule.until.Before(until) {
+                until = e.rule.until
+            }
+        }
+        w.writeVTimezone(e.loc, e.start, until)
+    }
+
+    w.line("BEGIN", "VEVENT")
+    w.line("UID", e.uid)
+    w.line("DTSTAMP", e.stamp.UTC().Format(icalUTCLayout))
+    p, v := icalTime(e.start, e.loc)
+    w.line("DTSTART"+p, v)
+    p, v = icalTime(e.end, e.loc)
+    w.line("DTEND"+p, v)
+    if e.rule != nil {
+        w.line("RRULE", e.rule.text)
+    }
+    w.line("SUMMARY", icalEscape(e.summary))
+    if e.description != "" {
+        w.line("DESCRIPTION", icalEscape(e.description))
+    }
+    if e.location != "" {
+        w.line("LOCATION", icalEscape(e.location))
+    }
+    if e.organizer != nil {
+        w.line("ORGANIZER"+icalCommonName(e.organizer), "mailto:"+e.organizer.Address)
+    }
+    for _, a := range e.attendees {
+        w.line("ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE"+icalCommonName(a), "mailto:"+a.Address)
+    }
+    w.line("SEQUENCE", "0")
+    w.line("STATUS", "CONFIRMED")
+    if e.alarm > 0 {
+        w.line("BEGIN", "VALARM")
+        w.line("ACTION", "DISPLAY")
+        w.line("DESCRIPTION", icalEscape(e.summary))
+        w.line("TRIGGER", "-"+formatISODuration(e.alarm))
+        w.line("END", "VALARM")
+    }
+    w.line("END", "VEVENT")
+    w.line("END", "VCALENDAR")
+    return w.b.String()
+}
+
+// icalCommonName returns the CN parameter for a, if it has a name
+func icalCommonName(a *mail.Address) string {
+    if a.Name == "" {
+        return ""
+    }
+    return `;CN="` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(a.Name) + `"`
+}
+
+// newEventUID returns a random event UID
+func newEventUID() string {
+    b := make([]byte, 12)
+    _, _ = rand.Read(b)
+    return hex.EncodeToString(b) + "@" + appName
+}
+
+// icalEventFromRequest reads and checks the create_ical_event arguments
+func icalEventFromRequest(ctx context.Context, req mcp.CallToolRequest) (*icalEvent, string, error) {
+    e := &icalEvent{
+        summary:     strings.TrimSpace(req.GetString("summary", "")),
+        description: req.GetString("description", ""),
+        location:    req.GetString("location", ""),
+        uid:         strings.TrimSpace(req.GetString("uid", "")),
+        stamp:       currentTime(),
+    }
+    if e.summary == "" {
+        return nil, "", fmt.Errorf("summary parameter is required")
+    }
+    switch {
+    case e.uid == "":
+        e.uid = newEventUID()
+    case len(e.uid) > 255 || strings.ContainsFunc(e.uid, unicode.IsControl):
+        return nil, "", fmt.Errorf("uid must be at most 255 characters without control characters")
+    }
+    loc, err := loadLocation(req.GetString("timezone", defaultTimezoneFor(ctx)))
+    if err != nil {
+        return nil, "", err
+    }
+    e.loc = loc
+
+    startStr, err := req.RequireString("start")
+    if err != nil {
+        return nil, "", fmt.Errorf("start parameter is required")
+    }
+    start, note, err := parseScheduleTime(startStr, loc)
+    if err != nil {
+        return nil, "", fmt.Errorf("invalid start: %v", err)
+    }
+    e.start = start
+
+    endStr, durStr := req.GetString("end", ""), req.GetString("duration", "")
+    switch {
+    case endStr != "" && durStr != "":
+        return nil, "", fmt.Errorf("give end or duration, not both")
+    case endStr != "":
+        if e.end, _, err = parseScheduleTime(endStr, loc); err != nil {
+            return nil, "", fmt.Errorf("invalid end: %v", err)
+        }
+    case durStr != "":
+        pd, err := parseDurationFlexible(durStr)
+        if err != nil {
+            return nil, "", err
+        }
+        e.end = e.start.Add(pd.d)
+    default:
+        e.end = e.start.Add(icalDefaultDuration)
+    }
+    if !e.end.After(e.start) {
+        return nil, "", fmt.Errorf("the event must end after it starts")
+    }
+
+    if raw := req.GetString("rrule", ""); raw != "" {
+        rule, err := parseRRule(raw)
+        if err != nil {
+            return nil, "", err
+        }
+        if !rule.until.IsZero() && !rule.untilUTC {
+            return nil, "", fmt.Errorf("rrule UNTIL must be a UTC time such as 20251231T235959Z")
+        }
+        if !rule.until.IsZero() && rule.until.Before(e.start) {
+            return nil, "", fmt.Errorf("rrule UNTIL is before the event starts")
+        }
+        e.rule = &rule
+    }
+
+    if raw := strings.TrimSpace(req.GetString("organizer", "")); raw != "" {
+        if e.organizer, err = mail.ParseAddress(raw); err != nil {
+            return nil, "", fmt.Errorf("invalid organizer %q: %v", raw, err)
+        }
+    }
+    if raw := strings.TrimSpace(req.GetString("attendees", "")); raw != "" {
+        if e.attendees, err = mail.ParseAddressList(raw); err != nil {
+            return nil, "", fmt.Errorf("invalid attendees: %v", err)
+        }
+        if len(e.attendees) > icalMaxAttendees {
+            return nil, "", fmt.Errorf("at most %d attendees", icalMaxAttendees)
+        }
+    }
+
+    if raw := req.GetString("alarm", ""); raw != "" {
+        pd, err := parseDurationFlexible(raw)
+        if err != nil {
+            return nil, "", fmt.Errorf("invalid alarm: %v", err)
+        }
+        // iCalendar durations have whole seconds
+        if e.alarm = pd.d.Truncate(time.Second); e.alarm <= 0 {
+            return nil, "", fmt.Errorf("alarm must be at least one second before the start")
+        }
+    }
+    return e, note, nil
+}
+
+// handleCreateICalEvent builds an .ics calendar object for a meeting
+func handleCreateICalEvent(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    e, note, err := icalEventFromRequest(ctx, req)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    ics := e.render()
+    uri := "ical://events/" + e.uid + ".ics"
+
+    summary := map[string]interface{}{
+        "uid":      e.uid,
+        "uri":      uri,
+        "filename": "invite.ics",
+        "start":    e.start.In(e.loc).Format(time.RFC3339),
+        "end":      e.end.In(e.loc).Format(time.RFC3339),
+        "timezone": e.loc.String(),
+        "bytes":    len(ics),
+        "ics":      ics,
+    }
+    if e.rule != nil {
+        summary["rrule"] = e.rule.text
+    }
+    if note != "" {
+        summary["note"] = note
+    }
+    text, err := toolResultJSON(summary)
+    if err != nil {
+        return nil, err
+    }
+
+    logAt(logInfo, "create_ical_event: %s at %s", e.uid, e.start.In(e.loc).Format(time.RFC3339))
+    text.Content = append(text.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+        URI:      uri,
+        MIMEType: "text/calendar",
+        Blob:     base64.StdEncoding.EncodeToString([]byte(ics)),
+    }))
+    return text, nil
+}
+
+// icalOccurrence is one instance of a parsed event
+type icalOccurrence struct {
+    wall       time.Time // start as written
+    start, end time.Time
+    overridden bool
+}
+
+// parsedEvent is a VEVENT read by parse_ical
+type parsedEvent struct {
+    c      *icalComponent
+    uid    string
+    start  icalDateTime
+    at     func(time.Time) time.Time // wall time to instant in the event's zone
+    length time.Duration             // wall-clock length for all-day events
+    rule   *icalRule
+}
+
+// occurrence returns the instance starting at wall time w
+func (e *parsedEvent) occurrence(w time.Time) icalOccurrence {
+    o := icalOccurrence{wall: w, start: e.at(w)}
+    if e.start.date {
+        o.end = e.at(w.Add(e.length))
+    } else {
+        o.end = o.start.Add(e.length)
+    }
+    return o
+}
+
+// format returns the start and end of o for the output
+func (e *parsedEvent) format(o icalOccurrence, target *time.Location) (string, string) {
+    if e.start.date {
+        return o.wall.Format("2006-01-02"), o.wall.Add(e.length).Format("2006-01-02")
+    }
+    return o.start.In(target).Format(time.RFC3339), o.end.In(target).Format(time.RFC3339)
+}
+
+// readICalEvent reads the timing of a VEVENT
+func readICalEvent(c *icalComponent, zones *icalZones) (*parsedEvent, error) {
+    e := &parsedEvent{c: c, uid: c.text("UID")}
+    p := c.prop("DTSTART")
+    if p == nil {
+        return nil, fmt.Errorf("event %q has no DTSTART", e.uid)
+    }
+    var err error
+    if e.start, err = parseICalDateTime(p, p.value); err != nil {
+        return nil, fmt.Errorf("event %q: %v", e.uid, err)
+    }
+    if e.at, err = zones.resolver(e.start); err != nil {
+        return nil, fmt.Errorf("event %q: %v", e.uid, err)
+    }
+
+    switch end, dur := c.prop("DTEND"), c.prop("DURATION"); {
+    case end != nil:
+        d, err := parseICalDateTime(end, end.value)
+        if err != nil {
+            return nil, fmt.Errorf("event %q: %v", e.uid, err)
+        }
+        if e.start.date {
+            e.length = d.wall.Sub(e.start.wall)
+        } else {
+            at, err := zones.instant(d)
+            if err != nil {
+                return nil, fmt.Errorf("event %q: %v", e.uid, err)
+            }
+            e.length = at.Sub(e.at(e.start.wall))
+        }
+    case dur != nil:
+        pd, err := parseDurationFlexible(dur.value)
+        if err != nil {
+            return nil, fmt.Errorf("event %q: invalid DURATION: %v", e.uid, err)
+        }
+        e.length = pd.d
+    case e.start.date:
+        e.length = 24 * time.Hour
+    }
+    if e.length < 0 {
+        return nil, fmt.Errorf("event %q ends before it starts", e.uid)
+    }
+
+    if p := c.prop("RRULE"); p != nil {
+        rule, err := parseRRule(p.value)
+        if err != nil {
+            return nil, fmt.Errorf("event %q: %v", e.uid, err)
+        }
+        e.rule = &rule
+    }
+    return e, nil
+}
+
+// icalDateSet reads the EXDATE or RDATE properties of c as instants
+func icalDateSet(c *icalComponent, name string, zones *icalZones, e *parsedEvent) (map[int64]time.Time, error) {
+    set := map[int64]time.Time{}
+    for i := range c.props {
+        if c.props[i].name != name {
+            continue
+        }
+        dates, err := parseICalDateTimes(&c.props[i])
+        if err != nil {
+            return nil, fmt.Errorf("event %q: %v", e.uid, err)
+        }
+        for _, d := range dates {
+            if d.date && !e.start.date {
+                // A date in a timed event means the occurrence on that day
+                d.wall = d.wall.Add(e.start.wall.Sub(civil(e.start.wall)))
+            }
+            at, err := zones.instant(d)
+            if err != nil {
+                return nil, fmt.Errorf("event %q: %v", e.uid, err)
+            }
+            set[at.Unix()] = d.wall
+        }
+    }
+    return set, nil
+}
+
+// icalWindow lists the occurrences of an event that overlap [from, to],
+// at most limit of them; overrides are the RECURRENCE-ID events by the
+// instant they replace
+func icalWindow(e *parsedEvent, overrides map[int64]*parsedEvent, zones *icalZones, from, to time.Time, limit int) (list []icalOccurrence, truncated bool, note string, err error) {
+    exdates, err := icalDateSet(e.c, "EXDATE", zones, e)
+    if err != nil {
+        return nil, false, "", err
+    }
+    rdates, err := icalDateSet(e.c, "RDATE", zones, e)
+    if err != nil {
+        return nil, false, "", err
+    }
+
+    add := func(w time.Time) {
+        o := e.occurrence(w)
+        if _, ok := exdates[o.start.Unix()]; ok {
+            return
+        }
+        if ov, ok := overrides[o.start.Unix()]; ok {
+            if strings.EqualFold(ov.c.text("STATUS"), "CANCELLED") {
+                return
+            }
+            o = ov.occurrence(ov.start.wall)
+            o.overridden = true
+        }
+        if !o.start.After(to) && (o.end.After(from) || !o.start.Before(from)) {
+            list = append(list, o)
+        }
+    }
+
+    switch {
+    case e.rule == nil:
+        add(e.start.wall)
+    case e.rule.unsupported != "":
+        add(e.start.wall)
+        note = fmt.Sprintf("rrule %s is not expanded; only the first occurrence is listed", e.rule.unsupported)
+    default:
+        e.rule.expand(e.start.wall, e.at, to, func(w, _ time.Time) bool {
+            add(w)
+            return len(list) <= limit
+        })
+    }
+    for _, w := range rdates {
+        add(w)
+    }
+
+    sort.SliceStable(list, func(i, j int) bool { return list[i].start.Before(list[j].start) })
+    if len(list) > limit {
+        list, truncated = list[:limit], true
+    }
+    return list, truncated, note, nil
+}
+
+// icalAddress formats an ORGANIZER or ATTENDEE value
+func icalAddress(p icalProp) string {
+    addr := p.value
+    if len(addr) >= len("mailto:") && strings.EqualFold(addr[:len("mailto:")], "mailto:") {
+        addr = addr[len("mailto:"):]
+    }
+    if cn := p.params["CN"]; cn != "" {
+        return cn + " <" + addr + ">"
+    }
+    return addr
+}
+
+// handleParseICal reads an .ics calendar object and lists its events and
+// their occurrences in the target timezone
+func handleParseICal(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    text, err := req.RequireString("ics")
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    target, err := loadLocation(req.GetString("timezone", defaultTimezoneFor(ctx)))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    from := currentTime()
+    if raw := req.GetString("from", ""); raw != "" {
+        if from, _, err = parseScheduleTime(raw, target); err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+    }
+    to := from.Add(icalDefaultWindow)
+    if raw := req.GetString("to", ""); raw != "" {
+        if to, _, err = parseScheduleTime(raw, target); err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+    }
+    if to.Before(from) {
+        return mcp.NewToolResultError("to must not be before from"), nil
+    }
+    limit := req.GetInt("max_occurrences", icalDefaultOccurrences)
+    if limit < 1 || limit > icalMaxOccurrences {
+        return mcp.NewToolResultError(fmt.Sprintf("max_occurrences must be between 1 and %d", icalMaxOccurrences)), nil
+    }
+
+    cal, err := parseICalendar(text)
+    if err != nil {
+        return mcp.NewToolResultError("invalid iCalendar: " + err.Error()), nil
+    }
+    zones, err := newICalZones(cal, target)
+    if err != nil {
+        return mcp.NewToolResultError("invalid iCalendar: " + err.Error()), nil
+    }
+
+    // Masters first, then the RECURRENCE-ID instances that replace their
+    // occurrences; an instance without a master is listed on its own
+    var masters []*parsedEvent
+    overrides := map[string]map[int64]*parsedEvent{}
+    byUID := map[string]bool{}
+    var instances []*parsedEvent
+    for _, c := range cal.children {
+        if c.name != "VEVENT" {
+            continue
+        }
+        if len(masters)+len(instances) >= icalMaxEvents {
+            return mcp.NewToolResultError(fmt.Sprintf("at most %d events", icalMaxEvents)), nil
+        }
+        e, err := readICalEvent(c, zones)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        rid := c.prop("RECURRENCE-ID")
+        if rid == nil {
+            masters = append(masters, e)
+            byUID[e.uid] = true
+            continue
+        }
+        d, err := parseICalDateTime(rid, rid.value)
+        if err == nil {
+            var at time.Time
+            if at, err = zones.instant(d); err == nil {
+                if overrides[e.uid] == nil {
+                    overrides[e.uid] = map[int64]*parsedEvent{}
+                }
+                overrides[e.uid][at.Unix()] = e
+                instances = append(instances, e)
+                continue
+            }
+        }
+        return mcp.NewToolResultError(fmt.Sprintf("event %q: %v", e.uid, err)), nil
+    }
+    for _, e := range instances {
+        if !byUID[e.uid] {
+            masters = append(masters, e)
+        }
+    }
+
+    events := make([]map[string]interface{}, 0, len(masters))
+    for _, e := range masters {
+        list, truncated, note, err := icalWindow(e, overrides[e.uid], zones, from, to, limit)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        occurrences := make([]map[string]interface{}, 0, len(list))
+        for _, o := range list {
+            start, end := e.format(o, target)
+            occurrences = append(occurrences, map[string]interface{}{"start": start, "end": end, "overridden": o.overridden})
+        }
+
+        start, end := e.format(e.occurrence(e.start.wall), target)
+        zone := e.start.tzid
+        switch {
+        case e.start.utc:
+            zone = "UTC"
+        case zone == "" || e.start.date:
+            zone = "floating"
+        }
+        event := map[string]interface{}{
+            "uid":              e.uid,
+            "summary":          e.c.text("SUMMARY"),
+            "start":            start,
+            "end":              end,
+            "all_day":          e.start.date,
+            "timezone":         zone,
+            "recurring":        e.rule != nil,
+            "occurrences":      occurrences,
+            "occurrence_count": len(occurrences),
+            "truncated":        truncated,
+        }
+        for _, name := range []string{"DESCRIPTION", "LOCATION", "STATUS"} {
+            if v := e.c.text(name); v != "" {
+                event[strings.ToLower(name)] = v
+            }
+        }
+        if p := e.c.prop("ORGANIZER"); p != nil {
+            event["organizer"] = icalAddress(*p)
+        }
+        var attendees []string
+        for _, p := range e.c.props {
+            if p.name == "ATTENDEE" {
+                attendees = append(attendees, icalAddress(p))
+            }
+        }
+        if len(attendees) > 0 {
+            event["attendees"] = attendees
+        }
+        if e.rule != nil {
+            event["rrule"] = e.rule.text
+        }
+        if note != "" {
+            event["note"] = note
+        }
+        events = append(events, event)
+    }
+
+    logAt(logDebug, "parse_ical: %d events between %s and %s", len(events), from.Format(time.RFC3339), to.Format(time.RFC3339))
+    return toolResultJSON(map[string]interface{}{
+        "timezone": target.String(),
+        "from":     from.In(target).Format(time.RFC3339),
+        "to":       to.In(target).Format(time.RFC3339),
+        "events":   events,
+        "count":    len(events),
+    })
+}
+
+// registerICalTools adds create_ical_event and parse_ical to the server
+func registerICalTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("create_ical_event",
+        mcp.WithDescription("Create an iCalendar (.ics) invite with timezone-aware start and end, optional RRULE recurrence, attendees and alarm, returned as a text/calendar resource"),
+        mcp.WithTitleAnnotation("Create iCalendar Event"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // A new UID and DTSTAMP each time
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("summary",
+            mcp.Required(),
+            mcp.Description("Event title"),
+        ),
+        mcp.WithString("start",
+            mcp.Required(),
+            mcp.Description("Start in RFC3339 or 'YYYY-MM-DD HH:MM' local to timezone"),
+        ),
+        mcp.WithString("end",
+            mcp.Description("End, in the same forms as start. Give end or duration (default 1h)"),
+        ),
+        mcp.WithString("duration",
+            mcp.Description("Length of the event, e.g. '30m', 'PT1H30M' or '1 hour'"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone of the event. Defaults to the session or server default"),
+        ),
+        mcp.WithString("rrule",
+            mcp.Description("RFC 5545 recurrence rule, e.g. 'FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10' (UNTIL in UTC: 20251231T235959Z)"),
+        ),
+        mcp.WithString("description",
+            mcp.Description("Event description"),
+        ),
+        mcp.WithString("location",
+            mcp.Description("Event location or meeting link"),
+        ),
+        mcp.WithString("organizer",
+            mcp.Description("Organizer email, optionally with a name: 'Ada <ada@example.com>'"),
+        ),
+        mcp.WithString("attendees",
+            mcp.Description("Comma-separated attendee emails; makes the object a METHOD:REQUEST invitation"),
+        ),
+        mcp.WithString("alarm",
+            mcp.Description("Add a display alarm this long before the start, e.g. '15m'"),
+        ),
+        mcp.WithString("uid",
+            mcp.Description("UID to reuse, e.g. to update an earlier invite. Generated when omitted"),
+        ),
+    ), handleCreateICalEvent)
+
+    s.AddTool(mcp.NewTool("parse_ical",
+        mcp.WithDescription("Parse iCalendar (.ics) text into events, expanding RRULE, RDATE, EXDATE and RECURRENCE-ID overrides into occurrences shown in a target timezone"),
+        mcp.WithTitleAnnotation("Parse iCalendar"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("ics",
+            mcp.Required(),
+            mcp.Description("Calendar object text, BEGIN:VCALENDAR ... END:VCALENDAR (at most 1 MiB)"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for the output and for floating times. Defaults to the session or server default"),
+        ),
+        mcp.WithString("from",
+            mcp.Description("Start of the occurrence window, RFC3339 or local to timezone (default: now)"),
+        ),
+        mcp.WithString("to",
+            mcp.Description("End of the occurrence window (default: 90 days after from)"),
+        ),
+        mcp.WithNumber("max_occurrences",
+            mcp.Description("Occurrences listed per event (default 100, max 1000)"),
+        ),
+    ), handleParseICal)
+}
@@ -0,0 +1,311 @@
+// -*- coding: utf-8 -*-
+// tools_ical_test.go - tests for create_ical_event
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "strings"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// createICal calls create_ical_event and returns the summary and .ics text
+func createICal(t *testing.T, args map[string]any) (map[string]interface{}, string) {
+    t.Helper()
+    res, err := handleCreateICalEvent(context.Background(), testRequest("create_ical_event", args))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if res.IsError {
+        t.Fatalf("create_ical_event(%v): %s", args, extractText(t, res))
+    }
+    var out map[string]interface{}
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    if len(res.Content) != 2 {
+        t.Fatalf("content = %d items, want text and resource", len(res.Content))
+    }
+    blob := res.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+    ics, err := base64.StdEncoding.DecodeString(blob.Blob)
+    if err != nil || blob.MIMEType != "text/calendar" || string(ics) != out["ics"] || blob.URI != out["uri"] {
+        t.Fatalf("resource = %+v (%v)", blob, err)
+    }
+    return out, string(ics)
+}
+
+// unfold joins folded iCalendar lines
+func unfold(ics string) []string {
+    return strings.Split(strings.ReplaceAll(strings.TrimSuffix(ics, "\r\n"), "\r\n ", ""), "\r\n")
+}
+
+func TestCreateICalEvent(t *testing.T) {
+    out, ics := createICal(t, map[string]any{
+        "summary":     "Planning, Q3; review",
+        "start":       "2025-03-10 09:00",
+        "duration":    "90m",
+        "timezone":    "America/New_York",
+        "rrule":       "freq=weekly;byday=MO;until=20250630T000000Z",
+        "description": "Agenda:\n1. Roadmap",
+        "attendees":   "Ada <ada@example.com>, bob@example.com",
+        "organizer":   "Grace <grace@example.com>",
+        "alarm":       "15m",
+        "uid":         "weekly-planning@example.com",
+    })
+    if out["start"] != "2025-03-10T09:00:00-04:00" || out["end"] != "2025-03-10T10:30:00-04:00" || out["rrule"] != "FREQ=WEEKLY;BYDAY=MO;UNTIL=20250630T000000Z" {
+        t.Errorf("summary = %v", out)
+    }
+
+    lines := unfold(ics)
+    for _, want := range []string{
+        "BEGIN:VCALENDAR", "VERSION:2.0", "METHOD:REQUEST",
+        "TZID:America/New_York",
+        // 2025 DST changes, onset in the local time before each one
+        "DTSTART:20250309T020000", "TZOFFSETFROM:-0500", "TZOFFSETTO:-0400", "TZNAME:EDT",
+        "DTSTART:20251102T020000",
+        "UID:weekly-planning@example.com",
+        "DTSTART;TZID=America/New_York:20250310T090000",
+        "DTEND;TZID=America/New_York:20250310T103000",
+        "RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20250630T000000Z",
+        `SUMMARY:Planning\, Q3\; review`,
+        `DESCRIPTION:Agenda:\n1. Roadmap`,
+        `ORGANIZER;CN="Grace":mailto:grace@example.com`,
+        `ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE;CN="Ada":mailto:ada@example.com`,
+        "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:bob@example.com",
+        "TRIGGER:-PT15M",
+        "END:VCALENDAR",
+    } {
+        found := false
+        for _, l := range lines {
+            found = found || l == want
+        }
+        if !found {
+            t.Errorf("missing line %q in\n%s", want, ics)
+        }
+    }
+    if strings.Count(ics, "BEGIN:DAYLIGHT") != 1 || strings.Count(ics, "BEGIN:STANDARD") != 2 {
+        t.Errorf("VTIMEZONE observances:\n%s", ics)
+    }
+    for _, l := range strings.Split(ics, "\r\n") {
+        if len(l) > icalLineLimit {
+            t.Errorf("line longer than %d octets: %q", icalLineLimit, l)
+        }
+    }
+}
+
+func TestCreateICalEventUTC(t *testing.T) {
+    out, ics := createICal(t, map[string]any{"summary": "Sync", "start": "2025-06-01T12:00:00Z", "timezone": "UTC"})
+    if strings.Contains(ics, "VTIMEZONE") || !strings.Contains(ics, "DTSTART:20250601T120000Z\r\n") ||
+        !strings.Contains(ics, "DTEND:20250601T130000Z\r\n") || !strings.Contains(ics, "METHOD:PUBLISH") {
+        t.Errorf("UTC event:\n%s", ics)
+    }
+    if !strings.HasSuffix(out["uid"].(string), "@"+appName) {
+        t.Errorf("generated uid = %v", out["uid"])
+    }
+}
+
+func TestCreateICalEventDSTGap(t *testing.T) {
+    out, ics := createICal(t, map[string]any{"summary": "x", "start": "2025-03-09T02:30", "timezone": "America/New_York"})
+    if out["start"] != "2025-03-09T03:30:00-04:00" || out["note"] == nil || !strings.Contains(ics, "DTSTART;TZID=America/New_York:20250309T033000") {
+        t.Errorf("event in a DST gap = %v", out)
+    }
+}
+
+func TestCreateICalEventErrors(t *testing.T) {
+    for _, args := range []map[string]any{
+        {"start": "2025-03-10 09:00"},
+        {"summary": "x"},
+        {"summary": "x", "start": "someday"},
+        {"summary": "x", "start": "2025-03-10 09:00", "end": "2025-03-10 08:00"},
+        {"summary": "x", "start": "2025-03-10 09:00", "end": "2025-03-10 10:00", "duration": "1h"},
+        {"summary": "x", "start": "2025-03-10 09:00", "rrule": "BYDAY=MO"},
+        {"summary": "x", "start": "2025-03-10 09:00", "rrule": "FREQ=FORTNIGHTLY"},
+        {"summary": "x", "start": "2025-03-10 09:00", "rrule": "FREQ=DAILY;COUNT=3;UNTIL=20250630T000000Z"},
+        {"summary": "x", "start": "2025-03-10 09:00", "rrule": "FREQ=DAILY;UNTIL=2025-06-30"},
+        {"summary": "x", "start": "2025-03-10 09:00", "rrule": "FREQ=DAILY;X-FOO=1"},
+        {"summary": "x", "start": "2025-03-10 09:00", "attendees": "not an email"},
+        {"summary": "x", "start": "2025-03-10 09:00", "uid": "a\r\nX-INJECTED:1"},
+        {"summary": "x", "start": "2025-03-10 09:00", "timezone": "Mars/Olympus"},
+    } {
+        res, _ := handleCreateICalEvent(context.Background(), testRequest("create_ical_event", args))
+        if !res.IsError {
+            t.Errorf("create_ical_event(%v) should fail", args)
+        }
+    }
+}
+
+func TestICalLineFolding(t *testing.T) {
+    w := &icalWriter{}
+    w.line("DESCRIPTION", strings.Repeat("é", 100)) // 2 octets each
+    for i, l := range strings.Split(strings.TrimSuffix(w.b.String(), "\r\n"), "\r\n") {
+        if len(l) > icalLineLimit || (i > 0 && !strings.HasPrefix(l, " ")) {
+            t.Errorf("folded line %d = %q", i, l)
+        }
+        if !strings.HasPrefix(strings.TrimPrefix(l, " "), "é") && i > 0 {
+            t.Errorf("line %d splits a character: %q", i, l)
+        }
+    }
+}
+
+// parseICal calls parse_ical and returns its events
+func parseICal(t *testing.T, args map[string]any) []map[string]interface{} {
+    t.Helper()
+    res, err := handleParseICal(context.Background(), testRequest("parse_ical", args))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if res.IsError {
+        t.Fatalf("parse_ical: %s", extractText(t, res))
+    }
+    var out struct {
+        Events []map[string]interface{} `json:"events"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    return out.Events
+}
+
+// occurrenceStarts returns the start of each listed occurrence
+func occurrenceStarts(event map[string]interface{}) []string {
+    var out []string
+    for _, o := range event["occurrences"].([]interface{}) {
+        out = append(out, o.(map[string]interface{})["start"].(string))
+    }
+    return out
+}
+
+func TestParseICalRoundTrip(t *testing.T) {
+    _, ics := createICal(t, map[string]any{
+        "summary":   "Planning, Q3; review",
+        "start":     "2025-03-10 09:00",
+        "duration":  "90m",
+        "timezone":  "America/New_York",
+        "rrule":     "FREQ=WEEKLY;BYDAY=MO;UNTIL=20250630T000000Z",
+        "attendees": "Ada <ada@example.com>",
+        "uid":       "weekly-planning@example.com",
+    })
+    events := parseICal(t, map[string]any{"ics": ics, "timezone": "Europe/London", "from": "2025-03-01T00:00:00Z", "to": "2025-12-31T00:00:00Z"})
+    if len(events) != 1 {
+        t.Fatalf("events = %v", events)
+    }
+    e := events[0]
+    starts := occurrenceStarts(e)
+    // Mondays 10 March to 23 June; London is 4 hours ahead until 31 March
+    if e["summary"] != "Planning, Q3; review" || e["uid"] != "weekly-planning@example.com" || e["timezone"] != "America/New_York" ||
+        e["occurrence_count"].(float64) != 16 || starts[0] != "2025-03-10T13:00:00Z" || starts[3] != "2025-03-31T14:00:00+01:00" ||
+        starts[15] != "2025-06-23T14:00:00+01:00" || e["end"] != "2025-03-10T14:30:00Z" {
+        t.Errorf("event = %v", e)
+    }
+    if a := e["attendees"].([]interface{}); len(a) != 1 || a[0] != "Ada <ada@example.com>" {
+        t.Errorf("attendees = %v", a)
+    }
+}
+
+// outlookICS is an Outlook-style export: a TZID that is no zone name, with
+// its own VTIMEZONE
+const outlookICS = "BEGIN:VCALENDAR\r\nPRODID:-//Microsoft Corporation//Outlook 16.0 MIMEDIR//EN\r\nVERSION:2.0\r\n" +
+    "BEGIN:VTIMEZONE\r\nTZID:(UTC+01:00) Amsterdam\\, Berlin\\, Rome\r\n" +
+    "BEGIN:STANDARD\r\nDTSTART:16011028T030000\r\nRRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nEND:STANDARD\r\n" +
+    "BEGIN:DAYLIGHT\r\nDTSTART:16010325T020000\r\nRRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nEND:DAYLIGHT\r\n" +
+    "END:VTIMEZONE\r\n" +
+    "BEGIN:VEVENT\r\nUID:outlook-1\r\nSUMMARY:Month end close\r\n" +
+    "DTSTART;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250131T160000\r\n" +
+    "DTEND;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250131T170000\r\n" +
+    "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1\r\n" +
+    "EXDATE;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250430T160000\r\n" +
+    "ORGANIZER;CN=\"Grace Hopper\":mailto:grace@example.com\r\n" +
+    "END:VEVENT\r\n" +
+    "BEGIN:VEVENT\r\nUID:outlook-1\r\nSUMMARY:Month end close (moved)\r\n" +
+    "RECURRENCE-ID;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250530T160000\r\n" +
+    "DTSTART;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250529T100000\r\n" +
+    "DURATION:PT30M\r\n" +
+    "END:VEVENT\r\n" +
+    "BEGIN:VEVENT\r\nUID:outlook-1\r\nSTATUS:CANCELLED\r\n" +
+    "RECURRENCE-ID;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250630T160000\r\n" +
+    "DTSTART;TZID=\"(UTC+01:00) Amsterdam, Berlin, Rome\":20250630T160000\r\n" +
+    "END:VEVENT\r\n" +
+    "END:VCALENDAR\r\n"
+
+func TestParseICalOutlookTimezone(t *testing.T) {
+    events := parseICal(t, map[string]any{"ics": outlookICS, "timezone": "UTC", "from": "2025-01-01T00:00:00Z", "to": "2025-08-01T00:00:00Z"})
+    if len(events) != 1 {
+        t.Fatalf("events = %v", events)
+    }
+    e := events[0]
+    got := strings.Join(occurrenceStarts(e), " ")
+    // Last workday of each month at 16:00 Berlin time: CET until 30 March,
+    // then CEST; April excluded, May moved, June cancelled
+    want := "2025-01-31T15:00:00Z 2025-02-28T15:00:00Z 2025-03-31T14:00:00Z 2025-05-29T08:00:00Z 2025-07-31T14:00:00Z"
+    if got != want || e["organizer"] != "Grace Hopper <grace@example.com>" || e["timezone"] != "(UTC+01:00) Amsterdam, Berlin, Rome" {
+        t.Errorf("occurrences = %s, want %s (%v)", got, want, e)
+    }
+    moved := e["occurrences"].([]interface{})[3].(map[string]interface{})
+    if moved["overridden"] != true || moved["end"] != "2025-05-29T08:30:00Z" {
+        t.Errorf("override = %v", moved)
+    }
+}
+
+func TestParseICalAllDayAndFloating(t *testing.T) {
+    ics := "BEGIN:VCALENDAR\nVERSION:2.0\n" +
+        "BEGIN:VEVENT\nUID:holiday\nSUMMARY:Founders\\, day\nDTSTART;VALUE=DATE:20250704\nRRULE:FREQ=YEARLY\nEND:VEVENT\n" +
+        "BEGIN:VEVENT\nUID:floating\nSUMMARY:Lunch\nDTSTART:20250704T120000\nDTEND:20250704T130000\n" +
+        "LOCATION:Cafe\n  on the corner\nEND:VEVENT\n" +
+        "END:VCALENDAR\n"
+    events := parseICal(t, map[string]any{"ics": ics, "timezone": "Asia/Tokyo", "from": "2025-01-01", "to": "2027-01-01"})
+    if len(events) != 2 {
+        t.Fatalf("events = %v", events)
+    }
+    holiday, lunch := events[0], events[1]
+    if holiday["all_day"] != true || holiday["start"] != "2025-07-04" || holiday["end"] != "2025-07-05" || holiday["summary"] != "Founders, day" ||
+        strings.Join(occurrenceStarts(holiday), " ") != "2025-07-04 2026-07-04" {
+        t.Errorf("all-day event = %v", holiday)
+    }
+    if lunch["timezone"] != "floating" || lunch["start"] != "2025-07-04T12:00:00+09:00" || lunch["location"] != "Cafe on the corner" || lunch["recurring"] != false {
+        t.Errorf("floating event = %v", lunch)
+    }
+}
+
+func TestParseICalLimits(t *testing.T) {
+    ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:daily\r\nDTSTART:20250101T090000Z\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n" +
+        "BEGIN:VEVENT\r\nUID:weekno\r\nDTSTART:20250101T090000Z\r\nRRULE:FREQ=YEARLY;BYWEEKNO=20\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+    events := parseICal(t, map[string]any{"ics": ics, "from": "2025-01-01T00:00:00Z", "to": "2025-12-31T00:00:00Z", "max_occurrences": 5})
+    if events[0]["occurrence_count"].(float64) != 5 || events[0]["truncated"] != true {
+        t.Errorf("truncated event = %v", events[0])
+    }
+    if events[1]["occurrence_count"].(float64) != 1 || events[1]["note"] == nil {
+        t.Errorf("unsupported rule = %v", events[1])
+    }
+}
+
+func TestParseICalErrors(t *testing.T) {
+    event := func(lines string) string {
+        return "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\n" + lines + "END:VEVENT\r\nEND:VCALENDAR\r\n"
+    }
+    for _, args := range []map[string]any{
+        {},
+        {"ics": "hello"},
+        {"ics": "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n"},
+        {"ics": event("SUMMARY:no start\r\n")},
+        {"ics": event("DTSTART:2025-01-01\r\n")},
+        {"ics": event("DTSTART;TZID=Nowhere:20250101T090000\r\n")},
+        {"ics": event("DTSTART:20250101T090000Z\r\nRRULE:FREQ=SOMETIMES\r\n")},
+        {"ics": event("DTSTART:20250101T090000Z\r\nDTEND:20241231T090000Z\r\n")},
+        {"ics": event("DTSTART:20250101T090000Z\r\n"), "max_occurrences": 5000},
+        {"ics": event("DTSTART:20250101T090000Z\r\n"), "from": "2025-02-01", "to": "2025-01-01"},
+        {"ics": event("DTSTART:20250101T090000Z\r\n"), "timezone": "Mars/Olympus"},
+        {"ics": strings.Repeat("X", icalMaxInput+1)},
+    } {
+        res, _ := handleParseICal(context.Background(), testRequest("parse_ical", args))
+        if !res.IsError {
+            t.Errorf("parse_ical(%.80v) should fail", args)
+        }
+    }
+}
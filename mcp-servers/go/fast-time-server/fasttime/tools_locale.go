@@ -0,0 +1,494 @@
+// -*- coding: utf-8 -*-
+// tools_locale.go - locale-aware date/time formatting for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements format_localized, which renders a time for people
+// rather than programs: month and weekday names, the order of the date parts,
+// 12/24-hour clocks and the digits of the locale's numbering system. The data
+// is a compact subset of CLDR for common locales, always in the Gregorian
+// calendar. A BCP-47 tag selects the locale ("de-DE", "ja", "ar-SA"); a
+// "-u-nu-" extension or the numbering argument overrides the digits
+// ("hi-IN-u-nu-deva").
+//
+// Patterns use the CLDR letters y, M, d, E, H, h, m, s, a and z; text in
+// single quotes is literal.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// localeData holds the names and patterns of one locale
+type localeData struct {
+    months      [12]string
+    monthsShort [12]string
+    days        [7]string // Sunday first, as time.Weekday
+    am, pm      string
+    dateFormats map[string]string // full, long, medium, short
+    timeFormats map[string]string // long, medium, short
+    dateTime    string            // {1} = date, {0} = time
+    numbering   string
+}
+
+// numberingDigits maps CLDR numbering systems to their digits 0-9
+var numberingDigits = map[string][10]rune{
+    "latn":     {'0', '1', '2', '3', '4', '5', '6', '7', '8', '9'},
+    "arab":     {'٠', '١', '٢', '٣', '٤', '٥', '٦', '٧', '٨', '٩'},
+    "arabext":  {'۰', '۱', '۲', '۳', '۴', '۵', '۶', '۷', '۸', '۹'},
+    "deva":     {'०', '१', '२', '३', '४', '५', '६', '७', '८', '९'},
+    "beng":     {'০', '১', '২', '৩', '৪', '৫', '৬', '৭', '৮', '৯'},
+    "thai":     {'๐', '๑', '๒', '๓', '๔', '๕', '๖', '๗', '๘', '๙'},
+    "hanidec":  {'〇', '一', '二', '三', '四', '五', '六', '七', '八', '九'},
+    "fullwide": {'０', '１', '２', '３', '４', '５', '６', '７', '８', '９'},
+}
+
+// 24-hour time patterns shared by most European locales
+var timeFormats24 = map[string]string{"long": "HH:mm:ss z", "medium": "HH:mm:ss", "short": "HH:mm"}
+
+// locales is keyed by language-REGION
+var locales = map[string]*localeData{
+    "en-US": {
+        months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+        monthsShort: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+        days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+        am:          "AM", pm: "PM",
+        dateFormats: map[string]string{"full": "EEEE, MMMM d, y", "long": "MMMM d, y", "medium": "MMM d, y", "short": "M/d/yy"},
+        timeFormats: map[string]string{"long": "h:mm:ss a z", "medium": "h:mm:ss a", "short": "h:mm a"},
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "en-GB": {
+        months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+        monthsShort: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sept", "Oct", "Nov", "Dec"},
+        days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+        am:          "am", pm: "pm",
+        dateFormats: map[string]string{"full": "EEEE d MMMM y", "long": "d MMMM y", "medium": "d MMM y", "short": "dd/MM/y"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "de-DE": {
+        months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+        monthsShort: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+        days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+        am:          "AM", pm: "PM",
+        dateFormats: map[string]string{"full": "EEEE, d. MMMM y", "long": "d. MMMM y", "medium": "dd.MM.y", "short": "dd.MM.yy"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "fr-FR": {
+        months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+        monthsShort: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+        days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+        am:          "AM", pm: "PM",
+        dateFormats: map[string]string{"full": "EEEE d MMMM y", "long": "d MMMM y", "medium": "d MMM y", "short": "dd/MM/y"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1} {0}",
+        numbering:   "latn",
+    },
+    "es-ES": {
+        months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+        monthsShort: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+        days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+        am:          "a. m.", pm: "p. m.",
+        dateFormats: map[string]string{"full": "EEEE, d 'de' MMMM 'de' y", "long": "d 'de' MMMM 'de' y", "medium": "d MMM y", "short": "d/M/yy"},
+        timeFormats: map[string]string{"long": "H:mm:ss z", "medium": "H:mm:ss", "short": "H:mm"},
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "it-IT": {
+        months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+        monthsShort: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+        days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+        am:          "AM", pm: "PM",
+        dateFormats: map[string]string{"full": "EEEE d MMMM y", "long": "d MMMM y", "medium": "d MMM y", "short": "dd/MM/yy"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "pt-BR": {
+        months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+        monthsShort: [12]string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+        days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+        am:          "AM", pm: "PM",
+        dateFormats: map[string]string{"full": "EEEE, d 'de' MMMM 'de' y", "long": "d 'de' MMMM 'de' y", "medium": "d 'de' MMM 'de' y", "short": "dd/MM/y"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1} {0}",
+        numbering:   "latn",
+    },
+    "nl-NL": {
+        months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+        monthsShort: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+        days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+        am:          "a.m.", pm: "p.m.",
+        dateFormats: map[string]string{"full": "EEEE d MMMM y", "long": "d MMMM y", "medium": "d MMM y", "short": "dd-MM-y"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1} {0}",
+        numbering:   "latn",
+    },
+    "ru-RU": {
+        // Genitive month names, as used inside a date
+        months:      [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+        monthsShort: [12]string{"янв.", "февр.", "мар.", "апр.", "мая", "июн.", "июл.", "авг.", "сент.", "окт.", "нояб.", "дек."},
+        days:        [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+        am:          "AM", pm: "PM",
+        dateFormats: map[string]string{"full": "EEEE, d MMMM y 'г'.", "long": "d MMMM y 'г'.", "medium": "d MMM y 'г'.", "short": "dd.MM.y"},
+        timeFormats: timeFormats24,
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "ja-JP": {
+        months:      [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+        monthsShort: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+        days:        [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+        am:          "午前", pm: "午後",
+        dateFormats: map[string]string{"full": "y年M月d日EEEE", "long": "y年M月d日", "medium": "y/MM/dd", "short": "y/MM/dd"},
+        timeFormats: map[string]string{"long": "H:mm:ss z", "medium": "H:mm:ss", "short": "H:mm"},
+        dateTime:    "{1} {0}",
+        numbering:   "latn",
+    },
+    "zh-CN": {
+        months:      [12]string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"},
+        monthsShort: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+        days:        [7]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"},
+        am:          "上午", pm: "下午",
+        dateFormats: map[string]string{"full": "y年M月d日EEEE", "long": "y年M月d日", "medium": "y年M月d日", "short": "y/M/d"},
+        timeFormats: map[string]string{"long": "z HH:mm:ss", "medium": "HH:mm:ss", "short": "HH:mm"},
+        dateTime:    "{1} {0}",
+        numbering:   "latn",
+    },
+    "ko-KR": {
+        months:      [12]string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+        monthsShort: [12]string{"1월", "2월", "3월", "4월", "5월", "6월", "7월", "8월", "9월", "10월", "11월", "12월"},
+        days:        [7]string{"일요일", "월요일", "화요일", "수요일", "목요일", "금요일", "토요일"},
+        am:          "오전", pm: "오후",
+        dateFormats: map[string]string{"full": "y년 MMMM d일 EEEE", "long": "y년 MMMM d일", "medium": "y. M. d.", "short": "yy. M. d."},
+        timeFormats: map[string]string{"long": "a h시 m분 s초 z", "medium": "a h:mm:ss", "short": "a h:mm"},
+        dateTime:    "{1} {0}",
+        numbering:   "latn",
+    },
+    "ar-SA": {
+        months:      [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+        monthsShort: [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو", "يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+        days:        [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+        am:          "ص", pm: "م",
+        dateFormats: map[string]string{"full": "EEEE، d MMMM y", "long": "d MMMM y", "medium": "dd‏/MM‏/y", "short": "d‏/M‏/y"},
+        timeFormats: map[string]string{"long": "h:mm:ss a z", "medium": "h:mm:ss a", "short": "h:mm a"},
+        dateTime:    "{1}، {0}",
+        numbering:   "arab",
+    },
+    "hi-IN": {
+        months:      [12]string{"जनवरी", "फ़रवरी", "मार्च", "अप्रैल", "मई", "जून", "जुलाई", "अगस्त", "सितंबर", "अक्तूबर", "नवंबर", "दिसंबर"},
+        monthsShort: [12]string{"जन॰", "फ़र॰", "मार्च", "अप्रैल", "मई", "जून", "जुल॰", "अग॰", "सित॰", "अक्तू॰", "नव॰", "दिस॰"},
+        days:        [7]string{"रविवार", "सोमवार", "मंगलवार", "बुधवार", "गुरुवार", "शुक्रवार", "शनिवार"},
+        am:          "am", pm: "pm",
+        dateFormats: map[string]string{"full": "EEEE, d MMMM y", "long": "d MMMM y", "medium": "d MMM y", "short": "d/M/yy"},
+        timeFormats: map[string]string{"long": "h:mm:ss a z", "medium": "h:mm:ss a", "short": "h:mm a"},
+        dateTime:    "{1}, {0}",
+        numbering:   "latn",
+    },
+    "fa-IR": {
+        months:      [12]string{"ژانویه", "فوریه", "مارس", "آوریل", "مه", "ژوئن", "ژوئیه", "اوت", "سپتامبر", "اکتبر", "نوامبر", "دسامبر"},
+        monthsShort: [12]string{"ژانویه", "فوریه", "مارس", "آوریل", "مه", "ژوئن", "ژوئیه", "اوت", "سپتامبر", "اکتبر", "نوامبر", "دسامبر"},
+        days:        [7]string{"یکشنبه", "دوشنبه", "سه‌شنبه", "چهارشنبه", "پنجشنبه", "جمعه", "شنبه"},
+        am:          "ق.ظ.", pm: "ب.ظ.",
+        dateFormats: map[string]string{"full": "EEEE d MMMM y", "long": "d MMMM y", "medium": "d MMM y", "short": "y/M/d"},
+        timeFormats: map[string]string{"long": "H:mm:ss z", "medium": "H:mm:ss", "short": "H:mm"},
+        dateTime:    "{1}، ساعت {0}",
+        numbering:   "arabext",
+    },
+}
+
+// defaultRegions picks the locale for a bare language tag
+var defaultRegions = map[string]string{
+    "en": "en-US", "de": "de-DE", "fr": "fr-FR", "es": "es-ES", "it": "it-IT",
+    "pt": "pt-BR", "nl": "nl-NL", "ru": "ru-RU", "ja": "ja-JP", "zh": "zh-CN",
+    "ko": "ko-KR", "ar": "ar-SA", "hi": "hi-IN", "fa": "fa-IR",
+}
+
+// supportedLocales returns the locale keys, sorted
+func supportedLocales() []string {
+    keys := make([]string, 0, len(locales))
+    for k := range locales {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// resolveLocale maps a BCP-47 tag to a locale key and numbering system.
+// Unknown regions fall back to the language's default locale.
+func resolveLocale(tag string) (string, string, error) {
+    parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+    lang := strings.ToLower(parts[0])
+    region, numbering := "", ""
+    for i := 1; i < len(parts); i++ {
+        p := parts[i]
+        switch {
+        case strings.EqualFold(p, "u"):
+            // Unicode extension: look for the nu keyword
+            for j := i + 1; j+1 < len(parts); j++ {
+                if strings.EqualFold(parts[j], "nu") {
+                    numbering = strings.ToLower(parts[j+1])
+                }
+            }
+            i = len(parts)
+        case len(p) == 2 && region == "":
+            region = strings.ToUpper(p)
+        }
+    }
+
+    key := lang + "-" + region
+    if _, ok := locales[key]; !ok {
+        if key, ok = defaultRegions[lang]; !ok {
+            return "", "", fmt.Errorf("unsupported locale %q (supported: %s)", tag, strings.Join(supportedLocales(), ", "))
+        }
+    }
+    if numbering == "" {
+        numbering = locales[key].numbering
+    }
+    if _, ok := numberingDigits[numbering]; !ok {
+        return "", "", fmt.Errorf("unsupported numbering system %q", numbering)
+    }
+    return key, numbering, nil
+}
+
+// localizeDigits replaces ASCII digits with those of a numbering system
+func localizeDigits(s, numbering string) string {
+    if numbering == "latn" {
+        return s
+    }
+    digits := numberingDigits[numbering]
+    return strings.Map(func(r rune) rune {
+        if r >= '0' && r <= '9' {
+            return digits[r-'0']
+        }
+        return r
+    }, s)
+}
+
+// formatPattern renders t with a CLDR-style pattern
+func formatPattern(t time.Time, pattern string, ld *localeData, numbering string) string {
+    var b strings.Builder
+    num := func(n, width int) {
+        s := strconv.Itoa(n)
+        for len(s) < width {
+            s = "0" + s
+        }
+        b.WriteString(localizeDigits(s, numbering))
+    }
+
+    runes := []rune(pattern)
+    for i := 0; i < len(runes); {
+        c := runes[i]
+        if c == '\'' {
+            // Quoted literal; '' is a single quote
+            if i+1 < len(runes) && runes[i+1] == '\'' {
+                b.WriteRune('\'')
+                i += 2
+                continue
+            }
+            j := i + 1
+            for j < len(runes) {
+                if runes[j] == '\'' {
+                    if j+1 < len(runes) && runes[j+1] == '\'' {
+                        b.WriteRune('\'')
+                        j += 2
+                        continue
+                    }
+                    break
+                }
+                b.WriteRune(runes[j])
+                j++
+            }
+            i = j + 1
+            continue
+        }
+        if !strings.ContainsRune("yMdEHhmsaz", c) {
+            b.WriteRune(c)
+            i++
+            continue
+        }
+        n := 1
+        for i+n < len(runes) && runes[i+n] == c {
+            n++
+        }
+        i += n
+
+        switch c {
+        case 'y':
+            if n == 2 {
+                num(t.Year()%100, 2)
+            } else {
+                num(t.Year(), n)
+            }
+        case 'M':
+            switch {
+            case n >= 4:
+                b.WriteString(ld.months[t.Month()-1])
+            case n == 3:
+                b.WriteString(ld.monthsShort[t.Month()-1])
+            default:
+                num(int(t.Month()), n)
+            }
+        case 'd':
+            num(t.Day(), n)
+        case 'E':
+            b.WriteString(ld.days[t.Weekday()])
+        case 'H':
+            num(t.Hour(), n)
+        case 'h':
+            h := t.Hour() % 12
+            if h == 0 {
+                h = 12
+            }
+            num(h, n)
+        case 'm':
+            num(t.Minute(), n)
+        case 's':
+            num(t.Second(), n)
+        case 'a':
+            if t.Hour() < 12 {
+                b.WriteString(ld.am)
+            } else {
+                b.WriteString(ld.pm)
+            }
+        case 'z':
+            abbr, _ := t.Zone()
+            b.WriteString(abbr)
+        }
+    }
+    return b.String()
+}
+
+// formatLocalized renders t in a locale with the given date and time styles
+// ("full", "long", "medium", "short" or "none")
+func formatLocalized(t time.Time, key, numbering, dateStyle, timeStyle string) (map[string]interface{}, error) {
+    ld := locales[key]
+    if timeStyle == "full" {
+        timeStyle = "long"
+    }
+
+    result := map[string]interface{}{
+        "locale":    key,
+        "numbering": numbering,
+        "calendar":  "gregorian",
+        "month":     ld.months[t.Month()-1],
+        "weekday":   ld.days[t.Weekday()],
+    }
+    var datePart, timePart string
+    if dateStyle != "none" {
+        p, ok := ld.dateFormats[dateStyle]
+        if !ok {
+            return nil, fmt.Errorf("invalid date_style %q: use full, long, medium, short or none", dateStyle)
+        }
+        datePart = formatPattern(t, p, ld, numbering)
+        result["date"] = datePart
+    }
+    if timeStyle != "none" {
+        p, ok := ld.timeFormats[timeStyle]
+        if !ok {
+            return nil, fmt.Errorf("invalid time_style %q: use full, long, medium, short or none", timeStyle)
+        }
+        timePart = formatPattern(t, p, ld, numbering)
+        result["time_of_day"] = timePart
+    }
+
+    switch {
+    case datePart != "" && timePart != "":
+        result["formatted"] = strings.NewReplacer("{1}", datePart, "{0}", timePart).Replace(ld.dateTime)
+    case datePart != "":
+        result["formatted"] = datePart
+    case timePart != "":
+        result["formatted"] = timePart
+    default:
+        return nil, fmt.Errorf("date_style and time_style cannot both be none")
+    }
+    return result, nil
+}
+
+// handleFormatLocalized formats a time for a locale
+func handleFormatLocalized(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    tag, err := req.RequireString("locale")
+    if err != nil {
+        return mcp.NewToolResultError("locale parameter is required"), nil
+    }
+    key, numbering, err := resolveLocale(tag)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    if nu := strings.ToLower(req.GetString("numbering", "")); nu != "" {
+        if _, ok := numberingDigits[nu]; !ok {
+            return mcp.NewToolResultError(fmt.Sprintf("unsupported numbering system %q", nu)), nil
+        }
+        numbering = nu
+    }
+
+    tz := req.GetString("timezone", defaultTimezoneFor(ctx))
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    t, err := timeArgIn(req, loc)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    result, err := formatLocalized(t, key, numbering,
+        strings.ToLower(req.GetString("date_style", "long")),
+        strings.ToLower(req.GetString("time_style", "short")))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    result["requested_locale"] = tag
+    result["time"] = t.Format(time.RFC3339)
+    result["timezone"] = tz
+
+    logAt(logInfo, "format_localized: %s %s = %s", key, t.Format(time.RFC3339), result["formatted"])
+    return toolResultJSON(result)
+}
+
+// registerLocaleTools adds format_localized to the server
+func registerLocaleTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("format_localized",
+        mcp.WithDescription("Format a time for a locale (BCP-47, e.g. de-DE, ja-JP, ar-SA) with localized month/day names, date order, 12/24-hour clock and numbering system"),
+        mcp.WithTitleAnnotation("Format Localized"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("locale",
+            mcp.Required(),
+            mcp.Description("BCP-47 locale tag, e.g. 'fr-FR', 'ja', 'hi-IN-u-nu-deva'"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time in RFC3339 or common formats. Defaults to now"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone to show the time in. Defaults to the server's default timezone"),
+        ),
+        mcp.WithString("date_style",
+            mcp.Description("Date style: full, long (default), medium, short or none"),
+            mcp.Enum("full", "long", "medium", "short", "none"),
+        ),
+        mcp.WithString("time_style",
+            mcp.Description("Time style: full, long, medium, short (default) or none"),
+            mcp.Enum("full", "long", "medium", "short", "none"),
+        ),
+        mcp.WithString("numbering",
+            mcp.Description("Override the numbering system: latn, arab, arabext, deva, beng, thai, hanidec or fullwide"),
+        ),
+    ), handleFormatLocalized)
+}
@@ -0,0 +1,122 @@
+// -*- coding: utf-8 -*-
+// tools_locale_test.go - Tests for locale-aware formatting
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestResolveLocale(t *testing.T) {
+    tests := []struct {
+        tag, key, numbering string
+    }{
+        {"de-DE", "de-DE", "latn"},
+        {"de_AT", "de-DE", "latn"},
+        {"ja", "ja-JP", "latn"},
+        {"EN-gb", "en-GB", "latn"},
+        {"ar-SA", "ar-SA", "arab"},
+        {"fa", "fa-IR", "arabext"},
+        {"hi-IN-u-nu-deva", "hi-IN", "deva"},
+        {"ar-SA-u-ca-gregory-nu-latn", "ar-SA", "latn"},
+    }
+    for _, tt := range tests {
+        key, nu, err := resolveLocale(tt.tag)
+        if err != nil || key != tt.key || nu != tt.numbering {
+            t.Errorf("resolveLocale(%q) = %s, %s, %v; want %s, %s", tt.tag, key, nu, err, tt.key, tt.numbering)
+        }
+    }
+
+    for _, tag := range []string{"xx-YY", "", "en-US-u-nu-klingon"} {
+        if _, _, err := resolveLocale(tag); err == nil {
+            t.Errorf("resolveLocale(%q): expected error", tag)
+        }
+    }
+}
+
+func TestFormatLocalized(t *testing.T) {
+    ts := time.Date(2025, 3, 7, 14, 5, 9, 0, time.UTC)
+    tests := []struct {
+        locale, dateStyle, timeStyle, want string
+    }{
+        {"en-US", "full", "short", "Friday, March 7, 2025, 2:05 PM"},
+        {"en-GB", "short", "none", "07/03/2025"},
+        {"de-DE", "full", "medium", "Freitag, 7. März 2025, 14:05:09"},
+        {"fr-FR", "long", "short", "7 mars 2025 14:05"},
+        {"es-ES", "long", "none", "7 de marzo de 2025"},
+        {"ja-JP", "full", "short", "2025年3月7日金曜日 14:05"},
+        {"ko-KR", "none", "short", "오후 2:05"},
+        {"ar-SA", "long", "none", "٧ مارس ٢٠٢٥"},
+        {"fa-IR", "short", "none", "۲۰۲۵/۳/۷"},
+        {"ru-RU", "long", "none", "7 марта 2025 г."},
+        {"en-US", "none", "long", "2:05:09 PM UTC"},
+    }
+    for _, tt := range tests {
+        key, nu, _ := resolveLocale(tt.locale)
+        got, err := formatLocalized(ts, key, nu, tt.dateStyle, tt.timeStyle)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.locale, err)
+        }
+        if got["formatted"] != tt.want {
+            t.Errorf("%s %s/%s: got %q, want %q", tt.locale, tt.dateStyle, tt.timeStyle, got["formatted"], tt.want)
+        }
+    }
+
+    if _, err := formatLocalized(ts, "en-US", "latn", "none", "none"); err == nil {
+        t.Error("expected error when both styles are none")
+    }
+    if _, err := formatLocalized(ts, "en-US", "latn", "tiny", "short"); err == nil {
+        t.Error("expected error for an invalid date_style")
+    }
+}
+
+func TestFormatPatternLiterals(t *testing.T) {
+    ts := time.Date(2025, 3, 7, 0, 30, 0, 0, time.UTC)
+    ld := locales["en-US"]
+    if got := formatPattern(ts, "h 'o''clock' a", ld, "latn"); got != "12 o'clock AM" {
+        t.Errorf("got %q", got)
+    }
+    if got := formatPattern(ts, "''yy", ld, "deva"); got != "'२५" {
+        t.Errorf("got %q", got)
+    }
+}
+
+func TestHandleFormatLocalized(t *testing.T) {
+    res, err := handleFormatLocalized(context.Background(), testRequest("format_localized", map[string]any{
+        "locale":     "hi-IN",
+        "time":       "2025-07-04T09:15:00Z",
+        "timezone":   "Asia/Kolkata",
+        "date_style": "medium",
+        "numbering":  "deva",
+    }))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body map[string]string
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body["formatted"] != "४ जुल॰ २०२५, २:४५ pm" || body["numbering"] != "deva" || body["locale"] != "hi-IN" {
+        t.Errorf("unexpected result: %v", body)
+    }
+    if body["weekday"] != "शुक्रवार" || body["timezone"] != "Asia/Kolkata" {
+        t.Errorf("unexpected result: %v", body)
+    }
+
+    for _, args := range []map[string]any{
+        {"locale": "tlh-QO"},
+        {"locale": "en-US", "timezone": "Mars/Olympus"},
+        {"locale": "en-US", "numbering": "roman"},
+    } {
+        res, _ := handleFormatLocalized(context.Background(), testRequest("format_localized", args))
+        if !res.IsError {
+            t.Errorf("%v: expected error result", args)
+        }
+    }
+}
@@ -0,0 +1,317 @@
+// -*- coding: utf-8 -*-
+// tools_meeting.go - meeting scheduling tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements meeting_overlap_windows, the computational
+// counterpart of the schedule_meeting prompt. Each participant's working
+// hours are expanded into UTC intervals on their own local calendar (so DST
+// and weekends are respected), the intervals are intersected, and the
+// resulting windows are ranked by how close a meeting would sit to the middle
+// of everyone's working day. With calendars, the busy times of calendars
+// from -free-busy-calendars are taken out first.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    maxOverlapDays     = 31               // upper bound on the searched date range
+    overlapScoringStep = 15 * time.Minute // granularity of suggested start times
+)
+
+// interval is a half-open [start, end) span of time
+type interval struct {
+    start, end time.Time
+}
+
+// workingHours is a daily local working period in minutes since midnight
+type workingHours struct {
+    startMin, endMin int
+}
+
+// parseClockMinutes parses "HH:MM" into minutes since midnight
+func parseClockMinutes(s string) (int, error) {
+    h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+    if !ok {
+        return 0, fmt.Errorf("invalid clock time %q (use HH:MM)", s)
+    }
+    hours, err1 := strconv.Atoi(h)
+    mins, err2 := strconv.Atoi(m)
+    if err1 != nil || err2 != nil || hours < 0 || hours > 24 || mins < 0 || mins >= 60 || hours*60+mins > 24*60 {
+        return 0, fmt.Errorf("invalid clock time %q (use HH:MM)", s)
+    }
+    return hours*60 + mins, nil
+}
+
+// parseWorkingHours parses a range such as "09:00-17:00"
+func parseWorkingHours(s string) (workingHours, error) {
+    from, to, ok := strings.Cut(s, "-")
+    if !ok {
+        return workingHours{}, fmt.Errorf("invalid working hours %q (use HH:MM-HH:MM)", s)
+    }
+    start, err := parseClockMinutes(from)
+    if err != nil {
+        return workingHours{}, err
+    }
+    end, err := parseClockMinutes(to)
+    if err != nil {
+        return workingHours{}, err
+    }
+    if end <= start {
+        return workingHours{}, fmt.Errorf("working hours %q must end after they start", s)
+    }
+    return workingHours{start, end}, nil
+}
+
+// isWeekend reports whether a local weekday is a non-working day
+func isWeekend(d time.Weekday) bool {
+    return d == time.Saturday || d == time.Sunday
+}
+
+// workIntervals expands daily working hours in loc into intervals clipped to [from, to)
+func workIntervals(loc *time.Location, wh workingHours, from, to time.Time, skipWeekends bool) []interval {
+    var out []interval
+    y, mo, d := from.In(loc).Date()
+    for day := time.Date(y, mo, d-1, 0, 0, 0, 0, loc); day.Before(to); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc) {
+        if skipWeekends && isWeekend(day.Weekday()) {
+            continue
+        }
+        s := time.Date(day.Year(), day.Month(), day.Day(), 0, wh.startMin, 0, 0, loc)
+        e := time.Date(day.Year(), day.Month(), day.Day(), 0, wh.endMin, 0, 0, loc)
+        if s.Before(from) {
+            s = from
+        }
+        if e.After(to) {
+            e = to
+        }
+        if s.Before(e) {
+            out = append(out, interval{s, e})
+        }
+    }
+    return out
+}
+
+// intersectIntervals returns the overlap of two sorted interval lists
+func intersectIntervals(a, b []interval) []interval {
+    var out []interval
+    for i, j := 0, 0; i < len(a) && j < len(b); {
+        s, e := a[i].start, a[i].end
+        if b[j].start.After(s) {
+            s = b[j].start
+        }
+        if b[j].end.Before(e) {
+            e = b[j].end
+        }
+        if s.Before(e) {
+            out = append(out, interval{s, e})
+        }
+        if a[i].end.Before(b[j].end) {
+            i++
+        } else {
+            j++
+        }
+    }
+    return out
+}
+
+// centeredness scores a meeting from 0 (at the edge of someone's day) to 1
+// (in the middle of everyone's day), averaged across participants
+func centeredness(start time.Time, dur time.Duration, locs []*time.Location, wh workingHours) float64 {
+    center := float64(wh.startMin+wh.endMin) / 2
+    half := float64(wh.endMin-wh.startMin) / 2
+    var total float64
+    for _, loc := range locs {
+        mid := start.Add(dur / 2).In(loc)
+        m := float64(mid.Hour()*60+mid.Minute()) + float64(mid.Second())/60
+        total += 1 - math.Abs(m-center)/half
+    }
+    return total / float64(len(locs))
+}
+
+// handleMeetingOverlapWindows finds and ranks common working-hour windows
+func handleMeetingOverlapWindows(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    // Participants come from the timezones list or a saved group
+    tzArg := req.GetString("timezones", "")
+    defaultHours := "09:00-17:00"
+    if name := req.GetString("group", ""); name != "" {
+        g, err := lookupGroup(name)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        if tzArg == "" {
+            tzArg = strings.Join(g.Timezones, ",")
+        }
+        if g.WorkingHours != "" {
+            defaultHours = g.WorkingHours
+        }
+    }
+    if tzArg == "" {
+        return mcp.NewToolResultError("timezones or group parameter is required"), nil
+    }
+    zones, err := splitZones(tzArg)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    locs := make([]*time.Location, len(zones))
+    for i, tz := range zones {
+        locs[i], _ = loadLocation(tz)
+    }
+
+    wh, err := parseWorkingHours(req.GetString("working_hours", defaultHours))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    dur := time.Duration(req.GetInt("duration", 60)) * time.Minute
+    if dur <= 0 {
+        return mcp.NewToolResultError("duration must be a positive number of minutes"), nil
+    }
+
+    days := req.GetInt("days", 5)
+    if days < 1 || days > maxOverlapDays {
+        return mcp.NewToolResultError(fmt.Sprintf("days must be between 1 and %d", maxOverlapDays)), nil
+    }
+
+    from := currentTime().UTC().Truncate(24 * time.Hour)
+    if startDate := req.GetString("start_date", ""); startDate != "" {
+        if from, err = time.Parse("2006-01-02", startDate); err != nil {
+            return mcp.NewToolResultError("start_date must be YYYY-MM-DD"), nil
+        }
+    }
+    to := from.AddDate(0, 0, days)
+    skipWeekends := req.GetBool("skip_weekends", true)
+    limit := req.GetInt("limit", 10)
+
+    overlap := workIntervals(locs[0], wh, from, to, skipWeekends)
+    for _, loc := range locs[1:] {
+        overlap = intersectIntervals(overlap, workIntervals(loc, wh, from, to, skipWeekends))
+    }
+    var calendars []string
+    if list := req.GetString("calendars", ""); list != "" {
+        busy, names, err := calendarBusyIntervals(ctx, list, from, to)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        overlap, calendars = subtractIntervals(overlap, busy), names
+    }
+
+    type window struct {
+        interval
+        best  time.Time
+        score float64
+    }
+    var windows []window
+    for _, iv := range overlap {
+        // Scoring is the expensive part; stop if the caller has given up
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+        if iv.end.Sub(iv.start) < dur {
+            continue
+        }
+        w := window{interval: iv, score: math.Inf(-1)}
+        for s := iv.start; !s.Add(dur).After(iv.end); s = s.Add(overlapScoringStep) {
+            if sc := centeredness(s, dur, locs, wh); sc > w.score {
+                w.best, w.score = s, sc
+            }
+        }
+        windows = append(windows, w)
+    }
+    sort.SliceStable(windows, func(i, j int) bool { return windows[i].score > windows[j].score })
+    if limit > 0 && len(windows) > limit {
+        windows = windows[:limit]
+    }
+
+    out := make([]map[string]interface{}, 0, len(windows))
+    for _, w := range windows {
+        local := make([]map[string]interface{}, 0, len(locs))
+        for _, loc := range locs {
+            local = append(local, map[string]interface{}{
+                "timezone":        loc.String(),
+                "start":           w.start.In(loc).Format(time.RFC3339),
+                "end":             w.end.In(loc).Format(time.RFC3339),
+                "suggested_start": w.best.In(loc).Format(time.RFC3339),
+            })
+        }
+        out = append(out, map[string]interface{}{
+            "start":            w.start.UTC().Format(time.RFC3339),
+            "end":              w.end.UTC().Format(time.RFC3339),
+            "duration_minutes": int(w.end.Sub(w.start).Minutes()),
+            "suggested_start":  w.best.UTC().Format(time.RFC3339),
+            "score":            math.Round(w.score*100) / 100,
+            "local":            local,
+        })
+    }
+
+    result := map[string]interface{}{
+        "timezones":       zones,
+        "working_hours":   fmt.Sprintf("%02d:%02d-%02d:%02d", wh.startMin/60, wh.startMin%60, wh.endMin/60, wh.endMin%60),
+        "meeting_minutes": int(dur.Minutes()),
+        "range_start":     from.Format(time.RFC3339),
+        "range_end":       to.Format(time.RFC3339),
+        "windows":         out,
+    }
+    if calendars != nil {
+        result["calendars"] = calendars
+    }
+
+    addSummary(ctx, req, "meeting windows", result)
+
+    logAt(logInfo, "meeting_overlap_windows: %d zones, %d windows", len(zones), len(out))
+    return toolResultJSON(result)
+}
+
+// registerMeetingTools adds meeting_overlap_windows to the server
+func registerMeetingTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("meeting_overlap_windows",
+        mcp.WithDescription("Find time windows where all participants are within working hours, ranked by how centered they are in everyone's working day"),
+        mcp.WithTitleAnnotation("Meeting Overlap Windows"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when start_date is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("timezones",
+            mcp.Description("Comma-separated IANA timezones of the participants. Required unless group is given"),
+        ),
+        mcp.WithString("group",
+            mcp.Description("Name of a group saved with save_participant_group"),
+        ),
+        mcp.WithString("working_hours",
+            mcp.Description("Local working hours for every participant as HH:MM-HH:MM. Defaults to the group's hours or 09:00-17:00"),
+        ),
+        mcp.WithString("calendars",
+            mcp.Description("Comma-separated calendars from -free-busy-calendars whose busy times are left out"),
+        ),
+        mcp.WithNumber("duration",
+            mcp.Description("Meeting length in minutes. Defaults to 60"),
+        ),
+        mcp.WithString("start_date",
+            mcp.Description("First UTC date to search (YYYY-MM-DD). Defaults to today"),
+        ),
+        mcp.WithNumber("days",
+            mcp.Description("Number of days to search (1-31). Defaults to 5"),
+        ),
+        mcp.WithBoolean("skip_weekends",
+            mcp.Description("Exclude each participant's Saturday and Sunday. Defaults to true"),
+        ),
+        mcp.WithNumber("limit",
+            mcp.Description("Maximum number of windows to return. Defaults to 10"),
+        ),
+        mcp.WithBoolean("summarize",
+            mcp.Description("Also ask the client's LLM (MCP sampling) for a natural-language summary. Defaults to false"),
+        ),
+    ), handleMeetingOverlapWindows)
+}
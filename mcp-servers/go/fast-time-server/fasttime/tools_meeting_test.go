@@ -0,0 +1,88 @@
+// -*- coding: utf-8 -*-
+// tools_meeting_test.go - Tests for meeting scheduling tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestParseWorkingHours(t *testing.T) {
+    wh, err := parseWorkingHours("08:30-17:15")
+    if err != nil || wh.startMin != 510 || wh.endMin != 1035 {
+        t.Errorf("parseWorkingHours = %+v, %v", wh, err)
+    }
+    for _, bad := range []string{"9-5", "17:00-09:00", "09:00", "25:00-26:00"} {
+        if _, err := parseWorkingHours(bad); err == nil {
+            t.Errorf("parseWorkingHours(%q) should fail", bad)
+        }
+    }
+}
+
+func TestIntersectIntervals(t *testing.T) {
+    at := func(h int) time.Time { return time.Date(2025, 1, 1, h, 0, 0, 0, time.UTC) }
+    a := []interval{{at(1), at(5)}, {at(8), at(12)}}
+    b := []interval{{at(3), at(9)}, {at(11), at(20)}}
+    got := intersectIntervals(a, b)
+    want := []interval{{at(3), at(5)}, {at(8), at(9)}, {at(11), at(12)}}
+    if len(got) != len(want) {
+        t.Fatalf("want %d intervals, got %d", len(want), len(got))
+    }
+    for i := range want {
+        if !got[i].start.Equal(want[i].start) || !got[i].end.Equal(want[i].end) {
+            t.Errorf("interval %d: want %v, got %v", i, want[i], got[i])
+        }
+    }
+}
+
+func TestHandleMeetingOverlapWindows(t *testing.T) {
+    type window struct {
+        Start           string `json:"start"`
+        End             string `json:"end"`
+        DurationMinutes int    `json:"duration_minutes"`
+        SuggestedStart  string `json:"suggested_start"`
+    }
+    call := func(args map[string]any) []window {
+        t.Helper()
+        res, err := handleMeetingOverlapWindows(context.Background(), testRequest("meeting_overlap_windows", args))
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        var body struct {
+            Windows []window `json:"windows"`
+        }
+        if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+            t.Fatalf("result is not JSON: %v", err)
+        }
+        return body.Windows
+    }
+
+    // New York (EDT) and London (BST) overlap 13:00-16:00 UTC in July
+    ws := call(map[string]any{
+        "timezones":  "America/New_York, Europe/London",
+        "start_date": "2025-07-07",
+        "days":       float64(1),
+    })
+    if len(ws) != 1 {
+        t.Fatalf("want 1 window, got %d", len(ws))
+    }
+    if ws[0].Start != "2025-07-07T13:00:00Z" || ws[0].End != "2025-07-07T16:00:00Z" || ws[0].DurationMinutes != 180 {
+        t.Errorf("unexpected window %+v", ws[0])
+    }
+
+    // Weekends are skipped by default
+    if ws := call(map[string]any{"timezones": "Europe/London", "start_date": "2025-07-05", "days": float64(2)}); len(ws) != 0 {
+        t.Errorf("want no windows on a weekend, got %d", len(ws))
+    }
+
+    // Tokyo and New York never overlap within 09:00-17:00
+    if ws := call(map[string]any{"timezones": "Asia/Tokyo,America/New_York", "start_date": "2025-07-07"}); len(ws) != 0 {
+        t.Errorf("want no windows for Tokyo/New York, got %d", len(ws))
+    }
+}
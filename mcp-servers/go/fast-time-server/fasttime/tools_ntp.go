@@ -0,0 +1,361 @@
+// -*- coding: utf-8 -*-
+// tools_ntp.go - host clock accuracy check for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements check_clock_accuracy, which queries the NTP servers
+// configured with -ntp-servers (SNTP, RFC 4330) and reports how far the host
+// clock is from them, and a /readyz check that fails when the measured drift
+// exceeds -ntp-max-drift. A skewed host clock silently corrupts every answer
+// this server gives, so it is worth taking the pod out of rotation for.
+//
+// The readiness check never queries NTP itself: a background monitor
+// measures every ntpCheckInterval and the check reads the last result. When
+// no server could be reached the check passes, since an NTP outage says
+// nothing about the local clock.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "math"
+    "net"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    defaultNTPServers = "pool.ntp.org"
+    ntpQueryTimeout   = 2 * time.Second
+    ntpCheckInterval  = 5 * time.Minute
+    maxNTPSamples     = 8
+)
+
+// ntpEpochOffset is the number of seconds between 1900-01-01 (the NTP era 0
+// epoch) and 1970-01-01
+const ntpEpochOffset = 2208988800
+
+// ntpServers lists the servers queried (set from -ntp-servers)
+var ntpServers = []string{defaultNTPServers}
+
+// ntpSample is one SNTP exchange
+type ntpSample struct {
+    offset  time.Duration // server clock minus host clock
+    delay   time.Duration // round trip, minus the server's processing time
+    stratum int
+}
+
+// ntpServerReport summarizes the samples taken from one server
+type ntpServerReport struct {
+    Server   string  `json:"server"`
+    OffsetMs float64 `json:"offset_ms,omitempty"`
+    DelayMs  float64 `json:"delay_ms,omitempty"`
+    JitterMs float64 `json:"jitter_ms,omitempty"`
+    Stratum  int     `json:"stratum,omitempty"`
+    Samples  int     `json:"samples"`
+    Error    string  `json:"error,omitempty"`
+}
+
+// ntpReport is the result of checking every configured server
+type ntpReport struct {
+    OffsetMs  float64           `json:"offset_ms"` // median of the servers' offsets
+    JitterMs  float64           `json:"jitter_ms"` // spread of the servers' offsets
+    Reachable int               `json:"reachable"`
+    Servers   []ntpServerReport `json:"servers"`
+    CheckedAt time.Time         `json:"checked_at"`
+}
+
+// ntpTime converts a 64-bit NTP timestamp to a time
+func ntpTime(b []byte) time.Time {
+    secs := int64(binary.BigEndian.Uint32(b[:4])) - ntpEpochOffset
+    frac := int64(binary.BigEndian.Uint32(b[4:8]))
+    return time.Unix(secs, frac*int64(time.Second)>>32)
+}
+
+// ntpAddress adds the NTP port to server unless it names one
+func ntpAddress(server string) string {
+    if _, _, err := net.SplitHostPort(server); err == nil {
+        return server
+    }
+    return net.JoinHostPort(server, "123")
+}
+
+// queryNTP performs one SNTP exchange with server
+func queryNTP(ctx context.Context, server string) (ntpSample, error) {
+    ctx, cancel := context.WithTimeout(ctx, ntpQueryTimeout)
+    defer cancel()
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "udp", ntpAddress(server))
+    if err != nil {
+        return ntpSample{}, err
+    }
+    defer conn.Close()
+    if deadline, ok := ctx.Deadline(); ok {
+        _ = conn.SetDeadline(deadline)
+    }
+
+    req := make([]byte, 48)
+    req[0] = 0<<6 | 4<<3 | 3 // no leap warning, version 4, client mode
+    sent := time.Now()
+    if _, err := conn.Write(req); err != nil {
+        return ntpSample{}, err
+    }
+    resp := make([]byte, 48)
+    n, err := conn.Read(resp)
+    received := time.Now()
+    if err != nil {
+        return ntpSample{}, err
+    }
+    if n < 48 {
+        return ntpSample{}, fmt.Errorf("short NTP response (%d bytes)", n)
+    }
+    if mode := resp[0] & 7; mode != 4 {
+        return ntpSample{}, fmt.Errorf("unexpected NTP mode %d", mode)
+    }
+    stratum := int(resp[1])
+    if stratum == 0 {
+        return ntpSample{}, errors.New("NTP server sent a kiss-o'-death")
+    }
+    if resp[0]>>6 == 3 {
+        return ntpSample{}, errors.New("NTP server is not synchronized")
+    }
+
+    rx, tx := ntpTime(resp[32:40]), ntpTime(resp[40:48])
+    return ntpSample{
+        offset:  (rx.Sub(sent) + tx.Sub(received)) / 2,
+        delay:   received.Sub(sent) - tx.Sub(rx),
+        stratum: stratum,
+    }, nil
+}
+
+// durationMs returns d in milliseconds rounded to microseconds
+func durationMs(d time.Duration) float64 {
+    return math.Round(float64(d)/1e3) / 1e3
+}
+
+// rmsSpread returns the root mean square distance of offsets from ref
+func rmsSpread(offsets []time.Duration, ref time.Duration) time.Duration {
+    if len(offsets) < 2 {
+        return 0
+    }
+    var sum float64
+    for _, o := range offsets {
+        diff := float64(o - ref)
+        sum += diff * diff
+    }
+    return time.Duration(math.Sqrt(sum / float64(len(offsets)-1)))
+}
+
+// medianDuration returns the median of ds, which must not be empty
+func medianDuration(ds []time.Duration) time.Duration {
+    sorted := append([]time.Duration(nil), ds...)
+    sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+    mid := len(sorted) / 2
+    if len(sorted)%2 == 0 {
+        return (sorted[mid-1] + sorted[mid]) / 2
+    }
+    return sorted[mid]
+}
+
+// checkNTPServer takes samples from one server. The offset reported is the
+// one from the exchange with the shortest round trip, which is the least
+// distorted by asymmetric network delay.
+func checkNTPServer(ctx context.Context, server string, samples int) ntpServerReport {
+    rep := ntpServerReport{Server: server}
+    var offsets []time.Duration
+    var best ntpSample
+    var lastErr error
+    for i := 0; i < samples && ctx.Err() == nil; i++ {
+        s, err := queryNTP(ctx, server)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        if len(offsets) == 0 || s.delay < best.delay {
+            best = s
+        }
+        offsets = append(offsets, s.offset)
+    }
+    if len(offsets) == 0 {
+        if lastErr == nil {
+            lastErr = ctx.Err()
+        }
+        rep.Error = lastErr.Error()
+        return rep
+    }
+    rep.Samples = len(offsets)
+    rep.OffsetMs = durationMs(best.offset)
+    rep.DelayMs = durationMs(best.delay)
+    rep.JitterMs = durationMs(rmsSpread(offsets, best.offset))
+    rep.Stratum = best.stratum
+    return rep
+}
+
+// checkClockAccuracy queries every server concurrently
+func checkClockAccuracy(ctx context.Context, servers []string, samples int) ntpReport {
+    rep := ntpReport{Servers: make([]ntpServerReport, len(servers))}
+    var wg sync.WaitGroup
+    for i, srv := range servers {
+        wg.Add(1)
+        go func(i int, srv string) {
+            defer wg.Done()
+            rep.Servers[i] = checkNTPServer(ctx, srv, samples)
+        }(i, srv)
+    }
+    wg.Wait()
+
+    var offsets []time.Duration
+    for _, s := range rep.Servers {
+        if s.Error == "" {
+            offsets = append(offsets, time.Duration(s.OffsetMs*float64(time.Millisecond)))
+        }
+    }
+    rep.Reachable = len(offsets)
+    rep.CheckedAt = time.Now().UTC()
+    if ctx.Err() == nil {
+        var err error
+        if rep.Reachable == 0 && len(servers) > 0 {
+            err = fmt.Errorf("no NTP server reachable: %s", rep.Servers[0].Error)
+        }
+        backends.report("ntp", err)
+    }
+    if len(offsets) > 0 {
+        median := medianDuration(offsets)
+        rep.OffsetMs = durationMs(median)
+        rep.JitterMs = durationMs(rmsSpread(offsets, median))
+    }
+    return rep
+}
+
+/* ------------------------------------------------------------------ */
+/*                          readiness monitor                         */
+/* ------------------------------------------------------------------ */
+
+// driftMonitor keeps the last NTP measurement for /readyz
+type driftMonitor struct {
+    mu       sync.Mutex
+    maxDrift time.Duration
+    last     *ntpReport
+}
+
+// drift is the process-wide monitor (nil unless -ntp-max-drift is set)
+var drift *driftMonitor
+
+// measure checks the configured servers and stores the result
+func (m *driftMonitor) measure(ctx context.Context) {
+    rep := checkClockAccuracy(ctx, ntpServers, 3)
+    if rep.Reachable == 0 {
+        logAt(logWarn, "ntp: no server reachable; clock drift unknown")
+    } else if off := math.Abs(rep.OffsetMs); off > durationMs(m.maxDrift) {
+        logAt(logError, "ntp: host clock is off by %.1fms (limit %s)", rep.OffsetMs, m.maxDrift)
+    } else {
+        logAt(logDebug, "ntp: host clock offset %.3fms jitter %.3fms", rep.OffsetMs, rep.JitterMs)
+    }
+    m.mu.Lock()
+    m.last = &rep
+    m.mu.Unlock()
+}
+
+// run measures now and then every ntpCheckInterval until ctx is done
+func (m *driftMonitor) run(ctx context.Context) {
+    ticker := time.NewTicker(ntpCheckInterval)
+    defer ticker.Stop()
+    for {
+        m.measure(ctx)
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// check is the /readyz check: it fails when the last measurement exceeded
+// the allowed drift
+func (m *driftMonitor) check() error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.last == nil || m.last.Reachable == 0 {
+        return nil
+    }
+    if off := math.Abs(m.last.OffsetMs); off > durationMs(m.maxDrift) {
+        return fmt.Errorf("host clock is off by %.1fms (limit %s)", m.last.OffsetMs, m.maxDrift)
+    }
+    return nil
+}
+
+/* ------------------------------------------------------------------ */
+/*                                 tool                               */
+/* ------------------------------------------------------------------ */
+
+// parseNTPServers splits a -ntp-servers value
+func parseNTPServers(list string) []string {
+    var out []string
+    for _, s := range strings.Split(list, ",") {
+        if s = strings.TrimSpace(s); s != "" {
+            out = append(out, s)
+        }
+    }
+    return out
+}
+
+// handleCheckClockAccuracy queries the configured NTP servers
+func handleCheckClockAccuracy(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    if len(ntpServers) == 0 {
+        return mcp.NewToolResultError("no NTP servers configured (see -ntp-servers)"), nil
+    }
+    samples := req.GetInt("samples", 3)
+    if samples < 1 || samples > maxNTPSamples {
+        return mcp.NewToolResultError(fmt.Sprintf("samples must be between 1 and %d", maxNTPSamples)), nil
+    }
+
+    rep := checkClockAccuracy(ctx, ntpServers, samples)
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+    result := map[string]interface{}{
+        "offset_ms":    rep.OffsetMs,
+        "jitter_ms":    rep.JitterMs,
+        "reachable":    rep.Reachable,
+        "servers":      rep.Servers,
+        "checked_at":   rep.CheckedAt.Format(time.RFC3339Nano),
+        "server_clock": clock.mode(),
+    }
+    if rep.Reachable == 0 {
+        result["status"] = "unknown"
+    } else if drift != nil && math.Abs(rep.OffsetMs) > durationMs(drift.maxDrift) {
+        result["status"] = "drifting"
+        result["max_drift_ms"] = durationMs(drift.maxDrift)
+    } else {
+        result["status"] = "ok"
+    }
+
+    logAt(logInfo, "check_clock_accuracy: offset=%.3fms reachable=%d/%d", rep.OffsetMs, rep.Reachable, len(ntpServers))
+    return toolResultJSON(result)
+}
+
+// registerNTPTools adds check_clock_accuracy to the server
+func registerNTPTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("check_clock_accuracy",
+        mcp.WithDescription("Query the configured NTP servers and report the host clock's estimated offset, round-trip delay and jitter"),
+        mcp.WithTitleAnnotation("Check Clock Accuracy"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false),
+        mcp.WithOpenWorldHintAnnotation(true),
+        mcp.WithNumber("samples",
+            mcp.Description("Exchanges per server; the one with the shortest round trip is reported. Defaults to 3"),
+            mcp.Min(1),
+            mcp.Max(maxNTPSamples),
+        ),
+    ), handleCheckClockAccuracy)
+}
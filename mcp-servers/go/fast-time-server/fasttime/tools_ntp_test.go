@@ -0,0 +1,127 @@
+// -*- coding: utf-8 -*-
+// tools_ntp_test.go - Tests for check_clock_accuracy and the drift check
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "math"
+    "net"
+    "testing"
+    "time"
+)
+
+// putNTPTime writes t as a 64-bit NTP timestamp
+func putNTPTime(b []byte, t time.Time) {
+    binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()+ntpEpochOffset))
+    binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/int64(time.Second)))
+}
+
+// fakeNTPServer answers SNTP requests with a clock running skew ahead of
+// the host's and returns its address
+func fakeNTPServer(t *testing.T, skew time.Duration) string {
+    t.Helper()
+    conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+    if err != nil {
+        t.Skipf("udp not available: %v", err)
+    }
+    t.Cleanup(func() { conn.Close() })
+    go func() {
+        buf := make([]byte, 48)
+        for {
+            _, addr, err := conn.ReadFrom(buf)
+            if err != nil {
+                return
+            }
+            resp := make([]byte, 48)
+            resp[0] = 4<<3 | 4 // version 4, server mode
+            resp[1] = 2
+            now := time.Now().Add(skew)
+            putNTPTime(resp[32:40], now)
+            putNTPTime(resp[40:48], now)
+            _, _ = conn.WriteTo(resp, addr)
+        }
+    }()
+    return conn.LocalAddr().String()
+}
+
+func TestNTPTimeRoundTrip(t *testing.T) {
+    want := time.Date(2025, 6, 1, 12, 0, 0, 250_000_000, time.UTC)
+    b := make([]byte, 8)
+    putNTPTime(b, want)
+    if got := ntpTime(b); got.Sub(want).Abs() > time.Microsecond {
+        t.Errorf("ntpTime = %v, want %v", got, want)
+    }
+    if ntpAddress("time.example.com") != "time.example.com:123" || ntpAddress("10.0.0.1:1123") != "10.0.0.1:1123" {
+        t.Error("ntpAddress should add port 123 only when missing")
+    }
+}
+
+func TestCheckClockAccuracy(t *testing.T) {
+    fast := fakeNTPServer(t, 2*time.Second)
+    slow := fakeNTPServer(t, 3*time.Second)
+    rep := checkClockAccuracy(context.Background(), []string{fast, slow, "127.0.0.1:1"}, 2)
+    if rep.Reachable != 2 {
+        t.Fatalf("reachable = %d: %+v", rep.Reachable, rep.Servers)
+    }
+    if math.Abs(rep.OffsetMs-2500) > 100 {
+        t.Errorf("median offset = %vms, want ~2500", rep.OffsetMs)
+    }
+    if rep.Servers[0].Samples != 2 || rep.Servers[0].Stratum != 2 || rep.Servers[2].Error == "" {
+        t.Errorf("servers = %+v", rep.Servers)
+    }
+}
+
+func TestCheckClockAccuracyTool(t *testing.T) {
+    defer func(prev []string) { ntpServers, drift = prev, nil }(ntpServers)
+    ntpServers = []string{fakeNTPServer(t, time.Second)}
+    drift = &driftMonitor{maxDrift: 500 * time.Millisecond}
+
+    res, err := handleCheckClockAccuracy(context.Background(), testRequest("check_clock_accuracy", map[string]interface{}{"samples": 1}))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var out map[string]interface{}
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatal(err)
+    }
+    if out["status"] != "drifting" || out["reachable"] != float64(1) {
+        t.Errorf("result = %v", out)
+    }
+
+    res, _ = handleCheckClockAccuracy(context.Background(), testRequest("check_clock_accuracy", map[string]interface{}{"samples": 20}))
+    if !res.IsError {
+        t.Error("too many samples should be rejected")
+    }
+}
+
+func TestDriftMonitorCheck(t *testing.T) {
+    defer func(prev []string) { ntpServers = prev }(ntpServers)
+    m := &driftMonitor{maxDrift: 500 * time.Millisecond}
+    if err := m.check(); err != nil {
+        t.Errorf("no measurement yet: %v", err)
+    }
+
+    ntpServers = []string{"127.0.0.1:1"}
+    m.measure(context.Background())
+    if err := m.check(); err != nil {
+        t.Errorf("unreachable NTP should not fail readiness: %v", err)
+    }
+
+    ntpServers = []string{fakeNTPServer(t, 0)}
+    m.measure(context.Background())
+    if err := m.check(); err != nil {
+        t.Errorf("accurate clock: %v", err)
+    }
+
+    ntpServers = []string{fakeNTPServer(t, -2*time.Second)}
+    m.measure(context.Background())
+    if err := m.check(); err == nil {
+        t.Error("2s drift should fail readiness")
+    }
+}
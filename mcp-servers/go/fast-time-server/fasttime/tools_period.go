@@ -0,0 +1,370 @@
+// -*- coding: utf-8 -*-
+// tools_period.go - calendar period tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements round_time and truncate_time, which snap a timestamp
+// to a granularity boundary (minute, 15 minutes, hour, day, week, month,
+// quarter, year) in a given timezone, and start_end_of_period, which returns
+// the bounds of the calendar or fiscal period containing a timestamp.
+// Boundaries are computed on the local wall clock so that "day" means local
+// midnight even across DST changes. Steps within a day are taken on the
+// instant first, so that in a repeated hour (when the clocks fall back) the
+// boundary is the one of the same occurrence as the input.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// periodUnit identifies a calendar unit
+type periodUnit int
+
+const (
+    unitClock periodUnit = iota // fixed step within a day (minutes, hours)
+    unitDay
+    unitWeek
+    unitMonth
+    unitQuarter
+    unitYear
+)
+
+// granularity is a parsed rounding granularity
+type granularity struct {
+    name string
+    unit periodUnit
+    step time.Duration // only for unitClock
+}
+
+// clockStepPattern matches loose forms like "15 minutes" or "2 hours"
+var clockStepPattern = regexp.MustCompile(`^(\d+)\s*(s|sec|secs|second|seconds|m|min|mins|minute|minutes|h|hr|hrs|hour|hours)$`)
+
+// parseGranularity resolves a granularity name such as "15m", "hour" or "quarter"
+func parseGranularity(s string) (granularity, error) {
+    key := strings.ToLower(strings.TrimSpace(s))
+    switch key {
+    case "second":
+        return granularity{"second", unitClock, time.Second}, nil
+    case "minute":
+        return granularity{"minute", unitClock, time.Minute}, nil
+    case "hour":
+        return granularity{"hour", unitClock, time.Hour}, nil
+    case "day", "daily":
+        return granularity{"day", unitDay, 0}, nil
+    case "week", "weekly":
+        return granularity{"week", unitWeek, 0}, nil
+    case "month", "monthly":
+        return granularity{"month", unitMonth, 0}, nil
+    case "quarter", "quarterly":
+        return granularity{"quarter", unitQuarter, 0}, nil
+    case "year", "yearly", "annual":
+        return granularity{"year", unitYear, 0}, nil
+    }
+
+    var step time.Duration
+    if m := clockStepPattern.FindStringSubmatch(key); m != nil {
+        n, _ := strconv.Atoi(m[1])
+        switch m[2][0] {
+        case 's':
+            step = time.Duration(n) * time.Second
+        case 'm':
+            step = time.Duration(n) * time.Minute
+        default:
+            step = time.Duration(n) * time.Hour
+        }
+    } else if d, err := time.ParseDuration(key); err == nil {
+        step = d
+    } else {
+        return granularity{}, fmt.Errorf("unknown granularity %q (use minute, 15m, hour, day, week, month, quarter or year)", s)
+    }
+
+    if step < time.Second || step > 24*time.Hour || step%time.Second != 0 {
+        return granularity{}, fmt.Errorf("granularity %q must be whole seconds between 1s and 24h", s)
+    }
+    return granularity{step.String(), unitClock, step}, nil
+}
+
+// floorTime returns the start of the granularity period containing t, in t's location
+func floorTime(t time.Time, g granularity, weekStart time.Weekday) time.Time {
+    loc := t.Location()
+    y, mo, d := t.Date()
+    switch g.unit {
+    case unitClock:
+        secs := clockSeconds(t)
+        step := int64(g.step / time.Second)
+        back := time.Duration(secs%step)*time.Second + time.Duration(t.Nanosecond())
+        if b := t.Add(-back); onClockGrid(b, g.step) {
+            return b
+        }
+        // A DST change lies in between: take the wall-clock boundary
+        return time.Date(y, mo, d, 0, 0, int(secs-secs%step), 0, loc)
+    case unitDay:
+        return time.Date(y, mo, d, 0, 0, 0, 0, loc)
+    case unitWeek:
+        back := (int(t.Weekday()) - int(weekStart) + 7) % 7
+        return time.Date(y, mo, d-back, 0, 0, 0, 0, loc)
+    case unitMonth:
+        return time.Date(y, mo, 1, 0, 0, 0, 0, loc)
+    case unitQuarter:
+        return time.Date(y, ((mo-1)/3)*3+1, 1, 0, 0, 0, 0, loc)
+    default:
+        return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+    }
+}
+
+// clockSeconds returns the seconds since local midnight shown by t's clock
+func clockSeconds(t time.Time) int64 {
+    return int64(t.Hour()*3600 + t.Minute()*60 + t.Second())
+}
+
+// onClockGrid reports whether t's wall clock is a whole multiple of step
+func onClockGrid(t time.Time, step time.Duration) bool {
+    return t.Nanosecond() == 0 && clockSeconds(t)%int64(step/time.Second) == 0
+}
+
+// nextBoundary returns the start of the period following the one beginning at start
+func nextBoundary(start time.Time, g granularity) time.Time {
+    y, mo, d := start.Date()
+    h, mi, s := start.Clock()
+    loc := start.Location()
+    switch g.unit {
+    case unitClock:
+        if next := start.Add(g.step); onClockGrid(next, g.step) {
+            return next
+        }
+        return time.Date(y, mo, d, h, mi, s+int(g.step/time.Second), 0, loc)
+    case unitDay:
+        return time.Date(y, mo, d+1, 0, 0, 0, 0, loc)
+    case unitWeek:
+        return time.Date(y, mo, d+7, 0, 0, 0, 0, loc)
+    case unitMonth:
+        return time.Date(y, mo+1, 1, 0, 0, 0, 0, loc)
+    case unitQuarter:
+        return time.Date(y, mo+3, 1, 0, 0, 0, 0, loc)
+    default:
+        return time.Date(y+1, time.January, 1, 0, 0, 0, 0, loc)
+    }
+}
+
+// roundTime returns the nearest period boundary to t; ties round up
+func roundTime(t time.Time, g granularity, weekStart time.Weekday) time.Time {
+    lower := floorTime(t, g, weekStart)
+    upper := nextBoundary(lower, g)
+    if t.Sub(lower) < upper.Sub(t) {
+        return lower
+    }
+    return upper
+}
+
+// floorPeriod is floorTime with quarters and years shifted to a fiscal year
+// beginning in fiscalStart
+func floorPeriod(t time.Time, g granularity, weekStart time.Weekday, fiscalStart time.Month) time.Time {
+    if fiscalStart == time.January || (g.unit != unitQuarter && g.unit != unitYear) {
+        return floorTime(t, g, weekStart)
+    }
+    y, mo, _ := t.Date()
+    back := (int(mo) - int(fiscalStart) + 12) % 12 // months since fiscal year start
+    if g.unit == unitQuarter {
+        back %= 3
+    }
+    return time.Date(y, mo-time.Month(back), 1, 0, 0, 0, 0, t.Location())
+}
+
+// fiscalYearOf returns the fiscal year containing t, named after the
+// calendar year in which it ends
+func fiscalYearOf(t time.Time, fiscalStart time.Month) int {
+    if fiscalStart == time.January || t.Month() < fiscalStart {
+        return t.Year()
+    }
+    return t.Year() + 1
+}
+
+// parseWeekday resolves a weekday name such as "monday" or "sun"
+func parseWeekday(s string) (time.Weekday, error) {
+    key := strings.ToLower(strings.TrimSpace(s))
+    for d := time.Sunday; d <= time.Saturday; d++ {
+        name := strings.ToLower(d.String())
+        if key == name || key == name[:3] {
+            return d, nil
+        }
+    }
+    return time.Sunday, fmt.Errorf("unknown weekday %q", s)
+}
+
+// makeRoundingHandler builds the handler shared by round_time and truncate_time
+func makeRoundingHandler(mode string) server.ToolHandlerFunc {
+    return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        granStr, err := req.RequireString("granularity")
+        if err != nil {
+            return mcp.NewToolResultError("granularity parameter is required"), nil
+        }
+        g, err := parseGranularity(granStr)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+
+        tz := req.GetString("timezone", "UTC")
+        loc, err := loadLocation(tz)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+
+        weekStart, err := parseWeekday(req.GetString("week_start", "monday"))
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+
+        t, err := timeArgIn(req, loc)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+
+        var result time.Time
+        if mode == "round" {
+            result = roundTime(t, g, weekStart)
+        } else {
+            result = floorTime(t, g, weekStart)
+        }
+
+        logAt(logInfo, "%s_time: %s to %s in %s = %s", mode, t.Format(time.RFC3339), g.name, tz, result.Format(time.RFC3339))
+        return toolResultJSON(map[string]interface{}{
+            "time":        t.Format(time.RFC3339Nano),
+            "result":      result.Format(time.RFC3339),
+            "unix":        result.Unix(),
+            "granularity": g.name,
+            "mode":        mode,
+            "timezone":    loc.String(),
+        })
+    }
+}
+
+// handleStartEndOfPeriod returns the bounds of the period containing a time
+func handleStartEndOfPeriod(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    periodStr, err := req.RequireString("period")
+    if err != nil {
+        return mcp.NewToolResultError("period parameter is required"), nil
+    }
+    g, err := parseGranularity(periodStr)
+    if err != nil || g.unit == unitClock {
+        return mcp.NewToolResultError(fmt.Sprintf("unknown period %q (use day, week, month, quarter or year)", periodStr)), nil
+    }
+
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    weekStart, err := parseWeekday(req.GetString("week_start", "monday"))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    fiscalStart := req.GetInt("fiscal_year_start_month", 1)
+    if fiscalStart < 1 || fiscalStart > 12 {
+        return mcp.NewToolResultError("fiscal_year_start_month must be between 1 and 12"), nil
+    }
+
+    t, err := timeArgIn(req, loc)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    start := floorPeriod(t, g, weekStart, time.Month(fiscalStart))
+    next := nextBoundary(start, g)
+
+    result := map[string]interface{}{
+        "time":       t.Format(time.RFC3339Nano),
+        "period":     g.name,
+        "timezone":   loc.String(),
+        "start":      start.Format(time.RFC3339),
+        "end":        next.Add(-time.Nanosecond).Format(time.RFC3339Nano),
+        "next_start": next.Format(time.RFC3339),
+        "duration":   next.Sub(start).String(),
+    }
+    switch g.unit {
+    case unitWeek:
+        result["week_start"] = weekStart.String()
+    case unitQuarter:
+        months := (int(start.Month()) - fiscalStart + 12) % 12
+        result["quarter"] = months/3 + 1
+        result["fiscal_year"] = fiscalYearOf(start, time.Month(fiscalStart))
+    case unitYear:
+        result["fiscal_year"] = fiscalYearOf(start, time.Month(fiscalStart))
+    }
+
+    logAt(logInfo, "start_end_of_period: %s of %s in %s = %s", g.name, t.Format(time.RFC3339), tz, start.Format(time.RFC3339))
+    return toolResultJSON(result)
+}
+
+// registerPeriodTools adds the calendar period tools to the server
+func registerPeriodTools(s *server.MCPServer) {
+    granularityDesc := "Granularity: minute, 15m (or '15 minutes'), hour, day, week, month, quarter, year, or any whole-second step up to 24h"
+
+    for _, mode := range []string{"round", "truncate"} {
+        title := "Round Time"
+        desc := "Round a timestamp to the nearest granularity boundary in a timezone (ties round up)"
+        if mode == "truncate" {
+            title = "Truncate Time"
+            desc = "Truncate a timestamp to the start of its granularity period in a timezone"
+        }
+        s.AddTool(mcp.NewTool(mode+"_time",
+            mcp.WithDescription(desc),
+            mcp.WithTitleAnnotation(title),
+            mcp.WithReadOnlyHintAnnotation(true),
+            mcp.WithDestructiveHintAnnotation(false),
+            mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+            mcp.WithOpenWorldHintAnnotation(false),
+            mcp.WithString("granularity",
+                mcp.Required(),
+                mcp.Description(granularityDesc),
+            ),
+            mcp.WithString("time",
+                mcp.Description("Time in RFC3339 or common formats. Defaults to now"),
+            ),
+            mcp.WithString("timezone",
+                mcp.Description("IANA timezone whose wall clock defines the boundaries. Defaults to UTC"),
+            ),
+            mcp.WithString("week_start",
+                mcp.Description("First day of the week for week granularity. Defaults to monday"),
+            ),
+        ), makeRoundingHandler(mode))
+    }
+
+    s.AddTool(mcp.NewTool("start_end_of_period",
+        mcp.WithDescription("Get the start and end instants of the day, week, month, quarter or year containing a time, with configurable week start and fiscal year"),
+        mcp.WithTitleAnnotation("Start/End of Period"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("period",
+            mcp.Required(),
+            mcp.Description("Calendar period"),
+            mcp.Enum("day", "week", "month", "quarter", "year"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time in RFC3339 or common formats. Defaults to now"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone whose wall clock defines the period. Defaults to UTC"),
+        ),
+        mcp.WithString("week_start",
+            mcp.Description("First day of the week"),
+            mcp.Enum("monday", "sunday"),
+        ),
+        mcp.WithNumber("fiscal_year_start_month",
+            mcp.Description("Month (1-12) in which the fiscal year starts, shifting quarters and years. Defaults to 1 (calendar year)"),
+        ),
+    ), handleStartEndOfPeriod)
+}
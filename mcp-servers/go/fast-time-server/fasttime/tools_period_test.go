@@ -0,0 +1,199 @@
+// -*- coding: utf-8 -*-
+// tools_period_test.go - Tests for calendar period tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestParseGranularity(t *testing.T) {
+    tests := []struct {
+        in   string
+        unit periodUnit
+        step time.Duration
+    }{
+        {"minute", unitClock, time.Minute},
+        {"15m", unitClock, 15 * time.Minute},
+        {"15 minutes", unitClock, 15 * time.Minute},
+        {"2 hours", unitClock, 2 * time.Hour},
+        {"1h30m", unitClock, 90 * time.Minute},
+        {"Day", unitDay, 0},
+        {"quarter", unitQuarter, 0},
+    }
+    for _, tt := range tests {
+        g, err := parseGranularity(tt.in)
+        if err != nil {
+            t.Errorf("parseGranularity(%q) error: %v", tt.in, err)
+            continue
+        }
+        if g.unit != tt.unit || g.step != tt.step {
+            t.Errorf("parseGranularity(%q) = %+v", tt.in, g)
+        }
+    }
+    for _, bad := range []string{"fortnight", "500ms", "48h"} {
+        if _, err := parseGranularity(bad); err == nil {
+            t.Errorf("parseGranularity(%q) should fail", bad)
+        }
+    }
+}
+
+func TestFloorAndRoundTime(t *testing.T) {
+    kolkata, _ := loadLocation("Asia/Kolkata")
+    base := time.Date(2025, 8, 14, 10, 52, 30, 0, kolkata) // Thursday
+
+    tests := []struct {
+        gran      string
+        wantFloor string
+        wantRound string
+    }{
+        {"15m", "2025-08-14T10:45:00+05:30", "2025-08-14T11:00:00+05:30"},
+        {"hour", "2025-08-14T10:00:00+05:30", "2025-08-14T11:00:00+05:30"},
+        {"day", "2025-08-14T00:00:00+05:30", "2025-08-14T00:00:00+05:30"},
+        {"week", "2025-08-11T00:00:00+05:30", "2025-08-11T00:00:00+05:30"},
+        {"month", "2025-08-01T00:00:00+05:30", "2025-08-01T00:00:00+05:30"},
+        {"quarter", "2025-07-01T00:00:00+05:30", "2025-07-01T00:00:00+05:30"},
+        {"year", "2025-01-01T00:00:00+05:30", "2026-01-01T00:00:00+05:30"},
+    }
+    for _, tt := range tests {
+        g, _ := parseGranularity(tt.gran)
+        if got := floorTime(base, g, time.Monday).Format(time.RFC3339); got != tt.wantFloor {
+            t.Errorf("floor %s: want %s, got %s", tt.gran, tt.wantFloor, got)
+        }
+        if got := roundTime(base, g, time.Monday).Format(time.RFC3339); got != tt.wantRound {
+            t.Errorf("round %s: want %s, got %s", tt.gran, tt.wantRound, got)
+        }
+    }
+
+    // Day boundaries follow local midnight across a DST change
+    ny, _ := loadLocation("America/New_York")
+    g, _ := parseGranularity("day")
+    dstDay := time.Date(2025, 3, 9, 12, 0, 0, 0, ny)
+    if got := nextBoundary(floorTime(dstDay, g, time.Monday), g).Format(time.RFC3339); got != "2025-03-10T00:00:00-04:00" {
+        t.Errorf("next day boundary across DST wrong: %s", got)
+    }
+
+    // Steps within a day keep to the occurrence of a repeated hour, and skip
+    // the hour the clocks jump over
+    for _, tt := range []struct {
+        in, gran, wantFloor, wantRound string
+    }{
+        {"2025-11-02T01:40:00-05:00", "15m", "2025-11-02T01:30:00-05:00", "2025-11-02T01:45:00-05:00"},
+        {"2025-11-02T01:40:00-04:00", "15m", "2025-11-02T01:30:00-04:00", "2025-11-02T01:45:00-04:00"},
+        {"2025-11-02T01:55:00-04:00", "15m", "2025-11-02T01:45:00-04:00", "2025-11-02T01:00:00-05:00"},
+        {"2025-11-02T01:05:00-05:00", "hour", "2025-11-02T01:00:00-05:00", "2025-11-02T01:00:00-05:00"},
+        {"2025-11-02T01:35:00-05:00", "hour", "2025-11-02T01:00:00-05:00", "2025-11-02T02:00:00-05:00"},
+        {"2025-11-02T01:05:00-05:00", "2h", "2025-11-02T00:00:00-04:00", "2025-11-02T02:00:00-05:00"},
+        {"2025-03-09T01:55:00-05:00", "15m", "2025-03-09T01:45:00-05:00", "2025-03-09T03:00:00-04:00"},
+    } {
+        in, _ := time.Parse(time.RFC3339, tt.in)
+        in = in.In(ny)
+        g, _ := parseGranularity(tt.gran)
+        floor, round := floorTime(in, g, time.Monday), roundTime(in, g, time.Monday)
+        if got := floor.Format(time.RFC3339); got != tt.wantFloor || floor.After(in) {
+            t.Errorf("floor %s at %s: want %s, got %s", tt.gran, tt.in, tt.wantFloor, got)
+        }
+        if got := round.Format(time.RFC3339); got != tt.wantRound {
+            t.Errorf("round %s at %s: want %s, got %s", tt.gran, tt.in, tt.wantRound, got)
+        }
+    }
+}
+
+func TestHandleTruncateTime(t *testing.T) {
+    handler := makeRoundingHandler("truncate")
+    req := testRequest("truncate_time", map[string]any{
+        "time":        "2025-06-18T15:20:00Z",
+        "granularity": "week",
+        "timezone":    "UTC",
+        "week_start":  "sunday",
+    })
+    res, err := handler(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        Result string `json:"result"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.Result != "2025-06-15T00:00:00Z" {
+        t.Errorf("want Sunday week start, got %s", body.Result)
+    }
+
+    res, _ = handler(context.Background(), testRequest("truncate_time", map[string]any{"granularity": "eon"}))
+    if !res.IsError {
+        t.Error("expected error result for bad granularity")
+    }
+}
+
+func TestFloorPeriodFiscal(t *testing.T) {
+    q, _ := parseGranularity("quarter")
+    y, _ := parseGranularity("year")
+    // Fiscal year starting in October (FY2026 = Oct 2025 - Sep 2026)
+    ts := time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+
+    if got := floorPeriod(ts, q, time.Monday, time.October).Format("2006-01-02"); got != "2025-10-01" {
+        t.Errorf("fiscal quarter start: got %s", got)
+    }
+    start := floorPeriod(ts, y, time.Monday, time.October)
+    if got := start.Format("2006-01-02"); got != "2025-10-01" {
+        t.Errorf("fiscal year start: got %s", got)
+    }
+    if fy := fiscalYearOf(start, time.October); fy != 2026 {
+        t.Errorf("want FY2026, got %d", fy)
+    }
+
+    // April start wraps back into the previous calendar year
+    feb := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+    if got := floorPeriod(feb, y, time.Monday, time.April).Format("2006-01-02"); got != "2025-04-01" {
+        t.Errorf("april fiscal year start: got %s", got)
+    }
+}
+
+func TestHandleStartEndOfPeriod(t *testing.T) {
+    res, err := handleStartEndOfPeriod(context.Background(), testRequest("start_end_of_period", map[string]any{
+        "period":                  "quarter",
+        "time":                    "2025-11-20T12:00:00",
+        "timezone":                "America/New_York",
+        "fiscal_year_start_month": float64(10),
+    }))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        Start      string `json:"start"`
+        End        string `json:"end"`
+        NextStart  string `json:"next_start"`
+        Quarter    int    `json:"quarter"`
+        FiscalYear int    `json:"fiscal_year"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.Start != "2025-10-01T00:00:00-04:00" || body.NextStart != "2026-01-01T00:00:00-05:00" {
+        t.Errorf("unexpected bounds: %+v", body)
+    }
+    if body.End != "2025-12-31T23:59:59.999999999-05:00" {
+        t.Errorf("unexpected end: %s", body.End)
+    }
+    if body.Quarter != 1 || body.FiscalYear != 2026 {
+        t.Errorf("want Q1 FY2026, got Q%d FY%d", body.Quarter, body.FiscalYear)
+    }
+
+    for _, args := range []map[string]any{
+        {"period": "15m"},
+        {"period": "year", "fiscal_year_start_month": float64(13)},
+    } {
+        res, _ := handleStartEndOfPeriod(context.Background(), testRequest("start_end_of_period", args))
+        if !res.IsError {
+            t.Errorf("expected error result for %v", args)
+        }
+    }
+}
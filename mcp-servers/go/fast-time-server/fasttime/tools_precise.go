@@ -0,0 +1,124 @@
+// -*- coding: utf-8 -*-
+// tools_precise.go - high-precision time tool for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements get_precise_time for agents benchmarking distributed
+// systems: the wall clock with nanosecond digits, a monotonic reading that
+// never jumps with NTP steps or -time-offset, and an estimate of how finely
+// each clock actually ticks on this host. The monotonic reading counts from
+// server start, since Go does not expose the raw OS counter; differences
+// between two readings of the same server are exact.
+//
+// Resolution is the smallest non-zero step seen between back-to-back clock
+// reads over `samples` tries; call_overhead_ns is the median cost of one
+// read. Both are estimates and vary with load.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Bounds of the samples argument of get_precise_time
+const (
+    defaultPreciseSamples = 100
+    maxPreciseSamples     = 10000
+)
+
+// maxClockSpins caps the reads spent waiting for one clock tick
+const maxClockSpins = 1 << 16
+
+// clockResolution is the estimated granularity of the clocks
+type clockResolution struct {
+    WallNs         int64 `json:"wall_resolution_ns"`
+    MonotonicNs    int64 `json:"monotonic_resolution_ns"`
+    CallOverheadNs int64 `json:"call_overhead_ns"`
+    Samples        int   `json:"samples"`
+}
+
+// estimateResolution reads the clock until it ticks, samples times, and
+// keeps the smallest wall and monotonic steps; a clock that never ticked
+// within maxClockSpins reads reports 0
+func estimateResolution(samples int) clockResolution {
+    res := clockResolution{Samples: samples}
+    overheads := make([]int64, 0, samples)
+    for i := 0; i < samples; i++ {
+        t0 := time.Now()
+        t1 := time.Now()
+        overheads = append(overheads, int64(t1.Sub(t0)))
+        for n := 0; n < maxClockSpins && t1.Sub(t0) == 0 && t1.UnixNano() == t0.UnixNano(); n++ {
+            t1 = time.Now()
+        }
+        if d := int64(t1.Sub(t0)); d > 0 && (res.MonotonicNs == 0 || d < res.MonotonicNs) {
+            res.MonotonicNs = d
+        }
+        if d := t1.UnixNano() - t0.UnixNano(); d > 0 && (res.WallNs == 0 || d < res.WallNs) {
+            res.WallNs = d
+        }
+    }
+    sort.Slice(overheads, func(i, j int) bool { return overheads[i] < overheads[j] })
+    res.CallOverheadNs = overheads[len(overheads)/2]
+    return res
+}
+
+// handleGetPreciseTime returns the wall clock with nanosecond precision, the
+// monotonic clock and their estimated resolution
+func handleGetPreciseTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return toolError(fieldError("timezone", err)), nil
+    }
+    samples := req.GetInt("samples", defaultPreciseSamples)
+    if samples < 1 || samples > maxPreciseSamples {
+        return mcp.NewToolResultError(fmt.Sprintf("samples must be between 1 and %d", maxPreciseSamples)), nil
+    }
+
+    // Read both clocks together, before the estimate spends time
+    mono := time.Since(startTime)
+    now := currentTime().In(loc)
+    res := estimateResolution(samples)
+
+    result := map[string]interface{}{
+        "time":             now.Format(time.RFC3339Nano),
+        "timezone":         loc.String(),
+        "unix_nano":        now.UnixNano(),
+        "unix_seconds":     now.Unix(),
+        "nanoseconds":      now.Nanosecond(),
+        "monotonic_ns":     mono.Nanoseconds(),
+        "monotonic_origin": startTime.UTC().Format(time.RFC3339Nano),
+        "resolution":       res,
+        "server_clock":     clock.mode(),
+    }
+
+    logAt(logInfo, "get_precise_time: %s monotonic=%dns resolution=%dns", result["time"], mono.Nanoseconds(), res.MonotonicNs)
+    return toolResultJSON(result)
+}
+
+// registerPreciseTools adds get_precise_time to the server
+func registerPreciseTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("get_precise_time",
+        mcp.WithDescription("Get the current time with nanosecond precision, a monotonic clock reading for measuring intervals, and an estimate of the clock resolution"),
+        mcp.WithTitleAnnotation("Get Precise Time"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Depends on the current time
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for the wall-clock time. Defaults to UTC"),
+        ),
+        mcp.WithNumber("samples",
+            mcp.Description(fmt.Sprintf("Clock reads used to estimate the resolution. Defaults to %d", defaultPreciseSamples)),
+            mcp.Min(1),
+            mcp.Max(maxPreciseSamples),
+        ),
+    ), handleGetPreciseTime)
+}
@@ -0,0 +1,60 @@
+// -*- coding: utf-8 -*-
+// tools_precise_test.go - Tests for get_precise_time
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestEstimateResolution(t *testing.T) {
+    res := estimateResolution(50)
+    if res.Samples != 50 || res.MonotonicNs <= 0 || res.WallNs <= 0 {
+        t.Errorf("resolution = %+v", res)
+    }
+    if res.MonotonicNs > int64(time.Second) {
+        t.Errorf("monotonic resolution %dns is implausible", res.MonotonicNs)
+    }
+}
+
+func TestHandleGetPreciseTime(t *testing.T) {
+    call := func(args map[string]any) map[string]any {
+        t.Helper()
+        res, err := handleGetPreciseTime(context.Background(), testRequest("get_precise_time", args))
+        if err != nil || res.IsError {
+            t.Fatalf("get_precise_time(%v) = %v, %v", args, res, err)
+        }
+        var out map[string]any
+        if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+            t.Fatal(err)
+        }
+        return out
+    }
+
+    first := call(map[string]any{"timezone": "Asia/Tokyo", "samples": 10})
+    second := call(nil)
+    if first["timezone"] != "Asia/Tokyo" || second["timezone"] != "UTC" {
+        t.Errorf("timezones = %v, %v", first["timezone"], second["timezone"])
+    }
+    if _, err := time.Parse(time.RFC3339Nano, first["time"].(string)); err != nil {
+        t.Errorf("time = %v", first["time"])
+    }
+    if first["monotonic_ns"].(float64) >= second["monotonic_ns"].(float64) {
+        t.Errorf("monotonic went backwards: %v then %v", first["monotonic_ns"], second["monotonic_ns"])
+    }
+    if res := second["resolution"].(map[string]any); res["samples"] != float64(defaultPreciseSamples) {
+        t.Errorf("resolution = %v", res)
+    }
+
+    for _, args := range []map[string]any{{"samples": 0}, {"samples": maxPreciseSamples + 1}, {"timezone": "Mars/Base"}} {
+        if res, _ := handleGetPreciseTime(context.Background(), testRequest("get_precise_time", args)); !res.IsError {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
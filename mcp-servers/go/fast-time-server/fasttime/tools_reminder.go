@@ -0,0 +1,359 @@
+// -*- coding: utf-8 -*-
+// tools_reminder.go - reminder tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements set_reminder, list_reminders and cancel_reminder, so
+// an agent can schedule a follow-up for itself. When a reminder is due the
+// server sends the session a notifications/message (logger "reminder") with
+// the reminder's message. If that cannot be delivered - the client is no
+// longer listening - the reminder goes to its webhook when one was given
+// (signed and restricted like the schedule API, so only with authentication
+// on), and is otherwise written to the server log.
+//
+// Reminders belong to the MCP session that set them, like the timer_* tools,
+// and are dropped when it disconnects; they can be at most maxReminderDelay
+// ahead. Unlike /api/v1/schedules they are not persisted.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// Reminder limits
+const (
+    maxRemindersPerSession = 100
+    maxReminderDelay       = 24 * time.Hour
+    maxReminderMessage     = 1000
+)
+
+// methodNotificationMessage carries a log message to the client
+const methodNotificationMessage = "notifications/message"
+
+// reminderLogger names reminders in notifications/message
+const reminderLogger = "reminder"
+
+// reminder is one pending reminder
+type reminder struct {
+    id      string
+    session string
+    message string
+    due     time.Time
+    loc     *time.Location
+    set     time.Time
+    webhook string
+
+    srv   *server.MCPServer
+    timer *time.Timer
+}
+
+// describe renders the reminder for tool results
+func (r *reminder) describe(now time.Time) map[string]interface{} {
+    human, _ := formatDurationStyle(r.due.Sub(now).Round(time.Second), "short")
+    out := map[string]interface{}{
+        "id":             r.id,
+        "message":        r.message,
+        "due_at":         r.due.In(r.loc).Format(time.RFC3339),
+        "timezone":       r.loc.String(),
+        "due_in":         human,
+        "due_in_seconds": roundSeconds(r.due.Sub(now)),
+        "set_at":         r.set.UTC().Format(time.RFC3339),
+    }
+    if r.webhook != "" {
+        out["webhook"] = r.webhook
+    }
+    return out
+}
+
+// sessionReminders holds the pending reminders of every session
+type sessionReminders struct {
+    mu        sync.Mutex
+    bySession map[string]map[string]*reminder
+    seq       int
+}
+
+// reminders is the process-wide reminder store
+var reminders = newSessionReminders()
+
+// newSessionReminders creates an empty store
+func newSessionReminders() *sessionReminders {
+    return &sessionReminders{bySession: make(map[string]map[string]*reminder)}
+}
+
+// add arms r and records it under its session
+func (sr *sessionReminders) add(r *reminder, now time.Time) error {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    mine := sr.bySession[r.session]
+    if len(mine) >= maxRemindersPerSession {
+        return fmt.Errorf("too many reminders (max %d per session)", maxRemindersPerSession)
+    }
+    if mine == nil {
+        mine = make(map[string]*reminder)
+        sr.bySession[r.session] = mine
+    }
+    sr.seq++
+    r.id = fmt.Sprintf("rem-%d", sr.seq)
+    mine[r.id] = r
+    r.timer = time.AfterFunc(r.due.Sub(now), func() { sr.fire(r) })
+    return nil
+}
+
+// take removes and returns reminder id of a session
+func (sr *sessionReminders) take(sessionID, id string) (*reminder, bool) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    r, ok := sr.bySession[sessionID][id]
+    if !ok {
+        return nil, false
+    }
+    delete(sr.bySession[sessionID], id)
+    if len(sr.bySession[sessionID]) == 0 {
+        delete(sr.bySession, sessionID)
+    }
+    return r, true
+}
+
+// list returns a session's reminders, soonest first
+func (sr *sessionReminders) list(sessionID string) []*reminder {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    out := make([]*reminder, 0, len(sr.bySession[sessionID]))
+    for _, r := range sr.bySession[sessionID] {
+        out = append(out, r)
+    }
+    sort.Slice(out, func(i, j int) bool {
+        if !out[i].due.Equal(out[j].due) {
+            return out[i].due.Before(out[j].due)
+        }
+        return out[i].set.Before(out[j].set)
+    })
+    return out
+}
+
+// dropSession cancels every reminder of a session
+func (sr *sessionReminders) dropSession(sessionID string) int {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    mine := sr.bySession[sessionID]
+    for _, r := range mine {
+        r.timer.Stop()
+    }
+    delete(sr.bySession, sessionID)
+    return len(mine)
+}
+
+// fire delivers a due reminder: to the session, else its webhook, else the log
+func (sr *sessionReminders) fire(r *reminder) {
+    if _, ok := sr.take(r.session, r.id); !ok {
+        return // cancelled
+    }
+    data := map[string]interface{}{
+        "reminder_id": r.id,
+        "message":     r.message,
+        "due_at":      r.due.In(r.loc).Format(time.RFC3339),
+        "set_at":      r.set.UTC().Format(time.RFC3339),
+    }
+
+    var err error = errNoSession
+    if r.srv != nil {
+        err = r.srv.SendNotificationToSpecificClient(r.session, methodNotificationMessage, map[string]any{
+            "level":  mcp.LoggingLevelInfo,
+            "logger": reminderLogger,
+            "data":   data,
+        })
+    }
+    if err == nil {
+        logAt(logInfo, "reminder: %s sent to session %s", r.id, r.session)
+        return
+    }
+
+    if r.webhook != "" && scheduler != nil {
+        payload, _ := json.Marshal(data)
+        _, werr := scheduler.deliver(context.Background(), webhookSchedule{
+            ID:       r.id,
+            URL:      r.webhook,
+            Timezone: r.loc.String(),
+            NextRun:  r.due.In(r.loc).Format(time.RFC3339),
+            Payload:  payload,
+        }, time.Now())
+        if werr == nil {
+            logAt(logInfo, "reminder: %s sent to %s (session %s: %v)", r.id, r.webhook, r.session, err)
+            return
+        }
+        err = fmt.Errorf("%v; webhook: %v", err, werr)
+    }
+    logAt(logWarn, "reminder: %s for session %s could not be delivered (%v): %s", r.id, r.session, err, r.message)
+}
+
+// errNoSession is the delivery error of reminders set outside an MCP session
+var errNoSession = errors.New("no MCP session to notify")
+
+// reminderDue reads the due time from either at (with timezone) or in
+func reminderDue(req mcp.CallToolRequest, now time.Time) (time.Time, *time.Location, error) {
+    at := strings.TrimSpace(req.GetString("at", ""))
+    in := strings.TrimSpace(req.GetString("in", ""))
+    loc, err := loadLocation(req.GetString("timezone", "UTC"))
+    if err != nil {
+        return time.Time{}, nil, err
+    }
+
+    var due time.Time
+    switch {
+    case (at == "") == (in == ""):
+        return time.Time{}, nil, fmt.Errorf("give exactly one of at (a time) or in (a duration)")
+    case in != "":
+        pd, err := parseDurationFlexible(in)
+        if err != nil {
+            return time.Time{}, nil, err
+        }
+        due = now.Add(pd.d)
+    default:
+        if due, _, err = parseScheduleTime(at, loc); err != nil {
+            return time.Time{}, nil, err
+        }
+    }
+
+    switch d := due.Sub(now); {
+    case d <= 0:
+        return time.Time{}, nil, fmt.Errorf("%s is not in the future", due.In(loc).Format(time.RFC3339))
+    case d > maxReminderDelay:
+        return time.Time{}, nil, fmt.Errorf("reminders can be at most %s ahead", maxReminderDelay)
+    }
+    return due, loc, nil
+}
+
+// handleSetReminder schedules a reminder for the calling session
+func handleSetReminder(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    message := strings.TrimSpace(req.GetString("message", ""))
+    switch {
+    case message == "":
+        return mcp.NewToolResultError("message parameter is required"), nil
+    case len(message) > maxReminderMessage:
+        return mcp.NewToolResultError(fmt.Sprintf("message is longer than %d bytes", maxReminderMessage)), nil
+    }
+    now := time.Now()
+    due, loc, err := reminderDue(req, now)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    webhook := strings.TrimSpace(req.GetString("webhook", ""))
+    if webhook != "" {
+        if scheduler == nil {
+            return mcp.NewToolResultError("webhook reminders need authentication: start the server with -auth-token or -auth-tokens-file"), nil
+        }
+        if err := scheduler.checkURL(webhook); err != nil {
+            return mcp.NewToolResultError("webhook: " + err.Error()), nil
+        }
+    }
+
+    r := &reminder{
+        session: sessionIDFrom(ctx),
+        message: message,
+        due:     due,
+        loc:     loc,
+        set:     now,
+        webhook: webhook,
+        srv:     server.ServerFromContext(ctx),
+    }
+    if err := reminders.add(r, now); err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    logAt(logInfo, "set_reminder: %s due %s", r.id, due.In(loc).Format(time.RFC3339))
+    return toolResultJSON(r.describe(now))
+}
+
+// handleListReminders lists the calling session's pending reminders
+func handleListReminders(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    now := time.Now()
+    mine := reminders.list(sessionIDFrom(ctx))
+    list := make([]map[string]interface{}, len(mine))
+    for i, r := range mine {
+        list[i] = r.describe(now)
+    }
+    return toolResultJSON(map[string]interface{}{
+        "reminders": list,
+        "count":     len(list),
+    })
+}
+
+// handleCancelReminder cancels one of the calling session's reminders
+func handleCancelReminder(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    id := strings.TrimSpace(req.GetString("id", ""))
+    if id == "" {
+        return mcp.NewToolResultError("id parameter is required"), nil
+    }
+    r, ok := reminders.take(sessionIDFrom(ctx), id)
+    if !ok {
+        return mcp.NewToolResultError(fmt.Sprintf("unknown reminder %q", id)), nil
+    }
+    r.timer.Stop()
+
+    logAt(logInfo, "cancel_reminder: %s", id)
+    out := r.describe(time.Now())
+    out["cancelled"] = true
+    return toolResultJSON(out)
+}
+
+// registerReminderTools adds set_reminder, list_reminders and cancel_reminder
+func registerReminderTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("set_reminder",
+        mcp.WithDescription(fmt.Sprintf("Schedule a reminder (at most %s ahead) that this session receives as a notifications/message from logger %q", maxReminderDelay, reminderLogger)),
+        mcp.WithTitleAnnotation("Set Reminder"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("message",
+            mcp.Required(),
+            mcp.Description("Text delivered with the reminder"),
+        ),
+        mcp.WithString("at",
+            mcp.Description("When to remind, in RFC3339 or 'YYYY-MM-DD HH:MM' local to timezone. Give at or in"),
+        ),
+        mcp.WithString("in",
+            mcp.Description("Delay before the reminder, e.g. '15m', 'PT2H' or '1 hour 30 minutes'. Give at or in"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for at without an offset. Defaults to UTC"),
+        ),
+        mcp.WithString("webhook",
+            mcp.Description("URL POSTed instead when the session cannot be notified (needs server authentication)"),
+        ),
+    ), handleSetReminder)
+
+    s.AddTool(mcp.NewTool("list_reminders",
+        mcp.WithDescription("List this session's pending reminders, soonest first"),
+        mcp.WithTitleAnnotation("List Reminders"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Reminders fire and due_in counts down
+        mcp.WithOpenWorldHintAnnotation(false),
+    ), handleListReminders)
+
+    s.AddTool(mcp.NewTool("cancel_reminder",
+        mcp.WithDescription("Cancel one of this session's pending reminders"),
+        mcp.WithTitleAnnotation("Cancel Reminder"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(true),
+        mcp.WithIdempotentHintAnnotation(false),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("id",
+            mcp.Required(),
+            mcp.Description("Reminder id returned by set_reminder"),
+        ),
+    ), handleCancelReminder)
+}
@@ -0,0 +1,154 @@
+// -*- coding: utf-8 -*-
+// tools_reminder_test.go - tests for the reminder tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// callReminderTool invokes a reminder handler and decodes its result
+func callReminderTool(t *testing.T, ctx context.Context, h server.ToolHandlerFunc, args map[string]any) (map[string]interface{}, *mcp.CallToolResult) {
+    t.Helper()
+    res, err := h(ctx, testRequest("reminder", args))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    var out map[string]interface{}
+    if !res.IsError {
+        if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+            t.Fatalf("bad JSON: %v", err)
+        }
+    }
+    return out, res
+}
+
+func TestReminderValidation(t *testing.T) {
+    ctx := timerContext("rem-validate")
+    defer reminders.dropSession("rem-validate")
+
+    past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+    far := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+    for _, args := range []map[string]any{
+        {"in": "5m"},
+        {"message": "x"},
+        {"message": "x", "in": "5m", "at": far},
+        {"message": "x", "at": past},
+        {"message": "x", "at": far},
+        {"message": "x", "in": "soon"},
+        {"message": "x", "in": "5m", "timezone": "Mars/Olympus"},
+        {"message": "x", "in": "5m", "webhook": "https://example.com/hook"}, // no scheduler without auth
+    } {
+        if _, res := callReminderTool(t, ctx, handleSetReminder, args); !res.IsError {
+            t.Errorf("set_reminder(%v) should fail", args)
+        }
+    }
+}
+
+func TestReminderLifecycle(t *testing.T) {
+    a, b := timerContext("rem-a"), timerContext("rem-b")
+    tokyo, _ := time.LoadLocation("Asia/Tokyo")
+    defer reminders.dropSession("rem-a")
+    defer reminders.dropSession("rem-b")
+
+    later, _ := callReminderTool(t, a, handleSetReminder, map[string]any{"message": "check the deploy", "in": "2h"})
+    sooner, _ := callReminderTool(t, a, handleSetReminder, map[string]any{
+        "message": "standup", "at": time.Now().Add(time.Hour).In(tokyo).Format("2006-01-02T15:04:05"), "timezone": "Asia/Tokyo",
+    })
+    if later["id"] == "" || sooner["timezone"] != "Asia/Tokyo" || later["due_in"] != "2h" {
+        t.Fatalf("set_reminder = %v / %v", later, sooner)
+    }
+
+    list, _ := callReminderTool(t, a, handleListReminders, nil)
+    items := list["reminders"].([]interface{})
+    if list["count"].(float64) != 2 || items[0].(map[string]interface{})["message"] != "standup" {
+        t.Errorf("list_reminders = %v", list)
+    }
+    if other, _ := callReminderTool(t, b, handleListReminders, nil); other["count"].(float64) != 0 {
+        t.Errorf("another session sees %v", other)
+    }
+
+    if _, res := callReminderTool(t, b, handleCancelReminder, map[string]any{"id": later["id"]}); !res.IsError {
+        t.Error("another session cancelled the reminder")
+    }
+    if out, res := callReminderTool(t, a, handleCancelReminder, map[string]any{"id": later["id"]}); res.IsError || out["cancelled"] != true {
+        t.Errorf("cancel_reminder = %v", out)
+    }
+    if _, res := callReminderTool(t, a, handleCancelReminder, map[string]any{"id": later["id"]}); !res.IsError {
+        t.Error("cancelling twice should fail")
+    }
+
+    if n := reminders.dropSession("rem-a"); n != 1 {
+        t.Errorf("dropSession dropped %d reminders, want 1", n)
+    }
+}
+
+func TestReminderNotification(t *testing.T) {
+    srv := server.NewMCPServer("test", "1.0", server.WithToolCapabilities(false))
+    registerReminderTools(srv)
+    sess := fakeSession{id: "rem-notify", ch: make(chan mcp.JSONRPCNotification, 1)}
+    if err := srv.RegisterSession(context.Background(), sess); err != nil {
+        t.Fatal(err)
+    }
+    ctx := srv.WithContext(context.Background(), sess)
+    defer reminders.dropSession("rem-notify")
+
+    msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"set_reminder","arguments":{"message":"follow up","in":"50ms"}}}`
+    resp, ok := srv.HandleMessage(ctx, json.RawMessage(msg)).(mcp.JSONRPCResponse)
+    if !ok {
+        t.Fatalf("unexpected response %#v", resp)
+    }
+    select {
+    case n := <-sess.ch:
+        data, _ := n.Params.AdditionalFields["data"].(map[string]interface{})
+        if n.Method != methodNotificationMessage || n.Params.AdditionalFields["logger"] != reminderLogger ||
+            data["message"] != "follow up" || data["reminder_id"] == "" {
+            t.Errorf("notification = %+v", n)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatal("reminder was not delivered")
+    }
+    if list, _ := callReminderTool(t, ctx, handleListReminders, nil); list["count"].(float64) != 0 {
+        t.Errorf("delivered reminder still listed: %v", list)
+    }
+}
+
+func TestReminderWebhookFallback(t *testing.T) {
+    useTestScheduler(t, "s3cret")
+    got := make(chan webhookDelivery, 1)
+    hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var d webhookDelivery
+        _ = json.NewDecoder(r.Body).Decode(&d)
+        got <- d
+    }))
+    defer hook.Close()
+
+    // No MCP session to notify, so the webhook is used
+    set, res := callReminderTool(t, context.Background(), handleSetReminder, map[string]any{
+        "message": "via webhook", "in": "50ms", "webhook": hook.URL,
+    })
+    if res.IsError {
+        t.Fatalf("set_reminder: %s", extractText(t, res))
+    }
+    select {
+    case d := <-got:
+        var payload map[string]interface{}
+        _ = json.Unmarshal(d.Payload, &payload)
+        if d.ScheduleID != set["id"] || payload["message"] != "via webhook" {
+            t.Errorf("webhook delivery = %+v", d)
+        }
+    case <-time.After(2 * time.Second):
+        t.Fatal("webhook was not called")
+    }
+}
@@ -0,0 +1,226 @@
+// -*- coding: utf-8 -*-
+// tools_rotation.go - on-call rotation tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements generate_rotation, which lays out an on-call schedule
+// for a list of participants. Handoffs happen at a fixed wall-clock time in
+// a chosen timezone, so day- and week-long rotations keep their handoff time
+// across DST changes, and every handoff is shown in each participant's local
+// time.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// maxRotationShifts bounds the size of a generated schedule
+const maxRotationShifts = 366
+
+// rotationMember is one participant in a rotation
+type rotationMember struct {
+    name string
+    tz   string
+    loc  *time.Location
+}
+
+// rotationLength is either a number of calendar days or a fixed duration
+type rotationLength struct {
+    days  int
+    fixed time.Duration
+}
+
+// advance returns the handoff following t
+func (rl rotationLength) advance(t time.Time) time.Time {
+    if rl.days > 0 {
+        return t.AddDate(0, 0, rl.days)
+    }
+    return t.Add(rl.fixed)
+}
+
+// String renders the rotation length for output
+func (rl rotationLength) String() string {
+    if rl.days > 0 {
+        return fmt.Sprintf("%dd", rl.days)
+    }
+    return rl.fixed.String()
+}
+
+// parseRotationLength accepts "7d", "2w", "1 week" or a sub-day Go duration such as "12h"
+func parseRotationLength(s string) (rotationLength, error) {
+    key := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+    for _, suf := range []struct {
+        suffix string
+        days   int
+    }{{"weeks", 7}, {"week", 7}, {"w", 7}, {"days", 1}, {"day", 1}, {"d", 1}} {
+        if num, ok := strings.CutSuffix(key, suf.suffix); ok {
+            n, err := strconv.Atoi(num)
+            if err != nil || n <= 0 {
+                break
+            }
+            return rotationLength{days: n * suf.days}, nil
+        }
+    }
+    d, err := time.ParseDuration(key)
+    if err != nil || d < time.Hour {
+        return rotationLength{}, fmt.Errorf("invalid rotation length %q (use e.g. '7d', '1w' or '12h')", s)
+    }
+    if d%(24*time.Hour) == 0 {
+        return rotationLength{days: int(d / (24 * time.Hour))}, nil
+    }
+    return rotationLength{fixed: d}, nil
+}
+
+// parseRotationMembers parses "Name:Zone" pairs separated by commas
+func parseRotationMembers(s string) ([]rotationMember, error) {
+    var out []rotationMember
+    for _, part := range strings.Split(s, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        name, tz, ok := strings.Cut(part, ":")
+        name, tz = strings.TrimSpace(name), strings.TrimSpace(tz)
+        if !ok || name == "" || tz == "" {
+            return nil, fmt.Errorf("invalid participant %q (use Name:Timezone)", part)
+        }
+        loc, err := loadLocation(tz)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, rotationMember{name, loc.String(), loc})
+    }
+    if len(out) == 0 {
+        return nil, fmt.Errorf("at least one participant is required")
+    }
+    return out, nil
+}
+
+// handleGenerateRotation builds an on-call schedule
+func handleGenerateRotation(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    membersArg, err := req.RequireString("participants")
+    if err != nil {
+        return mcp.NewToolResultError("participants parameter is required"), nil
+    }
+    members, err := parseRotationMembers(membersArg)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    length, err := parseRotationLength(req.GetString("rotation_length", "7d"))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    handoffMin, err := parseClockMinutes(req.GetString("handoff_time", "09:00"))
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    handoffTz := req.GetString("handoff_timezone", "UTC")
+    handoffLoc, err := loadLocation(handoffTz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    startDate, err := req.RequireString("start_date")
+    if err != nil {
+        return mcp.NewToolResultError("start_date parameter is required"), nil
+    }
+    day, err := time.ParseInLocation("2006-01-02", startDate, handoffLoc)
+    if err != nil {
+        return mcp.NewToolResultError("start_date must be YYYY-MM-DD"), nil
+    }
+
+    shifts := req.GetInt("shifts", len(members))
+    if shifts < 1 || shifts > maxRotationShifts {
+        return mcp.NewToolResultError(fmt.Sprintf("shifts must be between 1 and %d", maxRotationShifts)), nil
+    }
+
+    start := time.Date(day.Year(), day.Month(), day.Day(), 0, handoffMin, 0, 0, handoffLoc)
+    schedule := make([]map[string]interface{}, 0, shifts)
+    for i := 0; i < shifts; i++ {
+        if err := ctx.Err(); err != nil {
+            return nil, err
+        }
+        end := length.advance(start)
+        m := members[i%len(members)]
+
+        local := make(map[string]interface{}, len(members))
+        for _, p := range members {
+            local[p.name] = map[string]interface{}{
+                "timezone": p.tz,
+                "start":    start.In(p.loc).Format(time.RFC3339),
+                "end":      end.In(p.loc).Format(time.RFC3339),
+            }
+        }
+        schedule = append(schedule, map[string]interface{}{
+            "shift":     i + 1,
+            "on_call":   m.name,
+            "timezone":  m.tz,
+            "start":     start.Format(time.RFC3339),
+            "end":       end.Format(time.RFC3339),
+            "start_utc": start.UTC().Format(time.RFC3339),
+            "end_utc":   end.UTC().Format(time.RFC3339),
+            "hours":     end.Sub(start).Hours(),
+            "local":     local,
+        })
+        start = end
+    }
+
+    result := map[string]interface{}{
+        "rotation_length":  length.String(),
+        "handoff_time":     fmt.Sprintf("%02d:%02d", handoffMin/60, handoffMin%60),
+        "handoff_timezone": handoffTz,
+        "schedule":         schedule,
+    }
+
+    addSummary(ctx, req, "on-call rotation shifts", result)
+
+    logAt(logInfo, "generate_rotation: %d participants, %d shifts of %s", len(members), shifts, length)
+    return toolResultJSON(result)
+}
+
+// registerRotationTools adds generate_rotation to the server
+func registerRotationTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("generate_rotation",
+        mcp.WithDescription("Generate an on-call rotation schedule, with every handoff expressed in each participant's local time"),
+        mcp.WithTitleAnnotation("Generate Rotation"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("participants",
+            mcp.Required(),
+            mcp.Description("Comma-separated Name:Timezone pairs in rotation order (e.g., 'Alice:America/New_York, Bob:Europe/London')"),
+        ),
+        mcp.WithString("start_date",
+            mcp.Required(),
+            mcp.Description("Date of the first handoff (YYYY-MM-DD) in the handoff timezone"),
+        ),
+        mcp.WithString("rotation_length",
+            mcp.Description("Length of each shift (e.g., '7d', '1w', '12h'). Defaults to 7d"),
+        ),
+        mcp.WithString("handoff_time",
+            mcp.Description("Wall-clock handoff time as HH:MM. Defaults to 09:00"),
+        ),
+        mcp.WithString("handoff_timezone",
+            mcp.Description("IANA timezone in which handoff_time is defined. Defaults to UTC"),
+        ),
+        mcp.WithNumber("shifts",
+            mcp.Description("Number of shifts to generate. Defaults to one per participant"),
+        ),
+        mcp.WithBoolean("summarize",
+            mcp.Description("Also ask the client's LLM (MCP sampling) for a natural-language summary. Defaults to false"),
+        ),
+    ), handleGenerateRotation)
+}
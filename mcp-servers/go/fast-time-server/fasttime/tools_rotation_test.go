@@ -0,0 +1,86 @@
+// -*- coding: utf-8 -*-
+// tools_rotation_test.go - Tests for on-call rotation tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestParseRotationLength(t *testing.T) {
+    tests := map[string]rotationLength{
+        "7d":     {days: 7},
+        "2w":     {days: 14},
+        "1 week": {days: 7},
+        "48h":    {days: 2},
+        "12h":    {fixed: 12 * time.Hour},
+    }
+    for in, want := range tests {
+        got, err := parseRotationLength(in)
+        if err != nil || got != want {
+            t.Errorf("parseRotationLength(%q) = %+v, %v; want %+v", in, got, err, want)
+        }
+    }
+    for _, bad := range []string{"", "0d", "30m", "forever"} {
+        if _, err := parseRotationLength(bad); err == nil {
+            t.Errorf("parseRotationLength(%q) should fail", bad)
+        }
+    }
+}
+
+func TestHandleGenerateRotation(t *testing.T) {
+    // Weekly rotation across the US DST change keeps the 09:00 New York handoff
+    res, err := handleGenerateRotation(context.Background(), testRequest("generate_rotation", map[string]any{
+        "participants":     "Alice:America/New_York, Bob:Europe/London, Chen:Asia/Shanghai",
+        "start_date":       "2025-03-03",
+        "handoff_timezone": "America/New_York",
+        "shifts":           float64(4),
+    }))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        Schedule []struct {
+            OnCall   string                       `json:"on_call"`
+            Start    string                       `json:"start"`
+            StartUTC string                       `json:"start_utc"`
+            Hours    float64                      `json:"hours"`
+            Local    map[string]map[string]string `json:"local"`
+        } `json:"schedule"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if len(body.Schedule) != 4 {
+        t.Fatalf("want 4 shifts, got %d", len(body.Schedule))
+    }
+    wantOnCall := []string{"Alice", "Bob", "Chen", "Alice"}
+    for i, s := range body.Schedule {
+        if s.OnCall != wantOnCall[i] {
+            t.Errorf("shift %d: want %s, got %s", i+1, wantOnCall[i], s.OnCall)
+        }
+    }
+    if body.Schedule[1].Start != "2025-03-10T09:00:00-04:00" || body.Schedule[1].StartUTC != "2025-03-10T13:00:00Z" {
+        t.Errorf("second handoff should stay at 09:00 local after DST: %+v", body.Schedule[1])
+    }
+    if body.Schedule[0].Hours != 167 {
+        t.Errorf("first shift spans spring-forward and should be 167h, got %v", body.Schedule[0].Hours)
+    }
+    if got := body.Schedule[0].Local["Chen"]["start"]; got != "2025-03-03T22:00:00+08:00" {
+        t.Errorf("want Chen's local start 2025-03-03T22:00:00+08:00, got %s", got)
+    }
+
+    res, _ = handleGenerateRotation(context.Background(), testRequest("generate_rotation", map[string]any{
+        "participants": "Alice",
+        "start_date":   "2025-03-03",
+    }))
+    if !res.IsError {
+        t.Error("expected error result for participant without timezone")
+    }
+}
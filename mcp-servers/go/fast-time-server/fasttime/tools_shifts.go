@@ -0,0 +1,307 @@
+// -*- coding: utf-8 -*-
+// tools_shifts.go - shift-work schedule tool for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements get_shift_schedule, which tells ops and on-call
+// agents which shift of a repeating pattern is active at a time and when the
+// next shifts begin. A pattern is a cycle of shifts that starts at
+// start_time on the anchor date in the given timezone and repeats forever
+// (also backwards). Patterns are written as
+//
+//   - "4-on/4-off": N working days of shift_length, then M days off
+//   - "2-shift" (2x12): Day and Night, 12 hours each
+//   - "3-shift" (3x8): Early, Late and Night, 8 hours each
+//   - "Day:12h, Night:12h, Off:48h": any cycle of named shifts; shifts named
+//     off or rest are off duty
+//
+// Shift lengths are wall-clock time, so a 07:00 handover stays at 07:00
+// across DST changes and the shift spanning a change is an hour shorter or
+// longer.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    // maxShiftCycle bounds the length of one pattern cycle in minutes
+    maxShiftCycle = 366 * 24 * 60
+    // maxUpcomingShifts caps the upcoming shifts of one call
+    maxUpcomingShifts = 100
+)
+
+// shiftSegment is one shift of a pattern cycle
+type shiftSegment struct {
+    name    string
+    minutes int // wall-clock length
+    onDuty  bool
+}
+
+// onOffPattern matches "4-on/4-off", "4on4off" or "4 on, 4 off"
+var onOffPattern = regexp.MustCompile(`^(\d+)\s*-?\s*on\s*[/,]?\s*(\d+)\s*-?\s*off$`)
+
+// parseShiftPattern expands a pattern into its cycle; shiftMinutes is the
+// working part of a day in N-on/M-off patterns
+func parseShiftPattern(s string, shiftMinutes int) ([]shiftSegment, error) {
+    key := strings.ToLower(strings.TrimSpace(s))
+    switch key {
+    case "2-shift", "2x12", "two-shift":
+        return []shiftSegment{{"Day", 12 * 60, true}, {"Night", 12 * 60, true}}, nil
+    case "3-shift", "3x8", "three-shift":
+        return []shiftSegment{{"Early", 8 * 60, true}, {"Late", 8 * 60, true}, {"Night", 8 * 60, true}}, nil
+    }
+
+    if m := onOffPattern.FindStringSubmatch(key); m != nil {
+        on, _ := strconv.Atoi(m[1])
+        off, _ := strconv.Atoi(m[2])
+        if on < 1 || off < 0 || on+off > 366 {
+            return nil, fmt.Errorf("invalid pattern %q: use 1-366 days in total", s)
+        }
+        var out []shiftSegment
+        for i := 1; i <= on; i++ {
+            out = append(out, shiftSegment{fmt.Sprintf("On (day %d of %d)", i, on), shiftMinutes, true})
+            rest := 24*60 - shiftMinutes
+            if i == on {
+                rest += off * 24 * 60
+            }
+            if rest > 0 {
+                out = append(out, shiftSegment{"Off", rest, false})
+            }
+        }
+        return out, nil
+    }
+
+    var out []shiftSegment
+    total := 0
+    for _, part := range strings.Split(s, ",") {
+        name, length, ok := strings.Cut(strings.TrimSpace(part), ":")
+        name, length = strings.TrimSpace(name), strings.TrimSpace(length)
+        if !ok || name == "" {
+            return nil, fmt.Errorf("invalid pattern %q: use e.g. '4-on/4-off', '3-shift' or 'Day:12h, Night:12h, Off:48h'", s)
+        }
+        pd, err := parseDurationFlexible(length)
+        if err != nil || pd.d < time.Minute || pd.d%time.Minute != 0 {
+            return nil, fmt.Errorf("invalid length %q for shift %s: use whole minutes, e.g. 8h or 12h30m", length, name)
+        }
+        minutes := int(pd.d / time.Minute)
+        if total += minutes; total > maxShiftCycle {
+            return nil, fmt.Errorf("invalid pattern %q: a cycle can last at most 366 days", s)
+        }
+        lower := strings.ToLower(name)
+        out = append(out, shiftSegment{name, minutes, lower != "off" && lower != "rest"})
+    }
+    return out, nil
+}
+
+// shiftCycle is a pattern anchored at a wall-clock time in a zone
+type shiftCycle struct {
+    segments []shiftSegment
+    starts   []int // minutes from the cycle start
+    length   int   // minutes
+    anchor   time.Time
+    loc      *time.Location
+}
+
+func newShiftCycle(segments []shiftSegment, anchor time.Time) *shiftCycle {
+    c := &shiftCycle{segments: segments, anchor: anchor, loc: anchor.Location()}
+    for _, seg := range segments {
+        c.starts = append(c.starts, c.length)
+        c.length += seg.minutes
+    }
+    return c
+}
+
+// at returns the instant w wall-clock minutes after the anchor
+func (c *shiftCycle) at(w int) time.Time {
+    y, m, d := c.anchor.Date()
+    return time.Date(y, m, d, c.anchor.Hour(), c.anchor.Minute()+w, 0, 0, c.loc)
+}
+
+// locate returns the cycle number and segment index active at t
+func (c *shiftCycle) locate(t time.Time) (cycle, idx int) {
+    local := t.In(c.loc)
+    wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, time.UTC).
+        Sub(time.Date(c.anchor.Year(), c.anchor.Month(), c.anchor.Day(), c.anchor.Hour(), c.anchor.Minute(), 0, 0, time.UTC))
+    w := int(wall / time.Minute)
+    cycle = w / c.length
+    if w < 0 && w%c.length != 0 {
+        cycle--
+    }
+    off := w - cycle*c.length
+    idx = len(c.starts) - 1
+    for c.starts[idx] > off {
+        idx--
+    }
+    return cycle, idx
+}
+
+// shiftInstance is one occurrence of a shift
+type shiftInstance struct {
+    Name   string  `json:"name"`
+    OnDuty bool    `json:"on_duty"`
+    Cycle  int     `json:"cycle"` // 0 is the cycle starting at the anchor
+    Index  int     `json:"index"` // position in the cycle, from 1
+    Start  string  `json:"start"`
+    End    string  `json:"end"`
+    Hours  float64 `json:"hours"`
+
+    start, end time.Time
+}
+
+// instance returns the shift idx of cycle n
+func (c *shiftCycle) instance(n, idx int) shiftInstance {
+    w := n*c.length + c.starts[idx]
+    start, end := c.at(w), c.at(w+c.segments[idx].minutes)
+    return shiftInstance{
+        Name:   c.segments[idx].name,
+        OnDuty: c.segments[idx].onDuty,
+        Cycle:  n,
+        Index:  idx + 1,
+        Start:  start.Format(time.RFC3339),
+        End:    end.Format(time.RFC3339),
+        Hours:  end.Sub(start).Hours(),
+        start:  start,
+        end:    end,
+    }
+}
+
+// next returns the shift after idx of cycle n
+func (c *shiftCycle) next(n, idx int) (int, int) {
+    if idx++; idx == len(c.segments) {
+        return n + 1, 0
+    }
+    return n, idx
+}
+
+// handleGetShiftSchedule reports the active shift and the next boundaries
+func handleGetShiftSchedule(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    pattern := req.GetString("pattern", "")
+    if strings.TrimSpace(pattern) == "" {
+        return toolError(errMissingField("pattern")), nil
+    }
+    anchorDate := req.GetString("anchor_date", "")
+    if anchorDate == "" {
+        return toolError(errMissingField("anchor_date")), nil
+    }
+    loc, err := loadLocation(req.GetString("timezone", "UTC"))
+    if err != nil {
+        return toolError(fieldError("timezone", err)), nil
+    }
+    day, err := time.ParseInLocation("2006-01-02", anchorDate, loc)
+    if err != nil {
+        return mcp.NewToolResultError("anchor_date must be YYYY-MM-DD"), nil
+    }
+    startMin, err := parseClockMinutes(req.GetString("start_time", "07:00"))
+    if err != nil || startMin == 24*60 {
+        return mcp.NewToolResultError("start_time must be HH:MM between 00:00 and 23:59"), nil
+    }
+    shiftLen := 12 * 60
+    if s := req.GetString("shift_length", ""); s != "" {
+        pd, err := parseDurationFlexible(s)
+        if err != nil || pd.d < time.Minute || pd.d > 24*time.Hour || pd.d%time.Minute != 0 {
+            return mcp.NewToolResultError(fmt.Sprintf("invalid shift_length %q: use whole minutes up to 24h, e.g. 12h", s)), nil
+        }
+        shiftLen = int(pd.d / time.Minute)
+    }
+    segments, err := parseShiftPattern(pattern, shiftLen)
+    if err != nil {
+        return toolError(fieldError("pattern", err)), nil
+    }
+    count := req.GetInt("count", 6)
+    if count < 0 || count > maxUpcomingShifts {
+        return mcp.NewToolResultError(fmt.Sprintf("count must be between 0 and %d", maxUpcomingShifts)), nil
+    }
+    t, err := timeArgIn(req, loc)
+    if err != nil {
+        return toolError(fieldError("time", err)), nil
+    }
+
+    cycle := newShiftCycle(segments, time.Date(day.Year(), day.Month(), day.Day(), 0, startMin, 0, 0, loc))
+    n, idx := cycle.locate(t)
+    active := cycle.instance(n, idx)
+    // A shift ending in a repeated DST hour may already be over
+    for !t.Before(active.end) {
+        n, idx = cycle.next(n, idx)
+        active = cycle.instance(n, idx)
+    }
+
+    upcoming := make([]shiftInstance, 0, count)
+    var nextOn *shiftInstance
+    un, ui := n, idx
+    for i := 0; i < count || (nextOn == nil && !active.OnDuty && i < len(segments)); i++ {
+        un, ui = cycle.next(un, ui)
+        s := cycle.instance(un, ui)
+        if i < count {
+            upcoming = append(upcoming, s)
+        }
+        if nextOn == nil && s.OnDuty {
+            nextOn = &s
+        }
+    }
+
+    result := map[string]interface{}{
+        "pattern":           pattern,
+        "timezone":          loc.String(),
+        "time":              t.Format(time.RFC3339),
+        "cycle_start":       cycle.at(n * cycle.length).Format(time.RFC3339),
+        "cycle_hours":       float64(cycle.length) / 60,
+        "shifts_per_cycle":  len(segments),
+        "active":            active,
+        "elapsed_seconds":   t.Sub(active.start).Seconds(),
+        "remaining_seconds": active.end.Sub(t).Seconds(),
+        "remaining":         humanizeGap(active.end.Sub(t)),
+        "upcoming":          upcoming,
+    }
+    if !active.OnDuty && nextOn != nil {
+        result["next_on_duty"] = nextOn
+    }
+
+    logAt(logInfo, "get_shift_schedule: %s active at %s (pattern %q)", active.Name, t.Format(time.RFC3339), pattern)
+    return toolResultJSON(result)
+}
+
+// registerShiftTools adds get_shift_schedule to the server
+func registerShiftTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("get_shift_schedule",
+        mcp.WithDescription("Given a shift pattern (e.g. 4-on/4-off or a 3-shift rotation), an anchor date and a timezone, return the shift active at a time and the upcoming shift boundaries"),
+        mcp.WithTitleAnnotation("Shift Schedule"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Depends on the current time when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("pattern",
+            mcp.Required(),
+            mcp.Description("Shift pattern: 'N-on/M-off' (e.g. '4-on/4-off'), '2-shift' (2x12), '3-shift' (3x8), or named shifts such as 'Day:12h, Night:12h, Off:48h' (off or rest are off duty)"),
+        ),
+        mcp.WithString("anchor_date",
+            mcp.Required(),
+            mcp.Description("Date (YYYY-MM-DD) on which a cycle of the pattern starts with its first shift"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone of the anchor date and shift times. Defaults to UTC"),
+        ),
+        mcp.WithString("start_time",
+            mcp.Description("Wall-clock time (HH:MM) at which the first shift of a cycle starts. Defaults to 07:00"),
+        ),
+        mcp.WithString("shift_length",
+            mcp.Description("Working time per day of an N-on/M-off pattern, e.g. '12h' or '24h'. Defaults to 12h"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time to look up, RFC3339 or local to timezone. Defaults to now"),
+        ),
+        mcp.WithNumber("count",
+            mcp.Description(fmt.Sprintf("Number of upcoming shifts to list (0-%d). Defaults to 6", maxUpcomingShifts)),
+        ),
+    ), handleGetShiftSchedule)
+}
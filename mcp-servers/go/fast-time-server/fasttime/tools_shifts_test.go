@@ -0,0 +1,94 @@
+// -*- coding: utf-8 -*-
+// tools_shifts_test.go - Tests for get_shift_schedule
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+)
+
+type shiftScheduleBody struct {
+    Active     shiftInstance   `json:"active"`
+    Upcoming   []shiftInstance `json:"upcoming"`
+    NextOnDuty *shiftInstance  `json:"next_on_duty"`
+    CycleHours float64         `json:"cycle_hours"`
+    Remaining  string          `json:"remaining"`
+}
+
+func shiftSchedule(t *testing.T, args map[string]any) shiftScheduleBody {
+    t.Helper()
+    res, err := handleGetShiftSchedule(context.Background(), testRequest("get_shift_schedule", args))
+    if err != nil || res.IsError {
+        t.Fatalf("get_shift_schedule(%v) = %v, %v", args, res, err)
+    }
+    var body shiftScheduleBody
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatal(err)
+    }
+    return body
+}
+
+func TestHandleGetShiftSchedule(t *testing.T) {
+    // 4-on/4-off from Monday 6 January: day 6 (Saturday) is off
+    body := shiftSchedule(t, map[string]any{
+        "pattern":     "4-on/4-off",
+        "anchor_date": "2025-01-06",
+        "timezone":    "Europe/London",
+        "time":        "2025-01-11 12:00:00",
+        "count":       float64(2),
+    })
+    if body.Active.Name != "Off" || body.Active.OnDuty || body.Active.Start != "2025-01-09T19:00:00Z" || body.Active.End != "2025-01-14T07:00:00Z" {
+        t.Errorf("active = %+v", body.Active)
+    }
+    if body.CycleHours != 192 || len(body.Upcoming) != 2 || body.Upcoming[0].Name != "On (day 1 of 4)" || body.Upcoming[0].Cycle != 1 {
+        t.Errorf("cycle = %v, upcoming = %+v", body.CycleHours, body.Upcoming)
+    }
+    if body.NextOnDuty == nil || body.NextOnDuty.Start != "2025-01-14T07:00:00Z" {
+        t.Errorf("next_on_duty = %+v", body.NextOnDuty)
+    }
+
+    // 3x8 handovers stay at 06:00, 14:00 and 22:00 across the spring change
+    body = shiftSchedule(t, map[string]any{
+        "pattern":     "3-shift",
+        "anchor_date": "2025-03-01",
+        "timezone":    "Europe/Berlin",
+        "start_time":  "06:00",
+        "time":        "2025-03-30T01:00:00Z", // 03:00 CEST
+    })
+    if body.Active.Name != "Night" || body.Active.Start != "2025-03-29T22:00:00+01:00" || body.Active.End != "2025-03-30T06:00:00+02:00" || body.Active.Hours != 7 {
+        t.Errorf("night shift = %+v", body.Active)
+    }
+    if body.Upcoming[0].Name != "Early" || body.Upcoming[1].Start != "2025-03-30T14:00:00+02:00" || body.NextOnDuty != nil {
+        t.Errorf("upcoming = %+v", body.Upcoming)
+    }
+
+    // Named shifts, looked up before the anchor
+    body = shiftSchedule(t, map[string]any{
+        "pattern":     "Day:12h, Night:12h, Off:48h",
+        "anchor_date": "2025-06-10",
+        "time":        "2025-06-08T01:00:00Z",
+    })
+    if body.Active.Name != "Night" || body.Active.Cycle != -1 || body.Active.Start != "2025-06-07T19:00:00Z" || body.Remaining != "6h" {
+        t.Errorf("before anchor = %+v, remaining %s", body.Active, body.Remaining)
+    }
+
+    for _, args := range []map[string]any{
+        {"anchor_date": "2025-01-01"},
+        {"pattern": "3-shift"},
+        {"pattern": "3-shift", "anchor_date": "01/01/2025"},
+        {"pattern": "sometimes", "anchor_date": "2025-01-01"},
+        {"pattern": "Day:0h", "anchor_date": "2025-01-01"},
+        {"pattern": "4-on/4-off", "anchor_date": "2025-01-01", "shift_length": "25h"},
+        {"pattern": "3-shift", "anchor_date": "2025-01-01", "start_time": "7am"},
+        {"pattern": "3-shift", "anchor_date": "2025-01-01", "count": float64(500)},
+    } {
+        if res, _ := handleGetShiftSchedule(context.Background(), testRequest("get_shift_schedule", args)); !res.IsError {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
@@ -0,0 +1,200 @@
+// -*- coding: utf-8 -*-
+// tools_sleep.go - sleep and wait_until tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements sleep, which pauses for a duration, and wait_until,
+// which pauses until a timestamp. Both are bounded by -max-sleep, stop early
+// when the request is cancelled, and send notifications/progress about once a
+// second when the client supplied a progress token, with progress and total
+// expressed in seconds.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// defaultMaxSleep is the default upper bound for a single wait
+const defaultMaxSleep = 5 * time.Minute
+
+// maxSleep is the longest wait accepted (set from -max-sleep)
+var maxSleep = defaultMaxSleep
+
+// methodNotificationProgress reports progress on a long-running request
+const methodNotificationProgress = "notifications/progress"
+
+// sleepProgressStep is the interval between progress notifications
+var sleepProgressStep = time.Second
+
+// sendProgress reports progress for the current request when it carries a token
+func sendProgress(ctx context.Context, req mcp.CallToolRequest, progress, total float64, message string) {
+    if req.Params.Meta == nil || req.Params.Meta.ProgressToken == nil {
+        return
+    }
+    srv := server.ServerFromContext(ctx)
+    if srv == nil {
+        return
+    }
+    err := srv.SendNotificationToClient(ctx, methodNotificationProgress, map[string]any{
+        "progressToken": req.Params.Meta.ProgressToken,
+        "progress":      progress,
+        "total":         total,
+        "message":       message,
+    })
+    if err != nil {
+        logAt(logDebug, "progress notification failed: %v", err)
+    }
+}
+
+// roundSeconds returns d in seconds rounded to milliseconds
+func roundSeconds(d time.Duration) float64 {
+    return math.Round(d.Seconds()*1000) / 1000
+}
+
+// waitFor blocks for d, reporting progress, and returns how long it waited
+func waitFor(ctx context.Context, req mcp.CallToolRequest, d time.Duration) (time.Duration, error) {
+    start := time.Now()
+    total := roundSeconds(d)
+
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    ticker := time.NewTicker(sleepProgressStep)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return time.Since(start), ctx.Err()
+        case <-timer.C:
+            sendProgress(ctx, req, total, total, "done")
+            return time.Since(start), nil
+        case <-ticker.C:
+            elapsed := time.Since(start)
+            sendProgress(ctx, req, roundSeconds(elapsed), total,
+                fmt.Sprintf("%s remaining", (d-elapsed).Round(time.Second)))
+        }
+    }
+}
+
+// checkSleepBound rejects waits that are negative or longer than maxSleep
+func checkSleepBound(d time.Duration) error {
+    if d < 0 {
+        return fmt.Errorf("duration must not be negative")
+    }
+    if d > maxSleep {
+        return fmt.Errorf("wait of %s exceeds the maximum of %s", d, maxSleep)
+    }
+    return nil
+}
+
+// handleSleep pauses for the requested duration
+func handleSleep(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    durStr, err := req.RequireString("duration")
+    if err != nil {
+        return mcp.NewToolResultError("duration parameter is required"), nil
+    }
+    pd, err := parseDurationFlexible(durStr)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    if err := checkSleepBound(pd.d); err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    started := time.Now()
+    logAt(logInfo, "sleep: %s", pd.d)
+    waited, err := waitFor(ctx, req, pd.d)
+    if err != nil {
+        logAt(logInfo, "sleep: cancelled after %s", waited.Round(time.Millisecond))
+        return nil, err
+    }
+
+    return toolResultJSON(map[string]interface{}{
+        "requested_seconds": roundSeconds(pd.d),
+        "waited_seconds":    roundSeconds(waited),
+        "started_at":        started.UTC().Format(time.RFC3339Nano),
+        "finished_at":       time.Now().UTC().Format(time.RFC3339Nano),
+    })
+}
+
+// handleWaitUntil pauses until the requested time
+func handleWaitUntil(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    targetStr, err := req.RequireString("time")
+    if err != nil {
+        return mcp.NewToolResultError("time parameter is required"), nil
+    }
+    tz := req.GetString("timezone", "UTC")
+    loc, err := loadLocation(tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    target, err := parseTimeInLocation(targetStr, loc)
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("invalid time: %v", err)), nil
+    }
+
+    started := time.Now()
+    d := target.Sub(started)
+    if d < 0 {
+        d = 0
+    }
+    if err := checkSleepBound(d); err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    logAt(logInfo, "wait_until: %s (%s)", target.Format(time.RFC3339), d.Round(time.Second))
+    waited, err := waitFor(ctx, req, d)
+    if err != nil {
+        logAt(logInfo, "wait_until: cancelled after %s", waited.Round(time.Millisecond))
+        return nil, err
+    }
+
+    return toolResultJSON(map[string]interface{}{
+        "target":         target.In(loc).Format(time.RFC3339),
+        "timezone":       loc.String(),
+        "already_passed": !target.After(started),
+        "waited_seconds": roundSeconds(waited),
+        "started_at":     started.UTC().Format(time.RFC3339Nano),
+        "finished_at":    time.Now().UTC().Format(time.RFC3339Nano),
+    })
+}
+
+// registerSleepTools adds sleep and wait_until to the server
+func registerSleepTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("sleep",
+        mcp.WithDescription(fmt.Sprintf("Pause for a duration (at most %s) before returning, reporting progress while waiting", maxSleep)),
+        mcp.WithTitleAnnotation("Sleep"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("duration",
+            mcp.Required(),
+            mcp.Description("How long to wait, e.g. '30s', '2m', 'PT1M30S' or '1 minute 30 seconds'"),
+        ),
+    ), handleSleep)
+
+    s.AddTool(mcp.NewTool("wait_until",
+        mcp.WithDescription(fmt.Sprintf("Pause until a timestamp (at most %s ahead) before returning, reporting progress while waiting", maxSleep)),
+        mcp.WithTitleAnnotation("Wait Until"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("time",
+            mcp.Required(),
+            mcp.Description("Time to wait for in RFC3339 or 'YYYY-MM-DD HH:MM:SS'. Times in the past return immediately"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone for times without an offset. Defaults to UTC"),
+        ),
+    ), handleWaitUntil)
+}
@@ -0,0 +1,124 @@
+// -*- coding: utf-8 -*-
+// tools_sleep_test.go - tests for sleep and wait_until
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestHandleSleep(t *testing.T) {
+    res, err := handleSleep(context.Background(), testRequest("sleep", map[string]any{"duration": "50ms"}))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    var out struct {
+        Requested float64 `json:"requested_seconds"`
+        Waited    float64 `json:"waited_seconds"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    if out.Requested != 0.05 || out.Waited < 0.05 {
+        t.Errorf("requested %v waited %v, want 0.05 and at least 0.05", out.Requested, out.Waited)
+    }
+}
+
+func TestHandleSleepBounds(t *testing.T) {
+    for _, d := range []string{"10m", "-1s", "soon"} {
+        res, err := handleSleep(context.Background(), testRequest("sleep", map[string]any{"duration": d}))
+        if err != nil {
+            t.Fatalf("%s: unexpected error: %v", d, err)
+        }
+        if !res.IsError {
+            t.Errorf("%s: expected tool error", d)
+        }
+    }
+}
+
+func TestHandleSleepCancelled(t *testing.T) {
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+
+    start := time.Now()
+    _, err := handleSleep(ctx, testRequest("sleep", map[string]any{"duration": "1m"}))
+    if err != context.DeadlineExceeded {
+        t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+    }
+    if time.Since(start) > time.Second {
+        t.Error("sleep did not stop when cancelled")
+    }
+}
+
+func TestHandleWaitUntil(t *testing.T) {
+    past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+    res, err := handleWaitUntil(context.Background(), testRequest("wait_until", map[string]any{"time": past}))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    var out struct {
+        AlreadyPassed bool    `json:"already_passed"`
+        Waited        float64 `json:"waited_seconds"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &out); err != nil {
+        t.Fatalf("bad JSON: %v", err)
+    }
+    if !out.AlreadyPassed || out.Waited > 0.01 {
+        t.Errorf("got %+v, want immediate return", out)
+    }
+
+    future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+    res, _ = handleWaitUntil(context.Background(), testRequest("wait_until", map[string]any{"time": future}))
+    if !res.IsError {
+        t.Error("wait beyond -max-sleep should be rejected")
+    }
+}
+
+func TestSendProgressWithoutSession(t *testing.T) {
+    // No server or session in the context: progress is silently skipped
+    req := testRequest("sleep", nil)
+    req.Params.Meta = &mcp.Meta{ProgressToken: "tok"}
+    sendProgress(context.Background(), req, 1, 2, "halfway")
+}
+
+func TestSleepReportsProgress(t *testing.T) {
+    old := sleepProgressStep
+    sleepProgressStep = 10 * time.Millisecond
+    defer func() { sleepProgressStep = old }()
+
+    srv := server.NewMCPServer("test", "1.0", server.WithToolCapabilities(false))
+    registerSleepTools(srv)
+    sess := fakeSession{id: "s-progress", ch: make(chan mcp.JSONRPCNotification, 100)}
+    ctx := srv.WithContext(context.Background(), sess)
+
+    msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"sleep","arguments":{"duration":"55ms"},"_meta":{"progressToken":"tok"}}}`
+    if resp, ok := srv.HandleMessage(ctx, json.RawMessage(msg)).(mcp.JSONRPCResponse); !ok {
+        t.Fatalf("unexpected response %#v", resp)
+    }
+
+    close(sess.ch)
+    var last mcp.JSONRPCNotification
+    n := 0
+    for note := range sess.ch {
+        if note.Method != methodNotificationProgress || note.Params.AdditionalFields["progressToken"] != "tok" {
+            t.Fatalf("unexpected notification %+v", note)
+        }
+        last = note
+        n++
+    }
+    if n < 2 {
+        t.Fatalf("got %d progress notifications, want at least 2", n)
+    }
+    if last.Params.AdditionalFields["progress"] != last.Params.AdditionalFields["total"] {
+        t.Errorf("final progress %v != total %v", last.Params.AdditionalFields["progress"], last.Params.AdditionalFields["total"])
+    }
+}
@@ -0,0 +1,280 @@
+// -*- coding: utf-8 -*-
+// tools_timer.go - stopwatch tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements timer_start, timer_lap, timer_stop and timer_status,
+// named stopwatches that let an agent measure elapsed time across several
+// tool calls. Timers belong to the MCP session that started them: two clients
+// can both use a timer called "build" without interfering, and a session's
+// timers are discarded when it disconnects.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// maxTimersPerSession bounds the number of named timers a session may hold
+const maxTimersPerSession = 100
+
+// stopwatch is one named timer
+type stopwatch struct {
+    started time.Time
+    laps    []time.Time
+    stopped time.Time // zero while running
+}
+
+// running reports whether the stopwatch has not been stopped
+func (sw *stopwatch) running() bool {
+    return sw.stopped.IsZero()
+}
+
+// elapsed returns the time measured so far
+func (sw *stopwatch) elapsed(now time.Time) time.Duration {
+    if !sw.running() {
+        now = sw.stopped
+    }
+    return now.Sub(sw.started)
+}
+
+// describe renders the stopwatch state for tool results
+func (sw *stopwatch) describe(name string, now time.Time) map[string]interface{} {
+    elapsed := sw.elapsed(now)
+    human, _ := formatDurationStyle(elapsed.Round(time.Millisecond), "short")
+
+    laps := make([]map[string]interface{}, len(sw.laps))
+    prev := sw.started
+    for i, at := range sw.laps {
+        laps[i] = map[string]interface{}{
+            "lap":             i + 1,
+            "at":              at.UTC().Format(time.RFC3339Nano),
+            "lap_seconds":     roundSeconds(at.Sub(prev)),
+            "elapsed_seconds": roundSeconds(at.Sub(sw.started)),
+        }
+        prev = at
+    }
+
+    out := map[string]interface{}{
+        "name":            name,
+        "running":         sw.running(),
+        "started_at":      sw.started.UTC().Format(time.RFC3339Nano),
+        "elapsed_seconds": roundSeconds(elapsed),
+        "elapsed":         human,
+        "laps":            laps,
+    }
+    if !sw.running() {
+        out["stopped_at"] = sw.stopped.UTC().Format(time.RFC3339Nano)
+    }
+    return out
+}
+
+// sessionTimers holds the stopwatches of every session
+type sessionTimers struct {
+    mu        sync.Mutex
+    bySession map[string]map[string]*stopwatch
+}
+
+// timers is the process-wide stopwatch store
+var timers = newSessionTimers()
+
+// newSessionTimers creates an empty store
+func newSessionTimers() *sessionTimers {
+    return &sessionTimers{bySession: make(map[string]map[string]*stopwatch)}
+}
+
+// dropSession discards every timer held by a session
+func (st *sessionTimers) dropSession(sessionID string) {
+    st.mu.Lock()
+    delete(st.bySession, sessionID)
+    st.mu.Unlock()
+}
+
+// countFor returns the number of timers held by a session
+func (st *sessionTimers) countFor(sessionID string) int {
+    st.mu.Lock()
+    defer st.mu.Unlock()
+    return len(st.bySession[sessionID])
+}
+
+// sessionIDFrom returns the MCP session id of ctx ("" when there is none)
+func sessionIDFrom(ctx context.Context) string {
+    if sess := server.ClientSessionFromContext(ctx); sess != nil {
+        return sess.SessionID()
+    }
+    return ""
+}
+
+// timerName reads and validates the name argument
+func timerName(req mcp.CallToolRequest) (string, error) {
+    name := strings.TrimSpace(req.GetString("name", ""))
+    if name == "" {
+        return "", fmt.Errorf("name parameter is required")
+    }
+    return name, nil
+}
+
+// handleTimerStart starts (or restarts a stopped) named timer
+func handleTimerStart(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    name, err := timerName(req)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    sid := sessionIDFrom(ctx)
+    now := time.Now()
+
+    timers.mu.Lock()
+    defer timers.mu.Unlock()
+    mine := timers.bySession[sid]
+    if mine == nil {
+        mine = make(map[string]*stopwatch)
+        timers.bySession[sid] = mine
+    }
+    if sw, ok := mine[name]; ok && sw.running() {
+        return mcp.NewToolResultError(fmt.Sprintf("timer %q is already running; stop it first", name)), nil
+    }
+    if _, ok := mine[name]; !ok && len(mine) >= maxTimersPerSession {
+        return mcp.NewToolResultError(fmt.Sprintf("too many timers (max %d per session)", maxTimersPerSession)), nil
+    }
+
+    sw := &stopwatch{started: now}
+    mine[name] = sw
+    logAt(logInfo, "timer_start: %s", name)
+    return toolResultJSON(sw.describe(name, now))
+}
+
+// handleTimerLap records a lap on a running timer
+func handleTimerLap(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    name, err := timerName(req)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    now := time.Now()
+
+    timers.mu.Lock()
+    defer timers.mu.Unlock()
+    sw, ok := timers.bySession[sessionIDFrom(ctx)][name]
+    if !ok {
+        return mcp.NewToolResultError(fmt.Sprintf("unknown timer %q", name)), nil
+    }
+    if !sw.running() {
+        return mcp.NewToolResultError(fmt.Sprintf("timer %q is stopped", name)), nil
+    }
+
+    sw.laps = append(sw.laps, now)
+    logAt(logInfo, "timer_lap: %s lap %d", name, len(sw.laps))
+    return toolResultJSON(sw.describe(name, now))
+}
+
+// handleTimerStop stops a running timer
+func handleTimerStop(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    name, err := timerName(req)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    now := time.Now()
+
+    timers.mu.Lock()
+    defer timers.mu.Unlock()
+    sw, ok := timers.bySession[sessionIDFrom(ctx)][name]
+    if !ok {
+        return mcp.NewToolResultError(fmt.Sprintf("unknown timer %q", name)), nil
+    }
+    if sw.running() {
+        sw.stopped = now
+    }
+
+    logAt(logInfo, "timer_stop: %s after %s", name, sw.elapsed(now).Round(time.Millisecond))
+    return toolResultJSON(sw.describe(name, now))
+}
+
+// handleTimerStatus reports one timer, or every timer of the session
+func handleTimerStatus(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    now := time.Now()
+    name := strings.TrimSpace(req.GetString("name", ""))
+
+    timers.mu.Lock()
+    defer timers.mu.Unlock()
+    mine := timers.bySession[sessionIDFrom(ctx)]
+
+    if name != "" {
+        sw, ok := mine[name]
+        if !ok {
+            return mcp.NewToolResultError(fmt.Sprintf("unknown timer %q", name)), nil
+        }
+        return toolResultJSON(sw.describe(name, now))
+    }
+
+    names := make([]string, 0, len(mine))
+    for n := range mine {
+        names = append(names, n)
+    }
+    sort.Strings(names)
+    list := make([]map[string]interface{}, len(names))
+    for i, n := range names {
+        list[i] = mine[n].describe(n, now)
+    }
+    return toolResultJSON(map[string]interface{}{
+        "timers": list,
+        "count":  len(list),
+    })
+}
+
+// registerTimerTools adds the timer_* stopwatch tools to the server
+func registerTimerTools(s *server.MCPServer) {
+    nameArg := mcp.WithString("name",
+        mcp.Required(),
+        mcp.Description("Timer name, unique within the MCP session"),
+    )
+
+    s.AddTool(mcp.NewTool("timer_start",
+        mcp.WithDescription("Start a named stopwatch for this session (restarts it if it was stopped)"),
+        mcp.WithTitleAnnotation("Start Timer"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false),
+        mcp.WithOpenWorldHintAnnotation(false),
+        nameArg,
+    ), handleTimerStart)
+
+    s.AddTool(mcp.NewTool("timer_lap",
+        mcp.WithDescription("Record a lap on a running named stopwatch and return lap and total elapsed times"),
+        mcp.WithTitleAnnotation("Timer Lap"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false),
+        mcp.WithOpenWorldHintAnnotation(false),
+        nameArg,
+    ), handleTimerLap)
+
+    s.AddTool(mcp.NewTool("timer_stop",
+        mcp.WithDescription("Stop a named stopwatch and return the total elapsed time and laps"),
+        mcp.WithTitleAnnotation("Stop Timer"),
+        mcp.WithReadOnlyHintAnnotation(false),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true), // Stopping a stopped timer changes nothing
+        mcp.WithOpenWorldHintAnnotation(false),
+        nameArg,
+    ), handleTimerStop)
+
+    s.AddTool(mcp.NewTool("timer_status",
+        mcp.WithDescription("Show a named stopwatch, or every stopwatch of this session when name is omitted"),
+        mcp.WithTitleAnnotation("Timer Status"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Running timers keep counting
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("name",
+            mcp.Description("Timer name. Omit to list all timers"),
+        ),
+    ), handleTimerStatus)
+}
@@ -0,0 +1,112 @@
+// -*- coding: utf-8 -*-
+// tools_timer_test.go - tests for the timer_* stopwatch tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// timerState is the decoded result of a timer tool
+type timerState struct {
+    Name    string  `json:"name"`
+    Running bool    `json:"running"`
+    Elapsed float64 `json:"elapsed_seconds"`
+    Laps    []struct {
+        Lap int `json:"lap"`
+    } `json:"laps"`
+}
+
+// callTimer invokes a timer handler and decodes its result
+func callTimer(t *testing.T, ctx context.Context, h server.ToolHandlerFunc, tool, name string) (timerState, *mcp.CallToolResult) {
+    t.Helper()
+    res, err := h(ctx, testRequest(tool, map[string]any{"name": name}))
+    if err != nil {
+        t.Fatalf("%s: unexpected error: %v", tool, err)
+    }
+    var st timerState
+    if !res.IsError {
+        if err := json.Unmarshal([]byte(extractText(t, res)), &st); err != nil {
+            t.Fatalf("%s: bad JSON: %v", tool, err)
+        }
+    }
+    return st, res
+}
+
+// timerContext returns a context bound to a fake MCP session
+func timerContext(sid string) context.Context {
+    return server.NewMCPServer("test", "1.0").WithContext(context.Background(), fakeSession{id: sid})
+}
+
+func TestTimerLifecycle(t *testing.T) {
+    ctx := timerContext("timer-a")
+    defer timers.dropSession("timer-a")
+
+    st, _ := callTimer(t, ctx, handleTimerStart, "timer_start", "build")
+    if !st.Running || st.Name != "build" {
+        t.Fatalf("start: %+v", st)
+    }
+    if _, res := callTimer(t, ctx, handleTimerStart, "timer_start", "build"); !res.IsError {
+        t.Error("starting a running timer should fail")
+    }
+
+    time.Sleep(5 * time.Millisecond)
+    st, _ = callTimer(t, ctx, handleTimerLap, "timer_lap", "build")
+    if len(st.Laps) != 1 || st.Elapsed <= 0 {
+        t.Errorf("lap: %+v", st)
+    }
+
+    st, _ = callTimer(t, ctx, handleTimerStop, "timer_stop", "build")
+    if st.Running {
+        t.Error("timer should be stopped")
+    }
+    stopped := st.Elapsed
+
+    time.Sleep(5 * time.Millisecond)
+    st, _ = callTimer(t, ctx, handleTimerStatus, "timer_status", "build")
+    if st.Elapsed != stopped {
+        t.Errorf("stopped timer kept counting: %v -> %v", stopped, st.Elapsed)
+    }
+    if _, res := callTimer(t, ctx, handleTimerLap, "timer_lap", "build"); !res.IsError {
+        t.Error("lap on a stopped timer should fail")
+    }
+
+    // A stopped timer can be restarted from zero
+    st, _ = callTimer(t, ctx, handleTimerStart, "timer_start", "build")
+    if !st.Running || len(st.Laps) != 0 {
+        t.Errorf("restart: %+v", st)
+    }
+}
+
+func TestTimersArePerSession(t *testing.T) {
+    a, b := timerContext("timer-s1"), timerContext("timer-s2")
+    defer timers.dropSession("timer-s1")
+    defer timers.dropSession("timer-s2")
+
+    callTimer(t, a, handleTimerStart, "timer_start", "job")
+    if _, res := callTimer(t, b, handleTimerStop, "timer_stop", "job"); !res.IsError {
+        t.Error("another session should not see the timer")
+    }
+
+    res, _ := handleTimerStatus(a, testRequest("timer_status", nil))
+    var all struct {
+        Count int `json:"count"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &all); err != nil || all.Count != 1 {
+        t.Errorf("status list = %+v (%v), want 1 timer", all, err)
+    }
+
+    timers.dropSession("timer-s1")
+    if _, res := callTimer(t, a, handleTimerStatus, "timer_status", "job"); !res.IsError {
+        t.Error("timers should be gone after the session is dropped")
+    }
+}
@@ -0,0 +1,192 @@
+// -*- coding: utf-8 -*-
+// tools_timescale.go - UTC/TAI/GPS time scale conversion for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements convert_time_scale, which converts between UTC, TAI
+// and GPS time using the IERS leap-second table, and reports GPS week and
+// seconds-of-week plus Julian Date and Modified Julian Date. TAI and GPS
+// times are expressed as calendar labels in their own scale (TAI runs 37s
+// ahead of UTC since 2017, GPS 18s).
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+    gpsMinusTAI   = -19 * time.Second // GPS time was set equal to UTC at its 1980 epoch, when TAI-UTC was 19s
+    julianUnixDay = 2440587.5         // Julian Date of the Unix epoch
+    mjdOffset     = 2400000.5         // JD - MJD
+)
+
+// gpsEpoch is the start of GPS week 0
+var gpsEpoch = time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+// leapSecond is a TAI-UTC step taking effect at a UTC instant
+type leapSecond struct {
+    effective   time.Time
+    taiMinusUTC int
+}
+
+// leapSeconds is the IERS table of TAI-UTC since the 1972 switch to leap seconds
+var leapSeconds = func() []leapSecond {
+    dates := []string{
+        "1972-01-01", "1972-07-01", "1973-01-01", "1974-01-01", "1975-01-01", "1976-01-01",
+        "1977-01-01", "1978-01-01", "1979-01-01", "1980-01-01", "1981-07-01", "1982-07-01",
+        "1983-07-01", "1985-07-01", "1988-01-01", "1990-01-01", "1991-01-01", "1992-07-01",
+        "1993-07-01", "1994-07-01", "1996-01-01", "1997-07-01", "1999-01-01", "2006-01-01",
+        "2009-01-01", "2012-07-01", "2015-07-01", "2017-01-01",
+    }
+    table := make([]leapSecond, len(dates))
+    for i, d := range dates {
+        t, _ := time.Parse("2006-01-02", d)
+        table[i] = leapSecond{t, 10 + i}
+    }
+    return table
+}()
+
+// taiMinusUTC returns TAI-UTC at a UTC instant
+func taiMinusUTC(utc time.Time) (time.Duration, error) {
+    i := sort.Search(len(leapSeconds), func(i int) bool { return leapSeconds[i].effective.After(utc) })
+    if i == 0 {
+        return 0, fmt.Errorf("leap second table starts at 1972-01-01; earlier UTC is not supported")
+    }
+    return time.Duration(leapSeconds[i-1].taiMinusUTC) * time.Second, nil
+}
+
+// scaleToUTC converts a label in the given scale to the UTC instant
+func scaleToUTC(label time.Time, scale string) (time.Time, error) {
+    var extra time.Duration
+    switch scale {
+    case "utc":
+        return label, nil
+    case "tai":
+    case "gps":
+        extra = gpsMinusTAI
+    default:
+        return time.Time{}, fmt.Errorf("unknown time scale %q (use utc, tai or gps)", scale)
+    }
+    // Fixed-point iteration: the offset depends on the UTC instant being solved for
+    utc := label
+    for i := 0; i < 3; i++ {
+        off, err := taiMinusUTC(utc)
+        if err != nil {
+            return time.Time{}, err
+        }
+        utc = label.Add(-(off + extra))
+    }
+    return utc, nil
+}
+
+// julianDate returns the Julian Date of a UTC instant
+func julianDate(t time.Time) float64 {
+    return float64(t.UnixNano())/float64(24*time.Hour) + julianUnixDay
+}
+
+// handleConvertTimeScale converts between UTC, TAI and GPS time
+func handleConvertTimeScale(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    scale := strings.ToLower(req.GetString("from_scale", "utc"))
+
+    var utc time.Time
+    var err error
+    if week := req.GetInt("gps_week", -1); week >= 0 {
+        sow := req.GetFloat("gps_seconds_of_week", 0)
+        if sow < 0 || sow >= 7*24*3600 {
+            return mcp.NewToolResultError("gps_seconds_of_week must be in [0, 604800)"), nil
+        }
+        label := gpsEpoch.Add(time.Duration(week)*7*24*time.Hour + time.Duration(sow*float64(time.Second)))
+        scale = "gps"
+        utc, err = scaleToUTC(label, scale)
+    } else {
+        var label time.Time
+        label, err = timeArgIn(req, time.UTC)
+        if err == nil {
+            if scale != "utc" && req.GetString("time", "") == "" {
+                return mcp.NewToolResultError("time is required when from_scale is not utc"), nil
+            }
+            utc, err = scaleToUTC(label.UTC(), scale)
+        }
+    }
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    leap, err := taiMinusUTC(utc)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    tai := utc.Add(leap)
+    gps := tai.Add(gpsMinusTAI)
+
+    jd := julianDate(utc)
+    result := map[string]interface{}{
+        "from_scale":       scale,
+        "utc":              utc.Format(time.RFC3339Nano),
+        "tai":              strings.TrimSuffix(tai.Format(time.RFC3339Nano), "Z"),
+        "gps":              strings.TrimSuffix(gps.Format(time.RFC3339Nano), "Z"),
+        "tai_minus_utc":    int(leap / time.Second),
+        "gps_minus_utc":    int((leap + gpsMinusTAI) / time.Second),
+        "last_leap_second": leapSeconds[len(leapSeconds)-1].effective.Format("2006-01-02"),
+        "julian_date":      jd,
+        "modified_julian":  jd - mjdOffset,
+    }
+    if !gps.Before(gpsEpoch) {
+        since := gps.Sub(gpsEpoch)
+        week := since / (7 * 24 * time.Hour)
+        result["gps_week"] = int(week)
+        result["gps_seconds_of_week"] = (since - week*7*24*time.Hour).Seconds()
+        result["gps_seconds"] = since.Seconds()
+    }
+
+    if req.GetBool("include_table", false) {
+        table := make([]map[string]interface{}, len(leapSeconds))
+        for i, ls := range leapSeconds {
+            table[i] = map[string]interface{}{
+                "effective":     ls.effective.Format("2006-01-02"),
+                "tai_minus_utc": ls.taiMinusUTC,
+            }
+        }
+        result["leap_seconds"] = table
+    }
+
+    logAt(logInfo, "convert_time_scale: %s -> utc=%s tai-utc=%d", scale, utc.Format(time.RFC3339), result["tai_minus_utc"])
+    return toolResultJSON(result)
+}
+
+// registerTimeScaleTools adds convert_time_scale to the server
+func registerTimeScaleTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("convert_time_scale",
+        mcp.WithDescription("Convert between UTC, TAI and GPS time using the leap-second table, with GPS week/seconds and Julian Date / Modified Julian Date"),
+        mcp.WithTitleAnnotation("Convert Time Scale"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("time",
+            mcp.Description("Time as a calendar label in from_scale (e.g. '2025-01-01T00:00:37' TAI). Defaults to now for UTC"),
+        ),
+        mcp.WithString("from_scale",
+            mcp.Description("Scale of the input time. Defaults to utc"),
+            mcp.Enum("utc", "tai", "gps"),
+        ),
+        mcp.WithNumber("gps_week",
+            mcp.Description("GPS week number; use with gps_seconds_of_week instead of time"),
+        ),
+        mcp.WithNumber("gps_seconds_of_week",
+            mcp.Description("Seconds into the GPS week"),
+        ),
+        mcp.WithBoolean("include_table",
+            mcp.Description("Include the full leap-second table in the result"),
+        ),
+    ), handleConvertTimeScale)
+}
@@ -0,0 +1,80 @@
+// -*- coding: utf-8 -*-
+// tools_timescale_test.go - Tests for UTC/TAI/GPS conversion
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "math"
+    "testing"
+    "time"
+)
+
+func TestTAIMinusUTC(t *testing.T) {
+    tests := []struct {
+        utc  string
+        want time.Duration
+    }{
+        {"1972-01-01T00:00:00Z", 10 * time.Second},
+        {"2016-12-31T23:59:59Z", 36 * time.Second},
+        {"2017-01-01T00:00:00Z", 37 * time.Second},
+        {"2025-06-01T00:00:00Z", 37 * time.Second},
+    }
+    for _, tt := range tests {
+        u, _ := time.Parse(time.RFC3339, tt.utc)
+        if got, err := taiMinusUTC(u); err != nil || got != tt.want {
+            t.Errorf("taiMinusUTC(%s) = %v, %v; want %v", tt.utc, got, err, tt.want)
+        }
+    }
+    if _, err := taiMinusUTC(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+        t.Error("expected error before 1972")
+    }
+}
+
+func TestHandleConvertTimeScale(t *testing.T) {
+    type body struct {
+        UTC              string  `json:"utc"`
+        TAI              string  `json:"tai"`
+        GPS              string  `json:"gps"`
+        GPSWeek          int     `json:"gps_week"`
+        GPSSecondsOfWeek float64 `json:"gps_seconds_of_week"`
+        JulianDate       float64 `json:"julian_date"`
+        ModifiedJulian   float64 `json:"modified_julian"`
+    }
+    call := func(args map[string]any) body {
+        t.Helper()
+        res, err := handleConvertTimeScale(context.Background(), testRequest("convert_time_scale", args))
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        var b body
+        if err := json.Unmarshal([]byte(extractText(t, res)), &b); err != nil {
+            t.Fatalf("result is not JSON: %v", err)
+        }
+        return b
+    }
+
+    // Noon on 2000-01-01 is JD 2451545.0
+    b := call(map[string]any{"time": "2000-01-01T12:00:00Z"})
+    if b.JulianDate != 2451545.0 || b.ModifiedJulian != 51544.5 {
+        t.Errorf("want JD 2451545.0 / MJD 51544.5, got %v / %v", b.JulianDate, b.ModifiedJulian)
+    }
+    if b.TAI != "2000-01-01T12:00:32" || b.GPS != "2000-01-01T12:00:13" {
+        t.Errorf("unexpected TAI/GPS labels %s / %s", b.TAI, b.GPS)
+    }
+
+    // TAI label round-trips back to UTC
+    if b := call(map[string]any{"time": "2025-01-01T00:00:37", "from_scale": "tai"}); b.UTC != "2025-01-01T00:00:00Z" {
+        t.Errorf("TAI 00:00:37 should be UTC midnight, got %s", b.UTC)
+    }
+
+    // GPS week/seconds input
+    b = call(map[string]any{"gps_week": float64(2347), "gps_seconds_of_week": float64(18)})
+    if b.GPSWeek != 2347 || math.Abs(b.GPSSecondsOfWeek-18) > 1e-9 || b.UTC != "2024-12-29T00:00:00Z" {
+        t.Errorf("unexpected GPS week conversion %+v", b)
+    }
+}
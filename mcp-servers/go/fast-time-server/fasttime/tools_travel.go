@@ -0,0 +1,138 @@
+// -*- coding: utf-8 -*-
+// tools_travel.go - travel time tools for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements flight_arrival_time, which adds a flight duration to
+// a local departure time and expresses the result on the arrival zone's wall
+// clock. It also reports how far the clocks moved compared to the time
+// actually spent in the air, which is where hand calculations usually go
+// wrong.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// parseFlightDuration parses a positive duration in any form parse_duration accepts
+func parseFlightDuration(s string) (time.Duration, error) {
+    pd, err := parseDurationFlexible(s)
+    if err != nil {
+        return 0, fmt.Errorf("invalid duration %q (use e.g. '14h30m' or '14:30')", s)
+    }
+    if pd.d <= 0 {
+        return 0, fmt.Errorf("duration must be positive, got %q", s)
+    }
+    return pd.d, nil
+}
+
+// wallClock returns t's local date and time reinterpreted as UTC, so that
+// subtracting two wall clocks ignores their zones
+func wallClock(t time.Time) time.Time {
+    y, mo, d := t.Date()
+    h, mi, s := t.Clock()
+    return time.Date(y, mo, d, h, mi, s, t.Nanosecond(), time.UTC)
+}
+
+// calendarDays returns the number of calendar days between the local dates of a and b
+func calendarDays(a, b time.Time) int {
+    ay, am, ad := a.Date()
+    by, bm, bd := b.Date()
+    da := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+    db := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+    return int(db.Sub(da).Hours() / 24)
+}
+
+// handleFlightArrivalTime computes local arrival time from a local departure and a flight duration
+func handleFlightArrivalTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    depTimeStr, err := req.RequireString("departure_time")
+    if err != nil {
+        return mcp.NewToolResultError("departure_time parameter is required"), nil
+    }
+    depTz, err := req.RequireString("departure_timezone")
+    if err != nil {
+        return mcp.NewToolResultError("departure_timezone parameter is required"), nil
+    }
+    arrTz, err := req.RequireString("arrival_timezone")
+    if err != nil {
+        return mcp.NewToolResultError("arrival_timezone parameter is required"), nil
+    }
+    durStr, err := req.RequireString("duration")
+    if err != nil {
+        return mcp.NewToolResultError("duration parameter is required"), nil
+    }
+
+    depLoc, err := loadLocation(depTz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    arrLoc, err := loadLocation(arrTz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    dur, err := parseFlightDuration(durStr)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    dep, err := parseTimeInLocation(depTimeStr, depLoc)
+    if err != nil {
+        return mcp.NewToolResultError(fmt.Sprintf("invalid time format: %v", err)), nil
+    }
+    dep = dep.In(depLoc)
+
+    arr := dep.Add(dur).In(arrLoc)
+    wallDiff := wallClock(arr).Sub(wallClock(dep))
+    _, depOffset := dep.Zone()
+    _, arrOffset := arr.Zone()
+
+    result := map[string]interface{}{
+        "departure_time":        dep.Format(time.RFC3339),
+        "departure_timezone":    depTz,
+        "arrival_time":          arr.Format(time.RFC3339),
+        "arrival_timezone":      arrTz,
+        "arrival_utc":           arr.UTC().Format(time.RFC3339),
+        "flight_duration":       dur.String(),
+        "wall_clock_difference": wallDiff.String(),
+        "offset_change":         (time.Duration(arrOffset-depOffset) * time.Second).String(),
+        "day_change":            calendarDays(dep, arr),
+        "arrival_weekday":       arr.Weekday().String(),
+    }
+
+    logAt(logInfo, "flight_arrival_time: %s %s + %s -> %s %s", dep.Format(time.RFC3339), depTz, dur, arr.Format(time.RFC3339), arrTz)
+    return toolResultJSON(result)
+}
+
+// registerTravelTools adds flight_arrival_time to the server
+func registerTravelTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("flight_arrival_time",
+        mcp.WithDescription("Compute the local arrival time of a flight from its local departure time, departure timezone, duration and arrival timezone"),
+        mcp.WithTitleAnnotation("Flight Arrival Time"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(true),
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("departure_time",
+            mcp.Required(),
+            mcp.Description("Local departure time (e.g., '2025-03-08 22:30:00'); an explicit offset is also accepted"),
+        ),
+        mcp.WithString("departure_timezone",
+            mcp.Required(),
+            mcp.Description("IANA timezone of the departure airport"),
+        ),
+        mcp.WithString("duration",
+            mcp.Required(),
+            mcp.Description("Flight duration (e.g., '14h30m' or '14:30')"),
+        ),
+        mcp.WithString("arrival_timezone",
+            mcp.Required(),
+            mcp.Description("IANA timezone of the arrival airport"),
+        ),
+    ), handleFlightArrivalTime)
+}
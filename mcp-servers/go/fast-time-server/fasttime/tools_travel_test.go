@@ -0,0 +1,70 @@
+// -*- coding: utf-8 -*-
+// tools_travel_test.go - Tests for travel time tools
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestParseFlightDuration(t *testing.T) {
+    tests := map[string]time.Duration{
+        "14h30m": 14*time.Hour + 30*time.Minute,
+        "14:30":  14*time.Hour + 30*time.Minute,
+        "2h 5m":  2*time.Hour + 5*time.Minute,
+        "0:45":   45 * time.Minute,
+    }
+    for in, want := range tests {
+        got, err := parseFlightDuration(in)
+        if err != nil || got != want {
+            t.Errorf("parseFlightDuration(%q) = %v, %v; want %v", in, got, err, want)
+        }
+    }
+    for _, bad := range []string{"", "soon", "3:75", "-2h"} {
+        if _, err := parseFlightDuration(bad); err == nil {
+            t.Errorf("parseFlightDuration(%q) should fail", bad)
+        }
+    }
+}
+
+func TestHandleFlightArrivalTime(t *testing.T) {
+    // SFO -> SIN: 17h in the air, clocks jump 16h forward
+    res, err := handleFlightArrivalTime(context.Background(), testRequest("flight_arrival_time", map[string]any{
+        "departure_time":     "2025-07-10 23:00:00",
+        "departure_timezone": "America/Los_Angeles",
+        "duration":           "17h",
+        "arrival_timezone":   "Asia/Singapore",
+    }))
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body struct {
+        ArrivalTime string `json:"arrival_time"`
+        WallDiff    string `json:"wall_clock_difference"`
+        DayChange   int    `json:"day_change"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if body.ArrivalTime != "2025-07-12T07:00:00+08:00" {
+        t.Errorf("want arrival 2025-07-12T07:00:00+08:00, got %s", body.ArrivalTime)
+    }
+    if body.WallDiff != "32h0m0s" || body.DayChange != 2 {
+        t.Errorf("want 32h wall clock and +2 days, got %s and %d", body.WallDiff, body.DayChange)
+    }
+
+    res, _ = handleFlightArrivalTime(context.Background(), testRequest("flight_arrival_time", map[string]any{
+        "departure_time":     "2025-07-10 23:00:00",
+        "departure_timezone": "America/Los_Angeles",
+        "duration":           "17h",
+    }))
+    if !res.IsError {
+        t.Error("expected error result without arrival_timezone")
+    }
+}
@@ -0,0 +1,209 @@
+// -*- coding: utf-8 -*-
+// tools_world.go - world clock tool for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements get_world_times, the parameterized form of the
+// time://current/world resource: the caller names any cities or zones and
+// gets each local time, its UTC offset and how its calendar day relates to
+// the reference zone ("already tomorrow in Tokyo").
+//
+// A location is looked up as a world-clock city ("Hong Kong", or any city
+// of the -cities file and its aliases), a city
+// without a zone of its own ("Mumbai"), any zone name loadLocation accepts
+// (IANA, alias, Windows name, UTC offset), and finally as the city part of
+// a zone ("Berlin" for Europe/Berlin). Case, spaces, '_' and '-' are
+// ignored for city names.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// maxWorldTimes caps the locations of one get_world_times call
+const maxWorldTimes = 50
+
+// worldTime is the local time at one requested location
+type worldTime struct {
+    Location     string  `json:"location"` // as requested
+    City         string  `json:"city,omitempty"`
+    Timezone     string  `json:"timezone"`
+    Time         string  `json:"time"`
+    Display      string  `json:"display"`
+    Weekday      string  `json:"weekday"`
+    Abbreviation string  `json:"abbreviation"`
+    UTCOffset    string  `json:"utc_offset"`
+    IsDST        bool    `json:"is_dst"`
+    HoursAhead   float64 `json:"hours_ahead"`  // of the reference zone
+    DayDiff      int     `json:"day_diff"`     // calendar days ahead of the reference date
+    RelativeDay  string  `json:"relative_day"` // yesterday, today or tomorrow
+}
+
+// resolvePlace maps a city or zone name to its location; city is set when
+// the name was found as a city
+func resolvePlace(name string) (city string, loc *time.Location, err error) {
+    key := tzKey(name)
+    if c, tz, ok := lookupCity(name); ok {
+        loc, err = loadLocation(tz)
+        return c, loc, err
+    }
+    if tz, ok := cityZones[key]; ok {
+        loc, err = loadLocation(tz)
+        return name, loc, err
+    }
+    loc, err = loadLocation(name)
+    if err == nil || strings.Contains(name, "/") {
+        return "", loc, err
+    }
+    var zones []string
+    for cand, zone := range zoneCandidates() {
+        if strings.Contains(cand, "/") && tzKey(tzCity(cand)) == key {
+            zones = append(zones, zone)
+        }
+    }
+    if len(zones) > 0 {
+        sort.Strings(zones) // deterministic when a city name is ambiguous
+        l, lerr := loadLocation(zones[0])
+        if lerr == nil {
+            return strings.ReplaceAll(tzCity(zones[0]), "_", " "), l, nil
+        }
+    }
+    return "", nil, err
+}
+
+// relativeDay names a difference in calendar days
+func relativeDay(diff int) string {
+    switch {
+    case diff == 0:
+        return "today"
+    case diff == 1:
+        return "tomorrow"
+    case diff == -1:
+        return "yesterday"
+    case diff > 0:
+        return fmt.Sprintf("%d days ahead", diff)
+    default:
+        return fmt.Sprintf("%d days behind", -diff)
+    }
+}
+
+// worldTimeAt describes t at loc relative to the reference time ref
+func worldTimeAt(t time.Time, loc *time.Location, ref time.Time) worldTime {
+    local := t.In(loc)
+    abbr, off := local.Zone()
+    _, refOff := ref.Zone()
+    ly, lm, ld := local.Date()
+    ry, rm, rd := ref.Date()
+    diff := int(time.Date(ly, lm, ld, 0, 0, 0, 0, time.UTC).Sub(time.Date(ry, rm, rd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+    return worldTime{
+        Timezone:     loc.String(),
+        Time:         local.Format(time.RFC3339),
+        Display:      local.Format("2006-01-02 15:04:05 MST"),
+        Weekday:      local.Weekday().String(),
+        Abbreviation: abbr,
+        UTCOffset:    formatOffset(off),
+        IsDST:        local.IsDST(),
+        HoursAhead:   float64(off-refOff) / 3600,
+        DayDiff:      diff,
+        RelativeDay:  relativeDay(diff),
+    }
+}
+
+// locationsArg reads a list argument given as an array or a comma-separated string
+func locationsArg(req mcp.CallToolRequest, key string) []string {
+    var out []string
+    switch v := req.GetArguments()[key].(type) {
+    case []any:
+        for _, item := range v {
+            if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+                out = append(out, strings.TrimSpace(s))
+            }
+        }
+    case string:
+        for _, s := range strings.Split(v, ",") {
+            if s = strings.TrimSpace(s); s != "" {
+                out = append(out, s)
+            }
+        }
+    }
+    return out
+}
+
+// handleGetWorldTimes returns the local time at each requested location
+func handleGetWorldTimes(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    names := locationsArg(req, "locations")
+    if len(names) == 0 {
+        return toolError(errMissingField("locations")), nil
+    }
+    if len(names) > maxWorldTimes {
+        return mcp.NewToolResultError(fmt.Sprintf("at most %d locations can be listed", maxWorldTimes)), nil
+    }
+
+    refLoc, err := loadLocation(req.GetString("reference_timezone", "UTC"))
+    if err != nil {
+        return toolError(fieldError("reference_timezone", err)), nil
+    }
+    t, err := timeArgIn(req, refLoc)
+    if err != nil {
+        return toolError(fieldError("time", err)), nil
+    }
+    ref := t.In(refLoc)
+
+    times := make([]worldTime, 0, len(names))
+    for i, name := range names {
+        city, loc, err := resolvePlace(name)
+        if err != nil {
+            return toolError(fieldError(fmt.Sprintf("locations[%d]", i), err)), nil
+        }
+        wt := worldTimeAt(t, loc, ref)
+        wt.Location, wt.City = name, city
+        times = append(times, wt)
+    }
+
+    result := map[string]interface{}{
+        "reference": map[string]interface{}{
+            "timezone": refLoc.String(),
+            "time":     ref.Format(time.RFC3339),
+            "weekday":  ref.Weekday().String(),
+        },
+        "utc":   t.UTC().Format(time.RFC3339),
+        "times": times,
+    }
+
+    logAt(logInfo, "get_world_times: %d location(s) relative to %s", len(times), refLoc)
+    return toolResultJSON(result)
+}
+
+// registerWorldTools adds get_world_times to the server
+func registerWorldTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("get_world_times",
+        mcp.WithDescription("Get the current (or a given) time in any list of cities or timezones, with UTC offsets and whether each is already tomorrow or still yesterday relative to a reference timezone"),
+        mcp.WithTitleAnnotation("World Times"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Depends on the current time when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithArray("locations",
+            mcp.Required(),
+            mcp.Description("City names (e.g. 'Tokyo', 'Berlin') or timezones (e.g. 'America/New_York', 'UTC+5:30'); a comma-separated string also works"),
+            mcp.WithStringItems(),
+            mcp.MinItems(1),
+            mcp.MaxItems(maxWorldTimes),
+        ),
+        mcp.WithString("reference_timezone",
+            mcp.Description("Timezone the offsets and day differences are relative to. Defaults to UTC"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Instant to show, RFC3339 or a local time in reference_timezone. Defaults to now"),
+        ),
+    ), handleGetWorldTimes)
+}
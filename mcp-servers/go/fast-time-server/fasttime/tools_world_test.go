@@ -0,0 +1,82 @@
+// -*- coding: utf-8 -*-
+// tools_world_test.go - Tests for get_world_times
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+)
+
+func TestResolvePlace(t *testing.T) {
+    tests := []struct {
+        name, city, zone string
+    }{
+        {"hong kong", "Hong Kong", "Asia/Hong_Kong"},
+        {"Bombay", "Bombay", "Asia/Kolkata"},
+        {"Europe/Paris", "", "Europe/Paris"},
+        {"UTC+5:30", "", "UTC+05:30"},
+        {"Berlin", "Berlin", "Europe/Berlin"},
+        {"buenos aires", "Buenos Aires", "America/Argentina/Buenos_Aires"},
+    }
+    for _, tt := range tests {
+        city, loc, err := resolvePlace(tt.name)
+        if err != nil {
+            t.Errorf("%s: %v", tt.name, err)
+            continue
+        }
+        if city != tt.city || loc.String() != tt.zone {
+            t.Errorf("%s = %q %s, want %q %s", tt.name, city, loc, tt.city, tt.zone)
+        }
+    }
+    if _, _, err := resolvePlace("Atlantis"); err == nil {
+        t.Error("Atlantis resolved")
+    }
+}
+
+func TestHandleGetWorldTimes(t *testing.T) {
+    res, err := handleGetWorldTimes(context.Background(), testRequest("get_world_times", map[string]any{
+        "locations":          []any{"Tokyo", "America/Los_Angeles", "London"},
+        "reference_timezone": "Europe/London",
+        "time":               "2025-01-15T20:00:00Z",
+    }))
+    if err != nil || res.IsError {
+        t.Fatalf("get_world_times = %v, %v", res, err)
+    }
+    var body struct {
+        Times []worldTime `json:"times"`
+    }
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+        t.Fatal(err)
+    }
+    want := []struct {
+        time, day string
+        ahead     float64
+    }{
+        {"2025-01-16T05:00:00+09:00", "tomorrow", 9},
+        {"2025-01-15T12:00:00-08:00", "today", -8},
+        {"2025-01-15T20:00:00Z", "today", 0},
+    }
+    for i, w := range want {
+        got := body.Times[i]
+        if got.Time != w.time || got.RelativeDay != w.day || got.HoursAhead != w.ahead {
+            t.Errorf("%s = %+v", got.Location, got)
+        }
+    }
+
+    // A comma-separated string works too
+    res, _ = handleGetWorldTimes(context.Background(), testRequest("get_world_times", map[string]any{"locations": "Sydney, Dubai"}))
+    if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil || len(body.Times) != 2 {
+        t.Errorf("string locations: %s", extractText(t, res))
+    }
+
+    for _, args := range []map[string]any{{}, {"locations": []any{"Atlantis"}}, {"locations": "Tokyo", "reference_timezone": "Mars/Base"}} {
+        if res, _ := handleGetWorldTimes(context.Background(), testRequest("get_world_times", args)); !res.IsError {
+            t.Errorf("%v accepted", args)
+        }
+    }
+}
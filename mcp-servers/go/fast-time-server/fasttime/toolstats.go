@@ -0,0 +1,160 @@
+// -*- coding: utf-8 -*-
+// toolstats.go - per-tool call statistics
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file counts tool calls, failures and cancellations and keeps latency
+// totals for every tool. The counters are collected by a tool handler
+// middleware and reported by GET /admin/stats/tools; failures also go to
+// the dashboard's recent error list. Timeouts and recovered panics are
+// counted by toolguard.go.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// toolStat holds the counters of one tool
+type toolStat struct {
+    calls     int64
+    errors    int64
+    cancelled int64
+    timeouts  int64
+    panics    int64
+    total     time.Duration
+    max       time.Duration
+    last      time.Time
+}
+
+// toolStatsRegistry holds the counters of every tool that has been called
+type toolStatsRegistry struct {
+    mu     sync.Mutex
+    byTool map[string]*toolStat
+}
+
+// toolStats is the process-wide tool statistics registry
+var toolStats = newToolStatsRegistry()
+
+// newToolStatsRegistry creates an empty registry
+func newToolStatsRegistry() *toolStatsRegistry {
+    return &toolStatsRegistry{byTool: make(map[string]*toolStat)}
+}
+
+// stat returns the counters of tool, creating them; ts.mu is held
+func (ts *toolStatsRegistry) stat(tool string) *toolStat {
+    st := ts.byTool[tool]
+    if st == nil {
+        st = &toolStat{}
+        ts.byTool[tool] = st
+    }
+    return st
+}
+
+// record adds one finished call
+func (ts *toolStatsRegistry) record(tool string, start time.Time, dur time.Duration, failed, cancelled bool) {
+    ts.mu.Lock()
+    defer ts.mu.Unlock()
+    st := ts.stat(tool)
+    st.calls++
+    if failed {
+        st.errors++
+    }
+    if cancelled {
+        st.cancelled++
+    }
+    st.total += dur
+    if dur > st.max {
+        st.max = dur
+    }
+    st.last = start
+}
+
+// addTimeout counts a call of tool that ran past -tool-timeout
+func (ts *toolStatsRegistry) addTimeout(tool string) {
+    ts.mu.Lock()
+    ts.stat(tool).timeouts++
+    ts.mu.Unlock()
+}
+
+// addPanic counts a call of tool whose handler panicked
+func (ts *toolStatsRegistry) addPanic(tool string) {
+    ts.mu.Lock()
+    ts.stat(tool).panics++
+    ts.mu.Unlock()
+}
+
+// snapshot returns the counters of every tool, sorted by name
+func (ts *toolStatsRegistry) snapshot() []map[string]interface{} {
+    ts.mu.Lock()
+    defer ts.mu.Unlock()
+    names := make([]string, 0, len(ts.byTool))
+    for name := range ts.byTool {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    out := make([]map[string]interface{}, 0, len(names))
+    for _, name := range names {
+        st := ts.byTool[name]
+        var avg float64
+        if st.calls > 0 { // a timeout or panic is counted just before its call
+            avg = float64(st.total.Microseconds()) / float64(st.calls) / 1000
+        }
+        out = append(out, map[string]interface{}{
+            "tool":           name,
+            "calls":          st.calls,
+            "errors":         st.errors,
+            "cancelled":      st.cancelled,
+            "timeouts":       st.timeouts,
+            "panics":         st.panics,
+            "avg_ms":         avg,
+            "max_ms":         float64(st.max.Microseconds()) / 1000,
+            "last_called_at": st.last.UTC().Format(time.RFC3339),
+        })
+    }
+    return out
+}
+
+// reset clears all counters
+func (ts *toolStatsRegistry) reset() {
+    ts.mu.Lock()
+    ts.byTool = make(map[string]*toolStat)
+    ts.mu.Unlock()
+}
+
+// toolStatsMiddleware records the outcome and latency of every tool call
+func toolStatsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        start := time.Now()
+        res, err := next(ctx, req)
+        cancelled := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+        failed := !cancelled && (err != nil || (res != nil && res.IsError))
+        toolStats.record(req.Params.Name, start, time.Since(start), failed, cancelled)
+        if failed {
+            recentErrors.add("tool", req.Params.Name+": "+toolErrorText(res, err))
+        }
+        return res, err
+    }
+}
+
+// toolErrorText extracts the message of a failed tool call
+func toolErrorText(res *mcp.CallToolResult, err error) string {
+    if err != nil {
+        return err.Error()
+    }
+    for _, c := range res.Content {
+        if tc, ok := c.(mcp.TextContent); ok {
+            return tc.Text
+        }
+    }
+    return "tool returned an error"
+}
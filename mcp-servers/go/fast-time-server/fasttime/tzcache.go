@@ -0,0 +1,164 @@
+// -*- coding: utf-8 -*-
+// tzcache.go - bounded cache of loaded timezones
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// loadLocation keeps every name it resolves, valid or not, in a
+// least-recently-used cache of -tz-cache-size entries, so repeated lookups
+// skip parsing zoneinfo and computing "did you mean" suggestions while a
+// client probing with endless garbage names only ever evicts old entries.
+// Hits, misses and evictions are reported in /debug/vars; GET
+// /admin/tzcache shows them and DELETE /admin/tzcache empties the cache.
+
+package fasttime
+
+import (
+    "container/list"
+    "net/http"
+    "sync"
+    "time"
+)
+
+// defaultTzCacheSize is the number of names kept by default
+const defaultTzCacheSize = 1024
+
+// tzCacheEntry is a loaded location, or the error of a name that does not
+// load, and the tzdata bundle it came from
+type tzCacheEntry struct {
+    loc    *time.Location
+    err    error
+    zone   string    // IANA zone; empty for fixed UTC offsets and errors
+    bundle *tzBundle // nil for the system database
+}
+
+// tzLRU is a least-recently-used cache of tzCacheEntry by name
+type tzLRU struct {
+    mu    sync.Mutex
+    max   int
+    items map[string]*list.Element
+    order *list.List // of *tzCacheItem, most recently used first
+
+    hits, misses, evictions int64
+}
+
+type tzCacheItem struct {
+    name  string
+    entry tzCacheEntry
+}
+
+// tzCache is the process-wide cache used by loadLocation
+var tzCache = newTzLRU(defaultTzCacheSize)
+
+// newTzLRU returns a cache of at most max names; 0 disables caching
+func newTzLRU(max int) *tzLRU {
+    return &tzLRU{max: max, items: make(map[string]*list.Element), order: list.New()}
+}
+
+// get returns the entry for name if it came from bundle
+func (c *tzLRU) get(name string, bundle *tzBundle) (tzCacheEntry, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    el, ok := c.items[name]
+    if !ok || el.Value.(*tzCacheItem).entry.bundle != bundle {
+        c.misses++
+        return tzCacheEntry{}, false
+    }
+    c.hits++
+    c.order.MoveToFront(el)
+    return el.Value.(*tzCacheItem).entry, true
+}
+
+// put stores the entry for name, evicting the least recently used names
+func (c *tzLRU) put(name string, e tzCacheEntry) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if el, ok := c.items[name]; ok {
+        el.Value.(*tzCacheItem).entry = e
+        c.order.MoveToFront(el)
+        return
+    }
+    if c.max <= 0 {
+        return
+    }
+    c.items[name] = c.order.PushFront(&tzCacheItem{name: name, entry: e})
+    c.trim()
+}
+
+// trim evicts entries beyond max; c.mu must be held
+func (c *tzLRU) trim() {
+    for c.order.Len() > c.max {
+        el := c.order.Back()
+        c.order.Remove(el)
+        delete(c.items, el.Value.(*tzCacheItem).name)
+        c.evictions++
+    }
+}
+
+// resize changes the capacity, evicting entries beyond it
+func (c *tzLRU) resize(max int) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.max = max
+    c.trim()
+}
+
+// purge empties the cache and returns the number of entries dropped
+func (c *tzLRU) purge() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    n := c.order.Len()
+    c.items = make(map[string]*list.Element)
+    c.order.Init()
+    return n
+}
+
+// zones returns the IANA zones of the cached locations
+func (c *tzLRU) zones() []string {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    var out []string
+    for el := c.order.Front(); el != nil; el = el.Next() {
+        if z := el.Value.(*tzCacheItem).entry.zone; z != "" {
+            out = append(out, z)
+        }
+    }
+    return out
+}
+
+// stats reports the cache for /debug/vars and /admin/tzcache
+func (c *tzLRU) stats() map[string]interface{} {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    ratio := 0.0
+    if total := c.hits + c.misses; total > 0 {
+        ratio = float64(c.hits) / float64(total)
+    }
+    return map[string]interface{}{
+        "size":      c.order.Len(),
+        "capacity":  c.max,
+        "hits":      c.hits,
+        "misses":    c.misses,
+        "evictions": c.evictions,
+        "hit_ratio": ratio,
+    }
+}
+
+// invalidateTzCache drops every cached location
+func invalidateTzCache() int {
+    return tzCache.purge()
+}
+
+// handleAdminTzCache handles GET and DELETE /admin/tzcache
+func handleAdminTzCache(w http.ResponseWriter, r *http.Request) {
+    switch r.Method {
+    case http.MethodGet:
+        writeJSON(w, http.StatusOK, tzCache.stats())
+    case http.MethodDelete:
+        n := invalidateTzCache()
+        logAt(logInfo, "admin: dropped %d cached timezone(s)", n)
+        writeJSON(w, http.StatusOK, map[string]interface{}{"dropped": n})
+    default:
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+    }
+}
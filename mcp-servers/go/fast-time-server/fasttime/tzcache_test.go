@@ -0,0 +1,79 @@
+// -*- coding: utf-8 -*-
+// tzcache_test.go - tests for the bounded timezone cache
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestTzLRU(t *testing.T) {
+    c := newTzLRU(2)
+    c.put("a", tzCacheEntry{loc: time.UTC, zone: "UTC"})
+    c.put("b", tzCacheEntry{loc: time.UTC})
+    if _, ok := c.get("a", nil); !ok {
+        t.Fatal("a missing")
+    }
+    c.put("c", tzCacheEntry{loc: time.UTC})
+    if _, ok := c.get("b", nil); ok {
+        t.Error("least recently used entry kept")
+    }
+    if _, ok := c.get("a", &tzBundle{}); ok {
+        t.Error("entry from another bundle served")
+    }
+    stats := c.stats()
+    if stats["size"] != 2 || stats["hits"] != int64(1) || stats["misses"] != int64(2) || stats["evictions"] != int64(1) {
+        t.Errorf("stats = %v", stats)
+    }
+    if n := c.purge(); n != 2 || c.order.Len() != 0 {
+        t.Errorf("purge = %d", n)
+    }
+
+    c.resize(0)
+    c.put("a", tzCacheEntry{loc: time.UTC})
+    if _, ok := c.get("a", nil); ok {
+        t.Error("disabled cache stored an entry")
+    }
+}
+
+func TestLoadLocationBoundedCache(t *testing.T) {
+    old := tzCache
+    defer func() { tzCache = old }()
+    tzCache = newTzLRU(8)
+
+    for i := 0; i < 50; i++ {
+        if _, err := loadLocation(fmt.Sprintf("Garbage/Zone%d", i)); err == nil {
+            t.Fatal("garbage zone loaded")
+        }
+    }
+    if n := tzCache.order.Len(); n != 8 {
+        t.Errorf("cache holds %d entries, want 8", n)
+    }
+
+    // Errors are served from the cache too
+    _, first := loadLocation("Garbage/Zone49")
+    _, second := loadLocation("Garbage/Zone49")
+    if first == nil || first != second {
+        t.Errorf("cached error = %v, %v", first, second)
+    }
+
+    rec := httptest.NewRecorder()
+    handleAdminTzCache(rec, httptest.NewRequest(http.MethodDelete, "/admin/tzcache", nil))
+    var body map[string]int
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["dropped"] != 8 {
+        t.Errorf("DELETE = %d %s", rec.Code, rec.Body)
+    }
+    rec = httptest.NewRecorder()
+    handleAdminTzCache(rec, httptest.NewRequest(http.MethodPost, "/admin/tzcache", nil))
+    if rec.Code != http.StatusMethodNotAllowed {
+        t.Errorf("POST = %d", rec.Code)
+    }
+}
@@ -0,0 +1,218 @@
+// -*- coding: utf-8 -*-
+// tzdata.go - hot-reloadable timezone database from -tzdata-dir
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// With -tzdata-dir the server loads zones from a zoneinfo directory of its
+// own (a compiled tzdata release with a +VERSION file or tzdata.zi header)
+// instead of the system database, so rule changes can ship without a new
+// image or restart. Every -tzdata-check-interval, and on POST
+// /admin/tzdata/reload, the release in the directory is compared with the
+// one in use. A different release is checked (UTC must load and no zone in
+// use may be corrupt) and then swapped in at once: locations cached
+// from the old release are never served again, and "did you mean" names
+// are re-read. Zones missing from the directory still come from the
+// system database.
+//
+// The release in use is reported by /version and /debug/vars.
+
+package fasttime
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io/fs"
+    "net/http"
+    "os"
+    "path/filepath"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// defaultTzdataCheckInterval is how often -tzdata-dir is checked for a new release
+const defaultTzdataCheckInterval = time.Hour
+
+// tzBundle is a zoneinfo directory and the release it held when loaded
+type tzBundle struct {
+    Dir      string    `json:"dir"`
+    Version  string    `json:"version"`
+    LoadedAt time.Time `json:"loaded_at"`
+}
+
+// tzdataSource tracks the -tzdata-dir bundle in use
+type tzdataSource struct {
+    mu      sync.Mutex // serializes reloads
+    dir     string
+    bundle  atomic.Pointer[tzBundle]
+    checks  atomic.Int64
+    reloads atomic.Int64
+    failed  atomic.Int64
+    lastErr atomic.Pointer[string]
+}
+
+// tzdata is the process-wide source; without -tzdata-dir it has no bundle
+var tzdata = &tzdataSource{}
+
+// active returns the bundle in use, or nil for the system database
+func (t *tzdataSource) active() *tzBundle {
+    return t.bundle.Load()
+}
+
+// use loads the release in dir and makes it the bundle in use
+func (t *tzdataSource) use(dir string) error {
+    t.mu.Lock()
+    t.dir = dir
+    t.mu.Unlock()
+    _, err := t.reload()
+    return err
+}
+
+// reload swaps in the release now in the directory if it differs from the
+// one in use; it reports whether a new release was loaded
+func (t *tzdataSource) reload() (bool, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.dir == "" {
+        return false, errors.New("no -tzdata-dir configured")
+    }
+    t.checks.Add(1)
+    changed, err := t.reloadLocked()
+    if err != nil {
+        t.failed.Add(1)
+        msg := err.Error()
+        t.lastErr.Store(&msg)
+        return false, err
+    }
+    t.lastErr.Store(nil)
+    return changed, nil
+}
+
+func (t *tzdataSource) reloadLocked() (bool, error) {
+    version := readTzdataVersion(t.dir)
+    if version == "" {
+        return false, fmt.Errorf("%s: no +VERSION or tzdata.zi release found", t.dir)
+    }
+    old := t.bundle.Load()
+    if old != nil && old.Version == version {
+        return false, nil
+    }
+
+    // UTC must load from the new release, and zones in use must not be
+    // corrupt in it, before it is swapped in
+    next := &tzBundle{Dir: t.dir, Version: version, LoadedAt: time.Now()}
+    if _, err := next.load("UTC"); err != nil {
+        return false, fmt.Errorf("tzdata %s in %s: %w", version, t.dir, err)
+    }
+    for _, zone := range tzCache.zones() {
+        if _, err := next.load(zone); err != nil && !errors.Is(err, fs.ErrNotExist) {
+            return false, fmt.Errorf("tzdata %s in %s: %s: %w", version, t.dir, zone, err)
+        }
+    }
+
+    t.bundle.Store(next)
+    t.reloads.Add(1)
+    invalidateTzCache()
+    resetZoneCandidates()
+    resetZoneCountries()
+    if old == nil {
+        logAt(logInfo, "tzdata: using release %s from %s", version, t.dir)
+    } else {
+        logAt(logInfo, "tzdata: reloaded %s (was %s) from %s", version, old.Version, t.dir)
+    }
+    return true, nil
+}
+
+// watch reloads the directory every interval until ctx is done
+func (t *tzdataSource) watch(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+        if _, err := t.reload(); err != nil {
+            logAt(logWarn, "tzdata: keeping %s: %v", t.active().Version, err)
+        }
+    }
+}
+
+// stats reports the source for /debug/vars and /admin/tzdata
+func (t *tzdataSource) stats() map[string]interface{} {
+    out := map[string]interface{}{
+        "version": tzdataVersion(),
+        "source":  "system",
+        "checks":  t.checks.Load(),
+        "reloads": t.reloads.Load(),
+        "failed":  t.failed.Load(),
+    }
+    if b := t.active(); b != nil {
+        out["source"] = b.Dir
+        out["loaded_at"] = b.LoadedAt.UTC().Format(time.RFC3339)
+    }
+    if msg := t.lastErr.Load(); msg != nil {
+        out["last_error"] = *msg
+    }
+    return out
+}
+
+// load reads a zone from the bundle; fs.ErrNotExist means it has none
+func (b *tzBundle) load(name string) (*time.Location, error) {
+    if !fs.ValidPath(name) {
+        return nil, errors.New("invalid zone name")
+    }
+    data, err := os.ReadFile(filepath.Join(b.Dir, filepath.FromSlash(name)))
+    if err != nil {
+        return nil, err
+    }
+    return time.LoadLocationFromTZData(name, data)
+}
+
+// loadZone loads an IANA zone from the bundle in use, falling back to the
+// system database for zones the bundle lacks
+func loadZone(name string) (*time.Location, error) {
+    if b := tzdata.active(); b != nil && name != "UTC" && name != "Local" {
+        loc, err := b.load(name)
+        if err == nil || !errors.Is(err, fs.ErrNotExist) {
+            return loc, err
+        }
+    }
+    return time.LoadLocation(name)
+}
+
+/* ------------------------------------------------------------------ */
+/*                              admin                                 */
+/* ------------------------------------------------------------------ */
+
+// handleAdminTzdata handles GET /admin/tzdata
+func handleAdminTzdata(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    writeJSON(w, http.StatusOK, tzdata.stats())
+}
+
+// handleAdminTzdataReload handles POST /admin/tzdata/reload
+func handleAdminTzdataReload(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    changed, err := tzdata.reload()
+    if err != nil {
+        status := http.StatusUnprocessableEntity
+        if tzdata.active() == nil {
+            status = http.StatusConflict
+        }
+        writeJSONError(w, status, err.Error())
+        return
+    }
+    out := tzdata.stats()
+    out["changed"] = changed
+    writeJSON(w, http.StatusOK, out)
+}
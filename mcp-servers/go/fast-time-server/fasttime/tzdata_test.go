@@ -0,0 +1,127 @@
+// -*- coding: utf-8 -*-
+// tzdata_test.go - tests for the -tzdata-dir bundle and its reload
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+// writeZone copies a compiled zone from the system database into dir as name
+func writeZone(t *testing.T, dir, name, from string) {
+    t.Helper()
+    data, err := os.ReadFile(filepath.Join("/usr/share/zoneinfo", from))
+    if err != nil {
+        t.Skipf("system zoneinfo unavailable: %v", err)
+    }
+    path := filepath.Join(dir, filepath.FromSlash(name))
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(path, data, 0o644); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func writeRelease(t *testing.T, dir, version string) {
+    t.Helper()
+    if err := os.WriteFile(filepath.Join(dir, "+VERSION"), []byte(version+"\n"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestTzdataReload(t *testing.T) {
+    defer func() {
+        tzdata = &tzdataSource{}
+        invalidateTzCache()
+        resetZoneCandidates()
+    }()
+
+    dir := t.TempDir()
+    writeZone(t, dir, "UTC", "UTC")
+    writeZone(t, dir, "Europe/London", "Europe/London")
+    writeRelease(t, dir, "2099a")
+    if err := tzdata.use(dir); err != nil {
+        t.Fatal(err)
+    }
+    if v := currentVersionInfo(); v.Tzdata != "2099a" || v.TzdataDir != dir {
+        t.Errorf("version = %q from %q", v.Tzdata, v.TzdataDir)
+    }
+    winter := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+    loc, err := loadLocation("Europe/London")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, off := winter.In(loc).Zone(); off != 0 {
+        t.Errorf("London offset = %d", off)
+    }
+    // Zones the bundle lacks come from the system database
+    if _, err := loadLocation("Asia/Tokyo"); err != nil {
+        t.Errorf("fallback: %v", err)
+    }
+
+    // A new release with different rules replaces the cached zone
+    writeZone(t, dir, "Europe/London", "Asia/Tokyo")
+    writeRelease(t, dir, "2099b")
+    if changed, err := tzdata.reload(); err != nil || !changed {
+        t.Fatalf("reload = %v, %v", changed, err)
+    }
+    loc, _ = loadLocation("Europe/London")
+    if _, off := winter.In(loc).Zone(); off != 9*3600 {
+        t.Errorf("London offset after reload = %d", off)
+    }
+    if changed, err := tzdata.reload(); err != nil || changed {
+        t.Errorf("unchanged reload = %v, %v", changed, err)
+    }
+
+    // A corrupt release is refused and the previous one kept
+    if err := os.WriteFile(filepath.Join(dir, "Europe", "London"), []byte("garbage"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    writeRelease(t, dir, "2099c")
+    if _, err := tzdata.reload(); err == nil {
+        t.Error("corrupt release accepted")
+    }
+    stats := tzdata.stats()
+    if stats["version"] != "2099b" || stats["failed"] != int64(1) || stats["last_error"] == nil {
+        t.Errorf("stats = %v", stats)
+    }
+    if got := debugVars()["tzdata"].(map[string]interface{}); got["source"] != dir {
+        t.Errorf("debug vars = %v", got)
+    }
+}
+
+func TestAdminTzdataReload(t *testing.T) {
+    defer func() { tzdata = &tzdataSource{} }()
+
+    rec := httptest.NewRecorder()
+    handleAdminTzdataReload(rec, httptest.NewRequest(http.MethodPost, "/admin/tzdata/reload", nil))
+    if rec.Code != http.StatusConflict {
+        t.Errorf("without -tzdata-dir: %d %s", rec.Code, rec.Body)
+    }
+
+    dir := t.TempDir()
+    writeZone(t, dir, "UTC", "UTC")
+    writeRelease(t, dir, "2099a")
+    if err := tzdata.use(dir); err != nil {
+        t.Fatal(err)
+    }
+    rec = httptest.NewRecorder()
+    handleAdminTzdataReload(rec, httptest.NewRequest(http.MethodGet, "/admin/tzdata/reload", nil))
+    if rec.Code != http.StatusMethodNotAllowed {
+        t.Errorf("GET = %d", rec.Code)
+    }
+    rec = httptest.NewRecorder()
+    handleAdminTzdataReload(rec, httptest.NewRequest(http.MethodPost, "/admin/tzdata/reload", nil))
+    if rec.Code != http.StatusOK {
+        t.Errorf("POST = %d %s", rec.Code, rec.Body)
+    }
+}
@@ -0,0 +1,201 @@
+// -*- coding: utf-8 -*-
+// tzdetail.go - single-zone details for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file describes one timezone: its current offset, DST state and
+// abbreviation (as is_dst reports them), the next transition, the country
+// it belongs to and example cities. It backs GET /api/v1/timezones/{zone}
+// and the timezone://zones/{zone} resource template.
+//
+// Countries come from zone1970.tab (or the older zone.tab) and iso3166.tab
+// of the timezone database in use; without them the country is omitted.
+// Example cities are the zone's own city and the world-clock cities in it.
+
+package fasttime
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/url"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// zoneCountry is the zone1970.tab entry of a canonical zone
+type zoneCountry struct {
+    codes   []string
+    comment string
+}
+
+// zoneCountries and countryNames are read on first use and dropped when the
+// tzdata bundle is reloaded
+var (
+    zoneCountriesMu sync.Mutex
+    zoneCountries   map[string]zoneCountry
+    countryNames    map[string]string
+)
+
+// zoneCountryData returns zoneCountries and countryNames, loading them if needed
+func zoneCountryData() (map[string]zoneCountry, map[string]string) {
+    zoneCountriesMu.Lock()
+    defer zoneCountriesMu.Unlock()
+    if zoneCountries == nil {
+        zoneCountries, countryNames = loadZoneCountries()
+    }
+    return zoneCountries, countryNames
+}
+
+// resetZoneCountries makes the next lookup re-read the tab files
+func resetZoneCountries() {
+    zoneCountriesMu.Lock()
+    zoneCountries, countryNames = nil, nil
+    zoneCountriesMu.Unlock()
+}
+
+// readTab calls fn with the tab-separated fields of each line of path
+func readTab(path string, fn func(fields []string)) bool {
+    f, err := os.Open(path)
+    if err != nil {
+        return false
+    }
+    defer f.Close()
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        line := scanner.Text()
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        fn(strings.Split(line, "\t"))
+    }
+    return true
+}
+
+// loadZoneCountries reads the first zone and country tables found
+func loadZoneCountries() (map[string]zoneCountry, map[string]string) {
+    zones := make(map[string]zoneCountry)
+    names := make(map[string]string)
+    for _, dir := range zoneinfoDirs() {
+        for _, file := range []string{"zone1970.tab", "zone.tab"} {
+            if len(zones) > 0 {
+                break
+            }
+            readTab(filepath.Join(dir, file), func(fields []string) {
+                if len(fields) < 3 {
+                    return
+                }
+                zc := zoneCountry{codes: strings.Split(fields[0], ",")}
+                if len(fields) > 3 {
+                    zc.comment = fields[3]
+                }
+                zones[fields[2]] = zc
+            })
+        }
+        if len(names) == 0 {
+            readTab(filepath.Join(dir, "iso3166.tab"), func(fields []string) {
+                if len(fields) >= 2 {
+                    names[fields[0]] = fields[1]
+                }
+            })
+        }
+        if len(zones) > 0 && len(names) > 0 {
+            break
+        }
+    }
+    return zones, names
+}
+
+// exampleCities lists the zone's own city and the world-clock cities in it
+func exampleCities(zone string) []string {
+    out := []string{}
+    seen := make(map[string]bool)
+    add := func(name string) {
+        if key := tzKey(name); key != "" && !seen[key] {
+            seen[key] = true
+            out = append(out, name)
+        }
+    }
+    if strings.Contains(zone, "/") && !strings.HasPrefix(zone, "Etc/") {
+        add(strings.ReplaceAll(tzCity(zone), "_", " "))
+    }
+    var cities []string
+    for city, tz := range worldCities {
+        if tz == zone {
+            cities = append(cities, city)
+        }
+    }
+    sort.Strings(cities)
+    for _, city := range cities {
+        add(city)
+    }
+    return out
+}
+
+// timezoneDetail describes loc at t
+func timezoneDetail(loc *time.Location, t time.Time) map[string]interface{} {
+    local := t.In(loc)
+    info := dstInfo(local)
+    info["name"] = loc.String()
+    info["current_time"] = local.Format(time.RFC3339)
+
+    zone := loc.String()
+    if canon, ok := zoneCandidates()[zone]; ok && canon != zone {
+        zone = canon
+        info["canonical"] = canon
+    }
+    zones, names := zoneCountryData()
+    if zc, ok := zones[zone]; ok {
+        countries := make([]map[string]string, 0, len(zc.codes))
+        for _, code := range zc.codes {
+            countries = append(countries, map[string]string{"code": code, "name": names[code]})
+        }
+        info["country"] = countries[0]
+        if len(countries) > 1 {
+            info["countries"] = countries
+        }
+        if zc.comment != "" {
+            info["comment"] = zc.comment
+        }
+    }
+    info["example_cities"] = exampleCities(zone)
+    return info
+}
+
+// handleTimezoneDetailResource serves timezone://zones/{zone}
+func handleTimezoneDetailResource(_ context.Context, req mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    // Template variables arrive as []string; the zone may be escaped
+    var zone string
+    if vs, ok := req.Params.Arguments["zone"].([]string); ok && len(vs) > 0 {
+        zone = vs[0]
+    }
+    if z, err := url.PathUnescape(zone); err == nil {
+        zone = z
+    }
+    loc, err := loadLocation(zone)
+    if err != nil {
+        return nil, err
+    }
+
+    jsonData, err := json.Marshal(timezoneDetail(loc, currentTime()))
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal timezone detail: %w", err)
+    }
+
+    logAt(logInfo, "resource: timezone %s requested", loc)
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      req.Params.URI,
+            MIMEType: "application/json",
+            Text:     string(jsonData),
+        },
+    }, nil
+}
@@ -0,0 +1,76 @@
+// -*- coding: utf-8 -*-
+// tzdetail_test.go - Tests for single-zone details
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTimezoneDetail(t *testing.T) {
+    loc, err := loadLocation("Europe/London")
+    if err != nil {
+        t.Fatal(err)
+    }
+    at, _ := time.Parse(time.RFC3339, "2025-07-07T12:00:00Z")
+    d := timezoneDetail(loc, at)
+    if d["name"] != "Europe/London" || d["is_dst"] != true || d["abbreviation"] != "BST" {
+        t.Errorf("unexpected detail %v", d)
+    }
+    if d["current_time"] != "2025-07-07T13:00:00+01:00" {
+        t.Errorf("current_time = %v", d["current_time"])
+    }
+    if d["next_transition"] == nil {
+        t.Error("next_transition missing")
+    }
+    cities, _ := d["example_cities"].([]string)
+    if len(cities) == 0 || cities[0] != "London" {
+        t.Errorf("example_cities = %v", d["example_cities"])
+    }
+    // Countries need the zone tables of a system timezone database
+    if _, names := zoneCountryData(); len(names) > 0 {
+        if c, _ := d["country"].(map[string]string); c["code"] != "GB" {
+            t.Errorf("country = %v", d["country"])
+        }
+    }
+}
+
+func TestExampleCities(t *testing.T) {
+    if got := exampleCities("Etc/UTC"); len(got) != 0 {
+        t.Errorf("Etc/UTC cities = %v", got)
+    }
+    got := exampleCities("America/Argentina/Buenos_Aires")
+    if len(got) == 0 || got[0] != "Buenos Aires" {
+        t.Errorf("Buenos Aires cities = %v", got)
+    }
+}
+
+func TestHandleTimezoneDetailResource(t *testing.T) {
+    req := mcp.ReadResourceRequest{}
+    req.Params.URI = "timezone://zones/Asia%2FTokyo"
+    req.Params.Arguments = map[string]any{"zone": []string{"Asia%2FTokyo"}}
+    contents, err := handleTimezoneDetailResource(context.Background(), req)
+    if err != nil {
+        t.Fatalf("handler error: %v", err)
+    }
+    var body map[string]interface{}
+    if err := json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &body); err != nil {
+        t.Fatalf("resource is not JSON: %v", err)
+    }
+    if body["name"] != "Asia/Tokyo" || body["observes_dst"] != false {
+        t.Errorf("unexpected resource body %v", body)
+    }
+
+    req.Params.Arguments = map[string]any{"zone": []string{"Mars/Olympus"}}
+    if _, err := handleTimezoneDetailResource(context.Background(), req); err == nil {
+        t.Error("expected error for unknown zone")
+    }
+}
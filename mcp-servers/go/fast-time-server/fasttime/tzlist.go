@@ -0,0 +1,251 @@
+// -*- coding: utf-8 -*-
+// tzlist.go - filtering, sorting and paging of GET /api/v1/timezones
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// GET /api/v1/timezones lists the curated knownTimezones, or with all=true
+// every canonical zone of the timezone database in use. The list can be
+// narrowed by name (filter=Europe) and by current UTC offset
+// (offset=+02:00), sorted by name or offset (sort=id, -id, offset, -offset)
+// and split into pages (page=2&per_page=50). Paged responses carry an
+// RFC 5988 Link header with first, prev, next and last relations and an
+// X-Total-Count header, so UI clients can browse hundreds of zones.
+//
+// Without page or per_page the whole list is returned as before.
+
+package fasttime
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Bounds and default for per_page
+const (
+    defaultTimezonesPerPage = 50
+    maxTimezonesPerPage     = 500
+)
+
+// offsetMaxAge is how long clients may reuse a list that depends on the
+// current offsets, which change at DST transitions
+const offsetMaxAge = time.Minute
+
+// tzListQuery is the parsed query of GET /api/v1/timezones
+type tzListQuery struct {
+    filter  string
+    offset  *int // seconds east of UTC
+    all     bool
+    sort    string
+    page    int
+    perPage int // 0 when the list is not paged
+}
+
+// parseUTCOffset reads an offset such as +02:00, -0330, +5, UTC+2 or Z.
+// A leading space stands for '+', which an unencoded query turns into one.
+func parseUTCOffset(raw string) (int, error) {
+    s := strings.ToUpper(raw)
+    if strings.HasPrefix(s, " ") {
+        s = "+" + strings.TrimLeft(s, " ")
+    }
+    s = strings.TrimSpace(s)
+    s = strings.TrimPrefix(strings.TrimPrefix(s, "UTC"), "GMT")
+    if s == "" || s == "Z" || s == "0" {
+        return 0, nil
+    }
+    sign := 1
+    switch s[0] {
+    case '+':
+        s = s[1:]
+    case '-':
+        sign, s = -1, s[1:]
+    }
+
+    hh, mm, found := strings.Cut(s, ":")
+    if !found && len(s) == 4 {
+        hh, mm = s[:2], s[2:]
+    }
+    h, err := strconv.Atoi(hh)
+    if err != nil || h < 0 || h > 14 || len(hh) > 2 {
+        return 0, fmt.Errorf("invalid UTC offset %q: use a form such as +02:00", raw)
+    }
+    m := 0
+    if mm != "" {
+        if m, err = strconv.Atoi(mm); err != nil || m < 0 || m > 59 || len(mm) != 2 {
+            return 0, fmt.Errorf("invalid UTC offset %q: use a form such as +02:00", raw)
+        }
+    }
+    return sign * (h*3600 + m*60), nil
+}
+
+// positiveParam reads an optional positive integer query parameter
+func positiveParam(q url.Values, name string, def, max int) (int, error) {
+    raw := q.Get(name)
+    if raw == "" {
+        return def, nil
+    }
+    n, err := strconv.Atoi(raw)
+    if err != nil || n < 1 {
+        return 0, fmt.Errorf("%s must be a positive integer", name)
+    }
+    if max > 0 && n > max {
+        return 0, fmt.Errorf("%s must be at most %d", name, max)
+    }
+    return n, nil
+}
+
+// parseTZListQuery validates the query of GET /api/v1/timezones
+func parseTZListQuery(q url.Values) (tzListQuery, error) {
+    out := tzListQuery{filter: q.Get("filter"), sort: q.Get("sort")}
+
+    if raw := q.Get("all"); raw != "" {
+        all, err := strconv.ParseBool(raw)
+        if err != nil {
+            return out, fieldError("all", errors.New("all must be true or false"))
+        }
+        out.all = all
+    }
+    if raw := q.Get("offset"); raw != "" {
+        secs, err := parseUTCOffset(raw)
+        if err != nil {
+            return out, fieldError("offset", err)
+        }
+        out.offset = &secs
+    }
+    switch out.sort {
+    case "", "id", "-id", "offset", "-offset":
+    default:
+        return out, fieldError("sort", fmt.Errorf("invalid sort %q: use id, -id, offset or -offset", out.sort))
+    }
+
+    var err error
+    if out.page, err = positiveParam(q, "page", 1, 0); err != nil {
+        return out, fieldError("page", err)
+    }
+    if q.Has("page") || q.Has("per_page") {
+        if out.perPage, err = positiveParam(q, "per_page", defaultTimezonesPerPage, maxTimezonesPerPage); err != nil {
+            return out, fieldError("per_page", err)
+        }
+    }
+    return out, nil
+}
+
+// canonicalZones lists the canonical zones of the timezone database, by name
+func canonicalZones() []string {
+    var zones []string
+    for name, canon := range zoneCandidates() {
+        if name == canon {
+            zones = append(zones, name)
+        }
+    }
+    sort.Strings(zones)
+    return zones
+}
+
+// listTimezones returns the zones selected by q, in order, as of now
+func listTimezones(q tzListQuery, now time.Time) []string {
+    source := knownTimezones
+    if q.all {
+        source = canonicalZones()
+    }
+
+    needOffsets := q.offset != nil || strings.HasSuffix(q.sort, "offset")
+    filter := strings.ToLower(q.filter)
+    zones := []string{}
+    offsets := make(map[string]int)
+    for _, tz := range source {
+        if filter != "" && !strings.Contains(strings.ToLower(tz), filter) {
+            continue
+        }
+        if needOffsets {
+            loc, err := loadLocation(tz)
+            if err != nil {
+                continue
+            }
+            _, off := now.In(loc).Zone()
+            if q.offset != nil && off != *q.offset {
+                continue
+            }
+            offsets[tz] = off
+        }
+        zones = append(zones, tz)
+    }
+
+    switch q.sort {
+    case "id":
+        sort.Strings(zones)
+    case "-id":
+        sort.Sort(sort.Reverse(sort.StringSlice(zones)))
+    case "offset", "-offset":
+        desc := q.sort == "-offset"
+        sort.SliceStable(zones, func(i, j int) bool {
+            a, b := offsets[zones[i]], offsets[zones[j]]
+            if a != b {
+                return (a < b) != desc
+            }
+            return zones[i] < zones[j]
+        })
+    }
+    return zones
+}
+
+// paginationLinks builds the Link header value for page of lastPage
+func paginationLinks(path string, q url.Values, page, perPage, lastPage int) string {
+    link := func(p int, rel string) string {
+        q.Set("page", strconv.Itoa(p))
+        q.Set("per_page", strconv.Itoa(perPage))
+        return fmt.Sprintf("<%s?%s>; rel=%q", path, q.Encode(), rel)
+    }
+    links := []string{link(1, "first")}
+    if page > 1 {
+        links = append(links, link(min(page-1, lastPage), "prev"))
+    }
+    if page < lastPage {
+        links = append(links, link(page+1, "next"))
+    }
+    links = append(links, link(lastPage, "last"))
+    return strings.Join(links, ", ")
+}
+
+// writeTimezoneList writes the zones selected by r, paged if it asks to be
+func writeTimezoneList(w http.ResponseWriter, r *http.Request) {
+    q, err := parseTZListQuery(r.URL.Query())
+    if err != nil {
+        writeAPIError(w, http.StatusBadRequest, err)
+        return
+    }
+    zones := listTimezones(q, currentTime())
+    maxAge := staticMaxAge
+    if q.offset != nil || strings.HasSuffix(q.sort, "offset") {
+        maxAge = offsetMaxAge
+    }
+
+    if q.perPage == 0 {
+        writeCacheable(w, r, map[string]interface{}{
+            "timezones": zones,
+            "count":     len(zones),
+        }, maxAge)
+        return
+    }
+
+    total := len(zones)
+    lastPage := max(1, (total+q.perPage-1)/q.perPage)
+    start := min((q.page-1)*q.perPage, total)
+    end := min(start+q.perPage, total)
+    w.Header().Add("Link", paginationLinks(requestPath(r), r.URL.Query(), q.page, q.perPage, lastPage))
+    w.Header().Set("X-Total-Count", strconv.Itoa(total))
+    writeCacheable(w, r, map[string]interface{}{
+        "timezones":   zones[start:end],
+        "count":       end - start,
+        "total":       total,
+        "page":        q.page,
+        "per_page":    q.perPage,
+        "total_pages": lastPage,
+    }, maxAge)
+}
@@ -0,0 +1,129 @@
+// -*- coding: utf-8 -*-
+// tzlist_test.go - Tests for timezone list filtering, sorting and paging
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "slices"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestParseUTCOffset(t *testing.T) {
+    tests := []struct {
+        in   string
+        want int
+        ok   bool
+    }{
+        {"+02:00", 7200, true},
+        {" 02:00", 7200, true}, // unencoded '+'
+        {"-0330", -12600, true},
+        {"+5", 18000, true},
+        {"UTC+5:30", 19800, true},
+        {"Z", 0, true},
+        {"+15:00", 0, false},
+        {"+02:7", 0, false},
+        {"noon", 0, false},
+    }
+    for _, tt := range tests {
+        got, err := parseUTCOffset(tt.in)
+        if (err == nil) != tt.ok || got != tt.want {
+            t.Errorf("parseUTCOffset(%q) = %d, %v", tt.in, got, err)
+        }
+    }
+}
+
+func TestListTimezones(t *testing.T) {
+    summer, _ := time.Parse(time.RFC3339, "2025-07-07T12:00:00Z")
+    q, err := parseTZListQuery(url.Values{"filter": {"Europe"}, "offset": {" 02:00"}, "sort": {"-id"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    got := listTimezones(q, summer)
+    if len(got) == 0 || !slices.IsSortedFunc(got, func(a, b string) int { return strings.Compare(b, a) }) {
+        t.Fatalf("zones not reverse sorted: %v", got)
+    }
+    if !slices.Contains(got, "Europe/Berlin") || slices.Contains(got, "Europe/London") {
+        t.Errorf("unexpected zones at +02:00: %v", got)
+    }
+
+    q, _ = parseTZListQuery(url.Values{"sort": {"offset"}})
+    got = listTimezones(q, summer)
+    if got[0] != "America/Los_Angeles" || got[len(got)-1] != "Pacific/Fiji" {
+        t.Errorf("offset order: first %s, last %s", got[0], got[len(got)-1])
+    }
+
+    for _, bad := range []url.Values{
+        {"sort": {"name"}},
+        {"page": {"0"}},
+        {"per_page": {"501"}},
+        {"offset": {"soon"}},
+        {"all": {"maybe"}},
+    } {
+        if _, err := parseTZListQuery(bad); err == nil {
+            t.Errorf("%v: expected error", bad)
+        }
+    }
+}
+
+func TestRESTTimezonesPaging(t *testing.T) {
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/timezones?filter=Europe&page=2&per_page=5", nil)
+    w := httptest.NewRecorder()
+    handleRESTListTimezones(w, req)
+    if w.Code != http.StatusOK {
+        t.Fatalf("status %d: %s", w.Code, w.Body.String())
+    }
+    var body struct {
+        Timezones  []string `json:"timezones"`
+        Count      int      `json:"count"`
+        Total      int      `json:"total"`
+        Page       int      `json:"page"`
+        TotalPages int      `json:"total_pages"`
+    }
+    if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+        t.Fatal(err)
+    }
+    if body.Count != 5 || body.Page != 2 || body.Total != 16 || body.TotalPages != 4 {
+        t.Errorf("unexpected page %+v", body)
+    }
+    if body.Timezones[0] != "Europe/Amsterdam" {
+        t.Errorf("page 2 starts at %s", body.Timezones[0])
+    }
+    if w.Header().Get("X-Total-Count") != "16" {
+        t.Errorf("X-Total-Count = %q", w.Header().Get("X-Total-Count"))
+    }
+    link := w.Header().Get("Link")
+    for _, want := range []string{
+        `</api/v1/timezones?filter=Europe&page=1&per_page=5>; rel="first"`,
+        `</api/v1/timezones?filter=Europe&page=1&per_page=5>; rel="prev"`,
+        `</api/v1/timezones?filter=Europe&page=3&per_page=5>; rel="next"`,
+        `</api/v1/timezones?filter=Europe&page=4&per_page=5>; rel="last"`,
+    } {
+        if !strings.Contains(link, want) {
+            t.Errorf("Link %q lacks %q", link, want)
+        }
+    }
+
+    // Unpaged requests keep the original shape
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/timezones", nil)
+    w = httptest.NewRecorder()
+    handleRESTListTimezones(w, req)
+    if w.Header().Get("Link") != "" || strings.Contains(w.Body.String(), "total_pages") {
+        t.Errorf("unpaged list should not be paged: %s", w.Body.String())
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/timezones?sort=size", nil)
+    w = httptest.NewRecorder()
+    handleRESTListTimezones(w, req)
+    if w.Code != http.StatusBadRequest {
+        t.Errorf("invalid sort: status %d", w.Code)
+    }
+}
@@ -0,0 +1,253 @@
+// -*- coding: utf-8 -*-
+// tznames.go - legacy, Windows and UTC-offset timezone names
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// loadLocation accepts more than IANA zone names. Before a name is looked
+// up it is normalized here to the canonical IANA ID:
+//
+//   - legacy names and other tzdata links ("US/Eastern", "Asia/Calcutta")
+//     become the zone they link to ("America/New_York", "Asia/Kolkata")
+//   - Windows zone names ("Eastern Standard Time") map to the zone CLDR
+//     lists for them
+//   - UTC offsets ("UTC+5:30", "GMT-8", "+02:00") become the matching
+//     Etc/GMT zone for whole hours (note the inverted POSIX sign:
+//     "GMT-8" is Etc/GMT+8) and a fixed zone named "UTC+05:30" otherwise
+//
+// Responses report the canonical name, so a caller that sent "US/Eastern"
+// sees "America/New_York".
+
+package fasttime
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// windowsZones maps Windows zone names to IANA zones (CLDR windowsZones,
+// territory 001), keyed in lower case
+var windowsZones = map[string]string{
+    "dateline standard time":          "Etc/GMT+12",
+    "aleutian standard time":          "America/Adak",
+    "hawaiian standard time":          "Pacific/Honolulu",
+    "marquesas standard time":         "Pacific/Marquesas",
+    "alaskan standard time":           "America/Anchorage",
+    "pacific standard time (mexico)":  "America/Tijuana",
+    "pacific standard time":           "America/Los_Angeles",
+    "us mountain standard time":       "America/Phoenix",
+    "mountain standard time (mexico)": "America/Mazatlan",
+    "mountain standard time":          "America/Denver",
+    "yukon standard time":             "America/Whitehorse",
+    "central america standard time":   "America/Guatemala",
+    "central standard time":           "America/Chicago",
+    "easter island standard time":     "Pacific/Easter",
+    "central standard time (mexico)":  "America/Mexico_City",
+    "canada central standard time":    "America/Regina",
+    "sa pacific standard time":        "America/Bogota",
+    "eastern standard time (mexico)":  "America/Cancun",
+    "eastern standard time":           "America/New_York",
+    "haiti standard time":             "America/Port-au-Prince",
+    "cuba standard time":              "America/Havana",
+    "us eastern standard time":        "America/Indiana/Indianapolis",
+    "turks and caicos standard time":  "America/Grand_Turk",
+    "paraguay standard time":          "America/Asuncion",
+    "atlantic standard time":          "America/Halifax",
+    "venezuela standard time":         "America/Caracas",
+    "central brazilian standard time": "America/Cuiaba",
+    "sa western standard time":        "America/La_Paz",
+    "pacific sa standard time":        "America/Santiago",
+    "newfoundland standard time":      "America/St_Johns",
+    "tocantins standard time":         "America/Araguaina",
+    "e. south america standard time":  "America/Sao_Paulo",
+    "sa eastern standard time":        "America/Cayenne",
+    "argentina standard time":         "America/Argentina/Buenos_Aires",
+    "greenland standard time":         "America/Nuuk",
+    "montevideo standard time":        "America/Montevideo",
+    "magallanes standard time":        "America/Punta_Arenas",
+    "saint pierre standard time":      "America/Miquelon",
+    "bahia standard time":             "America/Bahia",
+    "azores standard time":            "Atlantic/Azores",
+    "cape verde standard time":        "Atlantic/Cape_Verde",
+    "utc":                             "Etc/UTC",
+    "gmt standard time":               "Europe/London",
+    "greenwich standard time":         "Atlantic/Reykjavik",
+    "sao tome standard time":          "Africa/Sao_Tome",
+    "morocco standard time":           "Africa/Casablanca",
+    "w. europe standard time":         "Europe/Berlin",
+    "central europe standard time":    "Europe/Budapest",
+    "romance standard time":           "Europe/Paris",
+    "central european standard time":  "Europe/Warsaw",
+    "w. central africa standard time": "Africa/Lagos",
+    "jordan standard time":            "Asia/Amman",
+    "gtb standard time":               "Europe/Bucharest",
+    "middle east standard time":       "Asia/Beirut",
+    "egypt standard time":             "Africa/Cairo",
+    "e. europe standard time":         "Europe/Chisinau",
+    "syria standard time":             "Asia/Damascus",
+    "west bank standard time":         "Asia/Hebron",
+    "south africa standard time":      "Africa/Johannesburg",
+    "fle standard time":               "Europe/Kyiv",
+    "israel standard time":            "Asia/Jerusalem",
+    "south sudan standard time":       "Africa/Juba",
+    "kaliningrad standard time":       "Europe/Kaliningrad",
+    "sudan standard time":             "Africa/Khartoum",
+    "libya standard time":             "Africa/Tripoli",
+    "namibia standard time":           "Africa/Windhoek",
+    "arabic standard time":            "Asia/Baghdad",
+    "turkey standard time":            "Europe/Istanbul",
+    "arab standard time":              "Asia/Riyadh",
+    "belarus standard time":           "Europe/Minsk",
+    "russian standard time":           "Europe/Moscow",
+    "e. africa standard time":         "Africa/Nairobi",
+    "volgograd standard time":         "Europe/Volgograd",
+    "iran standard time":              "Asia/Tehran",
+    "arabian standard time":           "Asia/Dubai",
+    "astrakhan standard time":         "Europe/Astrakhan",
+    "azerbaijan standard time":        "Asia/Baku",
+    "russia time zone 3":              "Europe/Samara",
+    "mauritius standard time":         "Indian/Mauritius",
+    "saratov standard time":           "Europe/Saratov",
+    "georgian standard time":          "Asia/Tbilisi",
+    "caucasus standard time":          "Asia/Yerevan",
+    "afghanistan standard time":       "Asia/Kabul",
+    "west asia standard time":         "Asia/Tashkent",
+    "ekaterinburg standard time":      "Asia/Yekaterinburg",
+    "pakistan standard time":          "Asia/Karachi",
+    "qyzylorda standard time":         "Asia/Qyzylorda",
+    "india standard time":             "Asia/Kolkata",
+    "sri lanka standard time":         "Asia/Colombo",
+    "nepal standard time":             "Asia/Kathmandu",
+    "central asia standard time":      "Asia/Almaty",
+    "bangladesh standard time":        "Asia/Dhaka",
+    "omsk standard time":              "Asia/Omsk",
+    "myanmar standard time":           "Asia/Yangon",
+    "se asia standard time":           "Asia/Bangkok",
+    "altai standard time":             "Asia/Barnaul",
+    "w. mongolia standard time":       "Asia/Hovd",
+    "north asia standard time":        "Asia/Krasnoyarsk",
+    "n. central asia standard time":   "Asia/Novosibirsk",
+    "tomsk standard time":             "Asia/Tomsk",
+    "china standard time":             "Asia/Shanghai",
+    "north asia east standard time":   "Asia/Irkutsk",
+    "singapore standard time":         "Asia/Singapore",
+    "w. australia standard time":      "Australia/Perth",
+    "taipei standard time":            "Asia/Taipei",
+    "ulaanbaatar standard time":       "Asia/Ulaanbaatar",
+    "aus central w. standard time":    "Australia/Eucla",
+    "transbaikal standard time":       "Asia/Chita",
+    "tokyo standard time":             "Asia/Tokyo",
+    "north korea standard time":       "Asia/Pyongyang",
+    "korea standard time":             "Asia/Seoul",
+    "yakutsk standard time":           "Asia/Yakutsk",
+    "cen. australia standard time":    "Australia/Adelaide",
+    "aus central standard time":       "Australia/Darwin",
+    "e. australia standard time":      "Australia/Brisbane",
+    "aus eastern standard time":       "Australia/Sydney",
+    "west pacific standard time":      "Pacific/Port_Moresby",
+    "tasmania standard time":          "Australia/Hobart",
+    "vladivostok standard time":       "Asia/Vladivostok",
+    "lord howe standard time":         "Australia/Lord_Howe",
+    "bougainville standard time":      "Pacific/Bougainville",
+    "russia time zone 10":             "Asia/Srednekolymsk",
+    "magadan standard time":           "Asia/Magadan",
+    "norfolk standard time":           "Pacific/Norfolk",
+    "sakhalin standard time":          "Asia/Sakhalin",
+    "central pacific standard time":   "Pacific/Guadalcanal",
+    "russia time zone 11":             "Asia/Kamchatka",
+    "new zealand standard time":       "Pacific/Auckland",
+    "fiji standard time":              "Pacific/Fiji",
+    "chatham islands standard time":   "Pacific/Chatham",
+    "tonga standard time":             "Pacific/Tongatapu",
+    "samoa standard time":             "Pacific/Apia",
+    "line islands standard time":      "Pacific/Kiritimati",
+}
+
+// legacyZones maps common legacy names to their zone where tzdata.zi, and
+// with it the full link table, is not available
+var legacyZones = map[string]string{
+    "US/Eastern":  "America/New_York",
+    "US/Central":  "America/Chicago",
+    "US/Mountain": "America/Denver",
+    "US/Pacific":  "America/Los_Angeles",
+    "US/Alaska":   "America/Anchorage",
+    "US/Hawaii":   "Pacific/Honolulu",
+    "US/Arizona":  "America/Phoenix",
+
+    "Canada/Atlantic": "America/Halifax",
+    "Canada/Eastern":  "America/Toronto",
+    "Canada/Central":  "America/Winnipeg",
+    "Canada/Mountain": "America/Edmonton",
+    "Canada/Pacific":  "America/Vancouver",
+
+    "Asia/Calcutta": "Asia/Kolkata",
+    "Asia/Saigon":   "Asia/Ho_Chi_Minh",
+    "Asia/Katmandu": "Asia/Kathmandu",
+    "Asia/Rangoon":  "Asia/Yangon",
+    "Europe/Kiev":   "Europe/Kyiv",
+    "GB":            "Europe/London",
+    "Japan":         "Asia/Tokyo",
+    "PRC":           "Asia/Shanghai",
+    "Etc/UTC":       "UTC",
+    "Etc/GMT":       "UTC",
+    "GMT":           "UTC",
+    "Zulu":          "UTC",
+}
+
+// utcOffsetPattern matches "UTC+5:30", "GMT-8", "UTC+0530" and "+02:00"
+var utcOffsetPattern = regexp.MustCompile(`^(?i)(UTC|GMT)?\s*([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// canonicalZone returns the IANA name for a legacy, Windows or offset name,
+// or the name unchanged; fixed is set for offsets without an IANA zone
+func canonicalZone(name string) (canon string, fixed *time.Location, err error) {
+    name = strings.TrimSpace(name)
+    if zone, ok := windowsZones[strings.ToLower(name)]; ok {
+        name = zone
+    }
+    if m := utcOffsetPattern.FindStringSubmatch(name); m != nil {
+        return offsetZone(m[2], m[3], m[4])
+    }
+    if strings.EqualFold(name, "UTC") || strings.EqualFold(name, "GMT") {
+        return "UTC", nil, nil
+    }
+    if zone, ok := zoneCandidates()[name]; ok && zone != name {
+        name = zone
+    } else if zone, ok := legacyZones[name]; ok {
+        name = zone
+    }
+    // Etc/UTC and Etc/GMT are links in tzdata.zi; report them as UTC
+    if name == "Etc/UTC" || name == "Etc/GMT" {
+        name = "UTC"
+    }
+    return name, nil, nil
+}
+
+// offsetZone returns the zone for a UTC offset given as sign, hours and
+// optional minutes
+func offsetZone(sign, hh, mm string) (string, *time.Location, error) {
+    hours, _ := strconv.Atoi(hh)
+    minutes := 0
+    if mm != "" {
+        minutes, _ = strconv.Atoi(mm)
+    }
+    if minutes >= 60 || hours > 14 || (sign == "-" && hours > 12) {
+        return "", nil, fmt.Errorf("UTC offset %s%s:%02d out of range (-12:00 to +14:00)", sign, hh, minutes)
+    }
+    if minutes == 0 {
+        if hours == 0 {
+            return "UTC", nil, nil
+        }
+        // Etc/GMT zones use the POSIX sign: UTC+5 is Etc/GMT-5
+        inverted := map[string]string{"+": "-", "-": "+"}[sign]
+        return fmt.Sprintf("Etc/GMT%s%d", inverted, hours), nil, nil
+    }
+    secs := hours*3600 + minutes*60
+    if sign == "-" {
+        secs = -secs
+    }
+    name := fmt.Sprintf("UTC%s%02d:%02d", sign, hours, minutes)
+    return name, time.FixedZone(name, secs), nil
+}
@@ -0,0 +1,72 @@
+// -*- coding: utf-8 -*-
+// tznames_test.go - tests for legacy, Windows and offset timezone names
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestLoadLocationNormalizesNames(t *testing.T) {
+    for in, want := range map[string]string{
+        "US/Eastern":            "America/New_York",
+        "Asia/Calcutta":         "Asia/Kolkata",
+        "Eastern Standard Time": "America/New_York",
+        "tokyo standard time":   "Asia/Tokyo",
+        "GMT Standard Time":     "Europe/London",
+        "UTC":                   "UTC",
+        "Etc/UTC":               "UTC",
+        "GMT-8":                 "Etc/GMT+8",
+        "UTC+2":                 "Etc/GMT-2",
+        "+00:00":                "UTC",
+        "UTC+5:30":              "UTC+05:30",
+        "utc-03:30":             "UTC-03:30",
+        "GMT+0545":              "UTC+05:45",
+        "Europe/London":         "Europe/London",
+    } {
+        loc, err := loadLocation(in)
+        if err != nil {
+            t.Errorf("loadLocation(%q): %v", in, err)
+            continue
+        }
+        if loc.String() != want {
+            t.Errorf("loadLocation(%q) = %s, want %s", in, loc, want)
+        }
+    }
+
+    // Offsets keep their sign: UTC+5:30 is ahead of UTC, GMT-8 behind
+    at := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+    for in, want := range map[string]int{"UTC+5:30": 19800, "GMT-8": -28800} {
+        loc, _ := loadLocation(in)
+        if _, off := at.In(loc).Zone(); off != want {
+            t.Errorf("%s offset = %d, want %d", in, off, want)
+        }
+    }
+
+    for _, bad := range []string{"UTC+15", "GMT-13", "UTC+5:75", "Eastern Standard"} {
+        if _, err := loadLocation(bad); err == nil {
+            t.Errorf("loadLocation(%q) should fail", bad)
+        }
+    }
+}
+
+func TestRESTReportsCanonicalZone(t *testing.T) {
+    rec := httptest.NewRecorder()
+    body := `{"time":"2025-06-21T16:00:00Z","from_timezone":"UTC","to_timezone":"US/Pacific"}`
+    handleRESTConvertTime(rec, httptest.NewRequest(http.MethodPost, "/api/v1/convert", strings.NewReader(body)))
+    var res ConvertResponse
+    if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+        t.Fatal(err)
+    }
+    if res.ToTimezone != "America/Los_Angeles" || res.ConvertedTime != "2025-06-21T09:00:00-07:00" {
+        t.Errorf("convert = %s", rec.Body)
+    }
+}
@@ -0,0 +1,212 @@
+// -*- coding: utf-8 -*-
+// tzsuggest.go - "did you mean" suggestions for unknown timezones
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// When a zone name does not load, the INVALID_TIMEZONE error lists the
+// closest zones so agents can correct themselves. Candidates come from the
+// system timezone database (tzdata.zi), the zones of GET /api/v1/timezones,
+// operator aliases and a short table of cities without a zone of their own.
+// A name matches when it equals a candidate once case, spaces, '_' and '-'
+// are ignored ("America/NewYork"), when it names a zone's city ("Calcutta",
+// the Asia/Calcutta link to Asia/Kolkata), or when it is a few edits away
+// from a zone or its city. Links are reported as the zone they point to.
+
+package fasttime
+
+import (
+    "bufio"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// maxSuggestions caps the zones offered for one unknown name
+const maxSuggestions = 3
+
+// cityZones maps well-known cities without a zone of their own, and former
+// names that are tzdata links (for systems without tzdata.zi), to their zone
+var cityZones = map[string]string{
+    "beijing":  "Asia/Shanghai",
+    "peking":   "Asia/Shanghai",
+    "mumbai":   "Asia/Kolkata",
+    "bombay":   "Asia/Kolkata",
+    "delhi":    "Asia/Kolkata",
+    "newdelhi": "Asia/Kolkata",
+    "calcutta": "Asia/Kolkata",
+    "osaka":    "Asia/Tokyo",
+    "saigon":   "Asia/Ho_Chi_Minh",
+    "rangoon":  "Asia/Yangon",
+    "kiev":     "Europe/Kyiv",
+}
+
+// tzCandidates maps each known zone or link name to its canonical zone; it
+// is read on first use and dropped when the tzdata bundle is reloaded
+var (
+    tzCandidatesMu sync.Mutex
+    tzCandidates   map[string]string
+)
+
+// zoneCandidates returns tzCandidates, loading it if needed
+func zoneCandidates() map[string]string {
+    tzCandidatesMu.Lock()
+    defer tzCandidatesMu.Unlock()
+    if tzCandidates == nil {
+        tzCandidates = loadTzCandidates()
+    }
+    return tzCandidates
+}
+
+// resetZoneCandidates makes the next lookup re-read tzdata.zi
+func resetZoneCandidates() {
+    tzCandidatesMu.Lock()
+    tzCandidates = nil
+    tzCandidatesMu.Unlock()
+}
+
+// zoneinfoDirs lists the directories searched for the timezone database,
+// starting with the -tzdata-dir bundle in use
+func zoneinfoDirs() []string {
+    dirs := []string{"/usr/share/zoneinfo", "/usr/share/lib/zoneinfo", "/usr/lib/locale/TZ"}
+    if dir := os.Getenv("ZONEINFO"); dir != "" {
+        dirs = append([]string{dir}, dirs...)
+    }
+    if b := tzdata.active(); b != nil {
+        dirs = append([]string{b.Dir}, dirs...)
+    }
+    return dirs
+}
+
+// loadTzCandidates reads zone and link names from the first tzdata.zi found
+func loadTzCandidates() map[string]string {
+    out := make(map[string]string)
+    for _, tz := range knownTimezones {
+        out[tz] = tz
+    }
+    for _, dir := range zoneinfoDirs() {
+        f, err := os.Open(filepath.Join(dir, "tzdata.zi"))
+        if err != nil {
+            continue
+        }
+        scanner := bufio.NewScanner(f)
+        for scanner.Scan() {
+            fields := strings.Fields(scanner.Text())
+            switch {
+            case len(fields) >= 2 && fields[0] == "Z":
+                out[fields[1]] = fields[1]
+            case len(fields) >= 3 && fields[0] == "L":
+                out[fields[2]] = fields[1]
+            }
+        }
+        f.Close()
+        break
+    }
+    return out
+}
+
+// tzKey folds a name for comparison: lower case without spaces, '_' or '-'
+func tzKey(s string) string {
+    return strings.Map(func(r rune) rune {
+        switch r {
+        case ' ', '_', '-':
+            return -1
+        }
+        return r
+    }, strings.ToLower(strings.TrimSpace(s)))
+}
+
+// tzCity returns the last path segment of a zone name
+func tzCity(name string) string {
+    if i := strings.LastIndexByte(name, '/'); i >= 0 {
+        return name[i+1:]
+    }
+    return name
+}
+
+// suggestTimezones returns up to maxSuggestions canonical zones close to name
+func suggestTimezones(name string) []string {
+    key := tzKey(name)
+    if key == "" {
+        return nil
+    }
+    candidates := zoneCandidates()
+
+    scores := make(map[string]int) // canonical zone -> best distance
+    consider := func(zone string, d int) {
+        if best, ok := scores[zone]; !ok || d < best {
+            scores[zone] = d
+        }
+    }
+    if zone, ok := cityZones[tzKey(tzCity(name))]; ok {
+        consider(zone, 0)
+    }
+    for _, a := range tzAliases.list() {
+        if d := editDistance(key, tzKey(a.Name)); d <= maxEdits(key) {
+            consider(a.Name, d)
+        }
+    }
+    cityKey := tzKey(tzCity(name))
+    for cand, zone := range candidates {
+        full := tzKey(cand)
+        if full == key || tzKey(tzCity(cand)) == key {
+            consider(zone, 0)
+            continue
+        }
+        if d := editDistance(key, full); d <= maxEdits(key) {
+            consider(zone, d)
+        }
+        if strings.Contains(cand, "/") {
+            if d := editDistance(cityKey, tzKey(tzCity(cand))); d <= maxEdits(cityKey) {
+                consider(zone, d+1) // a city match ranks behind a full-name match
+            }
+        }
+    }
+
+    out := make([]string, 0, len(scores))
+    for zone := range scores {
+        out = append(out, zone)
+    }
+    sort.Slice(out, func(i, j int) bool {
+        if scores[out[i]] != scores[out[j]] {
+            return scores[out[i]] < scores[out[j]]
+        }
+        return out[i] < out[j]
+    })
+    if len(out) > maxSuggestions {
+        out = out[:maxSuggestions]
+    }
+    return out
+}
+
+// maxEdits is the largest edit distance still worth suggesting for s
+func maxEdits(s string) int {
+    if n := len(s) / 4; n > 2 {
+        return n
+    }
+    return 2
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+    ra, rb := []rune(a), []rune(b)
+    prev := make([]int, len(rb)+1)
+    cur := make([]int, len(rb)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        cur[0] = i
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+        }
+        prev, cur = cur, prev
+    }
+    return prev[len(rb)]
+}
@@ -0,0 +1,66 @@
+// -*- coding: utf-8 -*-
+// tzsuggest_test.go - tests for timezone suggestions
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestSuggestTimezones(t *testing.T) {
+    for in, want := range map[string]string{
+        "America/NewYork":  "America/New_York",
+        "america/new york": "America/New_York",
+        "Calcutta":         "Asia/Kolkata",
+        "Bombay":           "Asia/Kolkata",
+        "Europe/Londn":     "Europe/London",
+        "Asia/Tokio":       "Asia/Tokyo",
+        "Singapore":        "Asia/Singapore",
+    } {
+        got := suggestTimezones(in)
+        if len(got) == 0 || got[0] != want {
+            t.Errorf("suggestTimezones(%q) = %v, want %s first", in, got, want)
+        }
+        if len(got) > maxSuggestions {
+            t.Errorf("suggestTimezones(%q) = %v, more than %d", in, got, maxSuggestions)
+        }
+    }
+    if got := suggestTimezones("Not a zone at all, really"); len(got) != 0 {
+        t.Errorf("unrelated name got %v", got)
+    }
+}
+
+func TestEditDistance(t *testing.T) {
+    for _, tc := range []struct {
+        a, b string
+        want int
+    }{{"", "abc", 3}, {"kitten", "sitting", 3}, {"tokyo", "tokio", 1}, {"same", "same", 0}} {
+        if got := editDistance(tc.a, tc.b); got != tc.want {
+            t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+        }
+    }
+}
+
+func TestInvalidTimezoneSuggestions(t *testing.T) {
+    _, err := loadLocation("America/NewYork")
+    if err == nil || !strings.Contains(err.Error(), "did you mean America/New_York") {
+        t.Errorf("loadLocation error = %v", err)
+    }
+
+    rec := httptest.NewRecorder()
+    handleRESTGetTime(rec, httptest.NewRequest(http.MethodGet, "/api/v1/time/Calcutta", nil))
+    var body ErrorResponse
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatal(err)
+    }
+    if rec.Code != http.StatusBadRequest || len(body.Suggestions) == 0 || body.Suggestions[0] != "Asia/Kolkata" {
+        t.Errorf("REST error = %d %s", rec.Code, rec.Body)
+    }
+}
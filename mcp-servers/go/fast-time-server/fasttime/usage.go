@@ -0,0 +1,227 @@
+// -*- coding: utf-8 -*-
+// usage.go - per-client usage analytics
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file attributes MCP traffic to the client that sent it so gateway
+// operators can see who causes load and bill internally. Requests are keyed
+// by the scoped token they were made with ("token:ci"), else by the client
+// name sent in initialize ("client:claude-ai"), else "anonymous". For each
+// client it counts requests by method, failed requests, and calls and errors
+// per tool.
+//
+// GET /admin/usage returns every client (DELETE resets the counters); the
+// usage://stats resource returns the same table, or only the caller's own
+// entry when the request used a scoped token.
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// usageResourceURI is the MCP resource reporting usage
+const usageResourceURI = "usage://stats"
+
+// toolUsage counts one client's calls of one tool
+type toolUsage struct {
+    calls  int64
+    errors int64
+}
+
+// clientUsage holds the counters of one client
+type clientUsage struct {
+    requests  int64
+    errors    int64 // JSON-RPC errors and tool error results
+    byMethod  map[string]int64
+    tools     map[string]*toolUsage
+    firstSeen time.Time
+    lastSeen  time.Time
+}
+
+// usageRegistry holds the counters of every client seen
+type usageRegistry struct {
+    mu       sync.Mutex
+    byClient map[string]*clientUsage
+}
+
+// usage is the process-wide usage registry
+var usage = newUsageRegistry()
+
+// newUsageRegistry creates an empty registry
+func newUsageRegistry() *usageRegistry {
+    return &usageRegistry{byClient: make(map[string]*clientUsage)}
+}
+
+// usageClient names the client behind the request in ctx
+func usageClient(ctx context.Context) string {
+    if c := callerFrom(ctx); c != nil {
+        return "token:" + c.Name
+    }
+    if info, ok := clientFrom(ctx); ok && info.ClientName != "" {
+        return "client:" + info.ClientName
+    }
+    return "anonymous"
+}
+
+// client returns the counters for name, creating them; the caller holds mu
+func (ur *usageRegistry) client(name string, now time.Time) *clientUsage {
+    cu := ur.byClient[name]
+    if cu == nil {
+        cu = &clientUsage{byMethod: make(map[string]int64), tools: make(map[string]*toolUsage), firstSeen: now}
+        ur.byClient[name] = cu
+    }
+    cu.lastSeen = now
+    return cu
+}
+
+// request counts one request
+func (ur *usageRegistry) request(client, method string) {
+    ur.mu.Lock()
+    defer ur.mu.Unlock()
+    cu := ur.client(client, time.Now())
+    cu.requests++
+    cu.byMethod[method]++
+}
+
+// requestError counts one failed request
+func (ur *usageRegistry) requestError(client string) {
+    ur.mu.Lock()
+    defer ur.mu.Unlock()
+    ur.client(client, time.Now()).errors++
+}
+
+// toolCall counts one finished tool call. Calls that fail with a Go error
+// are also counted by the OnError hook, so only error results add to the
+// client's failed requests here.
+func (ur *usageRegistry) toolCall(client, tool string, res *mcp.CallToolResult, err error) {
+    cancelled := errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+    isErrorResult := err == nil && res != nil && res.IsError
+
+    ur.mu.Lock()
+    defer ur.mu.Unlock()
+    cu := ur.client(client, time.Now())
+    tu := cu.tools[tool]
+    if tu == nil {
+        tu = &toolUsage{}
+        cu.tools[tool] = tu
+    }
+    tu.calls++
+    if !cancelled && (err != nil || isErrorResult) {
+        tu.errors++
+    }
+    if isErrorResult {
+        cu.errors++
+    }
+}
+
+// snapshot returns the counters of the named clients (all when only is
+// empty), sorted by client
+func (ur *usageRegistry) snapshot(only string) []map[string]interface{} {
+    ur.mu.Lock()
+    defer ur.mu.Unlock()
+    names := make([]string, 0, len(ur.byClient))
+    for name := range ur.byClient {
+        if only == "" || name == only {
+            names = append(names, name)
+        }
+    }
+    sort.Strings(names)
+
+    out := make([]map[string]interface{}, 0, len(names))
+    for _, name := range names {
+        cu := ur.byClient[name]
+        tools := make(map[string]interface{}, len(cu.tools))
+        var calls int64
+        for tool, tu := range cu.tools {
+            tools[tool] = map[string]int64{"calls": tu.calls, "errors": tu.errors}
+            calls += tu.calls
+        }
+        methods := make(map[string]int64, len(cu.byMethod))
+        for m, n := range cu.byMethod {
+            methods[m] = n
+        }
+        errorRate := 0.0
+        if cu.requests > 0 {
+            errorRate = float64(cu.errors) / float64(cu.requests)
+        }
+        out = append(out, map[string]interface{}{
+            "client":     name,
+            "requests":   cu.requests,
+            "errors":     cu.errors,
+            "error_rate": errorRate,
+            "methods":    methods,
+            "tool_calls": calls,
+            "tools":      tools,
+            "first_seen": cu.firstSeen.UTC().Format(time.RFC3339),
+            "last_seen":  cu.lastSeen.UTC().Format(time.RFC3339),
+        })
+    }
+    return out
+}
+
+// reset clears all counters
+func (ur *usageRegistry) reset() {
+    ur.mu.Lock()
+    ur.byClient = make(map[string]*clientUsage)
+    ur.mu.Unlock()
+}
+
+// trackUsage installs the hooks that count requests and failures
+func (ur *usageRegistry) trackUsage(hooks *server.Hooks) {
+    hooks.AddBeforeAny(func(ctx context.Context, _ any, method mcp.MCPMethod, _ any) {
+        ur.request(usageClient(ctx), string(method))
+    })
+    hooks.AddOnError(func(ctx context.Context, _ any, _ mcp.MCPMethod, _ any, _ error) {
+        ur.requestError(usageClient(ctx))
+    })
+}
+
+// usageMiddleware counts each client's tool calls and tool errors
+func usageMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        res, err := next(ctx, req)
+        usage.toolCall(usageClient(ctx), req.Params.Name, res, err)
+        return res, err
+    }
+}
+
+// handleUsageResource serves usage://stats
+func handleUsageResource(ctx context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+    only := ""
+    if callerFrom(ctx) != nil {
+        only = usageClient(ctx)
+    }
+    clients := usage.snapshot(only)
+    data, err := json.MarshalIndent(map[string]interface{}{
+        "clients": clients,
+        "count":   len(clients),
+    }, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return []mcp.ResourceContents{
+        mcp.TextResourceContents{
+            URI:      usageResourceURI,
+            MIMEType: "application/json",
+            Text:     string(data),
+        },
+    }, nil
+}
+
+// registerUsageResource adds usage://stats to the server
+func registerUsageResource(s *server.MCPServer) {
+    s.AddResource(mcp.NewResource(usageResourceURI, "Usage Statistics",
+        mcp.WithResourceDescription("Requests, tool mix and error rates per client or scoped token"),
+        mcp.WithMIMEType("application/json"),
+    ), handleUsageResource)
+}
@@ -0,0 +1,109 @@
+// -*- coding: utf-8 -*-
+// usage_test.go - Tests for per-client usage analytics
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+func TestUsageRegistryCounts(t *testing.T) {
+    ur := newUsageRegistry()
+    ur.request("token:ci", "tools/call")
+    ur.request("token:ci", "tools/call")
+    ur.request("token:ci", "tools/call")
+    ur.request("token:ci", "tools/list")
+    ur.toolCall("token:ci", "convert_time", mcp.NewToolResultText("ok"), nil)
+    ur.toolCall("token:ci", "convert_time", mcp.NewToolResultError("bad"), nil)
+    ur.toolCall("token:ci", "sleep", nil, context.Canceled)
+    ur.request("anonymous", "ping")
+
+    snap := ur.snapshot("")
+    if len(snap) != 2 || snap[0]["client"] != "anonymous" {
+        t.Fatalf("snapshot = %v", snap)
+    }
+    ci := snap[1]
+    if ci["requests"] != int64(4) || ci["errors"] != int64(1) || ci["tool_calls"] != int64(3) {
+        t.Errorf("ci = %v", ci)
+    }
+    if rate := ci["error_rate"].(float64); rate != 0.25 {
+        t.Errorf("error_rate = %v", rate)
+    }
+    tools := ci["tools"].(map[string]interface{})
+    if ct := tools["convert_time"].(map[string]int64); ct["calls"] != 2 || ct["errors"] != 1 {
+        t.Errorf("convert_time = %v", ct)
+    }
+    if sl := tools["sleep"].(map[string]int64); sl["errors"] != 0 {
+        t.Errorf("cancelled calls are not errors: %v", sl)
+    }
+
+    if only := ur.snapshot("token:ci"); len(only) != 1 {
+        t.Errorf("filtered snapshot = %v", only)
+    }
+    ur.reset()
+    if len(ur.snapshot("")) != 0 {
+        t.Error("reset left counters")
+    }
+}
+
+func TestUsageThroughServer(t *testing.T) {
+    defer usage.reset()
+    usage.reset()
+    hooks := &server.Hooks{}
+    usage.trackUsage(hooks)
+    srv := server.NewMCPServer("test", "1.0",
+        server.WithToolCapabilities(false),
+        server.WithResourceCapabilities(false, false),
+        server.WithHooks(hooks),
+        server.WithToolHandlerMiddleware(usageMiddleware),
+    )
+    srv.AddTool(mcp.NewTool("fails"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return nil, errors.New("boom")
+    })
+    registerUsageResource(srv)
+
+    ctx := withCaller(context.Background(), &callerToken{Name: "ci"})
+    srv.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fails"}}`))
+    srv.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+
+    // A scoped token only sees its own entry
+    resp := srv.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"usage://stats"}}`))
+    r, ok := resp.(mcp.JSONRPCResponse)
+    if !ok {
+        t.Fatalf("resources/read: %#v", resp)
+    }
+    res, ok := r.Result.(mcp.ReadResourceResult)
+    if !ok {
+        t.Fatalf("result is %T", r.Result)
+    }
+    var body struct {
+        Clients []map[string]interface{} `json:"clients"`
+    }
+    if err := json.Unmarshal([]byte(res.Contents[0].(mcp.TextResourceContents).Text), &body); err != nil {
+        t.Fatal(err)
+    }
+    if len(body.Clients) != 1 || body.Clients[0]["client"] != "token:ci" || body.Clients[0]["errors"] != float64(1) {
+        t.Errorf("clients = %v", body.Clients)
+    }
+
+    rec := httptest.NewRecorder()
+    handleAdminUsage(rec, httptest.NewRequest(http.MethodGet, "/admin/usage", nil))
+    var all struct {
+        Count int `json:"count"`
+    }
+    _ = json.Unmarshal(rec.Body.Bytes(), &all)
+    if rec.Code != http.StatusOK || all.Count != 2 {
+        t.Errorf("GET /admin/usage: %d %s", rec.Code, rec.Body)
+    }
+}
@@ -0,0 +1,210 @@
+// -*- coding: utf-8 -*-
+// workpool.go - bounded worker pool for CPU-heavy calls
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Most tools answer in microseconds, but a few do real work: expanding
+// recurrence rules (parse_ical), walking shift cycles and rotations, searching
+// meeting windows and converting many times at once (get_world_times and POST
+// /api/v1/convert/batch). These run in a pool of -worker-pool slots so that
+// a burst of them cannot take every CPU from the cheap calls. A call that
+// finds every slot taken waits in a queue of at most -worker-queue calls;
+// beyond that it is refused at once with a BUSY error (429 with Retry-After
+// over REST) rather than piling up. A queued call stops waiting when its
+// request is cancelled or hits -tool-timeout.
+//
+// Running and queued calls, the deepest the queue has been, and accepted,
+// rejected and total wait counts are reported by GET /admin/workers and in
+// /debug/vars.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "runtime"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// defaultWorkerQueue is the default -worker-queue
+const defaultWorkerQueue = 64
+
+// heavyTools are the tools that run in the worker pool
+var heavyTools = map[string]bool{
+    "parse_ical":              true,
+    "get_shift_schedule":      true,
+    "generate_rotation":       true,
+    "meeting_overlap_windows": true,
+    "get_world_times":         true,
+}
+
+// workerPool bounds the heavy calls running at once
+type workerPool struct {
+    slots    chan struct{} // nil when the pool is disabled
+    maxQueue int
+
+    mu        sync.Mutex
+    running   int
+    queued    int
+    peakQueue int
+    accepted  int64
+    rejected  int64
+    waited    time.Duration
+}
+
+// heavyPool is the process-wide pool, sized by -worker-pool and -worker-queue
+var heavyPool = newWorkerPool(runtime.NumCPU(), defaultWorkerQueue)
+
+// newWorkerPool returns a pool of workers slots with a queue of maxQueue
+// calls; 0 workers disables the pool
+func newWorkerPool(workers, maxQueue int) *workerPool {
+    p := &workerPool{maxQueue: maxQueue}
+    if workers > 0 {
+        p.slots = make(chan struct{}, workers)
+    }
+    return p
+}
+
+// errBusy is returned when the pool and its queue are full
+func (p *workerPool) errBusy() *apiError {
+    return &apiError{
+        Code:    codeBusy,
+        Message: fmt.Sprintf("server busy: %d heavy calls running and %d queued", cap(p.slots), p.maxQueue),
+        Hint:    "retry in a moment",
+    }
+}
+
+// acquire takes a slot, waiting in the queue if there is room, and returns
+// the function that gives it back
+func (p *workerPool) acquire(ctx context.Context) (func(), error) {
+    if p.slots == nil {
+        return func() {}, nil
+    }
+    select {
+    case p.slots <- struct{}{}:
+        p.started(0)
+        return p.release, nil
+    default:
+    }
+
+    p.mu.Lock()
+    if p.queued >= p.maxQueue {
+        p.rejected++
+        p.mu.Unlock()
+        return nil, p.errBusy()
+    }
+    p.queued++
+    if p.queued > p.peakQueue {
+        p.peakQueue = p.queued
+    }
+    p.mu.Unlock()
+
+    start := time.Now()
+    select {
+    case p.slots <- struct{}{}:
+        p.mu.Lock()
+        p.queued--
+        p.mu.Unlock()
+        p.started(time.Since(start))
+        return p.release, nil
+    case <-ctx.Done():
+        p.mu.Lock()
+        p.queued--
+        p.mu.Unlock()
+        return nil, ctx.Err()
+    }
+}
+
+// started counts a call that got a slot after waiting for wait
+func (p *workerPool) started(wait time.Duration) {
+    p.mu.Lock()
+    p.running++
+    p.accepted++
+    p.waited += wait
+    p.mu.Unlock()
+}
+
+// release gives a slot back
+func (p *workerPool) release() {
+    p.mu.Lock()
+    p.running--
+    p.mu.Unlock()
+    <-p.slots
+}
+
+// stats returns the pool counters for /admin/workers and /debug/vars
+func (p *workerPool) stats() map[string]interface{} {
+    tools := make([]string, 0, len(heavyTools))
+    for name := range heavyTools {
+        tools = append(tools, name)
+    }
+    sort.Strings(tools)
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    return map[string]interface{}{
+        "workers":      cap(p.slots),
+        "max_queue":    p.maxQueue,
+        "running":      p.running,
+        "queued":       p.queued,
+        "peak_queue":   p.peakQueue,
+        "accepted":     p.accepted,
+        "rejected":     p.rejected,
+        "wait_ms":      float64(p.waited.Microseconds()) / 1000,
+        "heavy_tools":  tools,
+        "heavy_routes": []string{apiV1Prefix + "/convert/batch"},
+    }
+}
+
+// workerPoolMiddleware runs the heavy tools in heavyPool
+func workerPoolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+    return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        if !heavyTools[req.Params.Name] {
+            return next(ctx, req)
+        }
+        release, err := heavyPool.acquire(ctx)
+        if err != nil {
+            if ctx.Err() != nil {
+                return nil, err
+            }
+            logAt(logWarn, "refused %s: worker pool busy", req.Params.Name)
+            return toolError(err), nil
+        }
+        defer release()
+        return next(ctx, req)
+    }
+}
+
+// heavyHandler runs a REST handler in heavyPool, answering 429 when busy
+func heavyHandler(next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        release, err := heavyPool.acquire(r.Context())
+        if err != nil {
+            if r.Context().Err() != nil {
+                return // the client is gone
+            }
+            logAt(logWarn, "refused %s %s: worker pool busy", r.Method, r.URL.Path)
+            w.Header().Set("Retry-After", "1")
+            writeAPIError(w, http.StatusTooManyRequests, err)
+            return
+        }
+        defer release()
+        next(w, r)
+    }
+}
+
+// handleAdminWorkers handles GET /admin/workers
+func handleAdminWorkers(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+        return
+    }
+    writeJSON(w, http.StatusOK, heavyPool.stats())
+}
@@ -0,0 +1,111 @@
+// -*- coding: utf-8 -*-
+// workpool_test.go - tests for the worker pool of heavy calls
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+)
+
+// useTestPool replaces heavyPool for the duration of a test
+func useTestPool(t *testing.T, p *workerPool) {
+    t.Helper()
+    old := heavyPool
+    heavyPool = p
+    t.Cleanup(func() { heavyPool = old })
+}
+
+func TestWorkerPoolQueueAndReject(t *testing.T) {
+    p := newWorkerPool(1, 1)
+    release, err := p.acquire(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // The second call queues, the third is refused
+    got := make(chan error, 1)
+    go func() {
+        rel, err := p.acquire(context.Background())
+        if err == nil {
+            rel()
+        }
+        got <- err
+    }()
+    for p.stats()["queued"] != 1 {
+        time.Sleep(time.Millisecond)
+    }
+    if _, err := p.acquire(context.Background()); classifyError(0, err).Code != codeBusy {
+        t.Errorf("full queue: %v", err)
+    }
+
+    // A queued call gives up with its context
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+    defer cancel()
+    p.maxQueue = 2
+    if _, err := p.acquire(ctx); err != context.DeadlineExceeded {
+        t.Errorf("cancelled wait: %v", err)
+    }
+
+    release()
+    if err := <-got; err != nil {
+        t.Errorf("queued call: %v", err)
+    }
+    st := p.stats()
+    if st["running"] != 0 || st["queued"] != 0 || st["peak_queue"] != 2 || st["accepted"] != int64(2) || st["rejected"] != int64(1) {
+        t.Errorf("stats = %v", st)
+    }
+
+    // A disabled pool never waits
+    off := newWorkerPool(0, 0)
+    for i := 0; i < 3; i++ {
+        if _, err := off.acquire(context.Background()); err != nil {
+            t.Fatal(err)
+        }
+    }
+}
+
+func TestWorkerPoolTransports(t *testing.T) {
+    p := newWorkerPool(1, 0)
+    useTestPool(t, p)
+    release, _ := p.acquire(context.Background())
+    defer func() { release() }()
+
+    // Heavy tools are refused, cheap ones still answer
+    handler := workerPoolMiddleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+        return mcp.NewToolResultText("ok"), nil
+    })
+    res, _ := handler(context.Background(), testRequest("parse_ical", nil))
+    if !res.IsError || res.StructuredContent.(map[string]any)["error"].(*apiError).Code != codeBusy {
+        t.Errorf("heavy tool: %+v", res)
+    }
+    if res, _ := handler(context.Background(), testRequest("get_system_time", nil)); res.IsError {
+        t.Errorf("cheap tool: %+v", res)
+    }
+
+    mux := http.NewServeMux()
+    registerRESTHandlers(mux)
+    rec := httptest.NewRecorder()
+    body := `{"conversions":[{"time":"2025-01-10T10:00:00Z","from_timezone":"UTC","to_timezone":"Asia/Tokyo"}]}`
+    mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/convert/batch", strings.NewReader(body)))
+    if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" || !strings.Contains(rec.Body.String(), `"error_code":"BUSY"`) {
+        t.Errorf("batch: %d %s", rec.Code, rec.Body)
+    }
+
+    release()
+    release = func() {}
+    rec = httptest.NewRecorder()
+    mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/convert/batch", strings.NewReader(body)))
+    if rec.Code != http.StatusOK {
+        t.Errorf("batch after release: %d %s", rec.Code, rec.Body)
+    }
+}
@@ -0,0 +1,351 @@
+// -*- coding: utf-8 -*-
+// workweek.go - per-country business hours for fast-time-server
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file holds structured workweek data for common countries (working
+// days, typical office hours and lunch breaks) and evaluates timestamps
+// against it. It backs the time://business-hours resource, the REST
+// business-hours resource and the is_business_hours tool, which also accepts
+// a custom schedule.
+
+package fasttime
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/mark3labs/mcp-go/mcp"
+    "github.com/mark3labs/mcp-go/server"
+)
+
+// businessSchedule is a weekly working pattern in one timezone
+type businessSchedule struct {
+    code     string
+    name     string
+    region   string
+    tz       string
+    workdays []time.Weekday
+    hours    workingHours
+    lunch    *workingHours
+}
+
+var (
+    monFri = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+    sunThu = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+    monSat = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+    satWed = []time.Weekday{time.Saturday, time.Sunday, time.Monday, time.Tuesday, time.Wednesday}
+)
+
+// hm builds working hours from "HH:MM" style hour and minute pairs
+func hm(fromH, fromM, toH, toM int) workingHours {
+    return workingHours{fromH*60 + fromM, toH*60 + toM}
+}
+
+// lunchAt returns a pointer to a lunch break for the schedule table
+func lunchAt(fromH, fromM, toH, toM int) *workingHours {
+    wh := hm(fromH, fromM, toH, toM)
+    return &wh
+}
+
+// countrySchedules is the built-in workweek table keyed by ISO 3166-1 alpha-2 code.
+// Countries spanning several zones use the zone of the capital or main business hub.
+var countrySchedules = map[string]businessSchedule{
+    "US": {"US", "United States", "north_america", "America/New_York", monFri, hm(9, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "CA": {"CA", "Canada", "north_america", "America/Toronto", monFri, hm(9, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "MX": {"MX", "Mexico", "north_america", "America/Mexico_City", monFri, hm(9, 0, 18, 0), lunchAt(14, 0, 15, 0)},
+    "BR": {"BR", "Brazil", "south_america", "America/Sao_Paulo", monFri, hm(9, 0, 18, 0), lunchAt(12, 0, 13, 0)},
+    "AR": {"AR", "Argentina", "south_america", "America/Argentina/Buenos_Aires", monFri, hm(9, 0, 18, 0), lunchAt(13, 0, 14, 0)},
+    "GB": {"GB", "United Kingdom", "europe", "Europe/London", monFri, hm(9, 0, 17, 30), lunchAt(12, 30, 13, 30)},
+    "IE": {"IE", "Ireland", "europe", "Europe/Dublin", monFri, hm(9, 0, 17, 30), lunchAt(13, 0, 14, 0)},
+    "FR": {"FR", "France", "europe", "Europe/Paris", monFri, hm(9, 0, 18, 0), lunchAt(12, 30, 14, 0)},
+    "DE": {"DE", "Germany", "europe", "Europe/Berlin", monFri, hm(8, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "ES": {"ES", "Spain", "europe", "Europe/Madrid", monFri, hm(9, 0, 18, 0), lunchAt(14, 0, 15, 0)},
+    "IT": {"IT", "Italy", "europe", "Europe/Rome", monFri, hm(9, 0, 18, 0), lunchAt(13, 0, 14, 0)},
+    "NL": {"NL", "Netherlands", "europe", "Europe/Amsterdam", monFri, hm(9, 0, 17, 30), lunchAt(12, 30, 13, 0)},
+    "SE": {"SE", "Sweden", "europe", "Europe/Stockholm", monFri, hm(8, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "CH": {"CH", "Switzerland", "europe", "Europe/Zurich", monFri, hm(8, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "PL": {"PL", "Poland", "europe", "Europe/Warsaw", monFri, hm(8, 0, 16, 0), nil},
+    "RU": {"RU", "Russia", "europe", "Europe/Moscow", monFri, hm(9, 0, 18, 0), lunchAt(13, 0, 14, 0)},
+    "TR": {"TR", "Turkey", "europe", "Europe/Istanbul", monFri, hm(9, 0, 18, 0), lunchAt(12, 30, 13, 30)},
+    "AE": {"AE", "United Arab Emirates", "middle_east", "Asia/Dubai", monFri, hm(8, 0, 17, 0), lunchAt(13, 0, 14, 0)},
+    "SA": {"SA", "Saudi Arabia", "middle_east", "Asia/Riyadh", sunThu, hm(8, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "IL": {"IL", "Israel", "middle_east", "Asia/Jerusalem", sunThu, hm(9, 0, 18, 0), nil},
+    "EG": {"EG", "Egypt", "middle_east", "Africa/Cairo", sunThu, hm(9, 0, 17, 0), nil},
+    "IR": {"IR", "Iran", "middle_east", "Asia/Tehran", satWed, hm(8, 0, 16, 0), nil},
+    "ZA": {"ZA", "South Africa", "africa", "Africa/Johannesburg", monFri, hm(8, 0, 17, 0), lunchAt(13, 0, 14, 0)},
+    "NG": {"NG", "Nigeria", "africa", "Africa/Lagos", monFri, hm(8, 0, 17, 0), nil},
+    "KE": {"KE", "Kenya", "africa", "Africa/Nairobi", monFri, hm(8, 0, 17, 0), lunchAt(13, 0, 14, 0)},
+    "IN": {"IN", "India", "asia_pacific", "Asia/Kolkata", monFri, hm(9, 30, 18, 30), lunchAt(13, 0, 14, 0)},
+    "CN": {"CN", "China", "asia_pacific", "Asia/Shanghai", monFri, hm(9, 0, 18, 0), lunchAt(12, 0, 13, 30)},
+    "JP": {"JP", "Japan", "asia_pacific", "Asia/Tokyo", monFri, hm(9, 0, 18, 0), lunchAt(12, 0, 13, 0)},
+    "KR": {"KR", "South Korea", "asia_pacific", "Asia/Seoul", monFri, hm(9, 0, 18, 0), lunchAt(12, 0, 13, 0)},
+    "SG": {"SG", "Singapore", "asia_pacific", "Asia/Singapore", monFri, hm(9, 0, 18, 0), lunchAt(12, 0, 13, 0)},
+    "HK": {"HK", "Hong Kong", "asia_pacific", "Asia/Hong_Kong", monFri, hm(9, 0, 18, 0), lunchAt(13, 0, 14, 0)},
+    "PH": {"PH", "Philippines", "asia_pacific", "Asia/Manila", monSat, hm(8, 0, 17, 0), lunchAt(12, 0, 13, 0)},
+    "AU": {"AU", "Australia", "asia_pacific", "Australia/Sydney", monFri, hm(9, 0, 17, 0), lunchAt(12, 30, 13, 30)},
+    "NZ": {"NZ", "New Zealand", "asia_pacific", "Pacific/Auckland", monFri, hm(8, 30, 17, 0), lunchAt(12, 30, 13, 0)},
+}
+
+// countryCodes returns the supported country codes in sorted order
+func countryCodes() []string {
+    codes := make([]string, 0, len(countrySchedules))
+    for c := range countrySchedules {
+        codes = append(codes, c)
+    }
+    sort.Strings(codes)
+    return codes
+}
+
+// parseWorkdays parses "mon-fri", "sun-thu" or a comma-separated list of weekdays
+func parseWorkdays(s string) ([]time.Weekday, error) {
+    s = strings.ToLower(strings.TrimSpace(s))
+    if from, to, ok := strings.Cut(s, "-"); ok {
+        start, err := parseWeekday(from)
+        if err != nil {
+            return nil, err
+        }
+        end, err := parseWeekday(to)
+        if err != nil {
+            return nil, err
+        }
+        var days []time.Weekday
+        for d := start; ; d = (d + 1) % 7 {
+            days = append(days, d)
+            if d == end {
+                return days, nil
+            }
+        }
+    }
+    var days []time.Weekday
+    for _, part := range strings.Split(s, ",") {
+        d, err := parseWeekday(part)
+        if err != nil {
+            return nil, err
+        }
+        days = append(days, d)
+    }
+    return days, nil
+}
+
+// formatClockRange renders working hours as HH:MM-HH:MM
+func formatClockRange(wh workingHours) string {
+    return fmt.Sprintf("%02d:%02d-%02d:%02d", wh.startMin/60, wh.startMin%60, wh.endMin/60, wh.endMin%60)
+}
+
+// isWorkday reports whether d is one of the schedule's working days
+func (bs businessSchedule) isWorkday(d time.Weekday) bool {
+    for _, w := range bs.workdays {
+        if w == d {
+            return true
+        }
+    }
+    return false
+}
+
+// describe returns the schedule as JSON-friendly data
+func (bs businessSchedule) describe() map[string]interface{} {
+    days := make([]string, len(bs.workdays))
+    for i, d := range bs.workdays {
+        days[i] = d.String()
+    }
+    out := map[string]interface{}{
+        "code":         bs.code,
+        "name":         bs.name,
+        "region":       bs.region,
+        "timezone":     bs.tz,
+        "working_days": days,
+        "hours":        formatClockRange(bs.hours),
+    }
+    if bs.lunch != nil {
+        out["lunch_break"] = formatClockRange(*bs.lunch)
+    }
+    return out
+}
+
+// dayIntervals returns the working intervals of the local date of day;
+// holidays maps YYYY-MM-DD dates that are closed to their names (may be nil)
+func (bs businessSchedule) dayIntervals(day time.Time, holidays map[string]string) []interval {
+    if _, holiday := holidays[day.Format("2006-01-02")]; holiday || !bs.isWorkday(day.Weekday()) {
+        return nil
+    }
+    y, mo, d := day.Date()
+    at := func(min int) time.Time { return time.Date(y, mo, d, 0, min, 0, 0, day.Location()) }
+    if bs.lunch == nil || bs.lunch.startMin <= bs.hours.startMin || bs.lunch.endMin >= bs.hours.endMin {
+        return []interval{{at(bs.hours.startMin), at(bs.hours.endMin)}}
+    }
+    return []interval{
+        {at(bs.hours.startMin), at(bs.lunch.startMin)},
+        {at(bs.lunch.endMin), at(bs.hours.endMin)},
+    }
+}
+
+// evaluate checks t against the schedule in loc
+func (bs businessSchedule) evaluate(t time.Time, loc *time.Location, holidays map[string]string) map[string]interface{} {
+    local := t.In(loc)
+    today := bs.dayIntervals(local, holidays)
+
+    result := map[string]interface{}{
+        "local_time":        local.Format(time.RFC3339),
+        "weekday":           local.Weekday().String(),
+        "is_business_hours": false,
+    }
+
+    var current *interval
+    for i := range today {
+        if !local.Before(today[i].start) && local.Before(today[i].end) {
+            current = &today[i]
+        }
+    }
+    switch {
+    case current != nil:
+        result["is_business_hours"] = true
+        result["reason"] = "within_hours"
+        result["ends_at"] = current.end.Format(time.RFC3339)
+    case len(today) == 0:
+        result["reason"] = "non_working_day"
+        if name, ok := holidays[local.Format("2006-01-02")]; ok {
+            result["reason"] = "holiday"
+            result["holiday"] = name
+        }
+    case local.Before(today[0].start):
+        result["reason"] = "before_hours"
+    case local.Before(today[len(today)-1].end):
+        result["reason"] = "lunch_break"
+    default:
+        result["reason"] = "after_hours"
+    }
+
+    if current == nil {
+        y, mo, d := local.Date()
+    search:
+        // Every holiday can push the next opening back by one more day
+        for i := 0; i <= 7+len(holidays); i++ {
+            for _, iv := range bs.dayIntervals(time.Date(y, mo, d+i, 12, 0, 0, 0, loc), holidays) {
+                if iv.start.After(local) {
+                    result["next_start"] = iv.start.Format(time.RFC3339)
+                    break search
+                }
+            }
+        }
+    }
+    return result
+}
+
+// businessHoursData renders the workweek table grouped by region
+func businessHoursData() map[string]interface{} {
+    countries := make(map[string]interface{}, len(countrySchedules))
+    regions := map[string][]string{}
+    for _, c := range countryCodes() {
+        bs := countrySchedules[c]
+        countries[c] = bs.describe()
+        regions[bs.region] = append(regions[bs.region], c)
+    }
+    return map[string]interface{}{
+        "countries": countries,
+        "regions":   regions,
+    }
+}
+
+// handleIsBusinessHours evaluates a time against a country or custom schedule
+func handleIsBusinessHours(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+    var bs businessSchedule
+    if country := req.GetString("country", ""); country != "" {
+        var ok bool
+        if bs, ok = countrySchedules[strings.ToUpper(strings.TrimSpace(country))]; !ok {
+            return mcp.NewToolResultError(fmt.Sprintf("unknown country %q (supported: %s)", country, strings.Join(countryCodes(), ", "))), nil
+        }
+    } else {
+        bs = businessSchedule{code: "custom", name: "Custom schedule", tz: "UTC", workdays: monFri, hours: hm(9, 0, 17, 0)}
+    }
+
+    // Custom fields override the country defaults
+    if v := req.GetString("workdays", ""); v != "" {
+        days, err := parseWorkdays(v)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        bs.workdays = days
+    }
+    if v := req.GetString("hours", ""); v != "" {
+        wh, err := parseWorkingHours(v)
+        if err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+        bs.hours = wh
+    }
+    if v := req.GetString("lunch_break", ""); v != "" {
+        if strings.EqualFold(v, "none") {
+            bs.lunch = nil
+        } else {
+            wh, err := parseWorkingHours(v)
+            if err != nil {
+                return mcp.NewToolResultError(err.Error()), nil
+            }
+            bs.lunch = &wh
+        }
+    }
+    if v := req.GetString("timezone", ""); v != "" {
+        bs.tz = v
+    }
+    var holidays map[string]string
+    if v := req.GetString("holiday_calendar", ""); v != "" {
+        var err error
+        if holidays, err = holidayDates(v); err != nil {
+            return mcp.NewToolResultError(err.Error()), nil
+        }
+    }
+
+    loc, err := loadLocation(bs.tz)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+    bs.tz = loc.String() // report legacy, Windows and offset names canonically
+    t, err := timeArgIn(req, loc)
+    if err != nil {
+        return mcp.NewToolResultError(err.Error()), nil
+    }
+
+    result := bs.evaluate(t, loc, holidays)
+    result["schedule"] = bs.describe()
+
+    logAt(logInfo, "is_business_hours: %s at %s = %v", bs.code, t.Format(time.RFC3339), result["is_business_hours"])
+    return toolResultJSON(result)
+}
+
+// registerWorkweekTools adds is_business_hours to the server
+func registerWorkweekTools(s *server.MCPServer) {
+    s.AddTool(mcp.NewTool("is_business_hours",
+        mcp.WithDescription("Check whether a time falls within business hours for a country's standard workweek or a custom schedule"),
+        mcp.WithTitleAnnotation("Is Business Hours"),
+        mcp.WithReadOnlyHintAnnotation(true),
+        mcp.WithDestructiveHintAnnotation(false),
+        mcp.WithIdempotentHintAnnotation(false), // Not idempotent when time is omitted
+        mcp.WithOpenWorldHintAnnotation(false),
+        mcp.WithString("country",
+            mcp.Description("ISO 3166-1 alpha-2 country code (e.g., 'US', 'AE', 'IL'). Omit for a custom schedule"),
+        ),
+        mcp.WithString("time",
+            mcp.Description("Time to check. Times without an offset use the schedule's timezone. Defaults to now"),
+        ),
+        mcp.WithString("timezone",
+            mcp.Description("IANA timezone overriding the country's default zone"),
+        ),
+        mcp.WithString("workdays",
+            mcp.Description("Working days as a range or list (e.g., 'mon-fri', 'sun-thu', 'mon,wed,fri')"),
+        ),
+        mcp.WithString("hours",
+            mcp.Description("Working hours as HH:MM-HH:MM (custom default 09:00-17:00)"),
+        ),
+        mcp.WithString("lunch_break",
+            mcp.Description("Lunch break as HH:MM-HH:MM, or 'none'"),
+        ),
+        mcp.WithString("holiday_calendar",
+            mcp.Description("Name of a custom holiday calendar (managed via /admin/holidays) whose dates are non-working"),
+        ),
+    ), handleIsBusinessHours)
+}
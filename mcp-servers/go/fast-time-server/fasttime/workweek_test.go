@@ -0,0 +1,82 @@
+// -*- coding: utf-8 -*-
+// workweek_test.go - Tests for per-country business hours
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package fasttime
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestCountrySchedulesLoad(t *testing.T) {
+    for code, bs := range countrySchedules {
+        if code != bs.code {
+            t.Errorf("schedule %s has code %s", code, bs.code)
+        }
+        if _, err := loadLocation(bs.tz); err != nil {
+            t.Errorf("%s timezone %s does not load: %v", code, bs.tz, err)
+        }
+        if bs.hours.endMin <= bs.hours.startMin || len(bs.workdays) == 0 {
+            t.Errorf("%s has an invalid schedule", code)
+        }
+    }
+}
+
+func TestParseWorkdays(t *testing.T) {
+    days, err := parseWorkdays("sun-thu")
+    if err != nil || len(days) != 5 || days[0] != time.Sunday || days[4] != time.Thursday {
+        t.Errorf("parseWorkdays(sun-thu) = %v, %v", days, err)
+    }
+    days, err = parseWorkdays("fri-mon")
+    if err != nil || len(days) != 4 || days[3] != time.Monday {
+        t.Errorf("parseWorkdays(fri-mon) should wrap, got %v, %v", days, err)
+    }
+    if days, err := parseWorkdays("mon, wed ,fri"); err != nil || len(days) != 3 {
+        t.Errorf("parseWorkdays(list) = %v, %v", days, err)
+    }
+    if _, err := parseWorkdays("mon-funday"); err == nil {
+        t.Error("expected error for unknown weekday")
+    }
+}
+
+func TestHandleIsBusinessHours(t *testing.T) {
+    tests := []struct {
+        args       map[string]any
+        wantOpen   bool
+        wantReason string
+    }{
+        // Sunday is a working day in Saudi Arabia
+        {map[string]any{"country": "SA", "time": "2025-07-06T10:00:00"}, true, "within_hours"},
+        {map[string]any{"country": "us", "time": "2025-07-06T10:00:00"}, false, "non_working_day"},
+        {map[string]any{"country": "US", "time": "2025-07-07T12:30:00"}, false, "lunch_break"},
+        {map[string]any{"country": "US", "time": "2025-07-07T12:30:00", "lunch_break": "none"}, true, "within_hours"},
+        {map[string]any{"country": "DE", "time": "2025-07-07T07:00:00"}, false, "before_hours"},
+        {map[string]any{"workdays": "mon-fri", "hours": "22:00-23:30", "timezone": "Asia/Tokyo", "time": "2025-07-07T13:15:00Z"}, true, "within_hours"},
+    }
+    for _, tt := range tests {
+        res, err := handleIsBusinessHours(context.Background(), testRequest("is_business_hours", tt.args))
+        if err != nil {
+            t.Fatalf("handler error: %v", err)
+        }
+        var body struct {
+            IsBusinessHours bool   `json:"is_business_hours"`
+            Reason          string `json:"reason"`
+        }
+        if err := json.Unmarshal([]byte(extractText(t, res)), &body); err != nil {
+            t.Fatalf("result is not JSON: %v", err)
+        }
+        if body.IsBusinessHours != tt.wantOpen || body.Reason != tt.wantReason {
+            t.Errorf("%v: got %+v, want %v/%s", tt.args, body, tt.wantOpen, tt.wantReason)
+        }
+    }
+
+    res, _ := handleIsBusinessHours(context.Background(), testRequest("is_business_hours", map[string]any{"country": "XX"}))
+    if !res.IsError {
+        t.Error("expected error result for unknown country")
+    }
+}
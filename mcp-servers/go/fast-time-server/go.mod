@@ -4,10 +4,28 @@ go 1.23
 
 toolchain go1.23.10
 
-require github.com/mark3labs/mcp-go v0.32.0 // MCP server/runtime
+require (
+	github.com/andybalholm/brotli v1.1.1 // Brotli for -compress
+	github.com/mark3labs/mcp-go v0.41.0 // MCP server/runtime; sampling needs >= v0.33.0, elicitation >= v0.41.0
+	golang.org/x/sys v0.22.0 // Windows service, event log and named pipes
+	gopkg.in/yaml.v3 v3.0.1 // YAML REST responses
+	modernc.org/sqlite v1.34.5 // Pure Go SQLite for -db
+)
 
 require (
-    github.com/google/uuid v1.6.0 // indirect
-    github.com/spf13/cast v1.7.1 // indirect
-    github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	github.com/bahlo/generic-list-go v0.2.0 // indirect
+	github.com/buger/jsonparser v1.1.1 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/invopop/jsonschema v0.13.0 // indirect
+	github.com/mailru/easyjson v0.7.7 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	github.com/spf13/cast v1.7.1 // indirect
+	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
+	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	modernc.org/libc v1.55.3 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.8.0 // indirect
 )
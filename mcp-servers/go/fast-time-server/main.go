@@ -5,9 +5,10 @@
 // SPDX-License-Identifier: Apache-2.0
 // Authors: Mihai Criveti, Manav Gupta
 //
-// This file implements an MCP (Model Context Protocol) server written in Go
-// that provides time-related tools for LLM applications. The server exposes
-// both get_system_time and convert_time tools for comprehensive timezone support.
+// This file is the command line of an MCP (Model Context Protocol) server
+// written in Go that provides time-related tools for LLM applications. The
+// server itself is the fasttime package, which other Go programs can embed;
+// main binds its flags to a fasttime.Config and runs it.
 //
 // Build:
 //   go build -o fast-time-server .
@@ -15,6 +16,35 @@
 // Available Tools:
 //   - get_system_time: Returns current time in any IANA timezone
 //   - convert_time: Converts time between different timezones
+//   - get_unix_time / from_unix_time: Epoch timestamps in s/ms/us/ns precision
+//   - round_time / truncate_time: Snap a timestamp to a calendar boundary
+//   - start_end_of_period: Bounds of the day/week/month/quarter/year containing a time
+//   - is_dst: Whether DST is in effect, with offset and abbreviation
+//   - format_localized: Locale-aware dates with month/day names and native digits
+//   - resolve_timezone_abbreviation: Map abbreviations like CST/IST to IANA zones
+//   - flight_arrival_time: Local arrival time from departure time and flight duration
+//   - meeting_overlap_windows: Ranked windows inside everyone's working hours
+//   - generate_rotation: On-call schedule with handoffs in every participant's local time
+//   - age_and_elapsed: Calendar-aware age or elapsed time with a humanized summary
+//   - time_until: Countdown to a target time ("in 3 days" / "3 days ago")
+//   - parse_duration: Normalize Go/ISO 8601/loose durations, or humanize one
+//   - convert_time_scale: UTC/TAI/GPS with leap seconds, Julian Date and MJD
+//   - market_hours: Whether NYSE/NASDAQ/LSE/TSE/HKEX are open, with holidays
+//   - is_business_hours: Evaluate a time against a country's workweek or a custom schedule
+//   - sleep / wait_until: Pause for a duration or until a time, with progress
+//   - timer_start / timer_lap / timer_stop / timer_status: Per-session stopwatches
+//   - set_reminder / list_reminders / cancel_reminder: Per-session follow-up notifications
+//   - create_ical_event: .ics invite with VTIMEZONE, RRULE, attendees and alarm
+//   - parse_ical: Events from .ics text with recurrences expanded in a target timezone
+//   - get_free_busy: Busy and free times of CalDAV/Google calendars (with -free-busy-calendars)
+//   - save/list/delete_participant_group: Saved meeting participant groups
+//   - check_clock_accuracy: Host clock offset and jitter against the -ntp-servers
+//   - get_precise_time: Nanosecond wall time, a monotonic reading and the clock resolution
+//   - compare_timestamps: Sort timestamps from several zones, flag simultaneous ones, report gaps
+//   - get_world_times: Local time in any list of cities or zones, with day differences
+//   - fiscal_quarter_of: Fiscal year, quarter, period and week of a date
+//   - fiscal_period_boundaries: Quarter or period dates of a fiscal year
+//   - get_shift_schedule: Active shift and upcoming handovers of a shift pattern
 //
 // Transport Modes:
 //   - stdio: For desktop clients like Claude Desktop (default)
@@ -26,6 +56,7 @@
 // Authentication:
 //   Optional Bearer token authentication for SSE and HTTP transports.
 //   Use -auth-token flag or AUTH_TOKEN environment variable.
+//   -auth-tokens-file adds named tokens limited to some tools and REST paths.
 //
 // Usage Examples:
 //
@@ -50,6 +81,21 @@
 //   # Or using environment variable:
 //   AUTH_TOKEN=secret123 ./fast-time-server -transport=sse
 //
+//   # SSE with keep-alive pings and stale connection reaping
+//   ./fast-time-server -transport=sse -sse-keepalive=15s -sse-idle-timeout=2m
+//
+//   # Timezone aliases from a file plus admin-managed ones stored in SQLite
+//   ./fast-time-server -transport=sse -aliases=aliases.json -db=fast-time.sqlite -admin-token=admin
+//
+//   # SSE with world-time pushes to resource subscribers every 30 seconds
+//   ./fast-time-server -transport=sse -resource-push-interval=30s
+//
+//   # Behind the MCP gateway or a load balancer: log the real client address
+//   ./fast-time-server -transport=sse -trusted-proxies=10.0.0.0/8,127.0.0.1
+//
+//   # Only accept connections from internal networks
+//   ./fast-time-server -transport=dual -allow-ips=10.0.0.0/8,192.168.0.0/16 -deny-ips=10.66.0.0/16
+//
 //   # 3) HTTP transport (for REST-style access)
 //   # Basic HTTP server
 //   ./fast-time-server -transport=http
@@ -66,6 +112,9 @@
 //   ./fast-time-server -transport=rest -port=8080
 //   # REST API at /api/v1/* with OpenAPI docs at /api/v1/docs
 //
+//   # 6) PIPE mode (stdio protocol on \\.\pipe\fast-time-server or a Unix socket)
+//   ./fast-time-server -transport=pipe -pipe=/run/fast-time.sock
+//
 // Endpoint URLs:
 //
 //   SSE Transport:
@@ -95,6 +144,8 @@
 //     Health:        http://localhost:8080/health
 //     Version:       http://localhost:8080/version
 //
+//   All HTTP transports also serve the Kubernetes probes /livez and /readyz.
+//
 // Authentication Headers:
 //   When auth-token is configured, include in requests:
 //     Authorization: Bearer <token>
@@ -149,705 +200,148 @@
 //     -H "Content-Type: application/json" \
 //     -d '{"jsonrpc":"2.0","method":"initialize","params":{"clientInfo":{"name":"test","version":"1.0"}},"id":1}'
 //
+// Debug Endpoints (with -debug, require the admin token):
+//     Profiling: http://localhost:8080/debug/pprof/
+//     Runtime:   http://localhost:8080/debug/vars
+//
+// Admin Endpoints (with an admin token, require it):
+//     Config:    http://localhost:8080/admin/config
+//     Sessions:  http://localhost:8080/admin/sessions
+//     Stats:     http://localhost:8080/admin/stats/tools
+//     Calendars: http://localhost:8080/admin/calendars
+//     Log level: http://localhost:8080/admin/log-level
+//     Tokens:    http://localhost:8080/admin/tokens/reload (POST)
+//     Dashboard: http://localhost:8080/dashboard
+//
 // Environment Variables:
-//   AUTH_TOKEN - Bearer token for authentication (overrides -auth-token flag)
+//   AUTH_TOKEN  - Bearer token for authentication (overrides -auth-token flag)
+//   ADMIN_TOKEN - Bearer token for admin/debug endpoints (overrides -admin-token flag)
 //
 // -------------------------------------------------------------------
 
 package main
 
 import (
-    "bufio"
     "context"
-    "encoding/json"
     "flag"
     "fmt"
-    "io"
-    "log"
-    "net"
-    "net/http"
     "os"
-    "strings"
-    "sync"
-    "time"
+    "os/signal"
+    "syscall"
 
-    "github.com/mark3labs/mcp-go/mcp"
-    "github.com/mark3labs/mcp-go/server"
+    "fast-time-server/fasttime"
 )
 
 /* ------------------------------------------------------------------ */
-/*                             constants                              */
-/* ------------------------------------------------------------------ */
-
-const (
-    appName    = "fast-time-server"
-    appVersion = "1.5.0"
-
-    // Default values
-    defaultPort     = 8080
-    defaultListen   = "0.0.0.0"
-    defaultLogLevel = "info"
-
-    // Environment variables
-    envAuthToken = "AUTH_TOKEN"
-)
-
-/* ------------------------------------------------------------------ */
-/*                             logging                                */
-/* ------------------------------------------------------------------ */
-
-// logLvl represents logging verbosity levels
-type logLvl int
-
-const (
-    logNone logLvl = iota
-    logError
-    logWarn
-    logInfo
-    logDebug
-)
-
-var (
-    curLvl = logInfo
-    logger = log.New(os.Stderr, "", log.LstdFlags)
-)
-
-// parseLvl converts a string log level to logLvl type
-func parseLvl(s string) logLvl {
-    switch strings.ToLower(s) {
-    case "debug":
-        return logDebug
-    case "info":
-        return logInfo
-    case "warn", "warning":
-        return logWarn
-    case "error":
-        return logError
-    case "none", "off", "silent":
-        return logNone
-    default:
-        return logInfo
-    }
-}
-
-// logAt logs a message if the current log level permits
-func logAt(l logLvl, f string, v ...any) {
-    if curLvl >= l {
-        logger.Printf(f, v...)
-    }
-}
-
-/* ------------------------------------------------------------------ */
-/*                    version / health helpers                        */
-/* ------------------------------------------------------------------ */
-
-// versionJSON returns server version information as JSON
-func versionJSON() string {
-    return fmt.Sprintf(`{"name":%q,"version":%q,"mcp_version":"1.0"}`, appName, appVersion)
-}
-
-// healthJSON returns server health status as JSON
-func healthJSON() string {
-    return fmt.Sprintf(`{"status":"healthy","uptime_seconds":%d}`, int(time.Since(startTime).Seconds()))
-}
-
-var startTime = time.Now()
-
-/* ------------------------------------------------------------------ */
-/*                         timezone cache                             */
-/* ------------------------------------------------------------------ */
-
-// tzCache stores loaded time.Location objects to avoid repeated parsing
-var tzCache sync.Map
-
-// loadLocation loads a timezone location, using cache when possible
-func loadLocation(name string) (*time.Location, error) {
-    // Check cache first
-    if loc, ok := tzCache.Load(name); ok {
-        return loc.(*time.Location), nil
-    }
-
-    // Load from system
-    loc, err := time.LoadLocation(name)
-    if err != nil {
-        return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
-    }
-
-    // Cache for future use
-    tzCache.Store(name, loc)
-    return loc, nil
-}
-
-/* ------------------------------------------------------------------ */
-/*                       resource handlers                            */
-/* ------------------------------------------------------------------ */
-
-// handleTimezoneInfo returns comprehensive timezone information
-func handleTimezoneInfo(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-    data := map[string]interface{}{
-        "timezones": []map[string]interface{}{
-            {
-                "id":           "America/New_York",
-                "name":         "Eastern Time",
-                "offset":       "-05:00",
-                "dst":          true,
-                "abbreviation": "EST/EDT",
-                "major_cities": []string{"New York", "Toronto", "Montreal"},
-                "population":   141000000,
-            },
-            {
-                "id":           "America/Chicago",
-                "name":         "Central Time",
-                "offset":       "-06:00",
-                "dst":          true,
-                "abbreviation": "CST/CDT",
-                "major_cities": []string{"Chicago", "Houston", "Mexico City"},
-                "population":   110000000,
-            },
-            {
-                "id":           "America/Denver",
-                "name":         "Mountain Time",
-                "offset":       "-07:00",
-                "dst":          true,
-                "abbreviation": "MST/MDT",
-                "major_cities": []string{"Denver", "Phoenix", "Calgary"},
-                "population":   35000000,
-            },
-            {
-                "id":           "America/Los_Angeles",
-                "name":         "Pacific Time",
-                "offset":       "-08:00",
-                "dst":          true,
-                "abbreviation": "PST/PDT",
-                "major_cities": []string{"Los Angeles", "San Francisco", "Seattle"},
-                "population":   53000000,
-            },
-            {
-                "id":           "Europe/London",
-                "name":         "Greenwich Mean Time",
-                "offset":       "+00:00",
-                "dst":          true,
-                "abbreviation": "GMT/BST",
-                "major_cities": []string{"London", "Dublin", "Lisbon"},
-                "population":   67000000,
-            },
-            {
-                "id":           "Europe/Paris",
-                "name":         "Central European Time",
-                "offset":       "+01:00",
-                "dst":          true,
-                "abbreviation": "CET/CEST",
-                "major_cities": []string{"Paris", "Madrid", "Rome"},
-                "population":   250000000,
-            },
-            {
-                "id":           "Europe/Moscow",
-                "name":         "Moscow Time",
-                "offset":       "+03:00",
-                "dst":          false,
-                "abbreviation": "MSK",
-                "major_cities": []string{"Moscow", "Istanbul", "Nairobi"},
-                "population":   250000000,
-            },
-            {
-                "id":           "Asia/Dubai",
-                "name":         "Gulf Standard Time",
-                "offset":       "+04:00",
-                "dst":          false,
-                "abbreviation": "GST",
-                "major_cities": []string{"Dubai", "Abu Dhabi", "Muscat"},
-                "population":   65000000,
-            },
-            {
-                "id":           "Asia/Shanghai",
-                "name":         "China Standard Time",
-                "offset":       "+08:00",
-                "dst":          false,
-                "abbreviation": "CST",
-                "major_cities": []string{"Shanghai", "Beijing", "Hong Kong"},
-                "population":   1400000000,
-            },
-            {
-                "id":           "Asia/Tokyo",
-                "name":         "Japan Standard Time",
-                "offset":       "+09:00",
-                "dst":          false,
-                "abbreviation": "JST",
-                "major_cities": []string{"Tokyo", "Osaka", "Yokohama"},
-                "population":   127000000,
-            },
-            {
-                "id":           "Australia/Sydney",
-                "name":         "Australian Eastern Time",
-                "offset":       "+10:00",
-                "dst":          true,
-                "abbreviation": "AEST/AEDT",
-                "major_cities": []string{"Sydney", "Melbourne", "Brisbane"},
-                "population":   25000000,
-            },
-        },
-        "timezone_groups": map[string][]string{
-            "us_timezones":     []string{"America/New_York", "America/Chicago", "America/Denver", "America/Los_Angeles"},
-            "europe_timezones": []string{"Europe/London", "Europe/Paris", "Europe/Berlin", "Europe/Moscow"},
-            "asia_timezones":   []string{"Asia/Tokyo", "Asia/Shanghai", "Asia/Singapore", "Asia/Dubai"},
-        },
-    }
-
-    jsonData, err := json.Marshal(data)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal timezone data: %w", err)
-    }
-
-    logAt(logInfo, "resource: timezone info requested")
-    return []mcp.ResourceContents{
-        mcp.TextResourceContents{
-            URI:      "timezone://info",
-            MIMEType: "application/json",
-            Text:     string(jsonData),
-        },
-    }, nil
-}
-
-// handleCurrentWorldTimes returns current time in major cities
-func handleCurrentWorldTimes(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-    cities := map[string]string{
-        "New York":     "America/New_York",
-        "Los Angeles":  "America/Los_Angeles",
-        "London":       "Europe/London",
-        "Paris":        "Europe/Paris",
-        "Tokyo":        "Asia/Tokyo",
-        "Sydney":       "Australia/Sydney",
-        "Dubai":        "Asia/Dubai",
-        "Singapore":    "Asia/Singapore",
-        "Mumbai":       "Asia/Kolkata",
-        "Hong Kong":    "Asia/Hong_Kong",
-    }
-
-    times := make(map[string]string)
-    now := time.Now()
-
-    for city, tz := range cities {
-        loc, err := loadLocation(tz)
-        if err != nil {
-            times[city] = "Error loading timezone"
-            continue
-        }
-        localTime := now.In(loc)
-        times[city] = localTime.Format("2006-01-02 15:04:05 MST")
-    }
-
-    data := map[string]interface{}{
-        "last_updated": now.UTC().Format(time.RFC3339),
-        "times":        times,
-    }
-
-    jsonData, err := json.Marshal(data)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal world times: %w", err)
-    }
-
-    logAt(logInfo, "resource: current world times requested")
-    return []mcp.ResourceContents{
-        mcp.TextResourceContents{
-            URI:      "time://current/world",
-            MIMEType: "application/json",
-            Text:     string(jsonData),
-        },
-    }, nil
-}
-
-// handleTimeFormats returns examples of supported time formats
-func handleTimeFormats(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-    data := map[string]interface{}{
-        "input_formats": []string{
-            "2006-01-02 15:04:05",
-            "2006-01-02T15:04:05Z",
-            "2006-01-02T15:04:05-07:00",
-            "Jan 2, 2006 3:04 PM",
-            "Monday, January 2, 2006",
-            "02/01/2006 15:04",
-        },
-        "output_formats": map[string]string{
-            "iso8601":        "2006-01-02T15:04:05Z07:00",
-            "rfc3339":        "2006-01-02T15:04:05Z",
-            "rfc822":         "Mon, 02 Jan 2006 15:04:05 MST",
-            "unix":           "1136214245",
-            "human_readable": "Monday, January 2, 2006 at 3:04 PM",
-            "short":          "1/2/06 3:04 PM",
-        },
-        "examples": []map[string]string{
-            {
-                "format":      "ISO 8601",
-                "example":     "2024-01-15T14:30:00-05:00",
-                "description": "Standard international format with timezone",
-            },
-            {
-                "format":      "Unix Timestamp",
-                "example":     "1705339800",
-                "description": "Seconds since January 1, 1970 UTC",
-            },
-            {
-                "format":      "RFC 3339",
-                "example":     "2024-01-15T14:30:00Z",
-                "description": "Internet standard format",
-            },
-        },
-    }
-
-    jsonData, err := json.Marshal(data)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal format data: %w", err)
-    }
-
-    logAt(logInfo, "resource: time formats requested")
-    return []mcp.ResourceContents{
-        mcp.TextResourceContents{
-            URI:      "time://formats",
-            MIMEType: "application/json",
-            Text:     string(jsonData),
-        },
-    }, nil
-}
-
-// handleBusinessHours returns standard business hours across regions
-func handleBusinessHours(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-    data := map[string]interface{}{
-        "regions": map[string]interface{}{
-            "north_america": map[string]interface{}{
-                "standard_hours": "9:00 AM - 5:00 PM",
-                "lunch_break":    "12:00 PM - 1:00 PM",
-                "working_days":   []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
-            },
-            "europe": map[string]interface{}{
-                "standard_hours": "9:00 AM - 6:00 PM",
-                "lunch_break":    "1:00 PM - 2:00 PM",
-                "working_days":   []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
-            },
-            "asia_pacific": map[string]interface{}{
-                "standard_hours": "9:00 AM - 6:00 PM",
-                "lunch_break":    "12:00 PM - 1:00 PM",
-                "working_days":   []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
-            },
-            "middle_east": map[string]interface{}{
-                "standard_hours": "9:00 AM - 6:00 PM",
-                "lunch_break":    "1:00 PM - 2:00 PM",
-                "working_days":   []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday"},
-            },
-        },
-        "holidays": map[string]interface{}{
-            "global": []string{"New Year's Day", "Christmas Day"},
-            "regional": map[string][]string{
-                "us":    []string{"Independence Day", "Thanksgiving", "Memorial Day", "Labor Day"},
-                "uk":    []string{"Boxing Day", "Spring Bank Holiday", "Summer Bank Holiday"},
-                "japan": []string{"Golden Week", "Obon", "New Year Holiday"},
-                "china": []string{"Spring Festival", "Mid-Autumn Festival", "National Day"},
-            },
-        },
-    }
-
-    jsonData, err := json.Marshal(data)
-    if err != nil {
-        return nil, fmt.Errorf("failed to marshal business hours: %w", err)
-    }
-
-    logAt(logInfo, "resource: business hours requested")
-    return []mcp.ResourceContents{
-        mcp.TextResourceContents{
-            URI:      "time://business-hours",
-            MIMEType: "application/json",
-            Text:     string(jsonData),
-        },
-    }, nil
-}
-
-/* ------------------------------------------------------------------ */
-/*                        prompt handlers                             */
-/* ------------------------------------------------------------------ */
-
-// handleCompareTimezonesPrompt compares times across multiple timezones
-func handleCompareTimezonesPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-    timezones := req.Params.Arguments["timezones"]
-    referenceTime := req.Params.Arguments["reference_time"]
-
-    if timezones == "" {
-        return nil, fmt.Errorf("timezones parameter is required")
-    }
-
-    tzList := strings.Split(timezones, ",")
-    for i := range tzList {
-        tzList[i] = strings.TrimSpace(tzList[i])
-    }
-
-    var baseTime time.Time
-    if referenceTime != "" {
-        var err error
-        baseTime, err = time.Parse(time.RFC3339, referenceTime)
-        if err != nil {
-            baseTime = time.Now()
-        }
-    } else {
-        baseTime = time.Now()
-    }
-
-    var promptText strings.Builder
-    promptText.WriteString("Compare the current time across these time zones:\n")
-    for _, tz := range tzList {
-        promptText.WriteString(fmt.Sprintf("- %s\n", tz))
-    }
-    promptText.WriteString(fmt.Sprintf("\nReference time: %s\n\n", baseTime.Format(time.RFC3339)))
-    promptText.WriteString("Show:\n")
-    promptText.WriteString("1. The current time in each timezone\n")
-    promptText.WriteString("2. The time difference from the first timezone\n")
-    promptText.WriteString("3. Whether it's business hours (9 AM - 5 PM)\n")
-    promptText.WriteString("4. The day of the week\n")
-
-    logAt(logInfo, "prompt: compare_timezones for %s", timezones)
-    return &mcp.GetPromptResult{
-        Description: "Time zone comparison analysis",
-        Messages: []mcp.PromptMessage{
-            {
-                Role:    mcp.RoleUser,
-                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
-            },
-        },
-    }, nil
-}
-
-// handleScheduleMeetingPrompt finds optimal meeting times
-func handleScheduleMeetingPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-    participants := req.Params.Arguments["participants"]
-    duration := req.Params.Arguments["duration"]
-    if duration == "" {
-        duration = "60"
-    }
-    preferredHours := req.Params.Arguments["preferred_hours"]
-    if preferredHours == "" {
-        preferredHours = "9 AM - 5 PM"
-    }
-    dateRange := req.Params.Arguments["date_range"]
-    if dateRange == "" {
-        dateRange = "next 7 days"
-    }
-
-    if participants == "" {
-        return nil, fmt.Errorf("participants parameter is required")
-    }
-
-    partList := strings.Split(participants, ",")
-    for i := range partList {
-        partList[i] = strings.TrimSpace(partList[i])
-    }
-
-    var promptText strings.Builder
-    promptText.WriteString("Find the best meeting time for participants in these locations:\n")
-    for _, p := range partList {
-        promptText.WriteString(fmt.Sprintf("- %s\n", p))
-    }
-    promptText.WriteString("\nMeeting details:\n")
-    promptText.WriteString(fmt.Sprintf("- Duration: %s minutes\n", duration))
-    promptText.WriteString(fmt.Sprintf("- Preferred hours: %s local time for each participant\n", preferredHours))
-    promptText.WriteString(fmt.Sprintf("- Date range: %s\n\n", dateRange))
-    promptText.WriteString("Consider:\n")
-    promptText.WriteString("1. Business hours overlap across all timezones\n")
-    promptText.WriteString("2. Avoid very early morning (before 8 AM) or late evening (after 7 PM)\n")
-    promptText.WriteString("3. Account for any timezone transitions (DST changes)\n")
-    promptText.WriteString("4. Suggest top 3 meeting times with pros/cons for each\n")
-
-    logAt(logInfo, "prompt: schedule_meeting for %s", participants)
-    return &mcp.GetPromptResult{
-        Description: "Meeting scheduler analysis",
-        Messages: []mcp.PromptMessage{
-            {
-                Role:    mcp.RoleUser,
-                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
-            },
-        },
-    }, nil
-}
-
-// handleConvertTimeDetailedPrompt converts time with detailed context
-func handleConvertTimeDetailedPrompt(_ context.Context, req mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-    timeStr := req.Params.Arguments["time"]
-    fromTz := req.Params.Arguments["from_timezone"]
-    toTzs := req.Params.Arguments["to_timezones"]
-    includeContext := req.Params.Arguments["include_context"]
-    if includeContext == "" {
-        includeContext = "false"
-    }
-
-    if timeStr == "" || fromTz == "" || toTzs == "" {
-        return nil, fmt.Errorf("time, from_timezone, and to_timezones are required")
-    }
-
-    tzList := strings.Split(toTzs, ",")
-    for i := range tzList {
-        tzList[i] = strings.TrimSpace(tzList[i])
-    }
-
-    var promptText strings.Builder
-    promptText.WriteString(fmt.Sprintf("Convert %s from %s to:\n", timeStr, fromTz))
-    for _, tz := range tzList {
-        promptText.WriteString(fmt.Sprintf("- %s\n", tz))
-    }
-
-    if includeContext == "true" {
-        promptText.WriteString("\nAlso provide:\n")
-        promptText.WriteString("1. Day of week in each timezone\n")
-        promptText.WriteString("2. Whether it's a business day\n")
-        promptText.WriteString("3. Any relevant holidays or observances\n")
-        promptText.WriteString("4. Time until/since this moment (relative to now)\n")
-        promptText.WriteString("5. Sunrise/sunset times if significantly different days\n")
-    }
-
-    logAt(logInfo, "prompt: convert_time_detailed from %s to %s", fromTz, toTzs)
-    return &mcp.GetPromptResult{
-        Description: "Detailed time conversion",
-        Messages: []mcp.PromptMessage{
-            {
-                Role:    mcp.RoleUser,
-                Content: mcp.TextContent{Type: "text", Text: promptText.String()},
-            },
-        },
-    }, nil
-}
-
-/* ------------------------------------------------------------------ */
-/*                         tool handlers                              */
+/*                              main                                  */
 /* ------------------------------------------------------------------ */
 
-// handleGetSystemTime returns the current time in the specified timezone
-func handleGetSystemTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-    // Get timezone parameter with UTC as default
-    tz := req.GetString("timezone", "UTC")
-
-    // Load timezone location
-    loc, err := loadLocation(tz)
-    if err != nil {
-        return mcp.NewToolResultError(err.Error()), nil
-    }
-
-    // Get current time in the specified timezone
-    now := time.Now().In(loc).Format(time.RFC3339)
-
-    logAt(logInfo, "get_system_time: timezone=%s result=%s", tz, now)
-    return mcp.NewToolResultText(now), nil
-}
-
-// handleConvertTime converts time between different timezones
-func handleConvertTime(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-    // Get required parameters
-    timeStr, err := req.RequireString("time")
-    if err != nil {
-        return mcp.NewToolResultError("time parameter is required"), nil
-    }
-
-    sourceTimezone, err := req.RequireString("source_timezone")
-    if err != nil {
-        return mcp.NewToolResultError("source_timezone parameter is required"), nil
-    }
-
-    targetTimezone, err := req.RequireString("target_timezone")
-    if err != nil {
-        return mcp.NewToolResultError("target_timezone parameter is required"), nil
-    }
-
-    // Load source timezone
-    sourceLoc, err := loadLocation(sourceTimezone)
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("invalid source timezone: %v", err)), nil
-    }
-
-    // Load target timezone
-    targetLoc, err := loadLocation(targetTimezone)
-    if err != nil {
-        return mcp.NewToolResultError(fmt.Sprintf("invalid target timezone: %v", err)), nil
-    }
-
-    // Parse the time string in the source timezone
-    parsedTime, err := time.ParseInLocation(time.RFC3339, timeStr, sourceLoc)
-    if err != nil {
-        // Try other common formats
-        for _, format := range []string{
-            "2006-01-02 15:04:05",
-            "2006-01-02T15:04:05",
-            "2006-01-02",
-        } {
-            if parsedTime, err = time.ParseInLocation(format, timeStr, sourceLoc); err == nil {
-                break
-            }
+func main() {
+    /* ------------------------- subcommands ------------------------ */
+    doctor := false
+    var withFlags flagSubcommand
+    if len(os.Args) > 1 {
+        if run, ok := subcommands[os.Args[1]]; ok {
+            os.Exit(run(os.Args[2:], os.Stdout, os.Stderr))
         }
-        if err != nil {
-            return mcp.NewToolResultError(fmt.Sprintf("invalid time format: %v", err)), nil
+        // These need the flags defined below
+        withFlags = flagSubcommands[os.Args[1]]
+        // doctor takes the server's own flags, so it runs after flag.Parse
+        if os.Args[1] == "doctor" {
+            doctor = true
+            os.Args = append(os.Args[:1:1], os.Args[2:]...)
         }
     }
 
-    // Convert to target timezone
-    convertedTime := parsedTime.In(targetLoc).Format(time.RFC3339)
-
-    logAt(logInfo, "convert_time: %s from %s to %s = %s", timeStr, sourceTimezone, targetTimezone, convertedTime)
-    return mcp.NewToolResultText(convertedTime), nil
-}
-
-/* ------------------------------------------------------------------ */
-/*                       authentication middleware                    */
-/* ------------------------------------------------------------------ */
-
-// authMiddleware creates a middleware that checks for Bearer token authentication
-func authMiddleware(token string, next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        // Skip auth for health and version endpoints
-        if r.URL.Path == "/health" || r.URL.Path == "/version" {
-            next.ServeHTTP(w, r)
-            return
-        }
-
-        // Get Authorization header
-        authHeader := r.Header.Get("Authorization")
-        if authHeader == "" {
-            logAt(logWarn, "missing authorization header from %s for %s", r.RemoteAddr, r.URL.Path)
-            w.Header().Set("WWW-Authenticate", `Bearer realm="MCP Server"`)
-            http.Error(w, "Authorization required", http.StatusUnauthorized)
-            return
-        }
-
-        // Check Bearer token format
-        const bearerPrefix = "Bearer "
-        if !strings.HasPrefix(authHeader, bearerPrefix) {
-            logAt(logWarn, "invalid authorization format from %s", r.RemoteAddr)
-            http.Error(w, "Invalid authorization format", http.StatusUnauthorized)
-            return
-        }
-
-        // Verify token
-        providedToken := strings.TrimPrefix(authHeader, bearerPrefix)
-        if providedToken != token {
-            logAt(logWarn, "invalid token from %s", r.RemoteAddr)
-            http.Error(w, "Invalid token", http.StatusUnauthorized)
-            return
-        }
-
-        // Token valid, proceed with request
-        logAt(logDebug, "authenticated request from %s to %s", r.RemoteAddr, r.URL.Path)
-        next.ServeHTTP(w, r)
-    })
-}
-
-/* ------------------------------------------------------------------ */
-/*                              main                                  */
-/* ------------------------------------------------------------------ */
-
-func main() {
     /* ---------------------------- flags --------------------------- */
-    var (
-        transport  = flag.String("transport", "stdio", "Transport: stdio | sse | http | dual | rest")
-        addrFlag   = flag.String("addr", "", "Full listen address (host:port) - overrides -listen/-port")
-        listenHost = flag.String("listen", defaultListen, "Listen interface for sse/http")
-        port       = flag.Int("port", defaultPort, "TCP port for sse/http")
-        publicURL  = flag.String("public-url", "", "External base URL advertised to SSE clients")
-        authToken  = flag.String("auth-token", "", "Bearer token for authentication (SSE/HTTP only)")
-        logLevel   = flag.String("log-level", defaultLogLevel, "Logging level: debug|info|warn|error|none")
-        showHelp   = flag.Bool("help", false, "Show help message")
-    )
+    cfg := fasttime.DefaultConfig()
+    flag.StringVar(&cfg.Transport, "transport", cfg.Transport, "Transport: stdio | sse | http | dual | rest | pipe")
+    flag.StringVar(&cfg.Addr, "addr", cfg.Addr, "Full listen address (host:port) - overrides -listen/-port")
+    flag.StringVar(&cfg.Listen, "listen", cfg.Listen, "Listen interface for sse/http")
+    flag.IntVar(&cfg.Port, "port", cfg.Port, "TCP port for sse/http")
+    flag.StringVar(&cfg.Pipe, "pipe", cfg.Pipe, "Named pipe (Windows) or Unix socket for -transport=pipe (default "+appName+" in the pipe or temp directory)")
+    flag.StringVar(&cfg.PublicURL, "public-url", cfg.PublicURL, "External base URL advertised to SSE clients")
+    flag.StringVar(&cfg.AuthToken, "auth-token", cfg.AuthToken, "Bearer token for authentication (SSE/HTTP only)")
+    flag.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Logging level: debug|info|warn|error|none")
+    flag.StringVar(&cfg.LogOutput, "log-output", cfg.LogOutput, "Log destination: stderr|file|syslog|journald (default file with -log-file, else stderr)")
+    flag.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "Write logs to this file instead of stderr; SIGUSR1 reopens it")
+    flag.Int64Var(&cfg.LogMaxSize, "log-max-size", cfg.LogMaxSize, "Rotate the log file at this size in bytes (0 disables)")
+    flag.DurationVar(&cfg.LogMaxAge, "log-max-age", cfg.LogMaxAge, "Rotate the log file once it is this old, e.g. 24h (0 disables)")
+    flag.IntVar(&cfg.LogMaxFiles, "log-max-files", cfg.LogMaxFiles, "Rotated log files to keep (0 keeps all)")
+    flag.DurationVar(&cfg.SSEKeepAlive, "sse-keepalive", cfg.SSEKeepAlive, "Interval between SSE keep-alive pings (0 disables)")
+    flag.DurationVar(&cfg.SSEIdleTimeout, "sse-idle-timeout", cfg.SSEIdleTimeout, "Close SSE connections idle longer than this (0 disables)")
+    flag.StringVar(&cfg.Aliases, "aliases", cfg.Aliases, "JSON file of timezone aliases, e.g. {\"HQ\": \"Europe/Berlin\"}")
+    flag.StringVar(&cfg.Cities, "cities", cfg.Cities, "YAML or JSON file of the world-clock cities and their zones")
+    flag.StringVar(&cfg.FiscalCalendars, "fiscal-calendars", cfg.FiscalCalendars, "YAML or JSON file of fiscal calendar definitions")
+    flag.StringVar(&cfg.FiscalCalendar, "fiscal-calendar", cfg.FiscalCalendar, "Fiscal calendar used when a tool names none")
+    flag.StringVar(&cfg.DB, "db", cfg.DB, "SQLite database for aliases, participant groups and holiday calendars (empty = in memory)")
+    flag.DurationVar(&cfg.MaxSleep, "max-sleep", cfg.MaxSleep, "Longest wait accepted by the sleep and wait_until tools")
+    flag.DurationVar(&cfg.ResourcePushInterval, "resource-push-interval", cfg.ResourcePushInterval, "Interval between pushes to resource subscribers (0 = on minute boundaries)")
+    flag.BoolVar(&cfg.Compress, "compress", cfg.Compress, "Compress HTTP responses with gzip or brotli when accepted (SSE streams excluded)")
+    flag.BoolVar(&cfg.GraphQL, "graphql", cfg.GraphQL, "Serve a read-only GraphQL API at /graphql on the dual and rest transports")
+    flag.BoolVar(&cfg.Debug, "debug", cfg.Debug, "Expose /debug/pprof/* and /debug/vars (requires -admin-token)")
+    flag.StringVar(&cfg.AdminToken, "admin-token", cfg.AdminToken, "Bearer token for admin/debug endpoints")
+    flag.StringVar(&cfg.AuthTokenFile, "auth-token-file", cfg.AuthTokenFile, "File holding the Bearer token; re-read by POST /admin/tokens/reload")
+    flag.StringVar(&cfg.AdminTokenFile, "admin-token-file", cfg.AdminTokenFile, "File holding the admin token; re-read by POST /admin/tokens/reload")
+    flag.StringVar(&cfg.AuthTokensFile, "auth-tokens-file", cfg.AuthTokensFile, "JSON file of named Bearer tokens limited to some tools and REST paths")
+    flag.StringVar(&cfg.AuditLog, "audit-log", cfg.AuditLog, "Append a record of every tool call to this JSON lines file (.db/.sqlite = SQLite)")
+    flag.Int64Var(&cfg.AuditMaxSize, "audit-max-size", cfg.AuditMaxSize, "Rotate the audit log file at this size in bytes (0 disables)")
+    flag.DurationVar(&cfg.AuditRetention, "audit-retention", cfg.AuditRetention, "Delete rotated audit files and rows older than this (0 keeps everything)")
+    flag.StringVar(&cfg.Record, "record", cfg.Record, "Append every MCP request and its response to this JSON lines file")
+    flag.StringVar(&cfg.Replay, "replay", cfg.Replay, "Answer tool calls, resource reads and prompts from a -record file")
+    flag.StringVar(&cfg.MockTime, "mock-time", cfg.MockTime, "Freeze the clock at this RFC3339 time for deterministic answers (CI, demos)")
+    flag.StringVar(&cfg.TimeOffset, "time-offset", cfg.TimeOffset, "Shift the clock by this duration, e.g. +3h or +30d, to simulate future dates")
+    flag.StringVar(&cfg.NTPServers, "ntp-servers", cfg.NTPServers, "Comma-separated NTP servers queried by check_clock_accuracy")
+    flag.DurationVar(&cfg.NTPMaxDrift, "ntp-max-drift", cfg.NTPMaxDrift, "Fail /readyz when the host clock is further than this from NTP (0 disables)")
+    flag.StringVar(&cfg.TzdataDir, "tzdata-dir", cfg.TzdataDir, "Load zones from this zoneinfo directory instead of the system database")
+    flag.DurationVar(&cfg.TzdataCheckInterval, "tzdata-check-interval", cfg.TzdataCheckInterval, "How often -tzdata-dir is checked for a new release (0 disables)")
+    flag.IntVar(&cfg.TzCacheSize, "tz-cache-size", cfg.TzCacheSize, "Timezone names kept in the lookup cache (0 disables)")
+    flag.StringVar(&cfg.TrustedProxies, "trusted-proxies", cfg.TrustedProxies, "Comma-separated IPs/CIDRs whose X-Forwarded-For/X-Real-IP headers are trusted")
+    flag.StringVar(&cfg.AllowIPs, "allow-ips", cfg.AllowIPs, "Comma-separated IPs/CIDRs allowed to connect (empty = any)")
+    flag.StringVar(&cfg.DenyIPs, "deny-ips", cfg.DenyIPs, "Comma-separated IPs/CIDRs refused before authentication")
+    flag.StringVar(&cfg.IPACLFile, "ip-acl-file", cfg.IPACLFile, "JSON file of allowed/denied networks, e.g. {\"allow\": [\"10.0.0.0/8\"]}")
+    flag.Int64Var(&cfg.MaxBodySize, "max-body-size", cfg.MaxBodySize, "Largest accepted request body in bytes (0 disables)")
+    flag.DurationVar(&cfg.RequestTimeout, "request-timeout", cfg.RequestTimeout, "Answer 408 when a request takes longer than this; SSE streams excluded (0 disables)")
+    flag.DurationVar(&cfg.ToolTimeout, "tool-timeout", cfg.ToolTimeout, "Fail a tool call with TIMEOUT when it takes longer than this, on every transport (0 disables)")
+    flag.IntVar(&cfg.WorkerPool, "worker-pool", cfg.WorkerPool, "CPU-heavy tool calls and batch conversions run at once (0 disables the pool)")
+    flag.IntVar(&cfg.WorkerQueue, "worker-queue", cfg.WorkerQueue, "Heavy calls that may wait for the worker pool before BUSY/429 is answered")
+    flag.DurationVar(&cfg.SlowRequest, "slow-request", cfg.SlowRequest, "Log requests and tool calls slower than this and keep them for /admin/slow (0 disables)")
+    flag.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", cfg.ReadHeaderTimeout, "Time allowed to read request headers")
+    flag.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "Keep-alive connections idle longer than this are closed")
+    flag.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "Time allowed to write a response; SSE streams excluded (0 disables)")
+    flag.IntVar(&cfg.MaxHeaderBytes, "max-header-bytes", cfg.MaxHeaderBytes, "Largest accepted request header size in bytes")
+    flag.IntVar(&cfg.MaxConnections, "max-connections", cfg.MaxConnections, "Answer 503 while more TCP connections are open than this (0 = unlimited)")
+    flag.IntVar(&cfg.MaxSSEClients, "max-sse-clients", cfg.MaxSSEClients, "Answer 503 to new SSE streams beyond this many (0 = unlimited)")
+    flag.DurationVar(&cfg.DrainTimeout, "drain-timeout", cfg.DrainTimeout, "After a SIGUSR2 upgrade, how long the old process lets connections drain")
+    flag.StringVar(&cfg.DefaultTimezone, "default-timezone", cfg.DefaultTimezone, "Timezone used by get_system_time and GET /api/v1/time when none is given")
+    flag.StringVar(&cfg.ErrorFormat, "error-format", cfg.ErrorFormat, "Error body style: classic (message plus error_code) or structured ({error: {code, message, field, hint}})")
+    flag.DurationVar(&cfg.IdempotencyTTL, "idempotency-ttl", cfg.IdempotencyTTL, "How long the response to a REST POST with an Idempotency-Key is replayed to retries (0 disables)")
+    flag.StringVar(&cfg.WebhookSecret, "webhook-secret", cfg.WebhookSecret, "HMAC-SHA256 key signing scheduled webhook deliveries (X-Fast-Time-Signature)")
+    flag.StringVar(&cfg.WebhookHosts, "webhook-allow-hosts", cfg.WebhookHosts, "Comma-separated hosts (or *.domain patterns) scheduled webhooks may call; empty allows any")
+    flag.BoolVar(&cfg.WebhookPrivate, "webhook-allow-private", cfg.WebhookPrivate, "Let webhooks call loopback, private and link-local addresses (refused by default)")
+    flag.StringVar(&cfg.APIV1Sunset, "api-v1-sunset", cfg.APIV1Sunset, "Deprecate /api/v1: send Deprecation, Sunset (this date, YYYY-MM-DD or RFC3339) and a successor Link to /api/v2")
+    flag.StringVar(&cfg.EnableTools, "enable-tools", cfg.EnableTools, "Comma-separated tools (and prompt:/resource: entries) to expose; others of that kind are hidden")
+    flag.StringVar(&cfg.DisableTools, "disable-tools", cfg.DisableTools, "Comma-separated tools (and prompt:/resource: entries) to hide; wildcards allowed")
+    flag.StringVar(&cfg.I18nLocale, "i18n-locale", cfg.I18nLocale, "Locale for tool, prompt and resource descriptions when the client names none, e.g. de or ja-JP")
+    flag.StringVar(&cfg.I18nDir, "i18n-dir", cfg.I18nDir, "Directory of extra translation catalogs (<locale>.json)")
+    flag.StringVar(&cfg.Plugins, "plugins", cfg.Plugins, "JSON file of extra tools, each run as a command per call with its arguments on stdin")
+    flag.StringVar(&cfg.Downstream, "downstream", cfg.Downstream, "JSON file of downstream MCP servers (stdio, SSE or HTTP) whose tools are served alongside this server's")
+    flag.StringVar(&cfg.FreeBusy, "free-busy-calendars", cfg.FreeBusy, "JSON file of CalDAV and Google calendars read by get_free_busy (registered only with this flag)")
+    flag.DurationVar(&cfg.OutboundTimeout, "outbound-timeout", cfg.OutboundTimeout, "Time allowed for each outgoing HTTP call (calendars, webhooks) unless the feature sets its own")
+    flag.IntVar(&cfg.OutboundRetries, "outbound-retries", cfg.OutboundRetries, "Retries with backoff of outgoing HTTP calls after network errors, 429 and 5xx")
+    flag.DurationVar(&cfg.OutboundCacheTTL, "outbound-cache-ttl", cfg.OutboundCacheTTL, "How long answers of outgoing reads such as free/busy queries are reused (0 disables)")
+    flag.IntVar(&cfg.OutboundBreakerFailures, "outbound-breaker-failures", cfg.OutboundBreakerFailures, "Stop calling a host after this many failures in a row (0 disables)")
+    flag.DurationVar(&cfg.OutboundBreakerCooldown, "outbound-breaker-cooldown", cfg.OutboundBreakerCooldown, "How long calls to a failing host are refused before one is let through")
+    flag.StringVar(&cfg.RequiredBackends, "required-backends", cfg.RequiredBackends, "Comma-separated backends (store, ntp, calendar:NAME) whose failure fails /readyz; others only degrade their features")
+    flag.StringVar(&cfg.OutboundProxy, "outbound-proxy", cfg.OutboundProxy, "HTTP proxy for outgoing calls, e.g. http://proxy:3128 (default: HTTPS_PROXY/HTTP_PROXY)")
+    flag.StringVar(&cfg.Listeners, "listeners", cfg.Listeners, "Comma-separated KIND=ADDR listeners served together, e.g. sse=:8080,rest=:8081,metrics=127.0.0.1:9090 (replaces -transport)")
+    configFile := flag.String("config", "", "YAML or JSON file of flag values; flags on the command line override it")
+    flag.StringVar(&cfg.PIDFile, "pid-file", cfg.PIDFile, "Write the server's PID to this file and refuse to start while it names a running server")
+    daemonMode := flag.Bool("daemon", false, "Start in the background, print the PID and exit once the server is ready (not on Windows)")
+    showHelp := flag.Bool("help", false, "Show help message")
+    if withFlags != nil {
+        os.Exit(withFlags(flag.CommandLine, os.Args[2:], os.Stdout, os.Stderr))
+    }
 
     // Custom usage function
     flag.Usage = func() {
@@ -867,15 +361,28 @@ func main() {
                 ind+"%s -transport=sse -listen=0.0.0.0 -port=8080\n"+
                 ind+"%s -transport=http -addr=127.0.0.1:9090\n"+
                 ind+"%s -transport=dual -port=8080 -auth-token=secret123\n"+
-                ind+"%s -transport=rest -port=8080\n\n"+
+                ind+"%s -transport=rest -port=8080\n"+
+                ind+"%s -transport=http -daemon -pid-file=/run/fast-time-server.pid -log-file=/var/log/fast-time-server.log\n\n"+
                 "MCP Protocol Endpoints:\n"+
                 ind+"SSE:  /sse (events), /messages (messages)\n"+
                 ind+"HTTP: / (single endpoint)\n"+
                 ind+"DUAL: /sse & /messages (SSE), /http (HTTP), /api/v1/* (REST)\n"+
-                ind+"REST: /api/v1/* (REST API only, no MCP)\n\n"+
+                ind+"REST: /api/v1/* (REST API only, no MCP)\n"+
+                ind+"PIPE: stdio protocol on a named pipe (Windows) or Unix socket, one client at a time\n"+
+                ind+"-listeners: several of the above plus metrics (/debug/vars) and admin (/admin/*) on their own ports\n\n"+
+                "Subcommands:\n"+
+                ind+"call [TOOL] [--arg=value ...] - call a tool on a running server (call -h for options)\n"+
+                ind+"repl - interactive client: list and call tools, read resources, trace JSON-RPC\n"+
+                ind+"bridge --to=URL [--auth-token=TOKEN] - relay stdio to a remote SSE or HTTP server, for desktop clients\n"+
+                ind+"doctor [server flags] - check tzdata, ports, TLS, auth, NTP and config files for these flags\n"+
+                ind+"validate-config FILE... - check -config files; -schema prints their JSON Schema\n"+
+                ind+"completion bash|zsh|fish - print a shell completion script\n"+
+                ind+"service install [server flags] | uninstall | start | stop | status - manage the Windows service\n\n"+
                 "Environment Variables:\n"+
-                ind+"AUTH_TOKEN - Bearer token for authentication (overrides -auth-token flag)\n",
-            os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+                ind+"AUTH_TOKEN - Bearer token for authentication (overrides -auth-token flag)\n"+
+                ind+"ADMIN_TOKEN - Bearer token for admin/debug endpoints (overrides -admin-token flag)\n"+
+                ind+"ENABLE_TOOLS / DISABLE_TOOLS - Feature allow/deny lists (override -enable-tools / -disable-tools)\n",
+            os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
     }
 
     flag.Parse()
@@ -884,471 +391,54 @@ func main() {
         flag.Usage()
         os.Exit(0)
     }
-
-    /* ----------------------- configuration setup ------------------ */
-    // Check for auth token in environment variable (overrides flag)
-    if envToken := os.Getenv(envAuthToken); envToken != "" {
-        *authToken = envToken
-        logAt(logDebug, "using auth token from environment variable")
-    }
-
-    /* ------------------------- logging setup ---------------------- */
-    curLvl = parseLvl(*logLevel)
-    if curLvl == logNone {
-        logger.SetOutput(io.Discard)
-    }
-
-    logAt(logDebug, "starting %s %s", appName, appVersion)
-    if *authToken != "" && *transport != "stdio" {
-        logAt(logInfo, "authentication enabled with Bearer token")
-    }
-
-    /* ----------------------- build MCP server --------------------- */
-    // Create server with appropriate options
-    s := server.NewMCPServer(
-        appName,
-        appVersion,
-        server.WithToolCapabilities(false),        // No progress reporting needed
-        server.WithResourceCapabilities(false, true), // Enable resource capabilities (no subscribe, list changed)
-        server.WithPromptCapabilities(true),       // Enable prompt capabilities (list changed)
-        server.WithLogging(),                      // Enable MCP protocol logging
-        server.WithRecovery(),                     // Recover from panics in handlers
-    )
-
-    /* ----------------------- register tools ----------------------- */
-    // Register get_system_time tool
-    getTimeTool := mcp.NewTool("get_system_time",
-        mcp.WithDescription("Get current system time in specified timezone"),
-        mcp.WithTitleAnnotation("Get System Time"),
-        mcp.WithReadOnlyHintAnnotation(true),      // This tool only reads, doesn't modify
-        mcp.WithDestructiveHintAnnotation(false),  // Not destructive - only returns time
-        mcp.WithIdempotentHintAnnotation(false),   // Not idempotent - returns different time each call
-        mcp.WithOpenWorldHintAnnotation(false),    // No external access - uses only local system time
-        mcp.WithString("timezone",
-            mcp.Description("IANA timezone name (e.g., 'America/New_York', 'Europe/London'). Defaults to UTC"),
-        ),
-    )
-    s.AddTool(getTimeTool, handleGetSystemTime)
-
-    // Register convert_time tool
-    convertTimeTool := mcp.NewTool("convert_time",
-        mcp.WithDescription("Convert time between different timezones"),
-        mcp.WithTitleAnnotation("Convert Time"),
-        mcp.WithReadOnlyHintAnnotation(true),      // This tool only converts, doesn't modify
-        mcp.WithDestructiveHintAnnotation(false),  // Not destructive - only converts time
-        mcp.WithIdempotentHintAnnotation(true),    // Idempotent - same input gives same output
-        mcp.WithOpenWorldHintAnnotation(false),    // No external access - pure computation
-        mcp.WithString("time",
-            mcp.Required(),
-            mcp.Description("Time to convert in RFC3339 format or common formats like '2006-01-02 15:04:05'"),
-        ),
-        mcp.WithString("source_timezone",
-            mcp.Required(),
-            mcp.Description("Source IANA timezone name"),
-        ),
-        mcp.WithString("target_timezone",
-            mcp.Required(),
-            mcp.Description("Target IANA timezone name"),
-        ),
-    )
-    s.AddTool(convertTimeTool, handleConvertTime)
-
-    /* ----------------------- register resources ---------------------- */
-    // Register timezone information resource
-    s.AddResource(mcp.NewResource("timezone://info", "Timezone Information",
-        mcp.WithResourceDescription("Comprehensive timezone information including offsets, DST, and major cities"),
-        mcp.WithMIMEType("application/json"),
-    ), handleTimezoneInfo)
-
-    // Register current world times resource
-    s.AddResource(mcp.NewResource("time://current/world", "Current World Times",
-        mcp.WithResourceDescription("Current time in major cities around the world"),
-        mcp.WithMIMEType("application/json"),
-    ), handleCurrentWorldTimes)
-
-    // Register time format examples resource
-    s.AddResource(mcp.NewResource("time://formats", "Time Formats",
-        mcp.WithResourceDescription("Examples of supported time formats for parsing and display"),
-        mcp.WithMIMEType("application/json"),
-    ), handleTimeFormats)
-
-    // Register business hours resource
-    s.AddResource(mcp.NewResource("time://business-hours", "Business Hours",
-        mcp.WithResourceDescription("Standard business hours across different regions"),
-        mcp.WithMIMEType("application/json"),
-    ), handleBusinessHours)
-
-    /* ----------------------- register prompts ------------------------ */
-    // Register time zone comparison prompt
-    s.AddPrompt(mcp.NewPrompt("compare_timezones",
-        mcp.WithPromptDescription("Compare current times across multiple time zones"),
-        mcp.WithArgument("timezones",
-            mcp.RequiredArgument(),
-            mcp.ArgumentDescription("Comma-separated list of timezone IDs to compare"),
-        ),
-        mcp.WithArgument("reference_time",
-            mcp.ArgumentDescription("Optional reference time (defaults to now)"),
-        ),
-    ), handleCompareTimezonesPrompt)
-
-    // Register meeting scheduler prompt
-    s.AddPrompt(mcp.NewPrompt("schedule_meeting",
-        mcp.WithPromptDescription("Find optimal meeting time across multiple time zones"),
-        mcp.WithArgument("participants",
-            mcp.RequiredArgument(),
-            mcp.ArgumentDescription("Comma-separated list of participant locations/timezones"),
-        ),
-        mcp.WithArgument("duration",
-            mcp.RequiredArgument(),
-            mcp.ArgumentDescription("Meeting duration in minutes"),
-        ),
-        mcp.WithArgument("preferred_hours",
-            mcp.ArgumentDescription("Preferred time range (e.g., '9 AM - 5 PM')"),
-        ),
-        mcp.WithArgument("date_range",
-            mcp.ArgumentDescription("Date range to consider (e.g., 'next 7 days')"),
-        ),
-    ), handleScheduleMeetingPrompt)
-
-    // Register time zone converter prompt
-    s.AddPrompt(mcp.NewPrompt("convert_time_detailed",
-        mcp.WithPromptDescription("Convert time with detailed context"),
-        mcp.WithArgument("time",
-            mcp.RequiredArgument(),
-            mcp.ArgumentDescription("Time to convert"),
-        ),
-        mcp.WithArgument("from_timezone",
-            mcp.RequiredArgument(),
-            mcp.ArgumentDescription("Source timezone"),
-        ),
-        mcp.WithArgument("to_timezones",
-            mcp.RequiredArgument(),
-            mcp.ArgumentDescription("Comma-separated list of target timezones"),
-        ),
-        mcp.WithArgument("include_context",
-            mcp.ArgumentDescription("Whether to include contextual information (true/false)"),
-        ),
-    ), handleConvertTimeDetailedPrompt)
-
-    /* -------------------- choose transport & serve ---------------- */
-    switch strings.ToLower(*transport) {
-
-    /* ---------------------------- stdio -------------------------- */
-    case "stdio":
-        if *authToken != "" {
-            logAt(logWarn, "auth-token is ignored for stdio transport")
+    if *configFile != "" {
+        if err := applyConfigFile(flag.CommandLine, *configFile); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(2)
         }
-        logAt(logInfo, "serving via stdio transport")
-        if err := server.ServeStdio(s); err != nil {
-            logger.Fatalf("stdio server error: %v", err)
-        }
-
-    /* ----------------------------- sse --------------------------- */
-    case "sse":
-        addr := effectiveAddr(*addrFlag, *listenHost, *port)
-        mux := http.NewServeMux()
-
-        // Configure SSE options - no base path for root serving
-        opts := []server.SSEOption{}
-        if *publicURL != "" {
-            // Ensure public URL doesn't have trailing slash
-            opts = append(opts, server.WithBaseURL(strings.TrimRight(*publicURL, "/")))
-        }
-
-        // Register SSE handler at root
-        sseHandler := server.NewSSEServer(s, opts...)
-        mux.Handle("/", sseHandler)
-
-        // Register health and version endpoints
-        registerHealthAndVersion(mux)
-
-        logAt(logInfo, "SSE server ready on http://%s", addr)
-        logAt(logInfo, "  MCP SSE events:   /sse")
-        logAt(logInfo, "  MCP SSE messages: /messages")
-        logAt(logInfo, "  Health check:     /health")
-        logAt(logInfo, "  Version info:     /version")
-
-        if *publicURL != "" {
-            logAt(logInfo, "  Public URL:       %s", *publicURL)
-        }
-
-        if *authToken != "" {
-            logAt(logInfo, "  Authentication:   Bearer token required")
-        }
-
-        // Create handler chain
-        var handler http.Handler = mux
-        handler = loggingHTTPMiddleware(handler)
-        if *authToken != "" {
-            handler = authMiddleware(*authToken, handler)
-        }
-
-        // Start server
-        if err := http.ListenAndServe(addr, handler); err != nil && err != http.ErrServerClosed {
-            logger.Fatalf("SSE server error: %v", err)
-        }
-
-    /* ----------------------- streamable http --------------------- */
-    case "http":
-        addr := effectiveAddr(*addrFlag, *listenHost, *port)
-        mux := http.NewServeMux()
-
-        // Register HTTP handler at root
-        httpHandler := server.NewStreamableHTTPServer(s)
-        mux.Handle("/", httpHandler)
-
-        // Register health and version endpoints
-        registerHealthAndVersion(mux)
-
-        // Add a helpful GET handler for root
-        mux.HandleFunc("/info", func(w http.ResponseWriter, _ *http.Request) {
-            w.Header().Set("Content-Type", "application/json")
-            fmt.Fprintf(w, `{"message":"MCP HTTP server ready","instructions":"Use POST requests with JSON-RPC 2.0 payloads","example":{"jsonrpc":"2.0","method":"tools/list","id":1}}`)
-        })
-
-        logAt(logInfo, "HTTP server ready on http://%s", addr)
-        logAt(logInfo, "  MCP endpoint:     / (POST with JSON-RPC)")
-        logAt(logInfo, "  Info:             /info")
-        logAt(logInfo, "  Health check:     /health")
-        logAt(logInfo, "  Version info:     /version")
-
-        if *authToken != "" {
-            logAt(logInfo, "  Authentication:   Bearer token required")
-        }
-
-        // Example command
-        logAt(logInfo, "Test with: curl -X POST http://%s/ -H 'Content-Type: application/json' -d '{\"jsonrpc\":\"2.0\",\"method\":\"tools/list\",\"id\":1}'", addr)
-
-        // Create handler chain
-        var handler http.Handler = mux
-        handler = loggingHTTPMiddleware(handler)
-        if *authToken != "" {
-            handler = authMiddleware(*authToken, handler)
-        }
-
-        // Start server
-        if err := http.ListenAndServe(addr, handler); err != nil && err != http.ErrServerClosed {
-            logger.Fatalf("HTTP server error: %v", err)
-        }
-
-    /* ---------------------------- dual --------------------------- */
-    case "dual":
-        addr := effectiveAddr(*addrFlag, *listenHost, *port)
-        mux := http.NewServeMux()
-
-        // Configure SSE handler for /sse and /messages
-        sseOpts := []server.SSEOption{}
-        if *publicURL != "" {
-            sseOpts = append(sseOpts, server.WithBaseURL(strings.TrimRight(*publicURL, "/")))
-        }
-        sseHandler := server.NewSSEServer(s, sseOpts...)
-
-        // Configure HTTP handler for /http
-        httpHandler := server.NewStreamableHTTPServer(s, server.WithEndpointPath("/http"))
-
-        // Register handlers
-        mux.Handle("/sse", sseHandler)
-        mux.Handle("/messages", sseHandler) // Support plural (backward compatibility)
-        mux.Handle("/message", sseHandler)  // Support singular (MCP Gateway compatibility)
-        mux.Handle("/http", httpHandler)
-
-        // Register REST API handlers
-        registerRESTHandlers(mux)
-
-        // Register health and version endpoints
-        registerHealthAndVersion(mux)
-
-        logAt(logInfo, "DUAL server ready on http://%s", addr)
-        logAt(logInfo, "  SSE events:       /sse")
-        logAt(logInfo, "  SSE messages:     /messages (plural) and /message (singular)")
-        logAt(logInfo, "  HTTP endpoint:    /http")
-        logAt(logInfo, "  REST API:         /api/v1/*")
-        logAt(logInfo, "  API Docs:         /api/v1/docs")
-        logAt(logInfo, "  Health check:     /health")
-        logAt(logInfo, "  Version info:     /version")
-
-        if *publicURL != "" {
-            logAt(logInfo, "  Public URL:       %s", *publicURL)
-        }
-
-        if *authToken != "" {
-            logAt(logInfo, "  Authentication:   Bearer token required")
-        }
-
-        // Create handler chain
-        var handler http.Handler = mux
-        handler = corsMiddleware(handler) // Add CORS support for REST API
-        handler = loggingHTTPMiddleware(handler)
-        if *authToken != "" {
-            handler = authMiddleware(*authToken, handler)
-        }
-
-        // Start server
-        if err := http.ListenAndServe(addr, handler); err != nil && err != http.ErrServerClosed {
-            logger.Fatalf("DUAL server error: %v", err)
-        }
-
-    /* ---------------------------- rest --------------------------- */
-    case "rest":
-        addr := effectiveAddr(*addrFlag, *listenHost, *port)
-        mux := http.NewServeMux()
-
-        // Register REST API handlers
-        registerRESTHandlers(mux)
-
-        // Register health and version endpoints
-        registerHealthAndVersion(mux)
-
-        logAt(logInfo, "REST API server ready on http://%s", addr)
-        logAt(logInfo, "  API Base:         /api/v1")
-        logAt(logInfo, "  API Docs:         /api/v1/docs")
-        logAt(logInfo, "  OpenAPI Spec:     /api/v1/openapi.json")
-        logAt(logInfo, "  Health check:     /health")
-        logAt(logInfo, "  Version info:     /version")
-
-        if *authToken != "" {
-            logAt(logInfo, "  Authentication:   Bearer token required")
-        }
-
-        // Example commands
-        logAt(logInfo, "Test commands:")
-        logAt(logInfo, "  Get time:    curl http://%s/api/v1/time?timezone=UTC", addr)
-        logAt(logInfo, "  List zones:  curl http://%s/api/v1/timezones", addr)
-        logAt(logInfo, "  Echo test:   curl http://%s/api/v1/test/echo", addr)
-
-        // Create handler chain
-        var handler http.Handler = mux
-        handler = corsMiddleware(handler) // Add CORS support
-        handler = loggingHTTPMiddleware(handler)
-        if *authToken != "" {
-            handler = authMiddleware(*authToken, handler)
-        }
-
-        // Start server
-        if err := http.ListenAndServe(addr, handler); err != nil && err != http.ErrServerClosed {
-            logger.Fatalf("REST server error: %v", err)
-        }
-
-    default:
-        fmt.Fprintf(os.Stderr, "Error: unknown transport %q\n\n", *transport)
-        flag.Usage()
-        os.Exit(2)
     }
-}
-
-/* ------------------------------------------------------------------ */
-/*                        helper functions                            */
-/* ------------------------------------------------------------------ */
-
-// effectiveAddr determines the actual address to listen on
-func effectiveAddr(addrFlag, listen string, port int) string {
-    if addrFlag != "" {
-        return addrFlag
+    if doctor {
+        os.Exit(fasttime.Doctor(cfg, os.Stdout))
     }
-    return fmt.Sprintf("%s:%d", listen, port)
-}
 
-// registerHealthAndVersion adds health and version endpoints to the mux
-func registerHealthAndVersion(mux *http.ServeMux) {
-    // Health endpoint - JSON response
-    mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
-        w.Header().Set("Content-Type", "application/json")
-        w.WriteHeader(http.StatusOK)
-        _, _ = w.Write([]byte(healthJSON()))
-    })
-
-    // Version endpoint - JSON response
-    mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
-        w.Header().Set("Content-Type", "application/json")
-        w.WriteHeader(http.StatusOK)
-        _, _ = w.Write([]byte(versionJSON()))
-    })
-}
-
-/* -------------------- HTTP middleware ----------------------------- */
-
-// loggingHTTPMiddleware provides request logging when log level permits
-func loggingHTTPMiddleware(next http.Handler) http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        if curLvl < logInfo {
-            next.ServeHTTP(w, r)
-            return
+    // The background copy may still see daemon: true from -config
+    if *daemonMode && os.Getenv(envDaemonFD) == "" {
+        daemonTransport := cfg.Transport
+        if cfg.Listeners != "" {
+            daemonTransport = "listeners"
         }
-
-        start := time.Now()
-
-        // Wrap response writer to capture status code
-        rw := &statusWriter{ResponseWriter: w, status: http.StatusOK, written: false}
-
-        // Call the next handler
-        next.ServeHTTP(rw, r)
-
-        // Log the request with body size for POST requests
-        duration := time.Since(start)
-        if r.Method == "POST" && curLvl >= logDebug {
-            logAt(logDebug, "%s %s %s %d (Content-Length: %s) %v",
-                r.RemoteAddr, r.Method, r.URL.Path, rw.status, r.Header.Get("Content-Length"), duration)
-        } else {
-            logAt(logInfo, "%s %s %s %d %v",
-                r.RemoteAddr, r.Method, r.URL.Path, rw.status, duration)
+        if err := checkDaemonFlags(daemonTransport, cfg.LogOutput, cfg.LogFile); err != nil {
+            fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+            os.Exit(exitUsage)
         }
-    })
-}
-
-// statusWriter wraps http.ResponseWriter so we can capture the status code
-// *and* still pass through streaming-related interfaces (Flusher, Hijacker,
-// CloseNotifier) that SSE / HTTP streaming require.
-type statusWriter struct {
-    http.ResponseWriter
-    status  int
-    written bool
-}
-
-/* -------- core ResponseWriter behaviour -------- */
-
-func (sw *statusWriter) WriteHeader(code int) {
-    if !sw.written {
-        sw.status = code
-        sw.written = true
-        sw.ResponseWriter.WriteHeader(code)
-    }
-}
-
-func (sw *statusWriter) Write(b []byte) (int, error) {
-    if !sw.written {
-        sw.WriteHeader(http.StatusOK)
-    }
-    return sw.ResponseWriter.Write(b)
-}
-
-/* -------- pass-through for streaming interfaces -------- */
-
-// Flush lets the underlying handler stream (needed for SSE)
-func (sw *statusWriter) Flush() {
-    if f, ok := sw.ResponseWriter.(http.Flusher); ok {
-        if !sw.written {
-            sw.WriteHeader(http.StatusOK)
+        if cfg.PIDFile != "" {
+            if err := fasttime.CheckPIDFile(cfg.PIDFile); err != nil {
+                fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+                os.Exit(exitFail)
+            }
         }
-        f.Flush()
+        os.Exit(startDaemon(os.Stdout, os.Stderr))
     }
-}
+    cfg.Ready = notifyDaemonParent
 
-// Hijack lets handlers switch to raw TCP (not used by SSE but good hygiene)
-func (sw *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-    if h, ok := sw.ResponseWriter.(http.Hijacker); ok {
-        return h.Hijack()
+    /* ---------------------------- serve --------------------------- */
+    srv, err := fasttime.New(cfg)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+        os.Exit(exitFail)
     }
-    return nil, nil, fmt.Errorf("hijacking not supported")
-}
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
 
-// CloseNotify keeps SSE clients informed if the peer goes away
-// Deprecated: Use Request.Context() instead. Kept for compatibility with older SSE implementations.
-func (sw *statusWriter) CloseNotify() <-chan bool {
-    // nolint:staticcheck // SA1019: http.CloseNotifier is deprecated but required for SSE compatibility
-    if cn, ok := sw.ResponseWriter.(http.CloseNotifier); ok {
-        return cn.CloseNotify()
+    // A server started by the Windows service manager reports to it
+    if err := watchServiceManager(stop); err != nil {
+        fasttime.Logf("error", "service manager: %v", err)
+        srv.Close()
+        os.Exit(exitFail)
+    }
+    if err := srv.Run(ctx); err != nil {
+        fasttime.Logf("error", "%v", err)
+        stop()
+        os.Exit(exitFail)
     }
-    // If the underlying writer doesn't support it, fabricate a never-closing chan
-    done := make(chan bool, 1)
-    return done
 }
@@ -0,0 +1,132 @@
+// -*- coding: utf-8 -*-
+// service.go - the service subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file implements `fast-time-server service ACTION`, which manages the
+// server as a Windows service (service_windows.go):
+//
+//   fast-time-server service install -transport=http -port=8080
+//   fast-time-server service start | stop | status | uninstall
+//
+// install registers this binary with the given server flags, starting
+// automatically at boot, and registers the event log source. Services have
+// no console, so install rejects -transport=stdio (use -transport=pipe for a
+// local stdio-style server), adds -log-output=eventlog unless a log
+// destination is given, and requires absolute file paths because services
+// start in %SystemRoot%\System32. Elsewhere the actions fail; use a systemd
+// unit instead.
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "io"
+    "path/filepath"
+    "strings"
+)
+
+// serviceActions are the actions of the service subcommand
+var serviceActions = []string{"install", "uninstall", "start", "stop", "status"}
+
+// servicePathFlags are the server flags naming files or directories
+var servicePathFlags = []string{
+    "config", "aliases", "cities", "fiscal-calendars", "db", "auth-token-file", "admin-token-file", "auth-tokens-file",
+    "audit-log", "record", "replay", "ip-acl-file", "i18n-dir", "log-file", "tzdata-dir",
+}
+
+// runService implements the service subcommand
+func runService(server *flag.FlagSet, args []string, stdout, stderr io.Writer) int {
+    usage := func() {
+        fmt.Fprintf(stderr, "Usage: %s service install [server flags]\n", appName)
+        fmt.Fprintf(stderr, "       %s service uninstall|start|stop|status\n\n", appName)
+        fmt.Fprintf(stderr, "Manages %s as a Windows service.\n", appName)
+    }
+    if len(args) == 0 {
+        usage()
+        return exitUsage
+    }
+    action, rest := args[0], args[1:]
+    switch action {
+    case "-h", "-help", "--help", "help":
+        usage()
+        return exitOK
+    case "install":
+    default:
+        if len(rest) > 0 {
+            fmt.Fprintf(stderr, "Error: service %s takes no arguments\n", action)
+            return exitUsage
+        }
+    }
+
+    var err error
+    switch action {
+    case "install":
+        var serviceArgs []string
+        if serviceArgs, err = serviceArguments(server, rest); err != nil {
+            fmt.Fprintln(stderr, "Error:", err)
+            return exitUsage
+        }
+        if err = installService(serviceArgs); err == nil {
+            fmt.Fprintf(stdout, "installed service %s: %s\n", appName, strings.Join(serviceArgs, " "))
+        }
+    case "uninstall":
+        if err = removeService(); err == nil {
+            fmt.Fprintf(stdout, "removed service %s\n", appName)
+        }
+    case "start":
+        if err = startService(); err == nil {
+            fmt.Fprintf(stdout, "started service %s\n", appName)
+        }
+    case "stop":
+        if err = stopService(); err == nil {
+            fmt.Fprintf(stdout, "stopped service %s\n", appName)
+        }
+    case "status":
+        var state string
+        if state, err = serviceStatus(); err == nil {
+            fmt.Fprintf(stdout, "%s: %s\n", appName, state)
+        }
+    default:
+        fmt.Fprintf(stderr, "Error: unknown action %q (use %s)\n", action, strings.Join(serviceActions, ", "))
+        return exitUsage
+    }
+    if err != nil {
+        fmt.Fprintln(stderr, "Error:", err)
+        return exitFail
+    }
+    return exitOK
+}
+
+// serviceArguments checks server flags for use by a service and returns
+// them with -log-output=eventlog added when no log destination is given
+func serviceArguments(server *flag.FlagSet, args []string) ([]string, error) {
+    // The server flag set exits on errors; parse a copy that reports them
+    fs := flag.NewFlagSet("service", flag.ContinueOnError)
+    fs.SetOutput(io.Discard)
+    server.VisitAll(func(f *flag.Flag) { fs.Var(f.Value, f.Name, f.Usage) })
+    if err := fs.Parse(args); err != nil {
+        return nil, err
+    }
+    if fs.NArg() > 0 {
+        return nil, fmt.Errorf("unexpected arguments %q", fs.Args())
+    }
+    set := map[string]bool{}
+    fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+    if t := strings.ToLower(fs.Lookup("transport").Value.String()); t == "stdio" {
+        return nil, fmt.Errorf("a service has no stdin or stdout; choose -transport=http, sse, dual, rest or pipe")
+    }
+    for _, name := range servicePathFlags {
+        if f := fs.Lookup(name); f != nil && set[name] && !filepath.IsAbs(f.Value.String()) {
+            return nil, fmt.Errorf("-%s must be an absolute path; services start in %%SystemRoot%%\\System32", name)
+        }
+    }
+    out := append([]string(nil), args...)
+    if !set["log-output"] && !set["log-file"] {
+        out = append(out, "-log-output=eventlog")
+    }
+    return out, nil
+}
@@ -0,0 +1,72 @@
+// -*- coding: utf-8 -*-
+// service_test.go - Tests for the service subcommand
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+    "bytes"
+    "flag"
+    "runtime"
+    "strings"
+    "testing"
+)
+
+// serviceServerFlags returns the server flags service install looks at
+func serviceServerFlags() *flag.FlagSet {
+    fs := testServerFlags()
+    fs.String("log-output", "", "Log destination")
+    fs.String("log-file", "", "Log file")
+    fs.String("aliases", "", "Aliases file")
+    return fs
+}
+
+func TestServiceArguments(t *testing.T) {
+    got, err := serviceArguments(serviceServerFlags(), []string{"-transport=http", "-port", "9090"})
+    if err != nil || strings.Join(got, " ") != "-transport=http -port 9090 -log-output=eventlog" {
+        t.Errorf("got %q, %v", got, err)
+    }
+    got, err = serviceArguments(serviceServerFlags(), []string{"-transport=pipe", "-log-output=stderr"})
+    if err != nil || len(got) != 2 {
+        t.Errorf("explicit log output: %q, %v", got, err)
+    }
+
+    for _, args := range [][]string{
+        {},                                     // stdio by default
+        {"-transport=stdio"},                   // no console
+        {"-transport=http", "-aliases=a.json"}, // relative path
+        {"-transport=http", "-bogus"},          // unknown flag
+        {"-transport=http", "extra"},           // positional
+        {"-transport=http", "-port=high"},      // bad value
+    } {
+        if _, err := serviceArguments(serviceServerFlags(), args); err == nil {
+            t.Errorf("%q should be rejected", args)
+        }
+    }
+}
+
+func TestRunServiceUsage(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    if code := runService(serviceServerFlags(), nil, &stdout, &stderr); code != exitUsage {
+        t.Errorf("no action: exit %d", code)
+    }
+    if code := runService(serviceServerFlags(), []string{"restart"}, &stdout, &stderr); code != exitUsage {
+        t.Errorf("unknown action: exit %d", code)
+    }
+    if code := runService(serviceServerFlags(), []string{"stop", "now"}, &stdout, &stderr); code != exitUsage {
+        t.Errorf("extra arguments: exit %d", code)
+    }
+    if code := runService(serviceServerFlags(), []string{"install"}, &stdout, &stderr); code != exitUsage || !strings.Contains(stderr.String(), "stdin or stdout") {
+        t.Errorf("stdio install: exit %d, %q", code, stderr.String())
+    }
+
+    if runtime.GOOS == "windows" {
+        t.Skip("the service manager is available")
+    }
+    stderr.Reset()
+    if code := runService(serviceServerFlags(), []string{"status"}, &stdout, &stderr); code != exitFail || !strings.Contains(stderr.String(), "only on Windows") {
+        t.Errorf("status: exit %d, %q", code, stderr.String())
+    }
+}
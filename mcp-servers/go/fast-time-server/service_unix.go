@@ -0,0 +1,26 @@
+// -*- coding: utf-8 -*-
+// service_unix.go - service stubs outside Windows
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// Only Windows has a service manager of this kind; elsewhere the service
+// actions fail and the server runs under systemd or another supervisor.
+
+//go:build !windows
+
+package main
+
+import "errors"
+
+// errNoServiceManager is returned by every service action
+var errNoServiceManager = errors.New("services are managed this way only on Windows; use a systemd unit here (see the README)")
+
+func installService([]string) error  { return errNoServiceManager }
+func removeService() error           { return errNoServiceManager }
+func startService() error            { return errNoServiceManager }
+func stopService() error             { return errNoServiceManager }
+func serviceStatus() (string, error) { return "", errNoServiceManager }
+
+// watchServiceManager does nothing outside Windows
+func watchServiceManager(func()) error { return nil }
@@ -0,0 +1,188 @@
+// -*- coding: utf-8 -*-
+// service_windows.go - Windows service manager integration
+//
+// Copyright 2025
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file installs, controls and removes the fast-time-server service,
+// and lets a server started by the service manager report that it is running
+// and stop when asked. The service runs as LocalSystem and starts at boot.
+
+//go:build windows
+
+package main
+
+import (
+    "fmt"
+    "os"
+    "strings"
+    "time"
+
+    "golang.org/x/sys/windows/svc"
+    "golang.org/x/sys/windows/svc/eventlog"
+    "golang.org/x/sys/windows/svc/mgr"
+
+    "fast-time-server/fasttime"
+)
+
+// serviceStopTimeout bounds how long `service stop` waits
+const serviceStopTimeout = 30 * time.Second
+
+// openService connects to the service manager and opens the service
+func openService() (*mgr.Mgr, *mgr.Service, error) {
+    m, err := mgr.Connect()
+    if err != nil {
+        return nil, nil, fmt.Errorf("connecting to the service manager (run as administrator): %w", err)
+    }
+    s, err := m.OpenService(appName)
+    if err != nil {
+        m.Disconnect()
+        return nil, nil, fmt.Errorf("service %s is not installed: %w", appName, err)
+    }
+    return m, s, nil
+}
+
+// installService registers the service with args and the event log source
+func installService(args []string) error {
+    exe, err := os.Executable()
+    if err != nil {
+        return err
+    }
+    m, err := mgr.Connect()
+    if err != nil {
+        return fmt.Errorf("connecting to the service manager (run as administrator): %w", err)
+    }
+    defer m.Disconnect()
+    if s, err := m.OpenService(appName); err == nil {
+        s.Close()
+        return fmt.Errorf("service %s is already installed; uninstall it first", appName)
+    }
+    s, err := m.CreateService(appName, exe, mgr.Config{
+        DisplayName: "Fast Time Server",
+        Description: "MCP server for time and timezone tools",
+        StartType:   mgr.StartAutomatic,
+    }, args...)
+    if err != nil {
+        return err
+    }
+    defer s.Close()
+    err = eventlog.InstallAsEventCreate(appName, eventlog.Error|eventlog.Warning|eventlog.Info)
+    if err != nil && !strings.HasSuffix(err.Error(), "already exists") {
+        s.Delete()
+        return fmt.Errorf("registering the event log source: %w", err)
+    }
+    return nil
+}
+
+// removeService deletes the service and its event log source
+func removeService() error {
+    m, s, err := openService()
+    if err != nil {
+        return err
+    }
+    defer m.Disconnect()
+    defer s.Close()
+    if err := s.Delete(); err != nil {
+        return err
+    }
+    _ = eventlog.Remove(appName)
+    return nil
+}
+
+// startService asks the service manager to start the service
+func startService() error {
+    m, s, err := openService()
+    if err != nil {
+        return err
+    }
+    defer m.Disconnect()
+    defer s.Close()
+    return s.Start()
+}
+
+// stopService stops the service and waits until it has stopped
+func stopService() error {
+    m, s, err := openService()
+    if err != nil {
+        return err
+    }
+    defer m.Disconnect()
+    defer s.Close()
+    status, err := s.Control(svc.Stop)
+    if err != nil {
+        return err
+    }
+    deadline := time.Now().Add(serviceStopTimeout)
+    for status.State != svc.Stopped {
+        if time.Now().After(deadline) {
+            return fmt.Errorf("service %s did not stop within %v", appName, serviceStopTimeout)
+        }
+        time.Sleep(300 * time.Millisecond)
+        if status, err = s.Query(); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// serviceStatus returns the state of the service
+func serviceStatus() (string, error) {
+    m, s, err := openService()
+    if err != nil {
+        return "", err
+    }
+    defer m.Disconnect()
+    defer s.Close()
+    status, err := s.Query()
+    if err != nil {
+        return "", err
+    }
+    switch status.State {
+    case svc.Stopped:
+        return "stopped", nil
+    case svc.StartPending:
+        return "starting", nil
+    case svc.StopPending:
+        return "stopping", nil
+    case svc.Running:
+        return "running", nil
+    case svc.Paused, svc.PausePending, svc.ContinuePending:
+        return "paused", nil
+    }
+    return fmt.Sprintf("state %d", status.State), nil
+}
+
+// watchServiceManager reports to the service manager when it started this
+// process, and calls stop once the service is stopped
+func watchServiceManager(stop func()) error {
+    isService, err := svc.IsWindowsService()
+    if err != nil || !isService {
+        return err
+    }
+    go func() {
+        if err := svc.Run(appName, serviceHandler{}); err != nil {
+            fasttime.Logf("error", "service manager: %v", err)
+        }
+        stop()
+    }()
+    return nil
+}
+
+// serviceHandler answers the service manager's control requests
+type serviceHandler struct{}
+
+// Execute implements svc.Handler
+func (serviceHandler) Execute(_ []string, req <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+    status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+    for c := range req {
+        switch c.Cmd {
+        case svc.Interrogate:
+            status <- c.CurrentStatus
+        case svc.Stop, svc.Shutdown:
+            fasttime.Logf("info", "stopping at the request of the service manager")
+            status <- svc.Status{State: svc.StopPending}
+            return false, 0
+        }
+    }
+    return false, 0
+}